@@ -41,6 +41,22 @@ type literal interface {
 // A Program node is the root node within the AST.
 type Program struct {
 	Statements []Statement
+	// MagicComments holds the magic comments of the source, e.g.
+	// `frozen_string_literal` => `true`
+	MagicComments map[string]string
+	// Data holds the content after the `__END__` marker, if HasData is true
+	Data    string
+	HasData bool
+	// Comments holds the comments of the source in the order of their
+	// position
+	Comments []*Comment
+}
+
+// MagicComment returns the value of the magic comment key and whether it was
+// set at all.
+func (p *Program) MagicComment(key string) (string, bool) {
+	value, ok := p.MagicComments[key]
+	return value, ok
 }
 
 func (p *Program) String() string {
@@ -62,6 +78,7 @@ func (p *Program) TokenLiteral() string {
 
 // A ReturnStatement represents a return node which yields another Expression.
 type ReturnStatement struct {
+	Span
 	Token       token.Token // the 'return' token
 	ReturnValue Expression
 }
@@ -81,6 +98,7 @@ func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
 
 // An ExpressionStatement is a Statement wrapping an Expression
 type ExpressionStatement struct {
+	Span
 	Token      token.Token // the first token of the expression
 	Expression Expression
 }
@@ -96,8 +114,53 @@ func (es *ExpressionStatement) statementNode() {}
 // TokenLiteral returns the first token of the Expression
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
 
+// A BreakStatement represents a break out of a loop, optionally yielding a
+// value as result of the loop
+type BreakStatement struct {
+	Span
+	Token token.Token // the 'break' token
+	Value Expression
+}
+
+func (bs *BreakStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(bs.TokenLiteral())
+	if bs.Value != nil {
+		out.WriteString(" ")
+		out.WriteString(bs.Value.String())
+	}
+	return out.String()
+}
+func (bs *BreakStatement) statementNode() {}
+
+// TokenLiteral returns the 'break' token literal
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+
+// A NextStatement represents a jump to the next iteration of a loop or the
+// end of a block, optionally yielding a value as result of the block
+type NextStatement struct {
+	Span
+	Token token.Token // the 'next' token
+	Value Expression
+}
+
+func (ns *NextStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(ns.TokenLiteral())
+	if ns.Value != nil {
+		out.WriteString(" ")
+		out.WriteString(ns.Value.String())
+	}
+	return out.String()
+}
+func (ns *NextStatement) statementNode() {}
+
+// TokenLiteral returns the 'next' token literal
+func (ns *NextStatement) TokenLiteral() string { return ns.Token.Literal }
+
 // BlockStatement represents a list of statements
 type BlockStatement struct {
+	Span
 	// the { token or the first token from the first statement
 	Token      token.Token
 	Statements []Statement
@@ -117,6 +180,7 @@ func (bs *BlockStatement) String() string {
 
 // VariableAssignment represents a variable assignment
 type VariableAssignment struct {
+	Span
 	Name  *Identifier
 	Value Expression
 }
@@ -141,8 +205,30 @@ func (v *VariableAssignment) expressionNode() {}
 // TokenLiteral returns the literal of the Name token
 func (v *VariableAssignment) TokenLiteral() string { return v.Name.Token.Literal }
 
+// IndexAssignment represents an assignment to an index, like `arr[1] = x`
+type IndexAssignment struct {
+	Span
+	Target *IndexExpression
+	Value  Expression
+}
+
+func (ia *IndexAssignment) String() string {
+	var out bytes.Buffer
+	out.WriteString(ia.Target.String())
+	out.WriteString(" = ")
+	if ia.Value != nil {
+		out.WriteString(ia.Value.String())
+	}
+	return out.String()
+}
+func (ia *IndexAssignment) expressionNode() {}
+
+// TokenLiteral returns the literal of the [ token
+func (ia *IndexAssignment) TokenLiteral() string { return ia.Target.Token.Literal }
+
 // Self represents self in the current context in the program
 type Self struct {
+	Span
 	Token token.Token // the token.SELF token
 }
 
@@ -155,8 +241,17 @@ func (s *Self) TokenLiteral() string { return s.Token.Literal }
 
 // An Identifier represents an identifier in the program
 type Identifier struct {
+	Span
 	Token token.Token // the token.IDENT token
 	Value string
+	// Scope is the scope of the method the identifier has been resolved
+	// within and Slot the index of the local variable named by the
+	// identifier. Scope is nil for unresolved identifiers.
+	Scope *Scope
+	Slot  int
+	// Declared is set if an assignment before the identifier declared it
+	// as local variable, which is nil as long as it is not assigned
+	Declared bool
 }
 
 func (i *Identifier) String() string  { return i.Value }
@@ -166,8 +261,64 @@ func (i *Identifier) literalNode()    {}
 // TokenLiteral returns the literal of the token.IDENT token
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 
+// A ScopedConstant represents a constant looked up within a class or
+// module, like `Net::HTTP`. Scope is nil for top level constants written
+// like `::String`.
+type ScopedConstant struct {
+	Span
+	Token token.Token // the token.SCOPE token
+	Scope Expression
+	Name  string
+}
+
+func (s *ScopedConstant) String() string {
+	if s.Scope == nil {
+		return "::" + s.Name
+	}
+	return s.Scope.String() + "::" + s.Name
+}
+func (s *ScopedConstant) expressionNode() {}
+
+// TokenLiteral returns the literal of the token.SCOPE token
+func (s *ScopedConstant) TokenLiteral() string { return s.Token.Literal }
+
+// A Scope lists the local variables of a method body. The environment of a
+// method call stores them by their index instead of by name.
+type Scope struct {
+	Names   []string
+	indices map[string]int
+}
+
+// NewScope returns a Scope holding the local variables names
+func NewScope(names ...string) *Scope {
+	scope := &Scope{indices: make(map[string]int)}
+	for _, name := range names {
+		scope.Add(name)
+	}
+	return scope
+}
+
+// Add adds the local variable name, unless s holds it already, and returns
+// its index
+func (s *Scope) Add(name string) int {
+	if index, ok := s.indices[name]; ok {
+		return index
+	}
+	s.indices[name] = len(s.Names)
+	s.Names = append(s.Names, name)
+	return len(s.Names) - 1
+}
+
+// Index returns the index of the local variable name and whether s holds
+// it
+func (s *Scope) Index(name string) (int, bool) {
+	index, ok := s.indices[name]
+	return index, ok
+}
+
 // IntegerLiteral represents an integer in the AST
 type IntegerLiteral struct {
+	Span
 	Token token.Token
 	Value int64
 }
@@ -179,8 +330,23 @@ func (il *IntegerLiteral) literalNode()    {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+// FloatLiteral represents a float in the AST
+type FloatLiteral struct {
+	Span
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal from the token.FLOAT token
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 // Nil represents the 'nil' keyword
 type Nil struct {
+	Span
 	Token token.Token
 }
 
@@ -193,6 +359,7 @@ func (n *Nil) String() string       { return "nil" }
 
 // Boolean represents a boolean in the AST
 type Boolean struct {
+	Span
 	Token token.Token
 	Value bool
 }
@@ -206,8 +373,12 @@ func (b *Boolean) String() string       { return b.Token.Literal }
 
 // StringLiteral represents a double quoted string in the AST
 type StringLiteral struct {
+	Span
 	Token token.Token // the '"'
 	Value string
+	// Frozen is true if the literal evaluates to a frozen string, i.e. if the
+	// program enabled the `frozen_string_literal` magic comment
+	Frozen bool
 }
 
 func (sl *StringLiteral) expressionNode() {}
@@ -219,6 +390,7 @@ func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 // SymbolLiteral represents a symbol within the AST
 type SymbolLiteral struct {
+	Span
 	Token token.Token // the ':'
 	Value string
 }
@@ -230,8 +402,84 @@ func (s *SymbolLiteral) literalNode()    {}
 func (s *SymbolLiteral) TokenLiteral() string { return s.Token.Literal }
 func (s *SymbolLiteral) String() string       { return ":" + s.Token.Literal }
 
+// ObjectLiteral represents a literal which has been resolved to its value
+// before evaluation, e.g. by an optimizer. As the value is shared between
+// all evaluations of the literal it must be immutable.
+type ObjectLiteral struct {
+	Span
+	Token token.Token // the token of the resolved literal
+	Value interface{}
+}
+
+func (ol *ObjectLiteral) expressionNode() {}
+func (ol *ObjectLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal from the token of the resolved literal
+func (ol *ObjectLiteral) TokenLiteral() string { return ol.Token.Literal }
+func (ol *ObjectLiteral) String() string {
+	if stringer, ok := ol.Value.(interface{ Inspect() string }); ok {
+		return stringer.Inspect()
+	}
+	return ol.Token.Literal
+}
+
+// RegexLiteral represents a regular expression literal within the AST
+type RegexLiteral struct {
+	Span
+	Token   token.Token // the token.REGEX
+	Value   string      // the pattern between the slashes
+	Options string      // the option letters following the closing slash
+}
+
+func (rl *RegexLiteral) expressionNode() {}
+func (rl *RegexLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal from token token.REGEX
+func (rl *RegexLiteral) TokenLiteral() string { return rl.Token.Literal }
+func (rl *RegexLiteral) String() string       { return rl.Token.Literal }
+
+// CommandLiteral represents a command substitution in backticks or like
+// `%x(ls -l)` within the AST
+type CommandLiteral struct {
+	Span
+	Token token.Token // the token.XSTRING
+	Value string      // the command with its escape sequences resolved
+}
+
+func (cl *CommandLiteral) expressionNode() {}
+
+// TokenLiteral returns the literal from token token.XSTRING
+func (cl *CommandLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CommandLiteral) String() string       { return "`" + cl.Token.Literal + "`" }
+
+// RangeLiteral represents a range literal like `1..5` or `1...5` within the
+// AST
+type RangeLiteral struct {
+	Span
+	Token     token.Token // the token.DOT2 or token.DOT3
+	Left      Expression
+	Right     Expression
+	Exclusive bool // true for `...`
+}
+
+func (rl *RangeLiteral) expressionNode() {}
+func (rl *RangeLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal from token token.DOT2 or token.DOT3
+func (rl *RangeLiteral) TokenLiteral() string { return rl.Token.Literal }
+func (rl *RangeLiteral) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(rl.Left.String())
+	out.WriteString(rl.Token.Literal)
+	out.WriteString(rl.Right.String())
+	out.WriteString(")")
+	return out.String()
+}
+
 // IfExpression represents an if expression within the AST
 type IfExpression struct {
+	Span
 	Token       token.Token // The 'if' token
 	Condition   Expression
 	Consequence *BlockStatement
@@ -256,8 +504,161 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// CaseExpression represents a case expression within the AST
+type CaseExpression struct {
+	Span
+	Token   token.Token // The 'case' token
+	Subject Expression  // nil for case expressions without subject
+	Whens   []*WhenClause
+	Else    *BlockStatement
+}
+
+func (ce *CaseExpression) expressionNode() {}
+
+// TokenLiteral returns the literal from token token.CASE
+func (ce *CaseExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CaseExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("case")
+	if ce.Subject != nil {
+		out.WriteString(" ")
+		out.WriteString(ce.Subject.String())
+	}
+	for _, when := range ce.Whens {
+		out.WriteString(" ")
+		out.WriteString(when.String())
+	}
+	if ce.Else != nil {
+		out.WriteString(" else ")
+		out.WriteString(ce.Else.String())
+	}
+	out.WriteString(" end")
+	return out.String()
+}
+
+// A WhenClause represents a single `when` branch of a case expression
+type WhenClause struct {
+	Span
+	Token      token.Token // The 'when' token
+	Conditions []Expression
+	Body       *BlockStatement
+}
+
+// TokenLiteral returns the literal from token token.WHEN
+func (wc *WhenClause) TokenLiteral() string { return wc.Token.Literal }
+func (wc *WhenClause) String() string {
+	conditions := []string{}
+	for _, c := range wc.Conditions {
+		conditions = append(conditions, c.String())
+	}
+	var out bytes.Buffer
+	out.WriteString("when ")
+	out.WriteString(strings.Join(conditions, ", "))
+	out.WriteString(" then ")
+	out.WriteString(wc.Body.String())
+	return out.String()
+}
+
+// BeginExpression represents a `begin ... end` expression within the AST.
+// The Ensure block is nil if there is no ensure clause.
+type BeginExpression struct {
+	Span
+	Token   token.Token // The 'begin' token
+	Body    *BlockStatement
+	Rescues []*RescueBlock
+	Ensure  *BlockStatement
+}
+
+func (be *BeginExpression) expressionNode() {}
+
+// TokenLiteral returns the literal from token token.BEGIN
+func (be *BeginExpression) TokenLiteral() string { return be.Token.Literal }
+func (be *BeginExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("begin ")
+	out.WriteString(be.Body.String())
+	for _, rescue := range be.Rescues {
+		out.WriteString(" ")
+		out.WriteString(rescue.String())
+	}
+	if be.Ensure != nil {
+		out.WriteString(" ensure ")
+		out.WriteString(be.Ensure.String())
+	}
+	out.WriteString(" end")
+	return out.String()
+}
+
+// A RescueBlock represents a rescue clause of a begin expression. Without
+// exception classes it rescues StandardErrors. If Exception is set the
+// rescued exception is assigned to it.
+type RescueBlock struct {
+	Span
+	Token            token.Token // The rescue token
+	ExceptionClasses []Expression
+	Exception        *Identifier
+	Body             *BlockStatement
+}
+
+// TokenLiteral returns the literal from token.RESCUE
+func (rb *RescueBlock) TokenLiteral() string { return rb.Token.Literal }
+func (rb *RescueBlock) String() string {
+	var out bytes.Buffer
+	out.WriteString("rescue")
+	for i, class := range rb.ExceptionClasses {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		out.WriteString(" ")
+		out.WriteString(class.String())
+	}
+	if rb.Exception != nil {
+		out.WriteString(" => ")
+		out.WriteString(rb.Exception.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(rb.Body.String())
+	return out.String()
+}
+
+// A Splat represents an expression expanded into a list, like `*errors`
+type Splat struct {
+	Span
+	Token token.Token // The '*' token
+	Value Expression
+}
+
+func (s *Splat) expressionNode() {}
+
+// TokenLiteral returns the literal from token.ASTERISK
+func (s *Splat) TokenLiteral() string { return s.Token.Literal }
+func (s *Splat) String() string       { return "*" + s.Value.String() }
+
+// WhileExpression represents a while loop within the AST
+type WhileExpression struct {
+	Span
+	Token     token.Token // The 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode() {}
+
+// TokenLiteral returns the literal from token token.WHILE
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("while ")
+	out.WriteString(we.Condition.String())
+	out.WriteString(" do ")
+	out.WriteString(we.Body.String())
+	out.WriteString(" end")
+	return out.String()
+}
+
 // ArrayLiteral represents an Array literal within the AST
 type ArrayLiteral struct {
+	Span
 	Token    token.Token // the '['
 	Elements []Expression
 }
@@ -279,12 +680,39 @@ func (al *ArrayLiteral) String() string {
 	return out.String()
 }
 
+// HashLiteral represents a Hash literal within the AST
+type HashLiteral struct {
+	Span
+	Token  token.Token // the '{'
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+func (hl *HashLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal of the token token.LBRACE
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for i, key := range hl.Keys {
+		pairs = append(pairs, key.String()+" => "+hl.Values[i].String())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
 // A FunctionLiteral represents a function definition in the AST
 type FunctionLiteral struct {
+	Span
 	Token      token.Token // The 'def' token
 	Name       *Identifier
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Scope      *Scope // the local variables of the method, once resolved
 }
 
 func (fl *FunctionLiteral) expressionNode() {}
@@ -309,11 +737,120 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// A ClassExpression represents a class definition or the reopening of an
+// existing class, like `class Foo < Bar ... end`
+type ClassExpression struct {
+	Span
+	Token      token.Token // The 'class' token
+	Name       *Identifier
+	SuperClass Expression // nil if no superclass is given
+	Body       *BlockStatement
+}
+
+func (ce *ClassExpression) expressionNode() {}
+
+// TokenLiteral returns the literal from token.CLASS
+func (ce *ClassExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ClassExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("class ")
+	out.WriteString(ce.Name.String())
+	if ce.SuperClass != nil {
+		out.WriteString(" < ")
+		out.WriteString(ce.SuperClass.String())
+	}
+	out.WriteString(" ")
+	out.WriteString(ce.Body.String())
+	out.WriteString(" end")
+	return out.String()
+}
+
+// A ModuleExpression represents a module definition or the reopening of an
+// existing module
+type ModuleExpression struct {
+	Span
+	Token token.Token // The 'module' token
+	Name  *Identifier
+	Body  *BlockStatement
+}
+
+func (me *ModuleExpression) expressionNode() {}
+
+// TokenLiteral returns the literal from token.MODULE
+func (me *ModuleExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *ModuleExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("module ")
+	out.WriteString(me.Name.String())
+	out.WriteString(" ")
+	out.WriteString(me.Body.String())
+	out.WriteString(" end")
+	return out.String()
+}
+
+// A Super represents a call of the overridden method. If Implicit is true
+// the call had no argument list and passes on the arguments of the current
+// method.
+type Super struct {
+	Span
+	Token     token.Token // The 'super' token
+	Arguments []Expression
+	Implicit  bool
+}
+
+func (s *Super) expressionNode() {}
+
+// TokenLiteral returns the literal from token.SUPER
+func (s *Super) TokenLiteral() string { return s.Token.Literal }
+func (s *Super) String() string {
+	if s.Implicit {
+		return "super"
+	}
+	args := []string{}
+	for _, a := range s.Arguments {
+		args = append(args, a.String())
+	}
+	return "super(" + strings.Join(args, ", ") + ")"
+}
+
+// A BlockLiteral represents a block passed to a method call, i.e.
+// `{ |x| x }` or `do |x| x end`
+type BlockLiteral struct {
+	Span
+	Token      token.Token // The '{' or 'do' token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (bl *BlockLiteral) expressionNode() {}
+func (bl *BlockLiteral) literalNode()    {}
+
+// TokenLiteral returns the literal from token.LBRACE or token.DO
+func (bl *BlockLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BlockLiteral) String() string {
+	var out bytes.Buffer
+	params := []string{}
+	for _, p := range bl.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString("{ ")
+	if len(params) != 0 {
+		out.WriteString("|")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString("| ")
+	}
+	out.WriteString(bl.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
 // An IndexExpression represents an array or hash access in the AST
 type IndexExpression struct {
-	Token token.Token // The [ token
-	Left  Expression
-	Index Expression
+	Span
+	Token  token.Token // The [ token
+	Left   Expression
+	Index  Expression
+	Length Expression // the optional length, like in `arr[2, 3]`
 }
 
 func (ie *IndexExpression) expressionNode() {}
@@ -326,16 +863,22 @@ func (ie *IndexExpression) String() string {
 	out.WriteString(ie.Left.String())
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
+	if ie.Length != nil {
+		out.WriteString(", ")
+		out.WriteString(ie.Length.String())
+	}
 	out.WriteString("])")
 	return out.String()
 }
 
 // A ContextCallExpression represents a method call on a given Context
 type ContextCallExpression struct {
-	Token     token.Token  // The '.' token
-	Context   Expression   // The lefthandside expression
-	Function  *Identifier  // The function to call
-	Arguments []Expression // The function arguments
+	Span
+	Token     token.Token   // The '.' token
+	Context   Expression    // The lefthandside expression
+	Function  *Identifier   // The function to call
+	Arguments []Expression  // The function arguments
+	Block     *BlockLiteral // The block passed to the function, if any
 }
 
 func (ce *ContextCallExpression) expressionNode() {}
@@ -356,11 +899,16 @@ func (ce *ContextCallExpression) String() string {
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
+	if ce.Block != nil {
+		out.WriteString(" ")
+		out.WriteString(ce.Block.String())
+	}
 	return out.String()
 }
 
 // PrefixExpression represents a prefix operator
 type PrefixExpression struct {
+	Span
 	Token    token.Token // The prefix token, e.g. !
 	Operator string
 	Right    Expression
@@ -381,6 +929,7 @@ func (pe *PrefixExpression) String() string {
 
 // An InfixExpression represents an infix operator in the AST
 type InfixExpression struct {
+	Span
 	Token    token.Token // The operator token, e.g. +
 	Left     Expression
 	Operator string
@@ -401,7 +950,71 @@ func (oe *InfixExpression) String() string {
 	return out.String()
 }
 
+// A BeginBlock represents a `BEGIN { ... }` block which runs before the rest
+// of the program
+type BeginBlock struct {
+	Span
+	Token token.Token // The BEGIN token
+	Body  *BlockStatement
+}
+
+func (bb *BeginBlock) statementNode() {}
+
+// TokenLiteral returns the literal from token.BEGIN_BLOCK
+func (bb *BeginBlock) TokenLiteral() string { return bb.Token.Literal }
+func (bb *BeginBlock) String() string {
+	var out bytes.Buffer
+	out.WriteString("BEGIN { ")
+	out.WriteString(bb.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
+// An EndBlock represents an `END { ... }` block which is registered to run
+// when the program exits
+type EndBlock struct {
+	Span
+	Token token.Token // The END token
+	Body  *BlockStatement
+}
+
+func (eb *EndBlock) statementNode() {}
+
+// TokenLiteral returns the literal from token.END_BLOCK
+func (eb *EndBlock) TokenLiteral() string { return eb.Token.Literal }
+func (eb *EndBlock) String() string {
+	var out bytes.Buffer
+	out.WriteString("END { ")
+	out.WriteString(eb.Body.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
+// A RescueModifier represents an expression followed by the rescue modifier,
+// i.e. `expression rescue rescue_expression`
+type RescueModifier struct {
+	Span
+	Token      token.Token // The rescue token
+	Expression Expression  // The expression to evaluate
+	Rescue     Expression  // The expression to evaluate if Expression raises
+}
+
+func (rm *RescueModifier) expressionNode() {}
+
+// TokenLiteral returns the literal from token.RESCUE
+func (rm *RescueModifier) TokenLiteral() string { return rm.Token.Literal }
+func (rm *RescueModifier) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(rm.Expression.String())
+	out.WriteString(" rescue ")
+	out.WriteString(rm.Rescue.String())
+	out.WriteString(")")
+	return out.String()
+}
+
 type RequireExpression struct {
+	Span
 	Token token.Token // The require token
 	Name  *StringLiteral
 }
@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/goruby/goruby/token"
+)
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Fprint writes the tree of node to w, one node per line and indented by
+// its depth. Each node is written with its type, the line of its token if
+// it has one, and the fields which are neither nodes nor tokens, like the
+// values of literals. Fields holding their zero value are left out, except
+// for values. Nodes whose span the parser recorded are written along with
+// it as start and end line:column.
+func Fprint(w io.Writer, node Node) error {
+	p := &printer{w: w}
+	p.print(node, "", 0)
+	return p.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) print(node Node, label string, depth int) {
+	value := reflect.ValueOf(node)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	var line strings.Builder
+	line.WriteString(strings.Repeat("  ", depth) + label + value.Type().Name())
+	if value.Kind() != reflect.Struct {
+		p.writeLine(line.String())
+		return
+	}
+	type child struct {
+		label string
+		node  Node
+	}
+	var children []child
+	for i := 0; i < value.NumField(); i++ {
+		field, name := value.Field(i), value.Type().Field(i).Name
+		if !field.CanInterface() {
+			continue
+		}
+		switch {
+		case field.Type() == tokenType:
+			if tok := field.Interface().(token.Token); tok.Line != 0 {
+				fmt.Fprintf(&line, " (line %d)", tok.Line)
+			}
+		case field.Kind() == reflect.Slice:
+			for j := 0; j < field.Len(); j++ {
+				if node, ok := nodeOf(field.Index(j)); ok {
+					children = append(children, child{fmt.Sprintf("%s[%d]: ", name, j), node})
+				}
+			}
+		case field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface:
+			if node, ok := nodeOf(field); ok {
+				children = append(children, child{name + ": ", node})
+			} else if field.Kind() == reflect.Interface && !field.IsNil() && name == "Value" {
+				fmt.Fprintf(&line, " %s=%v", name, field.Interface())
+			}
+		case field.Kind() == reflect.String:
+			if field.String() != "" || name == "Value" {
+				fmt.Fprintf(&line, " %s=%q", name, field.String())
+			}
+		case field.Kind() == reflect.Map || field.Kind() == reflect.Struct:
+		default:
+			if !field.IsZero() || name == "Value" {
+				fmt.Fprintf(&line, " %s=%v", name, field.Interface())
+			}
+		}
+	}
+	if node, ok := node.(spanned); ok && node.span().start.IsValid() {
+		span := node.span()
+		fmt.Fprintf(&line, " [%d:%d-%d:%d]", span.start.Line, span.start.Column, span.end.Line, span.end.Column)
+	}
+	p.writeLine(line.String())
+	for _, child := range children {
+		p.print(child.node, child.label, depth+1)
+	}
+}
+
+func (p *printer) writeLine(line string) {
+	if p.err == nil {
+		_, p.err = io.WriteString(p.w, line+"\n")
+	}
+}
+
+// nodeOf returns the Node held by value, if it holds one
+func nodeOf(value reflect.Value) (Node, bool) {
+	if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil() {
+		return nil, false
+	}
+	node, ok := value.Interface().(Node)
+	return node, ok
+}
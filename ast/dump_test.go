@@ -0,0 +1,49 @@
+package ast
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goruby/goruby/token"
+)
+
+func TestFprint(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "x", Line: 1},
+				Expression: &VariableAssignment{
+					Name: &Identifier{
+						Token: token.Token{Type: token.IDENT, Literal: "x", Line: 1},
+						Value: "x",
+					},
+					Value: &InfixExpression{
+						Token:    token.Token{Type: token.PLUS, Literal: "+", Line: 2},
+						Left:     &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "0", Line: 1}},
+						Operator: "+",
+						Right:    &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a", Line: 2}, Value: "a"},
+					},
+				},
+			},
+		},
+	}
+	statement := program.Statements[0]
+	SetSpan(statement, Position{Offset: 0, Line: 1, Column: 1}, Position{Offset: 12, Line: 2, Column: 4})
+
+	var out bytes.Buffer
+	if err := Fprint(&out, program); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Program
+  Statements[0]: ExpressionStatement (line 1) [1:1-2:4]
+    Expression: VariableAssignment
+      Name: Identifier (line 1) Value="x"
+      Value: InfixExpression (line 2) Operator="+"
+        Left: IntegerLiteral (line 1) Value=0
+        Right: StringLiteral (line 2) Value="a"
+`
+	if out.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,182 @@
+package ast
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goruby/goruby/token"
+)
+
+// A Position is a position within the source of an AST
+type Position struct {
+	Offset int // the byte offset within the source, counting from 0
+	Line   int // the line, counting from 1
+	Column int // the character within the line, counting from 1
+}
+
+// IsValid reports whether the position is known
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// A Span holds the positions a node starts and ends at. It is embedded
+// into all nodes but Program and set by the parser.
+type Span struct {
+	start, end Position
+}
+
+func (s *Span) span() *Span { return s }
+
+type spanned interface {
+	span() *Span
+}
+
+// SetSpan records that node starts at start and ends right before end
+func SetSpan(node Node, start, end Position) {
+	if value := reflect.ValueOf(node); value.Kind() == reflect.Ptr && value.IsNil() {
+		return
+	}
+	if node, ok := node.(spanned); ok {
+		*node.span() = Span{start: start, end: end}
+	}
+}
+
+// Pos returns the position node starts at. Unless the parser recorded it,
+// this is the position of the leftmost of the tokens of node and its
+// children. It returns an invalid Position if none of them knows its
+// position, as with nodes built by hand.
+func Pos(node Node) Position {
+	if node, ok := node.(spanned); ok && node.span().start.IsValid() {
+		return node.span().start
+	}
+	var pos Position
+	eachToken(node, func(tok token.Token) {
+		if !pos.IsValid() || tok.Pos < pos.Offset {
+			pos = TokenPos(tok)
+		}
+	})
+	return pos
+}
+
+// End returns the position right after the end of node. Unless the
+// parser recorded it, this is the end of the rightmost of the tokens of
+// node and its children. It returns an invalid Position if none of them
+// knows its position.
+func End(node Node) Position {
+	if node, ok := node.(spanned); ok && node.span().end.IsValid() {
+		return node.span().end
+	}
+	var end Position
+	eachToken(node, func(tok token.Token) {
+		if tokenEnd := TokenEnd(tok); !end.IsValid() || tokenEnd.Offset > end.Offset {
+			end = tokenEnd
+		}
+	})
+	return end
+}
+
+// eachToken calls f for each token of node and its children which knows
+// its position
+func eachToken(node Node, f func(token.Token)) {
+	Inspect(node, func(node Node) bool {
+		value := reflect.ValueOf(node)
+		if value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return true
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if field.Type() != tokenType || !field.CanInterface() {
+				continue
+			}
+			if tok := field.Interface().(token.Token); tok.Line != 0 && tok.Pos >= 0 {
+				f(tok)
+			}
+		}
+		return true
+	})
+}
+
+// TokenPos returns the position tok starts at
+func TokenPos(tok token.Token) Position {
+	return Position{Offset: tok.Pos, Line: tok.Line, Column: tok.Column}
+}
+
+// TokenEnd returns the position right after the literal of tok. The
+// closing delimiters of literals, like the quote ending a string, are not
+// part of it.
+func TokenEnd(tok token.Token) Position {
+	end := Position{
+		Offset: tok.Pos + len(tok.Literal),
+		Line:   tok.Line + strings.Count(tok.Literal, "\n"),
+		Column: tok.Column + utf8.RuneCountInString(tok.Literal),
+	}
+	if newline := strings.LastIndexByte(tok.Literal, '\n'); newline >= 0 {
+		end.Column = utf8.RuneCountInString(tok.Literal[newline+1:]) + 1
+	}
+	return end
+}
+
+// A Comment is a comment of the source, either a line comment or an
+// embedded document between `=begin` and `=end`
+type Comment struct {
+	Pos  Position
+	Text string // the comment including its delimiters, up to the line end
+}
+
+// A CommentMap maps the nodes of an AST to the comments attached to them
+type CommentMap map[Node][]*Comment
+
+// NewCommentMap attaches the comments of program to its statements, other
+// than the block statements grouping them. A comment following a statement
+// on its line is attached to the outermost statement starting on that
+// line, any other comment to the outermost statement starting after it.
+// Comments following the last statement are attached to program itself.
+func NewCommentMap(program *Program) CommentMap {
+	var statements []Statement
+	var positions []Position
+	Inspect(program, func(node Node) bool {
+		if _, isBlock := node.(*BlockStatement); isBlock {
+			return true
+		}
+		if statement, ok := node.(Statement); ok {
+			if pos := Pos(statement); pos.IsValid() {
+				statements = append(statements, statement)
+				positions = append(positions, pos)
+			}
+		}
+		return true
+	})
+	order := make([]int, len(statements))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return positions[order[i]].Offset < positions[order[j]].Offset
+	})
+
+	comments := make(CommentMap)
+	for _, comment := range program.Comments {
+		var target Node = program
+		for _, i := range order {
+			if positions[i].Line == comment.Pos.Line && positions[i].Offset < comment.Pos.Offset {
+				target = statements[i]
+				break
+			}
+		}
+		if target == program {
+			for _, i := range order {
+				if positions[i].Offset > comment.Pos.Offset {
+					target = statements[i]
+					break
+				}
+			}
+		}
+		comments[target] = append(comments[target], comment)
+	}
+	return comments
+}
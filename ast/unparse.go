@@ -0,0 +1,607 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unparseIndent is the indentation of a nesting level of unparsed source
+const unparseIndent = "  "
+
+// Unparse returns Ruby source code for node, which parses back to an AST
+// equal to node apart from the positions of its tokens. Statements are
+// written one per line and indented by their nesting level. The comments
+// of a Program are written along with the statements NewCommentMap
+// attaches them to.
+//
+// The literals are written from their values, not from the literals of
+// their tokens, so that nodes changed by a rewrite are written as they are.
+func Unparse(node Node) string {
+	u := &unparser{}
+	if program, ok := node.(*Program); ok {
+		u.comments = NewCommentMap(program)
+	}
+	u.node(node)
+	return u.out.String()
+}
+
+type unparser struct {
+	out      strings.Builder
+	depth    int
+	comments CommentMap
+}
+
+func (u *unparser) write(s ...string) {
+	for _, part := range s {
+		u.out.WriteString(part)
+	}
+}
+
+// newline ends the current line and indents the next one
+func (u *unparser) newline() {
+	u.write("\n", strings.Repeat(unparseIndent, u.depth))
+}
+
+// inline returns node unparsed on its own and whether it fits on a line
+func (u *unparser) inline(node Node) (string, bool) {
+	nested := &unparser{comments: u.comments}
+	nested.node(node)
+	source := nested.out.String()
+	return source, !strings.Contains(source, "\n") && len(u.comments[node]) == 0
+}
+
+func (u *unparser) node(node Node) {
+	switch node := node.(type) {
+	case *Program:
+		u.program(node)
+	case *BlockStatement:
+		u.statements(node.Statements)
+	case Statement:
+		u.statement(node)
+	case *WhenClause:
+		u.whenClause(node)
+	case *RescueBlock:
+		u.rescueBlock(node)
+	case *BlockLiteral:
+		u.block(node)
+	case Expression:
+		u.expression(node)
+	}
+}
+
+func (u *unparser) program(program *Program) {
+	for i, statement := range program.Statements {
+		if i > 0 {
+			u.write("\n")
+			u.separate(program.Statements[i-1], statement)
+		}
+		u.leadingComments(statement)
+		u.statement(statement)
+		u.trailingComments(statement)
+	}
+	for _, comment := range u.comments[program] {
+		if u.out.Len() > 0 {
+			u.write("\n")
+		}
+		u.write(comment.Text)
+	}
+	if u.out.Len() > 0 {
+		u.write("\n")
+	}
+	if program.HasData {
+		u.write("__END__\n", program.Data)
+	}
+}
+
+// body writes the statements of block indented by one level, each on its
+// own line. block may be nil.
+func (u *unparser) body(block *BlockStatement) {
+	u.depth++
+	if block != nil {
+		for i, statement := range block.Statements {
+			if i > 0 {
+				u.separate(block.Statements[i-1], statement)
+			}
+			u.newline()
+			u.leadingComments(statement)
+			u.statement(statement)
+			u.trailingComments(statement)
+		}
+	}
+	u.depth--
+	u.newline()
+}
+
+// statements writes statements on separate lines at the current depth
+func (u *unparser) statements(statements []Statement) {
+	for i, statement := range statements {
+		if i > 0 {
+			u.separate(statements[i-1], statement)
+			u.newline()
+		}
+		u.leadingComments(statement)
+		u.statement(statement)
+		u.trailingComments(statement)
+	}
+}
+
+// separate writes an empty line between the statements previous and next
+// if the source they were parsed from separated them by one
+func (u *unparser) separate(previous, next Statement) {
+	end, start := End(previous), Pos(next)
+	for _, comment := range u.comments[next] {
+		if comment.Pos.Offset < start.Offset {
+			start = comment.Pos
+			break
+		}
+	}
+	if end.IsValid() && start.IsValid() && start.Line > end.Line+1 {
+		u.write("\n")
+	}
+}
+
+// leadingComments writes the comments attached to statement which precede
+// it, each on its own line
+func (u *unparser) leadingComments(statement Statement) {
+	pos := Pos(statement)
+	for _, comment := range u.comments[statement] {
+		if comment.Pos.Offset > pos.Offset {
+			continue
+		}
+		if strings.HasPrefix(comment.Text, "=begin") {
+			// embedded documents must start at the beginning of a line
+			u.trimIndent()
+			u.write(comment.Text)
+			u.newline()
+			continue
+		}
+		u.write(comment.Text)
+		u.newline()
+	}
+}
+
+// trimIndent removes the indentation of the current line
+func (u *unparser) trimIndent() {
+	source := u.out.String()
+	trimmed := strings.TrimRight(source, " ")
+	if len(trimmed) == len(source) || (len(trimmed) > 0 && trimmed[len(trimmed)-1] != '\n') {
+		return
+	}
+	u.out.Reset()
+	u.out.WriteString(trimmed)
+}
+
+// trailingComments writes the comments attached to statement which follow
+// it on its line
+func (u *unparser) trailingComments(statement Statement) {
+	pos := Pos(statement)
+	for _, comment := range u.comments[statement] {
+		if comment.Pos.Offset > pos.Offset {
+			u.write(" ", comment.Text)
+		}
+	}
+}
+
+func (u *unparser) statement(statement Statement) {
+	switch statement := statement.(type) {
+	case *ExpressionStatement:
+		if statement.Expression != nil {
+			u.expression(statement.Expression)
+		}
+	case *ReturnStatement:
+		u.write("return")
+		if statement.ReturnValue != nil {
+			u.write(" ")
+			u.expression(statement.ReturnValue)
+		}
+	case *BreakStatement:
+		u.write("break")
+		if statement.Value != nil {
+			u.write(" ")
+			u.expression(statement.Value)
+		}
+	case *NextStatement:
+		u.write("next")
+		if statement.Value != nil {
+			u.write(" ")
+			u.expression(statement.Value)
+		}
+	case *BlockStatement:
+		u.statements(statement.Statements)
+	case *BeginBlock:
+		u.write("BEGIN {")
+		u.body(statement.Body)
+		u.write("}")
+	case *EndBlock:
+		u.write("END {")
+		u.body(statement.Body)
+		u.write("}")
+	}
+}
+
+func (u *unparser) expression(expression Expression) {
+	switch node := expression.(type) {
+	case *Identifier:
+		u.write(node.Value)
+	case *Self:
+		u.write("self")
+	case *Nil:
+		u.write("nil")
+	case *Boolean:
+		u.write(strconv.FormatBool(node.Value))
+	case *IntegerLiteral:
+		u.write(strconv.FormatInt(node.Value, 10))
+	case *FloatLiteral:
+		u.write(unparseFloat(node.Value))
+	case *StringLiteral:
+		u.write(quote(node.Value, '"'))
+	case *SymbolLiteral:
+		u.write(unparseSymbol(node.Value))
+	case *RegexLiteral:
+		u.write("/", node.Value, "/", node.Options)
+	case *CommandLiteral:
+		u.write(quote(node.Value, '`'))
+	case *ObjectLiteral:
+		if inspectable, ok := node.Value.(interface{ Inspect() string }); ok {
+			u.write(inspectable.Inspect())
+		} else {
+			u.write(node.Token.Literal)
+		}
+	case *ScopedConstant:
+		if node.Scope != nil {
+			u.operand(node.Scope, true)
+		}
+		u.write("::", node.Name)
+	case *RangeLiteral:
+		u.write("(")
+		if node.Left != nil {
+			u.operand(node.Left, false)
+		}
+		if node.Exclusive {
+			u.write("...")
+		} else {
+			u.write("..")
+		}
+		if node.Right != nil {
+			u.operand(node.Right, false)
+		}
+		u.write(")")
+	case *ArrayLiteral:
+		u.write("[")
+		u.list(node.Elements)
+		u.write("]")
+	case *HashLiteral:
+		u.write("{")
+		for i, key := range node.Keys {
+			if i > 0 {
+				u.write(", ")
+			}
+			u.operand(key, false)
+			u.write(" => ")
+			u.expression(node.Values[i])
+		}
+		u.write("}")
+	case *Splat:
+		u.write("*")
+		u.operand(node.Value, false)
+	case *VariableAssignment:
+		u.write(node.Name.Value, " = ")
+		u.expression(node.Value)
+	case *IndexAssignment:
+		u.expression(node.Target)
+		u.write(" = ")
+		u.expression(node.Value)
+	case *IndexExpression:
+		u.operand(node.Left, true)
+		u.write("[")
+		u.expression(node.Index)
+		if node.Length != nil {
+			u.write(", ")
+			u.expression(node.Length)
+		}
+		u.write("]")
+	case *PrefixExpression:
+		u.write(node.Operator)
+		u.operand(node.Right, true)
+	case *InfixExpression:
+		u.operand(node.Left, node.Operator == "**")
+		u.write(" ", node.Operator, " ")
+		u.operand(node.Right, node.Operator == "**")
+	case *RescueModifier:
+		u.operand(node.Expression, false)
+		u.write(" rescue ")
+		u.operand(node.Rescue, false)
+	case *ContextCallExpression:
+		u.call(node)
+	case *Super:
+		u.write("super")
+		if !node.Implicit {
+			u.write("(")
+			u.list(node.Arguments)
+			u.write(")")
+		}
+	case *RequireExpression:
+		u.write("require ", quote(node.Name.Value, '"'))
+	case *IfExpression:
+		u.write("if ")
+		u.expression(node.Condition)
+		u.body(node.Consequence)
+		if node.Alternative != nil {
+			u.write("else")
+			u.body(node.Alternative)
+		}
+		u.write("end")
+	case *WhileExpression:
+		u.write("while ")
+		u.expression(node.Condition)
+		u.body(node.Body)
+		u.write("end")
+	case *CaseExpression:
+		u.write("case")
+		if node.Subject != nil {
+			u.write(" ")
+			u.expression(node.Subject)
+		}
+		u.newline()
+		for _, when := range node.Whens {
+			u.whenClause(when)
+		}
+		if node.Else != nil {
+			u.write("else")
+			u.body(node.Else)
+		}
+		u.write("end")
+	case *BeginExpression:
+		u.write("begin")
+		u.rescueBody(node)
+		u.write("end")
+	case *FunctionLiteral:
+		u.write("def ", node.Name.Value)
+		if len(node.Parameters) > 0 {
+			u.write("(")
+			u.parameters(node.Parameters)
+			u.write(")")
+		}
+		if begin, ok := implicitBegin(node.Body); ok {
+			u.rescueBody(begin)
+		} else {
+			u.body(node.Body)
+		}
+		u.write("end")
+	case *ClassExpression:
+		u.write("class ", node.Name.Value)
+		if node.SuperClass != nil {
+			u.write(" < ")
+			u.expression(node.SuperClass)
+		}
+		u.body(node.Body)
+		u.write("end")
+	case *ModuleExpression:
+		u.write("module ", node.Name.Value)
+		u.body(node.Body)
+		u.write("end")
+	}
+}
+
+// operand writes expression, in parentheses unless it binds tighter than
+// any binary operator. Unary operators and negative numbers are put into
+// parentheses too if tight is set, as for the receiver of a call or the
+// operands of `**`.
+func (u *unparser) operand(expression Expression, tight bool) {
+	parenthesize := false
+	switch node := expression.(type) {
+	case *InfixExpression, *RescueModifier, *VariableAssignment, *IndexAssignment,
+		*IfExpression, *WhileExpression, *CaseExpression, *BeginExpression, *FunctionLiteral,
+		*ClassExpression, *ModuleExpression, *Splat, *RequireExpression:
+		parenthesize = true
+	case *PrefixExpression:
+		parenthesize = tight
+	case *IntegerLiteral:
+		parenthesize = tight && node.Value < 0
+	case *FloatLiteral:
+		parenthesize = tight && (node.Value < 0 || math.IsInf(node.Value, -1))
+	}
+	if parenthesize {
+		u.write("(")
+	}
+	u.expression(expression)
+	if parenthesize {
+		u.write(")")
+	}
+}
+
+func (u *unparser) list(expressions []Expression) {
+	for i, expression := range expressions {
+		if i > 0 {
+			u.write(", ")
+		}
+		u.expression(expression)
+	}
+}
+
+func (u *unparser) parameters(parameters []*Identifier) {
+	for i, parameter := range parameters {
+		if i > 0 {
+			u.write(", ")
+		}
+		u.write(parameter.Value)
+	}
+}
+
+func (u *unparser) call(call *ContextCallExpression) {
+	name := call.Function.Value
+	if call.Context != nil {
+		u.operand(call.Context, true)
+		u.write(".")
+	}
+	if call.Context != nil && isSetter(name) && len(call.Arguments) == 1 && call.Block == nil {
+		u.write(strings.TrimSuffix(name, "="), " = ")
+		u.expression(call.Arguments[0])
+		return
+	}
+	u.write(name)
+	if len(call.Arguments) > 0 || call.Context == nil {
+		u.write("(")
+		u.list(call.Arguments)
+		u.write(")")
+	}
+	if call.Block != nil {
+		u.write(" ")
+		u.block(call.Block)
+	}
+}
+
+// block writes a block in braces, on a single line if its body fits
+func (u *unparser) block(block *BlockLiteral) {
+	u.write("{")
+	if len(block.Parameters) > 0 {
+		u.write(" |")
+		u.parameters(block.Parameters)
+		u.write("|")
+	}
+	if block.Body == nil || len(block.Body.Statements) == 0 {
+		u.write(" }")
+		return
+	}
+	if len(block.Body.Statements) == 1 {
+		if source, ok := u.inline(block.Body.Statements[0]); ok {
+			u.write(" ", source, " }")
+			return
+		}
+	}
+	u.body(block.Body)
+	u.write("}")
+}
+
+func (u *unparser) whenClause(when *WhenClause) {
+	u.write("when ")
+	u.list(when.Conditions)
+	u.body(when.Body)
+}
+
+// rescueBody writes the body of begin with its rescue and ensure clauses,
+// each on its own line
+func (u *unparser) rescueBody(begin *BeginExpression) {
+	u.body(begin.Body)
+	for _, rescue := range begin.Rescues {
+		u.rescueBlock(rescue)
+	}
+	if begin.Ensure != nil {
+		u.write("ensure")
+		u.body(begin.Ensure)
+	}
+}
+
+func (u *unparser) rescueBlock(rescue *RescueBlock) {
+	u.write("rescue")
+	if len(rescue.ExceptionClasses) > 0 {
+		u.write(" ")
+		u.list(rescue.ExceptionClasses)
+	}
+	if rescue.Exception != nil {
+		u.write(" => ", rescue.Exception.Value)
+	}
+	u.body(rescue.Body)
+}
+
+// implicitBegin returns the begin expression a method body with rescue or
+// ensure clauses is wrapped in, as the parser does for bodies without an
+// explicit `begin`
+func implicitBegin(body *BlockStatement) (*BeginExpression, bool) {
+	if body == nil || len(body.Statements) != 1 {
+		return nil, false
+	}
+	statement, ok := body.Statements[0].(*ExpressionStatement)
+	if !ok {
+		return nil, false
+	}
+	begin, ok := statement.Expression.(*BeginExpression)
+	if !ok || begin.Token.Literal == "begin" || begin.Body == nil {
+		return nil, false
+	}
+	return begin, true
+}
+
+// isSetter reports whether name is the name of an attribute writer like
+// `name=`, as opposed to an operator
+func isSetter(name string) bool {
+	if !strings.HasSuffix(name, "=") || len(name) < 2 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func unparseFloat(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "Float::NAN"
+	case math.IsInf(value, 1):
+		return "Float::INFINITY"
+	case math.IsInf(value, -1):
+		return "-Float::INFINITY"
+	}
+	if magnitude := math.Abs(value); magnitude >= 1e16 || magnitude != 0 && magnitude < 1e-4 {
+		source := strconv.FormatFloat(value, 'e', -1, 64)
+		if !strings.Contains(source, ".") {
+			source = strings.Replace(source, "e", ".0e", 1)
+		}
+		return source
+	}
+	source := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(source, ".") {
+		source += ".0"
+	}
+	return source
+}
+
+// plainSymbol matches the symbols which need no quotes
+var plainSymbol = regexp.MustCompile(`^(?:[@$]?[\p{L}_][\p{L}\p{N}_]*[?!=]?|\[\]=?|<=>|===?|=~|!=|!~|<<|>>|<=|>=|\*\*|[+-]@?|[*/%<>!&|^~])$`)
+
+func unparseSymbol(value string) string {
+	if plainSymbol.MatchString(value) {
+		return ":" + value
+	}
+	return ":" + quote(value, '"')
+}
+
+// quote returns s quoted by delimiter, with the characters escaped which
+// are special within double quoted strings
+func quote(s string, delimiter rune) string {
+	var out strings.Builder
+	out.WriteRune(delimiter)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&out, "\\x%02X", s[i])
+		case r == delimiter || r == '\\':
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		case r == '#' && i+1 < len(s) && strings.ContainsRune("{@$", rune(s[i+1])):
+			out.WriteString("\\#")
+		case r == '\n':
+			out.WriteString("\\n")
+		case r == '\t':
+			out.WriteString("\\t")
+		case r == '\r':
+			out.WriteString("\\r")
+		case r == '\x1b':
+			out.WriteString("\\e")
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&out, "\\x%02X", r)
+		case !unicode.IsPrint(r) && r > 0x7f:
+			fmt.Fprintf(&out, "\\u{%X}", r)
+		default:
+			out.WriteRune(r)
+		}
+		i += size
+	}
+	out.WriteRune(delimiter)
+	return out.String()
+}
@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/goruby/goruby/token"
+)
+
+func TestUnparse(t *testing.T) {
+	ident := func(name string) *Identifier { return &Identifier{Value: name} }
+	integer := func(value int64) *IntegerLiteral { return &IntegerLiteral{Value: value} }
+	call := func(context Expression, name string, args ...Expression) *ContextCallExpression {
+		return &ContextCallExpression{Context: context, Function: ident(name), Arguments: args}
+	}
+
+	tests := []struct {
+		node     Node
+		expected string
+	}{
+		{&StringLiteral{Value: "a\"b\\c\n#{d}#e\x00"}, `"a\"b\\c\n\#{d}#e\x00"`},
+		{&CommandLiteral{Value: "echo `x`"}, "`echo \\`x\\``"},
+		{&SymbolLiteral{Value: "foo?"}, ":foo?"},
+		{&SymbolLiteral{Value: "<=>"}, ":<=>"},
+		{&SymbolLiteral{Value: "a b"}, `:"a b"`},
+		{&FloatLiteral{Value: 2}, "2.0"},
+		{&FloatLiteral{Value: 1e20}, "1.0e+20"},
+		{&FloatLiteral{Value: math.Inf(-1)}, "-Float::INFINITY"},
+		{&InfixExpression{
+			Left:     &InfixExpression{Left: integer(1), Operator: "+", Right: integer(2)},
+			Operator: "*",
+			Right:    &PrefixExpression{Operator: "-", Right: integer(3)},
+		}, "(1 + 2) * -3"},
+		{&InfixExpression{Left: integer(-2), Operator: "**", Right: integer(2)}, "(-2) ** 2"},
+		{call(integer(-1), "abs"), "(-1).abs"},
+		{call(nil, "foo"), "foo()"},
+		{call(&Self{}, "name=", &StringLiteral{Value: "x"}), `self.name = "x"`},
+		{call(ident("a"), "==", integer(1)), "a.==(1)"},
+		{&IndexAssignment{
+			Target: &IndexExpression{Left: ident("h"), Index: &SymbolLiteral{Value: "a"}},
+			Value:  &RangeLiteral{Left: integer(1), Right: integer(5), Exclusive: true},
+		}, "h[:a] = (1...5)"},
+		{&HashLiteral{Keys: []Expression{&SymbolLiteral{Value: "a"}}, Values: []Expression{&ArrayLiteral{}}}, "{:a => []}"},
+		{&ContextCallExpression{
+			Context:  ident("list"),
+			Function: ident("each"),
+			Block: &BlockLiteral{
+				Parameters: []*Identifier{ident("x")},
+				Body:       &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: call(nil, "puts", ident("x"))}}},
+			},
+		}, "list.each { |x| puts(x) }"},
+		{&FunctionLiteral{
+			Name:       ident("foo"),
+			Parameters: []*Identifier{ident("a")},
+			Body: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: &IfExpression{
+				Condition:   ident("a"),
+				Consequence: &BlockStatement{Statements: []Statement{&ReturnStatement{ReturnValue: integer(1)}}},
+				Alternative: &BlockStatement{},
+			}}}},
+		}, "def foo(a)\n  if a\n    return 1\n  else\n  end\nend"},
+		{&FunctionLiteral{
+			Name: ident("bar"),
+			Body: &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: &BeginExpression{
+				Token: token.Token{Type: token.NEWLINE, Literal: "\n"},
+				Body:  &BlockStatement{Statements: []Statement{&ExpressionStatement{Expression: call(nil, "baz")}}},
+				Rescues: []*RescueBlock{{
+					ExceptionClasses: []Expression{ident("StandardError")},
+					Exception:        ident("e"),
+					Body:             &BlockStatement{},
+				}},
+			}}}},
+		}, "def bar\n  baz()\nrescue StandardError => e\nend"},
+	}
+
+	for _, tt := range tests {
+		if actual := Unparse(tt.node); actual != tt.expected {
+			t.Errorf("Expected %s to unparse to\n%s\ngot\n%s", tt.node, tt.expected, actual)
+		}
+	}
+}
+
+func TestUnparseProgram(t *testing.T) {
+	program := testProgram()
+	program.HasData, program.Data = true, "data\n"
+
+	expected := "x = 1 + 2 # sum\n# print it\nputs(x)\n__END__\ndata\n"
+	if actual := Unparse(program); actual != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, actual)
+	}
+}
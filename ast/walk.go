@@ -0,0 +1,160 @@
+package ast
+
+import "reflect"
+
+// Inspect traverses the AST in depth-first order: it starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node.
+//
+// The children of a node are all fields holding a Node or a slice of Nodes.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if node != nil && f(node) {
+		return f
+	}
+	return nil
+}
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of
+// node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the AST in depth-first order: it starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+//
+// The children of a node are all fields holding a Node or a slice of
+// Nodes, in the order of their declaration.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	value := reflect.ValueOf(node)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			walkValue(v, value.Field(i))
+		}
+	}
+	v.Visit(nil)
+}
+
+func walkValue(v Visitor, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			walkValue(v, value.Index(i))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() || !value.CanInterface() {
+			return
+		}
+		if node, ok := value.Interface().(Node); ok {
+			Walk(v, node)
+		}
+	}
+}
+
+// Rewrite traverses the AST in depth-first order, rewriting the children of
+// node before node itself. Every child is replaced by the result of
+// f(child), unless the result is nil or cannot be stored within the field
+// holding the child. Rewrite returns f(node).
+//
+// The nodes are modified in place.
+func Rewrite(node Node, f func(Node) Node) Node {
+	return transform(rewriter(f), node, false)
+}
+
+type rewriter func(Node) Node
+
+func (f rewriter) Enter(node Node) bool { return true }
+func (f rewriter) Leave(node Node) Node { return f(node) }
+
+// A Transformer rewrites the nodes of an AST traversed by Transform
+type Transformer interface {
+	// Enter is called before the children of node are transformed. They
+	// are left as they are if it returns false.
+	Enter(node Node) bool
+	// Leave returns the replacement of node, whose children have been
+	// transformed. A nil result removes node if it is an element of a
+	// slice, like a statement of a block, and keeps it otherwise.
+	Leave(node Node) Node
+}
+
+// Transform traverses the AST in depth-first order and replaces each node
+// by the result of t.Leave, unless the result cannot be stored within the
+// field holding the node. The children of a node are transformed between
+// the calls of t.Enter and t.Leave for it. Transform returns the result
+// of t.Leave(node), or node itself if t.Enter(node) returns false.
+//
+// The nodes are modified in place.
+func Transform(t Transformer, node Node) Node {
+	return transform(t, node, true)
+}
+
+// transform implements Transform. Nodes are removed from slices only if
+// remove is set.
+func transform(t Transformer, node Node, remove bool) Node {
+	if !t.Enter(node) {
+		return node
+	}
+	value := reflect.ValueOf(node)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			transformValue(t, value.Field(i), remove)
+		}
+	}
+	return t.Leave(node)
+}
+
+// transformValue transforms the nodes held by value. It returns false if
+// value holds a node which is to be removed.
+func transformValue(t Transformer, value reflect.Value, remove bool) bool {
+	switch value.Kind() {
+	case reflect.Slice:
+		if !value.CanSet() {
+			return true
+		}
+		kept := 0
+		for i := 0; i < value.Len(); i++ {
+			if transformValue(t, value.Index(i), remove) {
+				value.Index(kept).Set(value.Index(i))
+				kept++
+			}
+		}
+		if kept < value.Len() {
+			value.SetLen(kept)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() || !value.CanSet() {
+			return true
+		}
+		node, ok := value.Interface().(Node)
+		if !ok {
+			return true
+		}
+		transformed := transform(t, node, remove)
+		if transformed == nil {
+			return !remove
+		}
+		if replacement := reflect.ValueOf(transformed); replacement.Type().AssignableTo(value.Type()) {
+			value.Set(replacement)
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/token"
+)
+
+// testProgram returns the AST of
+//
+//	x = 1 + 2 # sum
+//	# print it
+//	puts x
+func testProgram() *Program {
+	return &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "x", Pos: 0, Line: 1},
+				Expression: &VariableAssignment{
+					Name: &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x", Pos: 0, Line: 1}, Value: "x"},
+					Value: &InfixExpression{
+						Token:    token.Token{Type: token.PLUS, Literal: "+", Pos: 6, Line: 1},
+						Left:     &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1", Pos: 4, Line: 1}, Value: 1},
+						Operator: "+",
+						Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2", Pos: 8, Line: 1}, Value: 2},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "puts", Pos: 27, Line: 3},
+				Expression: &ContextCallExpression{
+					Token:     token.Token{Type: token.IDENT, Literal: "puts", Pos: 27, Line: 3},
+					Function:  &Identifier{Token: token.Token{Type: token.IDENT, Literal: "puts", Pos: 27, Line: 3}, Value: "puts"},
+					Arguments: []Expression{&Identifier{Token: token.Token{Type: token.IDENT, Literal: "x", Pos: 32, Line: 3}, Value: "x"}},
+				},
+			},
+		},
+		Comments: []*Comment{
+			{Pos: Position{Offset: 10, Line: 1}, Text: "# sum"},
+			{Pos: Position{Offset: 16, Line: 2}, Text: "# print it"},
+		},
+	}
+}
+
+type recorder struct {
+	visits *[]string
+	depth  int
+}
+
+func (r recorder) Visit(node Node) Visitor {
+	if node == nil {
+		*r.visits = append(*r.visits, fmt.Sprintf("%d:end", r.depth))
+		return nil
+	}
+	*r.visits = append(*r.visits, fmt.Sprintf("%d:%T", r.depth, node))
+	if _, ok := node.(*InfixExpression); ok {
+		return nil
+	}
+	return recorder{visits: r.visits, depth: r.depth + 1}
+}
+
+func TestWalk(t *testing.T) {
+	var visits []string
+	program := testProgram()
+	program.Statements = program.Statements[:1]
+
+	Walk(recorder{visits: &visits}, program)
+
+	expected := []string{
+		"0:*ast.Program",
+		"1:*ast.ExpressionStatement",
+		"2:*ast.VariableAssignment",
+		"3:*ast.Identifier",
+		"4:end",
+		"3:*ast.InfixExpression",
+		"3:end",
+		"2:end",
+		"1:end",
+	}
+	if !reflect.DeepEqual(visits, expected) {
+		t.Errorf("Expected visits %v, got %v", expected, visits)
+	}
+}
+
+type constantFolder struct{}
+
+func (constantFolder) Enter(node Node) bool {
+	_, isCall := node.(*ContextCallExpression)
+	return !isCall
+}
+
+func (constantFolder) Leave(node Node) Node {
+	switch node := node.(type) {
+	case *InfixExpression:
+		left, leftOk := node.Left.(*IntegerLiteral)
+		right, rightOk := node.Right.(*IntegerLiteral)
+		if leftOk && rightOk && node.Operator == "+" {
+			return &IntegerLiteral{Token: left.Token, Value: left.Value + right.Value}
+		}
+	case *ExpressionStatement:
+		if _, isCall := node.Expression.(*ContextCallExpression); isCall {
+			return nil
+		}
+	case *Identifier:
+		if node.Value == "puts" {
+			panic("Expected the children of calls to be skipped")
+		}
+	}
+	return node
+}
+
+func TestTransform(t *testing.T) {
+	program := testProgram()
+
+	result := Transform(constantFolder{}, program)
+
+	if result != program {
+		t.Errorf("Expected the program to be returned, got %v", result)
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("Expected the call to be removed, got %v", program.Statements)
+	}
+	assignment := program.Statements[0].(*ExpressionStatement).Expression.(*VariableAssignment)
+	if sum, ok := assignment.Value.(*IntegerLiteral); !ok || sum.Value != 3 {
+		t.Errorf("Expected the sum to be folded, got %s", assignment.Value)
+	}
+}
+
+func TestRewriteKeepsNodesReplacedByNil(t *testing.T) {
+	program := testProgram()
+
+	Rewrite(program, func(node Node) Node {
+		if _, ok := node.(*ExpressionStatement); ok {
+			return nil
+		}
+		return node
+	})
+
+	if len(program.Statements) != 2 {
+		t.Errorf("Expected the statements to be kept, got %v", program.Statements)
+	}
+}
+
+func TestPos(t *testing.T) {
+	program := testProgram()
+	assignment := program.Statements[0].(*ExpressionStatement).Expression.(*VariableAssignment)
+
+	tests := []struct {
+		node     Node
+		expected Position
+	}{
+		{program, Position{Offset: 0, Line: 1}},
+		{assignment.Value, Position{Offset: 4, Line: 1}},
+		{program.Statements[1], Position{Offset: 27, Line: 3}},
+		{&Identifier{Value: "x"}, Position{}},
+	}
+
+	for _, tt := range tests {
+		if actual := Pos(tt.node); actual != tt.expected {
+			t.Errorf("Expected %s to start at %+v, got %+v", tt.node, tt.expected, actual)
+		}
+	}
+	if (Position{}).IsValid() {
+		t.Errorf("Expected the zero Position to be invalid")
+	}
+}
+
+func TestNewCommentMap(t *testing.T) {
+	program := testProgram()
+	program.Comments = append(program.Comments, &Comment{Pos: Position{Offset: 34, Line: 4}, Text: "# done"})
+
+	comments := NewCommentMap(program)
+
+	expected := CommentMap{
+		program.Statements[0]: program.Comments[:1],
+		program.Statements[1]: program.Comments[1:2],
+		program:               program.Comments[2:],
+	}
+	if !reflect.DeepEqual(comments, expected) {
+		t.Errorf("Expected comments %v, got %v", expected, comments)
+	}
+}
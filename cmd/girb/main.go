@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -12,20 +13,31 @@ import (
 )
 
 func main() {
+	config := repl.DefaultConfig()
+	flag.StringVar(&config.Prompt, "prompt", config.Prompt, "the `template` of the prompt, in which %n is the line number, %i the nesting depth and %N the name of the REPL")
+	flag.StringVar(&config.ContinuationPrompt, "prompt-continuation", config.ContinuationPrompt, "the prompt `template` of the lines continuing an expression")
+	flag.BoolVar(&config.Color, "color", repl.IsTerminal(os.Stdout), "highlight results and errors")
+	norc := flag.Bool("norc", false, "do not evaluate ~/"+repl.RCFile)
+	flag.Parse()
+	if *norc {
+		config.RCFile = ""
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
 	}
 	fmt.Printf("Hello %s! This is the Ruby programming language in Go!\n", user.Username)
 	fmt.Printf("Feel free to type in commands\n")
-	start(os.Stdin, os.Stdout)
+	start(os.Stdin, os.Stdout, config)
 }
 
-func start(in io.Reader, out io.Writer) {
+func start(in io.Reader, out io.Writer, config repl.Config) {
 	printChan := make(chan string)
+	interrupts := make(chan struct{}, 1)
 	sigChan := make(chan os.Signal, 4)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGHUP, os.Kill)
-	go repl.Start(in, printChan)
+	go repl.StartWithConfig(in, printChan, interrupts, config)
 	for {
 		select {
 		case evaluated, ok := <-printChan:
@@ -38,6 +50,10 @@ func start(in io.Reader, out io.Writer) {
 			if sig != syscall.SIGINT {
 				return
 			}
+			select {
+			case interrupts <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
@@ -0,0 +1,176 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions is a sequence of encoded instructions
+type Instructions []byte
+
+// String returns the disassembled instructions, one per line, prefixed by
+// their offset
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, fmtInstruction(def, operands))
+		i += 1 + read
+	}
+	return out.String()
+}
+
+func fmtInstruction(def *Definition, operands []int) string {
+	out := def.Name
+	for _, operand := range operands {
+		out += fmt.Sprintf(" %d", operand)
+	}
+	return out
+}
+
+// Opcode identifies an instruction
+type Opcode byte
+
+const (
+	// OpConstant pushes the constant with the given index
+	OpConstant Opcode = iota
+	// OpPop discards the topmost value
+	OpPop
+	// OpNil pushes nil
+	OpNil
+	// OpTrue pushes true
+	OpTrue
+	// OpFalse pushes false
+	OpFalse
+	// OpSelf pushes self
+	OpSelf
+	// OpGetLocal pushes the local variable named by the identifier node
+	// with the given index, or the result of calling the method of that
+	// name if there is no such variable
+	OpGetLocal
+	// OpSetLocal assigns the topmost value to the local variable named by
+	// the constant with the given index, leaving the value on the stack
+	OpSetLocal
+	// OpInfix pops two operands and pushes the result of the infix operator
+	// named by the constant with the given index
+	OpInfix
+	// OpPrefix pops an operand and pushes the result of the prefix operator
+	// named by the constant with the given index
+	OpPrefix
+	// OpArray pops the given number of values and pushes them as Array
+	OpArray
+	// OpIndex pops the index and the receiver and pushes the element
+	OpIndex
+	// OpCall pops the given number of arguments and the receiver and
+	// pushes the result of calling the method of the call node with the
+	// given index
+	OpCall
+	// OpJump jumps to the given offset
+	OpJump
+	// OpJumpNotTruthy pops a value and jumps to the given offset if it is
+	// falsy
+	OpJumpNotTruthy
+	// OpLoop jumps back to the given offset, raising a pending interrupt
+	OpLoop
+	// OpEval evaluates the node with the given index by the evaluator
+	OpEval
+	// OpDefine evaluates the method definition node with the given index
+	// and lets the VM execute the method body with the instructions of the
+	// constant with the given index
+	OpDefine
+)
+
+// Definition describes an Opcode by its name and the widths in bytes of
+// its operands
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:      {"OpConstant", []int{2}},
+	OpPop:           {"OpPop", []int{}},
+	OpNil:           {"OpNil", []int{}},
+	OpTrue:          {"OpTrue", []int{}},
+	OpFalse:         {"OpFalse", []int{}},
+	OpSelf:          {"OpSelf", []int{}},
+	OpGetLocal:      {"OpGetLocal", []int{2}},
+	OpSetLocal:      {"OpSetLocal", []int{2}},
+	OpInfix:         {"OpInfix", []int{2}},
+	OpPrefix:        {"OpPrefix", []int{2}},
+	OpArray:         {"OpArray", []int{2}},
+	OpIndex:         {"OpIndex", []int{}},
+	OpCall:          {"OpCall", []int{2, 1}},
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	OpLoop:          {"OpLoop", []int{2}},
+	OpEval:          {"OpEval", []int{2}},
+	OpDefine:        {"OpDefine", []int{2, 2}},
+}
+
+// Lookup returns the definition of op
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes the instruction op with the given operands. It returns an
+// empty instruction if op is unknown.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+	length := 1
+	for _, width := range def.OperandWidths {
+		length += width
+	}
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+	offset := 1
+	for i, operand := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction defined by def. It
+// returns the operands and the number of bytes read.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+	return operands, offset
+}
+
+// ReadUint16 decodes a two byte operand
+func ReadUint16(ins Instructions) uint16 { return binary.BigEndian.Uint16(ins) }
+
+// ReadUint8 decodes a single byte operand
+func ReadUint8(ins Instructions) uint8 { return uint8(ins[0]) }
@@ -0,0 +1,81 @@
+package code
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpPop, []int{}, []byte{byte(OpPop)}},
+		{OpCall, []int{258, 3}, []byte{byte(OpCall), 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		if string(instruction) != string(tt.expected) {
+			t.Logf("Expected instruction %v, got %v", tt.expected, instruction)
+			t.Fail()
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpCall, []int{12, 255}, 3},
+		{OpDefine, []int{1, 2}, 4},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+		def, err := Lookup(byte(tt.op))
+		if err != nil {
+			t.Fatalf("definition not found: %q\n", err)
+		}
+
+		operands, read := ReadOperands(def, instruction[1:])
+
+		if read != tt.bytesRead {
+			t.Logf("Expected %d bytes to be read, got %d", tt.bytesRead, read)
+			t.Fail()
+		}
+		for i, expected := range tt.operands {
+			if operands[i] != expected {
+				t.Logf("Expected operand %d to equal %d, got %d", i, expected, operands[i])
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	var instructions Instructions
+	for _, ins := range [][]byte{
+		Make(OpConstant, 1),
+		Make(OpGetLocal, 2),
+		Make(OpInfix, 0),
+		Make(OpCall, 3, 1),
+		Make(OpPop),
+	} {
+		instructions = append(instructions, ins...)
+	}
+	expected := `0000 OpConstant 1
+0003 OpGetLocal 2
+0006 OpInfix 0
+0009 OpCall 3 1
+0013 OpPop
+`
+
+	if instructions.String() != expected {
+		t.Logf("Expected instructions to be printed as\n%q\ngot\n%q", expected, instructions.String())
+		t.Fail()
+	}
+}
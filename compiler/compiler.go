@@ -0,0 +1,380 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/code"
+	"github.com/goruby/goruby/object"
+)
+
+// ErrUnsupported is returned for programs the compiler cannot translate,
+// e.g. because they return from the top level. They have to be evaluated
+// by the evaluator.
+var ErrUnsupported = errors.New("program not supported by the compiler")
+
+// ErrOperandOverflow is returned for programs with an instruction operand
+// too large for the instruction to encode, like a jump beyond 65535 bytes
+// or an Array literal with more elements. They have to be evaluated by the
+// evaluator.
+var ErrOperandOverflow = errors.New("instruction operand out of range")
+
+// Bytecode holds the compiled instructions of a program or method body
+// together with the pools their operands refer to
+type Bytecode struct {
+	Instructions code.Instructions
+	Constants    []object.RubyObject
+	// Nodes holds the nodes handed to the evaluator, like calls with a
+	// block or class definitions
+	Nodes []ast.Node
+	// Names holds the names of variables and operators
+	Names []string
+	// Functions holds the compiled bodies of defined methods
+	Functions []*Bytecode
+	// Positions maps the offsets of instructions to the statements they
+	// have been compiled from, in ascending order
+	Positions []Position
+}
+
+// A Position marks the start of the instructions of a statement
+type Position struct {
+	Offset    int
+	Statement ast.Statement
+}
+
+// StatementAt returns the statement the instruction at offset belongs to,
+// or nil if there is none
+func (b *Bytecode) StatementAt(offset int) ast.Statement {
+	var statement ast.Statement
+	for _, position := range b.Positions {
+		if position.Offset > offset {
+			break
+		}
+		statement = position.Statement
+	}
+	return statement
+}
+
+// StatementStartingAt returns the statement whose instructions start at
+// offset, or nil if there is none
+func (b *Bytecode) StatementStartingAt(offset int) ast.Statement {
+	i := sort.Search(len(b.Positions), func(i int) bool { return b.Positions[i].Offset >= offset })
+	if i < len(b.Positions) && b.Positions[i].Offset == offset {
+		return b.Positions[i].Statement
+	}
+	return nil
+}
+
+// String returns the disassembled instructions followed by the pools they
+// refer to and the bytecode of the defined methods
+func (b *Bytecode) String() string {
+	var out strings.Builder
+	b.disassemble(&out, "")
+	return out.String()
+}
+
+func (b *Bytecode) disassemble(out *strings.Builder, indent string) {
+	for _, line := range strings.SplitAfter(b.Instructions.String(), "\n") {
+		if line != "" {
+			out.WriteString(indent + line)
+		}
+	}
+	for i, constant := range b.Constants {
+		fmt.Fprintf(out, "%sconstant %d: %s\n", indent, i, constant.Inspect())
+	}
+	for i, name := range b.Names {
+		fmt.Fprintf(out, "%sname %d: %s\n", indent, i, name)
+	}
+	for i, node := range b.Nodes {
+		source := strings.SplitN(node.String(), "\n", 2)[0]
+		fmt.Fprintf(out, "%snode %d: %T %s\n", indent, i, node, source)
+	}
+	for i, function := range b.Functions {
+		fmt.Fprintf(out, "%sfunction %d:\n", indent, i)
+		function.disassemble(out, indent+"  ")
+	}
+}
+
+// Compile translates program to Bytecode. Programs with BEGIN blocks, data
+// after `__END__`, or control flow leaving the top level, like `return`,
+// are not supported and return ErrUnsupported.
+func Compile(program *ast.Program) (*Bytecode, error) {
+	if program.HasData {
+		return nil, ErrUnsupported
+	}
+	for _, statement := range program.Statements {
+		if _, ok := statement.(*ast.BeginBlock); ok || escapes(statement) {
+			return nil, ErrUnsupported
+		}
+	}
+	c := &compiler{bytecode: &Bytecode{}}
+	c.compileStatements(program.Statements)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.bytecode, nil
+}
+
+// CompileBody translates the body of a method to Bytecode. Bodies which
+// return explicitly are not supported and return ErrUnsupported.
+func CompileBody(body *ast.BlockStatement) (*Bytecode, error) {
+	for _, statement := range body.Statements {
+		if escapes(statement) {
+			return nil, ErrUnsupported
+		}
+	}
+	c := &compiler{bytecode: &Bytecode{}}
+	c.compileStatements(body.Statements)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.bytecode, nil
+}
+
+// escapes reports whether node contains a return, or a break or next which
+// is not handled within it by a block or a loop. Method, class and module
+// definitions are not searched as they are left by none of them.
+func escapes(node ast.Node) bool {
+	found := false
+	var visit func(node ast.Node, inLoop bool)
+	visit = func(node ast.Node, inLoop bool) {
+		ast.Inspect(node, func(child ast.Node) bool {
+			if found {
+				return false
+			}
+			switch child := child.(type) {
+			case *ast.FunctionLiteral, *ast.ClassExpression, *ast.ModuleExpression:
+				return false
+			case *ast.ReturnStatement:
+				found = true
+			case *ast.BreakStatement, *ast.NextStatement:
+				found = !inLoop
+			case *ast.BlockLiteral:
+				if child != node {
+					visit(child, true)
+					return false
+				}
+			case *ast.WhileExpression:
+				if child != node {
+					visit(child, true)
+					return false
+				}
+			}
+			return !found
+		})
+	}
+	visit(node, false)
+	return found
+}
+
+// containsLoopControl reports whether the body of a loop contains a break
+// or next for that loop
+func containsLoopControl(body ast.Node) bool {
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.BlockLiteral, *ast.FunctionLiteral, *ast.WhileExpression:
+			return false
+		case *ast.BreakStatement, *ast.NextStatement:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+type compiler struct {
+	bytecode *Bytecode
+	// err is the first error found while compiling
+	err error
+}
+
+func (c *compiler) emit(op code.Opcode, operands ...int) int {
+	c.checkOperands(op, operands)
+	position := len(c.bytecode.Instructions)
+	c.bytecode.Instructions = append(c.bytecode.Instructions, code.Make(op, operands...)...)
+	return position
+}
+
+// changeOperand replaces the operand of the jump instruction at position
+func (c *compiler) changeOperand(position int, operand int) {
+	op := code.Opcode(c.bytecode.Instructions[position])
+	c.checkOperands(op, []int{operand})
+	copy(c.bytecode.Instructions[position:], code.Make(op, operand))
+}
+
+// checkOperands records ErrOperandOverflow if any of operands does not fit
+// into the bytes op encodes it with
+func (c *compiler) checkOperands(op code.Opcode, operands []int) {
+	def, err := code.Lookup(byte(op))
+	if err != nil {
+		return
+	}
+	for i, operand := range operands {
+		limit := math.MaxUint8
+		if def.OperandWidths[i] == 2 {
+			limit = math.MaxUint16
+		}
+		if (operand < 0 || operand > limit) && c.err == nil {
+			c.err = ErrOperandOverflow
+		}
+	}
+}
+
+func (c *compiler) addConstant(obj object.RubyObject) int {
+	c.bytecode.Constants = append(c.bytecode.Constants, obj)
+	return len(c.bytecode.Constants) - 1
+}
+
+func (c *compiler) addNode(node ast.Node) int {
+	c.bytecode.Nodes = append(c.bytecode.Nodes, node)
+	return len(c.bytecode.Nodes) - 1
+}
+
+func (c *compiler) addName(name string) int {
+	for i, existing := range c.bytecode.Names {
+		if existing == name {
+			return i
+		}
+	}
+	c.bytecode.Names = append(c.bytecode.Names, name)
+	return len(c.bytecode.Names) - 1
+}
+
+// compileStatements compiles statements, leaving the value of the last one
+// on the stack, or nil if there are none
+func (c *compiler) compileStatements(statements []ast.Statement) {
+	if len(statements) == 0 {
+		c.emit(code.OpNil)
+		return
+	}
+	for i, statement := range statements {
+		c.bytecode.Positions = append(c.bytecode.Positions, Position{len(c.bytecode.Instructions), statement})
+		c.compileStatement(statement)
+		if i < len(statements)-1 {
+			c.emit(code.OpPop)
+		}
+	}
+}
+
+func (c *compiler) compileStatement(statement ast.Statement) {
+	if statement, ok := statement.(*ast.ExpressionStatement); ok && statement.Expression != nil {
+		c.compileExpression(statement.Expression)
+		return
+	}
+	c.emit(code.OpEval, c.addNode(statement))
+}
+
+func (c *compiler) compileExpression(node ast.Expression) {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewInteger(node.Value)))
+	case *ast.FloatLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewFloat(node.Value)))
+	case *ast.SymbolLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.NewSymbol(node.Value)))
+	case *ast.ObjectLiteral:
+		c.emit(code.OpConstant, c.addConstant(node.Value.(object.RubyObject)))
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.Nil:
+		c.emit(code.OpNil)
+	case *ast.Self:
+		c.emit(code.OpSelf)
+	case *ast.Identifier:
+		c.emit(code.OpGetLocal, c.addNode(node))
+	case *ast.VariableAssignment:
+		name := node.Name.Value
+		if len(name) > 0 && (name[0] == '$' || object.IsConstantName(name)) {
+			c.emit(code.OpEval, c.addNode(node))
+			return
+		}
+		c.compileExpression(node.Value)
+		c.emit(code.OpSetLocal, c.addName(name))
+	case *ast.InfixExpression:
+		c.compileExpression(node.Left)
+		c.compileExpression(node.Right)
+		c.emit(code.OpInfix, c.addName(node.Operator))
+	case *ast.PrefixExpression:
+		c.compileExpression(node.Right)
+		c.emit(code.OpPrefix, c.addName(node.Operator))
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			c.compileExpression(element)
+		}
+		c.emit(code.OpArray, len(node.Elements))
+	case *ast.IndexExpression:
+		if node.Length != nil {
+			c.emit(code.OpEval, c.addNode(node))
+			return
+		}
+		c.compileExpression(node.Left)
+		c.compileExpression(node.Index)
+		c.emit(code.OpIndex)
+	case *ast.ContextCallExpression:
+		if node.Block != nil || len(node.Arguments) > math.MaxUint8 {
+			c.emit(code.OpEval, c.addNode(node))
+			return
+		}
+		if node.Context == nil {
+			c.emit(code.OpSelf)
+		} else {
+			c.compileExpression(node.Context)
+		}
+		for _, arg := range node.Arguments {
+			c.compileExpression(arg)
+		}
+		c.emit(code.OpCall, c.addNode(node), len(node.Arguments))
+	case *ast.IfExpression:
+		c.compileIfExpression(node)
+	case *ast.WhileExpression:
+		if containsLoopControl(node.Body) {
+			c.emit(code.OpEval, c.addNode(node))
+			return
+		}
+		c.compileWhileExpression(node)
+	case *ast.FunctionLiteral:
+		body, err := CompileBody(node.Body)
+		if err != nil {
+			c.emit(code.OpEval, c.addNode(node))
+			return
+		}
+		c.bytecode.Functions = append(c.bytecode.Functions, body)
+		c.emit(code.OpDefine, c.addNode(node), len(c.bytecode.Functions)-1)
+	default:
+		c.emit(code.OpEval, c.addNode(node))
+	}
+}
+
+func (c *compiler) compileIfExpression(node *ast.IfExpression) {
+	c.compileExpression(node.Condition)
+	jumpToAlternative := c.emit(code.OpJumpNotTruthy, 9999)
+	c.compileStatements(node.Consequence.Statements)
+	jumpToEnd := c.emit(code.OpJump, 9999)
+	c.changeOperand(jumpToAlternative, len(c.bytecode.Instructions))
+	if node.Alternative == nil {
+		c.emit(code.OpNil)
+	} else {
+		c.compileStatements(node.Alternative.Statements)
+	}
+	c.changeOperand(jumpToEnd, len(c.bytecode.Instructions))
+}
+
+func (c *compiler) compileWhileExpression(node *ast.WhileExpression) {
+	start := len(c.bytecode.Instructions)
+	c.compileExpression(node.Condition)
+	jumpToEnd := c.emit(code.OpJumpNotTruthy, 9999)
+	c.compileStatements(node.Body.Statements)
+	c.emit(code.OpPop)
+	c.emit(code.OpLoop, start)
+	c.changeOperand(jumpToEnd, len(c.bytecode.Instructions))
+	c.emit(code.OpNil)
+}
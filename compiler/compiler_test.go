@@ -0,0 +1,207 @@
+package compiler
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/code"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse error for %q: %v", input, err)
+	}
+	return program
+}
+
+func concat(instructions ...[]byte) code.Instructions {
+	var out code.Instructions
+	for _, ins := range instructions {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected code.Instructions
+	}{
+		{
+			"",
+			concat(code.Make(code.OpNil)),
+		},
+		{
+			"1 + 2; true",
+			concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpInfix, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpTrue),
+			),
+		},
+		{
+			"x = 3; -x",
+			concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetLocal, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpGetLocal, 0),
+				code.Make(code.OpPrefix, 1),
+			),
+		},
+		{
+			"if true; 1; end",
+			concat(
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 10),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpJump, 11),
+				code.Make(code.OpNil),
+			),
+		},
+		{
+			"while false; 1; end",
+			concat(
+				code.Make(code.OpFalse),
+				code.Make(code.OpJumpNotTruthy, 11),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpLoop, 0),
+				code.Make(code.OpNil),
+			),
+		},
+		{
+			"[1].push(2)",
+			concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpCall, 0, 1),
+			),
+		},
+		{
+			"foo(1)",
+			concat(
+				code.Make(code.OpSelf),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 0, 1),
+			),
+		},
+		{
+			"def foo; 1; end",
+			concat(code.Make(code.OpDefine, 0, 0)),
+		},
+		{
+			"def foo; return 1; end",
+			concat(code.Make(code.OpEval, 0)),
+		},
+		{
+			"[1].each { |x| x }",
+			concat(code.Make(code.OpEval, 0)),
+		},
+		{
+			"while true; break; end",
+			concat(code.Make(code.OpEval, 0)),
+		},
+	}
+
+	for _, tt := range tests {
+		bytecode, err := Compile(parse(t, tt.input))
+		if err != nil {
+			t.Fatalf("compile error for %q: %v", tt.input, err)
+		}
+
+		if bytecode.Instructions.String() != tt.expected.String() {
+			t.Logf("Expected %q to compile to\n%s\ngot\n%s", tt.input, tt.expected, bytecode.Instructions)
+			t.Fail()
+		}
+	}
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	tests := []string{
+		"return 1\n",
+		"if true; return 1; end",
+		"[1].each { |x| return x; }",
+		"x = 1\nBEGIN { x = 10 }\nx",
+		"DATA.read\n__END__\nfoo\n",
+	}
+
+	for _, input := range tests {
+		_, err := Compile(parse(t, input))
+
+		if err != ErrUnsupported {
+			t.Logf("Expected %q to be unsupported, got %v", input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestCompileOperandOverflow(t *testing.T) {
+	input := "if x\n" + strings.Repeat("y\n", math.MaxUint16/3) + "end"
+
+	_, err := Compile(parse(t, input))
+
+	if err != ErrOperandOverflow {
+		t.Logf("Expected a jump beyond %d bytes to overflow its operand, got %v", math.MaxUint16, err)
+		t.Fail()
+	}
+}
+
+func TestBytecodeString(t *testing.T) {
+	bytecode, err := Compile(parse(t, "def inc(x)\nx + 1\nend\ny = 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `0000 OpDefine 0 0
+0005 OpPop
+0006 OpConstant 0
+0009 OpSetLocal 0
+constant 0: 2
+name 0: y
+node 0: *ast.FunctionLiteral def inc(x) (x + 1) end
+function 0:
+  0000 OpGetLocal 0
+  0003 OpConstant 0
+  0006 OpInfix 0
+  constant 0: 1
+  name 0: +
+  node 0: *ast.Identifier x
+`
+	if bytecode.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, bytecode.String())
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	program := parse(t, "x = 1\nx + 2")
+	bytecode, err := Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %v", err)
+	}
+
+	tests := []struct {
+		offset   int
+		expected ast.Statement
+	}{
+		{0, program.Statements[0]},
+		{3, program.Statements[0]},
+		{7, program.Statements[1]},
+		{len(bytecode.Instructions) - 1, program.Statements[1]},
+	}
+
+	for _, tt := range tests {
+		if statement := bytecode.StatementAt(tt.offset); statement != tt.expected {
+			t.Logf("Expected offset %d to belong to %v, got %v", tt.offset, tt.expected, statement)
+			t.Fail()
+		}
+	}
+}
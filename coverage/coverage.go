@@ -0,0 +1,114 @@
+// Package coverage holds the line coverage of Ruby files and writes it as
+// lcov tracefile or as JSON.
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// NotExecutable marks the lines of a file which hold no statement, like
+// blank lines, comments or the end of a method
+const NotExecutable = -1
+
+// Lines holds the number of times each line of a file was executed, the
+// first line at index 0. Lines which hold no statement are NotExecutable.
+type Lines []int64
+
+// NewLines returns the Lines of a file with count lines, of which the
+// executable ones, counted from 1, have not been executed yet
+func NewLines(count int, executable []int) Lines {
+	lines := make(Lines, count)
+	for i := range lines {
+		lines[i] = NotExecutable
+	}
+	for _, line := range executable {
+		if line > 0 && line <= count {
+			lines[line-1] = 0
+		}
+	}
+	return lines
+}
+
+// Hit counts an execution of line, counted from 1, unless it is not
+// executable
+func (l Lines) Hit(line int) {
+	if line > 0 && line <= len(l) && l[line-1] != NotExecutable {
+		l[line-1]++
+	}
+}
+
+// Executable returns the number of executable lines and how many of them
+// were executed
+func (l Lines) Executable() (found, hit int) {
+	for _, count := range l {
+		if count == NotExecutable {
+			continue
+		}
+		found++
+		if count > 0 {
+			hit++
+		}
+	}
+	return found, hit
+}
+
+// MarshalJSON writes the lines as array of their counts, with null for the
+// lines which are not executable
+func (l Lines) MarshalJSON() ([]byte, error) {
+	counts := make([]*int64, len(l))
+	for i := range l {
+		if l[i] != NotExecutable {
+			counts[i] = &l[i]
+		}
+	}
+	return json.Marshal(counts)
+}
+
+// A Result maps the paths of files to their Lines
+type Result map[string]Lines
+
+// Paths returns the paths of the files of the result in sorted order
+func (r Result) Paths() []string {
+	paths := make([]string, 0, len(r))
+	for path := range r {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WriteLCOV writes the result to w as lcov tracefile, as read by genhtml
+// and most coverage services
+func (r Result) WriteLCOV(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "TN:"); err != nil {
+		return err
+	}
+	for _, path := range r.Paths() {
+		lines := r[path]
+		if _, err := fmt.Fprintf(w, "SF:%s\n", path); err != nil {
+			return err
+		}
+		for i, count := range lines {
+			if count == NotExecutable {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "DA:%d,%d\n", i+1, count); err != nil {
+				return err
+			}
+		}
+		found, hit := lines.Executable()
+		if _, err := fmt.Fprintf(w, "LF:%d\nLH:%d\nend_of_record\n", found, hit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the result to w as JSON object mapping each path to the
+// counts of its lines, like Coverage.result returns them
+func (r Result) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
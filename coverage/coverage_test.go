@@ -0,0 +1,61 @@
+package coverage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testResult() Result {
+	lib := NewLines(5, []int{1, 2, 4})
+	lib.Hit(1)
+	lib.Hit(2)
+	lib.Hit(2)
+	lib.Hit(3)
+	main := NewLines(2, []int{1, 2})
+	main.Hit(1)
+	return Result{"/b/main.rb": main, "/a/lib.rb": lib}
+}
+
+func TestLines(t *testing.T) {
+	lines := testResult()["/a/lib.rb"]
+
+	expected := Lines{1, 2, NotExecutable, 0, NotExecutable}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, lines)
+			break
+		}
+	}
+	if found, hit := lines.Executable(); found != 3 || hit != 2 {
+		t.Errorf("Expected 3 executable lines of which 2 were hit, got %d and %d", found, hit)
+	}
+}
+
+func TestResultWriteLCOV(t *testing.T) {
+	var out bytes.Buffer
+	if err := testResult().WriteLCOV(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "TN:\n" +
+		"SF:/a/lib.rb\nDA:1,1\nDA:2,2\nDA:4,0\nLF:3\nLH:2\nend_of_record\n" +
+		"SF:/b/main.rb\nDA:1,1\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestResultWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := testResult().WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"/a/lib.rb":[1,2,null,0,null],"/b/main.rb":[1,0]}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
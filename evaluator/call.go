@@ -0,0 +1,73 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// Call calls the method named by node on context with the evaluated args,
+// which include the block if node has one. Receiverless calls are resolved
+// against the functions of env first.
+func Call(node *ast.ContextCallExpression, context object.RubyObject, args []object.RubyObject, env object.Environment) (object.RubyObject, error) {
+	var result object.RubyObject
+	var err error
+	if function, ok := evaluatorFunctions[node.Function.Value]; ok && node.Context == nil {
+		updateLocation(env, node.Token)
+		result, err = function(env, args...)
+	} else if function, ok := env.Get(node.Function.Value); ok && isFunction(function) {
+		result, err = callWithFrame(env, node.Token, node.Function.Value, func() (object.RubyObject, error) {
+			return applyFunction(function, args)
+		})
+	} else {
+		self, _ := env.Get("self")
+		result, err = callWithFrame(env, node.Token, node.Function.Value, func() (object.RubyObject, error) {
+			if isSelfCall(node) {
+				if result, ok, err := callWithStreams(env, self, node.Function.Value, args...); ok {
+					return result, err
+				}
+			}
+			return object.RuntimeOf(env).SendFrom(self, context, node.Function.Value, args...)
+		})
+	}
+	if brk, ok := err.(*breakError); ok && node.Block != nil {
+		return brk.value, nil
+	}
+	return result, err
+}
+
+// EvalInfix applies the infix operator to left and right within runtime
+// the way the evaluator does for an InfixExpression
+func EvalInfix(runtime *object.Runtime, operator string, left, right object.RubyObject) (object.RubyObject, error) {
+	return evalInfixExpression(runtime, operator, left, right)
+}
+
+// EvalPrefix applies the prefix operator to right within runtime the way
+// the evaluator does for a PrefixExpression
+func EvalPrefix(runtime *object.Runtime, operator string, right object.RubyObject) (object.RubyObject, error) {
+	return evalPrefixExpression(runtime, operator, right)
+}
+
+// BindArguments returns the environment the body of the method fn is
+// evaluated in when called with args. Blocks the method does not expect
+// are dropped.
+func BindArguments(fn *object.Function, args []object.RubyObject) (object.Environment, error) {
+	if len(args) == len(fn.Parameters)+1 {
+		// methods ignore blocks they do not expect
+		if _, ok := args[len(args)-1].(*object.Proc); ok {
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) != len(fn.Parameters) {
+		return nil, object.NewWrongNumberOfArgumentsError(len(fn.Parameters), len(args))
+	}
+	extendedEnv := extendFunctionEnv(fn, args)
+	extendedEnv.Set(currentMethodKey, fn)
+	return extendedEnv, nil
+}
+
+// RecordBacktrace sets the backtrace of the exception err, raised by
+// statement, to the frames of the call stack unless it has one already
+func RecordBacktrace(env object.Environment, statement ast.Statement, err error) {
+	tok, _ := statementToken(statement)
+	setBacktrace(env, tok, err)
+}
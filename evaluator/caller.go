@@ -0,0 +1,247 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+func init() {
+	evaluatorFunctions["caller"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		frames, err := callerFrames(env, args)
+		if err != nil || frames == nil {
+			return object.NIL, err
+		}
+		for i, frame := range frames.Elements {
+			frames.Elements[i] = &object.String{Value: frame.(*object.Location).String()}
+		}
+		return frames, nil
+	}
+	evaluatorFunctions["caller_locations"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		frames, err := callerFrames(env, args)
+		if err != nil || frames == nil {
+			return object.NIL, err
+		}
+		return frames, nil
+	}
+}
+
+// callStackKey is the name under which the frames of all active method
+// calls are stored within the root environment. It is no valid Ruby
+// identifier and thus not accessible from within Ruby code.
+const callStackKey = "call stack"
+
+// unknownFile is the path reported for code not read from a file
+const unknownFile = "-"
+
+// callStack returns the frames of all active method calls as Locations,
+// the innermost last. The outermost frame represents the main program.
+// Every Fiber has its own call stack, so that the frames of a suspended
+// Fiber do not stay on the one of its resumer.
+func callStack(env object.Environment) *object.Array {
+	return fiberCallStack(env, object.RuntimeOf(env).CurrentFiber())
+}
+
+// fiberCallStack returns the call stack of fiber, or the one of the main
+// program for the root fiber. The call stack of a Fiber starts with a copy
+// of the innermost frame of its resumer, i.e. the call resuming it.
+func fiberCallStack(env object.Environment, fiber *object.Fiber) *object.Array {
+	if fiber == nil {
+		stack, ok := env.Get(callStackKey)
+		if !ok {
+			stack = env.SetGlobal(callStackKey, object.NewArray(object.NewLocation(currentFile(env), 0, "<main>")))
+		}
+		return stack.(*object.Array)
+	}
+	if fiber.CallStack == nil {
+		fiber.CallStack = object.NewArray(&object.Location{})
+	}
+	resumer := fiberCallStack(env, fiber.Resumer())
+	*fiber.CallStack.Elements[0].(*object.Location) = *resumer.Elements[len(resumer.Elements)-1].(*object.Location)
+	return fiber.CallStack
+}
+
+// currentFile returns the path of the file being evaluated
+func currentFile(env object.Environment) string {
+	file, ok := env.Get(currentFileKey)
+	if path, isString := file.(*object.String); ok && isString {
+		return path.Value
+	}
+	return unknownFile
+}
+
+// updateLocation records the position of tok within the innermost frame
+func updateLocation(env object.Environment, tok token.Token) *object.Array {
+	stack := callStack(env)
+	current := stack.Elements[len(stack.Elements)-1].(*object.Location)
+	current.Path, current.Lineno = currentFile(env), tok.Line
+	return stack
+}
+
+// callWithFrame calls fn within a new frame for the method name, called at
+// the position of tok
+func callWithFrame(env object.Environment, tok token.Token, name string, fn func() (object.RubyObject, error)) (object.RubyObject, error) {
+	updateLocation(env, tok)
+	return withFrame(env, object.Location{Path: currentFile(env), Lineno: tok.Line, Label: name}, fn)
+}
+
+// maxCallDepth is the number of nested calls after which a SystemStackError
+// is raised, well before the stack of the goroutine evaluating them is
+// exhausted
+const maxCallDepth = 10000
+
+// stackErrorFrames is the number of innermost and outermost frames kept in
+// the backtrace of a SystemStackError
+const stackErrorFrames = 8
+
+// withFrame calls fn with frame pushed onto the call stack. It raises a
+// SystemStackError instead if the call stack is full already.
+func withFrame(env object.Environment, frame object.Location, fn func() (object.RubyObject, error)) (object.RubyObject, error) {
+	stack := callStack(env)
+	if len(stack.Elements) >= maxCallDepth {
+		return nil, newSystemStackError(stack)
+	}
+	pushFrame(stack, frame)
+	defer func() { stack.Elements = stack.Elements[:len(stack.Elements)-1] }()
+	return fn()
+}
+
+// pushFrame pushes frame onto stack. The Locations of popped frames are
+// reused, as frames never leave the stack but as copies, so that calls do
+// not allocate a new one each.
+func pushFrame(stack *object.Array, frame object.Location) {
+	n := len(stack.Elements)
+	if n < cap(stack.Elements) {
+		if spare, ok := stack.Elements[:n+1][n].(*object.Location); ok {
+			*spare = frame
+			stack.Elements = stack.Elements[:n+1]
+			return
+		}
+	}
+	allocated := frame
+	stack.Elements = append(stack.Elements, &allocated)
+}
+
+// newSystemStackError returns a SystemStackError with a backtrace listing
+// only the innermost and outermost frames of stack
+func newSystemStackError(stack *object.Array) error {
+	err := object.NewSystemStackError()
+	frames := stack.Elements
+	backtrace := make([]string, 0, 2*stackErrorFrames+1)
+	for i := len(frames) - 1; i >= len(frames)-stackErrorFrames; i-- {
+		backtrace = append(backtrace, frames[i].(*object.Location).String())
+	}
+	backtrace = append(backtrace, fmt.Sprintf(" ... %d levels...", len(frames)-2*stackErrorFrames))
+	for i := stackErrorFrames - 1; i >= 0; i-- {
+		backtrace = append(backtrace, frames[i].(*object.Location).String())
+	}
+	object.SetBacktrace(err, backtrace)
+	return err
+}
+
+// blockLabel returns the label of the frame of a block defined within the
+// innermost frame, like `block in foo` or `block (2 levels) in foo`
+func blockLabel(env object.Environment) string {
+	stack := callStack(env)
+	label := stack.Elements[len(stack.Elements)-1].(*object.Location).Label
+	var levels int
+	if n, _ := fmt.Sscanf(label, "block (%d levels) in ", &levels); n == 1 {
+		return fmt.Sprintf("block (%d levels) in %s", levels+1, label[strings.Index(label, " in ")+4:])
+	}
+	if strings.HasPrefix(label, "block in ") {
+		return "block (2 levels) in " + strings.TrimPrefix(label, "block in ")
+	}
+	return "block in " + label
+}
+
+// recordBacktrace sets the backtrace of the exception err to the frames of
+// the call stack, unless it has one already, and reports the exception to
+// the TracePoints enabled for raise events. The position of the innermost
+// frame is updated to the one of statement, which raised err. It returns
+// err, or the error raised by a TracePoint.
+func recordBacktrace(env object.Environment, statement ast.Statement, err error) error {
+	tok, _ := statementToken(statement)
+	return raisedAt(env, tok, err)
+}
+
+// raisedAt records the backtrace of err like recordBacktrace, with the
+// innermost frame at tok, the token of the expression raising err. As the
+// innermost expression raising err records it first, the backtrace points
+// at it rather than at the statement holding it. Along with the backtrace,
+// the position of tok is recorded as the position of err.
+func raisedAt(env object.Environment, tok token.Token, err error) error {
+	if !setBacktrace(env, tok, err) || !object.Tracing() {
+		return err
+	}
+	if traceErr := traceRaise(env, err); traceErr != nil {
+		return traceErr
+	}
+	return err
+}
+
+// setBacktrace sets the backtrace of err like raisedAt and reports whether
+// it had none before. The innermost frame is left where it is if tok has
+// no line.
+func setBacktrace(env object.Environment, tok token.Token, err error) bool {
+	if backtrace, ok := object.Backtrace(err); !ok || backtrace != nil {
+		return false
+	}
+	var stack *object.Array
+	if tok.Line != 0 {
+		stack = updateLocation(env, tok)
+	} else {
+		stack = callStack(env)
+	}
+	backtrace := make([]string, 0, len(stack.Elements))
+	for i := len(stack.Elements) - 1; i >= 0; i-- {
+		backtrace = append(backtrace, stack.Elements[i].(*object.Location).String())
+	}
+	object.SetBacktrace(err, backtrace)
+	if tok.Line != 0 {
+		object.SetPosition(err, object.SourcePosition{Path: currentFile(env), Line: tok.Line, Offset: tok.Pos})
+	}
+	return true
+}
+
+// callerFrames returns copies of the frames of the call stack, innermost
+// first, skipping as many frames as given by the first argument, which
+// defaults to 1. The second argument limits the number of frames returned.
+// It returns nil if more frames should be skipped than there are.
+func callerFrames(env object.Environment, args []object.RubyObject) (*object.Array, error) {
+	args, _ = splitBlock(args)
+	if len(args) > 2 {
+		return nil, object.NewWrongNumberOfArgumentsRangeError(0, 2, len(args))
+	}
+	start, length := 1, -1
+	for i, arg := range args {
+		value, ok := arg.(*object.Integer)
+		if !ok {
+			return nil, object.NewImplicitConversionTypeError(&object.Integer{}, arg)
+		}
+		if value.Value < 0 {
+			if i == 0 {
+				return nil, object.NewArgumentError("negative level (%d)", value.Value)
+			}
+			return nil, object.NewArgumentError("negative size (%d)", value.Value)
+		}
+		if i == 0 {
+			start = int(value.Value)
+		} else {
+			length = int(value.Value)
+		}
+	}
+	stack := callStack(env).Elements
+	if start > len(stack) {
+		return nil, nil
+	}
+	frames := object.NewArray()
+	for i := len(stack) - 1 - start; i >= 0 && length != 0; i-- {
+		frame := *stack[i].(*object.Location)
+		frames.Elements = append(frames.Elements, &frame)
+		length--
+	}
+	return frames, nil
+}
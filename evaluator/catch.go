@@ -0,0 +1,82 @@
+package evaluator
+
+import "github.com/goruby/goruby/object"
+
+func init() {
+	evaluatorFunctions["catch"] = evalCatch
+	evaluatorFunctions["throw"] = evalThrow
+}
+
+// catchTagsKey is the name under which the tags of all active catch blocks
+// are stored within the root environment. It is no valid Ruby identifier and
+// thus not accessible from within Ruby code.
+const catchTagsKey = "catch tags"
+
+// throwError unwinds the evaluation up to the catch block with the given
+// tag, which then returns value
+type throwError struct {
+	tag   object.RubyObject
+	value object.RubyObject
+}
+
+func (t *throwError) Error() string { return "uncaught throw " + t.tag.Inspect() }
+
+func catchTags(env object.Environment) *object.Array {
+	tags, ok := env.Get(catchTagsKey)
+	if !ok {
+		tags = env.SetGlobal(catchTagsKey, object.NewArray())
+	}
+	return tags.(*object.Array)
+}
+
+// isSameObject reports whether a and b are the very same object
+func isSameObject(a, b object.RubyObject) bool {
+	return object.ObjectID(a) == object.ObjectID(b)
+}
+
+// evalCatch calls the block with the tag passed as argument, or a new
+// Object if there is none. It returns the value thrown to the tag within
+// the block, or the result of the block otherwise.
+func evalCatch(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	args, block := splitBlock(args)
+	if len(args) > 1 {
+		return nil, object.NewWrongNumberOfArgumentsRangeError(0, 1, len(args))
+	}
+	if block == nil {
+		return nil, object.NewArgumentError("no block given")
+	}
+	var tag object.RubyObject = &object.Object{}
+	if len(args) == 1 {
+		tag = args[0]
+	}
+	tags := catchTags(env)
+	tags.Elements = append(tags.Elements, tag)
+	defer func() { tags.Elements = tags.Elements[:len(tags.Elements)-1] }()
+
+	result, err := block.Call(tag)
+	if thrown, ok := err.(*throwError); ok && isSameObject(thrown.tag, tag) {
+		return thrown.value, nil
+	}
+	return result, err
+}
+
+// evalThrow unwinds the evaluation up to the active catch block with the
+// tag passed as first argument. It returns an UncaughtThrowError if there
+// is no such block.
+func evalThrow(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	args, _ = splitBlock(args)
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewWrongNumberOfArgumentsRangeError(1, 2, len(args))
+	}
+	tag := args[0]
+	var value object.RubyObject = object.NIL
+	if len(args) == 2 {
+		value = args[1]
+	}
+	for _, active := range catchTags(env).Elements {
+		if isSameObject(active, tag) {
+			return nil, &throwError{tag: tag, value: value}
+		}
+	}
+	return nil, object.NewUncaughtThrowError(tag, value)
+}
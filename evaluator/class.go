@@ -0,0 +1,164 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// currentMethodKey is the name under which the method being executed is
+// stored within the environment of its body. It is no valid Ruby identifier
+// and thus not accessible from within Ruby code.
+const currentMethodKey = "current method"
+
+// nestingKey is the name under which the modules lexically enclosing a
+// class or module body are stored as Array, innermost first
+const nestingKey = "module nesting"
+
+// nesting returns the classes and modules lexically enclosing env,
+// innermost first
+func nesting(env object.Environment) []object.RubyObject {
+	modules, ok := env.Get(nestingKey)
+	if !ok {
+		return nil
+	}
+	return modules.(*object.Array).Elements
+}
+
+// lookupConstant returns the constant name defined within the modules
+// lexically enclosing env or the ancestors of the innermost one
+func lookupConstant(name string, env object.Environment) (object.RubyObject, bool) {
+	modules := nesting(env)
+	if len(modules) == 0 {
+		return nil, false
+	}
+	runtime := object.RuntimeOf(env)
+	for _, module := range modules {
+		if value, ok := runtime.ModuleConstant(module, name, false); ok {
+			return value, true
+		}
+	}
+	return runtime.ModuleConstant(modules[0], name, true)
+}
+
+// definedConstant returns the constant name as it is reopened by a class
+// or module definition, which is the one of the innermost enclosing
+// module or a top level one
+func definedConstant(name string, env object.Environment) (object.RubyObject, bool) {
+	if modules := nesting(env); len(modules) != 0 {
+		return object.RuntimeOf(env).ModuleConstant(modules[0], name, false)
+	}
+	return env.Get(name)
+}
+
+// setConstant defines the constant name within the innermost module
+// enclosing env, or at the top level
+func setConstant(name string, value object.RubyObject, env object.Environment) {
+	if modules := nesting(env); len(modules) != 0 {
+		object.RuntimeOf(env).SetModuleConstant(modules[0], name, value)
+		return
+	}
+	object.RuntimeOf(env).SetConstant(name, value)
+}
+
+// evalClassExpression defines the class named by node, or reopens it if it
+// exists already, and evaluates the body with the class as self
+func evalClassExpression(node *ast.ClassExpression, env object.Environment) (object.RubyObject, error) {
+	var superclass object.RubyObject
+	if node.SuperClass != nil {
+		var err error
+		superclass, err = Eval(node.SuperClass, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	name := node.Name.Value
+	class, ok := definedConstant(name, env)
+	if ok {
+		if _, isClass := class.(object.RubyClassObject); !isClass {
+			return nil, object.NewTypeError("%s is not a class", name)
+		}
+		if superclass != nil {
+			current, err := object.RuntimeOf(env).Send(class, "superclass")
+			if err != nil {
+				return nil, err
+			}
+			if current != superclass {
+				return nil, object.NewTypeError("superclass mismatch for class %s", name)
+			}
+		}
+	} else {
+		var args []object.RubyObject
+		if superclass != nil {
+			args = append(args, superclass)
+		}
+		classClass, _ := env.Get("Class")
+		var err error
+		class, err = object.RuntimeOf(env).Send(classClass, "new", args...)
+		if err != nil {
+			return nil, err
+		}
+		setConstant(name, class, env)
+	}
+	return evalDefinitionBody(class, "class_eval", "<class:"+name+">", node.Token, node.Body, env)
+}
+
+// evalModuleExpression defines the module named by node, or reopens it if
+// it exists already, and evaluates the body with the module as self
+func evalModuleExpression(node *ast.ModuleExpression, env object.Environment) (object.RubyObject, error) {
+	name := node.Name.Value
+	module, ok := definedConstant(name, env)
+	if ok {
+		if _, isModule := module.(*object.Module); !isModule {
+			return nil, object.NewTypeError("%s is not a module", name)
+		}
+	} else {
+		moduleClass, _ := env.Get("Module")
+		var err error
+		module, err = object.RuntimeOf(env).Send(moduleClass, "new")
+		if err != nil {
+			return nil, err
+		}
+		setConstant(name, module, env)
+	}
+	return evalDefinitionBody(module, "module_eval", "<module:"+name+">", node.Token, node.Body, env)
+}
+
+// evalDefinitionBody evaluates the body of a class or module definition by
+// passing it as block to the given eval method of module. Within the body
+// module is the innermost of the lexically enclosing modules. The body runs
+// within a frame of its own labeled label, like `<class:Foo>`, called from
+// the definition at tok.
+func evalDefinitionBody(module object.RubyObject, eval, label string, tok token.Token, body *ast.BlockStatement, env object.Environment) (object.RubyObject, error) {
+	block := &ast.BlockLiteral{Body: body}
+	block.Token = tok
+	scope := object.NewEnclosedEnvironment(env)
+	scope.Set(nestingKey, object.NewArray(append([]object.RubyObject{module}, nesting(env)...)...))
+	updateLocation(env, tok)
+	return object.RuntimeOf(env).Send(module, eval, newLabeledProc(block, scope, label))
+}
+
+// evalSuper calls the method overridden by the current method. Without an
+// argument list the current values of the method parameters are passed on.
+func evalSuper(node *ast.Super, env object.Environment) (object.RubyObject, error) {
+	current, ok := env.Get(currentMethodKey)
+	if !ok {
+		return nil, object.NewRuntimeError("super called outside of method")
+	}
+	fn := current.(*object.Function)
+	var args []object.RubyObject
+	if node.Implicit {
+		for _, param := range fn.Parameters {
+			arg, _ := env.Get(param.Value)
+			args = append(args, arg)
+		}
+	} else {
+		var err error
+		args, err = evalExpressions(node.Arguments, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	self, _ := env.Get("self")
+	return object.RuntimeOf(env).CallSuper(self, fn, args...)
+}
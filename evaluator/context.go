@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"context"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// contextKey is the name under which the root environment holds the
+// context of the running evaluation
+const contextKey = "eval context"
+
+// evalContext holds the context of an evaluation within an environment. It
+// is no real Ruby object.
+type evalContext struct {
+	context.Context
+}
+
+func (c *evalContext) Type() object.Type       { return object.Type("CONTEXT") }
+func (c *evalContext) Inspect() string         { return "context" }
+func (c *evalContext) Class() object.RubyClass { return nil }
+
+// EvalContext evaluates node within env like Eval, but stops once ctx is
+// done. The context is checked at the back-edges of loops and on calls of
+// methods and blocks, which then return an Interrupt unwrapping to the
+// error of ctx. Builtin methods blocking meanwhile, like sleep or
+// Queue#pop, return it as well.
+func EvalContext(ctx context.Context, node ast.Node, env object.Environment) (object.RubyObject, error) {
+	defer SetContext(env, ctx)()
+	return Eval(node, env)
+}
+
+// SetContext makes evaluations within env stop once ctx is done, like
+// EvalContext does. It returns a function restoring the previous context.
+func SetContext(env object.Environment, ctx context.Context) (restore func()) {
+	previous, _ := env.Get(contextKey)
+	env.SetGlobal(contextKey, &evalContext{ctx})
+	stop := object.RuntimeOf(env).StopWhen(ctx.Done(), func() error {
+		return object.NewContextInterrupt(ctx.Err())
+	})
+	return func() {
+		stop()
+		if previous == nil {
+			previous = &evalContext{}
+		}
+		env.SetGlobal(contextKey, previous)
+	}
+}
+
+// currentContext returns the context the evaluation within env runs with,
+// or the background context if there is none
+func currentContext(env object.Environment) context.Context {
+	ctx, ok := env.Get(contextKey)
+	if evalCtx, isContext := ctx.(*evalContext); ok && isContext && evalCtx.Context != nil {
+		return evalCtx.Context
+	}
+	return context.Background()
+}
+
+// CheckContext returns an Interrupt if the context the evaluation within
+// env runs with is done, a ResourceLimitError if the evaluation exceeded
+// its limits, and nil otherwise. Every check counts as an operation.
+func CheckContext(env object.Environment) error {
+	if err := checkLimits(env); err != nil {
+		return err
+	}
+	if err := currentContext(env).Err(); err != nil {
+		return object.NewContextInterrupt(err)
+	}
+	return nil
+}
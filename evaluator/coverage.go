@@ -0,0 +1,32 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// registerCoverage adds the file path, holding source parsed to program,
+// to the coverage measured. The lines starting a statement are the
+// executable ones.
+func registerCoverage(program *ast.Program, source, path string) {
+	var executable []int
+	ast.Inspect(program, func(node ast.Node) bool {
+		if statement, ok := node.(ast.Statement); ok {
+			if tok, ok := statementToken(statement); ok && tok.Line != 0 {
+				executable = append(executable, tok.Line)
+			}
+		}
+		return true
+	})
+	object.RegisterCoverage(path, strings.Count(strings.TrimSuffix(source, "\n"), "\n")+1, executable)
+}
+
+// coverLine counts the execution of the line of statement, which is about
+// to be evaluated
+func coverLine(statement ast.Statement, env object.Environment) {
+	if tok, ok := statementToken(statement); ok && tok.Line != 0 {
+		object.CoverLine(currentFile(env), tok.Line)
+	}
+}
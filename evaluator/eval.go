@@ -0,0 +1,63 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/parser"
+)
+
+func init() {
+	evaluatorFunctions["binding"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		args, _ = splitBlock(args)
+		if len(args) != 0 {
+			return nil, object.NewWrongNumberOfArgumentsError(0, len(args))
+		}
+		binding := object.NewBinding(env)
+		binding.EvalFn = evalSourceIn
+		return binding, nil
+	}
+	evaluatorFunctions["eval"] = evalString
+}
+
+// splitBlock splits args into the regular arguments and the block passed as
+// last argument, if any
+func splitBlock(args []object.RubyObject) ([]object.RubyObject, *object.Proc) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	block, ok := args[len(args)-1].(*object.Proc)
+	if !ok {
+		return args, nil
+	}
+	return args[:len(args)-1], block
+}
+
+// evalString evaluates the Ruby source passed as first argument within the
+// Binding passed as second argument or env if there is none
+func evalString(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	args, _ = splitBlock(args)
+	if len(args) < 1 || len(args) > 2 {
+		return nil, object.NewWrongNumberOfArgumentsRangeError(1, 2, len(args))
+	}
+	source, ok := args[0].(*object.String)
+	if !ok {
+		return nil, object.NewImplicitConversionTypeError(&object.String{}, args[0])
+	}
+	if len(args) == 2 && args[1] != object.NIL {
+		binding, ok := args[1].(*object.Binding)
+		if !ok {
+			return nil, object.NewTypeError("wrong argument type %s (expected binding)", args[1].Class().(object.RubyObject).Inspect())
+		}
+		env = binding.Env
+	}
+	return evalSourceIn(source.Value, env)
+}
+
+// evalSourceIn parses source and evaluates it within env
+func evalSourceIn(source string, env object.Environment) (object.RubyObject, error) {
+	program, err := parser.New(lexer.New(source)).ParseProgram()
+	if err != nil {
+		return nil, object.NewFileSyntaxError("(eval)", err)
+	}
+	return Eval(program, env)
+}
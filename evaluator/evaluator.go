@@ -2,39 +2,57 @@ package evaluator
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
 	"strings"
 
 	"github.com/goruby/goruby/ast"
-	"github.com/goruby/goruby/lexer"
 	"github.com/goruby/goruby/object"
-	"github.com/goruby/goruby/parser"
 )
 
+func init() {
+	evaluatorFunctions["at_exit"] = registerAtExit
+	evaluatorFunctions["raise"] = evalRaise
+	evaluatorFunctions["fail"] = evalRaise
+}
+
 // Eval evaluates the given node and traverses recursive over its children
 func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 	switch node := node.(type) {
 
 	// Statements
 	case *ast.Program:
+		if _, ok := env.Get("DATA"); node.HasData && !ok {
+			env.Set("DATA", object.NewIO(strings.NewReader(node.Data)))
+		}
+		declareLocals(node)
 		return evalProgram(node.Statements, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return object.NewReturnValue(object.NIL), nil
+		}
 		val, err := Eval(node.ReturnValue, env)
 		if err != nil {
 			return nil, err
 		}
-		return &object.ReturnValue{Value: val}, nil
+		return object.NewReturnValue(val), nil
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
+	case *ast.BreakStatement:
+		return evalBreakStatement(node, env)
+	case *ast.NextStatement:
+		return evalNextStatement(node, env)
+	case *ast.EndBlock:
+		registerEndBlock(node, env)
+		return object.NIL, nil
 
 	// Expressions
 
 	// Literals
 	case (*ast.IntegerLiteral):
 		return object.NewInteger(node.Value), nil
+	case (*ast.FloatLiteral):
+		return object.NewFloat(node.Value), nil
 	case (*ast.Boolean):
 		return nativeBoolToBooleanObject(node.Value), nil
 	case (*ast.Nil):
@@ -43,35 +61,91 @@ func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 		self, _ := env.Get("self")
 		return self, nil
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		result, err := evalIdentifier(node, env)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		return result, nil
+	case *ast.ScopedConstant:
+		result, err := evalScopedConstant(node, env)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		return result, nil
 	case *ast.StringLiteral:
-		return &object.String{Value: node.Value}, nil
+		if node.Frozen {
+			return object.NewFrozenString(node.Value), nil
+		}
+		return CountObject(env, &object.String{Value: node.Value})
 	case *ast.SymbolLiteral:
-		return &object.Symbol{Value: node.Value}, nil
+		return object.NewSymbol(node.Value), nil
+	case *ast.ObjectLiteral:
+		return node.Value.(object.RubyObject), nil
+	case *ast.RegexLiteral:
+		return object.NewRegexp(node.Value, node.Options)
+	case *ast.CommandLiteral:
+		updateLocation(env, node.Token)
+		return evalBacktick(env, &object.String{Value: node.Value})
+	case *ast.RangeLiteral:
+		first, err := Eval(node.Left, env)
+		if err != nil {
+			return nil, err
+		}
+		last, err := Eval(node.Right, env)
+		if err != nil {
+			return nil, err
+		}
+		return object.NewRange(first, last, node.Exclusive)
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
 		context, _ := env.Get("self")
 		function := &object.Function{
+			Name:       node.Name.Value,
 			Parameters: params,
 			Env:        env,
 			Body:       body,
 			CallFn:     applyFunction,
+			Scope:      resolveScope(node),
+			Line:       node.Token.Line,
 		}
-		object.AddMethod(context, node.Name.Value, function)
+		if file, ok := env.Get(currentFileKey); ok {
+			function.File, _ = file.(*object.String)
+		}
+		if err := warnMethodDefinition(node, context, env); err != nil {
+			return nil, err
+		}
+		object.RuntimeOf(env).AddMethod(context, node.Name.Value, function)
 		return function, nil
 	case *ast.ArrayLiteral:
 		elements, err := evalExpressions(node.Elements, env)
 		if err != nil {
 			return nil, err
 		}
-		return &object.Array{Elements: elements}, nil
+		return CountObject(env, object.NewArray(elements...))
+	case *ast.HashLiteral:
+		hash, err := evalHashLiteral(node, env)
+		if err != nil {
+			return nil, err
+		}
+		return CountObject(env, hash)
 	case *ast.VariableAssignment:
 		val, err := Eval(node.Value, env)
 		if err != nil {
 			return nil, err
 		}
-		env.Set(node.Name.Value, val)
+		if node.Name.Value == "$_" {
+			object.RuntimeOf(env).SetLastLine(val)
+			return val, nil
+		}
+		if strings.HasPrefix(node.Name.Value, "$") {
+			return env.SetGlobal(node.Name.Value, val), nil
+		}
+		if object.IsConstantName(node.Name.Value) {
+			setConstant(node.Name.Value, val, env)
+			return val, nil
+		}
+		setVariable(node.Name, env, val)
 		return val, nil
 	case *ast.ContextCallExpression:
 		context, err := Eval(node.Context, env)
@@ -85,26 +159,58 @@ func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 		if err != nil {
 			return nil, err
 		}
-		if function, ok := env.Get(node.Function.Value); ok {
-			return applyFunction(function, args)
+		if node.Block != nil {
+			args = append(args, newProc(node.Block, env))
+		}
+		result, err := Call(node, context, args, env)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		if node.Function.Value == "new" {
+			return CountObject(env, result)
 		}
-		return object.Send(context, node.Function.Value, args...)
+		return result, nil
 	case *ast.IndexExpression:
 		left, err := Eval(node.Left, env)
 		if err != nil {
 			return nil, err
 		}
-		index, err := Eval(node.Index, env)
+		args, err := evalIndexArguments(node, env)
+		if err != nil {
+			return nil, err
+		}
+		result, err := evalIndexExpression(object.RuntimeOf(env), left, args...)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		return result, nil
+	case *ast.IndexAssignment:
+		left, err := Eval(node.Target.Left, env)
+		if err != nil {
+			return nil, err
+		}
+		args, err := evalIndexArguments(node.Target, env)
 		if err != nil {
 			return nil, err
 		}
-		return evalIndexExpression(left, index)
+		value, err := Eval(node.Value, env)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := object.RuntimeOf(env).Send(left, "[]=", append(args, value)...); err != nil {
+			return nil, raisedAt(env, node.Target.Token, err)
+		}
+		return value, nil
 	case *ast.PrefixExpression:
 		right, err := Eval(node.Right, env)
 		if err != nil {
 			return nil, err
 		}
-		return evalPrefixExpression(node.Operator, right)
+		result, err := evalPrefixExpression(object.RuntimeOf(env), node.Operator, right)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		return result, nil
 	case *ast.InfixExpression:
 		left, err := Eval(node.Left, env)
 		if err != nil {
@@ -115,9 +221,27 @@ func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 		if err != nil {
 			return nil, err
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		result, err := evalInfixExpression(object.RuntimeOf(env), node.Operator, left, right)
+		if err != nil {
+			return nil, raisedAt(env, node.Token, err)
+		}
+		return result, nil
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.CaseExpression:
+		return evalCaseExpression(node, env)
+	case *ast.BeginExpression:
+		return evalBeginExpression(node, env)
+	case *ast.ClassExpression:
+		return evalClassExpression(node, env)
+	case *ast.ModuleExpression:
+		return evalModuleExpression(node, env)
+	case *ast.Super:
+		return evalSuper(node, env)
+	case *ast.WhileExpression:
+		return evalWhileExpression(node, env)
+	case *ast.RescueModifier:
+		return evalRescueModifier(node, env)
 	case *ast.RequireExpression:
 		return evalRequireExpression(node, env)
 	case nil:
@@ -130,85 +254,154 @@ func Eval(node ast.Node, env object.Environment) (object.RubyObject, error) {
 }
 
 func evalProgram(stmts []ast.Statement, env object.Environment) (object.RubyObject, error) {
-	var result object.RubyObject
+	var result object.RubyObject = object.NIL
 	var err error
 	for _, statement := range stmts {
-		result, err = Eval(statement, env)
+		if beginBlock, ok := statement.(*ast.BeginBlock); ok {
+			_, err = Eval(beginBlock.Body, env)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, statement := range stmts {
+		if _, ok := statement.(*ast.BeginBlock); ok {
+			continue
+		}
+		result, err = evalStatement(statement, env)
 
+		if ret, ok := err.(*returnError); ok {
+			return ret.value, nil
+		}
 		if err != nil {
-			return nil, err
+			return nil, recordBacktrace(env, statement, err)
 		}
 
-		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value, nil
-		case *object.Builtin:
-			return result.Fn(), nil
+		if returnValue, ok := result.(*object.ReturnValue); ok {
+			return object.ReleaseReturnValue(returnValue), nil
 		}
 
 	}
 	return result, nil
 }
 
-func evalExpressions(exps []ast.Expression, env object.Environment) ([]object.RubyObject, error) {
-	var result []object.RubyObject
+// exitHandlersKey is the name under which the handlers to run at exit are
+// stored within the root environment. It is no valid Ruby identifier and thus
+// not accessible from within Ruby code.
+const exitHandlersKey = "exit handlers"
 
-	for _, e := range exps {
-		evaluated, err := Eval(e, env)
-		if err != nil {
-			return nil, err
+// registerEndBlock registers the body of block to run at exit. Each END
+// block is registered only once, regardless of how often it is evaluated.
+func registerEndBlock(block *ast.EndBlock, env object.Environment) {
+	handlers := exitHandlers(env)
+	for _, handler := range handlers.Elements {
+		if handler.(*object.Function).Body == block.Body {
+			return
 		}
-		result = append(result, evaluated)
 	}
-	return result, nil
+	handler := &object.Function{
+		Parameters: []*ast.Identifier{},
+		Body:       block.Body,
+		Env:        env,
+		CallFn: func(context object.RubyObject, args []object.RubyObject) (object.RubyObject, error) {
+			return Eval(block.Body, env)
+		},
+	}
+	handlers.Elements = append(handlers.Elements, handler)
 }
 
-func evalRequireExpression(expr *ast.RequireExpression, env object.Environment) (object.RubyObject, error) {
-	filename := expr.Name.Value
-	if !strings.HasSuffix(filename, "rb") {
-		filename += ".rb"
+// registerAtExit registers the block passed to Kernel#at_exit to run at
+// exit. Unlike END blocks it is registered each time it is called.
+func registerAtExit(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	args, block := splitBlock(args)
+	if len(args) != 0 {
+		return nil, object.NewWrongNumberOfArgumentsError(0, len(args))
 	}
-	loadedFeatures, ok := env.Get("$LOADED_FEATURES")
-	if !ok {
-		loadedFeatures = object.NewArray()
-		env.SetGlobal("$LOADED_FEATURES", loadedFeatures)
+	if block == nil {
+		return nil, object.NewArgumentError("called without a block")
 	}
-	arr, ok := loadedFeatures.(*object.Array)
+	handler := &object.Function{
+		Parameters: []*ast.Identifier{},
+		Env:        env,
+		CallFn: func(context object.RubyObject, args []object.RubyObject) (object.RubyObject, error) {
+			return block.Call()
+		},
+	}
+	handlers := exitHandlers(env)
+	handlers.Elements = append(handlers.Elements, handler)
+	return block, nil
+}
+
+func exitHandlers(env object.Environment) *object.Array {
+	handlers, ok := env.Get(exitHandlersKey)
 	if !ok {
-		arr = object.NewArray()
+		handlers = env.SetGlobal(exitHandlersKey, object.NewArray())
 	}
-	loaded := false
-	for _, feat := range arr.Elements {
-		if feat.Inspect() == filename {
-			loaded = true
-			break
+	return handlers.(*object.Array)
+}
+
+// RunExitHandlers runs all handlers registered to run at exit, like END
+// blocks, in reverse order of their registration. Every handler is run only
+// once. If a handler raises an exception the remaining handlers are still
+// run and the last exception is returned.
+func RunExitHandlers(env object.Environment) error {
+	handlers := exitHandlers(env)
+	var lastErr error
+	for len(handlers.Elements) > 0 {
+		last := len(handlers.Elements) - 1
+		handler := handlers.Elements[last].(*object.Function)
+		handlers.Elements = handlers.Elements[:last]
+		if _, err := handler.Call(nil); err != nil {
+			lastErr = err
 		}
 	}
-	if loaded {
-		return object.FALSE, nil
+	return lastErr
+}
+
+func evalExpressions(exps []ast.Expression, env object.Environment) ([]object.RubyObject, error) {
+	if len(exps) == 0 {
+		return nil, nil
 	}
+	result := make([]object.RubyObject, 0, len(exps))
 
-	arr.Elements = append(arr.Elements, &object.String{Value: filename})
-	file, err := ioutil.ReadFile(filename)
-	if os.IsNotExist(err) {
-		return nil, object.NewLoadError(expr.Name.Value)
+	for _, e := range exps {
+		evaluated, err := Eval(e, env)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, evaluated)
 	}
-	l := lexer.New(string(file))
-	p := parser.New(l)
-	prog, err := p.ParseProgram()
-	if err != nil {
-		return nil, object.NewSyntaxError(err.Error())
+	return result, nil
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env object.Environment) (object.RubyObject, error) {
+	hash := object.NewHash(nil)
+	for i, k := range node.Keys {
+		key, err := Eval(k, env)
+		if err != nil {
+			return nil, err
+		}
+		value, err := Eval(node.Values[i], env)
+		if err != nil {
+			return nil, err
+		}
+		hash.Set(key, value)
 	}
-	Eval(prog, env)
-	return object.TRUE, nil
+	return hash, nil
 }
 
-func evalPrefixExpression(operator string, right object.RubyObject) (object.RubyObject, error) {
+func evalRequireExpression(expr *ast.RequireExpression, env object.Environment) (object.RubyObject, error) {
+	return requireFeature(expr.Name.Value, env)
+}
+
+func evalPrefixExpression(runtime *object.Runtime, operator string, right object.RubyObject) (object.RubyObject, error) {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right), nil
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return runtime.Send(right, "~")
 	default:
 		return nil, object.NewException("unknown operator: %s%s", operator, right.Type())
 	}
@@ -230,22 +423,57 @@ func evalBangOperatorExpression(right object.RubyObject) object.RubyObject {
 func evalMinusPrefixOperatorExpression(right object.RubyObject) (object.RubyObject, error) {
 	switch right := right.(type) {
 	case *object.Integer:
-		return &object.Integer{Value: -right.Value}, nil
+		return object.NewInteger(-right.Value), nil
+	case *object.Float:
+		return object.NewFloat(-right.Value), nil
 	default:
 		return nil, object.NewException("unknown operator: -%s", right.Type())
 	}
 }
 
-func evalInfixExpression(operator string, left, right object.RubyObject) (object.RubyObject, error) {
+// sendOperators are the infix operators which are always evaluated by
+// sending them as method to the left operand
+var sendOperators = map[string]bool{
+	"%":  true,
+	"**": true,
+	"&":  true,
+	"|":  true,
+	"^":  true,
+	">>": true,
+}
+
+func evalInfixExpression(runtime *object.Runtime, operator string, left, right object.RubyObject) (object.RubyObject, error) {
 	switch {
+	case operator == "<=>" || operator == "<<" || operator == "=~":
+		return runtime.Send(left, operator, right)
+	case sendOperators[operator]:
+		return runtime.Send(left, operator, right)
+	case operator == "!~":
+		result, err := runtime.Send(left, "=~", right)
+		if err != nil {
+			return nil, err
+		}
+		return nativeBoolToBooleanObject(!isTruthy(result)), nil
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
-	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
-		return evalStringInfixExpression(operator, left, right)
+		return evalIntegerInfixExpression(runtime, operator, left, right)
+	case isNumeric(left) && isNumeric(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right), nil
+		return runtime.Send(left, "==", right)
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right), nil
+		result, err := runtime.Send(left, "==", right)
+		if err != nil {
+			return nil, err
+		}
+		return nativeBoolToBooleanObject(!isTruthy(result)), nil
+	case operator == "<" || operator == ">":
+		return runtime.Send(left, operator, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && operator == "*":
+		return runtime.Send(left, operator, right)
+	case !isNumeric(left) && left.Type() != object.STRING_OBJ && respondsTo(runtime, left, operator):
+		return runtime.Send(left, operator, right)
 	case left.Type() != right.Type():
 		return nil, object.NewException("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -253,18 +481,62 @@ func evalInfixExpression(operator string, left, right object.RubyObject) (object
 	}
 }
 
-func evalIntegerInfixExpression(operator string, left, right object.RubyObject) (object.RubyObject, error) {
+// respondsTo reports whether obj has a public method for the operator, so
+// that operators of other objects than numbers and Strings, like Time, are
+// sent as method
+func respondsTo(runtime *object.Runtime, obj object.RubyObject, operator string) bool {
+	_, ok := runtime.MethodFrom(object.NIL, obj, operator)
+	return ok
+}
+
+func evalIntegerInfixExpression(runtime *object.Runtime, operator string, left, right object.RubyObject) (object.RubyObject, error) {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}, nil
+		return object.NewInteger(leftVal + rightVal), nil
+	case "-":
+		return object.NewInteger(leftVal - rightVal), nil
+	case "*":
+		return object.NewInteger(leftVal * rightVal), nil
+	case "/":
+		return runtime.Send(left, operator, right)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal), nil
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal), nil
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal), nil
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal), nil
+	default:
+		return nil, object.NewException("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func isNumeric(obj object.RubyObject) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.RubyObject) float64 {
+	if i, ok := obj.(*object.Integer); ok {
+		return float64(i.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
+func evalFloatInfixExpression(operator string, left, right object.RubyObject) (object.RubyObject, error) {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+	switch operator {
+	case "+":
+		return object.NewFloat(leftVal + rightVal), nil
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}, nil
+		return object.NewFloat(leftVal - rightVal), nil
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}, nil
+		return object.NewFloat(leftVal * rightVal), nil
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}, nil
+		return object.NewFloat(leftVal / rightVal), nil
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal), nil
 	case ">":
@@ -304,32 +576,321 @@ func evalIfExpression(ie *ast.IfExpression, env object.Environment) (object.Ruby
 	}
 }
 
-func evalIndexExpression(left, index object.RubyObject) (object.RubyObject, error) {
-	switch {
-	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
-		return evalArrayIndexExpression(left, index), nil
-	default:
-		return nil, object.NewException("index operator not supported: %s", left.Type())
+func evalCaseExpression(ce *ast.CaseExpression, env object.Environment) (object.RubyObject, error) {
+	var subject object.RubyObject
+	if ce.Subject != nil {
+		var err error
+		subject, err = Eval(ce.Subject, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, when := range ce.Whens {
+		for _, condition := range when.Conditions {
+			matches, err := evalWhenCondition(condition, subject, env)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				return Eval(when.Body, env)
+			}
+		}
+	}
+	if ce.Else != nil {
+		return Eval(ce.Else, env)
+	}
+	return object.NIL, nil
+}
+
+// evalWhenCondition reports whether condition matches subject by sending
+// `===` to it. Without subject the condition itself must be truthy.
+func evalWhenCondition(condition ast.Expression, subject object.RubyObject, env object.Environment) (bool, error) {
+	value, err := Eval(condition, env)
+	if err != nil {
+		return false, err
+	}
+	if subject == nil {
+		return isTruthy(value), nil
+	}
+	matches, err := object.RuntimeOf(env).Send(value, "===", subject)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(matches), nil
+}
+
+// breakError unwinds the evaluation up to the innermost loop, which then
+// returns value
+type breakError struct {
+	value object.RubyObject
+}
+
+func (b *breakError) Error() string { return "break from proc-closure" }
+
+func evalBreakStatement(bs *ast.BreakStatement, env object.Environment) (object.RubyObject, error) {
+	var value object.RubyObject = object.NIL
+	if bs.Value != nil {
+		var err error
+		value, err = Eval(bs.Value, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, &breakError{value: value}
+}
+
+// nextError unwinds the evaluation up to the innermost block, which then
+// returns value, or the innermost loop, which continues with the next
+// iteration
+type nextError struct {
+	value object.RubyObject
+}
+
+func (n *nextError) Error() string { return "unexpected next" }
+
+func evalNextStatement(ns *ast.NextStatement, env object.Environment) (object.RubyObject, error) {
+	var value object.RubyObject = object.NIL
+	if ns.Value != nil {
+		var err error
+		value, err = Eval(ns.Value, env)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return nil, &nextError{value: value}
 }
 
-func evalArrayIndexExpression(array, index object.RubyObject) object.RubyObject {
-	arrayObject := array.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrayObject.Elements) - 1)
-	if idx < 0 || idx > max {
-		return object.NIL
+// evalBeginExpression evaluates the body and its rescue clauses. The ensure
+// clause is evaluated afterwards, however the body is left. An explicit
+// return or an exception within it takes precedence over the result.
+func evalBeginExpression(node *ast.BeginExpression, env object.Environment) (object.RubyObject, error) {
+	result, err := evalRescueClauses(node, env)
+	if node.Ensure == nil {
+		return result, err
 	}
-	return arrayObject.Elements[idx]
+	ensured, ensureErr := Eval(node.Ensure, env)
+	if ensureErr != nil {
+		return nil, ensureErr
+	}
+	if _, ok := ensured.(*object.ReturnValue); ok {
+		return ensured, nil
+	}
+	return result, err
+}
+
+// evalRescueClauses evaluates the body and hands an exception raised within
+// it to the first rescue clause matching it
+func evalRescueClauses(node *ast.BeginExpression, env object.Environment) (object.RubyObject, error) {
+	result, err := Eval(node.Body, env)
+	if err == nil || len(node.Rescues) == 0 {
+		return result, err
+	}
+	exception, ok := err.(object.RubyObject)
+	if !ok || !IsError(exception) {
+		return nil, err
+	}
+	for _, rescue := range node.Rescues {
+		matches, rescueErr := rescueMatches(rescue, exception, env)
+		if rescueErr != nil {
+			return nil, rescueErr
+		}
+		if matches {
+			if rescue.Exception != nil {
+				env.Set(rescue.Exception.Value, exception)
+			}
+			defer setErrorInfo(env, setErrorInfo(env, exception))
+			result, err := Eval(rescue.Body, env)
+			if err != nil {
+				object.SetCause(err, exception)
+			}
+			return result, err
+		}
+	}
+	return nil, err
+}
+
+// evalRescueModifier evaluates the expression, or the rescue value if it
+// raises a StandardError. Like within a rescue clause `$!` refers to the
+// exception meanwhile.
+func evalRescueModifier(node *ast.RescueModifier, env object.Environment) (object.RubyObject, error) {
+	result, err := Eval(node.Expression, env)
+	exception, ok := err.(object.RubyObject)
+	if !ok || !object.IsStandardError(err) {
+		return result, err
+	}
+	defer setErrorInfo(env, setErrorInfo(env, exception))
+	result, err = Eval(node.Rescue, env)
+	if err != nil {
+		object.SetCause(err, exception)
+	}
+	return result, err
+}
+
+// setErrorInfo sets `$!` and its aliases to exception, the exception being
+// handled, and returns the previous value
+func setErrorInfo(env object.Environment, exception object.RubyObject) object.RubyObject {
+	previous, ok := env.Get("$!")
+	if !ok {
+		previous = object.NIL
+	}
+	env.SetGlobal("$!", exception)
+	env.SetGlobal("$ERROR_INFO", exception)
+	backtrace := object.RubyObject(object.NIL)
+	if exception != object.NIL {
+		backtrace, _ = object.RuntimeOf(env).Send(exception, "backtrace")
+	}
+	env.SetGlobal("$@", backtrace)
+	return previous
+}
+
+// evalRaise re-raises the exception being handled if called without
+// arguments within a rescue clause. Otherwise it calls Kernel#raise.
+func evalRaise(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	if rest, _ := splitBlock(args); len(rest) == 0 {
+		if current, ok := env.Get("$!"); ok && current != object.NIL {
+			return nil, current.(error)
+		}
+	}
+	self, _ := env.Get("self")
+	return object.RuntimeOf(env).Send(self, "raise", args...)
+}
+
+// rescueMatches reports whether the rescue clause handles exception. A
+// clause without exception classes handles all StandardErrors.
+func rescueMatches(rescue *ast.RescueBlock, exception object.RubyObject, env object.Environment) (bool, error) {
+	if len(rescue.ExceptionClasses) == 0 {
+		return object.IsStandardError(exception.(error)), nil
+	}
+	classes, err := evalRescueClasses(rescue.ExceptionClasses, env)
+	if err != nil {
+		return false, err
+	}
+	for _, class := range classes {
+		switch class.(type) {
+		case object.RubyClassObject, *object.Module:
+		default:
+			return false, object.NewTypeError("class or module required for rescue clause")
+		}
+		matches, err := object.RuntimeOf(env).Send(class, "===", exception)
+		if err != nil {
+			return false, err
+		}
+		if isTruthy(matches) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// evalRescueClasses evaluates the exception classes of a rescue clause,
+// expanding splatted Arrays
+func evalRescueClasses(expressions []ast.Expression, env object.Environment) ([]object.RubyObject, error) {
+	var classes []object.RubyObject
+	for _, expression := range expressions {
+		splat, isSplat := expression.(*ast.Splat)
+		if isSplat {
+			expression = splat.Value
+		}
+		class, err := Eval(expression, env)
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := class.(*object.Array); ok && isSplat {
+			classes = append(classes, list.Elements...)
+			continue
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+func evalWhileExpression(we *ast.WhileExpression, env object.Environment) (object.RubyObject, error) {
+	for {
+		if err := object.RuntimeOf(env).CheckInterrupt(); err != nil {
+			return nil, err
+		}
+		if err := CheckContext(env); err != nil {
+			return nil, err
+		}
+		condition, err := Eval(we.Condition, env)
+		if err != nil {
+			return nil, err
+		}
+		if !isTruthy(condition) {
+			return object.NIL, nil
+		}
+		result, err := Eval(we.Body, env)
+		if brk, ok := err.(*breakError); ok {
+			return brk.value, nil
+		}
+		if _, ok := err.(*nextError); ok {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.(*object.ReturnValue); ok {
+			return result, nil
+		}
+	}
+}
+
+// evalIndexArguments evaluates the index and the optional length of node
+func evalIndexArguments(node *ast.IndexExpression, env object.Environment) ([]object.RubyObject, error) {
+	index, err := Eval(node.Index, env)
+	if err != nil {
+		return nil, err
+	}
+	if node.Length == nil {
+		return []object.RubyObject{index}, nil
+	}
+	length, err := Eval(node.Length, env)
+	if err != nil {
+		return nil, err
+	}
+	return []object.RubyObject{index, length}, nil
+}
+
+func evalIndexExpression(runtime *object.Runtime, left object.RubyObject, args ...object.RubyObject) (object.RubyObject, error) {
+	return runtime.Send(left, "[]", args...)
+}
+
+// evalStatement evaluates statement, unless the program has been
+// interrupted, in which case the Interrupt is returned instead
+func evalStatement(statement ast.Statement, env object.Environment) (object.RubyObject, error) {
+	if err := object.RuntimeOf(env).CheckInterrupt(); err != nil {
+		return nil, err
+	}
+	if count := object.SampleDue(); count != 0 {
+		recordSample(statement, env, count)
+	}
+	if err := EnterStatement(statement, env); err != nil {
+		return nil, err
+	}
+	return Eval(statement, env)
+}
+
+// EnterStatement counts the line of statement, which is about to be
+// executed within env, for the coverage measured and reports it to the
+// enabled TracePoints. The VM calls it for the statements it executes
+// itself.
+func EnterStatement(statement ast.Statement, env object.Environment) error {
+	if object.CoverageEnabled() {
+		coverLine(statement, env)
+	}
+	if object.Tracing() {
+		return traceLine(statement, env)
+	}
+	return nil
 }
 
 func evalBlockStatement(block *ast.BlockStatement, env object.Environment) (object.RubyObject, error) {
-	var result object.RubyObject
+	var result object.RubyObject = object.NIL
 	var err error
 	for _, statement := range block.Statements {
-		result, err = Eval(statement, env)
+		result, err = evalStatement(statement, env)
 		if err != nil {
-			return nil, err
+			return nil, recordBacktrace(env, statement, err)
 		}
 		if result != nil {
 			rt := result.Type()
@@ -343,45 +904,235 @@ func evalBlockStatement(block *ast.BlockStatement, env object.Environment) (obje
 }
 
 func evalIdentifier(node *ast.Identifier, env object.Environment) (object.RubyObject, error) {
-	val, ok := env.Get(node.Value)
+	if object.IsConstantName(node.Value) {
+		if val, ok := lookupConstant(node.Value, env); ok {
+			return val, nil
+		}
+	}
+	val, ok := getVariable(node, env)
 	if ok {
 		if fn, ok := val.(*object.Function); ok {
 			if len(fn.Parameters) != 0 {
 				return val, nil
 			}
-			return applyFunction(fn, []object.RubyObject{})
+			return callWithFrame(env, node.Token, node.Value, func() (object.RubyObject, error) {
+				return applyFunction(fn, []object.RubyObject{})
+			})
 		}
 		return val, nil
 	}
+	if node.Declared {
+		return object.NIL, nil
+	}
+	if path, ok := object.RuntimeOf(env).Autoload(node.Value); ok {
+		return evalAutoload(node.Value, path, env)
+	}
+	if function, ok := evaluatorFunctions[node.Value]; ok {
+		updateLocation(env, node.Token)
+		return function(env)
+	}
+	if strings.HasPrefix(node.Value, "$") {
+		// global variables are nil until assigned
+		return object.NIL, nil
+	}
 	self, _ := env.Get("self")
-	val, err := object.Send(self, node.Value)
-	if err != nil {
+	val, err := callWithFrame(env, node.Token, node.Value, func() (object.RubyObject, error) {
+		if result, ok, err := callWithStreams(env, self, node.Value); ok {
+			return result, err
+		}
+		return object.RuntimeOf(env).Send(self, node.Value)
+	})
+	if _, ok := err.(*object.NoMethodError); ok {
+		if object.IsConstantName(node.Value) {
+			return object.RuntimeOf(env).ConstMissing(node.Value)
+		}
 		return nil, object.NewNameError(self, node.Value)
 	}
+	return val, err
+}
+
+// evalScopedConstant looks up the constant within its scope by const_get,
+// or within Object for top level constants
+func evalScopedConstant(node *ast.ScopedConstant, env object.Environment) (object.RubyObject, error) {
+	var scope object.RubyObject
+	if node.Scope == nil {
+		scope, _ = env.Get("Object")
+	} else {
+		var err error
+		scope, err = Eval(node.Scope, env)
+		if err != nil {
+			return nil, err
+		}
+	}
+	updateLocation(env, node.Token)
+	val, err := object.RuntimeOf(env).Send(scope, "const_get", object.NewSymbol(node.Name))
+	if _, ok := err.(*object.NoMethodError); ok {
+		return nil, object.NewTypeError("%s is not a class/module", scope.Inspect())
+	}
+	return val, err
+}
+
+// evalAutoload requires the file registered via autoload for the constant
+// name and returns the constant defined by it
+func evalAutoload(name, path string, env object.Environment) (object.RubyObject, error) {
+	if _, err := requireFeature(path, env); err != nil {
+		return nil, err
+	}
+	val, ok := env.Get(name)
+	if !ok {
+		return nil, object.NewUninitializedConstantError(name)
+	}
 	return val, nil
 }
 
+// isFunction reports whether obj can be called by applyFunction
+func isFunction(obj object.RubyObject) bool {
+	_, ok := obj.(*object.Function)
+	return ok
+}
+
 func applyFunction(fn object.RubyObject, args []object.RubyObject) (object.RubyObject, error) {
 	switch fn := fn.(type) {
 	case *object.Function:
-		if len(args) != len(fn.Parameters) {
-			return nil, object.NewWrongNumberOfArgumentsError(len(fn.Parameters), len(args))
+		if !tailCallsEnabled(fn.Env) {
+			return applyMethodBody(fn, args, Eval)
+		}
+		for {
+			result, err := applyMethodBody(fn, args, func(body ast.Node, env object.Environment) (object.RubyObject, error) {
+				return evalTailBody(body.(*ast.BlockStatement), env)
+			})
+			call, ok := result.(*tailCall)
+			if !ok || err != nil {
+				return result, err
+			}
+			call.replaceFrame(fn.Name)
+			fn, args = call.function, call.args
+		}
+	default:
+		return nil, object.NewSyntaxError(fmt.Sprintf("not a function: %s", fn.Type()))
+	}
+}
+
+// ApplyMethod calls the method fn with args the way the evaluator does,
+// reporting the call and the return to the enabled TracePoints, but
+// executes its body by run, e.g. as compiled for the VM
+func ApplyMethod(fn *object.Function, args []object.RubyObject, run func(object.Environment) (object.RubyObject, error)) (object.RubyObject, error) {
+	return applyMethodBody(fn, args, func(_ ast.Node, env object.Environment) (object.RubyObject, error) {
+		return run(env)
+	})
+}
+
+// applyMethodBody binds args to the parameters of fn and evaluates its
+// body by eval. The body has its own `$~` and `$_`, so the caller's ones
+// are restored when it returns.
+func applyMethodBody(fn *object.Function, args []object.RubyObject, eval func(ast.Node, object.Environment) (object.RubyObject, error)) (object.RubyObject, error) {
+	if err := CheckContext(fn.Env); err != nil {
+		return nil, err
+	}
+	runtime := object.RuntimeOf(fn.Env)
+	defer runtime.SetLastMatch(runtime.SetLastMatch(nil))
+	defer runtime.SetLastLine(runtime.SetLastLine(nil))
+	extendedEnv, err := BindArguments(fn, args)
+	if err != nil {
+		return nil, err
+	}
+	if fn.File != nil {
+		extendedEnv.Set(currentFileKey, fn.File)
+	}
+	if object.Tracing() {
+		if err := traceMethod("call", fn, fn.Line, nil, extendedEnv); err != nil {
+			return nil, err
+		}
+	}
+	evaluated, err := eval(fn.Body, extendedEnv)
+	if ret, ok := err.(*returnError); ok && isEnclosedBy(ret.env, extendedEnv) {
+		evaluated, err = ret.value, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := unwrapReturnValue(evaluated)
+	if _, ok := result.(*tailCall); !ok && object.Tracing() {
+		if err := traceMethod("return", fn, currentLine(extendedEnv), result, extendedEnv); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// newProc returns a Proc evaluating the body of block within env. A
+// `return` within the Proc leaves the method the block was defined in,
+// unless the Proc has been turned into a lambda.
+func newProc(block *ast.BlockLiteral, env object.Environment) *object.Proc {
+	return newLabeledProc(block, env, blockLabel(env))
+}
+
+// newLabeledProc returns a Proc like newProc, whose calls run within a
+// frame labeled label
+func newLabeledProc(block *ast.BlockLiteral, env object.Environment, label string) *object.Proc {
+	proc := &object.Proc{
+		Parameters: block.Parameters,
+		Body:       block.Body,
+		Env:        env,
+	}
+	proc.CallFn = func(body *ast.BlockStatement, env object.Environment) (object.RubyObject, error) {
+		if err := CheckContext(env); err != nil {
+			return nil, err
+		}
+		frame := object.Location{Path: currentFile(env), Lineno: block.Token.Line, Label: label}
+		evaluated, err := withFrame(env, frame, func() (object.RubyObject, error) {
+			return Eval(body, env)
+		})
+		if next, ok := err.(*nextError); ok {
+			return next.value, nil
+		}
+		if proc.Lambda {
+			switch err := err.(type) {
+			case *breakError:
+				return err.value, nil
+			case *returnError:
+				if isEnclosedBy(err.env, env) {
+					return err.value, nil
+				}
+			}
 		}
-		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated, err := Eval(fn.Body, extendedEnv)
 		if err != nil {
 			return nil, err
 		}
+		if returnValue, ok := evaluated.(*object.ReturnValue); ok && !proc.Lambda {
+			return nil, &returnError{value: object.ReleaseReturnValue(returnValue), env: proc.Env}
+		}
 		return unwrapReturnValue(evaluated), nil
-	case *object.Builtin:
-		return fn.Fn(args...), nil
-	default:
-		return nil, object.NewSyntaxError(fmt.Sprintf("not a function: %s", fn.Type()))
 	}
+	return proc
+}
+
+// returnError unwinds the evaluation from a `return` within a Proc up to
+// the method or lambda enclosing env
+type returnError struct {
+	value object.RubyObject
+	env   object.Environment
+}
+
+func (r *returnError) Error() string { return "unexpected return" }
+
+// isEnclosedBy reports whether env is frame or one of its inner environments
+func isEnclosedBy(env, frame object.Environment) bool {
+	for ; env != nil; env = env.Outer() {
+		if env == frame {
+			return true
+		}
+	}
+	return false
 }
 
 func extendFunctionEnv(fn *object.Function, args []object.RubyObject) object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+	var env object.Environment
+	if fn.Scope != nil {
+		env = object.NewSlotEnvironment(fn.Env, fn.Scope)
+	} else {
+		env = object.NewEnclosedEnvironment(fn.Env)
+	}
 	for paramIdx, param := range fn.Parameters {
 		env.Set(param.Value, args[paramIdx])
 	}
@@ -390,7 +1141,7 @@ func extendFunctionEnv(fn *object.Function, args []object.RubyObject) object.Env
 
 func unwrapReturnValue(obj object.RubyObject) object.RubyObject {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+		return object.ReleaseReturnValue(returnValue)
 	}
 	return obj
 }
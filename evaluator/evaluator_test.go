@@ -1,14 +1,54 @@
 package evaluator
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/goruby/goruby/ast"
 	"github.com/goruby/goruby/lexer"
 	"github.com/goruby/goruby/object"
 	"github.com/goruby/goruby/parser"
 )
 
+func BenchmarkEvalIntegerArithmetic(b *testing.B) {
+	for _, limit := range []int{1000, 100000} {
+		input := fmt.Sprintf("i = 0\nwhile i < %d\ni = (i + 7) - 6\nend", limit)
+		program, err := parser.New(lexer.New(input)).ParseProgram()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("up to %d", limit), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Eval(program, object.NewEnvironment()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEvalBuiltinCalls(b *testing.B) {
+	input := "a = [1, 2]\ni = 0\nwhile i < 1000\na.first\na.include?(2)\ni = i + 1\nend"
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Eval(program, object.NewMainEnvironment()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestEvalIntegerExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -29,6 +69,19 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"-7 / 2", -4},
+		{"7 % 3", 1},
+		{"-7 % 3", 2},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"6 & 3 | 8", 10},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"1 << 4 >> 2", 4},
+		{"-16 >> 2", -4},
+		{"2.pow(10, 1000)", 24},
+		{"(-5).abs", 5},
+		{"12.gcd(18) + 4.lcm(6)", 18},
 	}
 
 	for _, tt := range tests {
@@ -38,6 +91,44 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"-2.5", "-2.5"},
+		{"1.5e10", "15000000000.0"},
+		{"1e20", "1.0e+20"},
+		{"2E-5", "2.0e-05"},
+		{"1.5 + 1", "2.5"},
+		{"3 * 0.5", "1.5"},
+		{"1 / 2.0", "0.5"},
+		{"1.0 / 0", "Infinity"},
+		{"1.5 < 2", "true"},
+		{"2 == 2.0", "true"},
+		{"1.5 <=> 2", "-1"},
+		{"2 <=> 1.5", "1"},
+		{"[2, 0.5, 1].sort", "[0.5, 1, 2]"},
+		{"7 % 2.5", "2.0"},
+		{"-7.5 % 2", "0.5"},
+		{"2 ** -1", "0.5"},
+		{"2.0 ** 3", "8.0"},
+		{"7.divmod(-2)", "[-4, -1]"},
+		{"4.even? == 5.odd?", "true"},
+		{"0.zero?", "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -62,6 +153,13 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{`"a" < "b"`, true},
+		{`"b" > "c"`, false},
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" != "a"`, false},
+		{`"a" == 1`, false},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +169,48 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestSpaceshipOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 <=> 2", "-1"},
+		{"2 <=> 2", "0"},
+		{"3 <=> 2", "1"},
+		{`"b" <=> "a"`, "1"},
+		{`1 <=> "a"`, "nil"},
+		{"[3, 1, 2].sort", "[1, 2, 3]"},
+		{"[3, 1, 2].min", "1"},
+		{`["b", "c", "a"].max`, "c"},
+		{":b <=> :a", "1"},
+		{`:a <=> "a"`, "nil"},
+		{"[:c, :a, :b].sort", "[:a, :b, :c]"},
+		{"[:c, :a, :b].min", ":a"},
+		{"[:c, :a, :b].max", ":c"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("failing comparisons", func(t *testing.T) {
+		tests := []string{`[1, "a"].sort`, `[1, nil].max`, `1 < "a"`}
+
+		for _, input := range tests {
+			_, err := testEval(input)
+			if _, ok := err.(*object.ArgumentError); !ok {
+				t.Logf("Expected %q to return an ArgumentError, got %T (%v)", input, err, err)
+				t.Fail()
+			}
+		}
+	})
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -195,7 +335,7 @@ end
 		},
 		{
 			"foobar",
-			"NameError: undefined local variable or method `foobar' for :Object",
+			"NameError: undefined local variable or method `foobar' for main:Object",
 		},
 		{
 			`
@@ -209,8 +349,7 @@ end
 	}
 
 	for _, tt := range tests {
-		env := object.NewEnvironment()
-		env.Set("self", &object.Self{&object.Object{}})
+		env := object.NewEnclosedEnvironment(object.NewMainEnvironment())
 		evaluated, err := testEval(tt.input, env)
 
 		if err == nil {
@@ -231,6 +370,158 @@ end
 	}
 }
 
+func TestRescueModifier(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5 rescue 3", 5},
+		{"1 / 0 rescue 3", 3},
+		{"x = 1 / 0 rescue 2 + 1; x", 3},
+		{"foobar rescue 3", 3},
+		{"def foo; 2 / 0; end; foo rescue 3", 3},
+		{"raise \"boom\" rescue 42", 42},
+		{"def foo(x); x / 0; end; foo 1 rescue 3", 3},
+		{"(raise \"boom\" rescue $!).message.size", 4},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+
+	t.Run("does not rescue non StandardErrors", func(t *testing.T) {
+		input := `require "this/file/does/not/exist" rescue 3`
+
+		evaluated, err := testEval(input)
+		if err == nil {
+			t.Fatalf("no error returned. got=%T(%+v)", evaluated, evaluated)
+		}
+
+		actual, ok := err.(object.RubyObject)
+		if !ok {
+			t.Fatalf("Error is not a RubyObject, got %T:%v\n", err, err)
+		}
+
+		testExceptionObject(t, actual, "LoadError: no such file to load -- this/file/does/not/exist")
+	})
+}
+
+func TestBeginBlocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"y = x + 1\nBEGIN { x = 10 }\ny", 11},
+		{"x = 1\nBEGIN { x = 10 }\nBEGIN { x = x * 2 }\nx", 1},
+		{"y = x\nBEGIN { x = 10 }\nBEGIN { x = x * 2 }\ny", 20},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestDataConstant(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"DATA.read\n__END__\nfoo\nbar\n", "foo\nbar\n"},
+		{"DATA.gets\n__END__\nfoo\nbar\n", "foo\n"},
+		{"DATA.gets\nDATA.gets\n__END__\nfoo\nbar", "bar"},
+		{"DATA.read\nDATA.rewind\nDATA.gets\n__END__\nfoo\nbar\n", "foo\n"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+		}
+
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. got=%q", str.Value)
+		}
+	}
+
+	t.Run("undefined without __END__", func(t *testing.T) {
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		_, err := testEval("DATA", env)
+		if _, ok := err.(*object.NameError); !ok {
+			t.Fatalf("Expected NameError, got %T (%v)", err, err)
+		}
+	})
+}
+
+func TestEndBlocks(t *testing.T) {
+	t.Run("run in reverse order at exit", func(t *testing.T) {
+		input := "x = 1\nEND { x = x * 2 }\nEND { x = x + 3 }\nx"
+
+		env := object.NewEnvironment()
+		program, err := parser.New(lexer.New(input)).ParseProgram()
+		checkError(t, err)
+		evaluated, err := Eval(program, env)
+		checkError(t, err)
+		testIntegerObject(t, evaluated, 1)
+
+		err = RunExitHandlers(env)
+		checkError(t, err)
+
+		x, _ := env.Get("x")
+		testIntegerObject(t, x, 8)
+	})
+	t.Run("registered only once", func(t *testing.T) {
+		input := "def foo; END { 3 }; end; foo; foo"
+
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		_, err := testEval(input, env)
+		checkError(t, err)
+
+		handlers := exitHandlers(env)
+		if len(handlers.Elements) != 1 {
+			t.Fatalf("Expected 1 exit handler, got %d", len(handlers.Elements))
+		}
+	})
+	t.Run("not registered when not evaluated", func(t *testing.T) {
+		input := "if false; END { 3 }; end"
+
+		env := object.NewEnvironment()
+		_, err := testEval(input, env)
+		checkError(t, err)
+
+		handlers := exitHandlers(env)
+		if len(handlers.Elements) != 0 {
+			t.Fatalf("Expected no exit handlers, got %d", len(handlers.Elements))
+		}
+	})
+	t.Run("all handlers run on errors", func(t *testing.T) {
+		input := "x = 1\nEND { x = 2 }\nEND { 1 / 0 }"
+
+		env := object.NewEnvironment()
+		program, err := parser.New(lexer.New(input)).ParseProgram()
+		checkError(t, err)
+		_, err = Eval(program, env)
+		checkError(t, err)
+
+		err = RunExitHandlers(env)
+		if _, ok := err.(*object.ZeroDivisionError); !ok {
+			t.Fatalf("Expected ZeroDivisionError, got %T:%v", err, err)
+		}
+
+		x, _ := env.Get("x")
+		testIntegerObject(t, x, 2)
+	})
+}
+
 func TestVariableAssignmentExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -249,6 +540,47 @@ func TestVariableAssignmentExpression(t *testing.T) {
 	}
 }
 
+func TestDeclaredLocalVariables(t *testing.T) {
+	tests := []string{
+		"if false; x = 3; end; x",
+		"def foo; if false; x = 3; end; x; end; foo",
+		"x = x; x",
+		"y = 1; [1].each { if false; x = 1; end; y = x }; y",
+	}
+
+	for _, input := range tests {
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated != object.NIL {
+			t.Logf("Expected %q to return nil, got %v", input, evaluated)
+			t.Fail()
+		}
+	}
+
+	_, err := testEval("[1].each { x = 1 }; x", object.NewMainEnvironment())
+	if _, ok := err.(*object.NameError); !ok {
+		t.Logf("Expected variables declared within a block to be local to it, got %T:%v", err, err)
+		t.Fail()
+	}
+}
+
+func TestUnassignedGlobalVariables(t *testing.T) {
+	tests := []string{
+		"$never_assigned",
+		"def foo; $never_assigned; end; foo",
+		"[1].map { $never_assigned }.first",
+	}
+
+	for _, input := range tests {
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated != object.NIL {
+			t.Logf("Expected %q to return nil, got %v", input, evaluated)
+			t.Fail()
+		}
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	tests := []struct {
 		input              string
@@ -276,7 +608,7 @@ func TestFunctionObject(t *testing.T) {
 
 	for _, tt := range tests {
 		env := object.NewEnvironment()
-		env.Set("self", &object.Self{&object.Object{}})
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
 		evaluated, err := testEval(tt.input, env)
 		checkError(t, err)
 		fn, ok := evaluated.(*object.Function)
@@ -320,7 +652,7 @@ func TestFunctionApplication(t *testing.T) {
 
 	for _, tt := range tests {
 		env := object.NewEnvironment()
-		env.Set("self", &object.Self{&object.Object{}})
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
 		evaluated, err := testEval(tt.input, env)
 		checkError(t, err)
 		testIntegerObject(t, evaluated, tt.expected)
@@ -357,392 +689,3201 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
-func TestSymbolLiteral(t *testing.T) {
-	input := `:foobar;`
-
-	evaluated, err := testEval(input)
-	checkError(t, err)
-	sym, ok := evaluated.(*object.Symbol)
-	if !ok {
-		t.Fatalf("object is not Symbol. got=%T (%+v)", evaluated, evaluated)
+func TestStringMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"ab" * 3`, "ababab"},
+		{`"  hello ".strip.capitalize.center(9, "*")`, "**Hello**"},
+		{`"hello".start_with?("he", "x")`, "true"},
+		{`"héllo".index("l")`, "2"},
 	}
 
-	if sym.Value != "foobar" {
-		t.Errorf("Symbol has wrong value. got=%q", sym.Value)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
 }
 
-func TestBuiltinFunctions(t *testing.T) {
+func TestArrayIteration(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected interface{}
+		expected string
 	}{
-		{`puts;`, nil},
-		{`puts "foo";`, nil},
+		{`[1, 2, 3].map { |x| x * 2 }`, "[2, 4, 6]"},
+		{`[1, 2, 3, 4].select { |x| x > 2 }`, "[3, 4]"},
+		{`[1, 2, 3, 4].reject do |x| x > 2 end`, "[1, 2]"},
+		{`[1, 2, 3].reduce(10) { |sum, x| sum + x }`, "16"},
+		{`[1, 2, 3].inject(:+)`, "6"},
+		{`sum = 0; [1, 2, 3].each { |x| sum = sum + x }; sum`, "6"},
+		{`s = 0; [5, 6].each_with_index { |x, i| s = s + x * i }; s`, "6"},
+		{`[3, 4].each_with_index.map { |x, i| [x, i] }`, "[[3, 0], [4, 1]]"},
+		{`[1, 2, 3].map.with_index { |x, i| x * i }`, "[0, 2, 6]"},
+		{`[1, 2, 3].select.with_index { |x, i| i > 0 }`, "[2, 3]"},
+		{`[1, 2, 3].each_slice(2).to_a`, "[[1, 2], [3]]"},
+		{`[[1, 2], [3, 4]].map { |a, b| a * b }`, "[2, 12]"},
+		{`["a", "b"].each_with_object("") { |x, memo| memo << x }`, "ab"},
+		{`[1, 2, 3].each { |x| if x == 2; break x * 10; end }`, "20"},
+		{`[3, 1, 2].sort { |a, b| b <=> a }`, "[3, 2, 1]"},
+		{`["ccc", "a", "bb"].sort_by { |s| s.size }`, "[a, bb, ccc]"},
+		{`["ccc", "a", "bb"].max_by { |s| s.size }`, "ccc"},
+		{`[1, 2, 3].min { |a, b| b <=> a }`, "3"},
+		{`[1, 2, 3].sum { |x| x * x }`, "14"},
+		{`[0.5, 1].sum(1)`, "2.5"},
+		{`s = 0; 3.times { |i| s = s + i }; s`, "3"},
+		{`5.times.map { |i| i * 2 }`, "[0, 2, 4, 6, 8]"},
+		{`sum = 0; 1.upto(3) { |i| sum = sum + i }; sum`, "6"},
+		{`3.downto(1).to_a`, "[3, 2, 1]"},
+		{`1.step(10, 4).to_a`, "[1, 5, 9]"},
+		{`1.step(2, 0.5).to_a`, "[1.0, 1.5, 2.0]"},
+		{`3.times.with_index(1).map { |x, i| x * i }`, "[0, 2, 6]"},
+		{`10.times { |i| if i == 4; break i; end }`, "4"},
+		{`2.times`, "#<Enumerator: 2:times>"},
 	}
 
 	for _, tt := range tests {
-		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		evaluated, err := testEval(tt.input)
 		checkError(t, err)
-
-		switch expected := tt.expected.(type) {
-		case int:
-			testIntegerObject(t, evaluated, int64(expected))
-		case nil:
-			testNilObject(t, evaluated)
-		case string:
-			ok := IsError(evaluated)
-			if !ok {
-				t.Errorf("object is not Error. got=%T (%+v)",
-					evaluated, evaluated)
-				continue
-			}
-			if evaluated.Inspect() != expected {
-				t.Errorf("wrong error message. expected=%q, got=%q",
-					expected, evaluated.Inspect())
-			}
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
 		}
 	}
 }
 
-func TestMethodCalls(t *testing.T) {
-	input := "x = 2; x.foo :bar"
-
-	evaluated, err := testEval(input)
+func TestArrayMutation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a = [1, 2]; a << 3; a.push(4, 5); a`, "[1, 2, 3, 4, 5]"},
+		{`a = [1, 2]; b = a; b << 3; a`, "[1, 2, 3]"},
+		{`a = [1, 2, 3]; b = a; b.pop; b.shift; a`, "[2]"},
+		{`a = [1, 2, 3]; a.delete_if { |x| x > 1 }; a`, "[1]"},
+		{`a = []; a.unshift(1).concat([2, 3]).insert(1, 0)`, "[1, 0, 2, 3]"},
+		{`a = ["a", "b"]; a.delete("a"); a`, "[b]"},
+		{`a = []; a.fill(1, 0, 2)`, "[1, 1]"},
+		{`a = [1, 2]; [3].each { |x| a << x }; a`, "[1, 2, 3]"},
+	}
 
-	if err == nil {
-		t.Logf("Expected error, got %T:%s\n", evaluated, evaluated)
-		t.Fail()
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
 }
 
-func TestArrayLiterals(t *testing.T) {
-	input := "[1, 2 * 2, 3 + 3]"
-	evaluated, err := testEval(input)
-	checkError(t, err)
+func TestArrayQueries(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, [2, 3]].include?([2, 3])`, "true"},
+		{`[1, 2, 3].index(2)`, "1"},
+		{`[1, 2, 3].first(2)`, "[1, 2]"},
+		{`[[1, [2]], nil, 3].flatten.compact.reverse.join(", ")`, "3, 2, 1"},
+		{`[1, 2, 1].uniq`, "[1, 2]"},
+		{`[1, 2].zip([3, 4]).map { |a, b| a + b }`, "[4, 6]"},
+		{`[1, 2, 3, 4, 5].each_slice(2).map { |s| s.sum }`, "[3, 7, 5]"},
+		{`sums = []; [1, 2, 3].each_cons(2) { |a, b| sums << a + b }; sums`, "[3, 5]"},
+		{`[1, 2, 3].drop(1).take(1)`, "[2]"},
+		{`[1, 2] == [1, 2]`, "true"},
+	}
 
-	result, ok := evaluated.(*object.Array)
-	if !ok {
-		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
-	if len(result.Elements) != 3 {
-		t.Fatalf(
-			"array has wrong num of elements. got=%d",
-			len(result.Elements),
-		)
+}
+
+func TestArraySlicing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3, 4][1..2]`, "[2, 3]"},
+		{`[1, 2, 3, 4][1...-1]`, "[2, 3]"},
+		{`[1, 2, 3, 4][2, 5]`, "[3, 4]"},
+		{`[1, 2, 3, 4][4, 1]`, "[]"},
+		{`[1, 2, 3, 4][5, 1]`, "nil"},
+		{`a = [1, 2, 3]; a[0] = :x; a`, "[:x, 2, 3]"},
+		{`a = [1, 2, 3]; a[1..2] = [:x]; a`, "[1, :x]"},
+		{`a = [1, 2, 3]; a[1, 0] = [7, 8]; a`, "[1, 7, 8, 2, 3]"},
+		{`a = []; a[2] = 1; a`, "[nil, nil, 1]"},
+		{`a = [1]; a[0] = 5`, "5"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
-	testIntegerObject(t, result.Elements[0], 1)
-	testIntegerObject(t, result.Elements[1], 4)
-	testIntegerObject(t, result.Elements[2], 6)
 }
 
-func TestArrayIndexExpressions(t *testing.T) {
+func TestHashMethods(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected interface{}
+		expected string
 	}{
-		{
-			"[1, 2, 3][0]",
-			1,
-		},
-		{
-			"[1, 2, 3][1]",
-			2,
-		},
-		{
-			"[1, 2, 3][2]",
-			3,
-		},
-		{
-			"i = 0; [1][i];",
-			1,
-		},
-		{
-			"[1, 2, 3][1 + 1];",
-			3,
-		},
-		{
-			"myArray = [1, 2, 3]; myArray[2];",
-			3,
-		},
-		{
-			"myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
-			6,
-		},
-		{
-			"myArray = [1, 2, 3]; i = myArray[0]; myArray[i]",
-			2,
-		},
-		{
-			"[1, 2, 3][3]",
-			nil,
-		},
-		{
-			"[1, 2, 3][-1]",
-			nil,
-		},
+		{`h = {a: 1}; h[:b] = 2; h`, "{:a=>1, :b=>2}"},
+		{`{a: 1}[:b]`, "nil"},
+		{`h = {}; h.default = 0; h[:x]`, "0"},
+		{`{a: 1, b: 2}.map { |k, v| v * 10 }`, "[10, 20]"},
+		{`sum = 0; {a: 1, b: 2}.each { |k, v| sum = sum + v }; sum`, "3"},
+		{`{a: 1, b: 2}.select { |k, v| v > 1 }`, "{:b=>2}"},
+		{`{a: 1, b: 2}.reject { |k, v| v > 1 }`, "{:a=>1}"},
+		{`{a: 1}.merge({a: 2, b: 3}) { |k, old, new| old + new }`, "{:a=>3, :b=>3}"},
+		{`{a: 1}.fetch(:b, 5)`, "5"},
+		{`{a: 1}.fetch(:b) { |k| k }`, ":b"},
+		{`{a: {b: [1, 2]}}.dig(:a, :b, 1)`, "2"},
+		{`{a: 1}.key?(:a)`, "true"},
+		{`{a: 1}.any? { |k, v| v > 1 }`, "false"},
+		{`{a: 1}.transform_values { |v| v + 1 }`, "{:a=>2}"},
+		{`{a: 1}.to_a`, "[[:a, 1]]"},
 	}
 
 	for _, tt := range tests {
 		evaluated, err := testEval(tt.input)
 		checkError(t, err)
-		integer, ok := tt.expected.(int)
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-		} else {
-			testNilObject(t, evaluated)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
 		}
 	}
-}
 
-func TestNilExpression(t *testing.T) {
-	input := "nil"
-	evaluated, err := testEval(input)
-	checkError(t, err)
-	testNilObject(t, evaluated)
-}
+	t.Run("fetch missing key", func(t *testing.T) {
+		_, err := testEval(`{a: 1}.fetch(:b)`)
 
-func TestSelfExpression(t *testing.T) {
-	input := "self"
+		expected := object.NewKeyError(&object.Symbol{Value: "b"})
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
 
-	env := object.NewMainEnvironment()
-	env.Set("self", &object.Self{&object.Integer{Value: 3}})
-	evaluated, err := testEval(input, env)
-	checkError(t, err)
+func TestRangeLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1..3`, "1..3"},
+		{`x = 2; 0...x + 1`, "0...3"},
+		{`(1..3).to_a`, "[1, 2, 3]"},
+		{`(1...3).size`, "2"},
+		{`(1.0..2.0).include?(1.5)`, "true"},
+		{`sum = 0; (1..4).each { |i| sum = sum + i }; sum`, "10"},
+	}
 
-	self, ok := evaluated.(*object.Self)
-	if !ok {
-		t.Logf("Expected evaluated object to be object.Self, got=%T", evaluated)
-		t.Fail()
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
 
-	testIntegerObject(t, self.RubyObject, 3)
+	t.Run("bad value for range", func(t *testing.T) {
+		_, err := testEval(`1.."a"`)
+
+		expected := object.NewArgumentError("bad value for range")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
 }
 
-func TestRequireExpression(t *testing.T) {
-	t.Run("simple require", func(t *testing.T) {
-		input := `require "testfile.rb"
-		x + 2
-		`
+func TestStringSlicing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[-1]`, "o"},
+		{`"héllo"[9]`, "nil"},
+		{`"héllo"[0..2]`, "hél"},
+		{`"héllo"[1...-1]`, "éll"},
+		{`"héllo".slice(1, 3)`, "éll"},
+		{`"abc123def"[/\d+/]`, "123"},
+		{`"key=value".slice(/(\w+)=(\w+)/, 2)`, "value"},
+		{`"héllo".chars`, "[h, é, l, l, o]"},
+		{`"hé".bytes`, "[104, 195, 169]"},
+		{`"a\nb".lines`, "[a\n, b]"},
+		{`s = ""; "abc".each_char { |c| s = c + s }; s`, "cba"},
+		{`n = 0; "a\nb\nc".each_line { |l| n = n + 1 }; n`, "3"},
+	}
 
-		evaluated, err := testEval(input)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
 		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
 
-		testIntegerObject(t, evaluated, int64(7))
-	})
-	t.Run("simple require no filetype", func(t *testing.T) {
-		input := `require "testfile"
-		x + 2
-		`
+func TestRegexpStringMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a,b,,c,,".split(",")`, `[a, b, , c]`},
+		{`"a1b22c".split(/\d+/)`, `[a, b, c]`},
+		{`"  a  b c ".split`, `[a, b, c]`},
+		{`"a-b_c".split(/([-_])/)`, `[a, -, b, _, c]`},
+		{`"hello world".sub(/o/, "0")`, `hell0 world`},
+		{`"hello world".gsub(/o/, "0")`, `hell0 w0rld`},
+		{`"john smith".gsub(/(\w+) (\w+)/, '\\2, \\1')`, `smith, john`},
+		{`"a1b2".gsub(/\d/) { |d| d * 2 }`, `a11b22`},
+		{`"a1b2".gsub(/\d/) do |d| "<" + d + ">" end`, `a<1>b<2>`},
+		{`"a1b22".scan(/\d+/)`, `[1, 22]`},
+		{`"a=1 b=2".scan(/(\w)=(\d)/)`, `[[a, 1], [b, 2]]`},
+		{`"foo bar".match(/(?<first>\w+) (\w+)/)["first"]`, `foo`},
+		{`"foo".match(/x/)`, `nil`},
+		{`"foobar" =~ /bar/`, `3`},
+		{`/bar/ =~ "foobar"`, `3`},
+		{`"foobar" !~ /baz/`, `true`},
+		{`x = "Hello"; case x; when /^h/i then :yes; else :no; end`, `:yes`},
+	}
 
+	for _, tt := range tests {
+		input := strings.Replace(tt.input, "'", `"`, -1)
 		evaluated, err := testEval(input)
 		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
 
-		testIntegerObject(t, evaluated, int64(7))
-	})
-	t.Run("require return value", func(t *testing.T) {
-		input := `require "testfile.rb"
-		`
+func TestLastMatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"foo bar" =~ /(\w+) (\w+)/; [$1, $2, $3]`, `[foo, bar, nil]`},
+		{`/(?<year>\d+)-(?<month>\d+)/.match("on 2024-05 ok"); [$~.pre_match, $~.post_match, $~[:month]]`, `[on ,  ok, 05]`},
+		{`"2024-05".match(/(?<year>\d+)-(?<month>\d+)/).named_captures`, `{year=>2024, month=>05}`},
+		{`"abc" =~ /b/; "abc" =~ /x/; [$~, $1]`, `[nil, nil]`},
+		{`case "v1.2"; when /v(\d)/ then $1; end`, `1`},
+		{`"ab" =~ /(a)/; Regexp.last_match(1)`, `a`},
+		{`def m; "x" =~ /(x)/; $1; end; "y" =~ /(y)/; [m, $1]`, `[x, y]`},
+		{`"y" =~ /(y)/; [1].map { |i| "z" =~ /(z)/ }; $1`, `z`},
+	}
 
-		evaluated, err := testEval(input)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
 		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
 
-		testBooleanObject(t, evaluated, true)
-	})
-	t.Run("require appends to $LOADED_FEATURES", func(t *testing.T) {
-		input := `require "testfile.rb"
-		`
+func TestLastLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"DATA.gets\n$_\n__END__\nfoo\nbar\n", "foo\n"},
+		{"l = []\nwhile DATA.gets\nl << $_.chomp\nend\n[l, $_]\n__END__\nfoo\nbar\n", "[[foo, bar], nil]"},
+		{"def m; DATA.gets; $_; end\n[m, $_]\n__END__\nfoo\n", "[foo\n, nil]"},
+		{"DATA.gets\n$_ = $_.upcase\n$_\n__END__\nfoo\n", "FOO\n"},
+	}
 
-		env := object.NewEnvironment()
-		_, err := testEval(input, env)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
 		checkError(t, err)
-
-		loadedFeatures, ok := env.Get("$LOADED_FEATURES")
-		if !ok {
-			t.Logf("Expected env to contain $LOADED_FEATURES object")
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %q, got %q", tt.input, tt.expected, evaluated.Inspect())
 			t.Fail()
 		}
+	}
+}
 
-		arr, ok := loadedFeatures.(*object.Array)
-		if !ok {
-			t.Logf("Expected loadedFeatures to be an array, got %T", loadedFeatures)
-			t.Fail()
-		}
+func TestProgramName(t *testing.T) {
+	env := object.NewMainEnvironment()
+	evaluated, err := testEval("$0", env)
+	checkError(t, err)
+	if evaluated != object.NIL {
+		t.Errorf("Expected $0 to be nil without a program, got %s", evaluated.Inspect())
+	}
 
-		expectedLen := 1
-		actualLen := len(arr.Elements)
+	env.SetGlobal("$PROGRAM_NAME", &object.String{Value: "-e"})
+	evaluated, err = testEval("def name; $0; end; name", env)
+	checkError(t, err)
+	if evaluated.Inspect() != "-e" {
+		t.Errorf("Expected $0 to be -e, got %s", evaluated.Inspect())
+	}
+}
 
-		if expectedLen != actualLen {
-			t.Logf("Expected array to have %d elements, got %d", expectedLen, actualLen)
+func TestBlocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"n = 0; \"aaa\".scan(/a/) { |m| n = n + 1 }; n", "3"},
+		{"\"aaa\".scan(/a/) { |m| x = 1 }; defined = 1", "1"},
+		{"\"a1b2\".gsub(/\\d/) { |d| break d }", "1"},
+		{"m = \"x\"; \"ab\".gsub(/b/) { |d| m }", "ax"},
+		{"\"ab\".gsub(/b/) { |d, e| e }", "a"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
 			t.Fail()
 		}
+	}
 
-		expectedElemValue := "testfile.rb"
-		actualElemValue := arr.Elements[0].Inspect()
-
-		if expectedElemValue != actualElemValue {
-			t.Logf("Expected elem value to equal %s, got %s", expectedElemValue, actualElemValue)
+	t.Run("block variables are local", func(t *testing.T) {
+		env := object.NewEnvironment()
+		_, err := testEval("\"a\".scan(/a/) { |m| inner = m }", env)
+		checkError(t, err)
+		if _, ok := env.Get("inner"); ok {
+			t.Logf("Expected variable defined within block not to leak")
+			t.Fail()
+		}
+		if _, ok := env.Get("m"); ok {
+			t.Logf("Expected block parameter not to leak")
 			t.Fail()
 		}
 	})
-	t.Run("require only parses the files once", func(t *testing.T) {
-		input := `require "testfile_require_once.rb"
-			x
-		`
-
-		evaluated, err := testEval(input)
-		checkError(t, err)
+}
 
-		testIntegerObject(t, evaluated, int64(7))
-	})
-	t.Run("require returns false if already required", func(t *testing.T) {
-		input := `require "testfile"
-			require "testfile"
-		`
+func TestFrozenStringLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{"x = \"foo\"\nx.frozen?", "false", nil},
+		{"# frozen_string_literal: false\nx = \"foo\"\nx.frozen?", "false", nil},
+		{"# frozen_string_literal: true\nx = \"foo\"\nx.frozen?", "true", nil},
+		{"x = \"foo\"\nx << \"bar\"", "foobar", nil},
+		{"x = \"foo\".freeze\nx << \"bar\"", "", object.NewFrozenError(&object.String{})},
+		{"# frozen_string_literal: true\nx = \"foo\"\nx << \"bar\"", "", object.NewFrozenError(&object.String{})},
+	}
 
-		evaluated, err := testEval(input)
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		if tt.err != nil {
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Logf("Expected error %v for %q, got %v", tt.err, tt.input, err)
+				t.Fail()
+			}
+			continue
+		}
 		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
 
-		testBooleanObject(t, evaluated, false)
-	})
-	t.Run("recursive require", func(t *testing.T) {
-		input := `require "testfile_recursive_require.rb"
-		x + 2
-		`
+	t.Run("one instance per literal", func(t *testing.T) {
+		input := "# frozen_string_literal: true\n[\"foo\", \"foo\"]"
 
 		evaluated, err := testEval(input)
 		checkError(t, err)
-
-		testIntegerObject(t, evaluated, int64(7))
-	})
-	t.Run("syntax error in file", func(t *testing.T) {
-		input := `require "testfile_syntax_error.rb"
-		`
-
-		evaluated, err := testEval(input)
-		if err == nil {
-			t.Errorf(
-				"no error returned. got=%T(%+v)",
-				evaluated,
-				evaluated,
-			)
-		}
-
-		actual, ok := err.(object.RubyObject)
+		array, ok := evaluated.(*object.Array)
 		if !ok {
-			t.Logf("Error is not a RubyObject, got %T:%v\n", err, err)
-			t.FailNow()
-		}
-
-		expected := "SyntaxError: syntax error, Parsing errors:\n\texpected next token to be of type [END], got EOF instead\n"
-		testExceptionObject(t, actual, expected)
-	})
-	t.Run("file not found", func(t *testing.T) {
-		input := `require "this/file/does/not/exist"
-		`
-
-		evaluated, err := testEval(input)
-
-		if err == nil {
-			t.Errorf(
-				"no error returned. got=%T(%+v)",
-				evaluated,
-				evaluated,
-			)
+			t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
 		}
-
-		actual, ok := err.(object.RubyObject)
-		if !ok {
-			t.Logf("Error is not a RubyObject, got %T:%v\n", err, err)
-			t.FailNow()
+		if array.Elements[0] != array.Elements[1] {
+			t.Logf("Expected frozen string literals to share their instance")
+			t.Fail()
 		}
-
-		expected := "LoadError: no such file to load -- this/file/does/not/exist"
-		testExceptionObject(t, actual, expected)
 	})
 }
 
-func testExceptionObject(t *testing.T, obj object.RubyObject, errorMessage string) {
-	if !IsError(obj) {
-		t.Logf("Expected error or exception, got %T", obj)
-		t.Fail()
-	}
-
-	actual := obj.Inspect()
+func TestSymbolLiteral(t *testing.T) {
+	input := `:foobar;`
 
-	if errorMessage != actual {
-		t.Logf("Expected obj to stringify to %q, got %q", errorMessage, actual)
+	evaluated, err := testEval(input)
+	checkError(t, err)
+	sym, ok := evaluated.(*object.Symbol)
+	if !ok {
+		t.Fatalf("object is not Symbol. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if sym.Value != "foobar" {
+		t.Errorf("Symbol has wrong value. got=%q", sym.Value)
+	}
+}
+
+func TestIntegerConversion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`255.to_s(16)`, "ff"},
+		{`(-5).to_s(2)`, "-101"},
+		{`"ff".to_i(16)`, "255"},
+		{`"0b101".to_i(0)`, "5"},
+		{`"12abc".to_i`, "12"},
+		{`Integer("42")`, "42"},
+		{`Integer(" -0x1A ")`, "-26"},
+		{`Integer("11", 2)`, "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`Integer("4x2")`, object.NewMainEnvironment())
+	expected := object.NewArgumentError(`invalid value for Integer(): "4x2"`)
+	if !reflect.DeepEqual(err, expected) {
+		t.Logf("Expected error to equal %v, got %v", expected, err)
 		t.Fail()
 	}
 }
 
-func testNilObject(t *testing.T, obj object.RubyObject) bool {
-	if obj != object.NIL {
-		t.Errorf("object is not NIL. got=%T (%+v)", obj, obj)
-		return false
+func TestSendExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1.send(:+, 2)`, "3"},
+		{`1.__send__("+", 2)`, "3"},
+		{`[1, 2].send(:map) { |x| x * 2 }`, "[2, 4]"},
+		{`[1, 2].public_send(:first)`, "1"},
+		{`send(:Integer, "42")`, "42"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`public_send(:Integer, "42")`, object.NewMainEnvironment())
+	if _, ok := err.(*object.NoMethodError); !ok {
+		t.Logf("Expected NoMethodError, got %T (%v)", err, err)
+		t.Fail()
 	}
-	return true
 }
 
-func testEval(input string, context ...object.Environment) (object.RubyObject, error) {
-	env := object.NewEnvironment()
-	for _, e := range context {
-		env = object.NewEnclosedEnvironment(e)
+func TestRespondToExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1.respond_to?(:+)`, "true"},
+		{`1.respond_to?("foo")`, "false"},
+		{`respond_to?(:puts)`, "false"},
+		{`respond_to?(:puts, true)`, "true"},
+		{`def foo; end; respond_to?(:foo)`, "true"},
 	}
-	l := lexer.New(input)
-	p := parser.New(l)
-	program, err := p.ParseProgram()
-	if err != nil {
-		return nil, object.NewSyntaxError(err.Error())
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
-	return Eval(program, env)
 }
 
-func checkError(t *testing.T, err error) {
-	if err != nil {
-		t.Logf("Expected no error, got %T:%v\n", err, err)
+func TestInstanceVariableReflection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`instance_variable_set(:@a, 1); instance_variable_get(:@a)`, "1"},
+		{`instance_variable_get("@a")`, "nil"},
+		{`instance_variable_set(:@a, 1); instance_variable_defined?(:@a)`, "true"},
+		{`instance_variable_set(:@b, 1); instance_variable_set(:@a, 2); instance_variables`, "[:@b, :@a]"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`instance_variable_get(:a)`, object.NewMainEnvironment())
+	expected := object.NewInvalidInstanceVariableNameError("a")
+	if !reflect.DeepEqual(err, expected) {
+		t.Logf("Expected error to equal %v, got %v", expected, err)
 		t.Fail()
 	}
 }
 
-func testBooleanObject(t *testing.T, obj object.RubyObject, expected bool) bool {
-	result, ok := obj.(*object.Boolean)
-	if !ok {
-		t.Errorf(
-			"object is not Boolean. got=%T (%+v)",
-			obj,
-			obj,
-		)
-		return false
+func TestObjectCopies(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a = [1, 2]; b = a.dup; b << 3; a`, "[1, 2]"},
+		{`a = "foo"; a.freeze; a.dup.frozen?`, "false"},
+		{`a = "foo"; a.freeze; a.clone.frozen?`, "true"},
+		{`a = "foo"; a.instance_variable_set(:@b, 1); a.clone.instance_variable_get(:@b)`, "1"},
+		{`{a: 1}.dup`, "{:a=>1}"},
 	}
-	if result.Value != expected {
-		t.Errorf(
-			"object has wrong value. got=%t, want=%t",
-			result.Value,
-			expected,
-		)
-		return false
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
 	}
-	return true
 }
 
-func testIntegerObject(t *testing.T, obj object.RubyObject, expected int64) bool {
-	result, ok := obj.(*object.Integer)
-	if !ok {
-		t.Errorf(
-			"object is not Integer. got=%T (%+v)",
-			obj,
-			obj,
-		)
-		return false
+func TestObjectIdentity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1.object_id`, "3"},
+		{`nil.object_id`, "8"},
+		{`a = "foo"; a.object_id == a.itself.object_id`, "true"},
+		{`"foo".object_id == "foo".object_id`, "false"},
+		{`:foo.object_id == :foo.object_id`, "true"},
+		{`a = "foo"; a.equal?(a)`, "true"},
+		{`"foo".equal?("foo")`, "false"},
+		{`:foo.equal?(:foo)`, "true"},
+		{`5.itself`, "5"},
 	}
-	if result.Value != expected {
-		t.Errorf(
-			"object has wrong value. got=%d, want=%d",
-			result.Value,
-			expected,
-		)
-		return false
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestTypePredicates(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1.is_a?(Integer)`, "true"},
+		{`1.kind_of?(Comparable)`, "true"},
+		{`1.is_a?(String)`, "false"},
+		{`1.instance_of?(Numeric)`, "false"},
+		{`1.instance_of?(Integer)`, "true"},
+		{`case "foo"
+		when Integer then 1
+		when String then 2
+		end`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestTapAndThen(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a = []; [1, 2].tap { |x| a << x.first }; a`, "[1]"},
+		{`[1, 2].tap { |x| 3 }`, "[1, 2]"},
+		{`5.then { |x| x + 1 }`, "6"},
+		{`5.yield_self { |x| x * 2 }.then { |x| x - 1 }`, "9"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestDefaultInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`self.inspect`, "main"},
+		{`self.to_s`, "main"},
+		{`"foo".inspect`, `"foo"`},
+		{`[1, "a"].inspect`, `[1, "a"]`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestInstanceEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2].instance_eval { first }`, "1"},
+		{`[1, 2].instance_eval { itself }`, "[1, 2]"},
+		{`[1, 2].instance_eval { |x| x.last }`, "2"},
+		{`[1, 2].instance_exec(10) { |x| first + x }`, "11"},
+		{`[].instance_eval { respond_to_missing?(:foo, false) }`, "false"},
+		{`Integer.instance_eval do
+			def answer_for_instance_eval
+				42
+			end
+		end
+		Integer.answer_for_instance_eval`, "42"},
+		{`Integer.class_eval do
+			def double_for_class_eval
+				abs + abs
+			end
+		end
+		4.double_for_class_eval`, "8"},
+		{`Integer.class_exec(3) do |x|
+			x
+		end`, "3"},
+		{`Comparable.module_eval do
+			def compared_for_module_eval
+				1
+			end
+		end
+		"a".compared_for_module_eval`, "1"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestERB(t *testing.T) {
+	input := `items = ["a", "b"]
+	def title
+		"List"
+	end
+	template = ERB.new("<%= title %>:\n<% items.each do |item| -%>\n- <%= item %>\n<% end -%>\n", trim_mode: "-")
+	template.result(binding)
+	`
+
+	evaluated, err := testEval(input, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+	checkError(t, err)
+
+	expected := "List:\n- a\n- b\n"
+	if str, ok := evaluated.(*object.String); !ok || str.Value != expected {
+		t.Logf("Expected result to equal %q, got %s", expected, evaluated.Inspect())
+		t.Fail()
+	}
+}
+
+func TestEvalString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`eval("1 + 2")`, "3"},
+		{`x = 5; eval("x * 2")`, "10"},
+		{`eval("y = 3"); y`, "3"},
+		{`b = binding; x = 4; eval("x", b)`, "4"},
+		{`x = 1; b = binding; b.local_variable_set(:x, 7); x`, "7"},
+		{`x = 2; binding.local_variable_get(:x)`, "2"},
+		{`binding.local_variable_defined?(:unknown)`, "false"},
+		{`def get_binding
+			z = 9
+			binding
+		end
+		eval("z", get_binding)`, "9"},
+		{`eval("[1, 2].first", nil)`, "1"},
+		{`x = 3; binding.eval("x + 1")`, "4"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := testEval(`eval("1 +")`, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+
+		syntaxError, ok := err.(*object.SyntaxError)
+		if !ok {
+			t.Logf("Expected SyntaxError, got %T (%v)", err, err)
+			t.FailNow()
+		}
+		var parseErrors *parser.Errors
+		if syntaxError.Path != "(eval)" || !errors.As(err, &parseErrors) {
+			t.Logf("Expected SyntaxError to hold the errors of the parser within (eval), got %q and %v", syntaxError.Path, syntaxError.Unwrap())
+			t.Fail()
+		}
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := testEval(`eval(1)`, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+		expected := object.NewImplicitConversionTypeError(&object.String{}, object.NewInteger(1))
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+
+		_, err = testEval(`eval("1", 2)`, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+		expected = object.NewTypeError("wrong argument type Integer (expected binding)")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`puts;`, nil},
+		{`puts "foo";`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNilObject(t, evaluated)
+		case string:
+			ok := IsError(evaluated)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)",
+					evaluated, evaluated)
+				continue
+			}
+			if evaluated.Inspect() != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestStatementSeparators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"def foo(a); a; end; foo 1; x = foo 2; x", 2},
+		{"x = 1;; y = 2; x + y", 3},
+		{"x = 3 \\\n * 2\nx", 6},
+		{"[3, 1, 2]\n  .sort\n  # the smallest\n  .min", 1},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestControlStructureValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = if 1 < 2 then 1 else 2 end; x", "1"},
+		{"x = if 1 > 2 then 1 end; x", "nil"},
+		{"x = if true; end; x", "nil"},
+		{"x = case 2\nwhen 1 then :one\nwhen 2, 3 then :two\nend; x", ":two"},
+		{"x = case 5; when 1; :one; else :other; end; x", ":other"},
+		{"x = case 5; when 1; :one; end; x", "nil"},
+		{"x = case :b; when :a then 1; when :b then 2; end; x", "2"},
+		{`x = case "foo"; when "bar" then 1; when "foo" then 2; end; x`, "2"},
+		{"x = case; when 1 > 2 then 1; when 2 > 1 then 2; end; x", "2"},
+		{"x = begin\n1\n2\nend; x", "2"},
+		{"i = 0; x = while i < 3 do i = i + 1 end; x", "nil"},
+		{"i = 0; while i < 3; i = i + 1; end; i", "3"},
+		{"i = 0; x = while true; i = i + 1; if i > 4 then break i * 2 end; end; x", "10"},
+		{"x = while true; break; end; x", "nil"},
+		{"i = 0\nwhile i < 3\n  while true\n    break\n  end\n  i = i + 1\nend\ni", "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("return from within loop", func(t *testing.T) {
+		input := "def foo; while true; return 3; end; end; foo"
+
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		evaluated, err := testEval(input, env)
+		checkError(t, err)
+		testIntegerObject(t, evaluated, 3)
+	})
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"größe = 3; größe", "3"},
+		{"def grüße(name); name; end; grüße :welt", ":welt"},
+		{"π = 3; 😀 = π * 2; 😀", "6"},
+		{`"\u{1F600}"`, "😀"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestMethodCalls(t *testing.T) {
+	input := "x = 2; x.foo :bar"
+
+	evaluated, err := testEval(input)
+
+	if err == nil {
+		t.Logf("Expected error, got %T:%s\n", evaluated, evaluated)
+		t.Fail()
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+	evaluated, err := testEval(input)
+	checkError(t, err)
+
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(result.Elements) != 3 {
+		t.Fatalf(
+			"array has wrong num of elements. got=%d",
+			len(result.Elements),
+		)
+	}
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{
+			"[1, 2, 3][0]",
+			1,
+		},
+		{
+			"[1, 2, 3][1]",
+			2,
+		},
+		{
+			"[1, 2, 3][2]",
+			3,
+		},
+		{
+			"i = 0; [1][i];",
+			1,
+		},
+		{
+			"[1, 2, 3][1 + 1];",
+			3,
+		},
+		{
+			"myArray = [1, 2, 3]; myArray[2];",
+			3,
+		},
+		{
+			"myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
+			6,
+		},
+		{
+			"myArray = [1, 2, 3]; i = myArray[0]; myArray[i]",
+			2,
+		},
+		{
+			"[1, 2, 3][3]",
+			nil,
+		},
+		{
+			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-4]",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+	}{
+		{
+			`{"foo" => 1, :bar => 2 * 3}`,
+			map[string]int64{"foo": 1, ":bar": 6},
+		},
+		{
+			`{foo: 1, bar: 2}`,
+			map[string]int64{":foo": 1, ":bar": 2},
+		},
+		{
+			`x = 3; y = 4; {x:, y:}`,
+			map[string]int64{":x": 3, ":y": 4},
+		},
+		{
+			`def opts x; x; end; opts(level: 2, verbose: 1)`,
+			map[string]int64{":level": 2, ":verbose": 1},
+		},
+		{
+			`def opts x; x; end; opts "a" => 2`,
+			map[string]int64{"a": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.Set("self", &object.Self{RubyObject: &object.Object{}})
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+
+		hash, ok := evaluated.(*object.Hash)
+		if !ok {
+			t.Fatalf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
+		}
+
+		actual := make(map[string]int64)
+		for _, key := range hash.Keys() {
+			value, _ := hash.Get(key)
+			integer, ok := value.(*object.Integer)
+			if !ok {
+				t.Fatalf("value is not Integer. got=%T (%+v)", value, value)
+			}
+			actual[key.Inspect()] = integer.Value
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("Expected hash to equal %v, got %v", tt.expected, actual)
+		}
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{foo: 5}[:foo]`, 5},
+		{`{"foo" => 5}["foo"]`, 5},
+		{`{1 => 5}[1]`, 5},
+		{`{foo: 5}[:bar]`, nil},
+		{`key = :foo; {key => 5}[:foo]`, 5},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input)
+		checkError(t, err)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNilObject(t, evaluated)
+		}
+	}
+}
+
+func TestNilExpression(t *testing.T) {
+	input := "nil"
+	evaluated, err := testEval(input)
+	checkError(t, err)
+	testNilObject(t, evaluated)
+}
+
+func TestSelfExpression(t *testing.T) {
+	input := "self"
+
+	env := object.NewMainEnvironment()
+	env.Set("self", &object.Self{RubyObject: &object.Integer{Value: 3}})
+	evaluated, err := testEval(input, env)
+	checkError(t, err)
+
+	self, ok := evaluated.(*object.Self)
+	if !ok {
+		t.Logf("Expected evaluated object to be object.Self, got=%T", evaluated)
+		t.Fail()
+	}
+
+	testIntegerObject(t, self.RubyObject, 3)
+}
+
+func TestAutoload(t *testing.T) {
+	t.Run("autoload requires file on first reference", func(t *testing.T) {
+		input := `autoload :Autoloaded, "testfile_autoload"
+		path = autoload?(:Autoloaded)
+		[path, Autoloaded + 1, autoload?(:Autoloaded)]
+		`
+
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+
+		expected := "[testfile_autoload, 43, nil]"
+		if evaluated.Inspect() != expected {
+			t.Logf("Expected result to equal %s, got %s", expected, evaluated.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("file not defining the constant", func(t *testing.T) {
+		input := `autoload :NotDefined, "testfile"
+		NotDefined
+		`
+
+		_, err := testEval(input, object.NewMainEnvironment())
+
+		expected := object.NewUninitializedConstantError("NotDefined")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+	t.Run("invalid constant name", func(t *testing.T) {
+		_, err := testEval(`autoload :foo, "testfile"`, object.NewMainEnvironment())
+
+		expected := object.NewInvalidConstantNameError("autoload must be constant name: %s", "foo")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestRequireExpression(t *testing.T) {
+	t.Run("simple require", func(t *testing.T) {
+		input := `require "testfile.rb"
+		x + 2
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, int64(7))
+	})
+	t.Run("simple require no filetype", func(t *testing.T) {
+		input := `require "testfile"
+		x + 2
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, int64(7))
+	})
+	t.Run("require return value", func(t *testing.T) {
+		input := `require "testfile.rb"
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testBooleanObject(t, evaluated, true)
+	})
+	t.Run("require appends to $LOADED_FEATURES", func(t *testing.T) {
+		input := `require "testfile.rb"
+		`
+
+		env := object.NewEnvironment()
+		_, err := testEval(input, env)
+		checkError(t, err)
+
+		loadedFeatures, ok := env.Get("$LOADED_FEATURES")
+		if !ok {
+			t.Logf("Expected env to contain $LOADED_FEATURES object")
+			t.Fail()
+		}
+
+		arr, ok := loadedFeatures.(*object.Array)
+		if !ok {
+			t.Logf("Expected loadedFeatures to be an array, got %T", loadedFeatures)
+			t.Fail()
+		}
+
+		expectedLen := 1
+		actualLen := len(arr.Elements)
+
+		if expectedLen != actualLen {
+			t.Logf("Expected array to have %d elements, got %d", expectedLen, actualLen)
+			t.Fail()
+		}
+
+		expectedElemValue, _ := filepath.Abs("testfile.rb")
+		actualElemValue := arr.Elements[0].Inspect()
+
+		if expectedElemValue != actualElemValue {
+			t.Logf("Expected elem value to equal %s, got %s", expectedElemValue, actualElemValue)
+			t.Fail()
+		}
+	})
+	t.Run("require only parses the files once", func(t *testing.T) {
+		input := `require "testfile_require_once.rb"
+			x
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, int64(7))
+	})
+	t.Run("require returns false if already required", func(t *testing.T) {
+		input := `require "testfile"
+			require "testfile"
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testBooleanObject(t, evaluated, false)
+	})
+	t.Run("rescue LoadError of missing file", func(t *testing.T) {
+		input := `begin
+			require "this/file/does/not/exist"
+		rescue LoadError => e
+			e.path
+		end
+		`
+
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+
+		if evaluated.Inspect() != "this/file/does/not/exist" {
+			t.Logf("Expected LoadError#path to return the missing path, got %s", evaluated.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("rescue LoadError of missing relative file", func(t *testing.T) {
+		input := `begin
+			require "testfile_relative/missing"
+		rescue LoadError => e
+			e.path
+		end
+		`
+
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+
+		expected, _ := filepath.Abs("testfile_relative/not_there")
+		if evaluated.Inspect() != expected {
+			t.Logf("Expected LoadError#path to return %q, got %q", expected, evaluated.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("recursive require", func(t *testing.T) {
+		input := `require "testfile_recursive_require.rb"
+		x + 2
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, int64(7))
+	})
+	t.Run("require only loads the same file once", func(t *testing.T) {
+		input := `require "testfile"
+		require "./testfile.rb"
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testBooleanObject(t, evaluated, false)
+	})
+	t.Run("require searches $LOAD_PATH", func(t *testing.T) {
+		input := `$LOAD_PATH = ["testfile_relative"]
+		require "helper"
+		relative_value
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, 42)
+	})
+	t.Run("require_relative", func(t *testing.T) {
+		input := `require "testfile_relative/main"
+		relative_value
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, 42)
+	})
+	t.Run("require_relative without file", func(t *testing.T) {
+		_, err := testEval(`require_relative "testfile"`)
+
+		expected := object.NewLoadError("cannot infer basepath")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+	t.Run("load evaluates the file every time", func(t *testing.T) {
+		input := `loaded = 0
+		load "testfile_load.rb"
+		load "testfile_load.rb"
+		loaded
+		`
+
+		evaluated, err := testEval(input)
+		checkError(t, err)
+
+		testIntegerObject(t, evaluated, 2)
+	})
+	t.Run("error in file", func(t *testing.T) {
+		env := object.NewEnvironment()
+		_, err := testEval(`require "testfile_error"`, env)
+
+		if _, ok := err.(*object.ZeroDivisionError); !ok {
+			t.Logf("Expected ZeroDivisionError, got %T:%v", err, err)
+			t.Fail()
+		}
+		features, _ := env.Get("$LOADED_FEATURES")
+		if len(features.(*object.Array).Elements) != 0 {
+			t.Logf("Expected failed file not to be recorded as loaded, got %s", features.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("syntax error in file", func(t *testing.T) {
+		input := `require "testfile_syntax_error.rb"
+		`
+
+		evaluated, err := testEval(input)
+		if err == nil {
+			t.Errorf(
+				"no error returned. got=%T(%+v)",
+				evaluated,
+				evaluated,
+			)
+		}
+
+		actual, ok := err.(object.RubyObject)
+		if !ok {
+			t.Logf("Error is not a RubyObject, got %T:%v\n", err, err)
+			t.FailNow()
+		}
+
+		expected := "SyntaxError: syntax error, Parsing errors:\n\texpected next token to be of type [END], got EOF instead\n"
+		testExceptionObject(t, actual, expected)
+	})
+	t.Run("file not found", func(t *testing.T) {
+		input := `require "this/file/does/not/exist"
+		`
+
+		evaluated, err := testEval(input)
+
+		if err == nil {
+			t.Errorf(
+				"no error returned. got=%T(%+v)",
+				evaluated,
+				evaluated,
+			)
+		}
+
+		actual, ok := err.(object.RubyObject)
+		if !ok {
+			t.Logf("Error is not a RubyObject, got %T:%v\n", err, err)
+			t.FailNow()
+		}
+
+		expected := "LoadError: no such file to load -- this/file/does/not/exist"
+		testExceptionObject(t, actual, expected)
+	})
+}
+
+func TestSourceLoader(t *testing.T) {
+	files := map[string]string{
+		"main.rb":       "require_relative \"lib/helper\"\nhelper + 1",
+		"lib/helper.rb": "def helper\n41\nend",
+		"lib/value.rb":  "value = 2",
+	}
+	loaders := map[string]SourceLoader{
+		"MapLoader": MapLoader(files),
+		"FSLoader": FSLoader(func() fstest.MapFS {
+			fsys := fstest.MapFS{}
+			for name, source := range files {
+				fsys[name] = &fstest.MapFile{Data: []byte(source)}
+			}
+			return fsys
+		}()),
+		"MultiLoader": MultiLoader(OSLoader{}, MapLoader(files)),
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`require "main"`, "true"},
+		{"require \"main\"\nhelper", "41"},
+		{"require \"/main.rb\"\nrequire \"main\"", "false"},
+		{"require \"main\"\n$LOADED_FEATURES", "[/main.rb, /lib/helper.rb]"},
+		{"$LOAD_PATH = [\"lib\"]\nrequire \"helper\"\nhelper", "41"},
+		{"load \"lib/value.rb\"\nvalue", "2"},
+	}
+	for name, loader := range loaders {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				env := object.NewMainEnvironment()
+				SetSourceLoader(env, loader)
+
+				evaluated, err := testEval(tt.input, env)
+				checkError(t, err)
+
+				if evaluated.Inspect() != tt.expected {
+					t.Logf("Expected result of %q to equal %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+					t.Fail()
+				}
+			}
+		})
+	}
+	t.Run("missing file", func(t *testing.T) {
+		env := object.NewMainEnvironment()
+		SetSourceLoader(env, MapLoader(files))
+
+		_, err := testEval(`require "testfile"`, env)
+
+		expected := object.NewLoadError("testfile")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
+
+type testGreeterExtension struct{}
+
+func (testGreeterExtension) Name() string { return "test_greeter" }
+
+func (testGreeterExtension) Init() error {
+	_, err := object.DefineModule(object.ModuleDefinition{
+		Name: "TestGreeter",
+		Methods: map[string]object.RubyMethod{
+			"greet": object.NewMethod(1, 1, func(self object.RubyObject, args ...object.RubyObject) (object.RubyObject, error) {
+				return &object.String{Value: "hello " + args[0].Inspect()}, nil
+			}),
+		},
+	})
+	return err
+}
+
+func TestRequireExtension(t *testing.T) {
+	object.RegisterExtension(testGreeterExtension{})
+	env := object.NewMainEnvironment()
+
+	evaluated, err := testEval("[require \"test_greeter\", TestGreeter.greet(\"ruby\")]", env)
+	checkError(t, err)
+
+	if evaluated.Inspect() != "[false, hello ruby]" {
+		t.Logf("Expected [false, hello ruby], got %s", evaluated.Inspect())
+		t.Fail()
+	}
+}
+
+func testExceptionObject(t *testing.T, obj object.RubyObject, errorMessage string) {
+	if !IsError(obj) {
+		t.Logf("Expected error or exception, got %T", obj)
+		t.Fail()
+	}
+
+	actual := obj.Inspect()
+
+	if errorMessage != actual {
+		t.Logf("Expected obj to stringify to %q, got %q", errorMessage, actual)
+		t.Fail()
+	}
+}
+
+func testNilObject(t *testing.T, obj object.RubyObject) bool {
+	if obj != object.NIL {
+		t.Errorf("object is not NIL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
+
+func testEval(input string, context ...object.Environment) (object.RubyObject, error) {
+	env := object.NewEnvironment()
+	for _, e := range context {
+		env = object.NewEnclosedEnvironment(e)
+	}
+	l := lexer.New(input)
+	p := parser.New(l)
+	program, err := p.ParseProgram()
+	if err != nil {
+		return nil, object.NewSyntaxError(err.Error())
+	}
+	result, err := Eval(program, env)
+	// backtraces depend on the position of the error and are tested in
+	// TestBacktrace only
+	object.SetBacktrace(err, nil)
+	return result, err
+}
+
+func checkError(t *testing.T, err error) {
+	if err != nil {
+		t.Logf("Expected no error, got %T:%v\n", err, err)
+		t.Fail()
+	}
+}
+
+func testBooleanObject(t *testing.T, obj object.RubyObject, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf(
+			"object is not Boolean. got=%T (%+v)",
+			obj,
+			obj,
+		)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf(
+			"object has wrong value. got=%t, want=%t",
+			result.Value,
+			expected,
+		)
+		return false
+	}
+	return true
+}
+
+func testIntegerObject(t *testing.T, obj object.RubyObject, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf(
+			"object is not Integer. got=%T (%+v)",
+			obj,
+			obj,
+		)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf(
+			"object has wrong value. got=%d, want=%d",
+			result.Value,
+			expected,
+		)
+		return false
+	}
+	return true
+}
+
+func TestProcsAndLambdas(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`proc { |x| x }.lambda?`, "false"},
+		{`lambda { |x| x }.lambda?`, "true"},
+		{`lambda { |x, y| x }.arity`, "2"},
+		{`proc { |x, y| [x, y] }.call(1)`, "[1, nil]"},
+		{`proc { |x, y| [x, y] }.call([1, 2])`, "[1, 2]"},
+		{`lambda { |x| x }.call([1, 2])`, "[1, 2]"},
+		{`def foo; l = lambda { return 3 }; l.call + 1; end; foo`, "4"},
+		{`def foo; [1, 2].each { |x| return x * 10 }; 5; end; foo`, "10"},
+		{`def foo; [1, 2].each { |x| return }; 5; end; foo`, "nil"},
+		{`def foo
+			l = lambda do
+				return 3
+			end
+			l.call + 1
+		end
+		foo`, "4"},
+		{`def foo
+			pr = proc do
+				return 3
+			end
+			pr.call + 1
+		end
+		foo`, "3"},
+		{`def foo
+			[1, 2].each do |x|
+				return x * 10
+			end
+			5
+		end
+		foo`, "10"},
+		{`def foo
+			l = lambda do
+				[1, 2].each do |x|
+					return x
+				end
+				7
+			end
+			l.call + 1
+		end
+		foo`, "2"},
+		{`lambda { break 5 }.call`, "5"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("strict lambda arity", func(t *testing.T) {
+		_, err := testEval(`lambda { |x, y| x }.call(1)`, object.NewMainEnvironment())
+		expected := object.NewWrongNumberOfArgumentsError(2, 1)
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestCatchAndThrow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`catch(:done) do
+			7
+		end`, "7"},
+		{`catch(:done) do
+			[1, 2, 3].each do |x|
+				if x == 2
+					throw :done, x * 10
+				end
+			end
+			5
+		end`, "20"},
+		{`catch(:done) do
+			throw :done
+		end`, "nil"},
+		{`catch do |tag|
+			throw tag, 3
+		end`, "3"},
+		{`catch(:outer) do
+			catch(:inner) do
+				throw :outer, 1
+			end
+			2
+		end`, "1"},
+		{`def finish
+			throw :out, 4
+		end
+		catch(:out) do
+			finish
+		end`, "4"},
+		{`def finish
+			throw :unknown
+		end
+		finish rescue 9`, "9"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("uncaught throw", func(t *testing.T) {
+		tests := []string{
+			`throw :done, 1`,
+			`catch(:other) do
+				throw :done, 1
+			end`,
+		}
+
+		for _, input := range tests {
+			_, err := testEval(input, object.NewMainEnvironment())
+			expected := object.NewUncaughtThrowError(&object.Symbol{Value: "done"}, object.NewInteger(1))
+			if !reflect.DeepEqual(err, expected) {
+				t.Logf("Expected error to equal %v, got %v", expected, err)
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestCaller(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`caller`, "[]"},
+		{`caller(0).first`, "-:1:in `<main>'"},
+		{`caller(3)`, "nil"},
+		{`def foo
+			caller(0)
+		end
+		foo.last`, "-:4:in `<main>'"},
+		{`def foo
+			caller.first
+		end
+		def bar
+			foo
+		end
+		bar`, "-:5:in `bar'"},
+		{`def foo
+			[1].map do |x|
+				caller(0, 2)
+			end.first
+		end
+		foo.last`, "-:2:in `map'"},
+		{`def foo
+			[1].map do |x|
+				caller(0, 2)
+			end.first
+		end
+		foo.first`, "-:3:in `block in foo'"},
+		{`def foo
+			caller_locations(0).first
+		end
+		foo.lineno`, "2"},
+		{`def foo
+			caller_locations(1, 1).first
+		end
+		foo.label`, "<main>"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := testEval(`caller(-1)`, object.NewMainEnvironment())
+		expected := object.NewArgumentError("negative level (-1)")
+		if !reflect.DeepEqual(err, expected) {
+			t.Logf("Expected error to equal %v, got %v", expected, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestMethodLocals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`def locals_sum(a, b)
+			c = a + b
+			c * 2
+		end
+		locals_sum(1, 2)`, "6"},
+		{`def locals_each
+			sum = 0
+			[1, 2, 3].each { |x| sum = sum + x }
+			sum
+		end
+		locals_each`, "6"},
+		{`def locals_block
+			[1, 2].map { |x| y = x; y }
+		end
+		locals_block`, "[1, 2]"},
+		{`def locals_eval
+			eval("x = 3")
+			if false
+				x = 1
+			end
+			x
+		end
+		locals_eval`, "3"},
+		{`def locals_binding
+			x = 1
+			b = binding
+			b.local_variable_set(:x, 5)
+			x
+		end
+		locals_binding`, "5"},
+		{`def locals_rescue
+			begin
+				raise "boom"
+			rescue => e
+				e.message
+			end
+		end
+		locals_rescue`, "boom"},
+		{`def locals_recursive(n)
+			m = n
+			if n > 0
+				locals_recursive(n - 1)
+			end
+			m
+		end
+		locals_recursive(3)`, "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("identifiers are resolved to slots", func(t *testing.T) {
+		program, err := parser.New(lexer.New("def foo(a)\nb = a\n[1].each { |c| b }\nend")).ParseProgram()
+		checkError(t, err)
+		fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+		scope := resolveScope(fn)
+
+		expected := []string{currentMethodKey, "a", "b"}
+		if !reflect.DeepEqual(scope.Names, expected) {
+			t.Logf("Expected scope to hold %v, got %v", expected, scope.Names)
+			t.Fail()
+		}
+		assignment := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.VariableAssignment)
+		if assignment.Name.Scope != scope || assignment.Name.Slot != 2 {
+			t.Logf("Expected b to be resolved to slot 2")
+			t.Fail()
+		}
+		if value := assignment.Value.(*ast.Identifier); value.Scope != scope || value.Slot != 1 {
+			t.Logf("Expected a to be resolved to slot 1")
+			t.Fail()
+		}
+	})
+}
+
+func TestSystemStackError(t *testing.T) {
+	t.Run("is rescuable", func(t *testing.T) {
+		input := `
+		def down(n)
+			down(n + 1)
+		end
+		begin
+			down(0)
+		rescue SystemStackError => e
+			[e.message, e.backtrace.first, e.backtrace[8], e.backtrace.last]
+		end
+		`
+		evaluated, err := testEval(input, object.NewMainEnvironment())
+		checkError(t, err)
+
+		expected := "[stack level too deep, -:3:in `down',  ... 9984 levels..., -:6:in `<main>']"
+		if evaluated.Inspect() != expected {
+			t.Logf("Expected %s, got %s", expected, evaluated.Inspect())
+			t.Fail()
+		}
+	})
+
+	t.Run("within blocks", func(t *testing.T) {
+		_, err := testEval("def down; [1].each { down }; end; down", object.NewMainEnvironment())
+		if _, ok := err.(*object.SystemStackError); !ok {
+			t.Logf("Expected SystemStackError, got %T (%v)", err, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestTailCalls(t *testing.T) {
+	countdown := `
+	def countdown(n)
+		if n == 0
+			return caller(0)
+		end
+		countdown(n - 1)
+	end
+	`
+
+	t.Run("frames are replaced", func(t *testing.T) {
+		env := object.NewMainEnvironment()
+		SetTailCallOptimization(env, true)
+		defer SetTailCallOptimization(env, false)
+
+		evaluated, err := testEval(countdown+"countdown(1000)", env)
+		checkError(t, err)
+
+		frames := evaluated.(*object.Array).Elements
+		expected := []string{"-:4:in `countdown'", "-:8:in `<main>'"}
+		if len(frames) != len(expected) {
+			t.Fatalf("Expected %d frames, got %d", len(expected), len(frames))
+		}
+		for i, frame := range frames {
+			if frame.Inspect() != expected[i] {
+				t.Logf("Expected frame %d to equal %s, got %s", i, expected[i], frame.Inspect())
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("frames are kept when disabled", func(t *testing.T) {
+		evaluated, err := testEval(countdown+"countdown(10)", object.NewMainEnvironment())
+		checkError(t, err)
+
+		if frames := evaluated.(*object.Array).Elements; len(frames) != 12 {
+			t.Logf("Expected 12 frames, got %d", len(frames))
+			t.Fail()
+		}
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`def sum(n, acc)
+			if n == 0
+				acc
+			else
+				sum(n - 1, acc + n)
+			end
+		end
+		sum(100, 0)`, "5050"},
+		{`def fact(n)
+			if n < 2
+				1
+			else
+				n * fact(n - 1)
+			end
+		end
+		fact(10)`, "3628800"},
+		{`class TailCallEven
+			def even?(n)
+				if n == 0
+					return true
+				end
+				odd?(n - 1)
+			end
+			def odd?(n)
+				if n == 0
+					false
+				else
+					even?(n - 1)
+				end
+			end
+		end
+		TailCallEven.new.even?(101)`, "false"},
+		{`def wrap(x)
+			[x]
+		end
+		def tail(x)
+			wrap(x)
+		end
+		tail(3)`, "[3]"},
+		{`def tail(x)
+			x.to_s
+		end
+		tail(3)`, "3"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewMainEnvironment()
+		SetTailCallOptimization(env, true)
+		defer SetTailCallOptimization(env, false)
+
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"%05.2f" % 3.14159`, `03.14`},
+		{`"%s-%s" % [1, :a]`, `1-a`},
+		{`"%<x>d%%" % { x: 50 }`, `50%`},
+		{`format("%-4s|", "ab")`, `ab  |`},
+		{`sprintf("%#x", 255)`, `0xff`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestEqualityProtocol(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1.eql?(1)`, `true`},
+		{`1.eql?(1.0)`, `false`},
+		{`"a".hash == "a".hash`, `true`},
+		{`[1, 2].hash == [1, 2].hash`, `true`},
+		{`[1, 2].eql?([1, 2.0])`, `false`},
+		{`{ a: 1 } == { a: 1 }`, `true`},
+		{`{ [1, 2] => :a }[[1, 2]]`, `:a`},
+		{`{ (1..2) => :a }[(1..2)]`, `:a`},
+		{`[(1..2), (1..2), (1...2)].uniq`, `[1..2, 1...2]`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestModuleReflection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`String.ancestors`, `[String, Comparable, Object, Kernel, BasicObject]`},
+		{`String.ancestors.first == String`, `true`},
+		{`Kernel.ancestors`, `[Kernel]`},
+		{`String.instance_methods.include?(:between?)`, `true`},
+		{`String.instance_methods(false).include?(:between?)`, `false`},
+		{`String.private_instance_methods.include?(:puts)`, `true`},
+		{`String.method_defined?(:upcase)`, `true`},
+		{`String.method_defined?("puts")`, `false`},
+		{`String.instance_method(:between?)`, `#<UnboundMethod: String(Comparable)#between?>`},
+		{`String.instance_method(:between?).owner`, `Comparable`},
+		{`String.instance_method(:upcase).bind_call("abc")`, `ABC`},
+		{`Comparable.instance_method(:between?).bind_call(2, 1, 3)`, `true`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`String.instance_method(:upcase).bind_call(1)`, object.NewMainEnvironment())
+	expected := object.NewTypeError("bind argument must be an instance of String")
+	if !reflect.DeepEqual(err, expected) {
+		t.Logf("Expected error %v, got %v", expected, err)
+		t.Fail()
+	}
+}
+
+func TestClassNew(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Object.new.class`, `Object`},
+		{`Object.allocate.class`, `Object`},
+		{`BasicObject.new.__id__ == 0`, `false`},
+		{`o = Object.new; o.instance_variable_set(:@a, 1); o.dup.instance_variable_get(:@a)`, `1`},
+		{`class Z; def initialize(a); instance_variable_set(:@a, a); end; end; Z.new(3).instance_variable_get(:@a)`, `3`},
+		{`class Z; def initialize; end; end; [Z.instance_methods(false), Z.public_instance_methods(false), Z.private_instance_methods(false)]`, `[[], [], [:initialize]]`},
+		{`class Z; def initialize; end; end; begin; Z.new.initialize; rescue NoMethodError => e; e.message.include?("private method"); end`, `true`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{`Object.new(1)`, object.NewWrongNumberOfArgumentsError(0, 1)},
+		{`Integer.new`, object.NewTypeError("allocator undefined for Integer")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestAnonymousClasses(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Class.new.superclass`, `Object`},
+		{`Class.new(String).superclass`, `String`},
+		{`Foo = Class.new; Foo`, `Foo`},
+		{`Foo = Class.new; Foo.new.class`, `Foo`},
+		{`Foo = Class.new; Bar = Class.new(Foo); Bar.ancestors`, `[Bar, Foo, Object, Kernel, BasicObject]`},
+		{`Foo = Class.new do
+			def greet
+				"hello"
+			end
+		end
+		Foo.new.greet`, `hello`},
+		{`Foo = Class.new do
+			def initialize(name)
+				instance_variable_set(:@name, name)
+			end
+			def name
+				instance_variable_get(:@name)
+			end
+		end
+		Bar = Class.new(Foo)
+		Bar.new("bar").name`, `bar`},
+		{`Foo = Class.new; Foo.instance_eval do
+			def build
+				new
+			end
+		end
+		Class.new(Foo).build.class.superclass`, `Foo`},
+		{`Greeter = Module.new do
+			def greet
+				"hi"
+			end
+		end
+		Greeter.instance_methods`, `[:greet]`},
+		{`Module.new.class`, `Module`},
+		{`double = Object.new
+		double.instance_eval do
+			def call
+				42
+			end
+		end
+		double.call`, `42`},
+		{`o = Object.new; o.singleton_class.class_eval do
+			def foo
+				1
+			end
+		end
+		o.foo`, `1`},
+		{`o = Object.new; o.singleton_class.instance_methods(false)`, `[]`},
+		{`o = Object.new; o.singleton_class; o.class`, `Object`},
+		{`"x".singleton_class.instance_methods(false)`, `[]`},
+		{`[].singleton_class.superclass`, `Array`},
+		{`s = "x"; s.singleton_class == s.singleton_class`, `true`},
+		{`s = "x"; s.singleton_class.class_eval do
+			def shout
+				upcase
+			end
+		end
+		[s.shout, "y".respond_to?(:shout)]`, `[X, false]`},
+		{`o = Object.new; o.instance_eval do
+			def foo
+				1
+			end
+		end
+		o.clone.foo`, `1`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{`Class.new(1)`, object.NewTypeError("superclass must be a Class (Integer given)")},
+		{`Class.new(Class)`, object.NewTypeError("can't make subclass of Class")},
+		{`1.singleton_class`, object.NewTypeError("can't define singleton")},
+		{`1.5.singleton_class`, object.NewTypeError("can't define singleton")},
+		{`:a.singleton_class`, object.NewTypeError("can't define singleton")},
+		{`nil.singleton_class`, object.NewTypeError("can't define singleton")},
+		{`true.singleton_class`, object.NewTypeError("can't define singleton")},
+		{`o = Object.new; o.instance_eval do
+			def foo
+				1
+			end
+		end
+		o.dup.foo`, nil},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if tt.expected == nil {
+			if _, ok := err.(*object.NoMethodError); !ok {
+				t.Logf("Expected NoMethodError for %q, got %v", tt.input, err)
+				t.Fail()
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestConstantReflection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Object.const_get(:String)`, `String`},
+		{`Object.const_get("Comparable")`, `Comparable`},
+		{`Outer = Module.new; Outer.const_set(:Inner, Class.new); Object.const_get("Outer::Inner")`, `Outer::Inner`},
+		{`Outer = Module.new; Outer.const_set(:VALUE, 42); Outer.const_get(:VALUE)`, `42`},
+		{`Outer = Module.new; Outer.const_set(:VALUE, 42); Object.const_get("::Outer::VALUE")`, `42`},
+		{`Object.const_set(:ANSWER, 42); ANSWER`, `42`},
+		{`Parent = Class.new; Parent.const_set(:LIMIT, 3); Class.new(Parent).const_get(:LIMIT)`, `3`},
+		{`Parent = Class.new; Parent.const_set(:LIMIT, 3); Class.new(Parent).const_defined?(:LIMIT, false)`, `false`},
+		{`Parent = Class.new; Parent.const_set(:LIMIT, 3); Child = Class.new(Parent); Child.const_set(:SIZE, 1); Child.constants`, `[:SIZE, :LIMIT]`},
+		{`Parent = Class.new; Parent.const_set(:LIMIT, 3); Child = Class.new(Parent); Child.constants(false)`, `[]`},
+		{`Class.new.const_get(:String)`, `String`},
+		{`Object.const_defined?(:String)`, `true`},
+		{`Object.const_defined?("String::Missing")`, `false`},
+		{`Object.const_defined?(:Missing)`, `false`},
+		{`Object.constants.include?(:Comparable)`, `true`},
+		{`module Nest; X = 1; module B; Y = 2; end; end; Nest.constants`, `[:X, :B]`},
+		{`module Nest; X = 1; module B; Y = 2; end; end; Nest::B.constants`, `[:Y]`},
+		{`module Nest; X = 1; class C; Y = X + 1; def y; [X, Y]; end; end; end; Nest::C.new.y`, `[1, 2]`},
+		{`module Nest; module B; end; end; Nest::B`, `Nest::B`},
+		{`module Nest; Hidden = 1; end; Object.const_defined?(:Hidden)`, `false`},
+		{`Lookup = Class.new
+		Lookup.instance_eval do
+			def const_missing(name)
+				name
+			end
+		end
+		Lookup.const_get(:Anything)`, `:Anything`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{`Object.const_get(:Missing)`, object.NewUninitializedConstantError("Missing")},
+		{`Missing`, object.NewUninitializedConstantError("Missing")},
+		{`Scope = Module.new; Scope.const_get(:Missing)`, object.NewUninitializedConstantError("Scope::Missing")},
+		{`Object.const_get(:lower)`, object.NewInvalidConstantNameError("wrong constant name %s", "lower")},
+		{`Object.const_set("A::B", 1)`, object.NewInvalidConstantNameError("wrong constant name %s", "A::B")},
+		{`Object.const_set(:FOO, 1); Object.const_get("FOO::BAR")`, object.NewTypeError("1 does not refer to class/module")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestScopedConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Thread::Queue`, `Thread::Queue`},
+		{`::String`, `String`},
+		{`Outer = Module.new; Outer.const_set(:Inner, Class.new); Outer::Inner`, `Outer::Inner`},
+		{`Outer = Module.new; Outer.const_set(:Inner, Class.new); Outer::Inner.new.class`, `Outer::Inner`},
+		{`Outer = Module.new; Outer.const_set(:VALUE, 42); Object::Outer::VALUE`, `42`},
+		{`Parent = Class.new; Parent.const_set(:LIMIT, 3); Class.new(Parent)::LIMIT`, `3`},
+		{"begin\nJSON.parse(\"{\")\nrescue JSON::ParserError => e\ne.class\nend", `JSON::ParserError`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{`Thread::Missing`, object.NewUninitializedConstantError("Thread::Missing")},
+		{`5::Missing`, object.NewTypeError("5 is not a class/module")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestCommandSubstitution(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`echo hello`", "hello\n"},
+		{"%x(echo a b | tr a-z A-Z)", "A B\n"},
+		{"$?", `nil`},
+		{"`exit 3`; $?.exitstatus", `3`},
+		{"system(\"exit 0\")", `true`},
+		{"system(\"exit 1\"); [$?.success?, $?.exitstatus]", `[false, 1]`},
+		{"system(\"goruby_missing_command\")", `nil`},
+		{"system({\"GREETING\" => \"hi\"}, \"test $GREETING = hi\")", `true`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	var out bytes.Buffer
+	env := object.NewMainEnvironment()
+	SetStreams(env, &object.Streams{Stdin: object.NewIO(strings.NewReader("")), Stdout: &out, Stderr: &out})
+	_, err := testEval(`system("echo", "to", "streams")`, env)
+	checkError(t, err)
+	if out.String() != "to streams\n" {
+		t.Errorf("Expected the output of system to be written to the streams, got %q", out.String())
+	}
+}
+
+func TestMethodVisibility(t *testing.T) {
+	account := `Account = Class.new do
+		def initialize(balance); instance_variable_set(:@balance, balance); end
+		def balance; instance_variable_get(:@balance); end
+		protected :balance
+		def richer?(other); balance > other.balance; end
+		private def secret; 42; end
+		def reveal; secret; end
+	end
+	`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{account + `Account.new(5).richer?(Account.new(3))`, `true`},
+		{account + `Account.new(5).reveal`, `42`},
+		{account + `Account.protected_instance_methods(false)`, `[:balance]`},
+		{account + `Account.private_instance_methods(false)`, `[:initialize, :secret]`},
+		{account + `Account.method_defined?(:secret)`, `false`},
+		{account + `Rich = Class.new(Account) { public :balance }; Rich.new(7).balance`, `7`},
+		{account + `Rich = Class.new(Account) { public :balance }; Account.protected_instance_methods(false)`, `[:balance]`},
+		{
+			`Foo = Class.new do
+				private
+				def a; 1; end
+				public
+				def b; a; end
+				protected
+				def c; 2; end
+			end
+			[Foo.new.b, Foo.private_instance_methods(false), Foo.protected_instance_methods(false), Foo.public_instance_methods(false)]`,
+			`[1, [:a], [:c], [:b]]`,
+		},
+		{`Foo = Class.new { private :to_s, :inspect }; Foo.private_instance_methods(false)`, `[:inspect, :to_s]`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input   string
+		message string
+	}{
+		{account + `Account.new(5).balance`, "protected method `balance' called for"},
+		{account + `Account.new(5).secret`, "private method `secret' called for"},
+		{account + `Other = Class.new { def peek(a); a.balance; end }; Other.new.peek(Account.new(1))`, "protected method `balance' called for"},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if _, ok := err.(*object.NoMethodError); !ok || !strings.HasPrefix(err.Error(), tt.message) {
+			t.Logf("Expected NoMethodError %q for %q, got %v", tt.message, tt.input, err)
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`Class.new { private :foo }`, object.NewMainEnvironment())
+	if _, ok := err.(*object.NameError); !ok {
+		t.Logf("Expected NameError, got %T (%v)", err, err)
+		t.Fail()
+	}
+}
+
+func TestMethodReflection(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"abc".method(:upcase)`, `#<Method: String#upcase>`},
+		{`"abc".method(:upcase).call`, `ABC`},
+		{`"abc".method(:between?).owner`, `Comparable`},
+		{`[1].method(:push).arity`, `-1`},
+		{`[1].method(:first).parameters`, `[[:opt]]`},
+		{`1.method(:+).arity`, `1`},
+		{`2.method(:+).to_proc.call(3)`, `5`},
+		{`2.method(:+).unbind`, `#<UnboundMethod: Integer#+>`},
+		{`String.instance_method(:center).arity`, `-2`},
+		{`String.instance_method(:center).parameters`, `[[:req], [:opt]]`},
+		{`proc { |a, b| a }.parameters`, `[[:opt, :a], [:opt, :b]]`},
+		{`lambda { |a, b| a }.parameters`, `[[:req, :a], [:req, :b]]`},
+		{`[proc { |a, b| a }.arity, lambda { |a| a }.arity, proc { }.arity]`, `[2, 1, 0]`},
+		{
+			`Foo = Class.new do
+				def add(a, b); a + b; end
+			end
+			m = Foo.new.method(:add)
+			[m.arity, m.parameters, m.call(1, 2)]`,
+			`[2, [[:req, :a], [:req, :b]], 3]`,
+		},
+		{
+			`Foo = Class.new do
+				def add(a, b); a + b; end
+			end
+			Foo.instance_method(:add).parameters`,
+			`[[:req, :a], [:req, :b]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval(`1.method(:unknown)`, object.NewMainEnvironment())
+	if _, ok := err.(*object.NameError); !ok {
+		t.Logf("Expected NameError, got %T (%v)", err, err)
+		t.Fail()
+	}
+}
+
+func TestConversionFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Integer("abc", exception: false)`, `nil`},
+		{`Integer("ff", 16, exception: false)`, `255`},
+		{`o = Object.new; o.instance_eval do def to_int; 7; end end; Integer(o)`, `7`},
+		{`Float("1.5")`, `1.5`},
+		{`Float(2)`, `2.0`},
+		{`Float("x", exception: false)`, `nil`},
+		{`String(12)`, `12`},
+		{`String(:abc)`, `abc`},
+		{`Array(nil)`, `[]`},
+		{`Array(1..3)`, `[1, 2, 3]`},
+		{`Array("a")`, `[a]`},
+		{`o = Object.new; o.instance_eval do def to_a; [9]; end end; Array(o)`, `[9]`},
+		{`Hash(nil)`, `{}`},
+		{`Hash([])`, `{}`},
+		{`o = Object.new; o.instance_eval do def to_hash; {b: 2}; end end; Hash(o)`, `{:b=>2}`},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{`Integer(nil)`, object.NewTypeError("can't convert nil into Integer")},
+		{`Float("1.")`, object.NewArgumentError(`invalid value for Float(): "1."`)},
+		{`Hash(1)`, object.NewTypeError("can't convert Integer into Hash")},
+		{
+			`o = Object.new; o.instance_eval do def to_i; "x"; end end; Integer(o)`,
+			object.NewTypeError("can't convert Object to Integer (Object#to_i gives String)"),
+		},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestBeginRescue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"begin\n1\nrescue\n2\nend", "1"},
+		{"begin\nraise \"x\"\nrescue\n2\nend", "2"},
+		{"begin\nraise ArgumentError, \"bad\"\nrescue TypeError\n1\nrescue StandardError\n2\nend", "2"},
+		{"begin\n1 / 0\nrescue ZeroDivisionError\n3\nend", "3"},
+		{"begin\nfoo_bar\nrescue NameError\n4\nend", "4"},
+		{"begin\nraise EOFError\nrescue IOError\n5\nend", "5"},
+		{"begin\nraise KeyError, \"k\"\nrescue IndexError\n6\nend", "6"},
+		{"begin; raise 1; rescue TypeError; 7; end", "7"},
+		{"begin; raise Comparable; rescue TypeError; 8; end", "8"},
+		{"begin; raise; rescue RuntimeError; 9; end", "9"},
+		{"begin; raise ArgumentError; rescue TypeError, ArgumentError; 10; end", "10"},
+		{"begin; raise \"x\"; rescue => e; e.message; end", "x"},
+		{"begin; raise TypeError, \"t\"; rescue ArgumentError, TypeError => e; e; end", "TypeError: t"},
+		{"errors = [KeyError, TypeError]; begin; raise TypeError; rescue *errors; 11; end", "11"},
+		{"errors = [KeyError]; begin; raise TypeError; rescue *errors, TypeError => err; end; err.class", "TypeError"},
+		{`ArgumentError.new("m").message`, "m"},
+		{`ArgumentError.new.message`, "ArgumentError"},
+		{`RuntimeError.exception("y")`, "RuntimeError: y"},
+		{`KeyError.new("k").full_message`, "k (KeyError)"},
+		{`e = RuntimeError.new("a"); [e.exception.equal?(e), e.exception("b").message]`, "[true, b]"},
+		{`RuntimeError.new("a") == RuntimeError.new("a")`, "true"},
+		{`StopIteration.ancestors`, "[StopIteration, IndexError, StandardError, Exception, Object, Kernel, BasicObject]"},
+		{`FloatDomainError.superclass`, "RangeError"},
+		{`Interrupt.ancestors.include?(StandardError)`, "false"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"begin\nraise \"x\"\nrescue TypeError\n1\nend", object.NewRuntimeError("x")},
+		{"begin\nraise \"x\"\nrescue 1\n1\nend", object.NewTypeError("class or module required for rescue clause")},
+		{"begin\nraise \"x\"\nrescue *[1]\n1\nend", object.NewTypeError("class or module required for rescue clause")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+
+	_, err := testEval("begin\nraise Exception, \"e\"\nrescue\n1\nend", object.NewMainEnvironment())
+	if err == nil || err.Error() != "e" {
+		t.Logf("Expected Exception not to be rescued by default, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestErrorInfo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"$!", "nil"},
+		{"begin; raise \"x\"; rescue; $!; end", "RuntimeError: x"},
+		{"begin; raise \"x\"; rescue; $ERROR_INFO.message; end", "x"},
+		{"begin; raise \"x\"; rescue; end; $!", "nil"},
+		{"begin; raise \"x\"; rescue; $@.class; end", "Array"},
+		{"begin; raise \"a\"; rescue; begin; raise \"b\"; rescue; end; $!.message; end", "a"},
+		{"begin\nbegin\nraise TypeError, \"t\"\nrescue\nraise\nend\nrescue TypeError => e\ne\nend", "TypeError: t"},
+		{"def reraise; raise; end; begin; begin; raise \"x\"; rescue; reraise; end; rescue => e; e.message; end", "x"},
+		{"begin; fail; rescue => e; e.message; end", "unhandled exception"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"begin\n1\nensure\n2\nend", "1"},
+		{"x = 0\nbegin\nx = 1\nensure\nx = x + 1\nend\nx", "2"},
+		{"def m\nraise \"x\"\nrescue\n1\nensure\n$ensured = 5\nend\n[m, $ensured]", "[1, 5]"},
+		{"def m\nreturn 1\nensure\n$ensured = 2\nend\n[m, $ensured]", "[1, 2]"},
+		{"def m\n1\nensure\nreturn 3\nend\nm", "3"},
+		{"r = [1, 2].each do |x|\nbegin\nbreak 7\nensure\n$ensured = x\nend\nend\n[r, $ensured]", "[7, 1]"},
+		{"[1].each do |x|\nbegin\nnext\nensure\n$ensured = 4\nend\nend\n$ensured", "4"},
+		{"begin\nbegin\nraise \"y\"\nensure\n$ensured = 6\nend\nrescue\n$ensured\nend", "6"},
+		{"def m(x)\nx.foo\nrescue NoMethodError\nx\nend\nm(8)", "8"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval("begin\nraise \"a\"\nensure\nraise \"b\"\nend", object.NewMainEnvironment())
+	if !reflect.DeepEqual(err, object.NewRuntimeError("b")) {
+		t.Logf("Expected the error of the ensure clause, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestBacktrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"1\nInteger(\"x\")", []string{"-:2:in `<main>'"}},
+		{"def m\n1\nraise \"x\"\nend\n\nm", []string{"-:3:in `m'", "-:6:in `<main>'"}},
+		{"def m\n1 / 0\nend\nm", []string{"-:2:in `m'", "-:4:in `<main>'"}},
+		{
+			"def m\n[1].each do |x|\nfoo\nend\nend\nm",
+			[]string{"-:3:in `block in m'", "-:2:in `each'", "-:2:in `m'", "-:6:in `<main>'"},
+		},
+		{"begin\nraise \"x\"\nrescue => e\nraise e\nend", []string{"-:2:in `<main>'"}},
+		{"x = {\na: 1,\nb: nil.upcase\n}", []string{"-:3:in `<main>'"}},
+		{"x = {\na: 1,\nb: 2 + nil\n}", []string{"-:3:in `<main>'"}},
+		{"x = 1\n\nclass Foo\ny = 2\nraise \"x\"\nend", []string{"-:5:in `<class:Foo>'", "-:3:in `<main>'"}},
+		{
+			"module Bar\n[1].each do |x|\nfoo\nend\nend",
+			[]string{"-:3:in `block in <module:Bar>'", "-:2:in `each'", "-:2:in `<module:Bar>'", "-:1:in `<main>'"},
+		},
+	}
+
+	for _, tt := range tests {
+		program, err := parser.New(lexer.New(tt.input)).ParseProgram()
+		if err != nil {
+			t.Fatalf("Unexpected parser error: %v", err)
+		}
+		_, err = Eval(program, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+		backtrace, ok := object.Backtrace(err)
+		if !ok {
+			t.Fatalf("Expected an exception for %q, got %T:%v", tt.input, err, err)
+		}
+		if !reflect.DeepEqual(backtrace, tt.expected) {
+			t.Logf("Expected backtrace of %q to equal %q, got %q", tt.input, tt.expected, backtrace)
+			t.Fail()
+		}
+	}
+
+	input := "x = 1\ny = [x, nil.upcase]"
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("Unexpected parser error: %v", err)
+	}
+	_, err = Eval(program, object.NewEnclosedEnvironment(object.NewMainEnvironment()))
+	expectedPosition := object.SourcePosition{Path: "-", Line: 2, Offset: strings.Index(input, ".upcase")}
+	if pos, ok := object.Position(err); !ok || pos != expectedPosition {
+		t.Logf("Expected the position %v of the failing call, got %v", expectedPosition, pos)
+		t.Fail()
+	}
+
+	evaluated, err := testEval("begin\nraise \"x\"\nrescue => e\ne.backtrace\nend", object.NewMainEnvironment())
+	checkError(t, err)
+	if evaluated.Inspect() != "[-:2:in `<main>']" {
+		t.Logf("Expected backtrace to be accessible, got %s", evaluated.Inspect())
+		t.Fail()
+	}
+}
+
+func TestNextStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3].map do |x|\nif x == 2 then next 0 end\nx\nend", "[1, 0, 3]"},
+		{"[1, 2].map { |x| next }", "[nil, nil]"},
+		{"i = 0; s = 0; while i < 5; i = i + 1; if i == 3 then next end; s = s + i; end; s", "12"},
+		{"l = lambda { next 5; 6 }; l.call", "5"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestClassDefinition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class Foo1\ndef hi\n1\nend\nend\nFoo1.new.hi", "1"},
+		{"class Foo2; end", "nil"},
+		{"class Foo3 < Array; end; Foo3.superclass", "Array"},
+		{"class Foo4; def a; 1; end; end; class Foo4; def b; 2; end; end; Foo4.new.a + Foo4.new.b", "3"},
+		{"class Foo5 < Array; end; class Foo5 < Array; 5; end", "5"},
+		{"module Foo6; def hi; 2; end; end; Foo6.instance_methods", "[:hi]"},
+		{"module Foo7; end; module Foo7; 3; end", "3"},
+		{"x.class", "NilClass"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewMainEnvironment()
+		env.Set("x", object.NIL)
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected error
+	}{
+		{"class Foo8 < Array; end; class Foo8 < Hash; end", object.NewTypeError("superclass mismatch for class Foo8")},
+		{"Foo9 = 1; class Foo9; end", object.NewTypeError("Foo9 is not a class")},
+		{"class Foo10; end; module Foo10; end", object.NewTypeError("Foo10 is not a module")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := testEval(tt.input, object.NewMainEnvironment())
+		if !reflect.DeepEqual(err, tt.expected) {
+			t.Logf("Expected error %v for %q, got %v", tt.expected, tt.input, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestSuper(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class SuperA11; def hi(x); x + 1; end; end; class SuperB11 < SuperA11; def hi(x); super(x * 2); end; end; SuperB11.new.hi(3)", "7"},
+		{"class SuperA12; def hi(x); x + 1; end; end; class SuperB12 < SuperA12; def hi(x); x = 5; super; end; end; SuperB12.new.hi(3)", "6"},
+		{"class SuperA13; def hi; 1; end; end; class SuperB13 < SuperA13; def hi; super() + 1; end; end; class SuperC13 < SuperB13; def hi; super + 1; end; end; SuperC13.new.hi", "3"},
+		{"class SuperA14; def to_s; super.class; end; end; SuperA14.new.to_s", "String"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval("class SuperA15; def hi; super; end; end; SuperA15.new.hi", object.NewMainEnvironment())
+	if _, ok := err.(*object.NoMethodError); !ok {
+		t.Logf("Expected NoMethodError, got %T", err)
+		t.Fail()
+	}
+}
+
+func TestUserDefinedExceptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"class MyError1 < StandardError; end; begin; raise MyError1; rescue MyError1; 1; end", "1"},
+		{"class MyError2 < StandardError; end; begin; raise MyError2, \"m\"; rescue StandardError; 2; end", "2"},
+		{"class MyError3 < StandardError; def initialize(msg); super(\"my: \" + msg); end; end; MyError3.new(\"x\").message", "my: x"},
+		{"class MyError4 < StandardError; def message; \"custom\"; end; end; MyError4.new.full_message", "custom (MyError4)"},
+		{"class MyError5 < StandardError; end; MyError5.new.message", "MyError5"},
+		{"class MyError6 < ArgumentError; end; MyError6.ancestors.include?(StandardError)", "true"},
+		{"RuntimeError.new.cause", "nil"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := testEval("class MyError7 < StandardError; end\nbegin\nraise \"inner\"\nrescue\nraise MyError7, \"outer\"\nend", object.NewMainEnvironment())
+	if err == nil || err.Error() != "outer" {
+		t.Fatalf("Expected MyError to be raised, got %v", err)
+	}
+	cause, sendErr := object.Send(err.(object.RubyObject), "cause")
+	checkError(t, sendErr)
+	if cause.Inspect() != "RuntimeError: inner" {
+		t.Logf("Expected cause to be the inner RuntimeError, got %s", cause.Inspect())
+		t.Fail()
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	defer object.SetWarningLevel(1)
+	env := object.NewMainEnvironment()
+	_, err := testEval("module Warning; def warn(message); $warnings << message; end; end", env)
+	checkError(t, err)
+
+	tests := []struct {
+		level    int
+		input    string
+		expected string
+	}{
+		{1, "warn \"foo\"", "foo\n"},
+		{1, "warn \"foo\", \"bar\"", "foo\nbar\n"},
+		{0, "warn \"foo\"", ""},
+		{1, "def warned1; x = 1; end", ""},
+		{2, "def warned2; x = 1; end", "-:1: warning: assigned but unused variable - x\n"},
+		{2, "def warned3\nx = 1\ny = 2\n_z = 2\ny\nend", "-:2: warning: assigned but unused variable - x\n"},
+		{2, "def warned4; x = 1; x; end", ""},
+		{2, "def warned5; $x = 1; X5 = 2; end", ""},
+		{2, "def warned6; def inner6; x = 1; end; x = 2; x; end", ""},
+		{1, "def warned7; end; def warned7; end", ""},
+		{2, "def warned8; end\ndef warned8; end", "-:2: warning: method redefined; discarding old warned8\n"},
+		{2, "class Warned9; def a; end; end; class Warned9; def a; end; end", "-:1: warning: method redefined; discarding old a\n"},
+		{2, "class Warned10; def a; end; end; class Warned11 < Warned10; def a; end; end", ""},
+	}
+
+	for _, tt := range tests {
+		object.SetWarningLevel(tt.level)
+		evaluated, err := testEval("$warnings = []; "+tt.input+"\n$warnings.join", env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to warn %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestExternalEnumeration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`e = [1, 2, 3].each; [e.next, e.next, e.next]`, "[1, 2, 3]"},
+		{`e = [1, 2].each; [e.peek, e.peek, e.next, e.peek]`, "[1, 1, 1, 2]"},
+		{`e = [1].each; e.next; begin; e.next; rescue StopIteration => x; x.message; end`, "iteration reached an end"},
+		{`e = [1].each; e.next; begin; e.peek; rescue StopIteration => x; x.result; end`, "[1]"},
+		{`e = [1, 2].each; e.next; e.rewind; e.next`, "1"},
+		{`e = 3.times; s = []; loop { s << e.next }; s`, "[0, 1, 2]"},
+		{`e = [1, 2].each; loop { e.next }`, "[1, 2]"},
+		{`a = [1, 2].each; b = [3, 4].each; s = []; loop { s << a.next + b.next }; s`, "[4, 6]"},
+		{`i = 0; loop { i = i + 1; if i == 3; break i * 10; end }`, "30"},
+		{`loop { raise StopIteration }`, "nil"},
+		{`begin; loop { raise "x" }; rescue => e; e.message; end`, "x"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestParallelIteration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3].pmap(2) { |x| x * 2 }`, "[2, 4, 6]"},
+		{`Parallel.map(1..4) { |x| x * x }`, "[1, 4, 9, 16]"},
+		{`s = []; [1, 2].peach(2) { |x| s << x }; s.sort`, "[1, 2]"},
+		{`begin; [1, 2].pmap(2) { |x| raise ArgumentError }; rescue ArgumentError => e; e.class; end`, "ArgumentError"},
+		{`[1, 2].pmap(2) { |x| Thread.current == Thread.main }`, "[false, false]"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestLazyEnumerators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`inf = Float.const_get(:INFINITY); (1..inf).lazy.map { |x| x * 2 }.select { |x| x % 3 == 0 }.first(3)`, "[6, 12, 18]"},
+		{`[1, 2, 3, 4].lazy.reject { |x| x == 2 }.take_while { |x| x < 4 }.to_a`, "[1, 3]"},
+		{`[1, 2, 3, 4].lazy.drop_while { |x| x < 2 }.drop(1).force`, "[3, 4]"},
+		{`calls = 0; [1, 2, 3].lazy.map { |x| calls = calls + 1; x }.first; calls`, "1"},
+		{`e = [1, 2].lazy.map { |x| x * 3 }; [e.next, e.next]`, "[3, 6]"},
+		{`(1..3).lazy.map { |x| x }.reduce(0) { |sum, x| sum + x }`, "6"},
+		{`[1, 2].lazy.filter_map { |x| if x > 1; x; end }.to_a`, "[2]"},
+		{`(1..2).lazy`, "#<Enumerator::Lazy: 1..2>"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`t = Time.at(60).utc; t + 30`, "1970-01-01 00:01:30 UTC"},
+		{`t = Time.at(60); (t + 1.5) - t`, "1.5"},
+		{`t = Time.at(60); [t < t + 1, t == Time.at(60)]`, "[true, true]"},
+		{`Time.at(0).utc.strftime("%Y-%m-%d")`, "1970-01-01"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestThreads(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`Thread.new { 1 + 2 }.value`, "3"},
+		{`Thread.new(2, 3) { |a, b| a * b }.value`, "6"},
+		{`s = []; t = Thread.new { s << 2 }; s << 1; t.join; s`, "[1, 2]"},
+		{`threads = [1, 2, 3].map { |i| Thread.new { i * 10 } }; threads.map { |t| t.value }`, "[10, 20, 30]"},
+		{`t = Thread.new { raise "boom" }; begin; t.join; rescue => e; e.message; end`, "boom"},
+		{`t = Thread.new { Thread.current[:x] = 1; Thread.current[:x] }; [t.value, t[:x], Thread.current[:x]]`, "[1, 1, nil]"},
+		{`t = Thread.new { 1 }; t.join; t.alive?`, "false"},
+		{`Thread.current == Thread.main`, "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestThreadSynchronization(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`m = Mutex.new; n = 0; ts = [1, 2, 3].map { Thread.new { m.synchronize { x = n; Thread.pass; n = x + 1 } } }; ts.each { |t| t.join }; n`, "3"},
+		{`m = Mutex.new; m.lock; [m.locked?, m.owned?, m.try_lock]`, "[true, true, false]"},
+		{`m = Mutex.new; begin; m.unlock; rescue ThreadError => e; e.message; end`, "Attempt to unlock a mutex which is not locked"},
+		{`q = Queue.new; t = Thread.new { q.pop + q.pop }; q << 1; q.push(2); t.value`, "3"},
+		{`q = Queue.new; r = []; t = Thread.new { while x = q.pop; r << x; end }; q << 1; q << 2; q.close; t.join; r`, "[1, 2]"},
+		{`q = Queue.new; begin; q.pop(true); rescue ThreadError => e; e.message; end`, "queue empty"},
+		{`q = Queue.new; begin; q.pop; rescue ThreadError => e; e.message; end`, "No live threads left. Deadlock?"},
+		{`q = Queue.new; q.close; begin; q << 1; rescue StopIteration => e; e.class; end`, "ClosedQueueError"},
+		{`q = SizedQueue.new(1); r = []; t = Thread.new { 3.times { |i| q << i; r << i } }; Thread.pass; a = r.first(5); 3.times { q.pop }; t.join; [a, r]`, "[[0], [0, 1, 2]]"},
+		{`q = SizedQueue.new(1); q << 1; begin; q.push(2, true); rescue ThreadError => e; e.message; end`, "queue full"},
+		{`m = Mutex.new; c = ConditionVariable.new; ready = false; t = Thread.new { m.synchronize { while !ready; c.wait(m); end; :woken } }; Thread.pass; m.synchronize { ready = true; c.signal }; t.value`, ":woken"},
+		{`m = Mutex.new; c = ConditionVariable.new; m.synchronize { c.wait(m, 0.01); m.owned? }`, "true"},
+		{`Thread.const_get(:Mutex) == Mutex`, "true"},
+		{`SizedQueue`, "Thread::SizedQueue"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestFibers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`f = Fiber.new { |x| y = Fiber.yield(x * 2); y + 1 }; [f.resume(5), f.resume(7), f.alive?]`, "[10, 8, false]"},
+		{`f = Fiber.new { 3.times { |i| Fiber.yield(i) }; :done }; [f.resume, f.resume, f.resume, f.resume]`, "[0, 1, 2, :done]"},
+		{`f = Fiber.new { Fiber.yield(1, 2) }; f.resume`, "[1, 2]"},
+		{`f = Fiber.new { Fiber.yield }; [f.resume, f.resume(1, 2)]`, "[nil, [1, 2]]"},
+		{`f = Fiber.new { raise "boom" }; begin; f.resume; rescue => e; e.message; end`, "boom"},
+		{`f = Fiber.new { 1 }; f.resume; begin; f.resume; rescue FiberError => e; e.message; end`, "dead fiber called"},
+		{`begin; Fiber.yield; rescue FiberError => e; e.message; end`, "can't yield from root fiber"},
+		{`inner = Fiber.new { Fiber.yield(:inner); :inner_done }; outer = Fiber.new { Fiber.yield(inner.resume); inner.resume }; [outer.resume, outer.resume]`, "[:inner, :inner_done]"},
+		{`e = [1, 2].each; f = Fiber.new { Fiber.yield(e.next); e.next }; [f.resume, f.resume]`, "[1, 2]"},
+		{`f = Fiber.new { Fiber.yield(1) }; f.resume; caller(0)`, "[-:1:in `<main>']"},
+		{`def walk(y)
+			y << 1
+			y << 2
+		end
+		3000.times { Enumerator.new { |y| walk(y) }.next }
+		caller(0)`, "[-:6:in `<main>']"},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"begin; while true; end; rescue Interrupt => e; e.message; end", "Interrupt"},
+		{"begin; loop { }; rescue Interrupt => e; e.class; end", "Interrupt"},
+		{"x = 0; begin; while true; x = x + 1; end; rescue Interrupt; end; x > 0", "true"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewMainEnvironment()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			object.RuntimeOf(env).RaiseInterrupt()
+		}()
+
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+	}
+
+	t.Run("not rescued by StandardError", func(t *testing.T) {
+		env := object.NewMainEnvironment()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			object.RuntimeOf(env).RaiseInterrupt()
+		}()
+
+		_, err := testEval("begin; while true; end; rescue => e; end", env)
+
+		if !reflect.DeepEqual(err, object.NewInterrupt()) {
+			t.Logf("Expected error to equal Interrupt, got %T:%v", err, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	files := map[string]string{
+		"lib.rb": "def sign(x)\nif x < 0\n-1\nelse\n1\nend\nend\n\nsign(3)\n",
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"Coverage.start\nrequire \"lib\"\nsign(5)\nCoverage.result",
+			"{/lib.rb=>[1, 2, 0, nil, 2, nil, nil, nil, 1]}",
+		},
+		{
+			"require \"lib\"\nCoverage.start\nsign(5)\n[Coverage.running?, Coverage.result]",
+			"[true, {}]",
+		},
+		{
+			"Coverage.start\nload \"lib.rb\"\nfirst = Coverage.peek_result\nload \"lib.rb\"\n[first, Coverage.result, Coverage.running?]",
+			"[{/lib.rb=>[1, 1, 0, nil, 1, nil, nil, nil, 1]}, {/lib.rb=>[1, 1, 0, nil, 1, nil, nil, nil, 1]}, false]",
+		},
+		{
+			"begin\nCoverage.result\nrescue RuntimeError => e\ne.message\nend",
+			"coverage measurement is not enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		env := object.NewMainEnvironment()
+		SetSourceLoader(env, MapLoader(files))
+		evaluated, err := testEval(tt.input, env)
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+		if object.CoverageEnabled() {
+			t.Fatalf("Expected coverage not to be left enabled by %q", tt.input)
+		}
+	}
+}
+
+func TestTracePoint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"def add(a, b)\na + b\nend\nevents = []\ntp = TracePoint.new(:call, :return, :line) { |t| events << [t.event, t.method_id, t.lineno] }\ntp.enable\nadd(1, 2)\ntp.disable\nevents",
+			"[[:line, nil, 7], [:call, :add, 1], [:line, :add, 2], [:return, :add, 2], [:line, nil, 8]]",
+		},
+		{
+			"def foo\n5\nend\nvalues = []\nTracePoint.new(:return) { |t| values << t.return_value }.enable { foo }\nvalues",
+			"[5]",
+		},
+		{
+			"messages = []\ntp = TracePoint.new(:raise) { |t| messages << t.raised_exception.message }\ntp.enable do\nbegin\nraise \"boom\"\nrescue\nend\nend\n[messages, tp.enabled?]",
+			"[[boom], false]",
+		},
+		{
+			"tp = TracePoint.new(:line) { |t| }\n[tp.enable, tp.enable, tp.enabled?, tp.disable, tp.enabled?]",
+			"[false, true, true, true, false]",
+		},
+		{
+			"begin\nTracePoint.new(:line) { |t| }.lineno\nrescue => e\ne.message\nend",
+			"access from outside",
+		},
+		{
+			"begin\nTracePoint.new(:foo) { |t| }\nrescue ArgumentError => e\ne.message\nend",
+			"unknown event: foo",
+		},
+		{
+			"def double(n)\nn * 2\nend\nvalues = []\nTracePoint.new(:call) { |t| values << t.binding.local_variable_get(:n) }.enable { double(21) }\nvalues",
+			"[21]",
+		},
+		{
+			"def add(a, b)\na + b\nend\nevents = []\nset_trace_func(proc { |event, file, line, id, binding, classname| events << [event, line, id, classname] })\nadd(1, 2)\nset_trace_func(nil)\nevents",
+			"[[line, 6, nil, nil], [call, 1, :add, Object], [line, 2, :add, Object], [return, 2, :add, Object], [line, 7, nil, nil]]",
+		},
+		{
+			"begin\nset_trace_func(1)\nrescue TypeError => e\ne.message\nend",
+			"trace_func needs to be Proc",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated, err := testEval(tt.input, object.NewMainEnvironment())
+		checkError(t, err)
+		if evaluated.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, evaluated.Inspect())
+			t.Fail()
+		}
+		if object.Tracing() {
+			t.Fatalf("Expected no TracePoint to be left enabled by %q", tt.input)
+		}
 	}
-	return true
 }
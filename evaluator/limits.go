@@ -0,0 +1,121 @@
+package evaluator
+
+import (
+	"context"
+	"time"
+
+	"github.com/goruby/goruby/object"
+)
+
+// limitsKey is the name under which the root environment holds the limits
+// of the running evaluation
+const limitsKey = "resource limits"
+
+// Limits caps the resources an evaluation may use, so that untrusted
+// programs can be run safely. A zero value disables the respective limit.
+type Limits struct {
+	// Operations caps the number of loop iterations and calls of methods
+	// and blocks
+	Operations int
+	// CallDepth caps the number of nested calls of methods and blocks
+	CallDepth int
+	// Objects caps the number of objects created by literals and by
+	// calls of new
+	Objects int
+	// Duration caps the wall time of the evaluation
+	Duration time.Duration
+}
+
+// limitsState tracks the resources used by an evaluation. It is no real
+// Ruby object.
+type limitsState struct {
+	Limits
+	deadline   time.Time
+	operations int
+	objects    int
+	// exceeded is the error of the first limit exceeded. It is returned by
+	// all checks afterwards, so that a program cannot continue by rescuing
+	// it.
+	exceeded error
+}
+
+func (l *limitsState) Type() object.Type       { return object.Type("LIMITS") }
+func (l *limitsState) Inspect() string         { return "limits" }
+func (l *limitsState) Class() object.RubyClass { return nil }
+
+// SetLimits makes evaluations within env stop with a ResourceLimitError
+// once they exceed limits. The wall time is measured from the call of
+// SetLimits, and builtin methods blocking when it is over, like sleep,
+// are stopped as well. It returns a function restoring the previous limits.
+func SetLimits(env object.Environment, limits Limits) (restore func()) {
+	previous, _ := env.Get(limitsKey)
+	state := &limitsState{Limits: limits}
+	stop := func() {}
+	if limits.Duration > 0 {
+		state.deadline = time.Now().Add(limits.Duration)
+		ctx, cancel := context.WithDeadline(context.Background(), state.deadline)
+		undo := object.RuntimeOf(env).StopWhen(ctx.Done(), func() error {
+			return object.NewResourceLimitError("time", limits.Duration)
+		})
+		stop = func() {
+			undo()
+			cancel()
+		}
+	}
+	env.SetGlobal(limitsKey, state)
+	return func() {
+		stop()
+		if previous == nil {
+			previous = &limitsState{}
+		}
+		env.SetGlobal(limitsKey, previous)
+	}
+}
+
+func currentLimits(env object.Environment) *limitsState {
+	state, ok := env.Get(limitsKey)
+	if limits, isLimits := state.(*limitsState); ok && isLimits {
+		return limits
+	}
+	return nil
+}
+
+// checkLimits counts an operation and returns a ResourceLimitError if the
+// evaluation within env exceeded any of its limits
+func checkLimits(env object.Environment) error {
+	state := currentLimits(env)
+	if state == nil {
+		return nil
+	}
+	if state.exceeded != nil {
+		return state.exceeded
+	}
+	state.operations++
+	switch {
+	case state.Operations > 0 && state.operations > state.Operations:
+		state.exceeded = object.NewResourceLimitError("operation", state.Operations)
+	case state.CallDepth > 0 && len(callStack(env).Elements)-1 > state.CallDepth:
+		state.exceeded = object.NewResourceLimitError("call depth", state.CallDepth)
+	case !state.deadline.IsZero() && time.Now().After(state.deadline):
+		state.exceeded = object.NewResourceLimitError("time", state.Duration)
+	}
+	return state.exceeded
+}
+
+// CountObject records the creation of obj by the evaluation within env and
+// returns a ResourceLimitError if it exceeds the object limit
+func CountObject(env object.Environment, obj object.RubyObject) (object.RubyObject, error) {
+	state := currentLimits(env)
+	if state == nil || state.Objects == 0 {
+		return obj, nil
+	}
+	if state.exceeded != nil {
+		return nil, state.exceeded
+	}
+	state.objects++
+	if state.objects > state.Objects {
+		state.exceeded = object.NewResourceLimitError("object", state.Objects)
+		return nil, state.exceeded
+	}
+	return obj, nil
+}
@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goruby/goruby/object"
+)
+
+// loaderKey is the name under which the root environment holds the
+// SourceLoader of require and load
+const loaderKey = "source loader"
+
+// A SourceLoader provides the files loaded by require, require_relative,
+// load and autoload, so that Ruby files may be bundled with the Go binary
+// instead of being read from disk.
+type SourceLoader interface {
+	// Resolve returns the absolute path of the file name within the
+	// directory dir and whether it exists. dir is empty for names to be
+	// resolved against the working directory or being absolute already.
+	Resolve(dir, name string) (string, bool)
+	// ReadSource returns the content of the file at the path returned by
+	// Resolve
+	ReadSource(path string) (string, error)
+}
+
+// OSLoader loads files from the file system of the operating system. It is
+// the loader used if none is set.
+type OSLoader struct{}
+
+// Resolve returns the absolute path of name joined to dir, if it is a file
+func (OSLoader) Resolve(dir, name string) (string, bool) {
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// ReadSource reads the file at path
+func (OSLoader) ReadSource(path string) (string, error) {
+	source, err := ioutil.ReadFile(path)
+	return string(source), err
+}
+
+// FSLoader returns a SourceLoader loading files from fsys, like an
+// embed.FS. The root of fsys is the root directory `/` as well as the
+// working directory, so that `require "lib/util"` and `require "/lib/util"`
+// both load the file lib/util.rb of fsys.
+func FSLoader(fsys fs.FS) SourceLoader {
+	return fsLoader{fsys: fsys}
+}
+
+type fsLoader struct {
+	fsys fs.FS
+}
+
+func (l fsLoader) Resolve(dir, name string) (string, bool) {
+	rooted := rootedPath(dir, name)
+	info, err := fs.Stat(l.fsys, fsPath(rooted))
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return rooted, true
+}
+
+func (l fsLoader) ReadSource(path string) (string, error) {
+	source, err := fs.ReadFile(l.fsys, fsPath(path))
+	return string(source), err
+}
+
+// MapLoader returns a SourceLoader loading files from memory. files maps
+// the paths of the files, which are relative to the root directory `/`
+// like for FSLoader, to their source.
+func MapLoader(files map[string]string) SourceLoader {
+	loader := mapLoader{}
+	for name, source := range files {
+		loader[rootedPath("", name)] = source
+	}
+	return loader
+}
+
+type mapLoader map[string]string
+
+func (l mapLoader) Resolve(dir, name string) (string, bool) {
+	rooted := rootedPath(dir, name)
+	_, ok := l[rooted]
+	return rooted, ok
+}
+
+func (l mapLoader) ReadSource(path string) (string, error) {
+	source, ok := l[path]
+	if !ok {
+		return "", &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return source, nil
+}
+
+// rootedPath returns the slash separated path of name within dir, rooted
+// at `/`
+func rootedPath(dir, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return path.Clean(name)
+	}
+	return path.Join("/", filepath.ToSlash(dir), filepath.ToSlash(name))
+}
+
+// fsPath converts the rooted path into a path valid for fs.FS
+func fsPath(rooted string) string {
+	if rooted == "/" {
+		return "."
+	}
+	return strings.TrimPrefix(rooted, "/")
+}
+
+// MultiLoader returns a SourceLoader resolving files by the first of
+// loaders containing them, like a loader for bundled files followed by an
+// OSLoader
+func MultiLoader(loaders ...SourceLoader) SourceLoader {
+	return multiLoader(loaders)
+}
+
+type multiLoader []SourceLoader
+
+func (l multiLoader) Resolve(dir, name string) (string, bool) {
+	for _, loader := range l {
+		if path, ok := loader.Resolve(dir, name); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func (l multiLoader) ReadSource(path string) (string, error) {
+	for _, loader := range l {
+		if resolved, ok := loader.Resolve("", path); ok && resolved == path {
+			return loader.ReadSource(path)
+		}
+	}
+	return "", &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+}
+
+// sourceLoader holds the SourceLoader of an environment. It is no real
+// Ruby object.
+type sourceLoader struct {
+	SourceLoader
+}
+
+func (l *sourceLoader) Type() object.Type       { return object.Type("LOADER") }
+func (l *sourceLoader) Inspect() string         { return "loader" }
+func (l *sourceLoader) Class() object.RubyClass { return nil }
+
+// SetSourceLoader makes require, require_relative, load and autoload within
+// env load files by loader instead of from the file system
+func SetSourceLoader(env object.Environment, loader SourceLoader) {
+	env.SetGlobal(loaderKey, &sourceLoader{loader})
+}
+
+// currentLoader returns the SourceLoader set for env, or an OSLoader
+func currentLoader(env object.Environment) SourceLoader {
+	loader, ok := env.Get(loaderKey)
+	if source, isLoader := loader.(*sourceLoader); ok && isLoader {
+		return source.SourceLoader
+	}
+	return OSLoader{}
+}
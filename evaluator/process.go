@@ -0,0 +1,54 @@
+package evaluator
+
+import "github.com/goruby/goruby/object"
+
+func init() {
+	evaluatorFunctions["system"] = evalSystem
+	evaluatorFunctions["`"] = evalBacktick
+	evaluatorFunctions["$0"] = evalProgramName
+}
+
+// evalProgramName returns `$0`, an alias of `$PROGRAM_NAME`, the name of
+// the script being run or nil if it is unknown
+func evalProgramName(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	if name, ok := env.Get("$PROGRAM_NAME"); ok {
+		return name, nil
+	}
+	return object.NIL, nil
+}
+
+// evalSystem runs a command like Kernel#system with the streams of env and
+// sets `$?` to its status. The command is killed once the context of the
+// evaluation is done.
+func evalSystem(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	if len(args) == 0 {
+		return nil, object.NewWrongNumberOfArgumentsError(1, 0)
+	}
+	result, status, err := object.RuntimeOf(env).RunSystem(currentContext(env), currentStreams(env), args...)
+	setProcessStatus(env, status)
+	return result, err
+}
+
+// evalBacktick runs the command line of a command substitution in
+// backticks or like `%x(ls)`, and returns its output. It sets `$?` to the
+// status of the command.
+func evalBacktick(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	if len(args) != 1 {
+		return nil, object.NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	commandLine, ok := args[0].(*object.String)
+	if !ok {
+		return nil, object.NewImplicitConversionTypeError(&object.String{}, args[0])
+	}
+	result, status, err := object.RuntimeOf(env).RunCommand(currentContext(env), currentStreams(env), commandLine.Value)
+	setProcessStatus(env, status)
+	return result, err
+}
+
+// setProcessStatus sets `$?` to the status of the last command, if it was
+// run
+func setProcessStatus(env object.Environment, status *object.ProcessStatus) {
+	if status != nil {
+		env.SetGlobal("$?", status)
+	}
+}
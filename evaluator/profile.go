@@ -0,0 +1,24 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/profile"
+)
+
+// recordSample records count samples of the call stack, with the innermost
+// frame at statement, into the profile being recorded
+func recordSample(statement ast.Statement, env object.Environment, count int64) {
+	var stack *object.Array
+	if tok, ok := statementToken(statement); ok {
+		stack = updateLocation(env, tok)
+	} else {
+		stack = callStack(env)
+	}
+	frames := make([]profile.Frame, len(stack.Elements))
+	for i, element := range stack.Elements {
+		location := element.(*object.Location)
+		frames[i] = profile.Frame{Function: location.Label, File: location.Path, Line: location.Lineno}
+	}
+	object.RecordSample(frames, count)
+}
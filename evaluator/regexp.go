@@ -0,0 +1,39 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/goruby/goruby/object"
+)
+
+func init() {
+	evaluatorFunctions["$~"] = evalLastMatch
+	for i := 1; i <= 9; i++ {
+		evaluatorFunctions[fmt.Sprintf("$%d", i)] = lastMatchGroup(i)
+	}
+}
+
+// evalLastMatch returns `$~`, the MatchData of the last match within the
+// current method, or nil
+func evalLastMatch(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+	if match := object.RuntimeOf(env).LastMatch(); match != nil {
+		return match, nil
+	}
+	return object.NIL, nil
+}
+
+// lastMatchGroup returns the function evaluating `$1` to `$9`, the groups
+// of the last match within the current method
+func lastMatchGroup(i int) evaluatorFunction {
+	return func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		match := object.RuntimeOf(env).LastMatch()
+		if match == nil {
+			return object.NIL, nil
+		}
+		group, ok := match.Group(i)
+		if !ok {
+			return object.NIL, nil
+		}
+		return &object.String{Value: group}, nil
+	}
+}
@@ -0,0 +1,226 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/parser"
+)
+
+// evaluatorFunction is a Kernel function which needs access to the
+// environment of its caller and is therefore implemented by the evaluator
+type evaluatorFunction func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error)
+
+var evaluatorFunctions = map[string]evaluatorFunction{}
+
+func init() {
+	evaluatorFunctions["require_relative"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		name, err := filenameArgument(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return requireRelative(name, env)
+	}
+	evaluatorFunctions["load"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		name, err := filenameArgument(args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return loadFile(name, env)
+	}
+}
+
+// filenameArgument returns the filename passed as first of at most max
+// arguments
+func filenameArgument(args []object.RubyObject, max int) (string, error) {
+	args, _ = splitBlock(args)
+	if len(args) < 1 || len(args) > max {
+		if max == 1 {
+			return "", object.NewWrongNumberOfArgumentsError(1, len(args))
+		}
+		return "", object.NewWrongNumberOfArgumentsRangeError(1, max, len(args))
+	}
+	name, ok := args[0].(*object.String)
+	if !ok {
+		return "", object.NewImplicitConversionTypeError(&object.String{}, args[0])
+	}
+	return name.Value, nil
+}
+
+// currentFileKey is the name the path of the file being evaluated is stored
+// under within the environment
+const currentFileKey = "__FILE__"
+
+// requireFeature loads the file name, unless it has been loaded already, and
+// evaluates it within env. The extension .rb is appended to name if it is
+// missing. Loaded files are recorded by their absolute path within
+// $LOADED_FEATURES. Requiring a registered extension returns false.
+func requireFeature(name string, env object.Environment) (object.RubyObject, error) {
+	// extensions are loaded at startup already
+	if ok, err := object.RequireExtension(name); ok {
+		if err != nil {
+			return nil, err
+		}
+		return object.FALSE, nil
+	}
+	filename := name
+	if !strings.HasSuffix(filename, ".rb") {
+		filename += ".rb"
+	}
+	path, ok := resolveFeature(filename, env)
+	if !ok {
+		return nil, object.NewLoadError(name)
+	}
+	features := loadedFeatures(env)
+	for _, feature := range features.Elements {
+		if feature.Inspect() == path {
+			return object.FALSE, nil
+		}
+	}
+	// the feature is recorded before evaluation to stop recursive requires
+	features.Elements = append(features.Elements, &object.String{Value: path})
+	if err := evalFile(path, env); err != nil {
+		for i, feature := range features.Elements {
+			if feature.Inspect() == path {
+				features.Elements = append(features.Elements[:i:i], features.Elements[i+1:]...)
+				break
+			}
+		}
+		return nil, err
+	}
+	return object.TRUE, nil
+}
+
+// requireRelative requires the file name relative to the directory of the
+// file currently evaluated
+func requireRelative(name string, env object.Environment) (object.RubyObject, error) {
+	current, _ := env.Get(currentFileKey)
+	file, ok := current.(*object.String)
+	if !ok {
+		return nil, object.NewLoadError("cannot infer basepath")
+	}
+	return requireFeature(filepath.Join(filepath.Dir(file.Value), name), env)
+}
+
+// loadFile evaluates the file name within env, no matter whether it has
+// been loaded before
+func loadFile(name string, env object.Environment) (object.RubyObject, error) {
+	path, ok := resolveFeature(name, env)
+	if !ok {
+		return nil, object.NewLoadError(name)
+	}
+	if err := evalFile(path, env); err != nil {
+		return nil, err
+	}
+	return object.TRUE, nil
+}
+
+// EvalFile evaluates the content of the file filename within env and
+// returns the result. It returns the error of reading the file unchanged.
+func EvalFile(filename string, env object.Environment) (object.RubyObject, error) {
+	return RunFile(filename, env, func(program *ast.Program, env object.Environment) (object.RubyObject, error) {
+		return Eval(program, env)
+	})
+}
+
+// RunFile parses the file filename and executes it within env by run.
+// While it is executed, __FILE__ refers to its absolute path. The error of
+// reading the file is returned unchanged.
+func RunFile(filename string, env object.Environment, run func(*ast.Program, object.Environment) (object.RubyObject, error)) (object.RubyObject, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return runSource(string(file), path, env, run)
+}
+
+// evalFile evaluates the content of the file path, read by the
+// SourceLoader of env, within env. While it is evaluated, __FILE__ refers
+// to path.
+func evalFile(path string, env object.Environment) error {
+	source, err := currentLoader(env).ReadSource(path)
+	if err != nil {
+		return object.NewLoadError(path)
+	}
+	_, err = evalSource(source, path, env)
+	return err
+}
+
+// evalSource evaluates source, read from the file path, within env
+func evalSource(source, path string, env object.Environment) (object.RubyObject, error) {
+	return runSource(source, path, env, func(program *ast.Program, env object.Environment) (object.RubyObject, error) {
+		return Eval(program, env)
+	})
+}
+
+// runSource executes source, read from the file path, within env by run
+func runSource(source, path string, env object.Environment, run func(*ast.Program, object.Environment) (object.RubyObject, error)) (object.RubyObject, error) {
+	program, err := parser.New(lexer.New(source)).ParseProgram()
+	if err != nil {
+		return nil, object.NewFileSyntaxError(path, err)
+	}
+	if object.CoverageEnabled() {
+		registerCoverage(program, source, path)
+	}
+	previous, ok := env.Get(currentFileKey)
+	if !ok {
+		previous = object.NIL
+	}
+	defer env.Set(currentFileKey, previous)
+	env.Set(currentFileKey, &object.String{Value: path})
+	return run(program, env)
+}
+
+// resolveFeature returns the absolute path of the file name as resolved by
+// the SourceLoader of env. Absolute names and names starting with ./ or ../
+// are used as they are, all other names are searched within the
+// directories of $LOAD_PATH and finally the working directory.
+func resolveFeature(name string, env object.Environment) (string, bool) {
+	dirs := []string{""}
+	if !filepath.IsAbs(name) && !strings.HasPrefix(name, "./") && !strings.HasPrefix(name, "../") {
+		dirs = append(loadPath(env), "")
+	}
+	loader := currentLoader(env)
+	for _, dir := range dirs {
+		if path, ok := loader.Resolve(dir, name); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// loadPath returns the directories listed within $LOAD_PATH
+func loadPath(env object.Environment) []string {
+	var dirs []string
+	paths, ok := env.Get("$LOAD_PATH")
+	if !ok {
+		return dirs
+	}
+	if paths, ok := paths.(*object.Array); ok {
+		for _, path := range paths.Elements {
+			if path, ok := path.(*object.String); ok {
+				dirs = append(dirs, path.Value)
+			}
+		}
+	}
+	return dirs
+}
+
+// loadedFeatures returns $LOADED_FEATURES, creating it if it does not exist
+func loadedFeatures(env object.Environment) *object.Array {
+	features, ok := env.Get("$LOADED_FEATURES")
+	if array, isArray := features.(*object.Array); ok && isArray {
+		return array
+	}
+	array := object.NewArray()
+	env.SetGlobal("$LOADED_FEATURES", array)
+	return array
+}
@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// resolveScope resolves the local variables of the method defined by node
+// to slots, unless this happened already, and returns its scope. The
+// identifiers within blocks and nested definitions are left unresolved and
+// looked up by name.
+func resolveScope(node *ast.FunctionLiteral) *ast.Scope {
+	if node.Scope != nil {
+		return node.Scope
+	}
+	scope := ast.NewScope(currentMethodKey)
+	for _, param := range node.Parameters {
+		scope.Add(param.Value)
+	}
+	var identifiers []*ast.Identifier
+	ast.Inspect(node.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral, *ast.ClassExpression, *ast.ModuleExpression, *ast.BlockLiteral:
+			return false
+		case *ast.VariableAssignment:
+			if object.IsLocalVariableName(node.Name.Value) {
+				scope.Add(node.Name.Value)
+			}
+		case *ast.RescueBlock:
+			if node.Exception != nil {
+				scope.Add(node.Exception.Value)
+			}
+		case *ast.Identifier:
+			identifiers = append(identifiers, node)
+		}
+		return true
+	})
+	for _, identifier := range identifiers {
+		if slot, ok := scope.Index(identifier.Value); ok {
+			identifier.Scope, identifier.Slot = scope, slot
+		}
+	}
+	node.Scope = scope
+	return scope
+}
+
+// declareLocals marks the identifiers within node which refer to a local
+// variable assigned before, see ast.Identifier.Declared
+func declareLocals(node ast.Node) {
+	ast.Walk(localDeclarations{}, node)
+}
+
+// localDeclarations holds the names of the local variables assigned so far
+// within a scope
+type localDeclarations map[string]bool
+
+func (d localDeclarations) Visit(node ast.Node) ast.Visitor {
+	switch node := node.(type) {
+	case *ast.FunctionLiteral, *ast.ClassExpression, *ast.ModuleExpression:
+		return localDeclarations{}
+	case *ast.BlockLiteral:
+		// blocks see the variables of their scope, but the ones they
+		// declare are local to them
+		inner := make(localDeclarations, len(d))
+		for name := range d {
+			inner[name] = true
+		}
+		return inner
+	case *ast.VariableAssignment:
+		if object.IsLocalVariableName(node.Name.Value) {
+			d[node.Name.Value] = true
+		}
+	case *ast.RescueBlock:
+		if node.Exception != nil {
+			d[node.Exception.Value] = true
+		}
+	case *ast.Identifier:
+		node.Declared = d[node.Value]
+	}
+	return d
+}
+
+// getVariable returns the value of the variable named by node, read from
+// its slot if node has been resolved to one of env
+func getVariable(node *ast.Identifier, env object.Environment) (object.RubyObject, bool) {
+	if node.Scope != nil {
+		if slots, ok := env.(object.SlotEnvironment); ok && slots.Scope() == node.Scope {
+			if val := slots.Slot(node.Slot); val != nil {
+				return val, true
+			}
+		}
+	}
+	return env.Get(node.Value)
+}
+
+// setVariable sets the local variable named by node to val, within its
+// slot if node has been resolved to one of env
+func setVariable(node *ast.Identifier, env object.Environment, val object.RubyObject) {
+	if node.Scope != nil {
+		if slots, ok := env.(object.SlotEnvironment); ok && slots.Scope() == node.Scope {
+			slots.SetSlot(node.Slot, val)
+			return
+		}
+	}
+	env.Set(node.Value, val)
+}
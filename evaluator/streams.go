@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// streamsKey is the name under which the root environment holds the
+// standard streams of the runtime
+const streamsKey = "standard streams"
+
+func init() {
+	evaluatorFunctions["$_"] = func(env object.Environment, args ...object.RubyObject) (object.RubyObject, error) {
+		return object.RuntimeOf(env).LastLine(), nil
+	}
+}
+
+// runtimeStreams holds the standard streams of a runtime. It is no real
+// Ruby object.
+type runtimeStreams struct {
+	*object.Streams
+}
+
+func (s *runtimeStreams) Type() object.Type       { return object.Type("STREAMS") }
+func (s *runtimeStreams) Inspect() string         { return "streams" }
+func (s *runtimeStreams) Class() object.RubyClass { return nil }
+
+// SetStreams makes the Kernel functions like puts and gets called within
+// env use streams instead of the process wide object.Stdout and friends, as
+// well as the warnings emitted by the evaluator
+func SetStreams(env object.Environment, streams *object.Streams) {
+	env.SetGlobal(streamsKey, &runtimeStreams{streams})
+}
+
+// currentStreams returns the streams set for env, or the process wide ones
+func currentStreams(env object.Environment) *object.Streams {
+	if streams, ok := runtimeStreamsOf(env); ok {
+		return streams
+	}
+	return object.DefaultStreams()
+}
+
+// runtimeStreamsOf returns the streams set for env, if any
+func runtimeStreamsOf(env object.Environment) (*object.Streams, bool) {
+	streams, ok := env.Get(streamsKey)
+	if runtime, isStreams := streams.(*runtimeStreams); ok && isStreams {
+		return runtime.Streams, true
+	}
+	return nil, false
+}
+
+// isSelfCall reports whether node calls a method without receiver or on
+// self explicitly
+func isSelfCall(node *ast.ContextCallExpression) bool {
+	if node.Context == nil {
+		return true
+	}
+	_, ok := node.Context.(*ast.Self)
+	return ok
+}
+
+// callWithStreams calls the Kernel function name using the standard
+// streams with the streams set for env, if any. It reports false if there
+// are none or name is no such function.
+func callWithStreams(env object.Environment, self object.RubyObject, name string, args ...object.RubyObject) (object.RubyObject, bool, error) {
+	streams, ok := runtimeStreamsOf(env)
+	if !ok {
+		return nil, false, nil
+	}
+	return object.RuntimeOf(env).CallWithStreams(streams, self, name, args...)
+}
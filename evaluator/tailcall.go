@@ -0,0 +1,175 @@
+package evaluator
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// tailCallsKey is the name under which the root environment records
+// whether tail call optimization is enabled. It is no valid Ruby identifier
+// and thus not accessible from within Ruby code.
+const tailCallsKey = "tail calls"
+
+// SetTailCallOptimization enables or disables tail call optimization for
+// the methods evaluated within env. If enabled, calls of methods defined in Ruby which are the last
+// expression evaluated by a method then replace the frame of the calling
+// method instead of nesting within it, so that recursion in tail position
+// runs in constant stack space. The replaced frames are missing from
+// backtraces.
+func SetTailCallOptimization(env object.Environment, enabled bool) {
+	env.SetGlobal(tailCallsKey, nativeBoolToBooleanObject(enabled))
+}
+
+func tailCallsEnabled(env object.Environment) bool {
+	enabled, ok := env.Get(tailCallsKey)
+	return ok && enabled == object.TRUE
+}
+
+// tailCall is the result of a method body ending in a call of a method
+// defined in Ruby. It is no real Ruby object and only handed to
+// applyFunction, which carries out the call in place of the returning
+// method.
+type tailCall struct {
+	function *object.Function
+	args     []object.RubyObject
+	name     string
+	token    token.Token
+	env      object.Environment
+}
+
+func (t *tailCall) Type() object.Type       { return object.Type("TAIL_CALL") }
+func (t *tailCall) Inspect() string         { return "tail call of " + t.name }
+func (t *tailCall) Class() object.RubyClass { return nil }
+
+// replaceFrame replaces the innermost frame by the one of the called
+// method, if it is the frame of the method caller making the call
+func (t *tailCall) replaceFrame(caller string) {
+	stack := callStack(t.env)
+	innermost := len(stack.Elements) - 1
+	if stack.Elements[innermost].(*object.Location).Label != caller {
+		return
+	}
+	stack.Elements[innermost] = object.NewLocation(currentFile(t.env), t.token.Line, t.name)
+}
+
+// evalTailBody evaluates the statements of body like evalBlockStatement,
+// with the last statement in tail position
+func evalTailBody(body *ast.BlockStatement, env object.Environment) (object.RubyObject, error) {
+	if len(body.Statements) == 0 {
+		return object.NIL, nil
+	}
+	last := len(body.Statements) - 1
+	for _, statement := range body.Statements[:last] {
+		result, err := evalStatement(statement, env)
+		if err != nil {
+			return nil, recordBacktrace(env, statement, err)
+		}
+		if result != nil && result.Type() == object.RETURN_VALUE_OBJ {
+			return result, nil
+		}
+	}
+	result, err := evalTailStatement(body.Statements[last], env)
+	if err != nil {
+		return nil, recordBacktrace(env, body.Statements[last], err)
+	}
+	return result, nil
+}
+
+func evalTailStatement(statement ast.Statement, env object.Environment) (object.RubyObject, error) {
+	if err := object.RuntimeOf(env).CheckInterrupt(); err != nil {
+		return nil, err
+	}
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		return evalTailExpression(statement.Expression, env)
+	case *ast.ReturnStatement:
+		if statement.ReturnValue == nil {
+			return object.NewReturnValue(object.NIL), nil
+		}
+		result, err := evalTailExpression(statement.ReturnValue, env)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := result.(*tailCall); ok {
+			return result, nil
+		}
+		return object.NewReturnValue(result), nil
+	default:
+		return Eval(statement, env)
+	}
+}
+
+// evalTailExpression evaluates node in tail position. Calls are returned
+// as tailCall if possible, the branches of conditionals are evaluated in
+// tail position as well.
+func evalTailExpression(node ast.Expression, env object.Environment) (object.RubyObject, error) {
+	switch node := node.(type) {
+	case *ast.IfExpression:
+		condition, err := Eval(node.Condition, env)
+		if err != nil {
+			return nil, err
+		}
+		if isTruthy(condition) {
+			return evalTailBody(node.Consequence, env)
+		}
+		if node.Alternative != nil {
+			return evalTailBody(node.Alternative, env)
+		}
+		return object.NIL, nil
+	case *ast.ContextCallExpression:
+		if node.Block == nil {
+			return evalTailCall(node, env)
+		}
+	}
+	return Eval(node, env)
+}
+
+func evalTailCall(node *ast.ContextCallExpression, env object.Environment) (object.RubyObject, error) {
+	context, err := Eval(node.Context, env)
+	if err != nil {
+		return nil, err
+	}
+	if context == nil {
+		context, _ = env.Get("self")
+	}
+	args, err := evalExpressions(node.Arguments, env)
+	if err != nil {
+		return nil, err
+	}
+	function, ok := tailCallTarget(node, context, env)
+	if !ok {
+		return Call(node, context, args, env)
+	}
+	updateLocation(env, node.Token)
+	return &tailCall{
+		function: function,
+		args:     args,
+		name:     node.Function.Value,
+		token:    node.Token,
+		env:      env,
+	}, nil
+}
+
+// tailCallTarget returns the method Call would call for node, bound to
+// context, if it is defined in Ruby
+func tailCallTarget(node *ast.ContextCallExpression, context object.RubyObject, env object.Environment) (*object.Function, bool) {
+	name := node.Function.Value
+	if _, ok := evaluatorFunctions[name]; ok && node.Context == nil {
+		return nil, false
+	}
+	if function, ok := env.Get(name); ok && isFunction(function) {
+		fn, ok := function.(*object.Function)
+		return fn, ok
+	}
+	self, _ := env.Get("self")
+	method, ok := object.RuntimeOf(env).MethodFrom(self, context, name)
+	if !ok {
+		return nil, false
+	}
+	fn, ok := method.(*object.Function)
+	if !ok {
+		return nil, false
+	}
+	return fn.Bind(context), true
+}
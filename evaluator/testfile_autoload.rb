@@ -0,0 +1 @@
+Autoloaded = 42
@@ -0,0 +1 @@
+loaded = loaded + 1
@@ -0,0 +1 @@
+relative_value = 42
@@ -0,0 +1 @@
+require_relative "helper"
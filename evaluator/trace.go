@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// traceLine reports the line event of statement, which is about to be
+// evaluated, to the enabled TracePoints. The innermost frame is moved to
+// the line, so that later events of the method report it as well.
+func traceLine(statement ast.Statement, env object.Environment) error {
+	tok, ok := statementToken(statement)
+	if !ok || tok.Line == 0 {
+		return nil
+	}
+	updateLocation(env, tok)
+	self, _ := env.Get("self")
+	return object.RuntimeOf(env).Trace(object.TraceEvent{
+		Event:    "line",
+		MethodID: frameMethod(env),
+		Path:     currentFile(env),
+		Lineno:   tok.Line,
+		Self:     self,
+		Binding:  traceBinding(env),
+	})
+}
+
+// traceMethod reports the call or return event of fn, whose body is
+// evaluated within env, to the enabled TracePoints
+func traceMethod(event string, fn *object.Function, line int, returnValue object.RubyObject, env object.Environment) error {
+	self, _ := env.Get("self")
+	return object.RuntimeOf(env).Trace(object.TraceEvent{
+		Event:       event,
+		MethodID:    fn.Name,
+		Path:        currentFile(env),
+		Lineno:      line,
+		Self:        self,
+		ReturnValue: returnValue,
+		Binding:     traceBinding(env),
+	})
+}
+
+// traceRaise reports the raise event of err to the enabled TracePoints,
+// unless err is no exception
+func traceRaise(env object.Environment, err error) error {
+	exception, ok := err.(object.RubyObject)
+	if !ok {
+		return nil
+	}
+	self, _ := env.Get("self")
+	return object.RuntimeOf(env).Trace(object.TraceEvent{
+		Event:           "raise",
+		MethodID:        frameMethod(env),
+		Path:            currentFile(env),
+		Lineno:          currentLine(env),
+		Self:            self,
+		RaisedException: exception,
+		Binding:         traceBinding(env),
+	})
+}
+
+// traceBinding returns a Binding of env for the events reported
+func traceBinding(env object.Environment) *object.Binding {
+	binding := object.NewBinding(env)
+	binding.EvalFn = evalSourceIn
+	return binding
+}
+
+// statementToken returns the token starting statement
+func statementToken(statement ast.Statement) (token.Token, bool) {
+	switch statement := statement.(type) {
+	case *ast.ExpressionStatement:
+		return statement.Token, true
+	case *ast.ReturnStatement:
+		return statement.Token, true
+	case *ast.BreakStatement:
+		return statement.Token, true
+	case *ast.NextStatement:
+		return statement.Token, true
+	}
+	return token.Token{}, false
+}
+
+// currentLine returns the line of the innermost frame
+func currentLine(env object.Environment) int {
+	stack := callStack(env)
+	return stack.Elements[len(stack.Elements)-1].(*object.Location).Lineno
+}
+
+// frameMethod returns the name of the method of the innermost frame, which
+// is the one a block is defined in for the frames of blocks. It returns an
+// empty string outside of methods.
+func frameMethod(env object.Environment) string {
+	stack := callStack(env)
+	label := stack.Elements[len(stack.Elements)-1].(*object.Location).Label
+	if strings.HasPrefix(label, "block ") {
+		label = label[strings.Index(label, " in ")+4:]
+	}
+	if strings.HasPrefix(label, "<") {
+		return ""
+	}
+	return label
+}
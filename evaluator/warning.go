@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+)
+
+// warnAt emits an interpreter warning for the given line of the current
+// file. Those warnings are only emitted if $VERBOSE is true.
+func warnAt(env object.Environment, line int, format string, args ...interface{}) error {
+	if !object.IsVerbose() {
+		return nil
+	}
+	message := fmt.Sprintf(format, args...)
+	return object.RuntimeOf(env).WarnTo(currentStreams(env), fmt.Sprintf("%s:%d: warning: %s", currentFile(env), line, message))
+}
+
+// warnMethodDefinition warns about method redefinitions and about local
+// variables within the method body which are assigned but never read
+func warnMethodDefinition(node *ast.FunctionLiteral, context object.RubyObject, env object.Environment) error {
+	if !object.IsVerbose() {
+		return nil
+	}
+	name := node.Name.Value
+	if object.RuntimeOf(env).IsMethodDefinedBy(context, name) {
+		err := warnAt(env, node.Token.Line, "method redefined; discarding old %s", name)
+		if err != nil {
+			return err
+		}
+	}
+	for _, variable := range unusedVariables(node) {
+		err := warnAt(env, variable.Token.Line, "assigned but unused variable - %s", variable.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unusedVariables returns the first assignment of every local variable of
+// the method fn which is never read, in the order of their assignment.
+// Variables prefixed with an underscore are never reported. Nested method,
+// class and module definitions have scopes of their own and are skipped.
+func unusedVariables(fn *ast.FunctionLiteral) []*ast.Identifier {
+	var assigned []*ast.Identifier
+	seen := make(map[string]bool)
+	read := make(map[string]bool)
+	var visit func(ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral, *ast.ClassExpression, *ast.ModuleExpression:
+			return false
+		case *ast.VariableAssignment:
+			name := node.Name.Value
+			if !seen[name] && object.IsLocalVariableName(name) {
+				seen[name] = true
+				assigned = append(assigned, node.Name)
+			}
+			ast.Inspect(node.Value, visit)
+			return false
+		case *ast.Identifier:
+			read[node.Value] = true
+		}
+		return true
+	}
+	ast.Inspect(fn.Body, visit)
+	var unused []*ast.Identifier
+	for _, variable := range assigned {
+		if !read[variable.Value] && !strings.HasPrefix(variable.Value, "_") {
+			unused = append(unused, variable)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,396 @@
+// Package format formats Ruby programs in a canonical style: two spaces of
+// indentation per nesting level, single spaces around binary operators and
+// after commas, no spaces within parentheses and brackets, and at most one
+// blank line in a row. Comments are kept where they are.
+//
+// The formatter works on the tokens of a program which parses, so that it
+// neither changes nor loses anything but whitespace. The content following
+// `__END__` is kept as it is.
+package format
+
+import (
+	"strings"
+
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/parser"
+	"github.com/goruby/goruby/token"
+)
+
+// indent is the indentation of a nesting level
+const indent = "  "
+
+// Source formats the Ruby program src. If src does not parse it returns the
+// error of the parser, which is a *parser.Errors listing the syntax errors.
+func Source(src []byte) ([]byte, error) {
+	source := string(src)
+	if _, err := parser.New(lexer.New(source)).ParseProgram(); err != nil {
+		return nil, err
+	}
+	l := lexer.New(source)
+	var tokens []token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		tokens = append(tokens, tok)
+	}
+	code, data := source, ""
+	if content, ok := l.Data(); ok {
+		marker := strings.LastIndex(source[:len(source)-len(content)], "__END__")
+		code, data = source[:marker], "__END__\n"+content
+	}
+	f := &formatter{}
+	for _, item := range items(code, tokens, l.Offsets(), l.Comments()) {
+		f.add(item)
+	}
+	out := strings.TrimLeft(f.finish(), "\n")
+	return []byte(out + data), nil
+}
+
+// An item is a token or a comment along with its text within the source
+type item struct {
+	tok     token.Token
+	comment bool
+	text    string
+	// gap is the source between the previous item and this one
+	gap string
+	// spacedAfter is set if whitespace follows the item
+	spacedAfter bool
+	// continued is set for the last token of a line ending with a
+	// backslash
+	continued bool
+}
+
+// items returns the tokens and comments of src in the order of their
+// position. The text of a token reaches up to the whitespace before the
+// next item, which includes the delimiters the literal of the token omits.
+// Operators the lexer emits as several tokens, like += or &&, are merged
+// into a single item.
+func items(src string, tokens []token.Token, offsets []int, comments []lexer.Comment) []*item {
+	type positioned struct {
+		pos  int
+		item *item
+	}
+	var all []positioned
+	c := 0
+	for i, tok := range tokens {
+		for c < len(comments) && comments[c].Pos < offsets[i] {
+			all = append(all, positioned{comments[c].Pos, &item{comment: true, text: comments[c].Text}})
+			c++
+		}
+		all = append(all, positioned{offsets[i], &item{tok: tok}})
+	}
+	for ; c < len(comments); c++ {
+		all = append(all, positioned{comments[c].Pos, &item{comment: true, text: comments[c].Text}})
+	}
+	var result []*item
+	end := 0
+	for i, p := range all {
+		next := len(src)
+		if i+1 < len(all) {
+			next = all[i+1].pos
+		}
+		p.item.gap = src[end:p.pos]
+		if !p.item.comment {
+			text := strings.TrimRight(src[p.pos:next], " \t\r\n")
+			if strings.HasSuffix(text, "\\") && strings.Contains(src[p.pos+len(text):next], "\n") {
+				text = strings.TrimRight(text[:len(text)-1], " \t")
+				p.item.continued = true
+			}
+			if p.item.tok.Type == token.NEWLINE {
+				text = ""
+			}
+			p.item.text = text
+		}
+		end = p.pos + len(p.item.text)
+		p.item.spacedAfter = end < next
+		if n := len(result); n > 0 && p.item.gap == "" && !p.item.comment && !result[n-1].comment &&
+			compoundOperators[result[n-1].text+p.item.text] {
+			result[n-1].text += p.item.text
+			result[n-1].spacedAfter = p.item.spacedAfter
+			result[n-1].continued = p.item.continued
+			continue
+		}
+		result = append(result, p.item)
+	}
+	return result
+}
+
+// compoundOperators are the operators the lexer emits as several tokens
+var compoundOperators = map[string]bool{
+	"+=": true, "-=": true, "*=": true, "/=": true, "%=": true, "**=": true,
+	"||": true, "&&": true, "||=": true, "&&=": true, "|=": true, "&=": true,
+	"^=": true, "<<=": true, ">>=": true, "<=": true, ">=": true, "===": true,
+}
+
+// formatter writes the items of a program line by line
+type formatter struct {
+	out strings.Builder
+	// line holds the current line, which is written with the indentation
+	// of lineDepth once it is complete
+	line      strings.Builder
+	lineDepth int
+	// depth is the nesting depth following the items added so far
+	depth int
+	// statementDepth is the depth at the start of the current statement,
+	// the lines continuing it are indented by one more level
+	statementDepth int
+	// last is the last token of the current statement
+	last *item
+	// lastBinary is set if last is a binary operator
+	lastBinary bool
+	// newStatement is set at the start of a statement
+	newStatement bool
+	// loopCondition is set within the condition of while and until, whose
+	// optional do opens no block
+	loopCondition bool
+	// defName is set within the name of a method being defined, whose
+	// spacing is kept as it is
+	defName bool
+	// newlines counts the line breaks since the last item written
+	newlines int
+	// blank is set if a blank line is to be written before the next line
+	blank bool
+}
+
+func (f *formatter) add(item *item) {
+	f.newlines += strings.Count(item.gap, "\n")
+	if item.tok.Type == token.NEWLINE && !item.comment {
+		if f.last != nil && !continuesLine(f.last) {
+			f.endStatement()
+			f.last = nil
+		}
+		return
+	}
+	if f.newlines > 0 {
+		f.endLine()
+		if f.newlines > 1 && f.out.Len() > 0 {
+			f.blank = true
+		}
+		f.newlines = 0
+	}
+	if item.comment {
+		f.addComment(item)
+		return
+	}
+	binary := f.isBinary(item)
+	if f.line.Len() == 0 {
+		f.startLine(item)
+	} else if f.spaceBefore(item, binary) {
+		f.line.WriteString(" ")
+	}
+	f.line.WriteString(item.text)
+	if item.continued {
+		f.line.WriteString(" \\")
+	}
+	f.nest(item)
+	f.last, f.lastBinary = item, binary
+	f.newStatement = false
+	if item.tok.Type == token.SEMICOLON {
+		f.endStatement()
+	}
+}
+
+func (f *formatter) endStatement() {
+	f.newStatement, f.loopCondition, f.defName = true, false, false
+}
+
+// addComment adds a comment at the end of the current line or on a line of
+// its own. Embedded documents are written without indentation.
+func (f *formatter) addComment(item *item) {
+	text := strings.TrimRight(item.text, " \t\r")
+	if f.line.Len() > 0 {
+		f.line.WriteString(" " + text)
+		return
+	}
+	f.flushBlank()
+	if strings.HasPrefix(text, "=begin") {
+		f.out.WriteString(text + "\n")
+		return
+	}
+	f.out.WriteString(strings.Repeat(indent, f.depth) + text + "\n")
+}
+
+// startLine determines the indentation of the line starting with item
+func (f *formatter) startLine(item *item) {
+	f.lineDepth = f.depth
+	if f.newStatement || f.last == nil {
+		f.statementDepth = f.depth
+	}
+	switch {
+	case closesLine(item):
+		if f.lineDepth > 0 {
+			f.lineDepth--
+		}
+	case f.last != nil && !f.newStatement && f.depth <= f.statementDepth:
+		// the line continues the statement of the previous line
+		f.lineDepth++
+	}
+}
+
+func (f *formatter) endLine() {
+	if f.line.Len() == 0 {
+		return
+	}
+	f.flushBlank()
+	f.out.WriteString(strings.Repeat(indent, f.lineDepth) + f.line.String() + "\n")
+	f.line.Reset()
+}
+
+func (f *formatter) flushBlank() {
+	if f.blank {
+		f.out.WriteString("\n")
+		f.blank = false
+	}
+}
+
+func (f *formatter) finish() string {
+	f.endLine()
+	return f.out.String()
+}
+
+// nest updates the nesting depth by the token of item
+func (f *formatter) nest(item *item) {
+	if f.defName && (item.tok.Type == token.LPAREN || item.gap != "") {
+		f.defName = false
+	}
+	typ := keyword(item.tok)
+	switch typ {
+	case token.DEF:
+		f.depth++
+		f.defName = true
+	case token.CLASS, token.MODULE, token.CASE, token.BEGIN,
+		token.LBRACE, token.LBRACKET, token.LPAREN:
+		f.depth++
+	case token.DO:
+		if !f.loopCondition {
+			f.depth++
+		}
+		f.loopCondition = false
+	case token.IF, token.WHILE:
+		if f.startsExpression() {
+			f.depth++
+			f.loopCondition = typ == token.WHILE
+		}
+	case token.END, token.RBRACE, token.RBRACKET, token.RPAREN:
+		if f.depth > 0 {
+			f.depth--
+		}
+	}
+}
+
+// startsExpression reports whether a token following the last one starts
+// an expression, so that an if following it is no modifier like in
+// `x if y`
+func (f *formatter) startsExpression() bool {
+	if f.newStatement || f.last == nil {
+		return true
+	}
+	switch f.last.tok.Type {
+	case token.ASSIGN, token.LPAREN, token.LBRACKET, token.COMMA, token.THEN,
+		token.ELSE, token.DO, token.BEGIN, token.RETURN, token.HASHROCKET:
+		return true
+	}
+	return false
+}
+
+// keyword returns the type of tok, treating the identifiers unless and until
+// like if and while
+func keyword(tok token.Token) token.Type {
+	if tok.Type == token.IDENT {
+		switch tok.Literal {
+		case "unless":
+			return token.IF
+		case "until":
+			return token.WHILE
+		}
+	}
+	return tok.Type
+}
+
+// closesLine reports whether a line starting with item is indented like the
+// line opening the block it continues or closes, like else and end
+func closesLine(item *item) bool {
+	switch item.tok.Type {
+	case token.END, token.RBRACE, token.RBRACKET, token.RPAREN,
+		token.ELSE, token.WHEN, token.RESCUE, token.ENSURE:
+		return true
+	case token.IDENT:
+		return item.tok.Literal == "elsif"
+	}
+	return false
+}
+
+// continuesLine reports whether a line ending with item is continued by the
+// next line, like after an operator or a comma
+func continuesLine(item *item) bool {
+	switch item.tok.Type {
+	case token.COMMA, token.DOT, token.LPAREN, token.LBRACKET:
+		return true
+	case token.PIPE:
+		// block parameters end with a pipe as well
+		return item.text != "|"
+	}
+	return binaryOperators[item.text] || ambiguousOperators[item.text]
+}
+
+// operandTypes are the tokens which may end an operand of a binary operator
+var operandTypes = map[token.Type]bool{
+	token.IDENT: true, token.INT: true, token.FLOAT: true, token.STRING: true,
+	token.CHAR: true, token.SYMBOL: true, token.REGEX: true, token.XSTRING: true,
+	token.RPAREN: true, token.RBRACKET: true, token.RBRACE: true,
+	token.SELF: true, token.NIL: true, token.TRUE: true, token.FALSE: true,
+	token.END: true,
+}
+
+// binaryOperators are the operators which cannot be unary, so that they
+// are always surrounded by spaces
+var binaryOperators = map[string]bool{
+	"=": true, "==": true, "!=": true, "===": true, "<": true, ">": true,
+	"<=": true, ">=": true, "<=>": true, "=~": true, "!~": true, "=>": true,
+	"&&": true, "||": true, "/": true, "%": true, "^": true, ">>": true,
+	"+=": true, "-=": true, "*=": true, "/=": true, "%=": true, "**=": true,
+	"||=": true, "&&=": true, "|=": true, "&=": true, "^=": true, "<<=": true,
+	">>=": true,
+}
+
+// ambiguousOperators are the binary operators which may also be unary or
+// mark an argument, like a splat, so that they are only surrounded by
+// spaces if they have the same spacing on both sides in the source
+var ambiguousOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "**": true, "&": true, "<<": true,
+}
+
+// isBinary reports whether item is a binary operator to be surrounded by
+// spaces
+func (f *formatter) isBinary(item *item) bool {
+	if item.comment || f.last == nil || !operandTypes[f.last.tok.Type] {
+		return false
+	}
+	if binaryOperators[item.text] {
+		return true
+	}
+	spacedBefore := item.gap != ""
+	return ambiguousOperators[item.text] && spacedBefore == item.spacedAfter
+}
+
+// spaceBefore reports whether a space separates item from the token before
+// it on the same line. binary is set if item is a binary operator.
+func (f *formatter) spaceBefore(item *item, binary bool) bool {
+	spaced := item.gap != ""
+	prev := f.last
+	if prev == nil || f.defName {
+		return spaced
+	}
+	switch item.tok.Type {
+	case token.COMMA, token.SEMICOLON, token.RPAREN, token.RBRACKET, token.DOT, token.COLON:
+		return false
+	}
+	switch prev.tok.Type {
+	case token.LPAREN, token.LBRACKET, token.DOT:
+		return false
+	case token.COMMA, token.SEMICOLON, token.COLON:
+		return true
+	}
+	if binary || f.lastBinary {
+		return true
+	}
+	return spaced
+}
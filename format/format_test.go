@@ -0,0 +1,144 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/parser"
+	"github.com/goruby/goruby/token"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"indentation",
+			"module Foo\nclass Bar\n    def baz\n  if x\nputs 1\n      else\n puts 2\nend\n end\nend\nend\n",
+			"module Foo\n  class Bar\n    def baz\n      if x\n        puts 1\n      else\n        puts 2\n      end\n    end\n  end\nend\n",
+		},
+		{
+			"operators",
+			"x=a+b*2\ny = x==1\nz = -x\nfoo *args\nclass A<B\nend\n",
+			"x = a + b * 2\ny = x == 1\nz = -x\nfoo *args\nclass A < B\nend\n",
+		},
+		{
+			"commas and brackets",
+			"foo( 1,2 )\nx = [ 1,2 ]\ny = {a: 1, :b=>2}\n",
+			"foo(1, 2)\nx = [1, 2]\ny = {a: 1, :b => 2}\n",
+		},
+		{
+			"blocks",
+			"[1].each do |x|\nputs x\nend\n[1].map { |x| x*2 }\n",
+			"[1].each do |x|\n  puts x\nend\n[1].map { |x| x * 2 }\n",
+		},
+		{
+			"while loops",
+			"while x < 3 do\nx = x + 1\nend\n",
+			"while x < 3 do\n  x = x + 1\nend\n",
+		},
+		{
+			"case",
+			"case x\n  when 1\n    puts 1\n  else\n    puts 2\nend\n",
+			"case x\nwhen 1\n  puts 1\nelse\n  puts 2\nend\n",
+		},
+		{
+			"comments",
+			"# leading\ndef foo  # trailing  \n      # own line\n  1\nend\n",
+			"# leading\ndef foo # trailing\n  # own line\n  1\nend\n",
+		},
+		{
+			"embedded documents",
+			"def foo\n=begin\n  doc\n=end\n1\nend\n",
+			"def foo\n=begin\n  doc\n=end\n  1\nend\n",
+		},
+		{
+			"blank lines",
+			"\n\nx = 1\n\n\n\ny = 2\n",
+			"x = 1\n\ny = 2\n",
+		},
+		{
+			"semicolons",
+			"x = 1;y = 2 ;z = 3\n",
+			"x = 1; y = 2; z = 3\n",
+		},
+		{
+			"literals are kept",
+			"x = \"a  ,b\"\ny = :sym\nz = /a +b/\nw = `ls  -l`\n",
+			"x = \"a  ,b\"\ny = :sym\nz = /a +b/\nw = `ls  -l`\n",
+		},
+		{
+			"line continuation",
+			"x = 1 + \\\n2\n",
+			"x = 1 + \\\n  2\n",
+		},
+		{
+			"data",
+			"x = 1\n__END__\n  data  \n",
+			"x = 1\n__END__\n  data  \n",
+		},
+		{
+			"trailing newline",
+			"x = 1",
+			"x = 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("Expected output\n%q\ngot\n%q", tt.expected, output)
+			}
+			again, err := Source(output)
+			if err != nil {
+				t.Fatalf("Expected no error formatting the output, got %v", err)
+			}
+			if string(again) != string(output) {
+				t.Errorf("Expected formatting to be idempotent, got\n%q", again)
+			}
+		})
+	}
+}
+
+func TestSourceSyntaxError(t *testing.T) {
+	_, err := Source([]byte("x = (\n"))
+	if _, ok := err.(*parser.Errors); !ok {
+		t.Errorf("Expected *parser.Errors, got %T", err)
+	}
+}
+
+func TestFormatterOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x||=1\n", "x ||= 1\n"},
+		{"y = a<=b&&c\n", "y = a <= b && c\n"},
+		{"puts x if x\n", "puts x if x\n"},
+		{"z = a -b\n", "z = a -b\n"},
+		{"unless x\nuntil y\nx\nend\nend\n", "unless x\n  until y\n    x\n  end\nend\n"},
+	}
+
+	for _, tt := range tests {
+		// the parser does not support all of these, so the formatter is
+		// fed the tokens directly
+		l := lexer.New(tt.input)
+		var tokens []token.Token
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			tokens = append(tokens, tok)
+		}
+		f := &formatter{}
+		for _, item := range items(tt.input, tokens, l.Offsets(), l.Comments()) {
+			f.add(item)
+		}
+		if output := f.finish(); output != tt.expected {
+			t.Errorf("Expected %q to format as %q, got %q", tt.input, tt.expected, output)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package goruby
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/parser"
+)
+
+// An Error is an exception raised by an evaluated program, or a
+// SyntaxError if the program could not be parsed
+type Error struct {
+	// Class is the name of the exception class
+	Class string
+	// Message is the message of the exception
+	Message string
+	// Backtrace lists the locations of the calls active when the exception
+	// was raised, innermost first
+	Backtrace []string
+	err       error
+}
+
+// Error returns the message followed by the class name
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Class)
+}
+
+// Unwrap returns the underlying error, e.g. the error of the context
+// interrupting EvalContext
+func (e *Error) Unwrap() error {
+	if unwrapper, ok := e.err.(interface{ Unwrap() error }); ok {
+		return unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// ExitError reports that the program terminated by Kernel#exit
+type ExitError struct {
+	// Status is the status passed to exit
+	Status int
+}
+
+// Error returns the exit status
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Status)
+}
+
+func result(obj object.RubyObject, err error) (Value, error) {
+	if err != nil {
+		return Value{}, wrapError(err)
+	}
+	return Value{obj}, nil
+}
+
+func wrapError(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *object.SystemExit:
+		return &ExitError{Status: e.Status}
+	case *parser.Errors:
+		return &Error{Class: "SyntaxError", Message: strings.TrimSpace(e.Error()), err: err}
+	case object.RubyObject:
+		backtrace, _ := object.Backtrace(err)
+		return &Error{
+			Class:     e.Class().(object.RubyObject).Inspect(),
+			Message:   err.Error(),
+			Backtrace: backtrace,
+			err:       err,
+		}
+	default:
+		return err
+	}
+}
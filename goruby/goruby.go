@@ -0,0 +1,283 @@
+// Package goruby embeds the goruby interpreter into Go applications.
+//
+// An Interpreter evaluates Ruby source and returns its results as Values,
+// which convert to Go types, and raised exceptions as *Error:
+//
+//	interp := goruby.New()
+//	defer interp.Close()
+//	result, err := interp.Eval("[1, 2, 3].map { |x| x * 2 }")
+//
+// The package only exposes what is needed for embedding and keeps it
+// stable, while the packages implementing the interpreter may change.
+package goruby
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/goruby/goruby/evaluator"
+	"github.com/goruby/goruby/interpreter"
+	"github.com/goruby/goruby/object"
+)
+
+// An Interpreter evaluates Ruby programs. Every Interpreter has its own
+// global variables, top-level local variables and constants, including the
+// classes and modules defined by its programs, which persist between
+// evaluations.
+type Interpreter struct {
+	interpreter interpreter.Interpreter
+}
+
+// An Option configures an Interpreter
+type Option func(*config)
+
+type config struct {
+	options []interpreter.Option
+	input   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	loaders []evaluator.SourceLoader
+}
+
+// WithVM lets the Interpreter compile programs to bytecode and execute them
+// by the VM, if possible
+func WithVM() Option {
+	return func(c *config) { c.options = append(c.options, interpreter.WithVM()) }
+}
+
+// WithInput sets the stream Kernel#gets reads from, which defaults to
+// os.Stdin
+func WithInput(input io.Reader) Option {
+	return func(c *config) { c.input = input }
+}
+
+// WithOutput sets the stream Kernel functions like puts, print and p write
+// to, which defaults to os.Stdout
+func WithOutput(stdout io.Writer) Option {
+	return func(c *config) { c.stdout = stdout }
+}
+
+// WithErrorOutput sets the stream Kernel#warn and the warnings of the
+// interpreter write to, which defaults to os.Stderr
+func WithErrorOutput(stderr io.Writer) Option {
+	return func(c *config) { c.stderr = stderr }
+}
+
+// WithFS lets require and load find files within fsys, like an embed.FS
+// bundling Ruby libraries with the application. The root of fsys is the
+// root directory as well as the working directory, so `require "lib/util"`
+// loads lib/util.rb of fsys. Files not found in fsys or the sources of
+// previous options are loaded from the file system.
+func WithFS(fsys fs.FS) Option {
+	return func(c *config) { c.loaders = append(c.loaders, evaluator.FSLoader(fsys)) }
+}
+
+// WithSources lets require and load find the files in sources, which maps
+// their paths to their content, like WithFS does for the files of a file
+// system
+func WithSources(sources map[string]string) Option {
+	return func(c *config) { c.loaders = append(c.loaders, evaluator.MapLoader(sources)) }
+}
+
+// Limits caps the resources every evaluation may use. A zero value disables
+// the respective limit.
+type Limits struct {
+	// Operations caps the number of loop iterations and calls of methods
+	// and blocks
+	Operations int
+	// CallDepth caps the number of nested calls
+	CallDepth int
+	// Objects caps the number of objects created by literals and new
+	Objects int
+	// Duration caps the wall time
+	Duration time.Duration
+}
+
+// WithLimits terminates evaluations exceeding limits with an *Error of
+// class ResourceLimitError, so that untrusted programs can be run
+func WithLimits(limits Limits) Option {
+	return func(c *config) {
+		c.options = append(c.options, interpreter.WithLimits(evaluator.Limits(limits)))
+	}
+}
+
+// New returns an Interpreter configured by options
+func New(options ...Option) *Interpreter {
+	var c config
+	for _, option := range options {
+		option(&c)
+	}
+	if len(c.loaders) > 0 {
+		loader := evaluator.MultiLoader(append(c.loaders, evaluator.OSLoader{})...)
+		c.options = append(c.options, interpreter.WithSourceLoader(loader))
+	}
+	i := &Interpreter{interpreter: interpreter.New(c.options...)}
+	if c.input != nil {
+		i.interpreter.SetInput(c.input)
+	}
+	if c.stdout != nil || c.stderr != nil {
+		if c.stdout == nil {
+			c.stdout = object.Stdout
+		}
+		if c.stderr == nil {
+			c.stderr = object.Stderr
+		}
+		i.interpreter.SetOutput(c.stdout, c.stderr)
+	}
+	return i
+}
+
+// Eval evaluates the Ruby source src and returns the value of its last
+// expression. Exceptions raised by src are returned as *Error.
+func (i *Interpreter) Eval(src string) (Value, error) {
+	return result(i.interpreter.Interpret(src))
+}
+
+// EvalContext evaluates src like Eval, but stops once ctx is done and
+// returns an *Error of class Interrupt unwrapping to the error of ctx
+func (i *Interpreter) EvalContext(ctx context.Context, src string) (Value, error) {
+	return result(i.interpreter.InterpretContext(ctx, src))
+}
+
+// EvalFile evaluates the Ruby file at path like Eval. Files required
+// relatively are resolved against its directory.
+func (i *Interpreter) EvalFile(path string) (Value, error) {
+	return result(i.interpreter.InterpretFile(path))
+}
+
+// Define makes the Go function fn callable from Ruby as the global method
+// name. The arguments are converted into the parameter types of fn, which
+// may be bool, the integer and float kinds, string, slices and maps of
+// these, interface{} and object.RubyObject. A last parameter of type
+// *object.Proc receives the block. fn may return a single value and an
+// error, which is raised as RuntimeError unless it is a Ruby exception.
+//
+// Define returns an error if fn is no func of supported types.
+func (i *Interpreter) Define(name string, fn interface{}) error {
+	method, err := object.NewGoMethod(fn)
+	if err != nil {
+		return err
+	}
+	i.interpreter.DefineMethod(name, method)
+	return nil
+}
+
+// DefineIn makes the Go function fn callable from Ruby as module method
+// name of the top level module, which is created if it does not exist yet.
+// fn is converted like by Define.
+func (i *Interpreter) DefineIn(module, name string, fn interface{}) error {
+	method, err := object.NewGoMethod(fn)
+	if err != nil {
+		return err
+	}
+	return i.interpreter.DefineModuleFunction(module, name, method)
+}
+
+// WrapStruct returns a Value exposing the struct ptr points to to Ruby. Its
+// exported fields are readable and writable by methods named after them in
+// snake case, like `listen_addr` and `listen_addr=` for ListenAddr. Its
+// exported methods are callable by their snake case names, with arguments
+// and results converted like by Define. Changes made from Ruby are made to
+// the struct itself.
+//
+// Functions passed to Define may also return pointers to structs, which are
+// wrapped the same way.
+func (i *Interpreter) WrapStruct(ptr interface{}) (Value, error) {
+	wrapped, err := object.WrapStruct(ptr)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{wrapped}, nil
+}
+
+// BindChannel makes the Go channel ch available to Ruby as the global
+// method name, returning a Queue-like object. Its push, pop, close and
+// closed? methods send to, receive from and close ch, with the elements
+// converted like the arguments of Define. A Ruby Thread waiting for ch lets
+// other Threads run meanwhile.
+//
+// Channels passed to SetGlobal are converted the same way.
+func (i *Interpreter) BindChannel(name string, ch interface{}) error {
+	channel, err := object.NewChannel(ch)
+	if err != nil {
+		return err
+	}
+	return i.Define(name, func() object.RubyObject { return channel })
+}
+
+// SetGlobal sets the global variable name, like `$config`, to value, which
+// is either a Value or a Go value converted like by ValueOf
+func (i *Interpreter) SetGlobal(name string, value interface{}) error {
+	if !strings.HasPrefix(name, "$") {
+		return fmt.Errorf("invalid global variable name %q", name)
+	}
+	converted, err := toRuby(value)
+	if err != nil {
+		return err
+	}
+	i.interpreter.SetGlobal(name, converted)
+	return nil
+}
+
+// Call calls the method name like a call without receiver from within the
+// evaluated programs, e.g. a method they defined. The arguments are
+// converted like by ValueOf, Values and Procs are passed as they are.
+func (i *Interpreter) Call(name string, args ...interface{}) (Value, error) {
+	converted, err := toRubyArgs(args)
+	if err != nil {
+		return Value{}, err
+	}
+	return result(i.interpreter.Call(name, converted...))
+}
+
+// Proc returns the Proc, lambda or Method v as Proc to be called from Go,
+// e.g. a block a program registered as callback. It returns an *Error of
+// class TypeError if v is no such object.
+func (i *Interpreter) Proc(v Value) (*Proc, error) {
+	switch v.rubyObject().(type) {
+	case *object.Proc, *object.Method:
+		return &Proc{interpreter: i, callable: v.rubyObject()}, nil
+	default:
+		return nil, wrapError(object.NewTypeError("wrong argument type %s (expected Proc)", v.Class()))
+	}
+}
+
+// toRubyArgs converts the arguments like toRuby
+func toRubyArgs(args []interface{}) ([]object.RubyObject, error) {
+	converted := make([]object.RubyObject, len(args))
+	for i, arg := range args {
+		obj, err := toRuby(arg)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = obj
+	}
+	return converted, nil
+}
+
+// toRuby converts value like ValueOf, but keeps Values and Procs as they
+// are
+func toRuby(value interface{}) (object.RubyObject, error) {
+	switch value := value.(type) {
+	case Value:
+		return value.rubyObject(), nil
+	case *Proc:
+		return value.callable, nil
+	}
+	converted, err := ValueOf(value)
+	if err != nil {
+		return nil, err
+	}
+	return converted.rubyObject(), nil
+}
+
+// Close runs the handlers the evaluated programs registered to run at
+// exit, like blocks passed to at_exit. The Interpreter must not be used
+// afterwards.
+func (i *Interpreter) Close() error {
+	return wrapError(i.interpreter.Finalize())
+}
@@ -0,0 +1,466 @@
+package goruby
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/goruby/goruby/object"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+		class    string
+	}{
+		{"1 + 2", int64(3), "Integer"},
+		{"1.5 * 2", 3.0, "Float"},
+		{`"foo" + "bar"`, "foobar", "String"},
+		{":sym", "sym", "Symbol"},
+		{"true", true, "TrueClass"},
+		{"nil", nil, "NilClass"},
+		{"[1, [2, 3]]", []interface{}{int64(1), []interface{}{int64(2), int64(3)}}, "Array"},
+		{`{a: 1, "b" => "c"}`, map[string]interface{}{"a": int64(1), "b": "c"}, "Hash"},
+	}
+
+	for _, tt := range tests {
+		value, err := New().Eval(tt.input)
+		if err != nil {
+			t.Fatalf("Eval(%q) returned error: %v", tt.input, err)
+		}
+		if actual, err := value.GoValue(); err != nil || !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Eval(%q) = %#v, expected %#v", tt.input, actual, tt.expected)
+		}
+		if class := value.Class(); class != tt.class {
+			t.Errorf("Eval(%q).Class() = %q, expected %q", tt.input, class, tt.class)
+		}
+	}
+}
+
+func TestEvalKeepsState(t *testing.T) {
+	interp := New()
+	if _, err := interp.Eval("x = 2; def double(n); n * 2; end"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	value, err := interp.Eval("double(x)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if i, ok := value.Int(); !ok || i != 4 {
+		t.Errorf("expected 4, got %s", value.Inspect())
+	}
+
+	if _, err := New().Eval("x"); err == nil {
+		t.Errorf("expected local variables not to be shared between interpreters")
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	t.Run("exception", func(t *testing.T) {
+		_, err := New().Eval("def fail_now\n  raise ArgumentError, \"bad\"\nend\nfail_now")
+		var rubyErr *Error
+		if !errors.As(err, &rubyErr) {
+			t.Fatalf("expected *Error, got %T: %v", err, err)
+		}
+		if rubyErr.Class != "ArgumentError" || rubyErr.Message != "bad" {
+			t.Errorf("expected ArgumentError: bad, got %s: %s", rubyErr.Class, rubyErr.Message)
+		}
+		if len(rubyErr.Backtrace) == 0 || !strings.Contains(rubyErr.Backtrace[0], "fail_now") {
+			t.Errorf("expected backtrace to start in fail_now, got %v", rubyErr.Backtrace)
+		}
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := New().Eval("1 +")
+		var rubyErr *Error
+		if !errors.As(err, &rubyErr) || rubyErr.Class != "SyntaxError" {
+			t.Errorf("expected SyntaxError, got %T: %v", err, err)
+		}
+	})
+	t.Run("exit", func(t *testing.T) {
+		_, err := New().Eval("exit 3")
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Status != 3 {
+			t.Errorf("expected exit status 3, got %T: %v", err, err)
+		}
+	})
+	t.Run("limits", func(t *testing.T) {
+		_, err := New(WithLimits(Limits{Operations: 100})).Eval("loop do; end")
+		var rubyErr *Error
+		if !errors.As(err, &rubyErr) || rubyErr.Class != "ResourceLimitError" {
+			t.Errorf("expected ResourceLimitError, got %T: %v", err, err)
+		}
+	})
+	t.Run("limits as local variable", func(t *testing.T) {
+		_, err := New(WithLimits(Limits{Operations: 100})).Eval("limits = 1\nloop do; end")
+		var rubyErr *Error
+		if !errors.As(err, &rubyErr) || rubyErr.Class != "ResourceLimitError" {
+			t.Errorf("expected ResourceLimitError, got %T: %v", err, err)
+		}
+	})
+}
+
+func TestEvalContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := New().EvalContext(ctx, "loop do; end")
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "Interrupt" {
+		t.Fatalf("expected Interrupt, got %T: %v", err, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to unwrap to context.DeadlineExceeded")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = New().EvalContext(ctx, "sleep 5")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("expected sleep to be stopped by the deadline, got %v after %s", err, time.Since(start))
+	}
+
+	value, err := New().EvalContext(context.Background(), "context = 1; i = 0; while i < 3; i = i + 1; end; i")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if i, _ := value.Int(); i != 3 {
+		t.Errorf("expected a local variable context not to shadow the context, got %s", value.Inspect())
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goruby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "main.rb")
+	if err := ioutil.WriteFile(path, []byte("[1, 2].map { |x| x * 10 }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := New().EvalFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	elements, ok := value.Array()
+	if !ok || len(elements) != 2 || elements[1].String() != "20" {
+		t.Errorf("expected [10, 20], got %s", value.Inspect())
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/greeting.rb": &fstest.MapFile{Data: []byte("def greet(name)\n\"Hello, \" + name\nend")},
+	}
+	interp := New(WithFS(fsys), WithSources(map[string]string{
+		"lib/farewell.rb": "def farewell(name)\n\"Bye, \" + name\nend",
+	}))
+
+	value, err := interp.Eval("require \"lib/greeting\"\nrequire \"lib/farewell\"\ngreet(\"Go\") + \". \" + farewell(\"Go\")")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if str, _ := value.Str(); str != "Hello, Go. Bye, Go" {
+		t.Errorf("expected %q, got %q", "Hello, Go. Bye, Go", str)
+	}
+
+	value, err = interp.Eval("loader = 1\nrequire \"lib/greeting\"")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value.Truthy() {
+		t.Errorf("expected a local variable loader not to shadow the loader, got %s", value.Inspect())
+	}
+
+	_, err = interp.Eval(`require "lib/missing"`)
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "LoadError" {
+		t.Errorf("expected a LoadError, got %v", err)
+	}
+}
+
+func TestWithInput(t *testing.T) {
+	value, err := New(WithInput(strings.NewReader("hello\n"))).Eval("gets")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if str, _ := value.Str(); str != "hello\n" {
+		t.Errorf("expected %q, got %q", "hello\n", str)
+	}
+}
+
+func TestWithOutput(t *testing.T) {
+	var stdout1, stdout2, stderr strings.Builder
+	interp1 := New(WithOutput(&stdout1), WithErrorOutput(&stderr))
+	interp2 := New(WithOutput(&stdout2))
+
+	if _, err := interp1.Eval(`puts "one"; warn "careful"`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := interp2.Eval(`print "two"; p :three`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stdout1.String() != "one\n" {
+		t.Errorf("expected first output %q, got %q", "one\n", stdout1.String())
+	}
+	if stdout2.String() != "two:three\n" {
+		t.Errorf("expected second output %q, got %q", "two:three\n", stdout2.String())
+	}
+	if stderr.String() != "careful\n" {
+		t.Errorf("expected error output %q, got %q", "careful\n", stderr.String())
+	}
+
+	var stdout3 strings.Builder
+	if _, err := New(WithOutput(&stdout3)).Eval("streams = 1\nputs 1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout3.String() != "1\n" {
+		t.Errorf("expected a local variable streams not to shadow the output, got %q", stdout3.String())
+	}
+}
+
+func TestDefine(t *testing.T) {
+	interp := New()
+	err := interp.Define("shout", func(s string, times int) (string, error) {
+		if times < 0 {
+			return "", errors.New("negative times")
+		}
+		return strings.Repeat(strings.ToUpper(s), times), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := interp.DefineIn("Host", "sum", func(values []float64) float64 {
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value, err := interp.Eval(`shout("ab", 2) + Host.sum([1, 2.5]).to_s`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if str, _ := value.Str(); str != "ABAB3.5" {
+		t.Errorf("expected %q, got %q", "ABAB3.5", str)
+	}
+
+	_, err = interp.Eval(`shout("ab", -1)`)
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "RuntimeError" || rubyErr.Message != "negative times" {
+		t.Errorf("expected RuntimeError: negative times, got %v", err)
+	}
+
+	if err := interp.Define("invalid", 42); err == nil {
+		t.Errorf("expected error defining a non-function")
+	}
+}
+
+func TestValueConversion(t *testing.T) {
+	value, err := New().Eval(`{"names" => ["a", "b"], "count" => 2}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := value.Decode(&decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{"names": []interface{}{"a", "b"}, "count": int64(2)}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %#v, got %#v", expected, decoded)
+	}
+
+	var names []int
+	err = value.Decode(&names)
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "TypeError" {
+		t.Errorf("expected TypeError, got %T: %v", err, err)
+	}
+
+	converted, err := ValueOf(map[string][]int{"x": {1, 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inspect := converted.Inspect(); inspect != "{x=>[1, 2]}" {
+		t.Errorf("expected %q, got %q", "{x=>[1, 2]}", inspect)
+	}
+}
+
+type testConfig struct {
+	Name    string
+	Retries int
+}
+
+func (c *testConfig) Describe(prefix string) string {
+	return prefix + c.Name
+}
+
+func TestWrapStruct(t *testing.T) {
+	interp := New()
+	config := &testConfig{Name: "app", Retries: 1}
+	wrapped, err := interp.WrapStruct(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := interp.SetGlobal("$config", wrapped); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value, err := interp.Eval(`$config.retries = $config.retries + 2; $config.describe("name: ")`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if str, _ := value.Str(); str != "name: app" {
+		t.Errorf("expected %q, got %q", "name: app", str)
+	}
+	if config.Retries != 3 {
+		t.Errorf("expected retries to be set to 3, got %d", config.Retries)
+	}
+
+	if err := interp.SetGlobal("config", 1); err == nil {
+		t.Errorf("expected error for a name without $")
+	}
+}
+
+func TestCall(t *testing.T) {
+	interp := New()
+	if _, err := interp.Eval("def greet(name, times); (\"hi \" + name) * times; end"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	value, err := interp.Call("greet", "bob", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if str, _ := value.Str(); str != "hi bobhi bob" {
+		t.Errorf("expected %q, got %q", "hi bobhi bob", str)
+	}
+
+	_, err = interp.Call("greet", "bob")
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "ArgumentError" {
+		t.Errorf("expected ArgumentError, got %v", err)
+	}
+
+	if _, err := interp.Call("greet", make(chan int), 1); err == nil {
+		t.Errorf("expected error converting a channel")
+	}
+}
+
+func TestProc(t *testing.T) {
+	interp := New()
+	var handler *Proc
+	err := interp.Define("on_event", func(block *object.Proc) error {
+		value, err := ValueOf(block)
+		if err != nil {
+			return err
+		}
+		handler, err = interp.Proc(value)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := interp.Eval("on_event { |x, y| [x * 2, y] }"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler == nil {
+		t.Fatalf("expected the block to be registered")
+	}
+	if handler.Arity() != 2 {
+		t.Errorf("expected arity 2, got %d", handler.Arity())
+	}
+
+	result, err := handler.Call(21, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	elements, _ := result.Array()
+	if len(elements) != 2 {
+		t.Fatalf("expected 2 elements, got %v", result)
+	}
+	if n, _ := elements[0].Int(); n != 42 {
+		t.Errorf("expected 42, got %v", elements[0])
+	}
+	if elements[1].Class() != "Proc" {
+		t.Errorf("expected the Proc itself, got %v", elements[1])
+	}
+
+	method, err := interp.Eval("5.method(:+)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	add, err := interp.Proc(method)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum, err := add.Call(3); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if n, _ := sum.Int(); n != 8 {
+		t.Errorf("expected 8, got %v", sum)
+	}
+
+	notCallable, _ := interp.Eval("42")
+	_, err = interp.Proc(notCallable)
+	var rubyErr *Error
+	if !errors.As(err, &rubyErr) || rubyErr.Class != "TypeError" {
+		t.Errorf("expected TypeError, got %v", err)
+	}
+}
+
+func TestBindChannel(t *testing.T) {
+	interp := New()
+	events := make(chan string)
+	results := make(chan int, 3)
+	if err := interp.BindChannel("events", events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := interp.BindChannel("results", (chan<- int)(results)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	go func() {
+		for _, event := range []string{"a", "bb", "ccc"} {
+			events <- event
+		}
+		close(events)
+	}()
+	value, err := interp.Eval(`
+	while (event = events.pop)
+	  results << event.size
+	end
+	results.close
+	events.closed?
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if closed, _ := value.Bool(); !closed {
+		t.Errorf("expected events to be closed")
+	}
+	var sizes []int
+	for size := range results {
+		sizes = append(sizes, size)
+	}
+	if !reflect.DeepEqual(sizes, []int{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", sizes)
+	}
+
+	if err := interp.BindChannel("invalid", 42); err == nil {
+		t.Errorf("expected error binding a non-channel")
+	}
+}
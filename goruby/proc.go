@@ -0,0 +1,43 @@
+package goruby
+
+import (
+	"github.com/goruby/goruby/object"
+)
+
+// A Proc is a Ruby Proc, lambda or Method which can be called from Go, e.g.
+// as callback for events of the host application. It is created by
+// Interpreter.Proc and must not be called concurrently with other
+// evaluations of its Interpreter.
+type Proc struct {
+	interpreter *Interpreter
+	callable    object.RubyObject
+}
+
+// Call calls p with args, converted like by Interpreter.Call, and returns
+// its result. Exceptions raised are returned as *Error, and the limits of
+// the Interpreter apply.
+func (p *Proc) Call(args ...interface{}) (Value, error) {
+	converted, err := toRubyArgs(args)
+	if err != nil {
+		return Value{}, err
+	}
+	return result(p.interpreter.interpreter.Send(p.callable, "call", converted...))
+}
+
+// Arity returns the number of arguments p takes, which is negative for
+// procs taking a variable number like Proc#arity
+func (p *Proc) Arity() int {
+	arity, err := p.interpreter.interpreter.Send(p.callable, "arity")
+	if err != nil {
+		return -1
+	}
+	if i, ok := arity.(*object.Integer); ok {
+		return int(i.Value)
+	}
+	return -1
+}
+
+// Value returns p as Value, e.g. to be passed to Ruby again
+func (p *Proc) Value() Value {
+	return Value{p.callable}
+}
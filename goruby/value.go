@@ -0,0 +1,163 @@
+package goruby
+
+import (
+	"github.com/goruby/goruby/object"
+)
+
+// A Value is a Ruby object returned by an evaluation
+type Value struct {
+	object object.RubyObject
+}
+
+// Inspect returns the representation of v like Kernel#inspect
+func (v Value) Inspect() string {
+	if v.object == nil {
+		return "nil"
+	}
+	return v.object.Inspect()
+}
+
+// String returns v converted by its to_s method
+func (v Value) String() string {
+	if v.object == nil {
+		return ""
+	}
+	if str, ok := v.object.(*object.String); ok {
+		return str.Value
+	}
+	converted, err := object.Send(v.object, "to_s")
+	if str, ok := converted.(*object.String); ok && err == nil {
+		return str.Value
+	}
+	return v.object.Inspect()
+}
+
+// Class returns the name of the class of v
+func (v Value) Class() string {
+	if v.object == nil {
+		return "NilClass"
+	}
+	return v.object.Class().(object.RubyObject).Inspect()
+}
+
+// IsNil reports whether v is nil
+func (v Value) IsNil() bool {
+	return v.object == nil || v.object == object.NIL
+}
+
+// Truthy reports whether v counts as true in conditions, i.e. whether it
+// is neither nil nor false
+func (v Value) Truthy() bool {
+	if v.IsNil() {
+		return false
+	}
+	b, ok := v.object.(*object.Boolean)
+	return !ok || b.Value
+}
+
+// Int returns the value of an Integer
+func (v Value) Int() (int64, bool) {
+	i, ok := v.object.(*object.Integer)
+	if !ok {
+		return 0, false
+	}
+	return i.Value, true
+}
+
+// Float returns the value of a Float or Integer
+func (v Value) Float() (float64, bool) {
+	switch number := v.object.(type) {
+	case *object.Float:
+		return number.Value, true
+	case *object.Integer:
+		return float64(number.Value), true
+	default:
+		return 0, false
+	}
+}
+
+// Bool returns the value of true or false
+func (v Value) Bool() (bool, bool) {
+	b, ok := v.object.(*object.Boolean)
+	if !ok {
+		return false, false
+	}
+	return b.Value, true
+}
+
+// Str returns the value of a String or the name of a Symbol
+func (v Value) Str() (string, bool) {
+	switch str := v.object.(type) {
+	case *object.String:
+		return str.Value, true
+	case *object.Symbol:
+		return str.Value, true
+	default:
+		return "", false
+	}
+}
+
+// Array returns the elements of an Array
+func (v Value) Array() ([]Value, bool) {
+	array, ok := v.object.(*object.Array)
+	if !ok {
+		return nil, false
+	}
+	return values(array.Elements), true
+}
+
+// Hash returns the keys and values of a Hash in insertion order
+func (v Value) Hash() (keys, vals []Value, ok bool) {
+	hash, ok := v.object.(*object.Hash)
+	if !ok {
+		return nil, nil, false
+	}
+	return values(hash.Keys()), values(hash.Values()), true
+}
+
+// GoValue converts v into the Go value closest to it: nil, bool, int64,
+// float64, string, time.Time, []interface{} or map[string]interface{} keyed
+// by the names of Strings and Symbols and the inspected other keys. Other
+// objects are returned as Value. It returns an *Error of class
+// ArgumentError for Hashes with keys converting to the same name.
+func (v Value) GoValue() (interface{}, error) {
+	goValue, err := object.GoValue(v.object)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if obj, ok := goValue.(object.RubyObject); ok {
+		return Value{obj}, nil
+	}
+	return goValue, nil
+}
+
+// Decode converts v into the type target points to and stores it there,
+// e.g. an Array of Strings into a []string. It returns an *Error of class
+// TypeError if v cannot be converted into that type.
+func (v Value) Decode(target interface{}) error {
+	return wrapError(object.ToGo(v.rubyObject(), target))
+}
+
+// ValueOf converts the Go value into a Value. Bools, numbers and strings
+// become their Ruby counterparts, time.Time a Time, slices Arrays and maps
+// Hashes. It returns an *Error of class TypeError for values which cannot
+// be converted, like structs.
+func ValueOf(value interface{}) (Value, error) {
+	return result(object.FromGo(value))
+}
+
+// rubyObject returns the object of v, which is nil for the zero Value
+func (v Value) rubyObject() object.RubyObject {
+	if v.object == nil {
+		return object.NIL
+	}
+	return v.object
+}
+
+func values(objects []object.RubyObject) []Value {
+	vals := make([]Value, len(objects))
+	for i, obj := range objects {
+		vals[i] = Value{obj}
+	}
+	return vals
+}
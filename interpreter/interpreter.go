@@ -1,27 +1,146 @@
 package interpreter
 
 import (
+	"context"
+	"io"
+
 	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/compiler"
 	"github.com/goruby/goruby/evaluator"
 	"github.com/goruby/goruby/lexer"
 	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/optimizer"
 	"github.com/goruby/goruby/parser"
+	"github.com/goruby/goruby/vm"
 )
 
 // Interpreter defines the methods of an interpreter
 type Interpreter interface {
 	Interpret(string) (object.RubyObject, error)
+	// InterpretContext interprets input like Interpret, but stops once ctx
+	// is done and returns an Interrupt unwrapping to the error of ctx
+	InterpretContext(ctx context.Context, input string) (object.RubyObject, error)
+	// InterpretFile interprets the content of the file filename. Files
+	// required relatively are resolved against its directory.
+	InterpretFile(filename string) (object.RubyObject, error)
 	SetEnvironment(object.Environment)
+	// DefineMethod defines method on the main object, so that programs can
+	// call it like a global function
+	DefineMethod(name string, method object.RubyMethod)
+	// DefineModuleFunction defines method as singleton method name of the
+	// top level module moduleName, which is created if it does not exist
+	// yet
+	DefineModuleFunction(moduleName, name string, method object.RubyMethod) error
+	// SetGlobal sets the global variable name, which includes the leading
+	// `$`, to value
+	SetGlobal(name string, value object.RubyObject)
+	// Call calls the method name on the main object like a call without
+	// receiver from within the program, e.g. a method the program defined
+	Call(name string, args ...object.RubyObject) (object.RubyObject, error)
+	// Send calls the method name on receiver, e.g. call on a Proc returned
+	// by the program. Like for Call, the limits of the Interpreter apply.
+	Send(receiver object.RubyObject, name string, args ...object.RubyObject) (object.RubyObject, error)
+	// SetInput sets the stream Kernel#gets reads from, which defaults to
+	// object.Stdin
+	SetInput(io.Reader)
+	// SetOutput sets the streams Kernel functions like puts and warn write
+	// to, which default to object.Stdout and object.Stderr
+	SetOutput(stdout, stderr io.Writer)
+	// Interrupt interrupts the running program, e.g. a call to sleep, which
+	// then raises an Interrupt exception
+	Interrupt()
+	// Finalize runs all handlers registered to run at exit, like END blocks
+	// and blocks passed to at_exit. It must be called once the program has
+	// finished, including when it was terminated by Kernel#exit.
+	Finalize() error
+}
+
+// ExitStatus returns the status a program should exit with when it finished
+// with err. It is the status passed to Kernel#exit if err is a SystemExit,
+// 0 if err is nil and 1 otherwise.
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exit, ok := err.(*object.SystemExit); ok {
+		return exit.Status
+	}
+	return 1
+}
+
+// An Option configures an Interpreter
+type Option func(*interpreter)
+
+// WithVM lets the Interpreter compile programs to bytecode and execute them
+// by the VM. Programs the compiler does not support are evaluated by the
+// evaluator as usual.
+func WithVM() Option {
+	return func(i *interpreter) { i.useVM = true }
+}
+
+// WithoutOptimizer lets the Interpreter run programs exactly as parsed,
+// without optimizing them first. This is mainly useful for debugging.
+func WithoutOptimizer() Option {
+	return func(i *interpreter) { i.skipOptimizer = true }
+}
+
+// WithTailCallOptimization lets calls in tail position replace the frame
+// of the calling method, so that deep recursion in tail position does not
+// exhaust the stack. The replaced frames are missing from backtraces.
+func WithTailCallOptimization() Option {
+	return func(i *interpreter) { i.tailCalls = true }
+}
+
+// WithLimits caps the resources each program run by the Interpreter may
+// use. A program exceeding them is terminated with a ResourceLimitError,
+// which it cannot rescue to continue. Builtin methods are not interrupted,
+// so a single call of one may still exceed the limits.
+func WithLimits(limits evaluator.Limits) Option {
+	return func(i *interpreter) { i.limits = &limits }
+}
+
+// WithSourceLoader lets require, require_relative, load and autoload load
+// files by loader instead of from the file system. Files passed to
+// InterpretFile are still read from the file system.
+func WithSourceLoader(loader evaluator.SourceLoader) Option {
+	return func(i *interpreter) { i.loader = loader }
+}
+
+// WithLoadPath appends dirs to the $LOAD_PATH of the environment the
+// Interpreter starts with, so that require finds the files within them
+// before those within the working directory
+func WithLoadPath(dirs ...string) Option {
+	return func(i *interpreter) {
+		loadPath, ok := i.environment.Get("$LOAD_PATH")
+		paths, isArray := loadPath.(*object.Array)
+		if !ok || !isArray {
+			paths = object.NewArray()
+			i.environment.SetGlobal("$LOAD_PATH", paths)
+		}
+		for _, dir := range dirs {
+			paths.Elements = append(paths.Elements, &object.String{Value: dir})
+		}
+	}
 }
 
 // New returns an Interpreter ready to use and with the environment set to
 // object.NewMainEnvironment()
-func New() Interpreter {
-	return &interpreter{environment: object.NewMainEnvironment()}
+func New(options ...Option) Interpreter {
+	i := &interpreter{environment: object.NewMainEnvironment()}
+	for _, option := range options {
+		option(i)
+	}
+	return i
 }
 
 type interpreter struct {
-	environment object.Environment
+	environment   object.Environment
+	useVM         bool
+	skipOptimizer bool
+	tailCalls     bool
+	limits        *evaluator.Limits
+	streams       *object.Streams
+	loader        evaluator.SourceLoader
 }
 
 func (i *interpreter) Interpret(input string) (object.RubyObject, error) {
@@ -29,18 +148,110 @@ func (i *interpreter) Interpret(input string) (object.RubyObject, error) {
 	if err != nil {
 		return nil, err
 	}
-	evaluated, err := evaluator.Eval(node, i.environment)
-	if evaluator.IsError(evaluated) {
+	evaluated, err := i.run(node, i.environment)
+	if err != nil {
 		return nil, err
 	}
 	return evaluated, nil
 }
 
+func (i *interpreter) InterpretContext(ctx context.Context, input string) (object.RubyObject, error) {
+	defer evaluator.SetContext(i.environment, ctx)()
+	return i.Interpret(input)
+}
+
+func (i *interpreter) InterpretFile(filename string) (object.RubyObject, error) {
+	return evaluator.RunFile(filename, i.environment, i.run)
+}
+
+// run optimizes program unless disabled and executes it by the VM if
+// enabled and the program can be compiled, and by the evaluator otherwise
+func (i *interpreter) run(program *ast.Program, env object.Environment) (object.RubyObject, error) {
+	if !i.skipOptimizer {
+		program = optimizer.Optimize(program)
+	}
+	defer i.configure(env)()
+	if i.useVM {
+		if bytecode, err := compiler.Compile(program); err == nil {
+			return vm.Run(bytecode, env)
+		}
+	}
+	return evaluator.Eval(program, env)
+}
+
+// configure applies the options of the interpreter to env. It returns a
+// function restoring the previous limits.
+func (i *interpreter) configure(env object.Environment) (restore func()) {
+	evaluator.SetTailCallOptimization(env, i.tailCalls)
+	if i.streams != nil {
+		evaluator.SetStreams(env, i.streams)
+	}
+	if i.loader != nil {
+		evaluator.SetSourceLoader(env, i.loader)
+	}
+	if i.limits != nil {
+		return evaluator.SetLimits(env, *i.limits)
+	}
+	return func() {}
+}
+
 func (i *interpreter) SetEnvironment(env object.Environment) {
 	i.environment = env
 }
 
-func (i *interpreter) parse(input string) (ast.Node, error) {
+func (i *interpreter) DefineMethod(name string, method object.RubyMethod) {
+	self, _ := i.environment.Get("self")
+	object.RuntimeOf(i.environment).DefineMethod(self, name, method)
+}
+
+func (i *interpreter) DefineModuleFunction(moduleName, name string, method object.RubyMethod) error {
+	return object.RuntimeOf(i.environment).DefineModuleFunction(moduleName, name, method)
+}
+
+func (i *interpreter) SetGlobal(name string, value object.RubyObject) {
+	i.environment.SetGlobal(name, value)
+}
+
+func (i *interpreter) Call(name string, args ...object.RubyObject) (object.RubyObject, error) {
+	defer i.configure(i.environment)()
+	self, _ := i.environment.Get("self")
+	runtime := object.RuntimeOf(i.environment)
+	if i.streams != nil {
+		if result, ok, err := runtime.CallWithStreams(i.streams, self, name, args...); ok {
+			return result, err
+		}
+	}
+	return runtime.Send(self, name, args...)
+}
+
+func (i *interpreter) Send(receiver object.RubyObject, name string, args ...object.RubyObject) (object.RubyObject, error) {
+	defer i.configure(i.environment)()
+	return object.RuntimeOf(i.environment).Send(receiver, name, args...)
+}
+
+func (i *interpreter) SetInput(input io.Reader) {
+	if i.streams == nil {
+		i.streams = object.DefaultStreams()
+	}
+	i.streams.Stdin = object.NewIO(input)
+}
+
+func (i *interpreter) SetOutput(stdout, stderr io.Writer) {
+	if i.streams == nil {
+		i.streams = object.DefaultStreams()
+	}
+	i.streams.Stdout, i.streams.Stderr = stdout, stderr
+}
+
+func (i *interpreter) Interrupt() {
+	object.RuntimeOf(i.environment).RaiseInterrupt()
+}
+
+func (i *interpreter) Finalize() error {
+	return evaluator.RunExitHandlers(i.environment)
+}
+
+func (i *interpreter) parse(input string) (*ast.Program, error) {
 	l := lexer.New(input)
 	p := parser.New(l)
 	return p.ParseProgram()
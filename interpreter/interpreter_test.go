@@ -1,8 +1,20 @@
 package interpreter
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/goruby/goruby/evaluator"
 	"github.com/goruby/goruby/object"
 )
 
@@ -39,6 +51,67 @@ func TestInterpreterInterpret(t *testing.T) {
 			t.Fail()
 		}
 	})
+	t.Run("return proper result with the VM", func(t *testing.T) {
+		input := `
+			def foo
+				3
+			end
+
+			x = 5
+			while x < 10
+				x = x + foo
+			end
+
+			def add x, y
+				x + y
+			end
+
+			add foo, x
+			`
+		i := New(WithVM())
+
+		out, err := i.Interpret(input)
+		if err != nil {
+			panic(err)
+		}
+
+		res, ok := out.(*object.Integer)
+		if !ok {
+			t.Logf("Expected *object.Integer, got %T\n", out)
+			t.Fail()
+		}
+
+		if res.Value != 14 {
+			t.Logf("Expected result to equal 14, got %d\n", res.Value)
+			t.Fail()
+		}
+	})
+	t.Run("return proper result without the optimizer", func(t *testing.T) {
+		input := `
+			x = 2 * 3
+			if false
+				x = 1
+			end
+			x + 1
+			`
+		i := New(WithoutOptimizer())
+
+		out, err := i.Interpret(input)
+		if err != nil {
+			panic(err)
+		}
+
+		res, ok := out.(*object.Integer)
+		if !ok {
+			t.Logf("Expected *object.Integer, got %T\n", out)
+			t.Fail()
+		}
+
+		if res.Value != 7 {
+			t.Logf("Expected result to equal 7, got %d\n", res.Value)
+			t.Fail()
+		}
+	})
 	t.Run("return proper result with changed env", func(t *testing.T) {
 		input := `
 			def foo
@@ -72,4 +145,582 @@ func TestInterpreterInterpret(t *testing.T) {
 			t.Fail()
 		}
 	})
+	t.Run("return runtime errors", func(t *testing.T) {
+		i := New()
+
+		_, err := i.Interpret("1 / 0")
+
+		if _, ok := err.(*object.ZeroDivisionError); !ok {
+			t.Logf("Expected ZeroDivisionError, got %T:%v\n", err, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestInterpreterFinalize(t *testing.T) {
+	input := `
+		x = 1
+		END { x = x + 1 }
+		`
+	env := object.NewEnvironment()
+	i := New()
+	i.SetEnvironment(env)
+
+	_, err := i.Interpret(input)
+	if err != nil {
+		panic(err)
+	}
+
+	err = i.Finalize()
+	if err != nil {
+		panic(err)
+	}
+
+	x, _ := env.Get("x")
+	res, ok := x.(*object.Integer)
+	if !ok {
+		t.Fatalf("Expected *object.Integer, got %T\n", x)
+	}
+
+	if res.Value != 2 {
+		t.Logf("Expected x to equal 2, got %d\n", res.Value)
+		t.Fail()
+	}
+}
+
+func TestInterpreterInterpretContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options []Option
+	}{
+		{"loop", "while true\nend", nil},
+		{"block", "loop { x = 1 }", nil},
+		{"method calls", "def foo\n1\nend\nwhile true\nfoo\nend", nil},
+		{"vm", "while true\nend", []Option{WithVM()}},
+		{"sleep", "sleep 5", nil},
+		{"Queue#pop", "q = Queue.new; Thread.new { sleep }; q.pop", nil},
+		{"Thread#join", "Thread.new { sleep }.join", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, err := New(tt.options...).InterpretContext(ctx, tt.input)
+
+			if _, ok := err.(*object.Interrupt); !ok || !errors.Is(err, context.DeadlineExceeded) {
+				t.Logf("Expected an Interrupt for the deadline, got %T:%v", err, err)
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("gets", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		input, output := io.Pipe()
+		defer output.Close()
+		i := New()
+		i.SetInput(input)
+
+		_, err := i.InterpretContext(ctx, "gets")
+		if _, ok := err.(*object.Interrupt); !ok || !errors.Is(err, context.DeadlineExceeded) {
+			t.Logf("Expected an Interrupt for the deadline, got %T:%v", err, err)
+			t.Fail()
+		}
+
+		go output.Write([]byte("foo\n"))
+		out, err := i.Interpret("gets")
+		if line, ok := out.(*object.String); err != nil || !ok || line.Value != "foo\n" {
+			t.Logf("Expected the line read meanwhile to be kept, got %v, %v", out, err)
+			t.Fail()
+		}
+	})
+	t.Run("finished", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		i := New()
+
+		out, err := i.InterpretContext(ctx, "x = 3")
+		cancel()
+		if err != nil {
+			t.Logf("Expected no error, got %T:%v", err, err)
+			t.FailNow()
+		}
+		if out.Inspect() != "3" {
+			t.Logf("Expected result to equal 3, got %s", out.Inspect())
+			t.Fail()
+		}
+
+		_, err = i.Interpret("while x > 0\nx = x - 1\nend")
+		if err != nil {
+			t.Logf("Expected the context not to apply afterwards, got %T:%v", err, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestInterpreterSetInput(t *testing.T) {
+	i := New()
+	i.SetInput(strings.NewReader("foo\nbar\n"))
+
+	out, err := i.Interpret("gets\ngets(chomp: true)")
+	if err != nil {
+		panic(err)
+	}
+
+	res, ok := out.(*object.String)
+	if !ok {
+		t.Logf("Expected *object.String, got %T\n", out)
+		t.FailNow()
+	}
+
+	if res.Value != "bar" {
+		t.Logf("Expected result to equal %q, got %q\n", "bar", res.Value)
+		t.Fail()
+	}
+}
+
+func TestInterpreterSetOutput(t *testing.T) {
+	var stdout1, stderr1, stdout2, stderr2 strings.Builder
+	i1, i2 := New(), New(WithVM())
+	i1.SetOutput(&stdout1, &stderr1)
+	i2.SetOutput(&stdout2, &stderr2)
+
+	_, err := i1.Interpret("puts 1\nprint 2\np 3\nwarn \"four\"")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = i2.Interpret("x = 5\nputs x\nprintf(\"%d\", 6)\nself.puts 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		actual   string
+		expected string
+	}{
+		{"first stdout", stdout1.String(), "1\n23\n"},
+		{"first stderr", stderr1.String(), "four\n"},
+		{"second stdout", stdout2.String(), "5\n67\n"},
+		{"second stderr", stderr2.String(), ""},
+	}
+	for _, tt := range tests {
+		if tt.actual != tt.expected {
+			t.Errorf("Expected %s to equal %q, got %q", tt.name, tt.expected, tt.actual)
+		}
+	}
+
+	t.Run("redefined methods", func(t *testing.T) {
+		var stdout strings.Builder
+		i := New()
+		i.SetOutput(&stdout, &stdout)
+
+		_, err := i.Interpret("def puts(x)\nprint \"custom \", x\nend\nputs 1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if stdout.String() != "custom 1" {
+			t.Errorf("Expected output to equal %q, got %q", "custom 1", stdout.String())
+		}
+	})
+}
+
+func TestInterpreterInterpretFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goruby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"main.rb": "require_relative \"lib\"\nvalue + 1",
+		"lib.rb":  "value = 41",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	i := New()
+
+	out, err := i.InterpretFile(filepath.Join(dir, "main.rb"))
+	if err != nil {
+		panic(err)
+	}
+
+	res, ok := out.(*object.Integer)
+	if !ok {
+		t.Logf("Expected *object.Integer, got %T\n", out)
+		t.FailNow()
+	}
+
+	if res.Value != 42 {
+		t.Logf("Expected result to equal 42, got %d\n", res.Value)
+		t.Fail()
+	}
+}
+
+func TestInterpreterCoverage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goruby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "main.rb")
+	source := "x = 1\nif x > 0\n  y = 2\nelse\n  y = 3\nend\ndef double(a)\n  a * 2\nend\ni = 0\nwhile i < 2\n  i = double(i) + 1\nend\n"
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected := "[1 1 1 -1 0 -1 1 2 -1 1 1 2 -1]"
+
+	for name, options := range map[string][]Option{"evaluator": nil, "vm": {WithVM()}} {
+		t.Run(name, func(t *testing.T) {
+			if err := object.StartCoverage(); err != nil {
+				t.Fatal(err)
+			}
+			_, err := New(options...).InterpretFile(path)
+			result := object.StopCoverage()
+			if err != nil {
+				t.Fatalf("Expected no error, got %T:%v", err, err)
+			}
+			if lines := fmt.Sprint(result[path]); lines != expected {
+				t.Logf("Expected the lines covered to equal %s, got %s", expected, lines)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestInterpreterTraceHook(t *testing.T) {
+	source := "def double(a)\n  a * 2\nend\nx = double(1)\nx + 1\n"
+	expected := []string{"1: line", "4: line", "1: call double", "2: line", "2: return double", "5: line"}
+
+	for name, options := range map[string][]Option{"evaluator": nil, "vm": {WithVM()}} {
+		t.Run(name, func(t *testing.T) {
+			var events []string
+			hook := object.NewTraceHook(func(event *object.TraceEvent) error {
+				if event.Event == "line" {
+					events = append(events, fmt.Sprintf("%d: %s", event.Lineno, event.Event))
+				} else {
+					events = append(events, fmt.Sprintf("%d: %s %s", event.Lineno, event.Event, event.MethodID))
+				}
+				return nil
+			})
+			hook.SetEnabled(true)
+			_, err := New(options...).Interpret(source)
+			hook.SetEnabled(false)
+			if err != nil {
+				t.Fatalf("Expected no error, got %T:%v", err, err)
+			}
+			if !reflect.DeepEqual(events, expected) {
+				t.Logf("Expected the events %q, got %q", expected, events)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestInterpreterWithLoadPath(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(first, "lib.rb"):    "def lib; \"first\"; end",
+		filepath.Join(second, "lib.rb"):   "def lib; \"second\"; end",
+		filepath.Join(second, "other.rb"): "def other; \"other\"; end",
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	i := New(WithLoadPath(first, second))
+
+	out, err := i.Interpret("require \"lib\"\nrequire \"other\"\n[lib, other]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "[first, other]"; out.Inspect() != expected {
+		t.Errorf("Expected %s, got %s", expected, out.Inspect())
+	}
+}
+
+func TestInterpreterExit(t *testing.T) {
+	input := `
+		x = []
+		at_exit { x << 1 }
+		at_exit { x << 2 }
+		exit 3
+		x << 4
+		`
+	env := object.NewMainEnvironment()
+	i := New()
+	i.SetEnvironment(env)
+
+	_, err := i.Interpret(input)
+	if status := ExitStatus(err); status != 3 {
+		t.Logf("Expected exit status 3, got %d (%v)\n", status, err)
+		t.Fail()
+	}
+
+	err = i.Finalize()
+	if err != nil {
+		panic(err)
+	}
+
+	x, _ := env.Get("x")
+	if x.Inspect() != "[2, 1]" {
+		t.Logf("Expected x to equal [2, 1], got %s\n", x.Inspect())
+		t.Fail()
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, 0},
+		{object.NewSystemExit(0), 0},
+		{object.NewSystemExit(4), 4},
+		{object.NewZeroDivisionError(), 1},
+	}
+
+	for _, tt := range tests {
+		if status := ExitStatus(tt.err); status != tt.status {
+			t.Logf("Expected exit status of %v to be %d, got %d\n", tt.err, tt.status, status)
+			t.Fail()
+		}
+	}
+}
+
+func TestInterpretersAreIsolated(t *testing.T) {
+	first, second := New(), New()
+	if _, err := first.Interpret("$name = \"first\"; x = 1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := second.Interpret("$name = \"second\""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := second.Interpret("x"); err == nil {
+		t.Logf("Expected local variables not to leak into another interpreter")
+		t.Fail()
+	}
+
+	if _, err := first.Interpret("LIMIT = 3; class Config; def value; [:first, LIMIT]; end; end"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := second.Interpret("class Config; def value; [:second, 1]; end; end"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value, err := first.Interpret("Config.new.value"); err != nil || value.Inspect() != "[:first, 3]" {
+		t.Logf("Expected classes not to be reopened by another interpreter, got %v, %v", value, err)
+		t.Fail()
+	}
+	if value, err := second.Interpret("Object.const_defined?(:LIMIT)"); err != nil || value.Inspect() != "false" {
+		t.Logf("Expected constants not to leak into another interpreter, got %v, %v", value, err)
+		t.Fail()
+	}
+
+	results := make(chan string, 2)
+	for _, i := range []Interpreter{first, second} {
+		go func(i Interpreter) {
+			out, err := i.Interpret("$count = 0; 1000.times { $count = $count + 1 }; $count.to_s + $name")
+			if err != nil {
+				results <- err.Error()
+				return
+			}
+			results <- out.Inspect()
+		}(i)
+	}
+	got := []string{<-results, <-results}
+	sort.Strings(got)
+	expected := []string{"1000first", "1000second"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Logf("Expected results %v, got %v", expected, got)
+		t.Fail()
+	}
+
+	threads := make(chan string, 4)
+	for n := 0; n < 4; n++ {
+		go func(i Interpreter) {
+			out, err := i.Interpret("t = Thread.new { 100.times { Thread.pass }; 1 }; [t.value, Thread.current == Thread.main]")
+			if err != nil {
+				threads <- err.Error()
+				return
+			}
+			threads <- out.Inspect()
+		}(New())
+	}
+	for n := 0; n < 4; n++ {
+		if got := <-threads; got != "[1, true]" {
+			t.Logf("Expected every interpreter to run its own threads, got %s", got)
+			t.Fail()
+		}
+	}
+
+	tags := make(chan string, 8)
+	for n := 0; n < 8; n++ {
+		go func(n int) {
+			input := fmt.Sprintf("class String; def tag; \"%d\"; end; end; 100.times { Thread.pass }; \"-\".tag", n)
+			out, err := New().Interpret(input)
+			if err != nil {
+				tags <- err.Error()
+				return
+			}
+			if out.Inspect() != fmt.Sprint(n) {
+				tags <- fmt.Sprintf("interpreter %d got %s", n, out.Inspect())
+				return
+			}
+			tags <- ""
+		}(n)
+	}
+	for n := 0; n < 8; n++ {
+		if got := <-tags; got != "" {
+			t.Logf("Expected builtin classes not to be reopened by another interpreter, %s", got)
+			t.Fail()
+		}
+	}
+	if _, err := first.Interpret("\"-\".tag"); err == nil {
+		t.Logf("Expected methods added to builtin classes not to leak into another interpreter")
+		t.Fail()
+	}
+}
+
+func TestInterpreterInterrupt(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"sleep", "sleep"},
+		{"Queue#pop", "q = Queue.new; Thread.new { sleep }; q.pop"},
+		{"Thread#join", "Thread.new { sleep }.join"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interrupted, other := New(), New()
+			results := make(chan error, 2)
+			go func() {
+				_, err := interrupted.Interpret(tt.input)
+				results <- err
+			}()
+			go func() {
+				out, err := other.Interpret("sleep 0.1; :woke")
+				if err == nil && out.Inspect() != ":woke" {
+					err = errors.New(out.Inspect())
+				}
+				results <- err
+			}()
+			time.Sleep(20 * time.Millisecond)
+			interrupted.Interrupt()
+
+			if err := <-results; !errors.As(err, new(*object.Interrupt)) {
+				t.Logf("Expected the interrupted program to raise an Interrupt, got %T:%v", err, err)
+				t.Fail()
+			}
+			if err := <-results; err != nil {
+				t.Logf("Expected other interpreters to keep running, got %T:%v", err, err)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestInterpreterWithLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   evaluator.Limits
+		input    string
+		resource string
+	}{
+		{"operations", evaluator.Limits{Operations: 100}, "x = 0; while true; x = x + 1; end", "operation"},
+		{"call depth", evaluator.Limits{CallDepth: 50}, "def down(n); down(n + 1); end; down(0)", "call depth"},
+		{"objects", evaluator.Limits{Objects: 100}, "a = []; while true; a << \"x\"; end", "object"},
+		{"objects by new", evaluator.Limits{Objects: 10}, "a = []; while true; a << Object.new; end", "object"},
+		{"time", evaluator.Limits{Duration: 10 * time.Millisecond}, "while true; end", "time"},
+		{"time in sleep", evaluator.Limits{Duration: 50 * time.Millisecond}, "sleep 2; :woke", "time"},
+		{"time rescued", evaluator.Limits{Duration: 50 * time.Millisecond}, "begin; sleep 2; rescue Exception; end; :woke", "time"},
+		{"rescued", evaluator.Limits{Operations: 100}, "begin; while true; end; rescue Exception; end; while true; end", "operation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(WithLimits(tt.limits)).Interpret(tt.input)
+
+			limitErr, ok := err.(*object.ResourceLimitError)
+			if !ok {
+				t.Fatalf("Expected *object.ResourceLimitError, got %T (%v)", err, err)
+			}
+			if limitErr.Resource != tt.resource {
+				t.Logf("Expected the %s limit to be exceeded, got %s", tt.resource, limitErr.Resource)
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("objects in the vm", func(t *testing.T) {
+		for _, input := range []string{"a = []; while true; a << [1]; end", "x = nil; while true; x = Object.new; end"} {
+			_, err := New(WithVM(), WithLimits(evaluator.Limits{Objects: 10})).Interpret(input)
+
+			limitErr, ok := err.(*object.ResourceLimitError)
+			if !ok || limitErr.Resource != "object" {
+				t.Logf("Expected %q to exceed the object limit, got %T (%v)", input, err, err)
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		i := New(WithLimits(evaluator.Limits{Operations: 100, CallDepth: 10, Objects: 10}))
+		for n := 0; n < 3; n++ {
+			out, err := i.Interpret("x = 0; 10.times { x = x + 1 }; [x]")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if out.Inspect() != "[10]" {
+				t.Logf("Expected [10], got %s", out.Inspect())
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestInterpreterCall(t *testing.T) {
+	i := New(WithLimits(evaluator.Limits{Operations: 1000}))
+	block, err := i.Interpret("def add(a, b); a + b; end; def spin; while true; end; end; lambda { |x| x * 2 }")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sum, err := i.Call("add", object.NewInteger(1), object.NewInteger(2))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if sum.Inspect() != "3" {
+		t.Logf("Expected 3, got %s", sum.Inspect())
+		t.Fail()
+	}
+
+	doubled, err := i.Send(block, "call", object.NewInteger(21))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if doubled.Inspect() != "42" {
+		t.Logf("Expected 42, got %s", doubled.Inspect())
+		t.Fail()
+	}
+
+	if _, err := i.Call("missing"); err == nil {
+		t.Logf("Expected NoMethodError, got nil")
+		t.Fail()
+	}
+
+	if _, err := i.Call("spin"); err == nil {
+		t.Logf("Expected the operation limit to be exceeded, got nil")
+		t.Fail()
+	}
 }
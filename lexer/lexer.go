@@ -3,6 +3,8 @@ package lexer
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -28,21 +30,39 @@ type StateFn func(*Lexer) StateFn
 // New returns a Lexer instance ready to process the given input.
 func New(input string) *Lexer {
 	l := &Lexer{
-		input:  input,
-		state:  startLexer,
-		tokens: make(chan token.Token, 2), // Two token sufficient.
+		input:         input,
+		state:         startLexer,
+		tokens:        make(chan token.Token, 2), // Two token sufficient.
+		magicComments: make(map[string]string),
 	}
 	return l
 }
 
 // Lexer is the engine to process input and emit Tokens
 type Lexer struct {
-	input  string           // the string being scanned.
-	state  StateFn          // the next lexing function to enter
-	pos    int              // current position in the input.
-	start  int              // start position of this item.
-	width  int              // width of last rune read from input.
-	tokens chan token.Token // channel of scanned tokens.
+	input         string            // the string being scanned.
+	state         StateFn           // the next lexing function to enter
+	pos           int               // current position in the input.
+	start         int               // start position of this item.
+	width         int               // width of last rune read from input.
+	tokens        chan token.Token  // channel of scanned tokens.
+	seenToken     bool              // whether a token other than a newline was emitted
+	lastToken     token.Type        // the type of the last emitted token
+	magicComments map[string]string // magic comments found before the first token
+	data          *string           // the content after the __END__ marker
+	line          int               // number of lines before lineEnd
+	lineEnd       int               // position up to which lines are counted
+	comments      []Comment         // the comments skipped so far
+	tokenStart    int               // the position the current token starts at, including delimiters
+	startTaken    bool              // whether tokenStart was used for an emitted token
+	offsets       []int             // the start positions of the emitted tokens
+}
+
+// A Comment is a comment skipped by the Lexer, either a line comment or an
+// embedded document between `=begin` and `=end`
+type Comment struct {
+	Pos  int    // the position of the comment within the input
+	Text string // the comment including its delimiters, up to the line end
 }
 
 // NextToken will return the next token processed from the lexer.
@@ -71,12 +91,74 @@ func (l *Lexer) HasNext() bool {
 	return l.state != nil
 }
 
+// MagicComments returns the magic comments found in the leading comment
+// lines of the input, like `# frozen_string_literal: true`. The keys are
+// normalized to lower case with dashes replaced by underscores.
+//
+// As magic comments are only recognized before the first token, the result
+// is complete as soon as the first non newline token was returned.
+// Input returns the input the Lexer scans
+func (l *Lexer) Input() string {
+	return l.input
+}
+
+func (l *Lexer) MagicComments() map[string]string {
+	return l.magicComments
+}
+
+// Offsets returns the positions within the input the tokens emitted so far
+// start at, in order. Unlike the positions of the tokens they include the
+// delimiters which are not part of the literal, like the opening quote of a
+// string.
+func (l *Lexer) Offsets() []int {
+	return l.offsets
+}
+
+// Comments returns the comments skipped so far, in the order of their
+// position. It is complete once token.EOF was returned.
+func (l *Lexer) Comments() []Comment {
+	return l.comments
+}
+
+// Data returns the content following the `__END__` marker and true if the
+// input contains such a marker. Otherwise it returns an empty string and
+// false.
+//
+// The result is only complete after the lexer returned token.EOF.
+func (l *Lexer) Data() (string, bool) {
+	if l.data == nil {
+		return "", false
+	}
+	return *l.data, true
+}
+
 // emit passes a token back to the client.
 func (l *Lexer) emit(t token.Type) {
-	l.tokens <- token.NewToken(t, l.input[l.start:l.pos], l.start)
+	if t != token.NEWLINE {
+		l.seenToken = true
+	}
+	l.lastToken = t
+	l.tokens <- l.newToken(t, l.input[l.start:l.pos])
 	l.start = l.pos
 }
 
+// newToken returns a token of type t starting at the current start position
+func (l *Lexer) newToken(t token.Type, literal string) token.Token {
+	l.line += strings.Count(l.input[l.lineEnd:l.start], "\n")
+	l.lineEnd = l.start
+	tok := token.NewToken(t, literal, l.start)
+	tok.Line = l.line + 1
+	lineStart := strings.LastIndexByte(l.input[:l.start], '\n') + 1
+	tok.Column = utf8.RuneCountInString(l.input[lineStart:l.start]) + 1
+	offset := l.start
+	if !l.startTaken && l.tokenStart < offset {
+		offset = l.tokenStart
+	}
+	l.startTaken = true
+	l.offsets = append(l.offsets, offset)
+	return tok
+}
+
 // next returns the next rune in the input.
 func (l *Lexer) next() rune {
 	if l.pos >= len(l.input) {
@@ -111,11 +193,12 @@ func (l *Lexer) peek() rune {
 // error returns an error token and terminates the scan by passing
 // back a nil pointer that will be the next state, terminating l.run.
 func (l *Lexer) errorf(format string, args ...interface{}) StateFn {
-	l.tokens <- token.NewToken(token.ILLEGAL, fmt.Sprintf(format, args...), l.start)
+	l.tokens <- l.newToken(token.ILLEGAL, fmt.Sprintf(format, args...))
 	return nil
 }
 
 func startLexer(l *Lexer) StateFn {
+	l.tokenStart, l.startTaken = l.start, false
 	r := l.next()
 	if isWhitespace(r) {
 		l.ignore()
@@ -123,19 +206,92 @@ func startLexer(l *Lexer) StateFn {
 	}
 	switch r {
 	case '\n':
+		if continuesWithLeadingDot(l.input[l.pos:]) {
+			l.ignore()
+			return startLexer
+		}
 		l.emit(token.NEWLINE)
 		return startLexer
+	case '\\':
+		rest := l.input[l.pos:]
+		if !strings.HasPrefix(rest, "\n") && !strings.HasPrefix(rest, "\r\n") {
+			return l.errorf("Illegal character at %d: '%c'", l.start, r)
+		}
+		l.pos += strings.IndexByte(rest, '\n') + 1
+		l.ignore()
+		return startLexer
 	case '"':
 		return lexString
+	case '`':
+		return lexCommand
+	case '#':
+		return lexComment
+	case '?':
+		return lexCharacter
+	case '$':
+		if p := l.peek(); p == '!' || p == '@' || p == '?' || p == '~' {
+			// the special variables holding the exception being handled,
+			// its backtrace, the status of the last child process and the
+			// last match
+			l.next()
+			l.emit(token.IDENT)
+			return startLexer
+		}
+		if isDigit(l.peek()) {
+			// the groups of the last match, like $1
+			for isDigit(l.peek()) {
+				l.next()
+			}
+			l.emit(token.IDENT)
+			return startLexer
+		}
+		if !isLetter(l.peek()) {
+			return l.errorf("Illegal character at %d: '%c'", l.start, r)
+		}
+		return lexIdentifier
 	case ':':
-		return lexSymbol
+		if l.peek() == ':' {
+			l.next()
+			l.emit(token.SCOPE)
+			return startLexer
+		}
+		if isLetter(l.peek()) || isVariableSymbol(l.input[l.pos:]) {
+			return lexSymbol
+		}
+		if operator := operatorSymbolPrefix(l.input[l.pos:]); operator != "" {
+			l.ignore()
+			l.pos += len(operator)
+			l.emit(token.SYMBOL)
+			return startLexer
+		}
+		l.emit(token.COLON)
+		return startLexer
 	case '.':
-		l.emit(token.DOT)
+		if l.peek() != '.' {
+			l.emit(token.DOT)
+			return startLexer
+		}
+		l.next()
+		if l.peek() == '.' {
+			l.next()
+			l.emit(token.DOT3)
+		} else {
+			l.emit(token.DOT2)
+		}
 		return startLexer
 	case '=':
+		if l.atLineStart() && isCommentStart(l.input[l.start:], "=begin") {
+			return lexMultilineComment
+		}
 		if l.peek() == '=' {
 			l.next()
 			l.emit(token.EQ)
+		} else if l.peek() == '~' {
+			l.next()
+			l.emit(token.MATCH)
+		} else if l.peek() == '>' {
+			l.next()
+			l.emit(token.HASHROCKET)
 		} else {
 			l.emit(token.ASSIGN)
 		}
@@ -150,21 +306,60 @@ func startLexer(l *Lexer) StateFn {
 		if l.peek() == '=' {
 			l.next()
 			l.emit(token.NOTEQ)
+		} else if l.peek() == '~' {
+			l.next()
+			l.emit(token.NOTMATCH)
 		} else {
 			l.emit(token.BANG)
 		}
 		return startLexer
 	case '/':
+		if l.startsLiteral() {
+			return lexRegex
+		}
 		l.emit(token.SLASH)
 		return startLexer
 	case '*':
-		l.emit(token.ASTERISK)
+		if l.peek() == '*' {
+			l.next()
+			l.emit(token.POW)
+		} else {
+			l.emit(token.ASTERISK)
+		}
+		return startLexer
+	case '%':
+		if l.peek() == 'x' && isPercentDelimiter(l.input[l.pos+1:]) && l.startsLiteral() {
+			return lexPercentCommand
+		}
+		l.emit(token.MODULO)
+		return startLexer
+	case '&':
+		l.emit(token.AMP)
+		return startLexer
+	case '^':
+		l.emit(token.CARET)
+		return startLexer
+	case '~':
+		l.emit(token.TILDE)
 		return startLexer
 	case '<':
-		l.emit(token.LT)
+		if strings.HasPrefix(l.input[l.pos:], "=>") {
+			l.pos += len("=>")
+			l.emit(token.SPACESHIP)
+		} else if strings.HasPrefix(l.input[l.pos:], "<") {
+			l.pos += len("<")
+			l.emit(token.LSHIFT)
+		} else {
+			l.emit(token.LT)
+		}
 		return startLexer
 	case '>':
-		l.emit(token.GT)
+		if l.peek() == '>' {
+			l.next()
+			l.emit(token.RSHIFT)
+		} else {
+			l.emit(token.GT)
+		}
 		return startLexer
 	case '(':
 		l.emit(token.LPAREN)
@@ -187,6 +382,9 @@ func startLexer(l *Lexer) StateFn {
 	case ',':
 		l.emit(token.COMMA)
 		return startLexer
+	case '|':
+		l.emit(token.PIPE)
+		return startLexer
 	case ';':
 		l.emit(token.SEMICOLON)
 		return startLexer
@@ -212,26 +410,165 @@ func lexIdentifier(l *Lexer) StateFn {
 	}
 	l.backup()
 	literal := l.input[l.start:l.pos]
+	if literal == "__END__" && l.atLineStart() && l.atLineEnd() {
+		return lexData
+	}
+	if l.lastToken == token.DOT {
+		// method names following a dot are never keywords, like in `x.then`
+		l.emit(token.IDENT)
+		return startLexer
+	}
 	l.emit(token.LookupIdent(literal))
 	return startLexer
 }
 
+// lexData stores everything after the `__END__` marker line as data and
+// ends the input at the marker.
+func lexData(l *Lexer) StateFn {
+	rest := l.input[l.pos:]
+	if strings.HasPrefix(rest, "\r\n") {
+		rest = rest[2:]
+	} else if strings.HasPrefix(rest, "\n") {
+		rest = rest[1:]
+	}
+	l.data = &rest
+	l.input = l.input[:l.start]
+	l.pos = l.start
+	return startLexer
+}
+
 func lexDigit(l *Lexer) StateFn {
+	isValidDigit := isDigit
+	prefixed := false
+	if l.input[l.start] == '0' {
+		prefixed = true
+		switch l.peek() {
+		case 'x', 'X':
+			isValidDigit = isHexDigit
+		case 'b', 'B':
+			isValidDigit = isBinaryDigit
+		case 'o', 'O':
+			isValidDigit = isOctalDigit
+		case 'd', 'D':
+			isValidDigit = isDigit
+		default:
+			prefixed = false
+		}
+		if prefixed {
+			l.next()
+			if !isValidDigit(l.peek()) {
+				return l.errorf("numeric literal without digits")
+			}
+		}
+	}
+	l.acceptDigits(isValidDigit)
+	if prefixed {
+		l.emit(token.INT)
+		return startLexer
+	}
+	isFloat := false
+	if rest := l.input[l.pos:]; len(rest) > 1 && rest[0] == '.' && isDigit(rune(rest[1])) {
+		l.next()
+		l.acceptDigits(isDigit)
+		isFloat = true
+	}
+	if hasExponent(l.input[l.pos:]) {
+		l.next()
+		if r := l.peek(); r == '+' || r == '-' {
+			l.next()
+		}
+		l.acceptDigits(isDigit)
+		isFloat = true
+	}
+	if isFloat {
+		l.emit(token.FLOAT)
+	} else {
+		l.emit(token.INT)
+	}
+	return startLexer
+}
+
+// acceptDigits consumes all runes which are valid digits or underscores
+func (l *Lexer) acceptDigits(isValidDigit func(rune) bool) {
 	r := l.next()
-	for isDigit(r) {
+	for isValidDigit(r) || r == '_' {
 		r = l.next()
 	}
 	l.backup()
-	l.emit(token.INT)
+}
+
+// hasExponent reports whether input starts with a float exponent like `e10`
+// or `E-3`
+func hasExponent(input string) bool {
+	if len(input) < 2 || (input[0] != 'e' && input[0] != 'E') {
+		return false
+	}
+	if input[1] == '+' || input[1] == '-' {
+		return len(input) > 2 && isDigit(rune(input[2]))
+	}
+	return isDigit(rune(input[1]))
+}
+
+// lexCharacter scans a character literal like `?a` or `?\n`
+func lexCharacter(l *Lexer) StateFn {
+	r := l.next()
+	switch {
+	case r == '\\':
+		if !l.acceptEscape() {
+			return l.errorf("Invalid escape character syntax at %d", l.start)
+		}
+	case r == eof || r == '\n' || isWhitespace(r):
+		return l.errorf("Illegal character at %d: '%c'", l.start, '?')
+	}
+	if r := l.peek(); isLetter(r) || isDigit(r) {
+		return l.errorf("Illegal character at %d: '%c'", l.start, '?')
+	}
+	l.emit(token.CHAR)
 	return startLexer
 }
 
+// acceptEscape consumes an escape sequence following a backslash. It
+// returns false if the sequence is incomplete.
+func (l *Lexer) acceptEscape() bool {
+	switch r := l.next(); {
+	case r == eof:
+		return false
+	case r == 'u' && l.peek() == '{':
+		end := strings.IndexByte(l.input[l.pos:], '}')
+		if end < 0 {
+			return false
+		}
+		l.pos += end + 1
+	case r == 'u':
+		return l.acceptRun(isHexDigit, 4) == 4
+	case r == 'x':
+		return l.acceptRun(isHexDigit, 2) > 0
+	case isOctalDigit(r):
+		l.acceptRun(isOctalDigit, 2)
+	}
+	return true
+}
+
+// acceptRun consumes up to max runes which are valid and returns the number
+// of consumed runes
+func (l *Lexer) acceptRun(valid func(rune) bool, max int) int {
+	n := 0
+	for n < max && valid(l.peek()) {
+		l.next()
+		n++
+	}
+	return n
+}
+
 func lexString(l *Lexer) StateFn {
 	l.ignore()
-	r := l.next()
-
-	for r != '"' {
-		r = l.next()
+	for r := l.next(); r != '"'; r = l.next() {
+		if r == '\\' {
+			r = l.next()
+		}
+		if r == eof {
+			return l.errorf("unterminated string meets end of file")
+		}
 	}
 	l.backup()
 	l.emit(token.STRING)
@@ -240,26 +577,307 @@ func lexString(l *Lexer) StateFn {
 	return startLexer
 }
 
+// lexCommand lexes a command substitution in backticks. The emitted literal
+// excludes the backticks.
+func lexCommand(l *Lexer) StateFn {
+	l.ignore()
+	for r := l.next(); r != '`'; r = l.next() {
+		if r == '\\' {
+			r = l.next()
+		}
+		if r == eof {
+			return l.errorf("unterminated command meets end of file")
+		}
+	}
+	l.backup()
+	l.emit(token.XSTRING)
+	l.next()
+	l.ignore()
+	return startLexer
+}
+
+// percentDelimiters maps the opening delimiters of percent literals which
+// nest to their closing ones
+var percentDelimiters = map[rune]rune{'(': ')', '[': ']', '{': '}', '<': '>'}
+
+// isPercentDelimiter reports whether input starts with a delimiter of a
+// percent literal, which is a bracket or any other punctuation character
+func isPercentDelimiter(input string) bool {
+	r, _ := utf8.DecodeRuneInString(input)
+	if _, ok := percentDelimiters[r]; ok {
+		return true
+	}
+	return r < utf8.RuneSelf && unicode.IsPunct(r) && r != '_'
+}
+
+// lexPercentCommand lexes a command substitution like `%x(ls -l)`. Brackets
+// nest within the command. The emitted literal excludes the delimiters.
+func lexPercentCommand(l *Lexer) StateFn {
+	l.next()
+	open := l.next()
+	closing, nests := percentDelimiters[open]
+	if !nests {
+		closing = open
+	}
+	l.ignore()
+	depth := 0
+	for r := l.next(); r != closing || depth > 0; r = l.next() {
+		switch {
+		case r == '\\':
+			l.next()
+		case r == eof:
+			return l.errorf("unterminated command meets end of file")
+		case nests && r == open:
+			depth++
+		case nests && r == closing:
+			depth--
+		}
+	}
+	l.backup()
+	l.emit(token.XSTRING)
+	l.next()
+	l.ignore()
+	return startLexer
+}
+
+// regexPrecedingTokens contains the tokens after which a slash starts a
+// regex literal rather than being a division operator
+var regexPrecedingTokens = map[token.Type]bool{
+	token.ILLEGAL:    true, // start of input
+	token.NEWLINE:    true,
+	token.SEMICOLON:  true,
+	token.LPAREN:     true,
+	token.LBRACKET:   true,
+	token.LBRACE:     true,
+	token.PIPE:       true,
+	token.COMMA:      true,
+	token.ASSIGN:     true,
+	token.EQ:         true,
+	token.NOTEQ:      true,
+	token.MATCH:      true,
+	token.NOTMATCH:   true,
+	token.HASHROCKET: true,
+	token.IF:         true,
+	token.THEN:       true,
+	token.ELSE:       true,
+	token.WHEN:       true,
+	token.WHILE:      true,
+	token.DO:         true,
+	token.RETURN:     true,
+	token.BREAK:      true,
+	token.NEXT:       true,
+}
+
+// startsLiteral reports whether the slash or percent sign just read starts a
+// literal rather than being an operator. After an identifier one preceded by
+// whitespace but not followed by whitespace is treated as the start of a
+// literal argument, e.g. `split /,/`.
+func (l *Lexer) startsLiteral() bool {
+	if regexPrecedingTokens[l.lastToken] {
+		return true
+	}
+	if l.lastToken != token.IDENT || l.start == 0 {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(l.input[:l.start])
+	next := l.peek()
+	return isWhitespace(before) && !unicode.IsSpace(next) && next != eof
+}
+
+// lexRegex lexes a regex literal. The emitted literal includes the
+// delimiting slashes and the trailing option letters.
+func lexRegex(l *Lexer) StateFn {
+	for r := l.next(); r != '/'; r = l.next() {
+		if r == '\\' {
+			r = l.next()
+		}
+		if r == eof {
+			return l.errorf("unterminated regexp meets end of file")
+		}
+	}
+	for r := l.peek(); r == 'i' || r == 'm' || r == 'x'; r = l.peek() {
+		l.next()
+	}
+	l.emit(token.REGEX)
+	return startLexer
+}
+
+func lexComment(l *Lexer) StateFn {
+	r := l.next()
+	for r != '\n' && r != eof {
+		r = l.next()
+	}
+	l.backup()
+	if !l.seenToken {
+		l.parseMagicComment(l.input[l.start+1 : l.pos])
+	}
+	l.comments = append(l.comments, Comment{Pos: l.start, Text: l.input[l.start:l.pos]})
+	l.ignore()
+	return startLexer
+}
+
+func lexMultilineComment(l *Lexer) StateFn {
+	for {
+		r := l.next()
+		if r == eof {
+			return l.errorf("embedded document meets end of file")
+		}
+		if r == '\n' && isCommentStart(l.input[l.pos:], "=end") {
+			break
+		}
+	}
+	for r := l.next(); r != '\n' && r != eof; r = l.next() {
+	}
+	l.backup()
+	l.comments = append(l.comments, Comment{Pos: l.start, Text: l.input[l.start:l.pos]})
+	l.ignore()
+	return startLexer
+}
+
+// magicCommentKeys contains all keys recognized as magic comments
+var magicCommentKeys = map[string]bool{
+	"coding":                   true,
+	"encoding":                 true,
+	"frozen_string_literal":    true,
+	"warn_indent":              true,
+	"shareable_constant_value": true,
+}
+
+var (
+	magicCommentPattern      = regexp.MustCompile(`^\s*([\w-]+)\s*:\s*(\S+)\s*$`)
+	emacsMagicCommentPattern = regexp.MustCompile(`-\*-(.*)-\*-`)
+)
+
+// parseMagicComment stores the magic comments found in comment. It supports
+// the plain form `key: value` as well as the emacs form
+// `-*- key: value; other_key: value -*-`
+func (l *Lexer) parseMagicComment(comment string) {
+	candidates := []string{comment}
+	if match := emacsMagicCommentPattern.FindStringSubmatch(comment); match != nil {
+		candidates = strings.Split(match[1], ";")
+	}
+	for _, candidate := range candidates {
+		match := magicCommentPattern.FindStringSubmatch(candidate)
+		if match == nil {
+			continue
+		}
+		key := strings.Replace(strings.ToLower(match[1]), "-", "_", -1)
+		if magicCommentKeys[key] {
+			l.magicComments[key] = match[2]
+		}
+	}
+}
+
+// atLineStart reports whether the current item starts at the beginning of a
+// line.
+func (l *Lexer) atLineStart() bool {
+	return l.start == 0 || l.input[l.start-1] == '\n'
+}
+
+// continuesWithLeadingDot reports whether the next line which is neither
+// blank nor a comment starts with a method call dot, e.g. `.map`.
+func continuesWithLeadingDot(input string) bool {
+	for {
+		line := strings.TrimLeft(input, " \t\r")
+		switch {
+		case strings.HasPrefix(line, "\n"):
+			input = line[1:]
+		case strings.HasPrefix(line, "#"):
+			end := strings.IndexByte(line, '\n')
+			if end < 0 {
+				return false
+			}
+			input = line[end+1:]
+		default:
+			return strings.HasPrefix(line, ".") && !strings.HasPrefix(line, "..")
+		}
+	}
+}
+
+// atLineEnd reports whether the current position is at the end of a line.
+func (l *Lexer) atLineEnd() bool {
+	rest := l.input[l.pos:]
+	return rest == "" || rest[0] == '\n' || strings.HasPrefix(rest, "\r\n")
+}
+
+// isCommentStart reports whether input starts with the given comment
+// delimiter followed by whitespace or the end of the input.
+func isCommentStart(input, delimiter string) bool {
+	if !strings.HasPrefix(input, delimiter) {
+		return false
+	}
+	rest := input[len(delimiter):]
+	return rest == "" || unicode.IsSpace(rune(rest[0]))
+}
+
+// isVariableSymbol reports whether input starts with an instance or global
+// variable name, like `@foo` or `$foo`.
+func isVariableSymbol(input string) bool {
+	if len(input) < 2 || input[0] != '@' && input[0] != '$' {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(input[1:])
+	return isLetter(r)
+}
+
 func lexSymbol(l *Lexer) StateFn {
 	l.ignore()
 	r := l.next()
+	if r == '@' || r == '$' {
+		r = l.next()
+	}
 
 	for isLetter(r) || isDigit(r) {
 		r = l.next()
 	}
-	l.backup()
+	if r != '?' && r != '!' {
+		l.backup()
+	}
 	l.emit(token.SYMBOL)
 	return startLexer
 }
 
+// operatorSymbols contains all operator method names which can be used as
+// symbol, longest first
+var operatorSymbols = []string{
+	"[]=", "<=>", "===",
+	"[]", "==", "=~", "!=", "!~", "<<", ">>", "<=", ">=", "**", "+@", "-@",
+	"+", "-", "*", "/", "%", "<", ">", "!", "&", "|", "^", "~",
+}
+
+// operatorSymbolPrefix returns the operator symbol at the start of s or an
+// empty string if there is none
+func operatorSymbolPrefix(s string) string {
+	for _, operator := range operatorSymbols {
+		if strings.HasPrefix(s, operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
 func isWhitespace(r rune) bool {
 	return unicode.IsSpace(r) && r != '\n'
 }
 
+// isLetter reports whether r can be part of an identifier. Like MRI, all
+// non-ASCII characters are treated as letters.
 func isLetter(r rune) bool {
-	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_'
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_' || r >= utf8.RuneSelf
 }
 
 func isDigit(r rune) bool {
 	return '0' <= r && r <= '9'
 }
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F'
+}
+
+func isOctalDigit(r rune) bool {
+	return '0' <= r && r <= '7'
+}
+
+func isBinaryDigit(r rune) bool {
+	return r == '0' || r == '1'
+}
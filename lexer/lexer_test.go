@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/goruby/goruby/token"
@@ -41,6 +42,32 @@ end
 nil
 require
 self
+{name: 1, "a" => :b}
+x = 1 # trailing comment
+# full line comment
+=begin
+ignored = 2
+=end
+y
+BEGIN { }
+END { }
+a <=> b <= c
+0xff 0b1_0 0o17 017 1_000
+1.5e10 2E-3 1_234.567_8 1.methods 1e5 1.e
+?a ?\n ?\u00e9 ?\u{1F600} nil?
+begin case when while do break
+"a\"b" été = "\u{1F600}" :ünï
+x << "y"
+a / b =~ /x\/y/i !~ /[a-z]+/
+split /,/ { |m| a/2 }
+s[0..2] + s[1...-1]
+[:+, :<=>, :[], :empty?, :save!]
+a % b ** c & d | e ^ ~f >> 2
+$LOAD_PATH
+$! $@ $~ $12
+:@ivar
+:$global
+x.then
 `
 
 	tests := []struct {
@@ -125,7 +152,6 @@ self
 		{token.STRING, "foo bar"},
 		{token.NEWLINE, "\n"},
 		{token.SYMBOL, "sym"},
-		{token.NEWLINE, "\n"},
 		{token.DOT, "."},
 		{token.NEWLINE, "\n"},
 		{token.NEWLINE, "\n"},
@@ -152,6 +178,155 @@ self
 		{token.NEWLINE, "\n"},
 		{token.SELF, "self"},
 		{token.NEWLINE, "\n"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "name"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.STRING, "a"},
+		{token.HASHROCKET, "=>"},
+		{token.SYMBOL, "b"},
+		{token.RBRACE, "}"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.NEWLINE, "\n"},
+		{token.NEWLINE, "\n"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "y"},
+		{token.NEWLINE, "\n"},
+		{token.BEGIN_BLOCK, "BEGIN"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.NEWLINE, "\n"},
+		{token.END_BLOCK, "END"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "a"},
+		{token.SPACESHIP, "<=>"},
+		{token.IDENT, "b"},
+		{token.LT, "<"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "c"},
+		{token.NEWLINE, "\n"},
+		{token.INT, "0xff"},
+		{token.INT, "0b1_0"},
+		{token.INT, "0o17"},
+		{token.INT, "017"},
+		{token.INT, "1_000"},
+		{token.NEWLINE, "\n"},
+		{token.FLOAT, "1.5e10"},
+		{token.FLOAT, "2E-3"},
+		{token.FLOAT, "1_234.567_8"},
+		{token.INT, "1"},
+		{token.DOT, "."},
+		{token.IDENT, "methods"},
+		{token.FLOAT, "1e5"},
+		{token.INT, "1"},
+		{token.DOT, "."},
+		{token.IDENT, "e"},
+		{token.NEWLINE, "\n"},
+		{token.CHAR, "?a"},
+		{token.CHAR, "?\\n"},
+		{token.CHAR, "?\\u00e9"},
+		{token.CHAR, "?\\u{1F600}"},
+		{token.IDENT, "nil?"},
+		{token.NEWLINE, "\n"},
+		{token.BEGIN, "begin"},
+		{token.CASE, "case"},
+		{token.WHEN, "when"},
+		{token.WHILE, "while"},
+		{token.DO, "do"},
+		{token.BREAK, "break"},
+		{token.NEWLINE, "\n"},
+		{token.STRING, `a\"b`},
+		{token.IDENT, "été"},
+		{token.ASSIGN, "="},
+		{token.STRING, `\u{1F600}`},
+		{token.SYMBOL, "ünï"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "x"},
+		{token.LSHIFT, "<<"},
+		{token.STRING, "y"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "a"},
+		{token.SLASH, "/"},
+		{token.IDENT, "b"},
+		{token.MATCH, "=~"},
+		{token.REGEX, `/x\/y/i`},
+		{token.NOTMATCH, "!~"},
+		{token.REGEX, "/[a-z]+/"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "split"},
+		{token.REGEX, "/,/"},
+		{token.LBRACE, "{"},
+		{token.PIPE, "|"},
+		{token.IDENT, "m"},
+		{token.PIPE, "|"},
+		{token.IDENT, "a"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.RBRACE, "}"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "s"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.DOT2, ".."},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.PLUS, "+"},
+		{token.IDENT, "s"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.DOT3, "..."},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.RBRACKET, "]"},
+		{token.NEWLINE, "\n"},
+		{token.LBRACKET, "["},
+		{token.SYMBOL, "+"},
+		{token.COMMA, ","},
+		{token.SYMBOL, "<=>"},
+		{token.COMMA, ","},
+		{token.SYMBOL, "[]"},
+		{token.COMMA, ","},
+		{token.SYMBOL, "empty?"},
+		{token.COMMA, ","},
+		{token.SYMBOL, "save!"},
+		{token.RBRACKET, "]"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "a"},
+		{token.MODULO, "%"},
+		{token.IDENT, "b"},
+		{token.POW, "**"},
+		{token.IDENT, "c"},
+		{token.AMP, "&"},
+		{token.IDENT, "d"},
+		{token.PIPE, "|"},
+		{token.IDENT, "e"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "f"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "2"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "$LOAD_PATH"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "$!"},
+		{token.IDENT, "$@"},
+		{token.IDENT, "$~"},
+		{token.IDENT, "$12"},
+		{token.NEWLINE, "\n"},
+		{token.SYMBOL, "@ivar"},
+		{token.NEWLINE, "\n"},
+		{token.SYMBOL, "$global"},
+		{token.NEWLINE, "\n"},
+		{token.IDENT, "x"},
+		{token.DOT, "."},
+		{token.IDENT, "then"},
+		{token.NEWLINE, "\n"},
 		{token.EOF, ""},
 	}
 
@@ -171,3 +346,265 @@ self
 		}
 	}
 }
+
+func TestLexerMagicComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]string
+	}{
+		{
+			"# frozen_string_literal: true\nx = 5",
+			map[string]string{"frozen_string_literal": "true"},
+		},
+		{
+			"#!/usr/bin/env goruby\n# encoding: utf-8\n\n# Frozen-String-Literal: false\nx = 5",
+			map[string]string{"encoding": "utf-8", "frozen_string_literal": "false"},
+		},
+		{
+			"# -*- coding: utf-8; frozen_string_literal: true -*-\n",
+			map[string]string{"coding": "utf-8", "frozen_string_literal": "true"},
+		},
+		{
+			"# TODO: not magic\nx = 5",
+			map[string]string{},
+		},
+		{
+			"x = 5\n# frozen_string_literal: true\n",
+			map[string]string{},
+		},
+		{
+			"=begin\nfrozen_string_literal: true\n=end\n# frozen_string_literal: true\n",
+			map[string]string{"frozen_string_literal": "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		}
+
+		actual := lexer.MagicComments()
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Logf("Expected magic comments to equal %v, got %v\n", tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerComments(t *testing.T) {
+	input := "# first\nx = \"#no\" # second\n=begin\ndoc\n=end\ny"
+	lexer := New(input)
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+	}
+
+	expected := []Comment{
+		{Pos: 0, Text: "# first"},
+		{Pos: 18, Text: "# second"},
+		{Pos: 27, Text: "=begin\ndoc\n=end"},
+	}
+	if actual := lexer.Comments(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected comments %v, got %v", expected, actual)
+	}
+}
+
+func TestLexerOffsets(t *testing.T) {
+	input := "x = \"ab\" + :c\n`ls`"
+	lexer := New(input)
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+	}
+
+	expected := []int{0, 2, 4, 9, 11, 13, 14, 18}
+	if actual := lexer.Offsets(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected offsets %v, got %v", expected, actual)
+	}
+}
+
+func TestLexerLineContinuation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Type
+	}{
+		{"x = 1 +\\\n  2", []token.Type{token.IDENT, token.ASSIGN, token.INT, token.PLUS, token.INT}},
+		{"x = 1 \\\r\n+ 2", []token.Type{token.IDENT, token.ASSIGN, token.INT, token.PLUS, token.INT}},
+		{"foo\n  .bar\n  .baz", []token.Type{token.IDENT, token.DOT, token.IDENT, token.DOT, token.IDENT}},
+		{"foo\n\n  # comment\n  .bar\n", []token.Type{token.IDENT, token.DOT, token.IDENT, token.NEWLINE}},
+		{"foo\n..bar", []token.Type{token.IDENT, token.NEWLINE, token.DOT2, token.IDENT}},
+		{"x \\ y", []token.Type{token.IDENT, token.ILLEGAL}},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		var actual []token.Type
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+			actual = append(actual, tok.Type)
+			if tok.Type == token.ILLEGAL {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Logf("Expected tokens for %q to equal %v, got %v\n", tt.input, tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerScope(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Type
+	}{
+		{"Net::HTTP", []token.Type{token.IDENT, token.SCOPE, token.IDENT}},
+		{"::String", []token.Type{token.SCOPE, token.IDENT}},
+		{"{a: :b}", []token.Type{token.LBRACE, token.IDENT, token.COLON, token.SYMBOL, token.RBRACE}},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		var actual []token.Type
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+			actual = append(actual, tok.Type)
+			if tok.Type == token.ILLEGAL {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Logf("Expected tokens for %q to equal %v, got %v\n", tt.input, tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{"`ls -l`", []token.Token{{Type: token.XSTRING, Literal: "ls -l"}}},
+		{"%x(echo (a))", []token.Token{{Type: token.XSTRING, Literal: "echo (a)"}}},
+		{"%x!date!", []token.Token{{Type: token.XSTRING, Literal: "date"}}},
+		{"x = `pwd`", []token.Token{{Type: token.IDENT, Literal: "x"}, {Type: token.ASSIGN, Literal: "="}, {Type: token.XSTRING, Literal: "pwd"}}},
+		{"a %x", []token.Token{{Type: token.IDENT, Literal: "a"}, {Type: token.MODULO, Literal: "%"}, {Type: token.IDENT, Literal: "x"}}},
+		{"$?", []token.Token{{Type: token.IDENT, Literal: "$?"}}},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		var actual []token.Token
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+			actual = append(actual, token.Token{Type: tok.Type, Literal: tok.Literal})
+			if tok.Type == token.ILLEGAL {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Logf("Expected tokens for %q to equal %v, got %v\n", tt.input, tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerTokenLines(t *testing.T) {
+	input := "x = 1\n\nfoo(\"a\nb\")\n=begin\nc\n=end\ny \\\n  + 2"
+	expected := []int{1, 1, 1, 1, 2, 3, 3, 3, 4, 4, 7, 8, 9, 9}
+
+	lexer := New(input)
+	var actual []int
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		actual = append(actual, tok.Line)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Logf("Expected token lines to equal %v, got %v\n", expected, actual)
+		t.Fail()
+	}
+}
+
+func TestLexerUnterminatedString(t *testing.T) {
+	tests := []string{`"foo`, `"foo\"`, `"foo\`, `/foo`, `x = /foo\/`}
+
+	for _, input := range tests {
+		lexer := New(input)
+
+		tok := lexer.NextToken()
+		for tok.Type != token.EOF && tok.Type != token.ILLEGAL {
+			tok = lexer.NextToken()
+		}
+
+		if tok.Type != token.ILLEGAL {
+			t.Logf("Expected last token for %q to be %s, got %s\n", input, token.ILLEGAL, tok.Type)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerData(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedData string
+		hasData      bool
+		tokens       int
+	}{
+		{"x\n__END__\nfoo\nbar\n", "foo\nbar\n", true, 2},
+		{"x\n__END__", "", true, 2},
+		{"x\r\n__END__\r\nfoo", "foo", true, 2},
+		{"x\n__END__ \nfoo", "", false, 5},
+		{"x = __END__\nfoo", "", false, 5},
+		{"x\n", "", false, 2},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		tokens := 0
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+			tokens++
+		}
+
+		if tokens != tt.tokens {
+			t.Logf("Expected %d tokens before EOF for input %q, got %d\n", tt.tokens, tt.input, tokens)
+			t.Fail()
+		}
+
+		data, ok := lexer.Data()
+		if ok != tt.hasData {
+			t.Logf("Expected data presence for input %q to be %t, got %t\n", tt.input, tt.hasData, ok)
+			t.Fail()
+		}
+		if data != tt.expectedData {
+			t.Logf("Expected data to equal %q, got %q\n", tt.expectedData, data)
+			t.Fail()
+		}
+	}
+}
+
+func TestLexerUnterminatedMultilineComment(t *testing.T) {
+	lexer := New("x\n=begin\nfoo\n")
+
+	tok := lexer.NextToken()
+	for tok.Type != token.EOF && tok.Type != token.ILLEGAL {
+		tok = lexer.NextToken()
+	}
+
+	if tok.Type != token.ILLEGAL {
+		t.Logf("Expected last token to be %s, got %s\n", token.ILLEGAL, tok.Type)
+		t.Fail()
+	}
+}
+
+func TestLexerTokenColumns(t *testing.T) {
+	input := "x = 1\n\tfoo(\"ä\", :b)\n  y"
+	expected := []int{1, 3, 5, 6, 2, 5, 7, 9, 12, 13, 14, 3}
+
+	lexer := New(input)
+	var actual []int
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		actual = append(actual, tok.Column)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected token columns to equal %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,335 @@
+// Package lint reports suspicious constructs within Ruby programs, like
+// variables which are assigned but never read or code following a return.
+// The analyses work on the AST only, so they neither run nor load anything.
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// A Rule names one of the analyses of Check
+type Rule string
+
+// The rules Check knows about
+const (
+	// UnusedVariable reports local variables which are assigned but never
+	// read. Variables prefixed with an underscore are never reported.
+	UnusedVariable Rule = "unused-variable"
+	// UnreachableCode reports statements following a return, break, next
+	// or raise within the same body.
+	UnreachableCode Rule = "unreachable-code"
+	// DuplicateMethod reports methods defined twice within the same class,
+	// module or the top level.
+	DuplicateMethod Rule = "duplicate-method"
+	// AssignmentInCondition reports conditions of if, unless, while and
+	// until which are an assignment, which often is a mistyped comparison.
+	AssignmentInCondition Rule = "assignment-in-condition"
+	// ShadowedBlockParameter reports block parameters which hide a local
+	// variable of the enclosing scope.
+	ShadowedBlockParameter Rule = "shadowed-block-param"
+)
+
+// Rules lists all rules, in the order of the analyses
+var Rules = []Rule{UnusedVariable, UnreachableCode, DuplicateMethod, AssignmentInCondition, ShadowedBlockParameter}
+
+// ParseRules parses a comma separated list of rule names
+func ParseRules(list string) ([]Rule, error) {
+	var rules []Rule
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		rule := Rule(name)
+		if !isRule(rule) {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func isRule(rule Rule) bool {
+	for _, known := range Rules {
+		if known == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// A Warning is a finding of Check
+type Warning struct {
+	Line    int
+	Rule    Rule
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%d: %s (%s)", w.Line, w.Message, w.Rule)
+}
+
+// Check analyses program by the given rules, or by all rules if none are
+// given, and returns the warnings found ordered by their line.
+func Check(program *ast.Program, rules ...Rule) []Warning {
+	if len(rules) == 0 {
+		rules = Rules
+	}
+	c := &checker{rules: make(map[Rule]bool)}
+	for _, rule := range rules {
+		c.rules[rule] = true
+	}
+	c.checkScope(nil, nil, func() {
+		c.checkStatements(program.Statements)
+		c.checkMethods(program.Statements, "")
+		c.walkStatements(program.Statements)
+	})
+	sort.SliceStable(c.warnings, func(i, j int) bool {
+		return c.warnings[i].Line < c.warnings[j].Line
+	})
+	return c.warnings
+}
+
+type checker struct {
+	rules    map[Rule]bool
+	warnings []Warning
+	scope    *scope
+}
+
+func (c *checker) warn(rule Rule, line int, format string, args ...interface{}) {
+	if c.rules[rule] {
+		c.warnings = append(c.warnings, Warning{Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// A scope holds the local variables of a method, a class or module body, a
+// block or the top level. The scopes of blocks have the scope enclosing
+// them as parent, as blocks see its variables.
+type scope struct {
+	parent    *scope
+	variables map[string]*variable
+	order     []*variable
+}
+
+type variable struct {
+	name *ast.Identifier
+	read bool
+	// param is set for parameters, which are never reported as unused
+	param bool
+}
+
+func (s *scope) lookup(name string) (*variable, bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.variables[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) declare(name *ast.Identifier, param bool) {
+	v := &variable{name: name, param: param}
+	s.variables[name.Value] = v
+	s.order = append(s.order, v)
+}
+
+// checkScope runs check within a new scope holding params, and reports the
+// variables of the scope which were never read afterwards
+func (c *checker) checkScope(parent *scope, params []*ast.Identifier, check func()) {
+	outer := c.scope
+	c.scope = &scope{parent: parent, variables: make(map[string]*variable)}
+	for _, param := range params {
+		c.scope.declare(param, true)
+	}
+	check()
+	for _, v := range c.scope.order {
+		if !v.read && !v.param && !strings.HasPrefix(v.name.Value, "_") {
+			c.warn(UnusedVariable, v.name.Token.Line, "assigned but unused variable - %s", v.name.Value)
+		}
+	}
+	c.scope = outer
+}
+
+func (c *checker) walkStatements(statements []ast.Statement) {
+	for _, statement := range statements {
+		c.walk(statement)
+	}
+}
+
+// walk checks node and its children, tracking the local variables they
+// assign and read
+func (c *checker) walk(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.VariableAssignment:
+		c.walk(node.Value)
+		name := node.Name.Value
+		if !object.IsLocalVariableName(name) {
+			return
+		}
+		if _, ok := c.scope.lookup(name); !ok {
+			c.scope.declare(node.Name, false)
+		}
+		return
+	case *ast.Identifier:
+		if v, ok := c.scope.lookup(node.Value); ok {
+			v.read = true
+		}
+		return
+	case *ast.ContextCallExpression:
+		// the name of the method called is no variable
+		if node.Context != nil {
+			c.walk(node.Context)
+		}
+		for _, argument := range node.Arguments {
+			c.walk(argument)
+		}
+		if node.Block != nil {
+			c.walk(node.Block)
+		}
+		return
+	case *ast.FunctionLiteral:
+		c.checkScope(nil, node.Parameters, func() { c.walk(node.Body) })
+		return
+	case *ast.ClassExpression:
+		if node.SuperClass != nil {
+			c.walk(node.SuperClass)
+		}
+		c.checkScope(nil, nil, func() {
+			c.checkMethods(node.Body.Statements, node.Name.Value)
+			c.walk(node.Body)
+		})
+		return
+	case *ast.ModuleExpression:
+		c.checkScope(nil, nil, func() {
+			c.checkMethods(node.Body.Statements, node.Name.Value)
+			c.walk(node.Body)
+		})
+		return
+	case *ast.BlockLiteral:
+		for _, param := range node.Parameters {
+			if _, ok := c.scope.lookup(param.Value); ok {
+				c.warn(ShadowedBlockParameter, param.Token.Line, "shadowing outer local variable - %s", param.Value)
+			}
+		}
+		c.checkScope(c.scope, node.Parameters, func() { c.walk(node.Body) })
+		return
+	case *ast.RescueBlock:
+		for _, class := range node.ExceptionClasses {
+			c.walk(class)
+		}
+		if node.Exception != nil {
+			if _, ok := c.scope.lookup(node.Exception.Value); !ok {
+				c.scope.declare(node.Exception, true)
+			}
+		}
+		c.walk(node.Body)
+		return
+	case *ast.BlockStatement:
+		c.checkStatements(node.Statements)
+	case *ast.IfExpression:
+		c.checkCondition(node.Condition, node.Token.Literal)
+	case *ast.WhileExpression:
+		c.checkCondition(node.Condition, node.Token.Literal)
+	}
+	ast.Inspect(node, func(child ast.Node) bool {
+		if child == node {
+			return true
+		}
+		c.walk(child)
+		return false
+	})
+}
+
+// checkStatements reports the first statement following one which leaves
+// the body, like return
+func (c *checker) checkStatements(statements []ast.Statement) {
+	for i := 0; i+1 < len(statements); i++ {
+		if keyword, ok := leavesBody(statements[i]); ok {
+			c.warn(UnreachableCode, lineOf(statements[i+1]), "statement not reached after %s", keyword)
+			return
+		}
+	}
+}
+
+// leavesBody reports whether statement unconditionally leaves the body it
+// belongs to, along with its keyword
+func leavesBody(statement ast.Statement) (string, bool) {
+	switch statement := statement.(type) {
+	case *ast.ReturnStatement:
+		return "return", true
+	case *ast.BreakStatement:
+		return "break", true
+	case *ast.NextStatement:
+		return "next", true
+	case *ast.ExpressionStatement:
+		switch expression := statement.Expression.(type) {
+		case *ast.Identifier:
+			return "raise", expression.Value == "raise"
+		case *ast.ContextCallExpression:
+			return "raise", expression.Context == nil && expression.Function.Value == "raise"
+		}
+	}
+	return "", false
+}
+
+// checkMethods reports methods defined several times by statements, the
+// body of the class or module named owner
+func (c *checker) checkMethods(statements []ast.Statement, owner string) {
+	defined := make(map[string]int)
+	for _, statement := range statements {
+		expression, ok := statement.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+		method, ok := expression.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			continue
+		}
+		name := method.Name.Value
+		if line, ok := defined[name]; ok {
+			if owner != "" {
+				name = owner + "#" + name
+			}
+			c.warn(DuplicateMethod, method.Token.Line, "method redefined; discarding old %s defined on line %d", name, line)
+		}
+		defined[method.Name.Value] = method.Token.Line
+	}
+}
+
+// checkCondition reports condition if it is an assignment
+func (c *checker) checkCondition(condition ast.Expression, keyword string) {
+	if assignment, ok := condition.(*ast.VariableAssignment); ok {
+		c.warn(AssignmentInCondition, assignment.Name.Token.Line, "assignment to %s in the condition of %s, did you mean ==?", assignment.Name.Value, keyword)
+	}
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// lineOf returns the line of the token of node, or of its first child
+// holding one
+func lineOf(node ast.Node) int {
+	line := 0
+	ast.Inspect(node, func(node ast.Node) bool {
+		if line != 0 {
+			return false
+		}
+		value := reflect.ValueOf(node)
+		if value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Struct {
+			if field := value.FieldByName("Token"); field.IsValid() && field.Type() == tokenType {
+				line = field.Interface().(token.Token).Line
+			}
+		}
+		return line == 0
+	})
+	return line
+}
@@ -0,0 +1,122 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse error for %q: %v", input, err)
+	}
+	return program
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"unused variables",
+			"def foo(a)\nx = 1\ny = 2\n_z = 3\ny\nend\nw = 1\n",
+			[]string{
+				"2: assigned but unused variable - x (unused-variable)",
+				"7: assigned but unused variable - w (unused-variable)",
+			},
+		},
+		{
+			"variables read within blocks",
+			"x = 1\n[1].each { |v| puts v + x }\n[2].each do |v|\ny = v\nend\n",
+			[]string{"4: assigned but unused variable - y (unused-variable)"},
+		},
+		{
+			"method names are no reads",
+			"x = 1\nfoo.x\n",
+			[]string{"1: assigned but unused variable - x (unused-variable)"},
+		},
+		{
+			"unreachable code",
+			"def foo\nreturn 1\nputs 2\nend\ndef bar\nraise \"x\"\nputs 3\nend\n[1].each do |x|\nnext\nputs x\nend\n",
+			[]string{
+				"3: statement not reached after return (unreachable-code)",
+				"7: statement not reached after raise (unreachable-code)",
+				"11: statement not reached after next (unreachable-code)",
+			},
+		},
+		{
+			"duplicate methods",
+			"def foo\nend\nclass A\ndef foo\nend\ndef foo\nend\nend\ndef foo\nend\n",
+			[]string{
+				"6: method redefined; discarding old A#foo defined on line 4 (duplicate-method)",
+				"9: method redefined; discarding old foo defined on line 1 (duplicate-method)",
+			},
+		},
+		{
+			"assignment in condition",
+			"x = 1\nif x = 2\nputs x\nend\nwhile y = gets\nputs y\nend\nif x == 1\nend\n",
+			[]string{
+				"2: assignment to x in the condition of if, did you mean ==? (assignment-in-condition)",
+				"5: assignment to y in the condition of while, did you mean ==? (assignment-in-condition)",
+			},
+		},
+		{
+			"shadowed block parameters",
+			"x = 1\n[1].each { |x| puts x }\n[2].each { |y| [3].each { |y| puts y } }\nputs x\n",
+			[]string{
+				"2: shadowing outer local variable - x (shadowed-block-param)",
+				"3: shadowing outer local variable - y (shadowed-block-param)",
+			},
+		},
+		{
+			"rescued exceptions are not reported",
+			"begin\nfoo\nrescue => e\nend\n",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []string
+			for _, warning := range Check(parse(t, tt.input)) {
+				actual = append(actual, warning.String())
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected warnings %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) {
+	program := parse(t, "def foo\nreturn 1\nx = 2\nend\n")
+
+	warnings := Check(program, UnreachableCode)
+
+	expected := []Warning{{Line: 3, Rule: UnreachableCode, Message: "statement not reached after return"}}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, warnings)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("unused-variable, duplicate-method")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Rule{UnusedVariable, DuplicateMethod}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected rules %v, got %v", expected, rules)
+	}
+
+	if _, err := ParseRules("unused-variable,unknown"); err == nil {
+		t.Errorf("Expected an error for an unknown rule")
+	}
+}
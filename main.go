@@ -1,14 +1,31 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/compiler"
+	"github.com/goruby/goruby/format"
 	"github.com/goruby/goruby/interpreter"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/lint"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/optimizer"
+	"github.com/goruby/goruby/parser"
+	"github.com/goruby/goruby/profile"
+	"github.com/goruby/goruby/repl"
+	"github.com/goruby/goruby/token"
 )
 
 type multiString []string
@@ -25,35 +42,591 @@ func (m *multiString) Set(s string) error {
 	return nil
 }
 
+// warningLevel is the value of the -W flag. Given without a value it sets
+// the level to 2.
+type warningLevel int
+
+func (w warningLevel) String() string { return strconv.Itoa(int(w)) }
+func (w *warningLevel) Set(s string) error {
+	if s == "true" {
+		*w = 2
+		return nil
+	}
+	level, err := strconv.Atoi(s)
+	if err != nil || level < 0 || level > 2 {
+		return fmt.Errorf("invalid warning level %q", s)
+	}
+	*w = warningLevel(level)
+	return nil
+}
+func (w *warningLevel) IsBoolFlag() bool { return true }
+
 var onelineScripts multiString
+var warnings warningLevel = 1
+var useVM bool
+var skipOptimizer bool
+var tailCalls bool
+var syntaxOnly bool
+var dump string
+var loopLines bool
+var printLines bool
+var autosplit bool
+var chompLines bool
+var includeDirs multiString
+var profileReport bool
+var profilePprof string
+var profileInterval time.Duration
+var coverageFile string
+var traceScript bool
+
+// switchCluster matches single letter switches given together, like -lane
+var switchCluster = regexp.MustCompile(`^-[nplac]+e?$`)
 
 func main() {
-	flag.Var(&onelineScripts, "e", "one line of script. Several -e's allowed. Omit [programfile]")
-	flag.Parse()
-	interpreter := interpreter.New()
-	if len(onelineScripts) != 0 {
-		input := strings.Join(onelineScripts, "\n")
-		_, err := interpreter.Interpret(input)
-		if err != nil {
-			fmt.Println(err.Error())
+	flag.Var(&onelineScripts, "e", "one line of script. Several -e's allowed. Omit [programfile], the arguments are passed in ARGV")
+	flag.Var(&warnings, "W", "set warning level; 0=silence, 1=medium, 2=verbose. -W alone means -W=2")
+	flag.BoolVar(&useVM, "vm", false, "compile the program to bytecode and run it by the VM")
+	flag.BoolVar(&skipOptimizer, "no-optimize", false, "run the program as parsed, without optimizing it")
+	flag.BoolVar(&tailCalls, "tailcall", false, "let calls in tail position replace the frame of the calling method")
+	flag.BoolVar(&syntaxOnly, "c", false, "check syntax only")
+	flag.StringVar(&dump, "dump", "", "dump the `tokens`, the ast or the VM insns of the program instead of running it")
+	flag.BoolVar(&loopLines, "n", false, "assume 'while gets(); ... end' loop around your script")
+	flag.BoolVar(&printLines, "p", false, "assume loop like -n but print line also like sed")
+	flag.BoolVar(&autosplit, "a", false, "autosplit mode with -n or -p (splits $_ into $F)")
+	flag.BoolVar(&chompLines, "l", false, "enable line ending processing")
+	flag.Var(&includeDirs, "I", "specify $LOAD_PATH `directory` (may be used more than once)")
+	flag.BoolVar(&profileReport, "profile", false, "sample the call stack and print a flat profile and the call graph to stderr at exit")
+	flag.StringVar(&profilePprof, "profile-pprof", "", "sample the call stack and write the profile to `file` in the format of pprof at exit")
+	flag.DurationVar(&profileInterval, "profile-interval", profile.DefaultInterval, "the interval between two samples of the call stack")
+	flag.BoolVar(&traceScript, "trace", false, "print each line, method call and return evaluated to stderr, indented by the call depth")
+	flag.StringVar(&coverageFile, "coverage", "", "measure line coverage and write it to `file` at exit, as JSON if its name ends in .json and as lcov tracefile otherwise")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			os.Exit(formatFiles(os.Args[2:]))
+		case "vet":
+			os.Exit(vetFiles(os.Args[2:]))
+		}
+	}
+	flag.CommandLine.Parse(expandSwitches(os.Args[1:]))
+	if syntaxOnly {
+		os.Exit(checkSyntax(flag.Args()))
+	}
+	if dump != "" {
+		os.Exit(dumpProgram(dump, flag.Args()))
+	}
+	var options []interpreter.Option
+	if useVM {
+		options = append(options, interpreter.WithVM())
+	}
+	if skipOptimizer {
+		options = append(options, interpreter.WithoutOptimizer())
+	}
+	if tailCalls {
+		options = append(options, interpreter.WithTailCallOptimization())
+	}
+	options = append(options, interpreter.WithLoadPath(loadPath()...))
+	interpreter := interpreter.New(options...)
+	object.SetWarningLevel(int(warnings))
+	interruptOnSignal(interpreter)
+	object.Console = openConsole
+	if profileReport || profilePprof != "" {
+		if err := object.StartProfiling(profileInterval); err != nil {
+			log.Printf("Error while starting the profiler: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if traceScript {
+		object.NewTraceHook(printTraceEvent()).SetEnabled(true)
+	}
+	if coverageFile != "" {
+		if err := object.StartCoverage(); err != nil {
+			log.Printf("Error while starting coverage: %v\n", err)
 			os.Exit(1)
 		}
-		return
 	}
 	args := flag.Args()
-	if len(args) == 0 {
-		log.Println("No program files specified")
-		os.Exit(1)
+	looping := loopLines || printLines
+	if len(onelineScripts) == 0 && len(args) != 0 && args[0] != "-" && !looping {
+		setProgram(interpreter, args[0], args[1:])
+		_, err := interpreter.InterpretFile(args[0])
+		if _, ok := err.(*os.PathError); ok {
+			log.Printf("Error while opening program file: %T:%v\n", err, err)
+			os.Exit(1)
+		}
+		exit(interpreter, args[0], err)
+		return
 	}
-	fileBytes, err := ioutil.ReadFile(args[0])
+	name, source, err := readProgram(args)
 	if err != nil {
-		log.Printf("Error while opening program file: %T:%v\n", err, err)
+		log.Printf("Error while opening program file: %v\n", err)
 		os.Exit(1)
 	}
-	_, err = interpreter.Interpret(string(fileBytes))
+	if name != "-e" && len(args) != 0 {
+		args = args[1:]
+	}
+	setProgram(interpreter, name, args)
+	if looping && len(args) != 0 {
+		input, err := openInputs(args)
+		if err != nil {
+			log.Printf("Error while opening input file: %v\n", err)
+			os.Exit(1)
+		}
+		interpreter.SetInput(input)
+	}
+	_, err = interpreter.Interpret(source)
+	exit(interpreter, name, err)
+}
+
+// loadPath returns the directories given by -I, followed by those listed in
+// the GORUBYLIB environment variable, which is separated by colons like
+// PATH. The directories given by -I are made absolute.
+func loadPath() []string {
+	var dirs []string
+	for _, dir := range includeDirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("GORUBYLIB")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// expandSwitches splits the clusters of single letter switches within args,
+// like -ne, into one argument per switch, and -I from the directory given
+// along with it, like -Ilib, as the flag package does not support them.
+// Arguments following the program file are left as they are.
+func expandSwitches(args []string) []string {
+	var expanded []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--" || !strings.HasPrefix(arg, "-"):
+			return append(expanded, args[i:]...)
+		case len(arg) > 2 && strings.HasPrefix(arg, "-I") && !strings.Contains(arg, "="):
+			expanded = append(expanded, "-I", arg[2:])
+			continue
+		case len(arg) > 2 && switchCluster.MatchString(arg):
+			for _, r := range arg[1:] {
+				expanded = append(expanded, "-"+string(r))
+			}
+		default:
+			expanded = append(expanded, arg)
+		}
+		if last := expanded[len(expanded)-1]; (last == "-e" || last == "-I" || last == "-dump" || last == "--dump") && i+1 < len(args) {
+			i++
+			expanded = append(expanded, args[i])
+		}
+	}
+	return expanded
+}
+
+// lineLoop wraps source into the loop over the lines of the input asked for
+// by -n and -p, which chomps the lines for -l and splits them into $F for
+// -a. The statements added before the program share the line of the loop,
+// so that the lines of the program keep their numbers.
+func lineLoop(source string) string {
+	loop := "while gets; "
+	if chompLines {
+		loop += "$_ = $_.chomp; "
+	}
+	if autosplit {
+		loop += "$F = $_.split; "
+	}
+	loop += source + "\n"
+	if printLines && chompLines {
+		loop += "print $_, \"\\n\"\n"
+	} else if printLines {
+		loop += "print $_\n"
+	}
+	return loop + "end\n"
+}
+
+// openInputs returns the concatenated content of the files, which the
+// loop of -n and -p reads from instead of stdin
+func openInputs(paths []string) (io.Reader, error) {
+	var readers []io.Reader
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		readers = append(readers, file)
+	}
+	return io.MultiReader(readers...), nil
+}
+
+// checkSyntax parses the program given by -e, the program file or stdin
+// without running it. It prints "Syntax OK", or every syntax error along with
+// its line, and returns the exit status.
+func checkSyntax(args []string) int {
+	name, source, err := readProgram(args)
+	if err != nil {
+		log.Printf("Error while opening program file: %v\n", err)
+		return 1
+	}
+	if _, ok := parseProgram(name, source); !ok {
+		return 1
+	}
+	fmt.Println("Syntax OK")
+	return 0
+}
+
+// dumpProgram prints the tokens, the AST or the VM instructions of the
+// program given by -e, the program file or stdin, depending on what, and
+// returns the exit status
+func dumpProgram(what string, args []string) int {
+	if what != "tokens" && what != "ast" && what != "insns" {
+		log.Printf("Unknown dump %q, expected tokens, ast or insns\n", what)
+		return 1
+	}
+	name, source, err := readProgram(args)
 	if err != nil {
+		log.Printf("Error while opening program file: %v\n", err)
+		return 1
+	}
+	if what == "tokens" {
+		l := lexer.New(source)
+		for l.HasNext() {
+			tok := l.NextToken()
+			fmt.Printf("%4d %-12s %q\n", tok.Line, tok.Type, tok.Literal)
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+		return 0
+	}
+	program, ok := parseProgram(name, source)
+	if !ok {
+		return 1
+	}
+	if what == "ast" {
+		ast.Fprint(os.Stdout, program)
+		return 0
+	}
+	if !skipOptimizer {
+		program = optimizer.Optimize(program)
+	}
+	bytecode, err := compiler.Compile(program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+	fmt.Print(bytecode)
+	return 0
+}
+
+// readProgram returns the name and the source of the program given by -e,
+// the program file or stdin, wrapped into the loop of -n and -p if given
+func readProgram(args []string) (string, string, error) {
+	name, source, err := "-e", strings.Join(onelineScripts, "\n"), error(nil)
+	if len(onelineScripts) == 0 {
+		var input []byte
+		if len(args) == 0 || args[0] == "-" {
+			name = "-"
+			input, err = io.ReadAll(os.Stdin)
+		} else {
+			name = args[0]
+			input, err = os.ReadFile(name)
+		}
+		source = string(input)
+	}
+	if loopLines || printLines {
+		source = lineLoop(source)
+	}
+	return name, source, err
+}
+
+// parseProgram parses source and prints the syntax errors found along with
+// their line to stderr. It returns whether there were none.
+func parseProgram(name, source string) (*ast.Program, bool) {
+	program, err := parser.New(lexer.New(source)).ParseProgram()
+	if err == nil {
+		return program, true
+	}
+	printSyntaxErrors(name, err)
+	return nil, false
+}
+
+// printSyntaxErrors prints the syntax errors of err to stderr, each along
+// with its position and the source line marked at it
+func printSyntaxErrors(name string, err error) {
+	parseErrors, ok := err.(*parser.Errors)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return
+	}
+	for _, syntaxError := range parseErrors.SyntaxErrors() {
+		if syntaxError.Column == 0 {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", name, syntaxError.Line, syntaxError.Message)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n%s", name, syntaxError.Line, syntaxError.Column, syntaxError.Message, syntaxError.Caret())
+	}
+}
+
+// vetFiles implements `goruby vet [-rules list] [files]`, which reports
+// suspicious constructs within the given Ruby files, or stdin. It returns
+// the exit status, which is 1 if anything was reported.
+func vetFiles(args []string) int {
+	flags := flag.NewFlagSet("vet", flag.ExitOnError)
+	names := make([]string, len(lint.Rules))
+	for i, rule := range lint.Rules {
+		names[i] = string(rule)
+	}
+	ruleList := flags.String("rules", "", "comma separated `list` of the rules to check, out of "+strings.Join(names, ", ")+"; all if empty")
+	flags.Parse(args)
+	rules, err := lint.ParseRules(*ruleList)
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := 0
+	for _, name := range files {
+		var input []byte
+		if name == "-" {
+			input, err = io.ReadAll(os.Stdin)
+		} else {
+			input, err = os.ReadFile(name)
+		}
+		if err != nil {
+			log.Printf("Error while opening program file: %v\n", err)
+			status = 1
+			continue
+		}
+		program, ok := parseProgram(name, string(input))
+		if !ok {
+			status = 1
+			continue
+		}
+		for _, warning := range lint.Check(program, rules...) {
+			fmt.Printf("%s:%s\n", name, warning)
+			status = 1
+		}
+	}
+	return status
+}
+
+// formatFiles implements `goruby fmt [-l] [-w] [files]`, which formats the
+// given Ruby files, or stdin, and prints the result. It returns the exit
+// status.
+func formatFiles(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	list := flags.Bool("l", false, "list the files whose formatting differs instead of printing them")
+	write := flags.Bool("w", false, "write the result to the file instead of printing it")
+	flags.Parse(args)
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := 0
+	for _, name := range files {
+		var input []byte
+		var err error
+		if name == "-" {
+			input, err = io.ReadAll(os.Stdin)
+		} else {
+			input, err = os.ReadFile(name)
+		}
+		if err != nil {
+			log.Printf("Error while opening program file: %v\n", err)
+			status = 1
+			continue
+		}
+		output, err := format.Source(input)
+		if err != nil {
+			printSyntaxErrors(name, err)
+			status = 1
+			continue
+		}
+		changed := string(output) != string(input)
+		if *list && changed {
+			fmt.Println(name)
+		}
+		if *write && name != "-" {
+			if changed {
+				if err := os.WriteFile(name, output, 0644); err != nil {
+					log.Printf("Error while writing %s: %v\n", name, err)
+					status = 1
+				}
+			}
+			continue
+		}
+		if !*list {
+			os.Stdout.Write(output)
+		}
+	}
+	return status
+}
+
+// setProgram sets `$PROGRAM_NAME` to the name of the program run, which is
+// -e for one line scripts and - for programs read from stdin, and ARGV to
+// the arguments following it
+func setProgram(interpreter interpreter.Interpreter, name string, args []string) {
+	argv := object.NewArray()
+	for _, arg := range args {
+		argv.Elements = append(argv.Elements, &object.String{Value: arg})
+	}
+	object.SetConstant("ARGV", argv)
+	interpreter.SetGlobal("$PROGRAM_NAME", &object.String{Value: name})
+}
+
+// interruptOnSignal interrupts the interpreter on Ctrl-C, unless the
+// program traps it itself. Another Ctrl-C terminates the program
+// immediately.
+func interruptOnSignal(interpreter interpreter.Interpreter) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		for range signals {
+			if object.SignalTrapped(os.Interrupt) {
+				continue
+			}
+			signal.Reset(os.Interrupt)
+			interpreter.Interrupt()
+			return
+		}
+	}()
+}
+
+// exit runs the exit handlers of the interpreter running the program name
+// and exits with a non zero status if either err or any of the handlers
+// failed, or with the status passed to Kernel#exit
+func exit(interp interpreter.Interpreter, name string, err error) {
+	printError(name, err)
+	if finalizeErr := interp.Finalize(); finalizeErr != nil {
+		printError(name, finalizeErr)
+		err = finalizeErr
+	}
+	writeProfile()
+	writeCoverage()
+	if status := interpreter.ExitStatus(err); status != 0 {
+		os.Exit(status)
+	}
+}
+
+// writeProfile stops the profiler started by --profile or --profile-pprof,
+// if any, and writes the profile recorded
+func writeProfile() {
+	p := object.StopProfiling()
+	if p == nil {
+		return
+	}
+	if profileReport {
+		if err := p.WriteReport(os.Stderr); err != nil {
+			log.Printf("Error while writing the profile: %v\n", err)
+		}
+	}
+	if profilePprof == "" {
+		return
+	}
+	file, err := os.Create(profilePprof)
+	if err != nil {
+		log.Printf("Error while writing the profile: %v\n", err)
+		return
+	}
+	defer file.Close()
+	if err := p.WritePprof(file); err != nil {
+		log.Printf("Error while writing the profile: %v\n", err)
+	}
+}
+
+// printTraceEvent returns a hook printing the events traced to stderr,
+// indented by the depth of the calls they occur in
+func printTraceEvent() func(event *object.TraceEvent) error {
+	depth := 0
+	return func(event *object.TraceEvent) error {
+		var detail string
+		switch event.Event {
+		case "call":
+			detail = " " + event.MethodID
+		case "return":
+			depth--
+			if depth < 0 {
+				depth = 0
+			}
+			detail = " " + event.MethodID + " => " + event.ReturnValue.Inspect()
+		case "raise":
+			detail = " " + event.RaisedException.Inspect()
+		}
+		fmt.Fprintf(os.Stderr, "%s%s:%d: %s%s\n", strings.Repeat("  ", depth), event.Path, event.Lineno, event.Event, detail)
+		if event.Event == "call" {
+			depth++
+		}
+		return nil
+	}
+}
+
+// openConsole opens a REPL on the terminal evaluating the expressions
+// entered within binding, for binding.irb
+func openConsole(binding *object.Binding) {
+	config := repl.DefaultConfig()
+	config.Color = repl.IsTerminal(os.Stdout)
+	repl.Console(binding, os.Stdin, os.Stdout, config)
+}
+
+// writeCoverage stops measuring the coverage started by --coverage, if
+// any, and writes the result
+func writeCoverage() {
+	if coverageFile == "" {
+		return
+	}
+	result := object.StopCoverage()
+	if result == nil {
+		return
+	}
+	file, err := os.Create(coverageFile)
+	if err != nil {
+		log.Printf("Error while writing the coverage: %v\n", err)
+		return
+	}
+	defer file.Close()
+	if strings.HasSuffix(coverageFile, ".json") {
+		err = result.WriteJSON(file)
+	} else {
+		err = result.WriteLCOV(file)
+	}
+	if err != nil {
+		log.Printf("Error while writing the coverage: %v\n", err)
+	}
+}
+
+// printError prints err of the program name unless it is nil or a regular
+// exit. Exceptions are printed along with their backtrace to stderr like MRI
+// does, syntax errors like by -c.
+func printError(name string, err error) {
+	if _, ok := err.(*object.SystemExit); err == nil || ok {
+		return
+	}
+	var parseErrors *parser.Errors
+	if errors.As(err, &parseErrors) {
+		var syntaxError *object.SyntaxError
+		if errors.As(err, &syntaxError) && syntaxError.Path != "" {
+			if path, _ := filepath.Abs(name); path != syntaxError.Path {
+				name = syntaxError.Path
+			}
+		}
+		printSyntaxErrors(name, parseErrors)
+		return
+	}
+	backtrace, _ := object.Backtrace(err)
+	exception, ok := err.(object.RubyObject)
+	if len(backtrace) == 0 || !ok {
 		fmt.Println(err.Error())
-		os.Exit(1)
+		return
+	}
+	class := exception.Class().(object.RubyObject).Inspect()
+	fmt.Fprintf(os.Stderr, "%s: %s (%s)\n", backtrace[0], err.Error(), class)
+	for _, line := range backtrace[1:] {
+		fmt.Fprintf(os.Stderr, "\tfrom %s\n", line)
 	}
-	return
 }
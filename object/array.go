@@ -1,6 +1,10 @@
 package object
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+)
 
 var arrayClass RubyClassObject = newClass("Array", objectClass, arrayMethods, arrayClassMethods)
 
@@ -10,21 +14,56 @@ func init() {
 
 // NewArray returns a new array populated with elements.
 func NewArray(elements ...RubyObject) *Array {
-	arr := &Array{Elements: make([]RubyObject, len(elements))}
+	arr := newArrayOf(make([]RubyObject, len(elements)))
 	for i, elem := range elements {
 		arr.Elements[i] = elem
 	}
 	return arr
 }
 
+// newArrayOf returns a new array holding elements without copying them
+func newArrayOf(elements []RubyObject) *Array {
+	allocatedArrays.add()
+	return &Array{Elements: elements}
+}
+
 // An Array represents a Ruby Array
 type Array struct {
 	Elements []RubyObject
+	// shared is 1 while Elements may share its backing array with another
+	// Array, see snapshot. It is accessed atomically, as an Array visible
+	// within several Runtimes may be copied by all of them at once.
+	shared int32
+	frozen bool
+}
+
+// snapshot returns a copy of a. Both Arrays share their elements until
+// either is modified.
+func (a *Array) snapshot() *Array {
+	allocatedArrays.add()
+	atomic.StoreInt32(&a.shared, 1)
+	return &Array{Elements: a.Elements[:len(a.Elements):len(a.Elements)], shared: 1}
+}
+
+// modify copies the elements of a if they may be shared with another
+// Array. It must be called before a is modified in place.
+func (a *Array) modify() {
+	if atomic.LoadInt32(&a.shared) == 0 {
+		return
+	}
+	a.Elements = append([]RubyObject(nil), a.Elements...)
+	atomic.StoreInt32(&a.shared, 0)
 }
 
 // Type returns the ObjectType of the array
 func (a *Array) Type() Type { return ARRAY_OBJ }
 
+// Frozen returns true if the array can not be modified anymore
+func (a *Array) Frozen() bool { return a.frozen }
+
+// Freeze prevents any further modifications of the array
+func (a *Array) Freeze() { a.frozen = true }
+
 // Inspect returns all elements within the array, divided by comma and
 // surrounded by brackets
 func (a *Array) Inspect() string {
@@ -40,4 +79,1202 @@ func (a *Array) Class() RubyClass { return arrayClass }
 
 var arrayClassMethods = map[string]RubyMethod{}
 
-var arrayMethods = map[string]RubyMethod{}
+var arrayMethods = map[string]RubyMethod{
+	"sort":    withArity(0, publicMethod(arraySort)),
+	"sort_by": withArity(0, publicMethod(arraySortBy)),
+	"min":     withArity(0, publicMethod(arrayMin)),
+	"max":     withArity(0, publicMethod(arrayMax)),
+	"min_by":  withArity(0, publicMethod(arrayMinBy)),
+	"max_by":  withArity(0, publicMethod(arrayMaxBy)),
+	"sum":     withArityRange(0, 1, publicMethod(arraySum)),
+
+	"each":             withArity(0, publicMethod(arrayEach)),
+	"lazy":             withArity(0, publicMethod(enumerableLazy)),
+	"pmap":             withArityRange(0, 1, publicRuntimeMethod(arrayParallelMap)),
+	"peach":            withArityRange(0, 1, publicRuntimeMethod(arrayParallelEach)),
+	"each_with_index":  withArity(0, publicMethod(arrayEachWithIndex)),
+	"each_with_object": withArity(1, publicMethod(arrayEachWithObject)),
+	"map":              withArity(0, publicMethod(arrayMap)),
+	"collect":          withArity(0, publicMethod(arrayMap)),
+	"select":           withArity(0, publicMethod(arraySelect)),
+	"filter":           withArity(0, publicMethod(arraySelect)),
+	"reject":           withArity(0, publicMethod(arrayReject)),
+	"reduce":           withArityRange(0, 2, publicMethod(arrayReduce)),
+	"inject":           withArityRange(0, 2, publicMethod(arrayReduce)),
+
+	"push":      publicMethod(modifying(arrayPush)),
+	"append":    publicMethod(modifying(arrayPush)),
+	"<<":        withArity(1, publicMethod(modifying(arrayPush))),
+	"pop":       withArityRange(0, 1, publicMethod(modifying(arrayPop))),
+	"shift":     withArityRange(0, 1, publicMethod(modifying(arrayShift))),
+	"unshift":   publicMethod(modifying(arrayUnshift)),
+	"prepend":   publicMethod(modifying(arrayUnshift)),
+	"insert":    withArityRange(1, -1, publicMethod(modifying(arrayInsert))),
+	"concat":    publicMethod(modifying(arrayConcat)),
+	"delete":    withArity(1, publicMethod(modifying(arrayDelete))),
+	"delete_at": withArity(1, publicMethod(modifying(arrayDeleteAt))),
+	"delete_if": withArity(0, publicMethod(modifying(arrayDeleteIf))),
+	"clear":     withArity(0, publicMethod(modifying(arrayClear))),
+	"fill":      withArityRange(0, 3, publicMethod(modifying(arrayFill))),
+
+	"==":         withArity(1, publicMethod(arrayEqual)),
+	"eql?":       withArity(1, publicMethod(arrayEql)),
+	"hash":       withArity(0, publicMethod(arrayHash)),
+	"include?":   withArity(1, publicMethod(arrayInclude)),
+	"index":      withArityRange(0, 1, publicMethod(arrayIndexOf)),
+	"first":      withArityRange(0, 1, publicMethod(arrayFirst)),
+	"last":       withArityRange(0, 1, publicMethod(arrayLast)),
+	"take":       withArity(1, publicMethod(arrayTake)),
+	"drop":       withArity(1, publicMethod(arrayDrop)),
+	"flatten":    withArityRange(0, 1, publicMethod(arrayFlatten)),
+	"compact":    withArity(0, publicMethod(arrayCompact)),
+	"uniq":       withArity(0, publicMethod(arrayUniq)),
+	"reverse":    withArity(0, publicMethod(arrayReverse)),
+	"join":       withArityRange(0, 1, publicMethod(arrayJoin)),
+	"zip":        publicMethod(arrayZip),
+	"each_slice": withArity(1, publicMethod(arrayEachSlice)),
+	"each_cons":  withArity(1, publicMethod(arrayEachCons)),
+
+	"[]":    withArityRange(1, 2, publicMethod(arraySlice)),
+	"slice": withArityRange(1, 2, publicMethod(arraySlice)),
+	"[]=":   withArityRange(2, 3, publicMethod(modifying(arraySliceAssign))),
+	"dig":   withArityRange(1, -1, publicMethod(arrayDig)),
+}
+
+// comparator compares a and b, returning a negative number if a is less
+// than b, zero if both are equal and a positive number otherwise
+type comparator func(a, b RubyObject) (int, error)
+
+// blockComparator returns a comparator calling block with both operands. It
+// returns Compare if block is nil.
+func blockComparator(block *Proc) comparator {
+	if block == nil {
+		return Compare
+	}
+	return func(a, b RubyObject) (int, error) {
+		result, err := block.Call(a, b)
+		if err != nil {
+			return 0, err
+		}
+		i, ok := result.(*Integer)
+		if !ok {
+			return 0, NewComparisonError(a, b)
+		}
+		return int(i.Value), nil
+	}
+}
+
+// sortElements sorts elements in place using cmp. It stops comparing after
+// the first error and returns it.
+func sortElements(elements []RubyObject, cmp comparator) error {
+	var err error
+	sort.SliceStable(elements, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var result int
+		result, err = cmp(elements[i], elements[j])
+		return result < 0
+	})
+	return err
+}
+
+func arraySort(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	sorted := NewArray(array.Elements...)
+	if err := sortElements(sorted.Elements, blockComparator(block)); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// arraySortBy sorts the elements by the keys the block returns for them.
+// The block is called exactly once per element.
+func arraySortBy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewArray(array.Elements...), nil
+	}
+	keys, err := arrayBlockKeys(array, block)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]int, len(array.Elements))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var cmp int
+		cmp, err = Compare(keys[indices[i]], keys[indices[j]])
+		return cmp < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	sorted := NewArray()
+	for _, index := range indices {
+		sorted.Elements = append(sorted.Elements, array.Elements[index])
+	}
+	return sorted, nil
+}
+
+// arrayBlockKeys returns the results of calling block for every element
+func arrayBlockKeys(array *Array, block *Proc) ([]RubyObject, error) {
+	keys := make([]RubyObject, len(array.Elements))
+	for i, elem := range array.Elements {
+		key, err := block.Call(elem)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+func arrayMin(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return arrayExtremum(context.(*Array), -1, blockComparator(block))
+}
+
+func arrayMax(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return arrayExtremum(context.(*Array), 1, blockComparator(block))
+}
+
+func arrayMinBy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return arrayExtremumBy(context.(*Array), -1, block)
+}
+
+func arrayMaxBy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return arrayExtremumBy(context.(*Array), 1, block)
+}
+
+// arrayExtremum returns the element which compares to all other elements
+// with the given direction, i.e. the minimum for -1 and the maximum for 1
+func arrayExtremum(array *Array, direction int, cmp comparator) (RubyObject, error) {
+	if len(array.Elements) == 0 {
+		return NIL, nil
+	}
+	result := array.Elements[0]
+	for _, elem := range array.Elements[1:] {
+		c, err := cmp(elem, result)
+		if err != nil {
+			return nil, err
+		}
+		if c*direction > 0 {
+			result = elem
+		}
+	}
+	return result, nil
+}
+
+// arrayExtremumBy works like arrayExtremum but compares the keys returned
+// by block instead of the elements
+func arrayExtremumBy(array *Array, direction int, block *Proc) (RubyObject, error) {
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	keys, err := arrayBlockKeys(array, block)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return NIL, nil
+	}
+	result := 0
+	for i, key := range keys[1:] {
+		c, err := Compare(key, keys[result])
+		if err != nil {
+			return nil, err
+		}
+		if c*direction > 0 {
+			result = i + 1
+		}
+	}
+	return array.Elements[result], nil
+}
+
+// arraySum adds up all elements, or the results of the block for them,
+// starting with the optional argument or 0. Integers and Floats are added
+// directly, any other object needs to respond to `+`.
+func arraySum(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	var sum RubyObject = NewInteger(0)
+	if len(args) == 1 {
+		sum = args[0]
+	}
+	for _, elem := range array.Elements {
+		if block != nil {
+			var err error
+			elem, err = block.Call(elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		left, leftIsInt := sum.(*Integer)
+		right, rightIsInt := elem.(*Integer)
+		leftFloat, leftIsNumeric := toFloat(sum)
+		rightFloat, rightIsNumeric := toFloat(elem)
+		switch {
+		case leftIsInt && rightIsInt:
+			sum = NewInteger(left.Value + right.Value)
+		case leftIsNumeric && rightIsNumeric:
+			sum = NewFloat(leftFloat + rightFloat)
+		default:
+			var err error
+			sum, err = Send(sum, "+", elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sum, nil
+}
+
+// arrayEach yields every element to the block. Without a block it returns
+// an Enumerator.
+func arrayEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(array, "each"), nil
+	}
+	for _, elem := range array.Elements {
+		if _, err := block.Call(elem); err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+func arrayEachWithIndex(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(array, "each_with_index"), nil
+	}
+	for i, elem := range array.Elements {
+		if _, err := block.Call(elem, NewInteger(int64(i))); err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+func arrayEachWithObject(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	memo := args[0]
+	if block == nil {
+		return NewEnumerator(array, "each_with_object", memo), nil
+	}
+	for _, elem := range array.Elements {
+		if _, err := block.Call(elem, memo); err != nil {
+			return nil, err
+		}
+	}
+	return memo, nil
+}
+
+func arrayMap(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(array, "map"), nil
+	}
+	result := NewArray()
+	for _, elem := range array.Elements {
+		mapped, err := block.Call(elem)
+		if err != nil {
+			return nil, err
+		}
+		result.Elements = append(result.Elements, mapped)
+	}
+	return result, nil
+}
+
+func arraySelect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(context, "select"), nil
+	}
+	return arrayFilter(context.(*Array), block, true)
+}
+
+func arrayReject(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(context, "reject"), nil
+	}
+	return arrayFilter(context.(*Array), block, false)
+}
+
+// arrayFilter returns a new Array with all elements for which the truthiness
+// of the block result equals keep
+func arrayFilter(array *Array, block *Proc, keep bool) (RubyObject, error) {
+	result := NewArray()
+	for _, elem := range array.Elements {
+		ok, err := block.Call(elem)
+		if err != nil {
+			return nil, err
+		}
+		if isTruthy(ok) == keep {
+			result.Elements = append(result.Elements, elem)
+		}
+	}
+	return result, nil
+}
+
+// arrayReduce combines all elements by either calling the block or sending
+// the method named by the last argument with the accumulator and the element.
+// An optional first argument is used as initial accumulator.
+func arrayReduce(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	var method string
+	if block == nil {
+		if len(args) == 0 {
+			return nil, NewArgumentError("no block given")
+		}
+		switch name := args[len(args)-1].(type) {
+		case *Symbol:
+			method = name.Value
+		case *String:
+			method = name.Value
+		default:
+			return nil, NewTypeError("%s is not a symbol nor a string", name.Inspect())
+		}
+		args = args[:len(args)-1]
+	} else if len(args) == 2 {
+		return nil, NewWrongNumberOfArgumentsRangeError(0, 1, 2)
+	}
+	elements := array.Elements
+	var accumulator RubyObject
+	if len(args) == 1 {
+		accumulator = args[0]
+	} else {
+		if len(elements) == 0 {
+			return NIL, nil
+		}
+		accumulator, elements = elements[0], elements[1:]
+	}
+	for _, elem := range elements {
+		var err error
+		if block != nil {
+			accumulator, err = block.Call(accumulator, elem)
+		} else {
+			accumulator, err = Send(accumulator, method, elem)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return accumulator, nil
+}
+
+// arrayIndex returns the position index refers to within an array of the
+// given length, counting negative indices from the end
+func arrayIndex(index int64, length int) int {
+	if index < 0 {
+		return int(index) + length
+	}
+	return int(index)
+}
+
+// arrayCount returns the optional count argument of Array#pop and
+// Array#shift
+func arrayCount(args []RubyObject) (int, error) {
+	count, err := integerArgument(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if count.Value < 0 {
+		return 0, NewArgumentError("negative array size")
+	}
+	return int(count.Value), nil
+}
+
+func arrayPush(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	array.Elements = append(array.Elements, args...)
+	return array, nil
+}
+
+func arrayPop(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	length := len(array.Elements)
+	if len(args) == 0 {
+		if length == 0 {
+			return NIL, nil
+		}
+		last := array.Elements[length-1]
+		array.Elements = array.Elements[:length-1]
+		return last, nil
+	}
+	count, err := arrayCount(args)
+	if err != nil {
+		return nil, err
+	}
+	if count > length {
+		count = length
+	}
+	popped := NewArray(array.Elements[length-count:]...)
+	array.Elements = array.Elements[:length-count]
+	return popped, nil
+}
+
+func arrayShift(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	length := len(array.Elements)
+	if len(args) == 0 {
+		if length == 0 {
+			return NIL, nil
+		}
+		first := array.Elements[0]
+		array.Elements = array.Elements[1:]
+		return first, nil
+	}
+	count, err := arrayCount(args)
+	if err != nil {
+		return nil, err
+	}
+	if count > length {
+		count = length
+	}
+	shifted := NewArray(array.Elements[:count]...)
+	array.Elements = array.Elements[count:]
+	return shifted, nil
+}
+
+func arrayUnshift(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	array.Elements = append(NewArray(args...).Elements, array.Elements...)
+	return array, nil
+}
+
+// arrayInsert inserts the given objects before the element at args[0]. A
+// negative index inserts after the element, counting from the end, and an
+// index beyond the end pads the array with nil.
+func arrayInsert(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	objects := args[1:]
+	if len(objects) == 0 {
+		return array, nil
+	}
+	length := len(array.Elements)
+	position := int(index.Value)
+	if position < 0 {
+		position += length + 1
+		if position < 0 {
+			return nil, NewIndexError(
+				"index %d too small for array; minimum: -%d",
+				index.Value,
+				length+1,
+			)
+		}
+	}
+	for len(array.Elements) < position {
+		array.Elements = append(array.Elements, NIL)
+	}
+	elements := make([]RubyObject, 0, len(array.Elements)+len(objects))
+	elements = append(elements, array.Elements[:position]...)
+	elements = append(elements, objects...)
+	array.Elements = append(elements, array.Elements[position:]...)
+	return array, nil
+}
+
+func arrayConcat(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	var elements []RubyObject
+	for _, arg := range args {
+		other, ok := arg.(*Array)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(array, arg)
+		}
+		elements = append(elements, other.Elements...)
+	}
+	array.Elements = append(array.Elements, elements...)
+	return array, nil
+}
+
+// arrayDelete removes all elements equal to args[0] and returns the last
+// removed element. If nothing was removed it returns nil or the result of
+// the block.
+func arrayDelete(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	args, block := extractBlock(args)
+	var deleted RubyObject
+	var kept []RubyObject
+	for _, elem := range array.Elements {
+		equal, err := isEqual(elem, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if equal {
+			deleted = elem
+			continue
+		}
+		kept = append(kept, elem)
+	}
+	array.Elements = append(array.Elements[:0], kept...)
+	if deleted != nil {
+		return deleted, nil
+	}
+	if block != nil {
+		return block.Call(args[0])
+	}
+	return NIL, nil
+}
+
+func arrayDeleteAt(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	position := arrayIndex(index.Value, len(array.Elements))
+	if position < 0 || position >= len(array.Elements) {
+		return NIL, nil
+	}
+	deleted := array.Elements[position]
+	array.Elements = append(array.Elements[:position], array.Elements[position+1:]...)
+	return deleted, nil
+}
+
+// arrayDeleteIf removes all elements for which the block returns a truthy
+// value
+func arrayDeleteIf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	_, block := extractBlock(args)
+	if block == nil {
+		return array, nil
+	}
+	var kept []RubyObject
+	for _, elem := range array.Elements {
+		remove, err := block.Call(elem)
+		if err != nil {
+			return nil, err
+		}
+		if !isTruthy(remove) {
+			kept = append(kept, elem)
+		}
+	}
+	array.Elements = append(array.Elements[:0], kept...)
+	return array, nil
+}
+
+func arrayClear(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.Elements = array.Elements[:0:0]
+	atomic.StoreInt32(&array.shared, 0)
+	return array, nil
+}
+
+// arrayFill sets the elements to the first argument or, if a block is
+// given, to the block result for each index. The filled section is either
+// the whole array, everything from a start index, a start index and a
+// length or a Range. Filling beyond the end grows the array.
+func arrayFill(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	args, block := extractBlock(args)
+	var value RubyObject
+	if block == nil {
+		if len(args) == 0 {
+			return nil, NewWrongNumberOfArgumentsRangeError(1, 3, 0)
+		}
+		value, args = args[0], args[1:]
+	} else if len(args) == 3 {
+		return nil, NewWrongNumberOfArgumentsRangeError(0, 2, 3)
+	}
+	start, end, err := arrayFillBounds(len(array.Elements), args)
+	if err != nil {
+		return nil, err
+	}
+	for len(array.Elements) < end {
+		array.Elements = append(array.Elements, NIL)
+	}
+	for i := start; i < end; i++ {
+		if block != nil {
+			value, err = block.Call(NewInteger(int64(i)))
+			if err != nil {
+				return nil, err
+			}
+		}
+		array.Elements[i] = value
+	}
+	return array, nil
+}
+
+// arrayFillBounds returns the section of an array with the given length
+// described by the optional arguments of Array#fill
+func arrayFillBounds(length int, args []RubyObject) (int, int, error) {
+	if len(args) == 0 || args[0] == NIL {
+		return 0, length, nil
+	}
+	if r, ok := args[0].(*Range); ok && len(args) == 1 {
+		first, err := integerArgument(r.First)
+		if err != nil {
+			return 0, 0, err
+		}
+		last, err := integerArgument(r.Last)
+		if err != nil {
+			return 0, 0, err
+		}
+		start := arrayIndex(first.Value, length)
+		if start < 0 {
+			return 0, 0, NewRangeError("%s out of range", r.Inspect())
+		}
+		end := arrayIndex(last.Value, length)
+		if !r.Exclusive {
+			end++
+		}
+		return start, end, nil
+	}
+	first, err := integerArgument(args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	start := arrayIndex(first.Value, length)
+	if start < 0 {
+		start = 0
+	}
+	if len(args) == 1 || args[1] == NIL {
+		return start, length, nil
+	}
+	count, err := integerArgument(args[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, start + int(count.Value), nil
+}
+
+// arrayEqual reports whether the argument is an Array with the same length
+// whose elements are all equal to the elements of the receiver
+func arrayEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareArrays(context.(*Array), args[0], isEqual)
+}
+
+func arrayEql(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareArrays(context.(*Array), args[0], isEql)
+}
+
+// compareArrays reports whether other is an Array of the same length as
+// array whose elements are equal according to equal
+func compareArrays(array *Array, other RubyObject, equal func(a, b RubyObject) (bool, error)) (RubyObject, error) {
+	otherArray, ok := other.(*Array)
+	if !ok || len(array.Elements) != len(otherArray.Elements) {
+		return FALSE, nil
+	}
+	if array == otherArray {
+		return TRUE, nil
+	}
+	for i, elem := range array.Elements {
+		isEqual, err := equal(elem, otherArray.Elements[i])
+		if err != nil {
+			return nil, err
+		}
+		if !isEqual {
+			return FALSE, nil
+		}
+	}
+	return TRUE, nil
+}
+
+// arrayHash combines the hash values of all elements in order
+func arrayHash(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	result := int64(len(array.Elements))
+	for _, elem := range array.Elements {
+		hash, err := sendHash(elem)
+		if err != nil {
+			return nil, err
+		}
+		result = result*31 + hash
+	}
+	return NewInteger(result), nil
+}
+
+func arrayInclude(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	index, err := arrayIndexOf(context, args...)
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(index != NIL), nil
+}
+
+// arrayIndexOf returns the index of the first element equal to the argument
+// or, if a block is given instead, the first element for which the block
+// returns a truthy value
+func arrayIndexOf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	if len(args) == 0 && block == nil {
+		return NIL, nil
+	}
+	for i, elem := range array.Elements {
+		var found bool
+		if len(args) == 1 {
+			equal, err := isEqual(elem, args[0])
+			if err != nil {
+				return nil, err
+			}
+			found = equal
+		} else {
+			result, err := block.Call(elem)
+			if err != nil {
+				return nil, err
+			}
+			found = isTruthy(result)
+		}
+		if found {
+			return NewInteger(int64(i)), nil
+		}
+	}
+	return NIL, nil
+}
+
+// arraySize returns the size argument of methods like Array#take. It
+// returns an ArgumentError naming action if the size is negative.
+func arraySize(arg RubyObject, action string) (int, error) {
+	size, err := integerArgument(arg)
+	if err != nil {
+		return 0, err
+	}
+	if size.Value < 0 {
+		return 0, NewArgumentError("attempt to %s negative size", action)
+	}
+	return int(size.Value), nil
+}
+
+func arrayFirst(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	if len(args) == 0 {
+		if len(array.Elements) == 0 {
+			return NIL, nil
+		}
+		return array.Elements[0], nil
+	}
+	return arrayTake(array, args...)
+}
+
+func arrayLast(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	length := len(array.Elements)
+	if len(args) == 0 {
+		if length == 0 {
+			return NIL, nil
+		}
+		return array.Elements[length-1], nil
+	}
+	count, err := arraySize(args[0], "take")
+	if err != nil {
+		return nil, err
+	}
+	if count > length {
+		count = length
+	}
+	return NewArray(array.Elements[length-count:]...), nil
+}
+
+func arrayTake(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	count, err := arraySize(args[0], "take")
+	if err != nil {
+		return nil, err
+	}
+	if count > len(array.Elements) {
+		count = len(array.Elements)
+	}
+	return NewArray(array.Elements[:count]...), nil
+}
+
+func arrayDrop(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	count, err := arraySize(args[0], "drop")
+	if err != nil {
+		return nil, err
+	}
+	if count > len(array.Elements) {
+		count = len(array.Elements)
+	}
+	return NewArray(array.Elements[count:]...), nil
+}
+
+// arrayFlatten returns a new Array with all nested Arrays replaced by their
+// elements, up to the optional depth
+func arrayFlatten(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	depth := -1
+	if len(args) == 1 && args[0] != NIL {
+		level, err := integerArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		depth = int(level.Value)
+	}
+	flattened := NewArray()
+	err := flattenInto(flattened, array, depth, map[*Array]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return flattened, nil
+}
+
+// flattenInto appends the elements of array to result, flattening nested
+// Arrays until depth reaches 0. A negative depth flattens completely.
+func flattenInto(result, array *Array, depth int, seen map[*Array]bool) error {
+	if seen[array] {
+		return NewArgumentError("tried to flatten recursive array")
+	}
+	seen[array] = true
+	defer delete(seen, array)
+	for _, elem := range array.Elements {
+		nested, ok := elem.(*Array)
+		if !ok || depth == 0 {
+			result.Elements = append(result.Elements, elem)
+			continue
+		}
+		if err := flattenInto(result, nested, depth-1, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func arrayCompact(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	compacted := NewArray()
+	for _, elem := range array.Elements {
+		if elem != NIL {
+			compacted.Elements = append(compacted.Elements, elem)
+		}
+	}
+	return compacted, nil
+}
+
+// arrayUniq returns a new Array without duplicates, keeping the first
+// occurrence. If a block is given, elements are considered duplicates if
+// the block returns the same value for them.
+func arrayUniq(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	_, block := extractBlock(args)
+	seen := NewHash(nil)
+	unique := NewArray()
+	for _, elem := range array.Elements {
+		key := elem
+		if block != nil {
+			var err error
+			key, err = block.Call(elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := seen.Get(key); ok {
+			continue
+		}
+		seen.Set(key, TRUE)
+		unique.Elements = append(unique.Elements, elem)
+	}
+	return unique, nil
+}
+
+func arrayReverse(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	length := len(array.Elements)
+	reversed := newArrayOf(make([]RubyObject, length))
+	for i, elem := range array.Elements {
+		reversed.Elements[length-1-i] = elem
+	}
+	return reversed, nil
+}
+
+// arrayJoin returns the string representations of all elements separated by
+// the optional separator. Nested Arrays are joined recursively.
+func arrayJoin(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	separator := ""
+	if len(args) == 1 && args[0] != NIL {
+		sep, err := stringArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		separator = sep.Value
+	}
+	var parts []string
+	err := joinInto(&parts, array, map[*Array]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: strings.Join(parts, separator)}, nil
+}
+
+func joinInto(parts *[]string, array *Array, seen map[*Array]bool) error {
+	if seen[array] {
+		return NewArgumentError("recursive array join")
+	}
+	seen[array] = true
+	defer delete(seen, array)
+	for _, elem := range array.Elements {
+		if nested, ok := elem.(*Array); ok {
+			if err := joinInto(parts, nested, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		*parts = append(*parts, toS(elem))
+	}
+	return nil
+}
+
+// arrayZip combines each element with the elements at the same index of the
+// argument Arrays, filling missing ones with nil
+func arrayZip(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	others := make([]*Array, len(args))
+	for i, arg := range args {
+		other, ok := arg.(*Array)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(array, arg)
+		}
+		others[i] = other
+	}
+	zipped := NewArray()
+	for i, elem := range array.Elements {
+		tuple := NewArray(elem)
+		for _, other := range others {
+			var value RubyObject = NIL
+			if i < len(other.Elements) {
+				value = other.Elements[i]
+			}
+			tuple.Elements = append(tuple.Elements, value)
+		}
+		if block != nil {
+			if _, err := block.Call(tuple); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		zipped.Elements = append(zipped.Elements, tuple)
+	}
+	if block != nil {
+		return NIL, nil
+	}
+	return zipped, nil
+}
+
+// arrayEachSlice yields consecutive, non-overlapping slices of the given
+// size
+func arrayEachSlice(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	size, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if size.Value <= 0 {
+		return nil, NewArgumentError("invalid slice size")
+	}
+	if block == nil {
+		return NewEnumerator(array, "each_slice", size), nil
+	}
+	var slices []*Array
+	for start := 0; start < len(array.Elements); start += int(size.Value) {
+		end := start + int(size.Value)
+		if end > len(array.Elements) {
+			end = len(array.Elements)
+		}
+		slices = append(slices, NewArray(array.Elements[start:end]...))
+	}
+	return yieldSlices(array, slices, block)
+}
+
+// arrayEachCons yields every run of consecutive elements of the given size
+func arrayEachCons(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	args, block := extractBlock(args)
+	size, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if size.Value <= 0 {
+		return nil, NewArgumentError("invalid size")
+	}
+	if block == nil {
+		return NewEnumerator(array, "each_cons", size), nil
+	}
+	var slices []*Array
+	for start := 0; start+int(size.Value) <= len(array.Elements); start++ {
+		slices = append(slices, NewArray(array.Elements[start:start+int(size.Value)]...))
+	}
+	return yieldSlices(array, slices, block)
+}
+
+// yieldSlices passes every slice to block and returns array
+func yieldSlices(array *Array, slices []*Array, block *Proc) (RubyObject, error) {
+	for _, slice := range slices {
+		if _, err := block.Call(slice); err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+// arraySlice implements Array#[] and Array#slice. It accepts an index, a
+// start index and a length or a Range of indices. Negative indices count
+// from the end.
+func arraySlice(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	length := len(array.Elements)
+	if r, ok := args[0].(*Range); ok && len(args) == 1 {
+		start, count, ok, err := r.indices(length)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return NIL, nil
+		}
+		return NewArray(array.Elements[start : start+count]...), nil
+	}
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	start := arrayIndex(index.Value, length)
+	if len(args) == 1 {
+		if start < 0 || start >= length {
+			return NIL, nil
+		}
+		return array.Elements[start], nil
+	}
+	count, err := integerArgument(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || start > length || count.Value < 0 {
+		return NIL, nil
+	}
+	end := start + int(count.Value)
+	if end > length || end < start {
+		end = length
+	}
+	return NewArray(array.Elements[start:end]...), nil
+}
+
+// arraySliceAssign implements Array#[]=. With an index it sets a single
+// element, with a start index and a length or a Range it replaces the
+// section by the elements of the assigned Array or by the assigned object.
+// Assigning beyond the end pads the array with nil.
+func arraySliceAssign(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	array := context.(*Array)
+	array.modify()
+	value := args[len(args)-1]
+	length := len(array.Elements)
+	var start, count int
+	switch {
+	case len(args) == 3:
+		index, err := integerArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		size, err := integerArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		start = arrayIndex(index.Value, length)
+		if start < 0 {
+			return nil, NewIndexError("index %d too small for array; minimum: -%d", index.Value, length)
+		}
+		if size.Value < 0 {
+			return nil, NewIndexError("negative length (%d)", size.Value)
+		}
+		count = int(size.Value)
+	default:
+		r, ok := args[0].(*Range)
+		if !ok {
+			index, err := integerArgument(args[0])
+			if err != nil {
+				return nil, err
+			}
+			position := arrayIndex(index.Value, length)
+			if position < 0 {
+				return nil, NewIndexError("index %d too small for array; minimum: -%d", index.Value, length)
+			}
+			for len(array.Elements) <= position {
+				array.Elements = append(array.Elements, NIL)
+			}
+			array.Elements[position] = value
+			return value, nil
+		}
+		first, err := integerArgument(r.First)
+		if err != nil {
+			return nil, err
+		}
+		last, err := integerArgument(r.Last)
+		if err != nil {
+			return nil, err
+		}
+		start = arrayIndex(first.Value, length)
+		if start < 0 {
+			return nil, NewRangeError("%s out of range", r.Inspect())
+		}
+		end := arrayIndex(last.Value, length)
+		if !r.Exclusive {
+			end++
+		}
+		if end > start {
+			count = end - start
+		}
+	}
+	for len(array.Elements) < start {
+		array.Elements = append(array.Elements, NIL)
+	}
+	end := start + count
+	if end > len(array.Elements) {
+		end = len(array.Elements)
+	}
+	replacement := []RubyObject{value}
+	if values, ok := value.(*Array); ok {
+		replacement = values.Elements
+	}
+	elements := make([]RubyObject, 0, len(array.Elements)-(end-start)+len(replacement))
+	elements = append(elements, array.Elements[:start]...)
+	elements = append(elements, replacement...)
+	array.Elements = append(elements, array.Elements[end:]...)
+	return value, nil
+}
+
+// arrayDig returns the element at the first index and sends `dig` with the
+// remaining indices to it. It returns nil as soon as a value is nil.
+func arrayDig(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	value, err := arraySlice(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 || value == NIL {
+		return value, nil
+	}
+	return Send(value, "dig", args[1:]...)
+}
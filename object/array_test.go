@@ -0,0 +1,509 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+)
+
+// testBlock returns a Proc with the given parameters which calls fn with the
+// values bound to them
+func testBlock(fn func(args ...RubyObject) (RubyObject, error), params ...string) *Proc {
+	block := &Proc{Env: NewEnvironment()}
+	for _, param := range params {
+		block.Parameters = append(block.Parameters, &ast.Identifier{Value: param})
+	}
+	block.CallFn = func(body *ast.BlockStatement, env Environment) (RubyObject, error) {
+		args := make([]RubyObject, len(params))
+		for i, param := range params {
+			args[i], _ = env.Get(param)
+		}
+		return fn(args...)
+	}
+	return block
+}
+
+func TestArraySort(t *testing.T) {
+	t.Run("comparable elements", func(t *testing.T) {
+		array := NewArray(NewInteger(3), NewInteger(1), NewInteger(2))
+
+		result, err := arraySort(array)
+
+		checkError(t, err, nil)
+
+		expected := NewArray(NewInteger(1), NewInteger(2), NewInteger(3))
+		if !reflect.DeepEqual(expected, result) {
+			t.Logf("Expected sorted array to equal %s, got %s", toString(expected), toString(result))
+			t.Fail()
+		}
+
+		original := NewArray(NewInteger(3), NewInteger(1), NewInteger(2))
+		if !reflect.DeepEqual(original, array) {
+			t.Logf("Expected array not to be modified, got %s", toString(array))
+			t.Fail()
+		}
+	})
+	t.Run("incomparable elements", func(t *testing.T) {
+		array := NewArray(NewInteger(3), &String{Value: "a"})
+
+		_, err := arraySort(array)
+
+		checkError(t, err, NewComparisonError(&String{Value: "a"}, NewInteger(3)))
+	})
+}
+
+func TestArrayMinMax(t *testing.T) {
+	tests := []struct {
+		array *Array
+		min   RubyObject
+		max   RubyObject
+	}{
+		{NewArray(NewInteger(3), NewInteger(1), NewInteger(2)), NewInteger(1), NewInteger(3)},
+		{NewArray(&String{Value: "b"}, &String{Value: "a"}), &String{Value: "a"}, &String{Value: "b"}},
+		{NewArray(), NIL, NIL},
+	}
+
+	for _, tt := range tests {
+		min, err := arrayMin(tt.array)
+		checkError(t, err, nil)
+		checkResult(t, min, tt.min)
+
+		max, err := arrayMax(tt.array)
+		checkError(t, err, nil)
+		checkResult(t, max, tt.max)
+	}
+}
+
+func TestArrayIteration(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	double := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerMul(args[0], NewInteger(2))
+	}, "x")
+	isEven := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return nativeBoolToBoolean(args[0].(*Integer).Value%2 == 0), nil
+	}, "x")
+	sum := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerAdd(args[0], args[1])
+	}, "acc", "x")
+
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"map", arrayMap, []RubyObject{double}, ints(2, 4, 6, 8)},
+		{"map without block", arrayMap, nil, NewEnumerator(ints(1, 2, 3, 4), "map")},
+		{"select without block", arraySelect, nil, NewEnumerator(ints(1, 2, 3, 4), "select")},
+		{"select", arraySelect, []RubyObject{isEven}, ints(2, 4)},
+		{"reject", arrayReject, []RubyObject{isEven}, ints(1, 3)},
+		{"reduce with block", arrayReduce, []RubyObject{sum}, NewInteger(10)},
+		{"reduce with initial value", arrayReduce, []RubyObject{NewInteger(5), sum}, NewInteger(15)},
+		{"reduce with symbol", arrayReduce, []RubyObject{&Symbol{Value: "+"}}, NewInteger(10)},
+		{"reduce with initial value and symbol", arrayReduce, []RubyObject{NewInteger(2), &Symbol{Value: "*"}}, NewInteger(48)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(ints(1, 2, 3, 4), tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	t.Run("reduce empty array", func(t *testing.T) {
+		result, err := arrayReduce(NewArray(), sum)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NIL)
+	})
+	t.Run("reduce without block or symbol", func(t *testing.T) {
+		_, err := arrayReduce(ints(1))
+
+		checkError(t, err, NewArgumentError("no block given"))
+	})
+	t.Run("each and each_with_index", func(t *testing.T) {
+		var yielded []RubyObject
+		collect := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			yielded = append(yielded, args...)
+			return NIL, nil
+		}, "x", "i")
+		array := NewArray(&String{Value: "a"}, &String{Value: "b"})
+
+		result, err := arrayEach(array, collect)
+		checkError(t, err, nil)
+		checkResult(t, result, array)
+
+		result, err = arrayEachWithIndex(array, collect)
+		checkError(t, err, nil)
+		checkResult(t, result, array)
+
+		result, err = arrayEachWithIndex(array)
+		checkError(t, err, nil)
+		checkResult(t, result, NewEnumerator(array, "each_with_index"))
+
+		expected := []RubyObject{
+			&String{Value: "a"}, NIL, &String{Value: "b"}, NIL,
+			&String{Value: "a"}, NewInteger(0), &String{Value: "b"}, NewInteger(1),
+		}
+		checkResult(t, NewArray(yielded...), NewArray(expected...))
+	})
+	t.Run("each_with_object", func(t *testing.T) {
+		memo := NewArray()
+		prepend := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			memo := args[1].(*Array)
+			memo.Elements = append([]RubyObject{args[0]}, memo.Elements...)
+			return NIL, nil
+		}, "x", "memo")
+
+		result, err := arrayEachWithObject(ints(1, 2, 3), memo, prepend)
+
+		checkError(t, err, nil)
+		if result != memo {
+			t.Logf("Expected each_with_object to return the memo, got %s", toString(result))
+			t.Fail()
+		}
+		checkResult(t, result, ints(3, 2, 1))
+	})
+}
+
+func TestArrayOrdering(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	str := func(value string) *String { return &String{Value: value} }
+	descending := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerSpaceship(args[1], args[0])
+	}, "a", "b")
+	length := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return stringLength(args[0])
+	}, "s")
+	words := NewArray(str("ccc"), str("a"), str("bb"), str("dd"))
+
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  *Array
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"sort with block", arraySort, ints(2, 3, 1), []RubyObject{descending}, ints(3, 2, 1)},
+		{"sort_by", arraySortBy, words, []RubyObject{length}, NewArray(str("a"), str("bb"), str("dd"), str("ccc"))},
+		{"min with block", arrayMin, ints(2, 3, 1), []RubyObject{descending}, NewInteger(3)},
+		{"max with block", arrayMax, ints(2, 3, 1), []RubyObject{descending}, NewInteger(1)},
+		{"min_by", arrayMinBy, words, []RubyObject{length}, str("a")},
+		{"max_by", arrayMaxBy, words, []RubyObject{length}, str("ccc")},
+		{"min_by empty", arrayMinBy, NewArray(), []RubyObject{length}, NIL},
+		{"sum", arraySum, ints(1, 2, 3), nil, NewInteger(6)},
+		{"sum empty", arraySum, NewArray(), nil, NewInteger(0)},
+		{"sum floats", arraySum, NewArray(NewInteger(1), NewFloat(0.5)), nil, NewFloat(1.5)},
+		{"sum with initial value", arraySum, NewArray(str("a"), str("b")), []RubyObject{str(">")}, str(">ab")},
+		{"sum with block", arraySum, words, []RubyObject{length}, NewInteger(8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(tt.context, tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	t.Run("sort with invalid block result", func(t *testing.T) {
+		invalid := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			return NIL, nil
+		}, "a", "b")
+
+		_, err := arraySort(ints(1, 2), invalid)
+
+		checkError(t, err, NewComparisonError(NewInteger(2), NewInteger(1)))
+	})
+	t.Run("sum of incompatible elements", func(t *testing.T) {
+		_, err := arraySum(NewArray(str("a")))
+
+		checkError(t, err, NewCoercionTypeError(str("a"), NewInteger(0)))
+	})
+}
+
+func TestArrayMutation(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	index := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerMul(args[0], NewInteger(10))
+	}, "i")
+	isEven := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return nativeBoolToBoolean(args[0].(*Integer).Value%2 == 0), nil
+	}, "x")
+	notFound := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return &String{Value: "not found"}, nil
+	}, "x")
+
+	tests := []struct {
+		name           string
+		method         func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		args           []RubyObject
+		expectedResult RubyObject
+		expectedArray  *Array
+	}{
+		{"push", arrayPush, []RubyObject{NewInteger(4), NewInteger(5)}, nil, ints(1, 2, 3, 4, 5)},
+		{"pop", arrayPop, nil, NewInteger(3), ints(1, 2)},
+		{"pop with count", arrayPop, []RubyObject{NewInteger(2)}, ints(2, 3), ints(1)},
+		{"pop with large count", arrayPop, []RubyObject{NewInteger(5)}, ints(1, 2, 3), ints()},
+		{"shift", arrayShift, nil, NewInteger(1), ints(2, 3)},
+		{"shift with count", arrayShift, []RubyObject{NewInteger(2)}, ints(1, 2), ints(3)},
+		{"unshift", arrayUnshift, []RubyObject{NewInteger(-1), NewInteger(0)}, nil, ints(-1, 0, 1, 2, 3)},
+		{"insert", arrayInsert, []RubyObject{NewInteger(1), NewInteger(9)}, nil, ints(1, 9, 2, 3)},
+		{"insert negative index", arrayInsert, []RubyObject{NewInteger(-2), NewInteger(9)}, nil, ints(1, 2, 9, 3)},
+		{"insert beyond end", arrayInsert, []RubyObject{NewInteger(5), NewInteger(9)}, nil, NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NIL, NIL, NewInteger(9))},
+		{"concat", arrayConcat, []RubyObject{ints(4), ints(5, 6)}, nil, ints(1, 2, 3, 4, 5, 6)},
+		{"delete", arrayDelete, []RubyObject{NewInteger(2)}, NewInteger(2), ints(1, 3)},
+		{"delete missing", arrayDelete, []RubyObject{NewInteger(5)}, NIL, ints(1, 2, 3)},
+		{"delete missing with block", arrayDelete, []RubyObject{NewInteger(5), notFound}, &String{Value: "not found"}, ints(1, 2, 3)},
+		{"delete_at", arrayDeleteAt, []RubyObject{NewInteger(-1)}, NewInteger(3), ints(1, 2)},
+		{"delete_at out of range", arrayDeleteAt, []RubyObject{NewInteger(3)}, NIL, ints(1, 2, 3)},
+		{"delete_if", arrayDeleteIf, []RubyObject{isEven}, nil, ints(1, 3)},
+		{"clear", arrayClear, nil, nil, ints()},
+		{"fill", arrayFill, []RubyObject{NewInteger(0)}, nil, ints(0, 0, 0)},
+		{"fill from start", arrayFill, []RubyObject{NewInteger(0), NewInteger(-2)}, nil, ints(1, 0, 0)},
+		{"fill with length", arrayFill, []RubyObject{NewInteger(0), NewInteger(2), NewInteger(3)}, nil, ints(1, 2, 0, 0, 0)},
+		{"fill range", arrayFill, []RubyObject{NewInteger(0), &Range{First: NewInteger(0), Last: NewInteger(1)}}, nil, ints(0, 0, 3)},
+		{"fill with block", arrayFill, []RubyObject{NewInteger(1), index}, nil, ints(1, 10, 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			array := ints(1, 2, 3)
+
+			result, err := tt.method(array, tt.args...)
+
+			checkError(t, err, nil)
+			if tt.expectedResult == nil {
+				if result != array {
+					t.Logf("Expected method to return the receiver, got %s", toString(result))
+					t.Fail()
+				}
+			} else {
+				checkResult(t, result, tt.expectedResult)
+			}
+			checkResult(t, array, tt.expectedArray)
+		})
+	}
+
+	t.Run("insert with too small index", func(t *testing.T) {
+		_, err := arrayInsert(ints(1, 2, 3), NewInteger(-5), NewInteger(0))
+
+		checkError(t, err, NewIndexError("index -5 too small for array; minimum: -4"))
+	})
+	t.Run("pop with negative count", func(t *testing.T) {
+		_, err := arrayPop(ints(1), NewInteger(-1))
+
+		checkError(t, err, NewArgumentError("negative array size"))
+	})
+	t.Run("concat with non array", func(t *testing.T) {
+		_, err := arrayConcat(ints(1), NewInteger(2))
+
+		checkError(t, err, NewImplicitConversionTypeError(NewArray(), NewInteger(2)))
+	})
+}
+
+func TestArrayQueries(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	str := func(value string) *String { return &String{Value: value} }
+	isEven := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return nativeBoolToBoolean(args[0].(*Integer).Value%2 == 0), nil
+	}, "x")
+	parity := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return NewInteger(args[0].(*Integer).Value % 2), nil
+	}, "x")
+	nested := NewArray(NewInteger(1), NewArray(NewInteger(2), NewArray(NewInteger(3), NIL)))
+
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  *Array
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"==", arrayEqual, nested, []RubyObject{NewArray(NewInteger(1), NewArray(NewInteger(2), NewArray(NewInteger(3), NIL)))}, TRUE},
+		{"== with different elements", arrayEqual, ints(1, 2), []RubyObject{ints(1, 3)}, FALSE},
+		{"== with different length", arrayEqual, ints(1, 2), []RubyObject{ints(1)}, FALSE},
+		{"== with non array", arrayEqual, ints(), []RubyObject{NIL}, FALSE},
+		{"include?", arrayInclude, NewArray(str("a"), str("b")), []RubyObject{str("b")}, TRUE},
+		{"include? nested", arrayInclude, nested, []RubyObject{NewArray(NewInteger(2), NewArray(NewInteger(3), NIL))}, TRUE},
+		{"include? missing", arrayInclude, ints(1, 2), []RubyObject{NewInteger(3)}, FALSE},
+		{"index", arrayIndexOf, ints(3, 4, 4), []RubyObject{NewInteger(4)}, NewInteger(1)},
+		{"index missing", arrayIndexOf, ints(3, 4), []RubyObject{NewInteger(5)}, NIL},
+		{"index with block", arrayIndexOf, ints(3, 4), []RubyObject{isEven}, NewInteger(1)},
+		{"first", arrayFirst, ints(1, 2, 3), nil, NewInteger(1)},
+		{"first empty", arrayFirst, ints(), nil, NIL},
+		{"first with count", arrayFirst, ints(1, 2, 3), []RubyObject{NewInteger(2)}, ints(1, 2)},
+		{"last", arrayLast, ints(1, 2, 3), nil, NewInteger(3)},
+		{"last with count", arrayLast, ints(1, 2, 3), []RubyObject{NewInteger(5)}, ints(1, 2, 3)},
+		{"take", arrayTake, ints(1, 2, 3), []RubyObject{NewInteger(2)}, ints(1, 2)},
+		{"drop", arrayDrop, ints(1, 2, 3), []RubyObject{NewInteger(2)}, ints(3)},
+		{"drop all", arrayDrop, ints(1, 2, 3), []RubyObject{NewInteger(4)}, ints()},
+		{"flatten", arrayFlatten, nested, nil, NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NIL)},
+		{"flatten with depth", arrayFlatten, nested, []RubyObject{NewInteger(1)}, NewArray(NewInteger(1), NewInteger(2), NewArray(NewInteger(3), NIL))},
+		{"compact", arrayCompact, NewArray(NIL, NewInteger(1), NIL), nil, ints(1)},
+		{"uniq", arrayUniq, NewArray(str("a"), str("b"), str("a")), nil, NewArray(str("a"), str("b"))},
+		{"uniq with block", arrayUniq, ints(1, 2, 3, 4), []RubyObject{parity}, ints(1, 2)},
+		{"reverse", arrayReverse, ints(1, 2, 3), nil, ints(3, 2, 1)},
+		{"join", arrayJoin, nested, nil, str("123")},
+		{"join with separator", arrayJoin, NewArray(str("a"), NewInteger(1), &Symbol{Value: "b"}, NIL), []RubyObject{str("-")}, str("a-1-b-")},
+		{"zip", arrayZip, ints(1, 2), []RubyObject{ints(3, 4), ints(5)}, NewArray(ints(1, 3, 5), NewArray(NewInteger(2), NewInteger(4), NIL))},
+		{"each_slice without block", arrayEachSlice, ints(1, 2, 3), []RubyObject{NewInteger(2)}, NewEnumerator(ints(1, 2, 3), "each_slice", NewInteger(2))},
+		{"each_cons without block", arrayEachCons, ints(1, 2, 3), []RubyObject{NewInteger(2)}, NewEnumerator(ints(1, 2, 3), "each_cons", NewInteger(2))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(tt.context, tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	t.Run("flatten recursive array", func(t *testing.T) {
+		recursive := ints(1)
+		recursive.Elements = append(recursive.Elements, recursive)
+
+		_, err := arrayFlatten(recursive)
+
+		checkError(t, err, NewArgumentError("tried to flatten recursive array"))
+	})
+	t.Run("take negative size", func(t *testing.T) {
+		_, err := arrayTake(ints(1), NewInteger(-1))
+
+		checkError(t, err, NewArgumentError("attempt to take negative size"))
+	})
+	t.Run("each_slice invalid size", func(t *testing.T) {
+		_, err := arrayEachSlice(ints(1), NewInteger(0))
+
+		checkError(t, err, NewArgumentError("invalid slice size"))
+	})
+}
+
+func TestArraySlice(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	rng := func(first, last int64, exclusive bool) *Range {
+		return &Range{First: NewInteger(first), Last: NewInteger(last), Exclusive: exclusive}
+	}
+
+	tests := []struct {
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{[]RubyObject{NewInteger(0)}, NewInteger(1)},
+		{[]RubyObject{NewInteger(-1)}, NewInteger(5)},
+		{[]RubyObject{NewInteger(5)}, NIL},
+		{[]RubyObject{NewInteger(-6)}, NIL},
+		{[]RubyObject{NewInteger(2), NewInteger(2)}, ints(3, 4)},
+		{[]RubyObject{NewInteger(-2), NewInteger(5)}, ints(4, 5)},
+		{[]RubyObject{NewInteger(5), NewInteger(1)}, ints()},
+		{[]RubyObject{NewInteger(6), NewInteger(1)}, NIL},
+		{[]RubyObject{NewInteger(1), NewInteger(-1)}, NIL},
+		{[]RubyObject{rng(1, 3, false)}, ints(2, 3, 4)},
+		{[]RubyObject{rng(1, 3, true)}, ints(2, 3)},
+		{[]RubyObject{rng(-2, -1, false)}, ints(4, 5)},
+		{[]RubyObject{rng(3, 1, false)}, ints()},
+		{[]RubyObject{rng(5, 7, false)}, ints()},
+		{[]RubyObject{rng(6, 7, false)}, NIL},
+		{[]RubyObject{rng(-6, 1, false)}, NIL},
+	}
+
+	for _, tt := range tests {
+		result, err := arraySlice(ints(1, 2, 3, 4, 5), tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestArraySliceAssign(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	rng := func(first, last int64, exclusive bool) *Range {
+		return &Range{First: NewInteger(first), Last: NewInteger(last), Exclusive: exclusive}
+	}
+	x := &Symbol{Value: "x"}
+
+	tests := []struct {
+		args     []RubyObject
+		expected *Array
+	}{
+		{[]RubyObject{NewInteger(0), x}, NewArray(x, NewInteger(2), NewInteger(3))},
+		{[]RubyObject{NewInteger(-1), x}, NewArray(NewInteger(1), NewInteger(2), x)},
+		{[]RubyObject{NewInteger(4), x}, NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NIL, x)},
+		{[]RubyObject{NewInteger(0), ints(7, 8)}, NewArray(ints(7, 8), NewInteger(2), NewInteger(3))},
+		{[]RubyObject{NewInteger(1), NewInteger(1), x}, NewArray(NewInteger(1), x, NewInteger(3))},
+		{[]RubyObject{NewInteger(1), NewInteger(0), ints(7, 8)}, ints(1, 7, 8, 2, 3)},
+		{[]RubyObject{NewInteger(1), NewInteger(5), ints()}, ints(1)},
+		{[]RubyObject{NewInteger(5), NewInteger(1), x}, NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NIL, NIL, x)},
+		{[]RubyObject{rng(1, 2, false), NewArray(x)}, NewArray(NewInteger(1), x)},
+		{[]RubyObject{rng(0, 0, true), x}, NewArray(x, NewInteger(1), NewInteger(2), NewInteger(3))},
+		{[]RubyObject{rng(2, 0, false), ints(9)}, ints(1, 2, 9, 3)},
+		{[]RubyObject{rng(-1, 5, false), ints(8, 9)}, ints(1, 2, 8, 9)},
+	}
+
+	for _, tt := range tests {
+		array := ints(1, 2, 3)
+
+		result, err := arraySliceAssign(array, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.args[len(tt.args)-1])
+		checkResult(t, array, tt.expected)
+	}
+
+	errorTests := []struct {
+		args []RubyObject
+		err  error
+	}{
+		{[]RubyObject{NewInteger(-4), x}, NewIndexError("index -4 too small for array; minimum: -3")},
+		{[]RubyObject{NewInteger(-4), NewInteger(1), x}, NewIndexError("index -4 too small for array; minimum: -3")},
+		{[]RubyObject{NewInteger(0), NewInteger(-1), x}, NewIndexError("negative length (-1)")},
+		{[]RubyObject{rng(-4, 1, false), x}, NewRangeError("-4..1 out of range")},
+	}
+
+	for _, tt := range errorTests {
+		_, err := arraySliceAssign(ints(1, 2, 3), tt.args...)
+
+		checkError(t, err, tt.err)
+	}
+}
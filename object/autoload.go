@@ -0,0 +1,125 @@
+package object
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// topLevel is the name of the module top level constants belong to
+const topLevel = "Object"
+
+// autoloadTable holds the files registered to define constants on first
+// reference, per name of the module they have been registered for
+type autoloadTable struct {
+	sync.Mutex
+	paths map[string]map[string]string
+}
+
+// sharedAutoloads holds the autoloads registered outside of any Runtime
+var sharedAutoloads = autoloadTable{paths: make(map[string]map[string]string)}
+
+// autoloadTable returns the table autoloads are registered within
+func (r *Runtime) autoloadTable() *autoloadTable {
+	if r == nil {
+		return &sharedAutoloads
+	}
+	return &r.autoloads
+}
+
+// register registers path to be required when the constant name is
+// referenced within module
+func (a *autoloadTable) register(module, name, path string) {
+	a.Lock()
+	defer a.Unlock()
+	if a.paths[module] == nil {
+		a.paths[module] = make(map[string]string)
+	}
+	a.paths[module][name] = path
+}
+
+// path returns the path registered for the constant name within module
+func (a *autoloadTable) path(module, name string) (string, bool) {
+	a.Lock()
+	defer a.Unlock()
+	path, ok := a.paths[module][name]
+	return path, ok
+}
+
+// Autoload returns the path registered via autoload within r for the top
+// level constant name and removes the registration, as the constant is
+// expected to be defined once the file is required. ok is false if there
+// is no registration for name.
+func (r *Runtime) Autoload(name string) (path string, ok bool) {
+	autoloads := r.autoloadTable()
+	autoloads.Lock()
+	defer autoloads.Unlock()
+	path, ok = autoloads.paths[topLevel][name]
+	delete(autoloads.paths[topLevel], name)
+	return path, ok
+}
+
+// IsConstantName reports whether name is a valid constant name, i.e. starts
+// with an uppercase letter
+func IsConstantName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// autoloadArguments validates the constant name and path passed to
+// autoload
+func autoloadArguments(args []RubyObject) (string, string, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return "", "", err
+	}
+	if !IsConstantName(name) {
+		return "", "", NewInvalidConstantNameError("autoload must be constant name: %s", name)
+	}
+	path, err := stringArgument(args[1])
+	if err != nil {
+		return "", "", err
+	}
+	if path.Value == "" {
+		return "", "", NewArgumentError("empty file name")
+	}
+	return name, path.Value, nil
+}
+
+// kernelAutoload registers a file to be required when the given top level
+// constant is referenced the first time
+func kernelAutoload(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, path, err := autoloadArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	r.autoloadTable().register(topLevel, name, path)
+	return NIL, nil
+}
+
+// kernelIsAutoload returns the path registered for the top level constant
+// or nil if there is none
+func kernelIsAutoload(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.isAutoload(topLevel, args[0])
+}
+
+func moduleAutoload(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, path, err := autoloadArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	r.autoloadTable().register(context.Inspect(), name, path)
+	return NIL, nil
+}
+
+func moduleIsAutoload(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.isAutoload(context.Inspect(), args[0])
+}
+
+func (r *Runtime) isAutoload(module string, name RubyObject) (RubyObject, error) {
+	path, ok := r.autoloadTable().path(module, toS(name))
+	if !ok {
+		return NIL, nil
+	}
+	return &String{Value: path}, nil
+}
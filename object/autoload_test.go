@@ -0,0 +1,28 @@
+package object
+
+import "testing"
+
+func TestModuleAutoload(t *testing.T) {
+	module := newModule("AutoloadTest", nil)
+
+	result, err := moduleAutoload(nil, module, &Symbol{"Foo"}, &String{Value: "foo"})
+	checkError(t, err, nil)
+	checkResult(t, result, NIL)
+
+	result, err = moduleIsAutoload(nil, module, &Symbol{"Foo"})
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "foo"})
+
+	result, err = kernelIsAutoload(nil, &Object{}, &Symbol{"Foo"})
+	checkError(t, err, nil)
+	checkResult(t, result, NIL)
+
+	_, ok := (*Runtime)(nil).Autoload("Foo")
+	if ok {
+		t.Logf("Expected autoload within module not to be registered at top level")
+		t.Fail()
+	}
+
+	_, err = moduleAutoload(nil, module, &Symbol{"Foo"}, &String{Value: ""})
+	checkError(t, err, NewArgumentError("empty file name"))
+}
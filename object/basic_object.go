@@ -3,11 +3,17 @@ package object
 var basicObjectClass RubyClassObject = newClass("BasicObject", nil, basicObjectMethods, basicObjectClassMethods)
 
 func init() {
+	setAllocator(basicObjectClass, func(class RubyClassObject) RubyObject {
+		return &basicObject{class: class}
+	})
 	classes.Set("BasicObject", basicObjectClass)
 }
 
 // basicObject represents a basicObject object in Ruby
-type basicObject struct{}
+type basicObject struct {
+	ivars instanceVariableTable
+	class RubyClass
+}
 
 // Inspect returns empty string. BasicObjects do not have an `inspect` method.
 func (b *basicObject) Inspect() string { return "" }
@@ -15,17 +21,38 @@ func (b *basicObject) Inspect() string { return "" }
 // Type returns the ObjectType of the array
 func (b *basicObject) Type() Type { return BASIC_OBJECT_OBJ }
 
-// Class returns the class of BasicObject
-func (b *basicObject) Class() RubyClass { return basicObjectClass }
+// Class returns the class the object has been allocated by, or
+// basicObjectClass
+func (b *basicObject) Class() RubyClass {
+	if b.class != nil {
+		return b.class
+	}
+	return basicObjectClass
+}
+
+func (b *basicObject) instanceVariables() *instanceVariableTable { return &b.ivars }
 
-var basicObjectClassMethods = map[string]RubyMethod{
-	"new": publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
-		return &basicObject{}, nil
-	}),
+func (b *basicObject) singletonClass() *eigenclass {
+	if eigenClass, ok := b.class.(*eigenclass); ok {
+		return eigenClass
+	}
+	eigenClass := newEigenclass(b.Class(), nil)
+	b.class = eigenClass
+	return eigenClass
 }
 
+var basicObjectClassMethods = map[string]RubyMethod{}
+
 var basicObjectMethods = map[string]RubyMethod{
+	"initialize":     withArity(0, privateMethod(basicObjectInitialize)),
 	"method_missing": privateMethod(basicObjectMethodMissing),
+	"__send__":       withArityRange(1, -1, publicRuntimeMethod(kernelSend)),
+	"__id__":         withArity(0, publicMethod(kernelObjectID)),
+	"equal?":         withArity(1, publicMethod(kernelIsEqual)),
+}
+
+func basicObjectInitialize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NIL, nil
 }
 
 func basicObjectMethodMissing(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -0,0 +1,494 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	benchmarkModule      = newModule("Benchmark", benchmarkMethods)
+	benchmarkTmsClass    = newClass("Benchmark::Tms", objectClass, benchmarkTmsMethods, nil)
+	benchmarkReportClass = newClass("Benchmark::Report", objectClass, benchmarkReportMethods, nil)
+	benchmarkIPSModule   = newModule("Benchmark::IPS", nil)
+	benchmarkIPSJobClass = newClass("Benchmark::IPS::Job", objectClass, benchmarkIPSJobMethods, nil)
+)
+
+// benchmarkCaption heads the times printed by Benchmark.bm
+const benchmarkCaption = "      user     system      total        real\n"
+
+// The defaults of Benchmark.ips for the time to measure each block, the
+// time to warm it up before, and the time a cycle of iterations should take
+const (
+	benchmarkIPSTime      = 5 * time.Second
+	benchmarkIPSWarmup    = 2 * time.Second
+	benchmarkIPSCycleTime = 100 * time.Millisecond
+)
+
+func init() {
+	classes.Set("Benchmark", benchmarkModule)
+	setConstant(benchmarkModule, "Tms", benchmarkTmsClass)
+	setConstant(benchmarkModule, "Report", benchmarkReportClass)
+	setConstant(benchmarkModule, "IPS", benchmarkIPSModule)
+	setConstant(benchmarkIPSModule, "Job", benchmarkIPSJobClass)
+	setConstant(benchmarkModule, "CAPTION", &String{Value: benchmarkCaption})
+}
+
+// cpuTimes returns the user and system CPU time consumed by the process so
+// far, in seconds
+func cpuTimes() (user, system float64) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, 0
+	}
+	return float64(usage.Utime.Nano()) / 1e9, float64(usage.Stime.Nano()) / 1e9
+}
+
+// benchmarkMeasure calls block and returns the times it took, labeled with
+// label. The real time is taken from the monotonic clock.
+func benchmarkMeasure(label string, block *Proc) (*benchmarkTms, error) {
+	user, system := cpuTimes()
+	start := time.Now()
+	_, err := block.Call()
+	real := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	endUser, endSystem := cpuTimes()
+	return &benchmarkTms{label: label, utime: endUser - user, stime: endSystem - system, real: real.Seconds()}, nil
+}
+
+// benchmarkTms holds the times measured by Benchmark, in seconds. The
+// times of children are always zero, as no child processes are waited for.
+type benchmarkTms struct {
+	label  string
+	utime  float64
+	stime  float64
+	cutime float64
+	cstime float64
+	real   float64
+}
+
+// Type returns OBJECT_OBJ
+func (t *benchmarkTms) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the label and the times
+func (t *benchmarkTms) Inspect() string {
+	return fmt.Sprintf("#<Benchmark::Tms label=%q utime=%f stime=%f total=%f real=%f>", t.label, t.utime, t.stime, t.total(), t.real)
+}
+
+// Class returns benchmarkTmsClass
+func (t *benchmarkTms) Class() RubyClass { return benchmarkTmsClass }
+
+func (t *benchmarkTms) total() float64 {
+	return t.utime + t.stime + t.cutime + t.cstime
+}
+
+// String returns the times formatted like Benchmark prints them below its
+// CAPTION
+func (t *benchmarkTms) String() string {
+	return fmt.Sprintf("%10.6f %10.6f %10.6f (%10.6f)\n", t.utime, t.stime, t.total(), t.real)
+}
+
+var benchmarkMethods = map[string]RubyMethod{
+	"measure":  withArityRange(0, 1, publicMethod(benchmarkModuleMeasure)),
+	"realtime": withArity(0, publicMethod(benchmarkRealtime)),
+	"bm":       withArityRange(0, -1, publicMethod(benchmarkBm)),
+	"ips":      withArityRange(0, 1, publicMethod(benchmarkIPS)),
+}
+
+// benchmarkModuleMeasure returns the times the block took as Benchmark::Tms,
+// labeled with the optional label
+func benchmarkModuleMeasure(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	label := ""
+	if len(args) == 1 {
+		str, err := stringArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		label = str.Value
+	}
+	return benchmarkMeasure(label, block)
+}
+
+// benchmarkRealtime returns the real time the block took in seconds
+func benchmarkRealtime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	start := time.Now()
+	if _, err := block.Call(); err != nil {
+		return nil, err
+	}
+	return NewFloat(time.Since(start).Seconds()), nil
+}
+
+// benchmarkBm prints the CAPTION indented by the optional label width and
+// passes a Benchmark::Report to the block, which prints the times of each
+// block reported to it. It returns the times as Array of Benchmark::Tms.
+func benchmarkBm(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	width := int64(0)
+	if len(args) > 0 {
+		w, ok := args[0].(*Integer)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(&Integer{}, args[0])
+		}
+		width = w.Value
+	}
+	report := &benchmarkReport{width: int(width)}
+	fmt.Fprint(Stdout, strings.Repeat(" ", report.width)+benchmarkCaption)
+	if _, err := block.Call(report); err != nil {
+		return nil, err
+	}
+	return benchmarkReportList(report)
+}
+
+// benchmarkReport collects the times of the blocks reported to it within
+// Benchmark.bm
+type benchmarkReport struct {
+	width int
+	list  []*benchmarkTms
+}
+
+// Type returns OBJECT_OBJ
+func (r *benchmarkReport) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the number of blocks reported
+func (r *benchmarkReport) Inspect() string {
+	return fmt.Sprintf("#<Benchmark::Report list=%d>", len(r.list))
+}
+
+// Class returns benchmarkReportClass
+func (r *benchmarkReport) Class() RubyClass { return benchmarkReportClass }
+
+var benchmarkReportMethods = map[string]RubyMethod{
+	"report": withArityRange(0, 1, publicMethod(benchmarkReportReport)),
+	"item":   withArityRange(0, 1, publicMethod(benchmarkReportReport)),
+	"list":   withArity(0, publicMethod(benchmarkReportList)),
+}
+
+// benchmarkReportReport measures the block, prints its times after the
+// label padded to the width of the report and returns them
+func benchmarkReportReport(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	report := context.(*benchmarkReport)
+	tms, err := benchmarkModuleMeasure(context, args...)
+	if err != nil {
+		return nil, err
+	}
+	measured := tms.(*benchmarkTms)
+	report.list = append(report.list, measured)
+	fmt.Fprintf(Stdout, "%-*s%s", report.width, measured.label, measured)
+	return measured, nil
+}
+
+func benchmarkReportList(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	list := NewArray()
+	for _, tms := range context.(*benchmarkReport).list {
+		list.Elements = append(list.Elements, tms)
+	}
+	return list, nil
+}
+
+var benchmarkTmsMethods = map[string]RubyMethod{
+	"label":  withArity(0, publicMethod(benchmarkTmsLabel)),
+	"utime":  withArity(0, publicMethod(benchmarkTmsUtime)),
+	"stime":  withArity(0, publicMethod(benchmarkTmsStime)),
+	"cutime": withArity(0, publicMethod(benchmarkTmsCutime)),
+	"cstime": withArity(0, publicMethod(benchmarkTmsCstime)),
+	"total":  withArity(0, publicMethod(benchmarkTmsTotal)),
+	"real":   withArity(0, publicMethod(benchmarkTmsReal)),
+	"to_a":   withArity(0, publicMethod(benchmarkTmsToA)),
+	"to_s":   withArity(0, publicMethod(benchmarkTmsToS)),
+	"format": withArity(0, publicMethod(benchmarkTmsToS)),
+}
+
+func benchmarkTmsLabel(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*benchmarkTms).label}, nil
+}
+
+func benchmarkTmsUtime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).utime), nil
+}
+
+func benchmarkTmsStime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).stime), nil
+}
+
+func benchmarkTmsCutime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).cutime), nil
+}
+
+func benchmarkTmsCstime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).cstime), nil
+}
+
+func benchmarkTmsTotal(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).total()), nil
+}
+
+func benchmarkTmsReal(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(context.(*benchmarkTms).real), nil
+}
+
+// benchmarkTmsToA returns the label followed by the user, system, children
+// user, children system and real time
+func benchmarkTmsToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	tms := context.(*benchmarkTms)
+	return NewArray(
+		&String{Value: tms.label},
+		NewFloat(tms.utime),
+		NewFloat(tms.stime),
+		NewFloat(tms.cutime),
+		NewFloat(tms.cstime),
+		NewFloat(tms.real),
+	), nil
+}
+
+func benchmarkTmsToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*benchmarkTms).String()}, nil
+}
+
+// benchmarkIPS passes a Benchmark::IPS::Job to the block, which collects
+// the blocks reported to it. Afterwards each block is run for the warmup
+// time to estimate the iterations per cycle of 100ms, and then in cycles
+// for the measurement time. The iterations per second are printed and
+// returned as Hash mapping each label to them.
+//
+// The options time and warmup, also accepted by Job#config, set the
+// measurement and warmup time in seconds.
+func benchmarkIPS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	job := &benchmarkIPSJob{time: benchmarkIPSTime, warmup: benchmarkIPSWarmup}
+	options, err := jsonOptions(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := job.configure(options); err != nil {
+		return nil, err
+	}
+	if _, err := block.Call(job); err != nil {
+		return nil, err
+	}
+	return job.run()
+}
+
+// benchmarkIPSJob holds the blocks reported to it within Benchmark.ips
+type benchmarkIPSJob struct {
+	time    time.Duration
+	warmup  time.Duration
+	compare bool
+	entries []*benchmarkIPSEntry
+}
+
+// benchmarkIPSEntry holds a block reported to a job and its measurement
+type benchmarkIPSEntry struct {
+	label      string
+	block      *Proc
+	cycle      int
+	iterations int
+	runtime    time.Duration
+	ips        float64
+	deviation  float64
+}
+
+// Type returns OBJECT_OBJ
+func (j *benchmarkIPSJob) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the number of blocks reported
+func (j *benchmarkIPSJob) Inspect() string {
+	return fmt.Sprintf("#<Benchmark::IPS::Job entries=%d>", len(j.entries))
+}
+
+// Class returns benchmarkIPSJobClass
+func (j *benchmarkIPSJob) Class() RubyClass { return benchmarkIPSJobClass }
+
+// configure sets the measurement and warmup time from options
+func (j *benchmarkIPSJob) configure(options *Hash) error {
+	settings := []struct {
+		name   string
+		target *time.Duration
+	}{{"time", &j.time}, {"warmup", &j.warmup}}
+	for _, setting := range settings {
+		name := setting.name
+		value, ok := options.Get(NewSymbol(name))
+		if !ok {
+			continue
+		}
+		seconds, ok := toFloat(value)
+		if !ok || seconds < 0 {
+			return NewArgumentError("invalid %s: %s", name, value.Inspect())
+		}
+		*setting.target = time.Duration(seconds * float64(time.Second))
+	}
+	return nil
+}
+
+// run warms up and measures the blocks reported, printing the results
+func (j *benchmarkIPSJob) run() (RubyObject, error) {
+	fmt.Fprintln(Stdout, "Warming up --------------------------------------")
+	for _, entry := range j.entries {
+		if err := entry.warmUp(j.warmup); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(Stdout, "%20s %s i/100ms\n", entry.label, scaleIPS(float64(entry.cycle)))
+	}
+	fmt.Fprintln(Stdout, "Calculating -------------------------------------")
+	result := NewHash(nil)
+	for _, entry := range j.entries {
+		if err := entry.measure(j.time); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(Stdout, "%20s %s (±%4.1f%%) i/s - %s in %10.6fs\n", entry.label, scaleIPS(entry.ips), entry.deviation, scaleIPS(float64(entry.iterations)), entry.runtime.Seconds())
+		result.Set(&String{Value: entry.label}, NewFloat(entry.ips))
+	}
+	if j.compare && len(j.entries) > 0 {
+		j.printComparison()
+	}
+	return result, nil
+}
+
+// printComparison prints the iterations per second of the blocks from the
+// fastest to the slowest, along with how much slower than the fastest each
+// block is
+func (j *benchmarkIPSJob) printComparison() {
+	entries := append([]*benchmarkIPSEntry(nil), j.entries...)
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].ips > entries[b].ips })
+	fmt.Fprintln(Stdout, "\nComparison:")
+	fastest := entries[0]
+	fmt.Fprintf(Stdout, "%20s: %10.1f i/s\n", fastest.label, fastest.ips)
+	for _, entry := range entries[1:] {
+		slowdown := math.Inf(1)
+		if entry.ips > 0 {
+			slowdown = fastest.ips / entry.ips
+		}
+		fmt.Fprintf(Stdout, "%20s: %10.1f i/s - %.2fx  slower\n", entry.label, entry.ips, slowdown)
+	}
+	fmt.Fprintln(Stdout)
+}
+
+// warmUp calls the block of the entry repeatedly for duration, at least
+// once, and sets the iterations of a cycle to the iterations run per 100ms
+func (e *benchmarkIPSEntry) warmUp(duration time.Duration) error {
+	start := time.Now()
+	iterations := 0
+	for iterations == 0 || time.Since(start) < duration {
+		if _, err := e.block.Call(); err != nil {
+			return err
+		}
+		iterations++
+	}
+	elapsed := time.Since(start)
+	e.cycle = int(float64(iterations) * float64(benchmarkIPSCycleTime) / float64(elapsed))
+	if e.cycle < 1 {
+		e.cycle = 1
+	}
+	return nil
+}
+
+// measure runs cycles of the block of the entry for duration, at least
+// one, and sets the mean iterations per second of the cycles along with
+// their standard deviation in percent
+func (e *benchmarkIPSEntry) measure(duration time.Duration) error {
+	var samples []float64
+	start := time.Now()
+	for len(samples) == 0 || time.Since(start) < duration {
+		cycleStart := time.Now()
+		for i := 0; i < e.cycle; i++ {
+			if _, err := e.block.Call(); err != nil {
+				return err
+			}
+		}
+		samples = append(samples, float64(e.cycle)/time.Since(cycleStart).Seconds())
+		e.iterations += e.cycle
+	}
+	e.runtime = time.Since(start)
+	var sum float64
+	for _, sample := range samples {
+		sum += sample
+	}
+	e.ips = sum / float64(len(samples))
+	var variance float64
+	for _, sample := range samples {
+		variance += (sample - e.ips) * (sample - e.ips)
+	}
+	variance /= float64(len(samples))
+	if e.ips > 0 {
+		e.deviation = math.Sqrt(variance) / e.ips * 100
+	}
+	return nil
+}
+
+// scaleIPS formats value right aligned with a suffix for thousands,
+// millions, billions or trillions, like 123.457k
+func scaleIPS(value float64) string {
+	suffixes := []string{"", "k", "M", "B", "T"}
+	scale := 0
+	if value > 0 {
+		scale = int(math.Log10(value) / 3)
+	}
+	if scale < 0 || scale >= len(suffixes) {
+		scale = 0
+	}
+	return fmt.Sprintf("%10.3f%s", value/math.Pow(1000, float64(scale)), suffixes[scale])
+}
+
+var benchmarkIPSJobMethods = map[string]RubyMethod{
+	"report":   withArityRange(0, 1, publicMethod(benchmarkIPSJobReport)),
+	"item":     withArityRange(0, 1, publicMethod(benchmarkIPSJobReport)),
+	"config":   withArity(1, publicMethod(benchmarkIPSJobConfig)),
+	"compare!": withArity(0, publicMethod(benchmarkIPSJobCompare)),
+}
+
+// benchmarkIPSJobReport adds the block under the optional label to the
+// blocks measured once the block of Benchmark.ips returns
+func benchmarkIPSJobReport(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	label := ""
+	if len(args) == 1 {
+		str, err := stringArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		label = str.Value
+	}
+	job := context.(*benchmarkIPSJob)
+	job.entries = append(job.entries, &benchmarkIPSEntry{label: label, block: block})
+	return NIL, nil
+}
+
+// benchmarkIPSJobConfig sets the options time and warmup in seconds
+func benchmarkIPSJobConfig(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	options, err := jsonOptions(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := context.(*benchmarkIPSJob).configure(options); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+// benchmarkIPSJobCompare makes the job print a comparison of the blocks
+// after measuring them
+func benchmarkIPSJobCompare(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	context.(*benchmarkIPSJob).compare = true
+	return NIL, nil
+}
@@ -0,0 +1,114 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkMeasure(t *testing.T) {
+	block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		time.Sleep(2 * time.Millisecond)
+		return NIL, nil
+	})
+
+	result, err := benchmarkModuleMeasure(benchmarkModule, &String{Value: "sleep"}, block)
+
+	checkError(t, err, nil)
+	tms, ok := result.(*benchmarkTms)
+	if !ok {
+		t.Fatalf("Expected a Benchmark::Tms, got %s", result.Inspect())
+	}
+	if tms.label != "sleep" || tms.real < 0.002 {
+		t.Errorf("Expected at least 2ms labeled sleep, got %s", tms.Inspect())
+	}
+	if !regexp.MustCompile(`^ +\d+\.\d{6} +\d+\.\d{6} +\d+\.\d{6} \( +0\.00\d{4}\)\n$`).MatchString(tms.String()) {
+		t.Errorf("Expected the times formatted below the caption, got %q", tms.String())
+	}
+}
+
+func TestBenchmarkBm(t *testing.T) {
+	var out bytes.Buffer
+	defer func(stdout io.Writer) { Stdout = stdout }(Stdout)
+	Stdout = &out
+	block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		empty := testBlock(func(args ...RubyObject) (RubyObject, error) { return NIL, nil })
+		for _, label := range []string{"a:", "bb:"} {
+			if _, err := benchmarkReportReport(args[0], &String{Value: label}, empty); err != nil {
+				return nil, err
+			}
+		}
+		return NIL, nil
+	}, "x")
+
+	result, err := benchmarkBm(benchmarkModule, NewInteger(4), block)
+
+	checkError(t, err, nil)
+	if list, ok := result.(*Array); !ok || len(list.Elements) != 2 {
+		t.Errorf("Expected the times of both reports, got %s", result.Inspect())
+	}
+	expected := regexp.MustCompile(`^    ` + regexp.QuoteMeta(benchmarkCaption) + `a:   .*\nbb:  .*\n$`)
+	if !expected.MatchString(out.String()) {
+		t.Errorf("Expected the caption and the labeled times, got %q", out.String())
+	}
+}
+
+func TestBenchmarkIPS(t *testing.T) {
+	var out bytes.Buffer
+	defer func(stdout io.Writer) { Stdout = stdout }(Stdout)
+	Stdout = &out
+	calls := 0
+	block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		job := args[0]
+		counted := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			calls++
+			return NIL, nil
+		})
+		if _, err := benchmarkIPSJobReport(job, &String{Value: "count"}, counted); err != nil {
+			return nil, err
+		}
+		return benchmarkIPSJobCompare(job)
+	}, "x")
+	options := NewHash(nil)
+	options.Set(NewSymbol("time"), NewFloat(0.01))
+	options.Set(NewSymbol("warmup"), NewInteger(0))
+
+	result, err := benchmarkIPS(benchmarkModule, options, block)
+
+	checkError(t, err, nil)
+	hash, ok := result.(*Hash)
+	if !ok {
+		t.Fatalf("Expected a Hash, got %s", result.Inspect())
+	}
+	ips, ok := hash.Get(&String{Value: "count"})
+	if value, isFloat := ips.(*Float); !ok || !isFloat || value.Value <= 0 {
+		t.Errorf("Expected the iterations per second of count, got %s", hash.Inspect())
+	}
+	if calls < 2 {
+		t.Errorf("Expected the block to be warmed up and measured, got %d calls", calls)
+	}
+	expected := regexp.MustCompile(`(?s)^Warming up -+\n +count +[\d.]+k? i/100ms\nCalculating -+\n +count +[\d.]+[kM]? \(± *[\d.]+%\) i/s - .* in +[\d.]+s\n\nComparison:\n +count: +[\d.]+ i/s\n\n$`)
+	if !expected.MatchString(out.String()) {
+		t.Errorf("Expected the warmup, the measurement and the comparison, got %q", out.String())
+	}
+}
+
+func TestScaleIPS(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "     0.000"},
+		{12.5, "    12.500"},
+		{123456, "   123.456k"},
+		{2500000, "     2.500M"},
+	}
+
+	for _, tt := range tests {
+		if actual := scaleIPS(tt.value); actual != tt.expected {
+			t.Errorf("Expected %v to scale to %q, got %q", tt.value, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package object
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+var bindingClass RubyClassObject = newClass("Binding", objectClass, bindingMethods, nil)
+
+func init() {
+	classes.Set("Binding", bindingClass)
+}
+
+// NewBinding returns a Binding capturing env
+func NewBinding(env Environment) *Binding {
+	return &Binding{Env: env}
+}
+
+// A Binding represents the execution context at some place in the code,
+// i.e. its local variables and self. Code can be evaluated within this
+// context later on.
+type Binding struct {
+	Env Environment
+	// EvalFn evaluates source within env. It is set by the evaluator
+	// creating the Binding.
+	EvalFn func(source string, env Environment) (RubyObject, error)
+}
+
+// Type returns BINDING_OBJ
+func (b *Binding) Type() Type { return BINDING_OBJ }
+
+// Inspect returns the class name of the Binding
+func (b *Binding) Inspect() string { return "#<Binding>" }
+
+// Class returns bindingClass
+func (b *Binding) Class() RubyClass { return bindingClass }
+
+var bindingMethods = map[string]RubyMethod{
+	"local_variable_get":      withArity(1, publicMethod(bindingLocalVariableGet)),
+	"local_variable_set":      withArity(2, publicMethod(bindingLocalVariableSet)),
+	"local_variable_defined?": withArity(1, publicMethod(bindingIsLocalVariableDefined)),
+	"local_variables":         withArity(0, publicMethod(bindingLocalVariables)),
+	"receiver":                withArity(0, publicMethod(bindingReceiver)),
+	"eval":                    withArity(1, publicMethod(bindingEval)),
+	"irb":                     withArity(0, publicMethod(bindingIrb)),
+	"pry":                     withArity(0, publicMethod(bindingIrb)),
+}
+
+// Console starts an interactive console evaluating the expressions entered
+// within binding, and returns once it is left. It is set by programs
+// providing one, like goruby does, and is called by Binding#irb.
+var Console func(binding *Binding)
+
+// bindingIrb opens the Console within the binding and returns nil once it
+// is left, so that the program continues
+func bindingIrb(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if Console == nil {
+		return nil, NewNotImplementedError("binding.irb is not supported without a console")
+	}
+	Console(context.(*Binding))
+	return NIL, nil
+}
+
+func bindingLocalVariableGet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	binding := context.(*Binding)
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := binding.Env.Get(name)
+	if !ok {
+		return nil, NewLocalVariableNotDefinedError(name, binding)
+	}
+	return value, nil
+}
+
+func bindingLocalVariableSet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	binding := context.(*Binding)
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return binding.Env.Set(name, args[1]), nil
+}
+
+func bindingIsLocalVariableDefined(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	binding := context.(*Binding)
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	_, ok := binding.Env.Get(name)
+	return nativeBoolToBoolean(ok), nil
+}
+
+// bindingLocalVariables returns the names of the local variables visible
+// within the binding, innermost first
+func bindingLocalVariables(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	seen := make(map[string]bool)
+	var names []RubyObject
+	for env := context.(*Binding).Env; env != nil; env = env.Outer() {
+		var envNames []string
+		switch env := env.(type) {
+		case *environment:
+			envNames = env.names()
+		case *blockEnvironment:
+			envNames = env.names()
+		case *slotEnvironment:
+			for i, name := range env.scope.Names {
+				if env.slots[i] != nil {
+					envNames = append(envNames, name)
+				}
+			}
+			for name := range env.store {
+				envNames = append(envNames, name)
+			}
+		case *runtimeEnvironment:
+			for name := range env.store {
+				envNames = append(envNames, name)
+			}
+		}
+		sort.Strings(envNames)
+		for _, name := range envNames {
+			if IsLocalVariableName(name) && !seen[name] {
+				seen[name] = true
+				names = append(names, NewSymbol(name))
+			}
+		}
+	}
+	return NewArray(names...), nil
+}
+
+// IsLocalVariableName reports whether name is a valid name of a local
+// variable, which excludes global variables, constants, self and the hidden
+// entries of environments
+func IsLocalVariableName(name string) bool {
+	first, _ := utf8.DecodeRuneInString(name)
+	if name == "" || name == "self" || first != '_' && (!unicode.IsLetter(first) || unicode.IsUpper(first)) {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func bindingReceiver(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	binding := context.(*Binding)
+	self, ok := binding.Env.Get("self")
+	if !ok {
+		return NIL, nil
+	}
+	return unwrapSelf(self), nil
+}
+
+// bindingEval evaluates the Ruby source passed within the binding
+func bindingEval(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	source, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	return context.(*Binding).Eval(source.Value)
+}
+
+// Eval evaluates source within the binding. It returns a RuntimeError if
+// the Binding has not been created by the evaluator.
+func (b *Binding) Eval(source string) (RubyObject, error) {
+	if b.EvalFn == nil {
+		return nil, NewRuntimeError("binding cannot evaluate code")
+	}
+	return b.EvalFn(source, b.Env)
+}
@@ -0,0 +1,61 @@
+package object
+
+import "testing"
+
+func TestBindingLocalVariables(t *testing.T) {
+	env := NewEnvironment()
+	env.Set("x", NewInteger(1))
+	env.Set("self", &Self{NewArray()})
+	binding := NewBinding(env)
+
+	result, err := Send(binding, "local_variable_get", &Symbol{"x"})
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(1))
+
+	_, err = Send(binding, "local_variable_get", &String{Value: "y"})
+	checkError(t, err, NewLocalVariableNotDefinedError("y", binding))
+
+	result, err = Send(binding, "local_variable_set", &Symbol{"y"}, NewInteger(2))
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(2))
+
+	result, err = Send(binding, "local_variable_defined?", &Symbol{"y"})
+	checkError(t, err, nil)
+	checkResult(t, result, TRUE)
+
+	result, err = Send(binding, "receiver")
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray())
+}
+
+func TestBindingLocalVariableNames(t *testing.T) {
+	outer := NewMainEnvironment()
+	outer.Set("b", NewInteger(1))
+	env := newBlockEnvironment(outer)
+	env.Set("a", NewInteger(2))
+	env.Set("b", NewInteger(3))
+
+	result, err := Send(NewBinding(env), "local_variables")
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray(NewSymbol("a"), NewSymbol("b")))
+}
+
+func TestIsLocalVariableName(t *testing.T) {
+	tests := map[string]bool{
+		"foo":    true,
+		"_":      true,
+		"x1":     true,
+		"änder":  true,
+		"":       false,
+		"self":   false,
+		"$foo":   false,
+		"Foo":    false,
+		"__fn__": true,
+		"a-b":    false,
+	}
+	for name, expected := range tests {
+		if actual := IsLocalVariableName(name); actual != expected {
+			t.Errorf("Expected IsLocalVariableName(%q) to be %t, got %t", name, expected, actual)
+		}
+	}
+}
@@ -35,6 +35,23 @@ func (b *Boolean) Class() RubyClass {
 	return falseClass
 }
 
+func (b *Boolean) hashKey() hashKey {
+	return hashKey{Type: b.Type(), Value: b.Value}
+}
+
 var booleanTrueMethods = map[string]RubyMethod{}
 
 var booleanFalseMethods = map[string]RubyMethod{}
+
+func nativeBoolToBoolean(b bool) RubyObject {
+	if b {
+		return TRUE
+	}
+	return FALSE
+}
+
+// isTruthy reports whether obj counts as true in a condition, i.e. whether
+// it is neither nil nor false
+func isTruthy(obj RubyObject) bool {
+	return obj != NIL && obj != FALSE
+}
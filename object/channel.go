@@ -0,0 +1,174 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var goChannelClass RubyClassObject = newClass("GoChannel", objectClass, goChannelMethods, nil)
+
+// NewChannel returns a Channel exposing the Go channel ch to Ruby like a
+// Queue, so that programs can take part in goroutine pipelines of the host
+// application. The element type of ch must be convertible like the
+// parameter types of NewGoMethod.
+func NewChannel(ch interface{}) (*Channel, error) {
+	value := reflect.ValueOf(ch)
+	if value.Kind() != reflect.Chan {
+		return nil, fmt.Errorf("expected a channel, got %T", ch)
+	}
+	if value.IsNil() {
+		return nil, fmt.Errorf("expected a non-nil channel, got nil %T", ch)
+	}
+	if !isConvertibleType(value.Type().Elem()) {
+		return nil, fmt.Errorf("unsupported element type %s", value.Type().Elem())
+	}
+	return &Channel{value: value}, nil
+}
+
+// A Channel is a Go channel wrapped by NewChannel. Pushing sends the
+// converted objects to the channel and popping receives from it. Waiting
+// Threads let other Threads run meanwhile.
+type Channel struct {
+	value reflect.Value
+	// closed is set once the channel was closed from Ruby or popping found
+	// it closed
+	closed bool
+}
+
+// Type returns GO_CHANNEL_OBJ
+func (c *Channel) Type() Type { return GO_CHANNEL_OBJ }
+
+// Inspect returns the class and type of the channel, like
+// `#<GoChannel chan string>`
+func (c *Channel) Inspect() string {
+	return fmt.Sprintf("#<GoChannel %s>", c.value.Type())
+}
+
+// Class returns goChannelClass
+func (c *Channel) Class() RubyClass { return goChannelClass }
+
+// Interface returns the wrapped channel
+func (c *Channel) Interface() interface{} { return c.value.Interface() }
+
+func (c *Channel) hashKey() hashKey {
+	return hashKey{Type: c.Type(), Value: c.value.Pointer()}
+}
+
+// push sends obj, waiting for a receiver or space in the buffer unless
+// nonBlock is set
+func (c *Channel) push(r *Runtime, obj RubyObject, nonBlock bool) (err error) {
+	if c.value.Type().ChanDir()&reflect.SendDir == 0 {
+		return NewTypeError("can't push to receive-only %s", c.value.Type())
+	}
+	if c.closed {
+		return NewClosedQueueError()
+	}
+	value, err := toGo(obj, c.value.Type().Elem())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// sending to a channel the host closed panics
+		if recover() != nil {
+			c.closed = true
+			err = NewClosedQueueError()
+		}
+	}()
+	if nonBlock {
+		if !c.value.TrySend(value) {
+			return NewThreadError("queue full")
+		}
+		return nil
+	}
+	r.withoutInterpreter(func() { c.value.Send(value) })
+	return nil
+}
+
+// pop receives the next value, waiting for one to be sent unless nonBlock
+// is set. A closed and drained channel returns nil.
+func (c *Channel) pop(r *Runtime, nonBlock bool) (RubyObject, error) {
+	if c.value.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, NewTypeError("can't pop from send-only %s", c.value.Type())
+	}
+	var value reflect.Value
+	var ok bool
+	if nonBlock {
+		value, ok = c.value.TryRecv()
+		if !ok && !value.IsValid() {
+			return nil, NewThreadError("queue empty")
+		}
+	} else {
+		r.withoutInterpreter(func() { value, ok = c.value.Recv() })
+	}
+	if !ok {
+		c.closed = true
+		return NIL, nil
+	}
+	return fromGo(value)
+}
+
+var goChannelMethods = map[string]RubyMethod{
+	"push":    withArityRange(1, 2, publicRuntimeMethod(channelPush)),
+	"<<":      withArityRange(1, 2, publicRuntimeMethod(channelPush)),
+	"enq":     withArityRange(1, 2, publicRuntimeMethod(channelPush)),
+	"pop":     withArityRange(0, 1, publicRuntimeMethod(channelPop)),
+	"shift":   withArityRange(0, 1, publicRuntimeMethod(channelPop)),
+	"deq":     withArityRange(0, 1, publicRuntimeMethod(channelPop)),
+	"size":    withArity(0, publicMethod(channelSize)),
+	"length":  withArity(0, publicMethod(channelSize)),
+	"empty?":  withArity(0, publicMethod(channelEmpty)),
+	"max":     withArity(0, publicMethod(channelMax)),
+	"close":   withArity(0, publicMethod(channelClose)),
+	"closed?": withArity(0, publicMethod(channelClosed)),
+}
+
+func channelPush(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	nonBlock := len(args) == 2 && isTruthy(args[1])
+	if err := context.(*Channel).push(r, args[0], nonBlock); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+func channelPop(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	nonBlock := len(args) == 1 && isTruthy(args[0])
+	return context.(*Channel).pop(r, nonBlock)
+}
+
+// channelSize returns the number of values buffered by the channel
+func channelSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*Channel).value.Len())), nil
+}
+
+func channelEmpty(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Channel).value.Len() == 0), nil
+}
+
+// channelMax returns the capacity of the channel's buffer
+func channelMax(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*Channel).value.Cap())), nil
+}
+
+// channelClose closes the channel, so that its receivers get the values
+// left and then nil. Closing it again does nothing.
+func channelClose(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	channel := context.(*Channel)
+	if channel.value.Type().ChanDir()&reflect.SendDir == 0 {
+		return nil, NewTypeError("can't close receive-only %s", channel.value.Type())
+	}
+	if !channel.closed {
+		channel.closed = true
+		func() {
+			// the host may have closed it already
+			defer func() { recover() }()
+			channel.value.Close()
+		}()
+	}
+	return context, nil
+}
+
+// channelClosed reports whether the channel was closed from Ruby, or found
+// closed by the host when popping or pushing
+func channelClosed(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Channel).closed), nil
+}
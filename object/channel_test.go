@@ -0,0 +1,121 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestChannel(t *testing.T) {
+	t.Run("push and pop", func(t *testing.T) {
+		ch := make(chan int, 2)
+		channel, err := NewChannel(ch)
+		checkError(t, err, nil)
+
+		_, err = Send(channel, "push", NewInteger(1))
+		checkError(t, err, nil)
+		if got := <-ch; got != 1 {
+			t.Errorf("Expected 1 to be sent, got %d", got)
+		}
+
+		ch <- 2
+		item, err := Send(channel, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NewInteger(2))
+	})
+	t.Run("non blocking", func(t *testing.T) {
+		channel, err := NewChannel(make(chan string, 1))
+		checkError(t, err, nil)
+
+		_, err = Send(channel, "pop", TRUE)
+		checkError(t, err, NewThreadError("queue empty"))
+
+		_, err = Send(channel, "push", &String{Value: "a"}, TRUE)
+		checkError(t, err, nil)
+		_, err = Send(channel, "push", &String{Value: "b"}, TRUE)
+		checkError(t, err, NewThreadError("queue full"))
+
+		size, err := Send(channel, "size")
+		checkError(t, err, nil)
+		checkResult(t, size, NewInteger(1))
+	})
+	t.Run("pop waits for the host", func(t *testing.T) {
+		ch := make(chan string)
+		channel, err := NewChannel(ch)
+		checkError(t, err, nil)
+		go func() { ch <- "event" }()
+
+		item, err := Send(channel, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, &String{Value: "event"})
+	})
+	t.Run("closed", func(t *testing.T) {
+		ch := make(chan int, 1)
+		ch <- 1
+		close(ch)
+		channel, err := NewChannel(ch)
+		checkError(t, err, nil)
+
+		item, err := Send(channel, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NewInteger(1))
+
+		item, err = Send(channel, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NIL)
+
+		closed, err := Send(channel, "closed?")
+		checkError(t, err, nil)
+		checkResult(t, closed, TRUE)
+
+		_, err = Send(channel, "push", NewInteger(2))
+		checkError(t, err, NewClosedQueueError())
+	})
+	t.Run("close from Ruby", func(t *testing.T) {
+		ch := make(chan int)
+		channel, err := NewChannel(ch)
+		checkError(t, err, nil)
+
+		_, err = Send(channel, "close")
+		checkError(t, err, nil)
+		if _, ok := <-ch; ok {
+			t.Errorf("Expected the channel to be closed")
+		}
+
+		_, err = Send(channel, "push", NewInteger(2))
+		checkError(t, err, NewClosedQueueError())
+	})
+	t.Run("element conversion", func(t *testing.T) {
+		channel, err := NewChannel(make(chan int, 1))
+		checkError(t, err, nil)
+
+		_, err = Send(channel, "push", &String{Value: "x"})
+		checkError(t, err, NewTypeError("no implicit conversion of String into int"))
+	})
+	t.Run("direction", func(t *testing.T) {
+		var receiveOnly <-chan int = make(chan int)
+		channel, err := NewChannel(receiveOnly)
+		checkError(t, err, nil)
+
+		_, err = Send(channel, "push", NewInteger(1))
+		checkError(t, err, NewTypeError("can't push to receive-only <-chan int"))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := NewChannel(42); err == nil {
+			t.Errorf("Expected error for a non-channel")
+		}
+		if _, err := NewChannel(make(chan struct{})); err == nil {
+			t.Errorf("Expected error for an unsupported element type")
+		}
+	})
+	t.Run("FromGo", func(t *testing.T) {
+		ch := make(chan int)
+		obj, err := FromGo(ch)
+		checkError(t, err, nil)
+		channel, ok := obj.(*Channel)
+		if !ok {
+			t.Fatalf("Expected *Channel, got %T", obj)
+		}
+		if channel.Interface() != ch {
+			t.Errorf("Expected the wrapped channel")
+		}
+	})
+}
@@ -6,20 +6,103 @@ var classClass RubyClassObject = &class{name: "Class", superClass: moduleClass,
 
 func init() {
 	classClass.(*class).class = classClass
+	setAllocator(classClass, func(RubyClassObject) RubyObject {
+		return &class{}
+	})
+	// registered here as it refers to classClass itself
+	classMethods["initialize"] = withArityRange(0, 1, privateMethod(classInitialize))
 	classes.Set("Class", classClass)
 }
 
+// IsKindOf returns true if class is the class of obj or one of its
+// superclasses
+func IsKindOf(obj RubyObject, class RubyClass) bool {
+	objClass := obj.Class()
+	for objClass != nil {
+		if objClass == class {
+			return true
+		}
+		objClass = objClass.SuperClass()
+	}
+	return false
+}
+
+// includesModule returns true if module is mixed into class or one of its
+// superclasses
+func includesModule(class RubyClass, module *Module) bool {
+	for class != nil {
+		if mixin, ok := class.(*methodSet); ok {
+			for _, m := range mixin.modules {
+				if m == module {
+					return true
+				}
+			}
+		}
+		class = class.SuperClass()
+	}
+	return false
+}
+
+// isA reports whether obj is an instance of module, one of its subclasses,
+// or of a class module is mixed into. It returns a TypeError if module is
+// neither a class nor a module.
+func isA(obj RubyObject, module RubyObject) (bool, error) {
+	switch module := module.(type) {
+	case *Module:
+		return includesModule(obj.Class(), module), nil
+	case RubyClassObject:
+		return IsKindOf(obj, module), nil
+	default:
+		return false, NewTypeError("class or module required")
+	}
+}
+
 // newClass returns a new Ruby Class
 func newClass(name string, superClass RubyClass, instanceMethods, classMethods map[string]RubyMethod) *class {
 	return &class{name: name, superClass: superClass, instanceMethods: instanceMethods, class: newEigenclass(classClass, classMethods)}
 }
 
+// newSubclass returns a new class inheriting from superClass, which
+// allocates its instances the way superClass does
+func newSubclass(name string, superClass RubyClassObject) *class {
+	subclass := &class{name: name}
+	subclass.inherit(superClass)
+	return subclass
+}
+
+// An allocator returns a new, uninitialized instance of class
+type allocator func(class RubyClassObject) RubyObject
+
 // class represents a Ruby Class object
 type class struct {
 	name            string
 	superClass      RubyClass
 	class           RubyClass
 	instanceMethods map[string]RubyMethod
+	allocator       allocator
+}
+
+// allocatorOf returns the allocator of class, or nil if instances of class
+// cannot be allocated
+func allocatorOf(rubyClass RubyClass) allocator {
+	switch rubyClass := rubyClass.(type) {
+	case *class:
+		return rubyClass.allocator
+	case *methodSet:
+		return allocatorOf(rubyClass.RubyClassObject)
+	default:
+		return nil
+	}
+}
+
+// setAllocator sets the allocator of the builtin class
+func setAllocator(rubyClass RubyClass, alloc allocator) {
+	switch rubyClass := rubyClass.(type) {
+	case *class:
+		rubyClass.allocator = alloc
+	case *methodSet:
+		setAllocator(rubyClass.RubyClassObject, alloc)
+	}
 }
 
 func (c *class) Inspect() string {
@@ -42,10 +125,89 @@ func (c *class) Methods() map[string]RubyMethod {
 	return c.instanceMethods
 }
 
+// inherit makes c a subclass of superClass. The singleton class of c
+// inherits from the one of superClass, so that class methods are inherited
+// as well.
+func (c *class) inherit(superClass RubyClassObject) {
+	invalidateMethodCache()
+	c.superClass = superClass
+	c.allocator = allocatorOf(superClass)
+	singletonSuperClass := RubyClass(classClass)
+	if eigenClass, ok := superClass.Class().(*eigenclass); ok {
+		singletonSuperClass = eigenClass
+	}
+	c.class = newEigenclass(singletonSuperClass, nil)
+}
+
+func (c *class) addMethod(name string, method RubyMethod) {
+	methodTables.Lock()
+	if c.instanceMethods == nil {
+		c.instanceMethods = make(map[string]RubyMethod)
+	}
+	c.instanceMethods[name] = method
+	methodTables.Unlock()
+	invalidateMethodCache()
+}
+
 var classClassMethods = map[string]RubyMethod{}
 
 var classMethods = map[string]RubyMethod{
 	"superclass": withArity(0, publicMethod(classSuperclass)),
+	"new":        publicMethod(classNew),
+	"allocate":   withArity(0, publicMethod(classAllocate)),
+}
+
+// classInitialize sets up a class created via Class.new as subclass of the
+// given class, Object by default. A given block is evaluated like with
+// class_eval.
+func classInitialize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	newClass := unwrapObject(context).(*class)
+	args, block := extractBlock(args)
+	superClass := objectClass
+	if len(args) == 1 {
+		class, ok := args[0].(RubyClassObject)
+		if !ok {
+			return nil, NewTypeError("superclass must be a Class (%s given)", className(args[0]))
+		}
+		if class == classClass {
+			return nil, NewTypeError("can't make subclass of Class")
+		}
+		superClass = class
+	}
+	newClass.inherit(superClass)
+	if block == nil {
+		return NIL, nil
+	}
+	return moduleClassEval(newClass, block)
+}
+
+// classNew allocates a new instance and calls its initialize method with
+// all arguments, including the block
+func classNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	instance, err := classAllocate(context)
+	if err != nil {
+		return nil, err
+	}
+	initialize, ok := findMethod(instance, "initialize")
+	if !ok {
+		return instance, nil
+	}
+	if _, err := initialize.Call(instance, args...); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// classAllocate returns a new instance without calling initialize. It
+// returns a TypeError for classes whose instances cannot be allocated.
+func classAllocate(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	class := context.(RubyClassObject)
+	alloc := allocatorOf(class)
+	if alloc == nil {
+		return nil, NewTypeError("allocator undefined for %s", class.Inspect())
+	}
+	allocatedInstances.add()
+	return alloc(class), nil
 }
 
 func classSuperclass(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -3,9 +3,107 @@ package object
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
+func TestIsKindOf(t *testing.T) {
+	tests := []struct {
+		obj      RubyObject
+		class    RubyClass
+		expected bool
+	}{
+		{NewInteger(1), integerClass, true},
+		{NewInteger(1), objectClass, true},
+		{NewInteger(1), basicObjectClass, true},
+		{NewInteger(1), stringClass, false},
+		{NewZeroDivisionError(), standardErrorClass, true},
+		{NewZeroDivisionError(), exceptionClass, true},
+		{NewLoadError("foo"), standardErrorClass, false},
+	}
+
+	for _, tt := range tests {
+		actual := IsKindOf(tt.obj, tt.class)
+
+		if actual != tt.expected {
+			t.Logf("Expected IsKindOf(%s, %s) to return %t, got %t", tt.obj.Inspect(), tt.class.(RubyObject).Inspect(), tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestIsA(t *testing.T) {
+	tests := []struct {
+		obj      RubyObject
+		module   RubyObject
+		expected bool
+	}{
+		{NewInteger(1), integerClass, true},
+		{NewInteger(1), numericClass, true},
+		{NewInteger(1), objectClass, true},
+		{NewInteger(1), stringClass, false},
+		{NewInteger(1), comparableModule, true},
+		{NewInteger(1), kernelModule, true},
+		{&String{}, comparableModule, true},
+		{NewArray(), comparableModule, false},
+		{AddMethod(&String{}, "foo", &Function{}), stringClass, true},
+	}
+
+	for _, tt := range tests {
+		actual, err := isA(tt.obj, tt.module)
+		checkError(t, err, nil)
+
+		if actual != tt.expected {
+			t.Logf("Expected isA(%s, %s) to return %t, got %t", tt.obj.Inspect(), tt.module.Inspect(), tt.expected, actual)
+			t.Fail()
+		}
+	}
+
+	_, err := isA(NewInteger(1), NewInteger(2))
+	checkError(t, err, NewTypeError("class or module required"))
+}
+
+func TestKernelIsInstanceOf(t *testing.T) {
+	tests := []struct {
+		obj      RubyObject
+		class    RubyObject
+		expected RubyObject
+	}{
+		{NewInteger(1), integerClass, TRUE},
+		{NewInteger(1), numericClass, FALSE},
+		{NewInteger(1), comparableModule, FALSE},
+		{AddMethod(&String{}, "foo", &Function{}), stringClass, TRUE},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(tt.obj, "instance_of?", tt.class)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	_, err := Send(NewInteger(1), "instance_of?", NIL)
+	checkError(t, err, NewTypeError("class or module required"))
+}
+
+func TestModuleCaseEqual(t *testing.T) {
+	tests := []struct {
+		module   RubyObject
+		obj      RubyObject
+		expected RubyObject
+	}{
+		{integerClass, NewInteger(1), TRUE},
+		{numericClass, NewInteger(1), TRUE},
+		{stringClass, NewInteger(1), FALSE},
+		{comparableModule, NewInteger(1), TRUE},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(tt.module, "===", tt.obj)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
 func TestClassInspect(t *testing.T) {
 	t.Run("class Class", func(t *testing.T) {
 		context := &class{}
@@ -138,3 +236,99 @@ func TestClassSuperclass(t *testing.T) {
 		}
 	})
 }
+
+func TestClassNew(t *testing.T) {
+	point := newSubclass("Point", objectClass)
+	point.addMethod("initialize", privateMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		args, block := extractBlock(args)
+		if len(args) != 2 {
+			return nil, NewWrongNumberOfArgumentsError(2, len(args))
+		}
+		ivars := instanceVariablesOf(context, true)
+		ivars.set("@x", args[0])
+		ivars.set("@y", args[1])
+		if block != nil {
+			return block.Call(context)
+		}
+		return NIL, nil
+	}))
+
+	t.Run("new calls initialize", func(t *testing.T) {
+		var yielded RubyObject
+		block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			yielded = args[0]
+			return NIL, nil
+		}, "point")
+
+		result, err := classNew(point, NewInteger(1), NewInteger(2), block)
+
+		checkError(t, err, nil)
+		if result.Class() != point {
+			t.Logf("Expected instance of Point, got %s", result.Class().(RubyObject).Inspect())
+			t.Fail()
+		}
+		x, _ := instanceVariablesOf(result, false).get("@x")
+		checkResult(t, x, NewInteger(1))
+		if yielded != result {
+			t.Logf("Expected block to be passed to initialize")
+			t.Fail()
+		}
+	})
+	t.Run("new with wrong arguments", func(t *testing.T) {
+		_, err := classNew(point, NewInteger(1))
+
+		checkError(t, err, NewWrongNumberOfArgumentsError(2, 1))
+	})
+	t.Run("allocate skips initialize", func(t *testing.T) {
+		result, err := classAllocate(point)
+
+		checkError(t, err, nil)
+		if result.Class() != point {
+			t.Logf("Expected instance of Point, got %s", result.Class().(RubyObject).Inspect())
+			t.Fail()
+		}
+		if ivars := instanceVariablesOf(result, false); ivars != nil && len(ivars.names) != 0 {
+			t.Logf("Expected no instance variables, got %v", ivars.names)
+			t.Fail()
+		}
+	})
+	t.Run("default initialize", func(t *testing.T) {
+		_, err := classNew(objectClass, NewInteger(1))
+
+		checkError(t, err, NewWrongNumberOfArgumentsError(0, 1))
+	})
+	t.Run("classes without allocator", func(t *testing.T) {
+		_, err := classAllocate(integerClass)
+
+		checkError(t, err, NewTypeError("allocator undefined for Integer"))
+	})
+}
+
+func TestClassNewAnonymous(t *testing.T) {
+	result, err := classNew(classClass, stringClass)
+	checkError(t, err, nil)
+
+	anonymous, ok := result.(*class)
+	if !ok {
+		t.Logf("Expected a class, got %T", result)
+		t.FailNow()
+	}
+	if anonymous.SuperClass() != stringClass {
+		t.Logf("Expected superclass String, got %s", anonymous.SuperClass().(RubyObject).Inspect())
+		t.Fail()
+	}
+	if !strings.HasPrefix(anonymous.Inspect(), "#<Class:") {
+		t.Logf("Expected anonymous class inspect, got %s", anonymous.Inspect())
+		t.Fail()
+	}
+
+	nameAnonymousModule(anonymous, "Text")
+	nameAnonymousModule(anonymous, "Other")
+	if anonymous.Inspect() != "Text" {
+		t.Logf("Expected class to be named Text, got %s", anonymous.Inspect())
+		t.Fail()
+	}
+
+	_, err = classNew(classClass, NewInteger(1))
+	checkError(t, err, NewTypeError("superclass must be a Class (Integer given)"))
+}
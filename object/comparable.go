@@ -0,0 +1,94 @@
+package object
+
+var comparableModule = newModule("Comparable", comparableMethods)
+
+func init() {
+	classes.Set("Comparable", comparableModule)
+}
+
+// Compare compares a and b by sending `<=>` to a. It returns a negative
+// number if a is less than b, zero if both are equal and a positive number
+// if a is greater than b. If `<=>` does not return an Integer, e.g. because
+// the objects are not comparable, it returns an ArgumentError.
+func Compare(a, b RubyObject) (int, error) {
+	result, err := Send(a, "<=>", b)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := result.(*Integer)
+	if !ok {
+		return 0, NewComparisonError(a, b)
+	}
+	switch {
+	case i.Value < 0:
+		return -1, nil
+	case i.Value > 0:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+var comparableMethods = map[string]RubyMethod{
+	"<":        withArity(1, publicMethod(comparableLessThan)),
+	"<=":       withArity(1, publicMethod(comparableLessThanOrEqual)),
+	">":        withArity(1, publicMethod(comparableGreaterThan)),
+	">=":       withArity(1, publicMethod(comparableGreaterThanOrEqual)),
+	"==":       withArity(1, publicMethod(comparableEqual)),
+	"between?": withArity(2, publicMethod(comparableBetween)),
+}
+
+func comparableLessThan(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	cmp, err := Compare(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(cmp < 0), nil
+}
+
+func comparableLessThanOrEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	cmp, err := Compare(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(cmp <= 0), nil
+}
+
+func comparableGreaterThan(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	cmp, err := Compare(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(cmp > 0), nil
+}
+
+func comparableGreaterThanOrEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	cmp, err := Compare(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(cmp >= 0), nil
+}
+
+func comparableEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if context == args[0] {
+		return TRUE, nil
+	}
+	cmp, err := Compare(context, args[0])
+	if err != nil {
+		return FALSE, nil
+	}
+	return nativeBoolToBoolean(cmp == 0), nil
+}
+
+func comparableBetween(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	min, err := Compare(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	max, err := Compare(context, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(min >= 0 && max <= 0), nil
+}
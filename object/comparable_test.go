@@ -0,0 +1,59 @@
+package object
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     RubyObject
+		expected int
+		err      error
+	}{
+		{NewInteger(1), NewInteger(2), -1, nil},
+		{NewInteger(2), NewInteger(2), 0, nil},
+		{&String{Value: "b"}, &String{Value: "a"}, 1, nil},
+		{NIL, NIL, 0, nil},
+		{NewInteger(1), &String{Value: "a"}, 0, NewComparisonError(NewInteger(1), &String{Value: "a"})},
+		{NewInteger(1), NIL, 0, NewComparisonError(NewInteger(1), NIL)},
+	}
+
+	for _, tt := range tests {
+		actual, err := Compare(tt.a, tt.b)
+
+		checkError(t, err, tt.err)
+
+		if actual != tt.expected {
+			t.Logf("Expected %s <=> %s to equal %d, got %d", toString(tt.a), toString(tt.b), tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestComparisonErrorMessage(t *testing.T) {
+	err := NewComparisonError(NewInteger(1), NIL)
+
+	expected := "comparison of Integer with nil failed"
+	if err.Message != expected {
+		t.Logf("Expected message to equal %q, got %q", expected, err.Message)
+		t.Fail()
+	}
+}
+
+func TestComparableBetween(t *testing.T) {
+	tests := []struct {
+		context  RubyObject
+		min, max RubyObject
+		expected RubyObject
+	}{
+		{NewInteger(2), NewInteger(1), NewInteger(3), TRUE},
+		{NewInteger(1), NewInteger(1), NewInteger(3), TRUE},
+		{NewInteger(4), NewInteger(1), NewInteger(3), FALSE},
+		{&String{Value: "b"}, &String{Value: "a"}, &String{Value: "c"}, TRUE},
+	}
+
+	for _, tt := range tests {
+		result, err := comparableBetween(tt.context, tt.min, tt.max)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
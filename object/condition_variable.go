@@ -0,0 +1,82 @@
+package object
+
+import (
+	"fmt"
+	"time"
+)
+
+var conditionVariableClass RubyClassObject = newClass("Thread::ConditionVariable", objectClass, conditionVariableMethods, conditionVariableClassMethods)
+
+func init() {
+	classes.Set("ConditionVariable", conditionVariableClass)
+	setConstant(threadClass, "ConditionVariable", conditionVariableClass)
+}
+
+// A ConditionVariable represents a Ruby ConditionVariable, which lets
+// Threads holding a Mutex wait for a signal from another Thread
+type ConditionVariable struct {
+	waiters waitQueue
+}
+
+// Type returns CONDITION_VARIABLE_OBJ
+func (c *ConditionVariable) Type() Type { return CONDITION_VARIABLE_OBJ }
+
+// Inspect returns the object id of the ConditionVariable
+func (c *ConditionVariable) Inspect() string {
+	return fmt.Sprintf("#<Thread::ConditionVariable:0x%016x>", ObjectID(c))
+}
+
+// Class returns conditionVariableClass
+func (c *ConditionVariable) Class() RubyClass { return conditionVariableClass }
+
+var conditionVariableClassMethods = map[string]RubyMethod{
+	"new": withArity(0, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return &ConditionVariable{}, nil
+	})),
+}
+
+var conditionVariableMethods = map[string]RubyMethod{
+	"wait":      withArityRange(1, 2, publicRuntimeMethod(conditionVariableWait)),
+	"signal":    withArity(0, publicMethod(conditionVariableSignal)),
+	"broadcast": withArity(0, publicMethod(conditionVariableBroadcast)),
+}
+
+// conditionVariableWait releases the Mutex given, waits for a signal or
+// the optional timeout in seconds and locks the Mutex again
+func conditionVariableWait(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	condition := context.(*ConditionVariable)
+	mutex, ok := args[0].(*Mutex)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&Mutex{}, args[0])
+	}
+	timeout := time.Duration(-1)
+	if len(args) == 2 {
+		var err error
+		if timeout, err = timeoutArgument(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	if err := mutex.unlock(r); err != nil {
+		return nil, err
+	}
+	_, err := condition.waiters.wait(r, timeout)
+	if lockErr := mutex.lock(r); err == nil && lockErr != nil {
+		return nil, lockErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// conditionVariableSignal resumes the Thread waiting the longest
+func conditionVariableSignal(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	context.(*ConditionVariable).waiters.signal()
+	return context, nil
+}
+
+// conditionVariableBroadcast resumes all waiting Threads
+func conditionVariableBroadcast(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	context.(*ConditionVariable).waiters.broadcast()
+	return context, nil
+}
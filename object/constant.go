@@ -0,0 +1,347 @@
+package object
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// constantTable holds the constants of a single class or module in the
+// order of their definition
+type constantTable struct {
+	names  []string
+	values map[string]RubyObject
+}
+
+func (t *constantTable) get(name string) (RubyObject, bool) {
+	value, ok := t.values[name]
+	return value, ok
+}
+
+func (t *constantTable) set(name string, value RubyObject) {
+	if t.values == nil {
+		t.values = make(map[string]RubyObject)
+	}
+	if _, ok := t.values[name]; !ok {
+		t.names = append(t.names, name)
+	}
+	t.values[name] = value
+}
+
+// constantTables holds the constant tables of classes and modules
+type constantTables struct {
+	sync.Mutex
+	tables map[RubyObject]*constantTable
+}
+
+// of returns the constant table of module. If create is false and module
+// has no constants nil is returned.
+func (c *constantTables) of(module RubyObject, create bool) *constantTable {
+	c.Lock()
+	defer c.Unlock()
+	table, ok := c.tables[module]
+	if !ok && create {
+		table = &constantTable{}
+		c.tables[module] = table
+	}
+	return table
+}
+
+// sharedConstants holds the constants of all classes and modules except
+// Object defined outside of any Runtime, whose top level constants are the
+// ones within kernelFunctions
+var sharedConstants = constantTables{tables: make(map[RubyObject]*constantTable)}
+
+// constantTables returns the tables constants of classes and modules are
+// defined within
+func (r *Runtime) constantTables() *constantTables {
+	if r == nil {
+		return &sharedConstants
+	}
+	return &r.moduleConstants
+}
+
+// topLevelConstants returns the environment top level constants are
+// defined within
+func (r *Runtime) topLevelConstants() *sharedEnvironment {
+	if r == nil {
+		return kernelFunctions
+	}
+	return r.constants
+}
+
+// isTopLevel reports whether module is Object, which holds the top level
+// constants
+func isTopLevel(module RubyObject) bool {
+	return module == RubyObject(objectClass)
+}
+
+// qualifiedConstantName returns name prefixed by the name of module, unless
+// module is Object
+func qualifiedConstantName(module RubyObject, name string) string {
+	if isTopLevel(module) {
+		return name
+	}
+	return module.Inspect() + "::" + name
+}
+
+// topLevelConstantNames returns the names of all constants visible at the
+// top level in alphabetical order
+func (r *Runtime) topLevelConstantNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	all := append(kernelFunctions.names(), classes.(*environment).names()...)
+	if r != nil {
+		all = append(all, r.constants.names()...)
+	}
+	for _, name := range all {
+		if IsConstantName(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ownConstantNames returns the names of the constants defined directly
+// within module, other than Object, in definition order
+func (r *Runtime) ownConstantNames(module RubyObject) []string {
+	var names []string
+	if table := sharedConstants.of(module, false); table != nil {
+		names = append(names, table.names...)
+	}
+	if r == nil {
+		return names
+	}
+	if table := r.moduleConstants.of(module, false); table != nil {
+		names = append(names, table.names...)
+	}
+	return names
+}
+
+// ownConstant returns the constant name defined directly within module
+func (r *Runtime) ownConstant(module RubyObject, name string) (RubyObject, bool) {
+	if isTopLevel(module) {
+		return r.topLevelConstants().Get(name)
+	}
+	if r != nil {
+		if table := r.moduleConstants.of(module, false); table != nil {
+			if value, ok := table.get(name); ok {
+				return value, true
+			}
+		}
+	}
+	if table := sharedConstants.of(module, false); table != nil {
+		return table.get(name)
+	}
+	return nil, false
+}
+
+// lookupConstant returns the constant name of module. If inherit is true the
+// ancestors of module are searched as well, and for modules the top level.
+func (r *Runtime) lookupConstant(module RubyObject, name string, inherit bool) (RubyObject, bool) {
+	if !inherit {
+		return r.ownConstant(module, name)
+	}
+	for _, ancestor := range ancestorsOf(module) {
+		if value, ok := r.ownConstant(ancestor, name); ok {
+			return value, true
+		}
+	}
+	if _, ok := module.(*Module); ok {
+		return r.ownConstant(objectClass, name)
+	}
+	return nil, false
+}
+
+// setConstant defines the constant name within module. Anonymous classes
+// and modules assigned get named after the constant.
+func (r *Runtime) setConstant(module RubyObject, name string, value RubyObject) {
+	nameAnonymousModule(value, qualifiedConstantName(module, name))
+	if isTopLevel(module) {
+		r.topLevelConstants().Set(name, value)
+		return
+	}
+	r.constantTables().of(module, true).set(name, value)
+}
+
+// ownConstant returns the constant name defined directly within module
+// outside of any Runtime
+func ownConstant(module RubyObject, name string) (RubyObject, bool) {
+	return (*Runtime)(nil).ownConstant(module, name)
+}
+
+// setConstant defines the constant name within module outside of any
+// Runtime, which makes it visible within all of them
+func setConstant(module RubyObject, name string, value RubyObject) {
+	(*Runtime)(nil).setConstant(module, name, value)
+}
+
+// constantPath splits a constant path like `A::B` into its names. A leading
+// `::` refers to the top level and is reported by topLevel.
+func constantPath(path string) (names []string, topLevel bool, err error) {
+	if strings.HasPrefix(path, "::") {
+		path = path[2:]
+		topLevel = true
+	}
+	names = strings.Split(path, "::")
+	for _, name := range names {
+		if !IsConstantName(name) {
+			return nil, false, NewInvalidConstantNameError("wrong constant name %s", path)
+		}
+	}
+	return names, topLevel, nil
+}
+
+// SetConstant defines the top level constant name, i.e. a constant of
+// Object, outside of any Runtime, which makes it visible within all of
+// them.
+func SetConstant(name string, value RubyObject) {
+	setConstant(objectClass, name, value)
+}
+
+// SetConstant defines the top level constant name within r
+func (r *Runtime) SetConstant(name string, value RubyObject) {
+	r.setConstant(objectClass, name, value)
+}
+
+// ModuleConstant returns the constant name of module within r. If inherit
+// is true the ancestors of module are searched as well.
+func (r *Runtime) ModuleConstant(module RubyObject, name string, inherit bool) (RubyObject, bool) {
+	return r.lookupConstant(unwrapObject(module), name, inherit)
+}
+
+// SetModuleConstant defines the constant name within module within r
+func (r *Runtime) SetModuleConstant(module RubyObject, name string, value RubyObject) {
+	r.setConstant(unwrapObject(module), name, value)
+}
+
+// ConstMissing calls const_missing on Object within r for the top level
+// constant name, which raises a NameError unless it is overridden
+func (r *Runtime) ConstMissing(name string) (RubyObject, error) {
+	return r.Send(objectClass, "const_missing", NewSymbol(name))
+}
+
+func moduleConstGet(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	path, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	names, topLevel, err := constantPath(path)
+	if err != nil {
+		return nil, err
+	}
+	module := unwrapObject(context)
+	if topLevel {
+		module = objectClass
+	}
+	inherit := inheritArgument(args[1:])
+	var value RubyObject
+	for i, name := range names {
+		if i > 0 {
+			switch value.(type) {
+			case *Module, RubyClassObject:
+				module = value
+			default:
+				return nil, NewTypeError("%s does not refer to class/module", value.Inspect())
+			}
+		}
+		var ok bool
+		value, ok = r.lookupConstant(module, name, inherit)
+		if !ok {
+			value, err = r.Send(module, "const_missing", NewSymbol(name))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+func moduleConstSet(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !IsConstantName(name) || strings.Contains(name, "::") {
+		return nil, NewInvalidConstantNameError("wrong constant name %s", name)
+	}
+	r.setConstant(unwrapObject(context), name, args[1])
+	return args[1], nil
+}
+
+func moduleIsConstDefined(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	path, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	names, topLevel, err := constantPath(path)
+	if err != nil {
+		return nil, err
+	}
+	module := unwrapObject(context)
+	if topLevel {
+		module = objectClass
+	}
+	inherit := inheritArgument(args[1:])
+	for i, name := range names {
+		value, ok := r.lookupConstant(module, name, inherit)
+		if !ok {
+			return FALSE, nil
+		}
+		if i == len(names)-1 {
+			break
+		}
+		switch value.(type) {
+		case *Module, RubyClassObject:
+			module = value
+		default:
+			return FALSE, nil
+		}
+	}
+	return TRUE, nil
+}
+
+// moduleConstantNames returns the names of the constants of the receiver in
+// definition order, followed by the ones of its ancestors if the optional
+// argument is not false. Top level constants are only listed for Object.
+func moduleConstantNames(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	module := unwrapObject(context)
+	if isTopLevel(module) {
+		var names []RubyObject
+		for _, name := range r.topLevelConstantNames() {
+			names = append(names, NewSymbol(name))
+		}
+		return NewArray(names...), nil
+	}
+	ancestors := []RubyObject{module}
+	if inheritArgument(args) {
+		ancestors = ancestorsOf(module)
+	}
+	seen := make(map[string]bool)
+	var names []RubyObject
+	for _, ancestor := range ancestors {
+		if isTopLevel(ancestor) {
+			continue
+		}
+		for _, name := range r.ownConstantNames(ancestor) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, NewSymbol(name))
+			}
+		}
+	}
+	return NewArray(names...), nil
+}
+
+// moduleConstMissing is called for constants which cannot be found. It
+// raises a NameError.
+func moduleConstMissing(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nil, NewUninitializedConstantError(qualifiedConstantName(unwrapObject(context), name))
+}
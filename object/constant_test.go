@@ -0,0 +1,62 @@
+package object
+
+import "testing"
+
+func TestConstantPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		names    []string
+		topLevel bool
+		err      error
+	}{
+		{"A", []string{"A"}, false, nil},
+		{"A::B", []string{"A", "B"}, false, nil},
+		{"::A", []string{"A"}, true, nil},
+		{"a::B", nil, false, NewInvalidConstantNameError("wrong constant name %s", "a::B")},
+		{"A::", nil, false, NewInvalidConstantNameError("wrong constant name %s", "A::")},
+	}
+
+	for _, tt := range tests {
+		names, topLevel, err := constantPath(tt.path)
+		checkError(t, err, tt.err)
+		if len(names) != len(tt.names) || topLevel != tt.topLevel {
+			t.Logf("Expected %q to split into %v (top level: %t), got %v (%t)", tt.path, tt.names, tt.topLevel, names, topLevel)
+			t.Fail()
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.names[i] {
+				t.Logf("Expected %q to split into %v, got %v", tt.path, tt.names, names)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestModuleConstants(t *testing.T) {
+	parent := newSubclass("", objectClass)
+	child := newSubclass("", parent)
+
+	_, err := moduleConstSet(nil, parent, &Symbol{"Nested"}, newSubclass("", objectClass))
+	checkError(t, err, nil)
+	_, err = moduleConstSet(nil, child, &String{Value: "VALUE"}, NewInteger(1))
+	checkError(t, err, nil)
+
+	nested, err := moduleConstGet(nil, child, &Symbol{"Nested"})
+	checkError(t, err, nil)
+	if nested.Inspect() != parent.Inspect()+"::Nested" {
+		t.Logf("Expected nested class to be named after the constant, got %s", nested.Inspect())
+		t.Fail()
+	}
+
+	result, err := moduleIsConstDefined(nil, child, &Symbol{"Nested"}, FALSE)
+	checkError(t, err, nil)
+	checkResult(t, result, FALSE)
+
+	result, err = moduleConstantNames(nil, child)
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray(&Symbol{"VALUE"}, &Symbol{"Nested"}))
+
+	_, err = moduleConstGet(nil, child, &Symbol{"Missing"}, FALSE)
+	checkError(t, err, NewUninitializedConstantError(child.Inspect()+"::Missing"))
+}
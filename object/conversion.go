@@ -0,0 +1,227 @@
+package object
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// floatLiteral matches the strings Float() accepts besides hexadecimal
+// integers. Underscores are allowed between digits only.
+var floatLiteral = regexp.MustCompile(`^[+-]?\d+(_\d+)*(\.\d+(_\d+)*)?([eE][+-]?\d+(_\d+)*)?$`)
+
+// exceptionOption removes a trailing options Hash with an exception key
+// from args. raise is false if the option is given and falsy.
+func exceptionOption(args []RubyObject) (rest []RubyObject, raise bool) {
+	args, _ = extractBlock(args)
+	if len(args) < 2 {
+		return args, true
+	}
+	options, ok := args[len(args)-1].(*Hash)
+	if !ok {
+		return args, true
+	}
+	exception, ok := options.Get(NewSymbol("exception"))
+	if !ok {
+		return args, true
+	}
+	return args[:len(args)-1], isTruthy(exception)
+}
+
+// rescueConversion returns nil instead of the ArgumentError or TypeError
+// of a failed conversion unless raise is true
+func rescueConversion(result RubyObject, err error, raise bool) (RubyObject, error) {
+	if err == nil || raise {
+		return result, err
+	}
+	switch err.(type) {
+	case *ArgumentError, *TypeError:
+		return NIL, nil
+	default:
+		return nil, err
+	}
+}
+
+// convertWith sends the first of methods obj responds to and returns its
+// result. ok is false if obj responds to none of them. The result has to be
+// of the same type as expected, otherwise a TypeError naming the conversion
+// method is returned.
+func convertWith(obj RubyObject, expected RubyObject, methods ...string) (RubyObject, bool, error) {
+	for _, name := range methods {
+		method, ok := findMethod(obj, name)
+		if !ok {
+			continue
+		}
+		result, err := method.Call(obj)
+		if err != nil {
+			return nil, true, err
+		}
+		if result.Type() != expected.Type() {
+			class := comparisonOperandName(obj)
+			return nil, true, NewTypeError(
+				"can't convert %s to %s (%s#%s gives %s)",
+				class,
+				expected.Class().(RubyObject).Inspect(),
+				class,
+				name,
+				comparisonOperandName(result),
+			)
+		}
+		return result, true, nil
+	}
+	return nil, false, nil
+}
+
+// kernelInteger converts its argument strictly to an Integer. Strings must
+// contain a valid integer literal, optionally in the base given as second
+// argument. Other objects are converted by to_int or to_i. Passing
+// `exception: false` returns nil instead of raising an error.
+func kernelInteger(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, raise := exceptionOption(args)
+	result, err := toInteger(args)
+	return rescueConversion(result, err, raise)
+}
+
+func toInteger(args []RubyObject) (RubyObject, error) {
+	base := 0
+	if len(args) == 2 {
+		radix, err := radixArgument(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		base = radix
+	}
+	if _, ok := args[0].(*String); !ok && len(args) == 2 {
+		return nil, NewArgumentError("base specified for non string value")
+	}
+	switch arg := args[0].(type) {
+	case *String:
+		value, ok := parseInteger(arg.Value, base, true)
+		if !ok {
+			return nil, NewArgumentError("invalid value for Integer(): %q", arg.Value)
+		}
+		return NewInteger(value), nil
+	case *Integer:
+		return arg, nil
+	case *Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+			return nil, NewRangeError("%s", arg.Inspect())
+		}
+		return NewInteger(int64(arg.Value)), nil
+	case *nilObject:
+		return nil, NewTypeError("can't convert nil into Integer")
+	}
+	result, ok, err := convertWith(args[0], &Integer{}, "to_int", "to_i")
+	if !ok {
+		return nil, NewTypeError("can't convert %s into Integer", comparisonOperandName(args[0]))
+	}
+	return result, err
+}
+
+// kernelFloat converts its argument strictly to a Float. Strings must
+// contain a valid decimal or hexadecimal number. Other objects are converted
+// by to_f. Passing `exception: false` returns nil instead of raising an
+// error.
+func kernelFloat(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, raise := exceptionOption(args)
+	if len(args) != 1 {
+		return nil, NewWrongNumberOfArgumentsError(1, len(args))
+	}
+	result, err := toFloatStrict(args[0])
+	return rescueConversion(result, err, raise)
+}
+
+func toFloatStrict(arg RubyObject) (RubyObject, error) {
+	switch arg := arg.(type) {
+	case *Float:
+		return arg, nil
+	case *Integer:
+		return NewFloat(float64(arg.Value)), nil
+	case *String:
+		value, ok := parseFloat(arg.Value)
+		if !ok {
+			return nil, NewArgumentError("invalid value for Float(): %q", arg.Value)
+		}
+		return NewFloat(value), nil
+	case *nilObject:
+		return nil, NewTypeError("can't convert nil into Float")
+	}
+	result, ok, err := convertWith(arg, &Float{}, "to_f")
+	if !ok {
+		return nil, NewTypeError("can't convert %s into Float", comparisonOperandName(arg))
+	}
+	return result, err
+}
+
+// parseFloat parses s as strictly as Float() does. Surrounding whitespace
+// is ignored.
+func parseFloat(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if value, ok := parseInteger(s, 0, true); ok && isHexLiteral(s) {
+		return float64(value), true
+	}
+	if !floatLiteral.MatchString(s) {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(s, "_", "", -1), 64)
+	if err != nil && !math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+// isHexLiteral reports whether s starts with a hexadecimal prefix after an
+// optional sign
+func isHexLiteral(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+}
+
+// kernelString converts its argument to a String by to_str or to_s
+func kernelString(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if str, ok := args[0].(*String); ok {
+		return str, nil
+	}
+	result, ok, err := convertWith(args[0], &String{}, "to_str", "to_s")
+	if !ok {
+		return nil, NewTypeError("can't convert %s into String", comparisonOperandName(args[0]))
+	}
+	return result, err
+}
+
+// kernelArray converts its argument to an Array by to_ary or to_a. nil
+// becomes an empty Array, all other objects are wrapped into one.
+func kernelArray(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	switch arg := args[0].(type) {
+	case *Array:
+		return arg, nil
+	case *nilObject:
+		return NewArray(), nil
+	}
+	result, ok, err := convertWith(args[0], &Array{}, "to_ary", "to_a")
+	if !ok {
+		return NewArray(args[0]), nil
+	}
+	return result, err
+}
+
+// kernelHashConversion converts its argument to a Hash by to_hash. nil and
+// an empty Array become an empty Hash.
+func kernelHashConversion(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	switch arg := args[0].(type) {
+	case *Hash:
+		return arg, nil
+	case *nilObject:
+		return NewHash(nil), nil
+	case *Array:
+		if len(arg.Elements) == 0 {
+			return NewHash(nil), nil
+		}
+	}
+	result, ok, err := convertWith(args[0], &Hash{}, "to_hash")
+	if !ok {
+		return nil, NewTypeError("can't convert %s into Hash", comparisonOperandName(args[0]))
+	}
+	return result, err
+}
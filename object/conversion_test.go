@@ -0,0 +1,120 @@
+package object
+
+import "testing"
+
+func TestKernelInteger(t *testing.T) {
+	noException := NewHash(map[RubyObject]RubyObject{&Symbol{"exception"}: FALSE})
+
+	tests := []struct {
+		args     []RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{[]RubyObject{&String{Value: "42"}}, NewInteger(42), nil},
+		{[]RubyObject{&String{Value: " 0x1A "}}, NewInteger(26), nil},
+		{[]RubyObject{&String{Value: "ff"}, NewInteger(16)}, NewInteger(255), nil},
+		{[]RubyObject{NewFloat(3.9)}, NewInteger(3), nil},
+		{[]RubyObject{&String{Value: "4x2"}}, nil, NewArgumentError(`invalid value for Integer(): "4x2"`)},
+		{[]RubyObject{NIL}, nil, NewTypeError("can't convert nil into Integer")},
+		{[]RubyObject{NewArray()}, nil, NewTypeError("can't convert Array into Integer")},
+		{[]RubyObject{NewInteger(4), NewInteger(2)}, nil, NewArgumentError("base specified for non string value")},
+		{[]RubyObject{&String{Value: "abc"}, noException}, NIL, nil},
+		{[]RubyObject{NIL, noException}, NIL, nil},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelInteger(NIL, tt.args...)
+		checkError(t, err, tt.err)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestKernelFloat(t *testing.T) {
+	noException := NewHash(map[RubyObject]RubyObject{&Symbol{"exception"}: FALSE})
+
+	tests := []struct {
+		args     []RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{[]RubyObject{&String{Value: "1.5"}}, NewFloat(1.5), nil},
+		{[]RubyObject{&String{Value: "1_000.25"}}, NewFloat(1000.25), nil},
+		{[]RubyObject{&String{Value: "-1e3"}}, NewFloat(-1000), nil},
+		{[]RubyObject{&String{Value: "0x1A"}}, NewFloat(26), nil},
+		{[]RubyObject{NewInteger(3)}, NewFloat(3), nil},
+		{[]RubyObject{&String{Value: "1."}}, nil, NewArgumentError(`invalid value for Float(): "1."`)},
+		{[]RubyObject{&String{Value: "1__0"}}, nil, NewArgumentError(`invalid value for Float(): "1__0"`)},
+		{[]RubyObject{&String{Value: "NaN"}}, nil, NewArgumentError(`invalid value for Float(): "NaN"`)},
+		{[]RubyObject{NIL}, nil, NewTypeError("can't convert nil into Float")},
+		{[]RubyObject{&Symbol{"a"}}, nil, NewTypeError("can't convert Symbol into Float")},
+		{[]RubyObject{&String{Value: "x"}, noException}, NIL, nil},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelFloat(NIL, tt.args...)
+		checkError(t, err, tt.err)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestKernelString(t *testing.T) {
+	tests := []struct {
+		arg      RubyObject
+		expected RubyObject
+	}{
+		{&String{Value: "a"}, &String{Value: "a"}},
+		{NewInteger(1), &String{Value: "1"}},
+		{&Symbol{"a"}, &String{Value: "a"}},
+		{NIL, &String{Value: ""}},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelString(NIL, tt.arg)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestKernelArray(t *testing.T) {
+	tests := []struct {
+		arg      RubyObject
+		expected string
+	}{
+		{NIL, "[]"},
+		{NewArray(NewInteger(1)), "[1]"},
+		{&Range{First: NewInteger(1), Last: NewInteger(3)}, "[1, 2, 3]"},
+		{NewHash(map[RubyObject]RubyObject{&Symbol{"a"}: NewInteger(1)}), "[[:a, 1]]"},
+		{NewInteger(1), "[1]"},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelArray(NIL, tt.arg)
+		checkError(t, err, nil)
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected %s, got %s", tt.expected, result.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestKernelHashConversion(t *testing.T) {
+	hash := NewHash(map[RubyObject]RubyObject{&Symbol{"a"}: NewInteger(1)})
+
+	tests := []struct {
+		arg      RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{NIL, &Hash{}, nil},
+		{NewArray(), &Hash{}, nil},
+		{hash, hash, nil},
+		{NewInteger(1), nil, NewTypeError("can't convert Integer into Hash")},
+		{NewArray(NewInteger(1)), nil, NewTypeError("can't convert Array into Hash")},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelHashConversion(NIL, tt.arg)
+		checkError(t, err, tt.err)
+		checkResult(t, result, tt.expected)
+	}
+}
@@ -0,0 +1,128 @@
+package object
+
+// shallowCopy returns a copy of obj which shares all referenced objects with
+// the original. It reports false if objects of that kind can not be copied.
+func shallowCopy(obj RubyObject) (RubyObject, bool) {
+	switch obj := obj.(type) {
+	case *Object:
+		allocatedInstances.add()
+		return &Object{class: realClass(obj)}, true
+	case *mainObject:
+		allocatedInstances.add()
+		return &Object{class: realClass(obj)}, true
+	case *basicObject:
+		return &basicObject{class: realClass(obj)}, true
+	case *String:
+		return &String{Value: obj.Value}, true
+	case *Array:
+		return obj.snapshot(), true
+	case *Hash:
+		return obj.snapshot(), true
+	case *Range:
+		copy := *obj
+		return &copy, true
+	case *Regexp:
+		copy := *obj
+		return &copy, true
+	case *Proc:
+		copy := *obj
+		return &copy, true
+	default:
+		return nil, false
+	}
+}
+
+// singletonOf returns the extendedObject holding the singleton methods of
+// obj, if any
+func singletonOf(obj RubyObject) (*extendedObject, bool) {
+	for {
+		switch wrapper := obj.(type) {
+		case *Self:
+			obj = wrapper.RubyObject
+		case *definingSelf:
+			obj = wrapper.RubyObject
+		case *extendedObject:
+			return wrapper, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// copyObject returns a shallow copy of obj including its instance
+// variables. If clone is true, the singleton methods are copied as well.
+// The copy gets initialized by calling initialize_copy with the original.
+func copyObject(obj RubyObject, clone bool) (RubyObject, error) {
+	original := unwrapObject(obj)
+	switch original.(type) {
+	case *Integer, *Float, *Symbol, *Boolean, *nilObject:
+		return original, nil
+	}
+	copy, ok := shallowCopy(original)
+	if !ok {
+		return nil, NewTypeError("can't copy %s", original.Class().(RubyObject).Inspect())
+	}
+	if ivars := instanceVariablesOf(original, false); ivars != nil {
+		copyIvars := instanceVariablesOf(copy, true)
+		for _, name := range ivars.names {
+			copyIvars.set(name, ivars.values[name])
+		}
+	}
+	if holder, ok := original.(singletonHolder); clone && ok {
+		if eigenClass, ok := holder.Class().(*eigenclass); ok {
+			copyEigenClass := copy.(singletonHolder).singletonClass()
+			for name, method := range eigenClass.methods {
+				copyEigenClass.addMethod(name, method)
+			}
+		}
+	}
+	if singleton, ok := singletonOf(obj); clone && ok {
+		methods := make(map[string]RubyMethod, len(singleton.class.methods))
+		for name, method := range singleton.class.methods {
+			methods[name] = method
+		}
+		copy = &extendedObject{
+			RubyObject: copy,
+			class:      newEigenclass(singleton.class.wrappedClass, methods),
+		}
+	}
+	initializeCopy, ok := findMethod(copy, "initialize_copy")
+	if ok {
+		if _, err := initializeCopy.Call(copy, original); err != nil {
+			return nil, err
+		}
+	}
+	return copy, nil
+}
+
+func kernelDup(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return copyObject(context, false)
+}
+
+// kernelClone copies context including its singleton methods and frozen
+// state. Passing `freeze: false` returns an unfrozen copy.
+func kernelClone(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	freeze := true
+	args, _ = extractBlock(args)
+	if len(args) == 1 {
+		options, ok := args[0].(*Hash)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(&Hash{}, args[0])
+		}
+		if value, ok := options.Get(NewSymbol("freeze")); ok {
+			freeze = isTruthy(value)
+		}
+	}
+	copy, err := copyObject(context, true)
+	if err != nil {
+		return nil, err
+	}
+	if obj, ok := unwrapObject(copy).(freezable); ok && freeze && isFrozen(unwrapObject(context)) {
+		obj.Freeze()
+	}
+	return copy, nil
+}
+
+func kernelInitializeCopy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context, nil
+}
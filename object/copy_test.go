@@ -0,0 +1,214 @@
+package object
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestKernelDup(t *testing.T) {
+	t.Run("copies value and instance variables", func(t *testing.T) {
+		original := &String{Value: "foo"}
+		InstanceVariableSet(original, "@foo", NewInteger(1))
+		original.Freeze()
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+
+		copy, ok := result.(*String)
+		if !ok || copy == original {
+			t.Logf("Expected a new String, got %#v", result)
+			t.FailNow()
+		}
+		checkResult(t, copy, &String{Value: "foo"})
+
+		value, ok := InstanceVariableGet(copy, "@foo")
+		if !ok || !reflect.DeepEqual(value, NewInteger(1)) {
+			t.Logf("Expected copy to have @foo set to 1, got %v", value)
+			t.Fail()
+		}
+	})
+	t.Run("copies are shallow", func(t *testing.T) {
+		element := &String{Value: "foo"}
+		original := NewArray(element)
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+
+		copy := result.(*Array)
+		copy.Elements = append(copy.Elements, NIL)
+		if len(original.Elements) != 1 || copy.Elements[0] != element {
+			t.Logf("Expected a shallow copy, got %v from %v", copy.Inspect(), original.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("immediates", func(t *testing.T) {
+		original := NewInteger(3)
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+
+		if result != original {
+			t.Logf("Expected dup of Integer to return the receiver, got %#v", result)
+			t.Fail()
+		}
+	})
+	t.Run("drops singleton methods", func(t *testing.T) {
+		original := AddMethod(&Object{}, "foo", &Function{})
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+
+		if _, ok := findMethod(result, "foo"); ok {
+			t.Logf("Expected dup to drop singleton methods")
+			t.Fail()
+		}
+	})
+	t.Run("not copyable", func(t *testing.T) {
+		_, err := Send(&testRubyObject{}, "dup")
+		checkError(t, err, NewTypeError("can't copy Object"))
+	})
+}
+
+func TestKernelClone(t *testing.T) {
+	t.Run("copies frozen state", func(t *testing.T) {
+		original := &String{Value: "foo", frozen: true}
+
+		result, err := Send(original, "clone")
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "foo", frozen: true})
+
+		result, err = Send(original, "clone", NewHash(map[RubyObject]RubyObject{&Symbol{"freeze"}: FALSE}))
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "foo"})
+	})
+	t.Run("copies frozen state of containers and objects", func(t *testing.T) {
+		originals := []RubyObject{
+			&Array{Elements: []RubyObject{NewInteger(1)}, frozen: true},
+			&Hash{frozen: true},
+			&Object{frozen: true},
+		}
+
+		for _, original := range originals {
+			result, err := Send(original, "clone")
+			checkError(t, err, nil)
+			frozen, _ := Send(result, "frozen?")
+			checkResult(t, frozen, TRUE)
+
+			result, err = Send(original, "dup")
+			checkError(t, err, nil)
+			frozen, _ = Send(result, "frozen?")
+			checkResult(t, frozen, FALSE)
+		}
+
+		_, err := Send(originals[0], "push", NewInteger(2))
+		checkError(t, err, NewFrozenError(originals[0]))
+
+		_, err = Send(originals[1], "[]=", NewInteger(1), NewInteger(2))
+		checkError(t, err, NewFrozenError(originals[1]))
+	})
+	t.Run("copies singleton methods", func(t *testing.T) {
+		original := AddMethod(&Object{}, "foo", &Function{})
+
+		result, err := Send(original, "clone")
+		checkError(t, err, nil)
+
+		if _, ok := findMethod(result, "foo"); !ok {
+			t.Logf("Expected clone to keep singleton methods")
+			t.Fail()
+		}
+		AddMethod(result, "bar", &Function{})
+		if _, ok := findMethod(original, "bar"); ok {
+			t.Logf("Expected clone not to share singleton methods with the original")
+			t.Fail()
+		}
+	})
+	t.Run("calls initialize_copy", func(t *testing.T) {
+		var received []RubyObject
+		original := &extendedObject{
+			RubyObject: &Object{},
+			class: newEigenclass(objectClass, map[string]RubyMethod{
+				"initialize_copy": privateMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+					received = append(received, context, args[0])
+					return context, nil
+				}),
+			}),
+		}
+
+		result, err := Send(original, "clone")
+		checkError(t, err, nil)
+
+		expected := []RubyObject{result, original.RubyObject}
+		if !reflect.DeepEqual(received, expected) {
+			t.Logf("Expected initialize_copy to be called with %v, got %v", expected, received)
+			t.Fail()
+		}
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := Send(&Object{}, "clone", NewInteger(1))
+		checkError(t, err, NewImplicitConversionTypeError(&Hash{}, NewInteger(1)))
+	})
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	t.Run("Array", func(t *testing.T) {
+		original := NewArray(NewInteger(1), NewInteger(2), NewInteger(3))
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+		copy := result.(*Array)
+
+		if &copy.Elements[0] != &original.Elements[0] {
+			t.Logf("Expected copy to share the elements of the original")
+			t.Fail()
+		}
+
+		_, err = Send(copy, "pop")
+		checkError(t, err, nil)
+		_, err = Send(copy, "push", NewInteger(4))
+		checkError(t, err, nil)
+		_, err = Send(original, "fill", NewInteger(0))
+		checkError(t, err, nil)
+
+		checkResult(t, original, &Array{Elements: []RubyObject{NewInteger(0), NewInteger(0), NewInteger(0)}})
+		checkResult(t, copy, &Array{Elements: []RubyObject{NewInteger(1), NewInteger(2), NewInteger(4)}})
+	})
+	t.Run("Hash", func(t *testing.T) {
+		original := &Hash{}
+		original.Set(NewSymbol("a"), NewInteger(1))
+
+		result, err := Send(original, "dup")
+		checkError(t, err, nil)
+		copy := result.(*Hash)
+
+		copy.Set(NewSymbol("a"), NewInteger(2))
+		original.Set(NewSymbol("b"), NewInteger(3))
+
+		if original.Inspect() != "{:a=>1, :b=>3}" {
+			t.Logf("Expected original to be unaffected by the copy, got %s", original.Inspect())
+			t.Fail()
+		}
+		if copy.Inspect() != "{:a=>2}" {
+			t.Logf("Expected copy to be unaffected by the original, got %s", copy.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("concurrent copies", func(t *testing.T) {
+		originals := []RubyObject{
+			&Array{Elements: []RubyObject{NewInteger(1)}, frozen: true},
+			&Hash{frozen: true},
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, original := range originals {
+					shallowCopy(original)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
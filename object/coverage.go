@@ -0,0 +1,133 @@
+package object
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/goruby/goruby/coverage"
+)
+
+var coverageModule = newModule("Coverage", coverageMethods)
+
+func init() {
+	classes.Set("Coverage", coverageModule)
+}
+
+// coverageState holds the lines of the files loaded while coverage is
+// measured. enabled is set while it is measured, so that CoverageEnabled
+// does not need to lock.
+var coverageState struct {
+	sync.Mutex
+	result  coverage.Result
+	enabled int32
+}
+
+// CoverageEnabled reports whether coverage is measured, so that the
+// evaluator only counts lines if anybody asks for them
+func CoverageEnabled() bool {
+	return atomic.LoadInt32(&coverageState.enabled) != 0
+}
+
+// StartCoverage starts measuring the coverage of the files loaded from now
+// on. It returns a RuntimeError if coverage is measured already.
+func StartCoverage() error {
+	coverageState.Lock()
+	defer coverageState.Unlock()
+	if coverageState.result != nil {
+		return NewRuntimeError("coverage measurement is already setup")
+	}
+	coverageState.result = make(coverage.Result)
+	atomic.StoreInt32(&coverageState.enabled, 1)
+	return nil
+}
+
+// StopCoverage stops measuring coverage and returns the result, or nil if
+// coverage is not measured
+func StopCoverage() coverage.Result {
+	coverageState.Lock()
+	defer coverageState.Unlock()
+	result := coverageState.result
+	coverageState.result = nil
+	atomic.StoreInt32(&coverageState.enabled, 0)
+	return result
+}
+
+// RegisterCoverage adds the file path with lineCount lines, of which the
+// given ones are executable, to the coverage measured. The counts of a
+// file loaded again start over.
+func RegisterCoverage(path string, lineCount int, executable []int) {
+	coverageState.Lock()
+	defer coverageState.Unlock()
+	if coverageState.result != nil {
+		coverageState.result[path] = coverage.NewLines(lineCount, executable)
+	}
+}
+
+// CoverLine counts an execution of line of the file path, if its coverage
+// is measured
+func CoverLine(path string, line int) {
+	coverageState.Lock()
+	defer coverageState.Unlock()
+	if lines, ok := coverageState.result[path]; ok {
+		lines.Hit(line)
+	}
+}
+
+var coverageMethods = map[string]RubyMethod{
+	"start":       withArityRange(0, 1, publicMethod(coverageStart)),
+	"result":      withArity(0, publicMethod(coverageResult)),
+	"peek_result": withArity(0, publicMethod(coveragePeekResult)),
+	"running?":    withArity(0, publicMethod(coverageIsRunning)),
+}
+
+// coverageStart starts measuring line coverage. The modes MRI accepts as
+// argument are ignored, only lines are counted.
+func coverageStart(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if err := StartCoverage(); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+// coverageResult stops measuring coverage and returns the result as Hash
+// mapping the paths of the files to the Array of their line counts
+func coverageResult(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	result := StopCoverage()
+	if result == nil {
+		return nil, NewRuntimeError("coverage measurement is not enabled")
+	}
+	return coverageHash(result), nil
+}
+
+// coveragePeekResult returns the result like result, but continues
+// measuring coverage
+func coveragePeekResult(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	coverageState.Lock()
+	defer coverageState.Unlock()
+	if coverageState.result == nil {
+		return nil, NewRuntimeError("coverage measurement is not enabled")
+	}
+	return coverageHash(coverageState.result), nil
+}
+
+func coverageIsRunning(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(CoverageEnabled()), nil
+}
+
+// coverageHash returns result as Hash, with nil for the lines which are not
+// executable
+func coverageHash(result coverage.Result) *Hash {
+	hash := NewHash(nil)
+	for _, path := range result.Paths() {
+		counts := NewArray()
+		for _, count := range result[path] {
+			if count == coverage.NotExecutable {
+				counts.Elements = append(counts.Elements, NIL)
+				continue
+			}
+			counts.Elements = append(counts.Elements, NewInteger(count))
+		}
+		hash.Set(&String{Value: path}, counts)
+	}
+	return hash
+}
@@ -1,5 +1,10 @@
 package object
 
+import (
+	"sync"
+	"sync/atomic"
+)
+
 func newEigenclass(wrappedClass RubyClass, methods map[string]RubyMethod) *eigenclass {
 	return &eigenclass{methods: methods, wrappedClass: wrappedClass}
 }
@@ -17,9 +22,6 @@ func (e *eigenclass) Inspect() string {
 }
 func (e *eigenclass) Type() Type { return EIGENCLASS_OBJ }
 func (e *eigenclass) Class() RubyClass {
-	if e.wrappedClass != nil {
-		return e.wrappedClass
-	}
 	return classClass
 }
 func (e *eigenclass) Methods() map[string]RubyMethod { return e.methods }
@@ -30,5 +32,103 @@ func (e *eigenclass) SuperClass() RubyClass {
 	return objectClass
 }
 func (e *eigenclass) addMethod(name string, method RubyMethod) {
+	methodTables.Lock()
+	if e.methods == nil {
+		e.methods = make(map[string]RubyMethod)
+	}
 	e.methods[name] = method
+	methodTables.Unlock()
+	invalidateMethodCache()
+}
+
+// singletonHolder is implemented by objects which keep their singleton class
+// themselves, instead of getting wrapped into an extendedObject
+type singletonHolder interface {
+	RubyObject
+	singletonClass() *eigenclass
+}
+
+// singletonClassOf returns the singleton class of obj, creating it if
+// necessary. It returns a TypeError for immediate values, which cannot
+// have one.
+func singletonClassOf(obj RubyObject) (*eigenclass, error) {
+	if extended, ok := singletonOf(obj); ok {
+		return extended.class, nil
+	}
+	obj = unwrapObject(obj)
+	switch obj := obj.(type) {
+	case singletonHolder:
+		return obj.singletonClass(), nil
+	case *Module:
+		if eigenClass, ok := obj.Class().(*eigenclass); ok {
+			return eigenClass, nil
+		}
+	case RubyClassObject:
+		if eigenClass, ok := obj.Class().(*eigenclass); ok {
+			return eigenClass, nil
+		}
+	case *Integer, *Float, *Symbol, *nilObject, *Boolean:
+		return nil, NewTypeError("can't define singleton")
+	}
+	return detachedSingletonClass(obj), nil
+}
+
+// detachedSingletons holds the singleton classes of the objects which
+// cannot keep one themselves, like Strings or Arrays, by the objects.
+// Method lookups starting at one of them start at its singleton class.
+var detachedSingletons = struct {
+	sync.RWMutex
+	classes map[RubyObject]*eigenclass
+	count   int32
+}{classes: make(map[RubyObject]*eigenclass)}
+
+// detachedSingletonClass returns the singleton class of obj kept within
+// detachedSingletons, creating it if necessary
+func detachedSingletonClass(obj RubyObject) *eigenclass {
+	detachedSingletons.Lock()
+	defer detachedSingletons.Unlock()
+	eigenClass, ok := detachedSingletons.classes[obj]
+	if !ok {
+		eigenClass = newEigenclass(obj.Class(), nil)
+		detachedSingletons.classes[obj] = eigenClass
+		atomic.AddInt32(&detachedSingletons.count, 1)
+	}
+	return eigenClass
+}
+
+// detachedSingleton returns the singleton class of obj kept within
+// detachedSingletons, if it has one
+func detachedSingleton(obj RubyObject) (*eigenclass, bool) {
+	if atomic.LoadInt32(&detachedSingletons.count) == 0 {
+		return nil, false
+	}
+	detachedSingletons.RLock()
+	defer detachedSingletons.RUnlock()
+	eigenClass, ok := detachedSingletons.classes[unwrapObject(obj)]
+	return eigenClass, ok
+}
+
+// classOf returns the class method lookups for obj start at, which is its
+// detached singleton class if it has one
+func classOf(obj RubyObject) RubyClass {
+	if eigenClass, ok := detachedSingleton(obj); ok {
+		return eigenClass
+	}
+	return obj.Class()
+}
+
+// realClass returns the class of obj, skipping singleton classes
+func realClass(obj RubyObject) RubyClass {
+	class := obj.Class()
+	for {
+		eigenClass, ok := class.(*eigenclass)
+		if !ok || eigenClass.wrappedClass == nil {
+			return class
+		}
+		class = eigenClass.wrappedClass
+	}
+}
+
+func kernelSingletonClass(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return singletonClassOf(context)
 }
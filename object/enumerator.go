@@ -0,0 +1,372 @@
+package object
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var enumeratorClass RubyClassObject = newClass("Enumerator", objectClass, enumeratorMethods, enumeratorClassMethods)
+
+var generatorClass RubyClassObject = newClass("Enumerator::Generator", objectClass, generatorMethods, nil)
+
+var yielderClass RubyClassObject = newClass("Enumerator::Yielder", objectClass, yielderMethods, nil)
+
+func init() {
+	classes.Set("Enumerator", enumeratorClass)
+	setConstant(enumeratorClass, "Generator", generatorClass)
+	setConstant(enumeratorClass, "Yielder", yielderClass)
+}
+
+// NewEnumerator returns an Enumerator which iterates by sending method with
+// args and a block to receiver
+func NewEnumerator(receiver RubyObject, method string, args ...RubyObject) *Enumerator {
+	return &Enumerator{Receiver: receiver, Method: method, Args: args}
+}
+
+// An Enumerator represents a not yet started iteration, as returned by
+// iterating methods called without a block
+type Enumerator struct {
+	Receiver RubyObject
+	Method   string
+	Args     []RubyObject
+	external *externalIteration
+}
+
+// Type returns ENUMERATOR_OBJ
+func (e *Enumerator) Type() Type { return ENUMERATOR_OBJ }
+
+// Inspect returns the receiver and method the Enumerator iterates with
+func (e *Enumerator) Inspect() string {
+	var out strings.Builder
+	out.WriteString("#<Enumerator: ")
+	out.WriteString(e.Receiver.Inspect())
+	out.WriteString(":")
+	out.WriteString(e.Method)
+	if len(e.Args) > 0 {
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = arg.Inspect()
+		}
+		out.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// Class returns enumeratorClass
+func (e *Enumerator) Class() RubyClass { return enumeratorClass }
+
+// Each runs the iteration, calling block for every yielded value
+func (e *Enumerator) Each(block *Proc) (RubyObject, error) {
+	return Send(e.Receiver, e.Method, append(e.Args, block)...)
+}
+
+// ToA returns all yielded values. Multiple values yielded at once are
+// collected as Array.
+func (e *Enumerator) ToA() (*Array, error) {
+	values := NewArray()
+	_, err := e.Each(newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+		values.Elements = append(values.Elements, yieldedValue(args))
+		return NIL, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// yieldedValue returns the single value passed to a block or all values as
+// Array
+func yieldedValue(args []RubyObject) RubyObject {
+	switch len(args) {
+	case 0:
+		return NIL
+	case 1:
+		return args[0]
+	default:
+		return NewArray(args...)
+	}
+}
+
+var enumeratorClassMethods = map[string]RubyMethod{
+	"new": withArity(0, publicMethod(enumeratorNew)),
+}
+
+var enumeratorMethods = map[string]RubyMethod{
+	"each":       withArity(0, publicMethod(enumeratorEach)),
+	"to_a":       withArity(0, publicMethod(enumeratorToA)),
+	"entries":    withArity(0, publicMethod(enumeratorToA)),
+	"size":       withArity(0, publicMethod(enumeratorSize)),
+	"with_index": withArityRange(0, 1, publicMethod(enumeratorWithIndex)),
+	"next":       withArity(0, publicRuntimeMethod(enumeratorNext)),
+	"peek":       withArity(0, publicRuntimeMethod(enumeratorPeek)),
+	"rewind":     withArity(0, publicMethod(enumeratorRewind)),
+	"lazy":       withArity(0, publicMethod(enumerableLazy)),
+}
+
+func init() {
+	// an Enumerator supports the iteration methods of Array by collecting
+	// its values first
+	for _, name := range []string{
+		"map", "collect", "select", "filter", "reject", "reduce", "inject",
+		"each_with_index", "each_with_object", "each_slice", "each_cons",
+		"sort", "sort_by", "min", "max", "min_by", "max_by", "sum",
+		"include?", "first", "take", "drop", "zip", "uniq",
+	} {
+		enumeratorMethods[name] = publicMethod(enumeratorDelegate(name))
+	}
+}
+
+func enumeratorDelegate(name string) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		values, err := context.(*Enumerator).ToA()
+		if err != nil {
+			return nil, err
+		}
+		return Send(values, name, args...)
+	}
+}
+
+// enumeratorNew returns an Enumerator iterating the values the block passes
+// to the Yielder it is called with
+func enumeratorNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	return NewEnumerator(&Generator{block: block}, "each"), nil
+}
+
+func enumeratorEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return context, nil
+	}
+	return context.(*Enumerator).Each(block)
+}
+
+func enumeratorToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Enumerator).ToA()
+}
+
+func enumeratorSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	values, err := context.(*Enumerator).ToA()
+	if err != nil {
+		return nil, err
+	}
+	return NewInteger(int64(len(values.Elements))), nil
+}
+
+// enumeratorWithIndex iterates like each, passing every value together with
+// its index, starting at the optional offset, to the block
+func enumeratorWithIndex(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	enumerator := context.(*Enumerator)
+	args, block := extractBlock(args)
+	offset := int64(0)
+	if len(args) == 1 && args[0] != NIL {
+		start, err := integerArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		offset = start.Value
+	}
+	if block == nil {
+		return NewEnumerator(enumerator, "with_index", args...), nil
+	}
+	index := offset
+	return enumerator.Each(newNativeProc(func(values ...RubyObject) (RubyObject, error) {
+		result, err := block.Call(yieldedValue(values), NewInteger(index))
+		index++
+		return result, err
+	}))
+}
+
+// externalIteration runs the iteration of an Enumerator step by step for
+// next and peek. The iteration runs within a Fiber, which is resumed for
+// every value requested and yields it.
+type externalIteration struct {
+	fiber  *Fiber
+	peeked RubyObject
+	result RubyObject
+	err    error
+}
+
+// newExternalIteration returns an externalIteration of e running within r.
+// The Fiber is killed once the iteration is rewound or collected, so that
+// its goroutine does not outlive it.
+func newExternalIteration(r *Runtime, e *Enumerator) *externalIteration {
+	// the Fiber must not refer to e, which refers to the iteration, as it
+	// could not be collected otherwise
+	iterated := &Enumerator{Receiver: e.Receiver, Method: e.Method, Args: e.Args}
+	it := &externalIteration{
+		fiber: NewFiber(r, newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return iterated.Each(newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+				if _, err := FiberYield(r, yieldedValue(args)); err != nil {
+					return nil, err
+				}
+				return NIL, nil
+			}))
+		})),
+	}
+	r.abandonedFibers().collect()
+	runtime.SetFinalizer(it, func(it *externalIteration) {
+		r.abandonedFibers().add(it.fiber)
+	})
+	return it
+}
+
+// abandonedFibers holds the Fibers of collected external iterations. A
+// finalizer runs outside of the Threads and may not kill them itself, so
+// they are killed once the next external iteration starts.
+type abandonedFibers struct {
+	sync.Mutex
+	fibers []*Fiber
+}
+
+var sharedAbandonedFibers abandonedFibers
+
+func (r *Runtime) abandonedFibers() *abandonedFibers {
+	if r == nil {
+		return &sharedAbandonedFibers
+	}
+	return &r.abandoned
+}
+
+func (a *abandonedFibers) add(fiber *Fiber) {
+	a.Lock()
+	defer a.Unlock()
+	a.fibers = append(a.fibers, fiber)
+}
+
+// collect kills the abandoned Fibers
+func (a *abandonedFibers) collect() {
+	a.Lock()
+	fibers := a.fibers
+	a.fibers = nil
+	a.Unlock()
+	for _, fiber := range fibers {
+		fiber.kill()
+	}
+}
+
+// peek returns the next value without consuming it. A StopIteration
+// holding the result of the iteration method is returned at the end.
+func (it *externalIteration) peek() (RubyObject, error) {
+	if it.peeked != nil {
+		return it.peeked, nil
+	}
+	if it.fiber.Alive() {
+		value, err := it.fiber.Resume()
+		if it.fiber.Alive() {
+			it.peeked = value
+			return value, nil
+		}
+		it.result, it.err = value, err
+	}
+	if it.err != nil {
+		return nil, it.err
+	}
+	return nil, NewStopIteration(it.result)
+}
+
+// next returns the next value and advances the iteration
+func (it *externalIteration) next() (RubyObject, error) {
+	value, err := it.peek()
+	it.peeked = nil
+	return value, err
+}
+
+func (e *Enumerator) externalIteration(r *Runtime) *externalIteration {
+	if e.external == nil {
+		e.external = newExternalIteration(r, e)
+	}
+	return e.external
+}
+
+// enumeratorNext returns the next value of the iteration, raising a
+// StopIteration once it is exhausted
+func enumeratorNext(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Enumerator).externalIteration(r).next()
+}
+
+// enumeratorPeek returns the next value like next without advancing
+func enumeratorPeek(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Enumerator).externalIteration(r).peek()
+}
+
+// enumeratorRewind restarts the external iteration from the beginning
+func enumeratorRewind(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	enumerator := context.(*Enumerator)
+	if enumerator.external != nil {
+		enumerator.external.fiber.kill()
+		enumerator.external = nil
+	}
+	return context, nil
+}
+
+// A Generator is the receiver of an Enumerator created by Enumerator.new.
+// Its each method calls the block with a Yielder.
+type Generator struct {
+	block *Proc
+}
+
+// Type returns GENERATOR_OBJ
+func (g *Generator) Type() Type { return GENERATOR_OBJ }
+
+// Inspect returns the class name and object id of the Generator
+func (g *Generator) Inspect() string {
+	return fmt.Sprintf("#<Enumerator::Generator:0x%016x>", ObjectID(g))
+}
+
+// Class returns generatorClass
+func (g *Generator) Class() RubyClass { return generatorClass }
+
+var generatorMethods = map[string]RubyMethod{
+	"each": publicMethod(generatorEach),
+}
+
+func generatorEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return context, nil
+	}
+	return context.(*Generator).block.Call(&Yielder{block: block})
+}
+
+// A Yielder passes the values given to it on to the block of an iteration
+type Yielder struct {
+	block *Proc
+}
+
+// Type returns YIELDER_OBJ
+func (y *Yielder) Type() Type { return YIELDER_OBJ }
+
+// Inspect returns the class name and object id of the Yielder
+func (y *Yielder) Inspect() string {
+	return fmt.Sprintf("#<Enumerator::Yielder:0x%016x>", ObjectID(y))
+}
+
+// Class returns yielderClass
+func (y *Yielder) Class() RubyClass { return yielderClass }
+
+var yielderMethods = map[string]RubyMethod{
+	"<<":    withArity(1, publicMethod(yielderPush)),
+	"yield": publicMethod(yielderYield),
+	"call":  publicMethod(yielderYield),
+}
+
+// yielderPush passes its argument to the block and returns the Yielder
+func yielderPush(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := context.(*Yielder).block.Call(args[0]); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// yielderYield passes its arguments to the block and returns its result
+func yielderYield(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	return context.(*Yielder).block.Call(args...)
+}
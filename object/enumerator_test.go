@@ -0,0 +1,153 @@
+package object
+
+import "testing"
+
+func TestEnumeratorInspect(t *testing.T) {
+	tests := []struct {
+		enumerator *Enumerator
+		expected   string
+	}{
+		{NewEnumerator(NewInteger(3), "times"), "#<Enumerator: 3:times>"},
+		{NewEnumerator(NewInteger(1), "step", NewInteger(9), NewInteger(2)), "#<Enumerator: 1:step(9, 2)>"},
+	}
+
+	for _, tt := range tests {
+		if tt.enumerator.Inspect() != tt.expected {
+			t.Logf("Expected Inspect to return %q, got %q", tt.expected, tt.enumerator.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestEnumeratorMethods(t *testing.T) {
+	times := NewEnumerator(NewInteger(3), "times")
+	pairs := NewEnumerator(NewArray(NewInteger(5), NewInteger(6)), "each_with_index")
+	ints := NewArray(NewInteger(1), NewInteger(2), NewInteger(3))
+	slices := NewEnumerator(ints, "each_slice", NewInteger(2))
+	runs := NewEnumerator(ints, "each_cons", NewInteger(2))
+	longRuns := NewEnumerator(ints, "each_cons", NewInteger(4))
+	double := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return NewInteger(args[0].(*Integer).Value * 2), nil
+	}, "x")
+	weighted := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return NewInteger(args[0].(*Integer).Value * args[1].(*Integer).Value), nil
+	}, "x", "i")
+
+	tests := []struct {
+		name       string
+		enumerator *Enumerator
+		args       []RubyObject
+		expected   RubyObject
+	}{
+		{"to_a", times, nil, NewArray(NewInteger(0), NewInteger(1), NewInteger(2))},
+		{"to_a", pairs, nil, NewArray(NewArray(NewInteger(5), NewInteger(0)), NewArray(NewInteger(6), NewInteger(1)))},
+		{"to_a", slices, nil, NewArray(NewArray(NewInteger(1), NewInteger(2)), NewArray(NewInteger(3)))},
+		{"to_a", runs, nil, NewArray(NewArray(NewInteger(1), NewInteger(2)), NewArray(NewInteger(2), NewInteger(3)))},
+		{"to_a", longRuns, nil, NewArray()},
+		{"size", times, nil, NewInteger(3)},
+		{"map", pairs, []RubyObject{weighted}, NewArray(NewInteger(0), NewInteger(6))},
+		{"map", times, []RubyObject{double}, NewArray(NewInteger(0), NewInteger(2), NewInteger(4))},
+		{"include?", times, []RubyObject{NewInteger(2)}, TRUE},
+		{"with_index", times, []RubyObject{NewInteger(1), weighted}, times.Receiver},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(tt.enumerator, tt.name, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestEnumeratorNext(t *testing.T) {
+	enumerator := NewEnumerator(NewInteger(2), "times")
+
+	tests := []struct {
+		method   string
+		expected RubyObject
+	}{
+		{"peek", NewInteger(0)},
+		{"next", NewInteger(0)},
+		{"next", NewInteger(1)},
+		{"rewind", enumerator},
+		{"next", NewInteger(0)},
+		{"next", NewInteger(1)},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(enumerator, tt.method)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	_, err := Send(enumerator, "next")
+	checkError(t, err, NewStopIteration(NewInteger(2)))
+
+	_, err = Send(enumerator, "peek")
+	checkError(t, err, NewStopIteration(NewInteger(2)))
+}
+
+func TestEnumeratorNew(t *testing.T) {
+	generator := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		yielder, err := Send(args[0], "<<", NewInteger(1))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := Send(yielder, "yield", NewInteger(2)); err != nil {
+			return nil, err
+		}
+		return &Symbol{"done"}, nil
+	}, "y")
+
+	result, err := Send(enumeratorClass, "new", generator)
+	checkError(t, err, nil)
+	enumerator := result.(*Enumerator)
+
+	result, err = Send(enumerator, "to_a")
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray(NewInteger(1), NewInteger(2)))
+
+	for _, expected := range []RubyObject{NewInteger(1), NewInteger(2)} {
+		result, err = Send(enumerator, "next")
+		checkError(t, err, nil)
+		checkResult(t, result, expected)
+	}
+	_, err = Send(enumerator, "next")
+	checkError(t, err, NewStopIteration(&Symbol{"done"}))
+
+	_, err = Send(enumeratorClass, "new")
+	checkError(t, err, NewArgumentError("no block given"))
+}
+
+func TestEnumeratorExternalIterationEnds(t *testing.T) {
+	t.Run("rewind", func(t *testing.T) {
+		enumerator := NewEnumerator(NewInteger(3), "times")
+		_, err := Send(enumerator, "next")
+		checkError(t, err, nil)
+		fiber := enumerator.external.fiber
+
+		_, err = Send(enumerator, "rewind")
+		checkError(t, err, nil)
+
+		if status := fiber.status(); status != "terminated" {
+			t.Logf("Expected rewind to end the iteration, got fiber %s", status)
+			t.Fail()
+		}
+	})
+	t.Run("abandoned", func(t *testing.T) {
+		enumerator := NewEnumerator(NewInteger(3), "times")
+		_, err := Send(enumerator, "next")
+		checkError(t, err, nil)
+		fiber := enumerator.external.fiber
+		// what the finalizer does once the iteration is collected
+		(*Runtime)(nil).abandonedFibers().add(fiber)
+
+		_, err = Send(NewEnumerator(NewInteger(3), "times"), "next")
+		checkError(t, err, nil)
+
+		if status := fiber.status(); status != "terminated" {
+			t.Logf("Expected the next iteration to end abandoned ones, got fiber %s", status)
+			t.Fail()
+		}
+	})
+}
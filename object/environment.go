@@ -1,13 +1,32 @@
 package object
 
+import (
+	"sync"
+
+	"github.com/goruby/goruby/ast"
+)
+
 var classes = NewEnvironment()
 
-// NewMainEnvironment returns a new Environment populated with all Ruby classes
-// and the Kernel functions
+// NewMainEnvironment returns the root Environment of a new Runtime, in
+// which all Ruby classes and the Kernel functions are visible. Every
+// Runtime has its own main object, global variables and constants, so that
+// programs run within different runtimes, e.g. by different interpreters,
+// do not interfere.
 func NewMainEnvironment() Environment {
-	env := kernelFunctions
-	env.Set("self", &Self{&Object{}})
+	env := &runtimeEnvironment{store: make(map[string]RubyObject), runtime: newRuntime()}
+	env.Set("self", &Self{&mainObject{&Object{}}})
 	env.SetGlobal("$LOADED_FEATURES", NewArray())
+	env.SetGlobal("$LOAD_PATH", NewArray())
+	env.SetGlobal("$!", NIL)
+	env.SetGlobal("$ERROR_INFO", NIL)
+	env.SetGlobal("$@", NIL)
+	env.SetGlobal("$?", NIL)
+	if _, ok := env.Get("$VERBOSE"); !ok {
+		SetWarningLevel(1)
+	}
+	// failing extensions raise their error once they are required
+	_ = InitExtensions()
 	return env
 }
 
@@ -19,12 +38,31 @@ func NewEnclosedEnvironment(outer Environment) Environment {
 	return env
 }
 
+// NewSlotEnvironment returns an Environment wrapped by outer, which stores
+// the local variables of scope by their index
+func NewSlotEnvironment(outer Environment, scope *ast.Scope) SlotEnvironment {
+	return &slotEnvironment{
+		scope: scope,
+		slots: make([]RubyObject, len(scope.Names)),
+		outer: outer,
+	}
+}
+
 // NewEnvironment returns a new Environment ready to use
 func NewEnvironment() Environment {
 	s := make(map[string]RubyObject)
 	return &environment{store: s, outer: nil}
 }
 
+// newBlockEnvironment returns an Environment for the evaluation of a block
+// wrapped by outer. Unlike an enclosed environment it shares the variables of
+// outer, i.e. setting a variable which already exists within outer sets it
+// there.
+func newBlockEnvironment(outer Environment) *blockEnvironment {
+	s := make(map[string]RubyObject)
+	return &blockEnvironment{&environment{store: s, outer: outer}}
+}
+
 // Environment holds Ruby object referenced by strings
 type Environment interface {
 	// Get returns the RubyObject found for this key. If it is not found,
@@ -39,6 +77,19 @@ type Environment interface {
 	Outer() Environment
 }
 
+// A SlotEnvironment is an Environment storing the local variables of a
+// scope by index. Variables not within the scope are stored by name.
+type SlotEnvironment interface {
+	Environment
+	// Scope returns the scope the slots belong to
+	Scope() *ast.Scope
+	// Slot returns the value of the local variable at index, or nil if it
+	// is not set
+	Slot(index int) RubyObject
+	// SetSlot sets the local variable at index to val
+	SetSlot(index int, val RubyObject)
+}
+
 type environment struct {
 	store map[string]RubyObject
 	outer Environment
@@ -83,6 +134,15 @@ func (e *environment) Enclose(outer Environment) Environment {
 	return env
 }
 
+// names returns the names of all objects stored directly within e
+func (e *environment) names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	return names
+}
+
 func (e *environment) clone() *environment {
 	s := make(map[string]RubyObject)
 	env := &environment{store: s, outer: nil}
@@ -91,3 +151,155 @@ func (e *environment) clone() *environment {
 	}
 	return env
 }
+
+type blockEnvironment struct {
+	*environment
+}
+
+// Set sets the RubyObject for the given key. If the key does not exist
+// within the block but within the outer environment it is set there.
+func (b *blockEnvironment) Set(name string, val RubyObject) RubyObject {
+	if _, ok := b.store[name]; !ok {
+		if _, ok := b.outer.Get(name); ok {
+			return b.outer.Set(name, val)
+		}
+	}
+	b.store[name] = val
+	return val
+}
+
+type slotEnvironment struct {
+	scope *ast.Scope
+	slots []RubyObject
+	store map[string]RubyObject // variables not within scope, if any
+	outer Environment
+}
+
+func (s *slotEnvironment) Scope() *ast.Scope                 { return s.scope }
+func (s *slotEnvironment) Slot(index int) RubyObject         { return s.slots[index] }
+func (s *slotEnvironment) SetSlot(index int, val RubyObject) { s.slots[index] = val }
+
+// Get returns the RubyObject found for this key. If it is not found,
+// ok  will be false
+func (s *slotEnvironment) Get(name string) (RubyObject, bool) {
+	if index, ok := s.scope.Index(name); ok {
+		if val := s.slots[index]; val != nil {
+			return val, true
+		}
+	} else if val, ok := s.store[name]; ok {
+		return val, true
+	}
+	if s.outer != nil {
+		return s.outer.Get(name)
+	}
+	return nil, false
+}
+
+// Set sets the RubyObject for the given key. If there is already an
+// object with that key it will be overridden by object
+func (s *slotEnvironment) Set(name string, val RubyObject) RubyObject {
+	if index, ok := s.scope.Index(name); ok {
+		s.slots[index] = val
+		return val
+	}
+	if s.store == nil {
+		s.store = make(map[string]RubyObject)
+	}
+	s.store[name] = val
+	return val
+}
+
+// SetGlobal sets val under name at the root of the environment
+func (s *slotEnvironment) SetGlobal(name string, val RubyObject) RubyObject {
+	var env Environment = s
+	for env.Outer() != nil {
+		env = env.Outer()
+	}
+	env.Set(name, val)
+	return val
+}
+
+// Outer returns the parent environment
+func (s *slotEnvironment) Outer() Environment {
+	return s.outer
+}
+
+// runtimeEnvironment is the root Environment of a Runtime. It holds the
+// global variables and falls back to the constants of the Runtime for all
+// other names.
+type runtimeEnvironment struct {
+	store   map[string]RubyObject
+	runtime *Runtime
+}
+
+// sharedGlobals are the global variables configuring builtin methods, which
+// are shared by all runtimes as builtin methods have no access to them
+var sharedGlobals = map[string]bool{"$VERBOSE": true}
+
+func (r *runtimeEnvironment) Get(name string) (RubyObject, bool) {
+	if obj, ok := r.store[name]; ok {
+		return obj, true
+	}
+	return r.runtime.constants.Get(name)
+}
+
+func (r *runtimeEnvironment) Set(name string, val RubyObject) RubyObject {
+	if sharedGlobals[name] {
+		return kernelFunctions.Set(name, val)
+	}
+	r.store[name] = val
+	return val
+}
+
+func (r *runtimeEnvironment) SetGlobal(name string, val RubyObject) RubyObject {
+	return r.Set(name, val)
+}
+
+// Outer returns nil, as the runtime is the root of all environments of its
+// programs
+func (r *runtimeEnvironment) Outer() Environment { return nil }
+
+// sharedEnvironment is an Environment safe for concurrent use
+type sharedEnvironment struct {
+	sync.RWMutex
+	store map[string]RubyObject
+	outer Environment
+}
+
+func newSharedEnvironment(outer Environment) *sharedEnvironment {
+	return &sharedEnvironment{store: make(map[string]RubyObject), outer: outer}
+}
+
+func (s *sharedEnvironment) Get(name string) (RubyObject, bool) {
+	s.RLock()
+	obj, ok := s.store[name]
+	s.RUnlock()
+	if !ok && s.outer != nil {
+		return s.outer.Get(name)
+	}
+	return obj, ok
+}
+
+func (s *sharedEnvironment) Set(name string, val RubyObject) RubyObject {
+	s.Lock()
+	defer s.Unlock()
+	s.store[name] = val
+	return val
+}
+
+func (s *sharedEnvironment) SetGlobal(name string, val RubyObject) RubyObject {
+	return s.Set(name, val)
+}
+
+func (s *sharedEnvironment) Outer() Environment { return s.outer }
+
+// names returns the names of all objects stored directly within s
+func (s *sharedEnvironment) names() []string {
+	s.RLock()
+	defer s.RUnlock()
+	names := make([]string, 0, len(s.store))
+	for name := range s.store {
+		names = append(names, name)
+	}
+	return names
+}
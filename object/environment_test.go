@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+)
 
 func TestEnvironmentSet(t *testing.T) {
 	env := &environment{store: make(map[string]RubyObject)}
@@ -154,3 +158,66 @@ func TestEnvironmentGet(t *testing.T) {
 		}
 	})
 }
+
+func TestSlotEnvironment(t *testing.T) {
+	outer := &environment{store: map[string]RubyObject{"bar": TRUE, "qux": TRUE}}
+	scope := ast.NewScope("foo", "bar")
+	env := NewSlotEnvironment(outer, scope)
+
+	t.Run("unset slots fall back to outer", func(t *testing.T) {
+		if _, ok := env.Get("foo"); ok {
+			t.Logf("Expected foo not to be found")
+			t.Fail()
+		}
+		if val, ok := env.Get("bar"); !ok || val != TRUE {
+			t.Logf("Expected bar to equal TRUE, got %v", val)
+			t.Fail()
+		}
+	})
+
+	t.Run("variables within scope are stored in slots", func(t *testing.T) {
+		env.Set("bar", FALSE)
+
+		if env.Slot(1) != FALSE {
+			t.Logf("Expected slot 1 to equal FALSE, got %v", env.Slot(1))
+			t.Fail()
+		}
+		if val, _ := env.Get("bar"); val != FALSE {
+			t.Logf("Expected bar to equal FALSE, got %v", val)
+			t.Fail()
+		}
+		if outer.store["bar"] != TRUE {
+			t.Logf("Expected outer bar to be untouched")
+			t.Fail()
+		}
+
+		env.SetSlot(0, NIL)
+
+		if val, ok := env.Get("foo"); !ok || val != NIL {
+			t.Logf("Expected foo to equal NIL, got %v", val)
+			t.Fail()
+		}
+	})
+
+	t.Run("other variables are stored by name", func(t *testing.T) {
+		env.Set("qux", FALSE)
+
+		if val, _ := env.Get("qux"); val != FALSE {
+			t.Logf("Expected qux to equal FALSE, got %v", val)
+			t.Fail()
+		}
+		if outer.store["qux"] != TRUE {
+			t.Logf("Expected outer qux to be untouched")
+			t.Fail()
+		}
+	})
+
+	t.Run("globals are set at the root", func(t *testing.T) {
+		env.SetGlobal("$foo", TRUE)
+
+		if outer.store["$foo"] != TRUE {
+			t.Logf("Expected $foo to be set within the outer env")
+			t.Fail()
+		}
+	})
+}
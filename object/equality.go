@@ -0,0 +1,81 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// kernelHashMethod is the default implementation of `hash`. Objects whose
+// `hash` method differs from it are stored in a Hash by their hash value
+// and compared by `eql?` rather than by identity.
+var kernelHashMethod = withArity(0, publicMethod(kernelHash))
+
+// customHashKey is the hashKey value of objects with their own `hash`
+// method. Slot distinguishes objects whose hash values collide but which are
+// not `eql?`.
+type customHashKey struct {
+	hash int64
+	slot int
+}
+
+// hashValue returns the default hash value of obj. Objects equal by value
+// share the same hash, all others are hashed by their identity.
+func hashValue(obj RubyObject) int64 {
+	h, ok := obj.(hashable)
+	if !ok {
+		return ObjectID(obj)
+	}
+	key := h.hashKey()
+	hasher := fnv.New64a()
+	fmt.Fprintf(hasher, "%s:%v", key.Type, key.Value)
+	return int64(hasher.Sum64())
+}
+
+// customHash returns the result of the `hash` method of obj if it does not
+// use the default implementation. ok is false otherwise, or if the method
+// did not return an Integer.
+func customHash(obj RubyObject) (int64, bool) {
+	if _, ok := obj.(hashable); ok {
+		return 0, false
+	}
+	method, ok := findMethod(obj, "hash")
+	if !ok || method == kernelHashMethod {
+		return 0, false
+	}
+	result, err := method.Call(obj)
+	if err != nil {
+		return 0, false
+	}
+	hash, ok := result.(*Integer)
+	if !ok {
+		return 0, false
+	}
+	return hash.Value, true
+}
+
+// sendHash returns the hash value of obj by sending `hash` to it
+func sendHash(obj RubyObject) (int64, error) {
+	result, err := Send(obj, "hash")
+	if err != nil {
+		return 0, err
+	}
+	hash, ok := result.(*Integer)
+	if !ok {
+		return 0, NewImplicitConversionTypeError(&Integer{}, result)
+	}
+	return hash.Value, nil
+}
+
+// isEql reports whether a and b are equal as Hash keys by sending `eql?` to
+// a
+func isEql(a, b RubyObject) (bool, error) {
+	result, err := Send(a, "eql?", b)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(result), nil
+}
+
+func kernelHash(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(hashValue(context)), nil
+}
@@ -0,0 +1,156 @@
+package object
+
+import "testing"
+
+// testKey is a Hash key which is eql? to any other testKey with the same
+// group, independent of its name
+type testKey struct {
+	group int64
+	name  string
+}
+
+var testKeyClass = newClass("TestKey", objectClass, map[string]RubyMethod{
+	"hash": withArity(0, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NewInteger(context.(*testKey).group), nil
+	})),
+	"eql?": withArity(1, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		other, ok := args[0].(*testKey)
+		return nativeBoolToBoolean(ok && other.group == context.(*testKey).group), nil
+	})),
+}, nil)
+
+func (k *testKey) Type() Type       { return Type("TEST_KEY") }
+func (k *testKey) Inspect() string  { return k.name }
+func (k *testKey) Class() RubyClass { return testKeyClass }
+
+func TestKernelHash(t *testing.T) {
+	obj := &IO{}
+	tests := []struct {
+		left  RubyObject
+		right RubyObject
+		equal bool
+	}{
+		{obj, obj, true},
+		{obj, &IO{}, false},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&String{Value: "a"}, &Symbol{Value: "a"}, false},
+		{NewInteger(1), NewInteger(1), true},
+		{NewInteger(1), NewFloat(1), false},
+	}
+
+	for _, tt := range tests {
+		eql, err := Send(tt.left, "eql?", tt.right)
+		checkError(t, err, nil)
+		checkResult(t, eql, nativeBoolToBoolean(tt.equal))
+
+		left, err := Send(tt.left, "hash")
+		checkError(t, err, nil)
+		right, err := Send(tt.right, "hash")
+		checkError(t, err, nil)
+		if sameHash := left.(*Integer).Value == right.(*Integer).Value; sameHash != tt.equal {
+			t.Logf("Expected hash of %s and %s to be equal: %t, got %t", tt.left.Inspect(), tt.right.Inspect(), tt.equal, sameHash)
+			t.Fail()
+		}
+	}
+}
+
+func TestArrayAndHashEquality(t *testing.T) {
+	ints := func(values ...int64) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, NewInteger(v))
+		}
+		return array
+	}
+	hash := func(pairs ...RubyObject) *Hash {
+		h := &Hash{}
+		for i := 0; i < len(pairs); i += 2 {
+			h.Set(pairs[i], pairs[i+1])
+		}
+		return h
+	}
+	a, b := &Symbol{Value: "a"}, &Symbol{Value: "b"}
+
+	tests := []struct {
+		name     string
+		left     RubyObject
+		method   string
+		right    RubyObject
+		expected RubyObject
+	}{
+		{"Array#eql?", ints(1, 2), "eql?", ints(1, 2), TRUE},
+		{"Array#eql? with Float", ints(1, 2), "eql?", NewArray(NewInteger(1), NewFloat(2)), FALSE},
+		{"Hash#==", hash(a, NewInteger(1), b, NewInteger(2)), "==", hash(b, NewInteger(2), a, NewInteger(1)), TRUE},
+		{"Hash#== with different value", hash(a, NewInteger(1)), "==", hash(a, NewInteger(2)), FALSE},
+		{"Hash#== with different key", hash(a, NewInteger(1)), "==", hash(b, NewInteger(1)), FALSE},
+		{"Hash#eql?", hash(a, ints(1)), "eql?", hash(a, ints(1)), TRUE},
+		{"Hash#== with non hash", hash(), "==", NIL, FALSE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Send(tt.left, tt.method, tt.right)
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	left, err := Send(hash(a, ints(1, 2), b, NIL), "hash")
+	checkError(t, err, nil)
+	right, err := Send(hash(b, NIL, a, ints(1, 2)), "hash")
+	checkError(t, err, nil)
+	checkResult(t, left, right)
+}
+
+func TestHashWithArrayKeys(t *testing.T) {
+	hash := &Hash{}
+	hash.Set(NewArray(NewInteger(1), &String{Value: "a"}), TRUE)
+
+	value, ok := hash.Get(NewArray(NewInteger(1), &String{Value: "a"}))
+	if !ok {
+		t.Logf("Expected Array key to be found by value")
+		t.FailNow()
+	}
+	checkResult(t, value, TRUE)
+
+	if _, ok := hash.Get(NewArray(NewInteger(1))); ok {
+		t.Logf("Expected different Array key not to be found")
+		t.Fail()
+	}
+
+	result, err := arrayUniq(NewArray(NewArray(NewInteger(1)), NewArray(NewInteger(1)), NewArray(NewInteger(2))))
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray(NewArray(NewInteger(1)), NewArray(NewInteger(2))))
+}
+
+func TestHashWithCustomKeys(t *testing.T) {
+	hash := &Hash{}
+	hash.Set(&testKey{1, "a"}, NewInteger(1))
+	hash.Set(&testKey{2, "b"}, NewInteger(2))
+	hash.Set(&testKey{1, "c"}, NewInteger(3))
+
+	checkResult(t, NewInteger(int64(hash.Len())), NewInteger(2))
+	if hash.Inspect() != "{a=>3, b=>2}" {
+		t.Logf("Expected eql? keys to be replaced, got %s", hash.Inspect())
+		t.Fail()
+	}
+
+	value, ok := hash.Get(&testKey{2, "d"})
+	if !ok {
+		t.Logf("Expected eql? key to be found")
+		t.FailNow()
+	}
+	checkResult(t, value, NewInteger(2))
+
+	if _, ok := hash.Get(&testKey{3, "a"}); ok {
+		t.Logf("Expected key which is not eql? not to be found")
+		t.Fail()
+	}
+
+	result, err := arrayUniq(NewArray(&testKey{1, "a"}, &testKey{2, "b"}, &testKey{1, "c"}))
+	checkError(t, err, nil)
+	if result.Inspect() != "[a, b]" {
+		t.Logf("Expected uniq to use hash and eql?, got %s", result.Inspect())
+		t.Fail()
+	}
+}
@@ -0,0 +1,174 @@
+package object
+
+import (
+	"strings"
+)
+
+var erbClass RubyClassObject = newClass("ERB", objectClass, erbMethods, erbClassMethods)
+
+func init() {
+	classes.Set("ERB", erbClass)
+}
+
+// NewERB returns an ERB for template. With trimMode `-`, the tags `<%-`
+// and `-%>` remove the indentation before and the newline after them,
+// with `>` all tags remove the newline after them.
+func NewERB(template, trimMode string) (*ERB, error) {
+	src, err := compileERB(template, trimMode)
+	if err != nil {
+		return nil, err
+	}
+	return &ERB{Template: template, Src: src}, nil
+}
+
+// ERB represents a template mixing text with Ruby code in `<% %>` tags and
+// the values of expressions in `<%= %>` tags
+type ERB struct {
+	Template string
+	// Src is the Ruby code the template has been compiled to, which
+	// returns the rendered template
+	Src string
+}
+
+// Type returns ERB_OBJ
+func (e *ERB) Type() Type { return ERB_OBJ }
+
+// Inspect returns the class name
+func (e *ERB) Inspect() string { return "#<ERB>" }
+
+// Class returns erbClass
+func (e *ERB) Class() RubyClass { return erbClass }
+
+var erbClassMethods = map[string]RubyMethod{
+	"new": withArityRange(1, 2, publicMethod(erbNew)),
+}
+
+var erbMethods = map[string]RubyMethod{
+	"src":    withArity(0, publicMethod(erbSrc)),
+	"result": withArity(1, publicMethod(erbResult)),
+}
+
+// erbNew compiles the template passed, accepting the option trim_mode
+func erbNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	template, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	options, err := jsonOptions(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	var trimMode string
+	if mode, ok := options.Get(NewSymbol("trim_mode")); ok && mode != NIL {
+		str, ok := mode.(*String)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(&String{}, mode)
+		}
+		trimMode = str.Value
+	}
+	return NewERB(template.Value, trimMode)
+}
+
+func erbSrc(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*ERB).Src}, nil
+}
+
+// erbResult renders the template by evaluating its code within the Binding
+// passed, so that it can refer to the local variables and methods there
+func erbResult(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	binding, ok := args[0].(*Binding)
+	if !ok {
+		return nil, NewTypeError("wrong argument type %s (expected binding)", className(args[0]))
+	}
+	return binding.Eval(context.(*ERB).Src)
+}
+
+// erbBuffer is the local variable the compiled code appends the rendered
+// template to
+const erbBuffer = "_erbout"
+
+// compileERB translates template into Ruby code appending its text and the
+// values of its expressions to erbBuffer and returning it
+func compileERB(template, trimMode string) (string, error) {
+	var src strings.Builder
+	src.WriteString(erbBuffer + " = \"\"\n")
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() > 0 {
+			src.WriteString(erbBuffer + " << " + erbStringLiteral(text.String()) + "\n")
+			text.Reset()
+		}
+	}
+	rest := template
+	for {
+		start := strings.Index(rest, "<%")
+		if start < 0 {
+			text.WriteString(rest)
+			break
+		}
+		text.WriteString(rest[:start])
+		rest = rest[start+2:]
+		if strings.HasPrefix(rest, "%") {
+			text.WriteString("<%")
+			rest = rest[1:]
+			continue
+		}
+		end := strings.Index(rest, "%>")
+		if end < 0 {
+			return "", NewSyntaxError("unterminated ERB tag")
+		}
+		tag := rest[:end]
+		rest = rest[end+2:]
+		trimNewline := trimMode == ">"
+		if trimMode == "-" {
+			if strings.HasPrefix(tag, "-") {
+				tag = tag[1:]
+				erbTrimIndentation(&text)
+			}
+			if strings.HasSuffix(tag, "-") {
+				tag = tag[:len(tag)-1]
+				trimNewline = true
+			}
+		}
+		if trimNewline {
+			if strings.HasPrefix(rest, "\r\n") {
+				rest = rest[2:]
+			} else if strings.HasPrefix(rest, "\n") {
+				rest = rest[1:]
+			}
+		}
+		switch {
+		case strings.HasPrefix(tag, "#"):
+		case strings.HasPrefix(tag, "="):
+			flushText()
+			src.WriteString(erbBuffer + " << ((" + strings.TrimSpace(tag[1:]) + ").to_s)\n")
+		default:
+			flushText()
+			src.WriteString(strings.TrimSpace(tag) + "\n")
+		}
+	}
+	flushText()
+	src.WriteString(erbBuffer)
+	return src.String(), nil
+}
+
+// erbTrimIndentation removes the spaces and tabs at the end of text if
+// they are all the last line consists of
+func erbTrimIndentation(text *strings.Builder) {
+	s := text.String()
+	trimmed := strings.TrimRight(s, " \t")
+	if trimmed != "" && !strings.HasSuffix(trimmed, "\n") {
+		return
+	}
+	text.Reset()
+	text.WriteString(trimmed)
+}
+
+var erbEscapes = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`, "#", `\#`,
+)
+
+// erbStringLiteral returns s as double quoted Ruby string literal
+func erbStringLiteral(s string) string {
+	return `"` + erbEscapes.Replace(s) + `"`
+}
@@ -0,0 +1,32 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestCompileERB(t *testing.T) {
+	tests := []struct {
+		template string
+		trimMode string
+		expected string
+	}{
+		{"plain \"text\"\n", "", "_erbout = \"\"\n_erbout << \"plain \\\"text\\\"\\n\"\n_erbout"},
+		{"<%= x %>#<% y %>", "", "_erbout = \"\"\n_erbout << ((x).to_s)\n_erbout << \"\\#\"\ny\n_erbout"},
+		{"<%# comment %><%% x", "", "_erbout = \"\"\n_erbout << \"<% x\"\n_erbout"},
+		{"a\n  <%- x -%>\nb", "-", "_erbout = \"\"\n_erbout << \"a\\n\"\nx\n_erbout << \"b\"\n_erbout"},
+		{"<% x %>\nb", ">", "_erbout = \"\"\nx\n_erbout << \"b\"\n_erbout"},
+	}
+
+	for _, tt := range tests {
+		src, err := compileERB(tt.template, tt.trimMode)
+
+		checkError(t, err, nil)
+		if src != tt.expected {
+			t.Logf("Expected %q to compile to %q, got %q", tt.template, tt.expected, src)
+			t.Fail()
+		}
+	}
+
+	_, err := compileERB("<%= x", "")
+	checkError(t, err, NewSyntaxError("unterminated ERB tag"))
+}
@@ -0,0 +1,97 @@
+package object
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+)
+
+var errnoModule = newModule("Errno", nil)
+
+// errnoClasses are the subclasses of SystemCallError within Errno by the
+// error numbers they represent
+var errnoClasses = map[syscall.Errno]RubyClassObject{}
+
+func init() {
+	classes.Set("Errno", errnoModule)
+	for name, errno := range map[string]syscall.Errno{
+		"EPERM":        syscall.EPERM,
+		"ENOENT":       syscall.ENOENT,
+		"EIO":          syscall.EIO,
+		"EBADF":        syscall.EBADF,
+		"EACCES":       syscall.EACCES,
+		"EBUSY":        syscall.EBUSY,
+		"EEXIST":       syscall.EEXIST,
+		"EXDEV":        syscall.EXDEV,
+		"ENOTDIR":      syscall.ENOTDIR,
+		"EISDIR":       syscall.EISDIR,
+		"EINVAL":       syscall.EINVAL,
+		"ENOSPC":       syscall.ENOSPC,
+		"EROFS":        syscall.EROFS,
+		"EPIPE":        syscall.EPIPE,
+		"ENOTEMPTY":    syscall.ENOTEMPTY,
+		"ECONNREFUSED": syscall.ECONNREFUSED,
+		"ECONNRESET":   syscall.ECONNRESET,
+		"ETIMEDOUT":    syscall.ETIMEDOUT,
+	} {
+		class := newSubclass("Errno::"+name, systemCallErrorClass)
+		setConstant(class, "Errno", NewInteger(int64(errno)))
+		setConstant(errnoModule, name, class)
+		errnoClasses[errno] = class
+	}
+}
+
+// NewSystemCallError returns the exception for a failing system call, which
+// is an instance of the Errno class of the error number if err wraps one,
+// like Errno::ENOENT for a missing file. The message names the operation and
+// the path of *os.PathErrors, like
+// `No such file or directory @ open - missing.txt`.
+func NewSystemCallError(err error) *SystemCallError {
+	class := systemCallErrorClass
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if errnoClass, ok := errnoClasses[errno]; ok {
+			class = errnoClass
+		}
+	}
+	message := err.Error()
+	var pathErr *os.PathError
+	var linkErr *os.LinkError
+	switch {
+	case errors.As(err, &pathErr):
+		message = capitalize(pathErr.Err.Error()) + " @ " + pathErr.Op + " - " + pathErr.Path
+	case errors.As(err, &linkErr):
+		message = capitalize(linkErr.Err.Error()) + " @ " + linkErr.Op + " - (" + linkErr.Old + ", " + linkErr.New + ")"
+	case errno != 0:
+		message = capitalize(errno.Error())
+	}
+	return &SystemCallError{exception: &exception{Message: message}, class: class, Errno: errno}
+}
+
+// capitalize returns s with its first letter in upper case, like the
+// messages of Ruby's system call errors
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// SystemCallError represents a failing call of the operating system, like
+// opening a missing file
+type SystemCallError struct {
+	*exception
+	class RubyClassObject
+	// Errno is the error number, or 0 if unknown
+	Errno syscall.Errno
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *SystemCallError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *SystemCallError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns the Errno class of the error number, or SystemCallError
+func (e *SystemCallError) Class() RubyClass { return e.class }
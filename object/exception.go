@@ -6,17 +6,50 @@ import (
 )
 
 var (
-	exceptionClass           RubyClassObject = newClass("Exception", objectClass, exceptionMethods, exceptionClassMethods)
-	standardErrorClass       RubyClassObject = newClass("StandardError", exceptionClass, nil, nil)
-	zeroDivisionErrorClass   RubyClassObject = newClass("ZeroDivisionError", standardErrorClass, nil, nil)
-	argumentErrorClass       RubyClassObject = newClass("ArgumentError", standardErrorClass, nil, nil)
-	nameErrorClass           RubyClassObject = newClass("NameError", standardErrorClass, nil, nil)
-	noMethodErrorClass       RubyClassObject = newClass("NoMethodError", nameErrorClass, nil, nil)
-	typeErrorClass           RubyClassObject = newClass("TypeError", standardErrorClass, nil, nil)
-	scriptErrorClass         RubyClassObject = newClass("ScriptError", exceptionClass, nil, nil)
-	loadErrorClass           RubyClassObject = newClass("LoadError", scriptErrorClass, nil, nil)
-	syntaxErrorClass         RubyClassObject = newClass("SyntaxError", scriptErrorClass, nil, nil)
-	notImplementedErrorClass RubyClassObject = newClass("NotImplementedError", scriptErrorClass, nil, nil)
+	exceptionClass           RubyClassObject = newExceptionClass()
+	standardErrorClass       RubyClassObject = newSubclass("StandardError", exceptionClass)
+	zeroDivisionErrorClass   RubyClassObject = newSubclass("ZeroDivisionError", standardErrorClass)
+	argumentErrorClass       RubyClassObject = newSubclass("ArgumentError", standardErrorClass)
+	nameErrorClass           RubyClassObject = newSubclass("NameError", standardErrorClass)
+	noMethodErrorClass       RubyClassObject = newSubclass("NoMethodError", nameErrorClass)
+	typeErrorClass           RubyClassObject = newSubclass("TypeError", standardErrorClass)
+	runtimeErrorClass        RubyClassObject = newSubclass("RuntimeError", standardErrorClass)
+	indexErrorClass          RubyClassObject = newSubclass("IndexError", standardErrorClass)
+	keyErrorClass            RubyClassObject = newSubclass("KeyError", indexErrorClass)
+	rangeErrorClass          RubyClassObject = newSubclass("RangeError", standardErrorClass)
+	regexpErrorClass         RubyClassObject = newSubclass("RegexpError", standardErrorClass)
+	frozenErrorClass         RubyClassObject = newSubclass("FrozenError", runtimeErrorClass)
+	uncaughtThrowErrorClass  RubyClassObject = newSubclass("UncaughtThrowError", argumentErrorClass)
+	scriptErrorClass         RubyClassObject = newSubclass("ScriptError", exceptionClass)
+	loadErrorClass           RubyClassObject = newSubclass("LoadError", scriptErrorClass)
+	syntaxErrorClass         RubyClassObject = newSubclass("SyntaxError", scriptErrorClass)
+	notImplementedErrorClass RubyClassObject = newSubclass("NotImplementedError", scriptErrorClass)
+	signalExceptionClass     RubyClassObject = newSubclass("SignalException", exceptionClass)
+	interruptClass           RubyClassObject = newSubclass("Interrupt", signalExceptionClass)
+	systemExitClass          RubyClassObject = newSubclass("SystemExit", exceptionClass)
+	noMemoryErrorClass       RubyClassObject = newSubclass("NoMemoryError", exceptionClass)
+	securityErrorClass       RubyClassObject = newSubclass("SecurityError", exceptionClass)
+	systemStackErrorClass    RubyClassObject = newSubclass("SystemStackError", exceptionClass)
+	resourceLimitErrorClass  RubyClassObject = newSubclass("ResourceLimitError", exceptionClass)
+	ioErrorClass             RubyClassObject = newSubclass("IOError", standardErrorClass)
+	eofErrorClass            RubyClassObject = newSubclass("EOFError", ioErrorClass)
+	stopIterationClass       RubyClassObject = newSubclass("StopIteration", indexErrorClass)
+	closedQueueErrorClass    RubyClassObject = newSubclass("ClosedQueueError", stopIterationClass)
+	floatDomainErrorClass    RubyClassObject = newSubclass("FloatDomainError", rangeErrorClass)
+	localJumpErrorClass      RubyClassObject = newSubclass("LocalJumpError", standardErrorClass)
+	encodingErrorClass       RubyClassObject = newSubclass("EncodingError", standardErrorClass)
+	fiberErrorClass          RubyClassObject = newSubclass("FiberError", standardErrorClass)
+	threadErrorClass         RubyClassObject = newSubclass("ThreadError", standardErrorClass)
+	systemCallErrorClass     RubyClassObject = newSubclass("SystemCallError", standardErrorClass)
+	jsonErrorClass           RubyClassObject = newSubclass("JSON::JSONError", standardErrorClass)
+	jsonParserErrorClass     RubyClassObject = newSubclass("JSON::ParserError", jsonErrorClass)
+	jsonGeneratorErrorClass  RubyClassObject = newSubclass("JSON::GeneratorError", jsonErrorClass)
+	psychExceptionClass      RubyClassObject = newSubclass("Psych::Exception", runtimeErrorClass)
+	psychSyntaxErrorClass    RubyClassObject = newSubclass("Psych::SyntaxError", psychExceptionClass)
+	timeoutErrorClass        RubyClassObject = newSubclass("Timeout::Error", runtimeErrorClass)
+	netOpenTimeoutClass      RubyClassObject = newSubclass("Net::OpenTimeout", timeoutErrorClass)
+	netReadTimeoutClass      RubyClassObject = newSubclass("Net::ReadTimeout", timeoutErrorClass)
+	socketErrorClass         RubyClassObject = newSubclass("SocketError", standardErrorClass)
 )
 
 func init() {
@@ -27,25 +60,79 @@ func init() {
 	classes.Set("NameError", nameErrorClass)
 	classes.Set("NoMethodError", noMethodErrorClass)
 	classes.Set("TypeError", typeErrorClass)
+	classes.Set("RuntimeError", runtimeErrorClass)
+	classes.Set("IndexError", indexErrorClass)
+	classes.Set("KeyError", keyErrorClass)
+	classes.Set("RangeError", rangeErrorClass)
+	classes.Set("RegexpError", regexpErrorClass)
+	classes.Set("FrozenError", frozenErrorClass)
+	classes.Set("UncaughtThrowError", uncaughtThrowErrorClass)
 	classes.Set("ScriptError", scriptErrorClass)
 	classes.Set("LoadError", loadErrorClass)
 	classes.Set("SyntaxError", syntaxErrorClass)
 	classes.Set("NotImplementedError", notImplementedErrorClass)
+	classes.Set("SignalException", signalExceptionClass)
+	classes.Set("Interrupt", interruptClass)
+	classes.Set("SystemExit", systemExitClass)
+	classes.Set("NoMemoryError", noMemoryErrorClass)
+	classes.Set("SecurityError", securityErrorClass)
+	classes.Set("SystemStackError", systemStackErrorClass)
+	classes.Set("ResourceLimitError", resourceLimitErrorClass)
+	classes.Set("IOError", ioErrorClass)
+	classes.Set("EOFError", eofErrorClass)
+	classes.Set("StopIteration", stopIterationClass)
+	classes.Set("ClosedQueueError", closedQueueErrorClass)
+	classes.Set("FloatDomainError", floatDomainErrorClass)
+	classes.Set("LocalJumpError", localJumpErrorClass)
+	classes.Set("EncodingError", encodingErrorClass)
+	classes.Set("FiberError", fiberErrorClass)
+	classes.Set("ThreadError", threadErrorClass)
+	classes.Set("SystemCallError", systemCallErrorClass)
+	classes.Set("SocketError", socketErrorClass)
+	// registered here as classNew refers to objectClass
+	exceptionClassMethods["exception"] = publicMethod(classNew)
+	stopIterationClass.(*class).addMethod("result", withArity(0, publicMethod(stopIterationResult)))
+	loadErrorClass.(*class).addMethod("path", withArity(0, publicMethod(loadErrorPath)))
+}
+
+// newExceptionClass returns the Exception class. Exceptions created from
+// Ruby code, like `ArgumentError.new`, are all represented by Exception,
+// holding the class they were created from. Subclasses inherit this
+// allocator.
+func newExceptionClass() *class {
+	exceptionClass := newClass("Exception", objectClass, exceptionMethods, exceptionClassMethods)
+	exceptionClass.allocator = func(class RubyClassObject) RubyObject {
+		return &Exception{exception: &exception{}, class: class}
+	}
+	return exceptionClass
+}
+
+// IsStandardError returns true if err is a Ruby exception of class
+// StandardError or one of its subclasses
+func IsStandardError(err error) bool {
+	exception, ok := err.(RubyObject)
+	if !ok {
+		return false
+	}
+	return IsKindOf(exception, standardErrorClass)
 }
 
 func formatException(exception RubyObject, message string) string {
-	return fmt.Sprintf("%s: %s", reflect.TypeOf(exception).Elem().Name(), message)
+	return fmt.Sprintf("%s: %s", exception.Class().(RubyObject).Inspect(), message)
 }
 
 // NewException creates a new exception with the given message template and
 //uses fmt.Sprintf to interpolate the args into messageinto message.
 func NewException(message string, args ...interface{}) *Exception {
-	return &Exception{&exception{Message: fmt.Sprintf(message, args...)}}
+	return &Exception{exception: &exception{Message: fmt.Sprintf(message, args...)}}
 }
 
-// Exception represents a basic exception
+// Exception represents a basic exception. It also represents instances of
+// all exception classes created from Ruby code, like `ArgumentError.new` or
+// instances of user defined exception classes.
 type Exception struct {
 	*exception
+	class RubyClass
 }
 
 // Type returns the type of the RubyObject
@@ -54,19 +141,240 @@ func (e *Exception) Type() Type { return EXCEPTION_OBJ }
 // Inspect returns a string starting with the exception class name, followed by the message
 func (e *Exception) Inspect() string { return formatException(e, e.Message) }
 
-// Class returns exceptionClass
-func (e *Exception) Class() RubyClass { return exceptionClass }
+// Class returns the class the exception was created from, or
+// exceptionClass
+func (e *Exception) Class() RubyClass {
+	if e.class != nil {
+		return e.class
+	}
+	return exceptionClass
+}
 
 var exceptionClassMethods = map[string]RubyMethod{}
 
-var exceptionMethods = map[string]RubyMethod{}
+var exceptionMethods = map[string]RubyMethod{
+	"initialize":    withArityRange(0, 1, privateMethod(exceptionInitialize)),
+	"message":       withArity(0, publicMethod(exceptionMessage)),
+	"to_s":          withArity(0, publicMethod(exceptionToS)),
+	"full_message":  withArityRange(0, 1, publicMethod(exceptionFullMessage)),
+	"exception":     withArityRange(0, 1, publicMethod(exceptionException)),
+	"cause":         withArity(0, publicMethod(exceptionCause)),
+	"backtrace":     withArity(0, publicMethod(exceptionBacktrace)),
+	"set_backtrace": withArity(1, publicMethod(exceptionSetBacktrace)),
+	"==":            withArity(1, publicMethod(exceptionEqual)),
+}
 
 type exception struct {
-	Message string
+	Message  string
+	causedBy RubyObject
+	trace    []string
+	pos      *SourcePosition
 }
 
 func (e *exception) Error() string { return e.Message }
 
+func (e *exception) setMessage(message string) { e.Message = message }
+
+func (e *exception) cause() RubyObject { return e.causedBy }
+
+func (e *exception) setCause(cause RubyObject) { e.causedBy = cause }
+
+func (e *exception) backtrace() []string { return e.trace }
+
+func (e *exception) setBacktrace(backtrace []string) { e.trace = backtrace }
+
+func (e *exception) position() *SourcePosition { return e.pos }
+
+func (e *exception) setPosition(pos *SourcePosition) { e.pos = pos }
+
+// messageHolder is implemented by all exceptions
+type messageHolder interface {
+	error
+	setMessage(message string)
+	cause() RubyObject
+	setCause(cause RubyObject)
+	backtrace() []string
+	setBacktrace(backtrace []string)
+	position() *SourcePosition
+	setPosition(pos *SourcePosition)
+}
+
+// A SourcePosition locates the expression which raised an exception
+// within the source it was parsed from
+type SourcePosition struct {
+	Path string
+	Line int
+	// Offset is the byte offset of the token of the expression within the
+	// source
+	Offset int
+}
+
+// Position returns the position of the expression which raised err. ok is
+// false if err is no exception or the position is unknown.
+func Position(err error) (pos SourcePosition, ok bool) {
+	exception, ok := err.(messageHolder)
+	if !ok || exception.position() == nil {
+		return SourcePosition{}, false
+	}
+	return *exception.position(), true
+}
+
+// SetPosition records pos as the position of the expression which raised
+// err. It has no effect if err is no exception.
+func SetPosition(err error, pos SourcePosition) {
+	if exception, ok := err.(messageHolder); ok {
+		exception.setPosition(&pos)
+	}
+}
+
+// Backtrace returns the backtrace of err, innermost frame first. ok is false
+// if err is no exception. The backtrace is nil if it has not been set yet.
+func Backtrace(err error) (backtrace []string, ok bool) {
+	exception, ok := err.(messageHolder)
+	if !ok {
+		return nil, false
+	}
+	return exception.backtrace(), true
+}
+
+// SetBacktrace sets the backtrace of err, innermost frame first. It has no
+// effect if err is no exception. The position of err is dropped along with
+// the former backtrace.
+func SetBacktrace(err error, backtrace []string) {
+	if exception, ok := err.(messageHolder); ok {
+		exception.setBacktrace(backtrace)
+		exception.setPosition(nil)
+	}
+}
+
+// SetCause records cause as the exception being handled while err was
+// raised. It has no effect if err already has a cause or is cause itself.
+func SetCause(err error, cause RubyObject) {
+	exception, ok := err.(messageHolder)
+	if !ok || exception.cause() != nil {
+		return
+	}
+	if obj, ok := err.(RubyObject); ok && obj == cause {
+		return
+	}
+	exception.setCause(cause)
+}
+
+// exceptionInitialize sets the message of the exception, which defaults to
+// the name of its class
+func exceptionInitialize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	exception, ok := context.(messageHolder)
+	if !ok {
+		return nil, NewTypeError("%s is not an exception", context.Inspect())
+	}
+	message := realClass(context).(RubyObject).Inspect()
+	if len(args) == 1 && args[0] != NIL {
+		message = toS(args[0])
+	}
+	exception.setMessage(message)
+	return NIL, nil
+}
+
+// exceptionMessage returns the result of to_s
+func exceptionMessage(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return Send(context, "to_s")
+}
+
+func exceptionToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	exception := context.(messageHolder)
+	if exception.Error() == "" {
+		return &String{Value: realClass(context).(RubyObject).Inspect()}, nil
+	}
+	return &String{Value: exception.Error()}, nil
+}
+
+// exceptionFullMessage returns the message followed by the class name of
+// the exception
+func exceptionFullMessage(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	message, err := Send(context, "message")
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: fmt.Sprintf(
+		"%s (%s)",
+		toS(message),
+		realClass(context).(RubyObject).Inspect(),
+	)}, nil
+}
+
+// exceptionException returns the receiver if called without an argument,
+// or a copy of it with the argument as message otherwise
+func exceptionException(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 || args[0] == context {
+		return context, nil
+	}
+	return &Exception{
+		exception: &exception{Message: toS(args[0])},
+		class:     realClass(context),
+	}, nil
+}
+
+// exceptionCause returns the exception which was being handled when the
+// receiver was raised, or nil
+func exceptionCause(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if cause := context.(messageHolder).cause(); cause != nil {
+		return cause, nil
+	}
+	return NIL, nil
+}
+
+// exceptionBacktrace returns the frames active when the receiver was raised
+// as Array of Strings, or nil if it has not been raised
+func exceptionBacktrace(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	backtrace := context.(messageHolder).backtrace()
+	if backtrace == nil {
+		return NIL, nil
+	}
+	lines := make([]RubyObject, len(backtrace))
+	for i, line := range backtrace {
+		lines[i] = &String{Value: line}
+	}
+	return NewArray(lines...), nil
+}
+
+// exceptionSetBacktrace sets the backtrace to the given String or Array of
+// Strings, or removes it given nil
+func exceptionSetBacktrace(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	var backtrace []string
+	switch arg := args[0].(type) {
+	case *nilObject:
+	case *String:
+		backtrace = []string{arg.Value}
+	case *Array:
+		for _, elem := range arg.Elements {
+			line, ok := elem.(*String)
+			if !ok {
+				return nil, NewTypeError("backtrace must be Array of String")
+			}
+			backtrace = append(backtrace, line.Value)
+		}
+		if backtrace == nil {
+			backtrace = []string{}
+		}
+	default:
+		return nil, NewTypeError("backtrace must be Array of String")
+	}
+	context.(messageHolder).setBacktrace(backtrace)
+	return args[0], nil
+}
+
+// exceptionEqual reports whether the argument is an exception of the same
+// class with the same message
+func exceptionEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	other, ok := args[0].(messageHolder)
+	if !ok || realClass(args[0]) != realClass(context) {
+		return FALSE, nil
+	}
+	return nativeBoolToBoolean(other.Error() == context.(messageHolder).Error()), nil
+}
+
 // NewStandardError returns a StandardError with the given message
 func NewStandardError(message string) *StandardError {
 	return &StandardError{&exception{Message: message}}
@@ -122,6 +430,56 @@ func NewWrongNumberOfArgumentsError(expected, actual int) *ArgumentError {
 	}
 }
 
+// NewWrongNumberOfArgumentsRangeError returns an ArgumentError populated
+// with the default message for methods accepting min to max arguments. A
+// negative max denotes an unlimited number of arguments.
+func NewWrongNumberOfArgumentsRangeError(min, max, actual int) *ArgumentError {
+	expected := fmt.Sprintf("%d..%d", min, max)
+	if max < 0 {
+		expected = fmt.Sprintf("%d+", min)
+	}
+	return &ArgumentError{
+		&exception{
+			Message: fmt.Sprintf(
+				"wrong number of arguments (given %d, expected %s)",
+				actual,
+				expected,
+			),
+		},
+	}
+}
+
+// NewArgumentError returns an ArgumentError with the provided message
+func NewArgumentError(format string, args ...interface{}) *ArgumentError {
+	return &ArgumentError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// NewComparisonError returns an ArgumentError with the default message for
+// failed comparisons between left and right
+func NewComparisonError(left, right RubyObject) *ArgumentError {
+	return &ArgumentError{
+		&exception{
+			Message: fmt.Sprintf(
+				"comparison of %s with %s failed",
+				comparisonOperandName(left),
+				comparisonOperandName(right),
+			),
+		},
+	}
+}
+
+func comparisonOperandName(obj RubyObject) string {
+	switch obj {
+	case NIL, TRUE, FALSE:
+		return obj.Inspect()
+	}
+	class, err := kernelClass(obj)
+	if err != nil {
+		return string(obj.Type())
+	}
+	return class.Inspect()
+}
+
 // ArgumentError represents an error in method call arguments
 type ArgumentError struct {
 	*exception
@@ -150,6 +508,40 @@ func NewNameError(context RubyObject, name string) *NameError {
 	}
 }
 
+// NewUndefinedMethodError returns a NameError for the method name missing
+// within module
+func NewUndefinedMethodError(name string, module RubyObject) *NameError {
+	kind := "class"
+	if _, ok := module.(*Module); ok {
+		kind = "module"
+	}
+	return &NameError{&exception{Message: fmt.Sprintf("undefined method `%s' for %s `%s'", name, kind, module.Inspect())}}
+}
+
+// NewUninitializedConstantError returns a NameError for the undefined
+// constant name
+func NewUninitializedConstantError(name string) *NameError {
+	return &NameError{&exception{Message: fmt.Sprintf("uninitialized constant %s", name)}}
+}
+
+// NewInvalidConstantNameError returns a NameError with the given message for
+// a name which is no valid constant name
+func NewInvalidConstantNameError(format string, name string) *NameError {
+	return &NameError{&exception{Message: fmt.Sprintf(format, name)}}
+}
+
+// NewInvalidInstanceVariableNameError returns a NameError for a name which
+// is no valid instance variable name
+func NewInvalidInstanceVariableNameError(name string) *NameError {
+	return &NameError{&exception{Message: fmt.Sprintf("`%s' is not allowed as an instance variable name", name)}}
+}
+
+// NewLocalVariableNotDefinedError returns a NameError for the local
+// variable name missing within binding
+func NewLocalVariableNotDefinedError(name string, binding *Binding) *NameError {
+	return &NameError{&exception{Message: fmt.Sprintf("local variable `%s' is not defined for %s", name, binding.Inspect())}}
+}
+
 // A NameError represents an error accessing an identifier unknown to the environment
 type NameError struct {
 	*exception
@@ -192,6 +584,35 @@ func NewPrivateNoMethodError(context RubyObject, method string) *NoMethodError {
 	}
 }
 
+// NewProtectedNoMethodError returns a NoMethodError with the default message for protected methods
+func NewProtectedNoMethodError(context RubyObject, method string) *NoMethodError {
+	return &NoMethodError{
+		&exception{
+			Message: fmt.Sprintf(
+				"protected method `%s' called for %s:%s",
+				method,
+				context.Inspect(),
+				context.Class().(RubyObject).Inspect(),
+			),
+		},
+	}
+}
+
+// NewNoSuperMethodError returns a NoMethodError for a call of super within
+// a method no ancestor defines
+func NewNoSuperMethodError(context RubyObject, method string) *NoMethodError {
+	return &NoMethodError{
+		&exception{
+			Message: fmt.Sprintf(
+				"super: no superclass method `%s' for %s:%s",
+				method,
+				context.Inspect(),
+				context.Class().(RubyObject).Inspect(),
+			),
+		},
+	}
+}
+
 // NoMethodError represents an error finding a fitting method on an object
 type NoMethodError struct {
 	*exception
@@ -232,6 +653,11 @@ func NewImplicitConversionTypeError(expected, actual RubyObject) *TypeError {
 	}
 }
 
+// NewTypeError returns a TypeError with the provided message
+func NewTypeError(format string, args ...interface{}) *TypeError {
+	return &TypeError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
 // TypeError represents an error when the given type does not fit in the given context
 type TypeError struct {
 	*exception
@@ -246,6 +672,239 @@ func (e *TypeError) Inspect() string { return formatException(e, e.Message) }
 // Class returns typeErrorClass
 func (e *TypeError) Class() RubyClass { return typeErrorClass }
 
+// NewRuntimeError returns a RuntimeError with the provided message
+func NewRuntimeError(format string, args ...interface{}) *RuntimeError {
+	return &RuntimeError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// RuntimeError represents a generic error raised at runtime
+type RuntimeError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *RuntimeError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *RuntimeError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns runtimeErrorClass
+func (e *RuntimeError) Class() RubyClass { return runtimeErrorClass }
+
+// NewIndexError returns an IndexError with the provided message
+func NewIndexError(format string, args ...interface{}) *IndexError {
+	return &IndexError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// IndexError represents an access with an index out of range
+type IndexError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *IndexError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *IndexError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns indexErrorClass
+func (e *IndexError) Class() RubyClass { return indexErrorClass }
+
+// NewKeyError returns a KeyError for the given missing key
+func NewKeyError(key RubyObject) *KeyError {
+	return &KeyError{&exception{Message: fmt.Sprintf("key not found: %s", key.Inspect())}}
+}
+
+// KeyError represents an access to a missing Hash key
+type KeyError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *KeyError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *KeyError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns keyErrorClass
+func (e *KeyError) Class() RubyClass { return keyErrorClass }
+
+// NewRangeError returns a RangeError with the provided message
+func NewRangeError(format string, args ...interface{}) *RangeError {
+	return &RangeError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// RangeError represents a value out of its valid range
+type RangeError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *RangeError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *RangeError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns rangeErrorClass
+func (e *RangeError) Class() RubyClass { return rangeErrorClass }
+
+// NewRegexpError returns a RegexpError with the provided message
+func NewRegexpError(format string, args ...interface{}) *RegexpError {
+	return &RegexpError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// RegexpError represents an invalid regular expression
+type RegexpError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *RegexpError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *RegexpError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns regexpErrorClass
+func (e *RegexpError) Class() RubyClass { return regexpErrorClass }
+
+// NewFiberError returns a FiberError with the provided message
+func NewFiberError(format string, args ...interface{}) *FiberError {
+	return &FiberError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// FiberError represents an invalid operation on a Fiber
+type FiberError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *FiberError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *FiberError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns fiberErrorClass
+func (e *FiberError) Class() RubyClass { return fiberErrorClass }
+
+// NewThreadError returns a ThreadError with the provided message
+func NewThreadError(format string, args ...interface{}) *ThreadError {
+	return &ThreadError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// ThreadError represents an invalid operation on a Thread
+type ThreadError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *ThreadError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *ThreadError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns threadErrorClass
+func (e *ThreadError) Class() RubyClass { return threadErrorClass }
+
+// NewFrozenError returns a FrozenError for an attempt to modify the frozen
+// object obj
+func NewFrozenError(obj RubyObject) *FrozenError {
+	return &FrozenError{
+		&exception{
+			Message: fmt.Sprintf("can't modify frozen %s", obj.Class().(RubyObject).Inspect()),
+		},
+	}
+}
+
+// FrozenError represents an attempt to modify a frozen object
+type FrozenError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *FrozenError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *FrozenError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns frozenErrorClass
+func (e *FrozenError) Class() RubyClass { return frozenErrorClass }
+
+// NewUncaughtThrowError returns an UncaughtThrowError for a throw of tag
+// without a matching catch
+func NewUncaughtThrowError(tag, value RubyObject) *UncaughtThrowError {
+	return &UncaughtThrowError{
+		exception: &exception{Message: fmt.Sprintf("uncaught throw %s", tag.Inspect())},
+		Tag:       tag,
+		Value:     value,
+	}
+}
+
+// UncaughtThrowError represents a throw without a matching catch
+type UncaughtThrowError struct {
+	*exception
+	Tag   RubyObject
+	Value RubyObject
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *UncaughtThrowError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *UncaughtThrowError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns uncaughtThrowErrorClass
+func (e *UncaughtThrowError) Class() RubyClass { return uncaughtThrowErrorClass }
+
+// NewStopIteration returns a StopIteration signalling the end of an
+// external iteration, which returned result
+func NewStopIteration(result RubyObject) *StopIteration {
+	return &StopIteration{exception: &exception{Message: "iteration reached an end"}, Result: result}
+}
+
+// StopIteration represents the end of an external iteration
+type StopIteration struct {
+	*exception
+	Result RubyObject
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *StopIteration) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *StopIteration) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns stopIterationClass
+func (e *StopIteration) Class() RubyClass { return stopIterationClass }
+
+// NewClosedQueueError returns a ClosedQueueError for pushing to a closed
+// Queue
+func NewClosedQueueError() *ClosedQueueError {
+	return &ClosedQueueError{&exception{Message: "queue closed"}}
+}
+
+// ClosedQueueError represents an attempt to push to a closed Queue
+type ClosedQueueError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *ClosedQueueError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *ClosedQueueError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns closedQueueErrorClass
+func (e *ClosedQueueError) Class() RubyClass { return closedQueueErrorClass }
+
+// stopIterationResult returns the return value of the iteration method, or
+// nil for a StopIteration raised explicitly
+func stopIterationResult(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if stop, ok := context.(*StopIteration); ok && stop.Result != nil {
+		return stop.Result, nil
+	}
+	return NIL, nil
+}
+
 // NewScriptError returns a new script error with the provided message
 func NewScriptError(format string, args ...interface{}) *ScriptError {
 	return &ScriptError{&exception{Message: fmt.Sprintf(format, args...)}}
@@ -268,18 +927,20 @@ func (e *ScriptError) Class() RubyClass { return scriptErrorClass }
 // NewLoadError returns a new LoadError with the default message
 func NewLoadError(filepath string) *LoadError {
 	return &LoadError{
-		&exception{
+		exception: &exception{
 			Message: fmt.Sprintf(
 				"no such file to load -- %s",
 				filepath,
 			),
 		},
+		Path: filepath,
 	}
 }
 
 // LoadError represents an error while loading another file
 type LoadError struct {
 	*exception
+	Path string
 }
 
 // Type returns EXCEPTION_OBJ
@@ -291,10 +952,19 @@ func (e *LoadError) Inspect() string { return formatException(e, e.Message) }
 // Class returns loadErrorClass
 func (e *LoadError) Class() RubyClass { return loadErrorClass }
 
+// loadErrorPath returns the path which could not be loaded, or nil for a
+// LoadError raised explicitly
+func loadErrorPath(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if loadError, ok := context.(*LoadError); ok && loadError.Path != "" {
+		return &String{Value: loadError.Path}, nil
+	}
+	return NIL, nil
+}
+
 // NewSyntaxError returns a new SyntaxError with the default message
 func NewSyntaxError(syntaxError string) *SyntaxError {
 	return &SyntaxError{
-		&exception{
+		exception: &exception{
 			Message: fmt.Sprintf(
 				"syntax error, %s",
 				syntaxError,
@@ -303,11 +973,26 @@ func NewSyntaxError(syntaxError string) *SyntaxError {
 	}
 }
 
+// NewFileSyntaxError returns a SyntaxError for the errors err found while
+// parsing the file path
+func NewFileSyntaxError(path string, err error) *SyntaxError {
+	syntaxError := NewSyntaxError(err.Error())
+	syntaxError.Path, syntaxError.parseErr = path, err
+	return syntaxError
+}
+
 // SyntaxError represents a syntax error in the ruby scripts
 type SyntaxError struct {
 	*exception
+	// Path is the file the error was found in, if known
+	Path     string
+	parseErr error
 }
 
+// Unwrap returns the errors of the parser the SyntaxError has been created
+// for, if any
+func (e *SyntaxError) Unwrap() error { return e.parseErr }
+
 // Type returns EXCEPTION_OBJ
 func (e *SyntaxError) Type() Type { return EXCEPTION_OBJ }
 
@@ -335,3 +1020,260 @@ func (e *NotImplementedError) Inspect() string { return formatException(e, e.Mes
 
 // Class returns notImplementedErrorClass
 func (e *NotImplementedError) Class() RubyClass { return notImplementedErrorClass }
+
+// NewInterrupt returns an Interrupt exception, as raised when the program is
+// interrupted
+func NewInterrupt() *Interrupt {
+	return &Interrupt{exception: &exception{Message: "Interrupt"}}
+}
+
+// NewContextInterrupt returns an Interrupt exception for a program stopped
+// as the context it is evaluated with is done with err
+func NewContextInterrupt(err error) *Interrupt {
+	return &Interrupt{exception: &exception{Message: err.Error()}, contextErr: err}
+}
+
+// Interrupt represents the interruption of the program, e.g. by Ctrl-C
+type Interrupt struct {
+	*exception
+	contextErr error
+}
+
+// Unwrap returns the error of the context the program has been stopped by,
+// if any
+func (e *Interrupt) Unwrap() error { return e.contextErr }
+
+// Type returns EXCEPTION_OBJ
+func (e *Interrupt) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *Interrupt) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns interruptClass
+func (e *Interrupt) Class() RubyClass { return interruptClass }
+
+// NewSystemStackError returns a SystemStackError, as raised when the
+// program recurses too deeply
+func NewSystemStackError() *SystemStackError {
+	return &SystemStackError{&exception{Message: "stack level too deep"}}
+}
+
+// SystemStackError represents the overflow of the call stack
+type SystemStackError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *SystemStackError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *SystemStackError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns systemStackErrorClass
+func (e *SystemStackError) Class() RubyClass { return systemStackErrorClass }
+
+// NewResourceLimitError returns a ResourceLimitError for a program which
+// exceeded the limit of the given resource
+func NewResourceLimitError(resource string, limit interface{}) *ResourceLimitError {
+	return &ResourceLimitError{
+		exception: &exception{Message: fmt.Sprintf("%s limit of %v exceeded", resource, limit)},
+		Resource:  resource,
+	}
+}
+
+// ResourceLimitError represents the termination of a program which
+// exceeded one of the limits it runs with. It is no StandardError and thus
+// not rescued by a bare rescue clause.
+type ResourceLimitError struct {
+	*exception
+	Resource string
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *ResourceLimitError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *ResourceLimitError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns resourceLimitErrorClass
+func (e *ResourceLimitError) Class() RubyClass { return resourceLimitErrorClass }
+
+// NewSystemExit returns a SystemExit exception, as raised by Kernel#exit,
+// requesting the program to terminate with the given status
+func NewSystemExit(status int) *SystemExit {
+	return &SystemExit{exception: &exception{Message: "exit"}, Status: status}
+}
+
+// SystemExit represents the request to terminate the program
+type SystemExit struct {
+	*exception
+	Status int
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *SystemExit) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *SystemExit) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns systemExitClass
+func (e *SystemExit) Class() RubyClass { return systemExitClass }
+
+// NewJSONParserError returns a JSON::ParserError with the provided message
+func NewJSONParserError(format string, args ...interface{}) *JSONParserError {
+	return &JSONParserError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// JSONParserError represents invalid JSON passed to JSON.parse
+type JSONParserError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *JSONParserError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *JSONParserError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns jsonParserErrorClass
+func (e *JSONParserError) Class() RubyClass { return jsonParserErrorClass }
+
+// NewJSONGeneratorError returns a JSON::GeneratorError with the provided
+// message
+func NewJSONGeneratorError(format string, args ...interface{}) *JSONGeneratorError {
+	return &JSONGeneratorError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// JSONGeneratorError represents an object JSON.generate cannot represent
+// as JSON, like NaN
+type JSONGeneratorError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *JSONGeneratorError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *JSONGeneratorError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns jsonGeneratorErrorClass
+func (e *JSONGeneratorError) Class() RubyClass { return jsonGeneratorErrorClass }
+
+// NewYAMLSyntaxError returns a Psych::SyntaxError with the provided message
+func NewYAMLSyntaxError(format string, args ...interface{}) *YAMLSyntaxError {
+	return &YAMLSyntaxError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// YAMLSyntaxError represents invalid YAML passed to YAML.load
+type YAMLSyntaxError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *YAMLSyntaxError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *YAMLSyntaxError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns psychSyntaxErrorClass
+func (e *YAMLSyntaxError) Class() RubyClass { return psychSyntaxErrorClass }
+
+// NewNetOpenTimeoutError returns a Net::OpenTimeout for a connection to
+// address not established in time
+func NewNetOpenTimeoutError(address string) *NetOpenTimeoutError {
+	return &NetOpenTimeoutError{&exception{Message: fmt.Sprintf("Failed to open TCP connection to %s (execution expired)", address)}}
+}
+
+// NetOpenTimeoutError represents an HTTP connection not established within
+// the open timeout
+type NetOpenTimeoutError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *NetOpenTimeoutError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *NetOpenTimeoutError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns netOpenTimeoutClass
+func (e *NetOpenTimeoutError) Class() RubyClass { return netOpenTimeoutClass }
+
+// NewNetReadTimeoutError returns a Net::ReadTimeout for a response not
+// received in time
+func NewNetReadTimeoutError() *NetReadTimeoutError {
+	return &NetReadTimeoutError{&exception{Message: "Net::ReadTimeout"}}
+}
+
+// NetReadTimeoutError represents an HTTP response not received within the
+// read timeout
+type NetReadTimeoutError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *NetReadTimeoutError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *NetReadTimeoutError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns netReadTimeoutClass
+func (e *NetReadTimeoutError) Class() RubyClass { return netReadTimeoutClass }
+
+// NewSocketError returns a SocketError with the provided message
+func NewSocketError(format string, args ...interface{}) *SocketError {
+	return &SocketError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// SocketError represents a failing network connection, like an unknown host
+type SocketError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *SocketError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *SocketError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns socketErrorClass
+func (e *SocketError) Class() RubyClass { return socketErrorClass }
+
+// NewIOError returns an IOError with the provided message
+func NewIOError(format string, args ...interface{}) *IOError {
+	return &IOError{&exception{Message: fmt.Sprintf(format, args...)}}
+}
+
+// IOError represents an invalid operation on a stream, like reading from a
+// closed one
+type IOError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *IOError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *IOError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns ioErrorClass
+func (e *IOError) Class() RubyClass { return ioErrorClass }
+
+// NewEOFError returns an EOFError for reading beyond the end of a stream
+func NewEOFError() *EOFError {
+	return &EOFError{&exception{Message: "end of file reached"}}
+}
+
+// EOFError represents reading beyond the end of a stream
+type EOFError struct {
+	*exception
+}
+
+// Type returns EXCEPTION_OBJ
+func (e *EOFError) Type() Type { return EXCEPTION_OBJ }
+
+// Inspect returns a string starting with the exception class name, followed by the message
+func (e *EOFError) Inspect() string { return formatException(e, e.Message) }
+
+// Class returns eofErrorClass
+func (e *EOFError) Class() RubyClass { return eofErrorClass }
@@ -0,0 +1,113 @@
+package object
+
+import "testing"
+
+func TestExceptionClassHierarchy(t *testing.T) {
+	tests := []struct {
+		class      RubyClass
+		superClass RubyClass
+	}{
+		{standardErrorClass, exceptionClass},
+		{noMethodErrorClass, nameErrorClass},
+		{keyErrorClass, indexErrorClass},
+		{stopIterationClass, indexErrorClass},
+		{eofErrorClass, ioErrorClass},
+		{floatDomainErrorClass, rangeErrorClass},
+		{frozenErrorClass, runtimeErrorClass},
+		{interruptClass, signalExceptionClass},
+		{systemStackErrorClass, exceptionClass},
+	}
+
+	for _, tt := range tests {
+		if superClass := tt.class.SuperClass(); superClass != tt.superClass {
+			t.Logf("Expected superclass of %s to be %s, got %s", tt.class.(RubyObject).Inspect(), tt.superClass.(RubyObject).Inspect(), superClass.(RubyObject).Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestExceptionNew(t *testing.T) {
+	tests := []struct {
+		class   RubyClassObject
+		args    []RubyObject
+		message string
+	}{
+		{exceptionClass, nil, "Exception"},
+		{argumentErrorClass, []RubyObject{&String{Value: "bad"}}, "bad"},
+		{ioErrorClass, []RubyObject{NIL}, "IOError"},
+		{runtimeErrorClass, []RubyObject{&Symbol{"sym"}}, "sym"},
+	}
+
+	for _, tt := range tests {
+		result, err := classNew(tt.class, tt.args...)
+		checkError(t, err, nil)
+		if !IsKindOf(result, tt.class) {
+			t.Logf("Expected exception to be a %s, got %s", tt.class.Inspect(), result.Class().(RubyObject).Inspect())
+			t.Fail()
+		}
+		message, err := exceptionMessage(result)
+		checkError(t, err, nil)
+		checkResult(t, message, &String{Value: tt.message})
+	}
+}
+
+func TestKernelRaise(t *testing.T) {
+	argumentError, _ := classNew(argumentErrorClass, &String{Value: "bad"})
+
+	tests := []struct {
+		args     []RubyObject
+		expected error
+	}{
+		{nil, NewRuntimeError("unhandled exception")},
+		{[]RubyObject{&String{Value: "msg"}}, NewRuntimeError("msg")},
+		{[]RubyObject{argumentError}, argumentError.(error)},
+		{[]RubyObject{NewInteger(1)}, NewTypeError("exception class/object expected")},
+		{[]RubyObject{comparableModule}, NewTypeError("exception class/object expected")},
+	}
+
+	for _, tt := range tests {
+		_, err := kernelRaise(NIL, tt.args...)
+		checkError(t, err, tt.expected)
+	}
+
+	_, err := kernelRaise(NIL, typeErrorClass, &String{Value: "wrong"})
+	if !IsKindOf(err.(RubyObject), typeErrorClass) || err.Error() != "wrong" {
+		t.Logf("Expected TypeError with message wrong, got %v", err)
+		t.Fail()
+	}
+}
+
+func TestExceptionBacktrace(t *testing.T) {
+	err := NewRuntimeError("x")
+
+	result, methodErr := exceptionBacktrace(err)
+	checkError(t, methodErr, nil)
+	checkResult(t, result, NIL)
+
+	SetBacktrace(err, []string{"a.rb:2:in `m'", "a.rb:5:in `<main>'"})
+	result, methodErr = exceptionBacktrace(err)
+	checkError(t, methodErr, nil)
+	checkResult(t, result, NewArray(&String{Value: "a.rb:2:in `m'"}, &String{Value: "a.rb:5:in `<main>'"}))
+
+	_, methodErr = exceptionSetBacktrace(err, &String{Value: "b.rb:1"})
+	checkError(t, methodErr, nil)
+	if backtrace, _ := Backtrace(err); len(backtrace) != 1 || backtrace[0] != "b.rb:1" {
+		t.Logf("Expected backtrace to be set, got %q", backtrace)
+		t.Fail()
+	}
+
+	_, methodErr = exceptionSetBacktrace(err, NewArray(NewInteger(1)))
+	checkError(t, methodErr, NewTypeError("backtrace must be Array of String"))
+}
+
+func TestLoadErrorPath(t *testing.T) {
+	result, err := Send(NewLoadError("foo/bar"), "path")
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "foo/bar"})
+
+	loadError, err := Send(loadErrorClass, "new", &String{Value: "x"})
+	checkError(t, err, nil)
+	result, err = Send(loadError, "path")
+	checkError(t, err, nil)
+	checkResult(t, result, NIL)
+}
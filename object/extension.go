@@ -0,0 +1,263 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// An Extension contributes classes and modules implemented in Go to the
+// interpreter, like a C extension does to MRI. Packages register their
+// extensions by RegisterExtension, usually within an init function, and
+// the interpreter initializes them once at startup. Init defines the
+// classes and modules by DefineClass and DefineModule.
+type Extension interface {
+	// Name is the name programs require the extension by
+	Name() string
+	// Init defines the classes and modules of the extension
+	Init() error
+}
+
+// registeredExtension is an Extension and the result of its initialization
+type registeredExtension struct {
+	extension   Extension
+	initialized bool
+	err         error
+}
+
+// extensions holds the registered Extensions by name
+var extensions = struct {
+	sync.Mutex
+	byName map[string]*registeredExtension
+	names  []string
+}{byName: make(map[string]*registeredExtension)}
+
+// RegisterExtension registers ext to be initialized by InitExtensions. It
+// panics if an extension of the same name is registered already.
+func RegisterExtension(ext Extension) {
+	extensions.Lock()
+	defer extensions.Unlock()
+	name := ext.Name()
+	if _, ok := extensions.byName[name]; ok {
+		panic(fmt.Sprintf("extension %s registered twice", name))
+	}
+	extensions.byName[name] = &registeredExtension{extension: ext}
+	extensions.names = append(extensions.names, name)
+}
+
+// InitExtensions initializes the extensions registered since the last
+// call, in the order they were registered. It is called for every new main
+// environment and returns the errors of the extensions failing, whose
+// classes and modules may be defined partially. Requiring them raises a
+// LoadError with their error.
+func InitExtensions() error {
+	extensions.Lock()
+	defer extensions.Unlock()
+	var failed []string
+	for _, name := range extensions.names {
+		registered := extensions.byName[name]
+		if registered.initialized {
+			continue
+		}
+		registered.initialized = true
+		if err := registered.extension.Init(); err != nil {
+			registered.err = err
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("initializing extensions failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// RequireExtension reports whether name is a registered extension, which
+// programs require like a feature loaded at startup. It returns a LoadError
+// if the extension failed to initialize.
+func RequireExtension(name string) (bool, error) {
+	extensions.Lock()
+	defer extensions.Unlock()
+	registered, ok := extensions.byName[name]
+	if !ok || !registered.initialized {
+		return false, nil
+	}
+	if registered.err != nil {
+		return true, &LoadError{exception: &exception{Message: fmt.Sprintf("%s -- %s", registered.err, name)}, Path: name}
+	}
+	return true, nil
+}
+
+// NewMethod returns a public method calling fn with the receiver and the
+// arguments, which are at least min and at most max, or any number above
+// min if max is negative. Otherwise an ArgumentError is raised. A block is
+// passed as last argument and not counted.
+func NewMethod(min, max int, fn func(self RubyObject, args ...RubyObject) (RubyObject, error)) RubyMethod {
+	if min == max {
+		return withArity(min, publicMethod(fn))
+	}
+	return withArityRange(min, max, publicMethod(fn))
+}
+
+// A ClassDefinition describes a class defined by DefineClass
+type ClassDefinition struct {
+	// Name is the name of the constant the class is assigned to, which may
+	// be nested into existing modules like `Net::Client`
+	Name string
+	// Superclass defaults to Object
+	Superclass RubyClassObject
+	// Include names the modules mixed in, like `Comparable`
+	Include []string
+	// Methods are the instance methods by name
+	Methods map[string]RubyMethod
+	// ClassMethods are the singleton methods of the class by name
+	ClassMethods map[string]RubyMethod
+	// Constants are set within the class
+	Constants map[string]RubyObject
+	// Allocate returns new, uninitialized instances of class for new and
+	// allocate, usually of a Go type implementing RubyObject. Instances are
+	// allocated like the ones of Superclass if it is nil.
+	Allocate func(class RubyClassObject) RubyObject
+}
+
+// DefineClass defines the class described by def, or adds the methods and
+// constants of def to the class if it exists already. It returns a TypeError
+// if the constant is no class or a class with another superclass, and a
+// NameError for invalid or undefined names. Like the builtin classes, the
+// class is shared by all runtimes.
+func DefineClass(def ClassDefinition) (RubyClassObject, error) {
+	namespace, name, err := definitionNamespace(def.Name)
+	if err != nil {
+		return nil, err
+	}
+	superClass := def.Superclass
+	if superClass == nil {
+		superClass = objectClass
+	}
+	var rubyClass RubyClassObject
+	if existing, ok := ownConstant(namespace, name); ok {
+		existingClass, ok := existing.(RubyClassObject)
+		if _, isModule := existing.(*Module); !ok || isModule {
+			return nil, NewTypeError("%s is not a class", def.Name)
+		}
+		if def.Superclass != nil && existingClass.SuperClass() != superClass {
+			return nil, NewTypeError("superclass mismatch for class %s", def.Name)
+		}
+		if len(def.Include) > 0 {
+			return nil, NewTypeError("can't include modules into the existing class %s", def.Name)
+		}
+		rubyClass = existingClass
+	} else {
+		modules := make([]*Module, len(def.Include))
+		for i, moduleName := range def.Include {
+			constant, ok := ownConstant(objectClass, moduleName)
+			if !ok {
+				return nil, NewUninitializedConstantError(moduleName)
+			}
+			module, ok := constant.(*Module)
+			if !ok {
+				return nil, NewTypeError("wrong argument type %s (expected Module)", className(constant))
+			}
+			modules[i] = module
+		}
+		newClass := newSubclass(qualifiedConstantName(namespace, name), superClass)
+		rubyClass = newClass
+		if len(modules) > 0 {
+			rubyClass = mixin(newClass, modules...)
+		}
+		setConstant(namespace, name, rubyClass)
+	}
+	if def.Allocate != nil {
+		setAllocator(rubyClass, def.Allocate)
+	}
+	for methodName, method := range def.Methods {
+		NewSymbol(methodName)
+		rubyClass.(methodDefiner).addMethod(methodName, method)
+	}
+	defineSingletonMethods(rubyClass, def.ClassMethods)
+	if err := defineConstants(rubyClass, def.Constants); err != nil {
+		return nil, err
+	}
+	markBuiltinTables(rubyClass)
+	return rubyClass, nil
+}
+
+// A ModuleDefinition describes a module defined by DefineModule
+type ModuleDefinition struct {
+	// Name is the name of the constant the module is assigned to, which may
+	// be nested into existing modules like `Net::HTTP`
+	Name string
+	// Methods are the module functions by name, which are mixed in as
+	// instance methods by classes including the module
+	Methods map[string]RubyMethod
+	// Constants are set within the module
+	Constants map[string]RubyObject
+}
+
+// DefineModule defines the module described by def, or adds the methods and
+// constants of def to the module if it exists already. It returns a
+// TypeError if the constant is no module, and a NameError for invalid or
+// undefined names.
+func DefineModule(def ModuleDefinition) (*Module, error) {
+	namespace, name, err := definitionNamespace(def.Name)
+	if err != nil {
+		return nil, err
+	}
+	var module *Module
+	if existing, ok := ownConstant(namespace, name); ok {
+		if module, ok = existing.(*Module); !ok {
+			return nil, NewTypeError("%s is not a module", def.Name)
+		}
+	} else {
+		module = newModule(qualifiedConstantName(namespace, name), nil)
+		setConstant(namespace, name, module)
+	}
+	defineSingletonMethods(module, def.Methods)
+	if err := defineConstants(module, def.Constants); err != nil {
+		return nil, err
+	}
+	markBuiltinTables(module)
+	return module, nil
+}
+
+// definitionNamespace resolves the modules a constant path like `A::B`
+// nests into and returns the innermost one with the last name
+func definitionNamespace(path string) (RubyObject, string, error) {
+	names, _, err := constantPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var namespace RubyObject = objectClass
+	for _, name := range names[:len(names)-1] {
+		constant, ok := ownConstant(namespace, name)
+		if !ok {
+			return nil, "", NewUninitializedConstantError(qualifiedConstantName(namespace, name))
+		}
+		if _, ok := constant.(RubyClassObject); !ok {
+			if _, ok := constant.(*Module); !ok {
+				return nil, "", NewTypeError("%s is not a class/module", constant.Inspect())
+			}
+		}
+		namespace = constant
+	}
+	return namespace, names[len(names)-1], nil
+}
+
+// defineSingletonMethods adds methods to the singleton class of module
+func defineSingletonMethods(module RubyObject, methods map[string]RubyMethod) {
+	for name, method := range methods {
+		NewSymbol(name)
+		module.Class().(methodDefiner).addMethod(name, method)
+	}
+}
+
+// defineConstants sets constants within module, returning a NameError for
+// invalid names
+func defineConstants(module RubyObject, constants map[string]RubyObject) error {
+	for name, value := range constants {
+		if !IsConstantName(name) {
+			return NewInvalidConstantNameError("wrong constant name %s", name)
+		}
+		setConstant(module, name, value)
+	}
+	return nil
+}
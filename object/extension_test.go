@@ -0,0 +1,187 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+// testCounter is an instance of the class defined by testCounterExtension
+type testCounter struct {
+	class RubyClassObject
+	count int64
+}
+
+func (c *testCounter) Type() Type       { return OBJECT_OBJ }
+func (c *testCounter) Inspect() string  { return "#<TestCounter>" }
+func (c *testCounter) Class() RubyClass { return c.class }
+
+type testCounterExtension struct{}
+
+func (e testCounterExtension) Name() string { return "test_counter" }
+
+func (e testCounterExtension) Init() error {
+	_, err := DefineClass(ClassDefinition{
+		Name:    "TestCounter",
+		Include: []string{"Comparable"},
+		Methods: map[string]RubyMethod{
+			"increment": NewMethod(0, 1, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+				by := int64(1)
+				if len(args) == 1 {
+					by = args[0].(*Integer).Value
+				}
+				self.(*testCounter).count += by
+				return self, nil
+			}),
+			"count": NewMethod(0, 0, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+				return NewInteger(self.(*testCounter).count), nil
+			}),
+			"<=>": NewMethod(1, 1, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+				return Send(NewInteger(self.(*testCounter).count), "<=>", NewInteger(args[0].(*testCounter).count))
+			}),
+		},
+		ClassMethods: map[string]RubyMethod{
+			"zero": NewMethod(0, 0, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+				return Send(self, "new")
+			}),
+		},
+		Constants: map[string]RubyObject{"STEP": NewInteger(1)},
+		Allocate: func(class RubyClassObject) RubyObject {
+			return &testCounter{class: class}
+		},
+	})
+	return err
+}
+
+func TestDefineClass(t *testing.T) {
+	checkError(t, testCounterExtension{}.Init(), nil)
+
+	counterClass, ok := ownConstant(objectClass, "TestCounter")
+	if !ok {
+		t.Fatalf("Expected TestCounter to be defined")
+	}
+	if counterClass.Inspect() != "TestCounter" {
+		t.Errorf("Expected class name TestCounter, got %s", counterClass.Inspect())
+	}
+
+	counter, err := Send(counterClass, "zero")
+	checkError(t, err, nil)
+	_, err = Send(counter, "increment", NewInteger(2))
+	checkError(t, err, nil)
+	count, err := Send(counter, "count")
+	checkError(t, err, nil)
+	checkResult(t, count, NewInteger(2))
+
+	other, err := Send(counterClass, "new")
+	checkError(t, err, nil)
+	greater, err := Send(counter, ">", other)
+	checkError(t, err, nil)
+	checkResult(t, greater, TRUE)
+
+	_, err = Send(counter, "increment", NewInteger(1), NewInteger(2))
+	checkError(t, err, NewWrongNumberOfArgumentsRangeError(0, 1, 2))
+
+	step, ok := ownConstant(counterClass, "STEP")
+	if !ok {
+		t.Errorf("Expected TestCounter::STEP to be defined")
+	} else {
+		checkResult(t, step, NewInteger(1))
+	}
+
+	t.Run("reopen", func(t *testing.T) {
+		_, err := DefineClass(ClassDefinition{
+			Name: "TestCounter",
+			Methods: map[string]RubyMethod{
+				"reset": NewMethod(0, 0, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+					self.(*testCounter).count = 0
+					return self, nil
+				}),
+			},
+		})
+		checkError(t, err, nil)
+		_, err = Send(counter, "reset")
+		checkError(t, err, nil)
+		count, err := Send(counter, "count")
+		checkError(t, err, nil)
+		checkResult(t, count, NewInteger(0))
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := DefineClass(ClassDefinition{Name: "TestCounter", Superclass: stringClass})
+		checkError(t, err, NewTypeError("superclass mismatch for class TestCounter"))
+
+		_, err = DefineClass(ClassDefinition{Name: "Comparable"})
+		checkError(t, err, NewTypeError("Comparable is not a class"))
+
+		_, err = DefineClass(ClassDefinition{Name: "Undefined::Client"})
+		checkError(t, err, NewUninitializedConstantError("Undefined"))
+
+		_, err = DefineClass(ClassDefinition{Name: "lowercase"})
+		checkError(t, err, NewInvalidConstantNameError("wrong constant name %s", "lowercase"))
+	})
+}
+
+func TestDefineModule(t *testing.T) {
+	module, err := DefineModule(ModuleDefinition{
+		Name: "TestExtensionModule",
+		Methods: map[string]RubyMethod{
+			"twice": NewMethod(1, 1, func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+				return Send(args[0], "*", NewInteger(2))
+			}),
+		},
+		Constants: map[string]RubyObject{"VERSION": &String{Value: "1.0"}},
+	})
+	checkError(t, err, nil)
+
+	result, err := Send(module, "twice", NewInteger(21))
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(42))
+
+	nested, err := DefineClass(ClassDefinition{Name: "TestExtensionModule::Client"})
+	checkError(t, err, nil)
+	if nested.Inspect() != "TestExtensionModule::Client" {
+		t.Errorf("Expected TestExtensionModule::Client, got %s", nested.Inspect())
+	}
+	if _, ok := ownConstant(module, "Client"); !ok {
+		t.Errorf("Expected Client to be defined within TestExtensionModule")
+	}
+
+	_, err = DefineModule(ModuleDefinition{Name: "String"})
+	checkError(t, err, NewTypeError("String is not a module"))
+}
+
+func TestRegisterExtension(t *testing.T) {
+	failing := errors.New("missing library")
+	RegisterExtension(failingTestExtension{failing})
+
+	err := InitExtensions()
+	if err == nil || err.Error() != "initializing extensions failed: test_failing: missing library" {
+		t.Errorf("Expected the extension to fail, got %v", err)
+	}
+	checkError(t, InitExtensions(), nil)
+
+	ok, err := RequireExtension("test_failing")
+	if !ok {
+		t.Errorf("Expected test_failing to be an extension")
+	}
+	loadErr, isLoadError := err.(*LoadError)
+	if !isLoadError || loadErr.Message != "missing library -- test_failing" {
+		t.Errorf("Expected LoadError, got %v", err)
+	}
+
+	ok, err = RequireExtension("test_unknown")
+	checkError(t, err, nil)
+	if ok {
+		t.Errorf("Expected test_unknown not to be an extension")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering test_failing twice to panic")
+		}
+	}()
+	RegisterExtension(failingTestExtension{failing})
+}
+
+type failingTestExtension struct{ err error }
+
+func (e failingTestExtension) Name() string { return "test_failing" }
+func (e failingTestExtension) Init() error  { return e.err }
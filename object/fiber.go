@@ -0,0 +1,194 @@
+package object
+
+import "fmt"
+
+var fiberClass RubyClassObject = newClass("Fiber", objectClass, fiberMethods, fiberClassMethods)
+
+func init() {
+	classes.Set("Fiber", fiberClass)
+}
+
+// NewFiber returns a Fiber running within r which calls block when it is
+// resumed first
+func NewFiber(r *Runtime, block *Proc) *Fiber {
+	return &Fiber{block: block, runtime: r}
+}
+
+// A Fiber represents a Ruby Fiber. Its block runs on its own goroutine,
+// paired with the goroutine resuming it: the resuming side waits while the
+// Fiber runs and the Fiber waits while it is suspended, so that both never
+// run at the same time.
+type Fiber struct {
+	block *Proc
+	// runtime is the Runtime the Fiber was created and runs within
+	runtime *Runtime
+	resumed chan fiberTransfer
+	yielded chan fiberTransfer
+	// previous is the Fiber which resumed this one, or nil for the root
+	// fiber of the Thread
+	previous *Fiber
+	running  bool
+	done     bool
+	// killed is set once the Fiber is killed, so that it does not suspend
+	// again while its block unwinds
+	killed bool
+	// CallStack holds the frames of the method calls made within the
+	// Fiber, which the evaluator keeps apart from the ones of its resumer
+	CallStack *Array
+}
+
+// CurrentFiber returns the Fiber running within the current Thread of r, or
+// nil if the root fiber of the Thread runs
+func (r *Runtime) CurrentFiber() *Fiber {
+	return r.currentThread().fiber
+}
+
+// Resumer returns the Fiber which resumed f, or nil if the root fiber of the
+// Thread did
+func (f *Fiber) Resumer() *Fiber { return f.previous }
+
+// fiberTransfer holds the values passed between a Fiber and its resumer.
+// kill asks a suspended Fiber to end.
+type fiberTransfer struct {
+	values []RubyObject
+	err    error
+	kill   bool
+}
+
+// fiberKilled unwinds the block of a killed Fiber from the Fiber.yield it
+// was suspended in. It is no Ruby exception and thus not rescued.
+type fiberKilled struct{}
+
+func (fiberKilled) Error() string { return "fiber killed" }
+
+// Type returns FIBER_OBJ
+func (f *Fiber) Type() Type { return FIBER_OBJ }
+
+// Inspect returns the object id and status of the Fiber
+func (f *Fiber) Inspect() string {
+	return fmt.Sprintf("#<Fiber:0x%016x (%s)>", ObjectID(f), f.status())
+}
+
+// Class returns fiberClass
+func (f *Fiber) Class() RubyClass { return fiberClass }
+
+func (f *Fiber) status() string {
+	switch {
+	case f.done:
+		return "terminated"
+	case f.running:
+		return "resumed"
+	case f.resumed == nil:
+		return "created"
+	default:
+		return "suspended"
+	}
+}
+
+// Alive reports whether f did not return yet
+func (f *Fiber) Alive() bool { return !f.done }
+
+// Resume runs f until it yields or returns and returns the values passed
+// to Fiber.yield or the result of its block. The args are passed to the
+// block on the first resume and returned from Fiber.yield afterwards.
+func (f *Fiber) Resume(args ...RubyObject) (RubyObject, error) {
+	switch {
+	case f.done:
+		return nil, NewFiberError("dead fiber called")
+	case f.running:
+		return nil, NewFiberError("attempt to resume a resumed fiber (double resume)")
+	}
+	if f.resumed == nil {
+		f.resumed = make(chan fiberTransfer)
+		f.yielded = make(chan fiberTransfer)
+		go f.run()
+	}
+	transfer := f.transfer(fiberTransfer{values: args})
+	if transfer.err != nil {
+		return nil, transfer.err
+	}
+	return yieldedValue(transfer.values), nil
+}
+
+// kill ends f if it is suspended, unwinding its block, so that the
+// goroutine it runs on finishes. Like Resume it runs within the current
+// Thread until the block returned.
+func (f *Fiber) kill() {
+	if f.resumed == nil {
+		f.done = true
+	}
+	if f.running || f.done {
+		return
+	}
+	f.killed = true
+	f.transfer(fiberTransfer{kill: true})
+}
+
+// transfer runs f, passing it sent, until it yields or returns what it
+// passes back
+func (f *Fiber) transfer(sent fiberTransfer) fiberTransfer {
+	thread := f.runtime.threadState().currentThread()
+	f.previous, thread.fiber = thread.fiber, f
+	f.running = true
+	f.resumed <- sent
+	received := <-f.yielded
+	f.running = false
+	thread.fiber = f.previous
+	return received
+}
+
+func (f *Fiber) run() {
+	transfer := <-f.resumed
+	result, err := f.block.Call(transfer.values...)
+	f.done = true
+	f.yielded <- fiberTransfer{values: []RubyObject{result}, err: err}
+}
+
+// FiberYield suspends the Fiber running within the current Thread of r,
+// passing values to its resumer. It returns the values passed to the next
+// resume.
+func FiberYield(r *Runtime, values ...RubyObject) (RubyObject, error) {
+	fiber := r.currentThread().fiber
+	if fiber == nil {
+		return nil, NewFiberError("can't yield from root fiber")
+	}
+	if fiber.killed {
+		return nil, fiberKilled{}
+	}
+	fiber.yielded <- fiberTransfer{values: values}
+	transfer := <-fiber.resumed
+	if transfer.kill {
+		return nil, fiberKilled{}
+	}
+	return yieldedValue(transfer.values), nil
+}
+
+var fiberClassMethods = map[string]RubyMethod{
+	"new": withArity(0, publicRuntimeMethod(func(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+		_, block := extractBlock(args)
+		if block == nil {
+			return nil, NewArgumentError("tried to create Proc object without a block")
+		}
+		return NewFiber(r, block), nil
+	})),
+	"yield": publicRuntimeMethod(fiberYield),
+}
+
+var fiberMethods = map[string]RubyMethod{
+	"resume": publicMethod(fiberResume),
+	"alive?": withArity(0, publicMethod(fiberAlive)),
+}
+
+func fiberYield(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	return FiberYield(r, args...)
+}
+
+func fiberResume(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	return context.(*Fiber).Resume(args...)
+}
+
+func fiberAlive(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Fiber).Alive()), nil
+}
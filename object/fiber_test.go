@@ -0,0 +1,59 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestFiber(t *testing.T) {
+	t.Run("resume and yield", func(t *testing.T) {
+		var received []RubyObject
+		fiber := NewFiber(nil, newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			received = append(received, args...)
+			value, err := FiberYield(nil, NewInteger(1))
+			if err != nil {
+				return nil, err
+			}
+			received = append(received, value)
+			return NewInteger(2), nil
+		}))
+
+		result, err := fiber.Resume(NewSymbol("a"))
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(1))
+
+		result, err = fiber.Resume(NewSymbol("b"))
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(2))
+
+		checkResult(t, NewArray(received...), NewArray(NewSymbol("a"), NewSymbol("b")))
+
+		if fiber.Alive() {
+			t.Logf("Expected fiber to be terminated")
+			t.Fail()
+		}
+
+		_, err = fiber.Resume()
+		checkError(t, err, NewFiberError("dead fiber called"))
+	})
+	t.Run("exceptions propagate to the resumer", func(t *testing.T) {
+		fiber := NewFiber(nil, newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return nil, NewRuntimeError("boom")
+		}))
+
+		_, err := fiber.Resume()
+		checkError(t, err, NewRuntimeError("boom"))
+	})
+	t.Run("yield from root fiber", func(t *testing.T) {
+		_, err := FiberYield(nil)
+		checkError(t, err, NewFiberError("can't yield from root fiber"))
+	})
+	t.Run("double resume", func(t *testing.T) {
+		var fiber *Fiber
+		fiber = NewFiber(nil, newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return fiber.Resume()
+		}))
+
+		_, err := fiber.Resume()
+		checkError(t, err, NewFiberError("attempt to resume a resumed fiber (double resume)"))
+	})
+}
@@ -0,0 +1,574 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var fileUtilsModule = newModule("FileUtils", fileUtilsMethods)
+
+func init() {
+	classes.Set("FileUtils", fileUtilsModule)
+}
+
+var fileUtilsMethods = map[string]RubyMethod{
+	"pwd":      withArity(0, publicMethod(fileUtilsPwd)),
+	"mkdir":    withArityRange(1, 2, publicMethod(fileUtilsMkdir)),
+	"mkdir_p":  withArityRange(1, 2, publicMethod(fileUtilsMkdirP)),
+	"makedirs": withArityRange(1, 2, publicMethod(fileUtilsMkdirP)),
+	"mkpath":   withArityRange(1, 2, publicMethod(fileUtilsMkdirP)),
+	"cp":       withArityRange(2, 3, publicMethod(fileUtilsCp)),
+	"copy":     withArityRange(2, 3, publicMethod(fileUtilsCp)),
+	"cp_r":     withArityRange(2, 3, publicMethod(fileUtilsCpR)),
+	"mv":       withArityRange(2, 3, publicMethod(fileUtilsMv)),
+	"move":     withArityRange(2, 3, publicMethod(fileUtilsMv)),
+	"rm":       withArityRange(1, 2, publicMethod(fileUtilsRm)),
+	"remove":   withArityRange(1, 2, publicMethod(fileUtilsRm)),
+	"rm_f":     withArityRange(1, 2, publicMethod(fileUtilsRmF)),
+	"rm_r":     withArityRange(1, 2, publicMethod(fileUtilsRmR)),
+	"rm_rf":    withArityRange(1, 2, publicMethod(fileUtilsRmRF)),
+	"rmtree":   withArityRange(1, 2, publicMethod(fileUtilsRmRF)),
+	"touch":    withArityRange(1, 2, publicMethod(fileUtilsTouch)),
+	"chmod":    withArityRange(2, 3, publicMethod(fileUtilsChmod)),
+}
+
+// fileUtilsOptions are the options common to the FileUtils methods
+type fileUtilsOptions struct {
+	// noop skips the operations, which are still reported with verbose
+	noop bool
+	// verbose writes the operations like shell commands to stderr
+	verbose bool
+	// force ignores missing files
+	force bool
+	// mode are the permissions of directories created, if set
+	mode fs.FileMode
+	// mtime is the modification time touch sets, the current time if zero
+	mtime time.Time
+}
+
+// fileUtilsArguments splits args into the positional arguments and the
+// options of a trailing Hash, raising an ArgumentError for unknown options
+func fileUtilsArguments(args []RubyObject, allowed ...string) ([]RubyObject, fileUtilsOptions, error) {
+	args, _ = extractBlock(args)
+	var options fileUtilsOptions
+	if len(args) == 0 {
+		return args, options, nil
+	}
+	hash, ok := args[len(args)-1].(*Hash)
+	if !ok {
+		return args, options, nil
+	}
+	args = args[:len(args)-1]
+	keys, values := hash.Keys(), hash.Values()
+	for i, key := range keys {
+		name := toS(key)
+		if !containsString(append(allowed, "noop", "verbose"), name) {
+			return nil, options, NewArgumentError("unknown keyword: :%s", name)
+		}
+		switch name {
+		case "noop":
+			options.noop = isTruthy(values[i])
+		case "verbose":
+			options.verbose = isTruthy(values[i])
+		case "force":
+			options.force = isTruthy(values[i])
+		case "mode":
+			mode, err := integerArgument(values[i])
+			if err != nil {
+				return nil, options, err
+			}
+			options.mode = fs.FileMode(mode.Value) & fs.ModePerm
+		case "mtime":
+			t, ok := values[i].(*Time)
+			if !ok && values[i] != NIL {
+				return nil, options, NewTypeError("can't convert %s into time", className(values[i]))
+			}
+			if ok {
+				options.mtime = t.Value
+			}
+		}
+	}
+	return args, options, nil
+}
+
+// containsString reports whether s is one of list
+func containsString(list []string, s string) bool {
+	for _, element := range list {
+		if element == s {
+			return true
+		}
+	}
+	return false
+}
+
+// fileUtilsPaths returns the path of a String or the paths of an Array of
+// Strings, like the lists FileUtils methods accept
+func fileUtilsPaths(arg RubyObject) ([]string, error) {
+	if array, ok := arg.(*Array); ok {
+		paths := make([]string, len(array.Elements))
+		for i, element := range array.Elements {
+			path, err := stringArgument(element)
+			if err != nil {
+				return nil, err
+			}
+			paths[i] = path.Value
+		}
+		return paths, nil
+	}
+	path, err := stringArgument(arg)
+	if err != nil {
+		return nil, err
+	}
+	return []string{path.Value}, nil
+}
+
+// fileUtilsList returns the paths of arg as Ruby returns them, which is an
+// Array for an Array argument and the String otherwise
+func fileUtilsList(arg RubyObject, paths []string) RubyObject {
+	if _, ok := arg.(*Array); ok {
+		elements := make([]RubyObject, len(paths))
+		for i, path := range paths {
+			elements[i] = &String{Value: path}
+		}
+		return NewArray(elements...)
+	}
+	return arg
+}
+
+// report writes the command to stderr if the verbose option is set
+func (o fileUtilsOptions) report(command string, args ...string) {
+	if o.verbose {
+		fmt.Fprintln(Stderr, command+" "+strings.Join(args, " "))
+	}
+}
+
+// chmod sets the permissions of the directory created at path to the mode
+// option, if any, which are restricted by the umask otherwise
+func (o fileUtilsOptions) chmod(path string) error {
+	if o.mode == 0 {
+		return nil
+	}
+	if err := os.Chmod(path, o.mode); err != nil {
+		return NewSystemCallError(err)
+	}
+	return nil
+}
+
+func fileUtilsPwd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, NewSystemCallError(err)
+	}
+	return &String{Value: dir}, nil
+}
+
+// fileUtilsMkdir creates the directories, raising Errno::EEXIST if one
+// exists already
+func fileUtilsMkdir(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args, "mode")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	options.report("mkdir", paths...)
+	if options.noop {
+		return fileUtilsList(args[0], paths), nil
+	}
+	for _, path := range paths {
+		if err := os.Mkdir(path, 0777); err != nil {
+			return nil, NewSystemCallError(err)
+		}
+		if err := options.chmod(path); err != nil {
+			return nil, err
+		}
+	}
+	return fileUtilsList(args[0], paths), nil
+}
+
+// fileUtilsMkdirP creates the directories and their missing parents
+func fileUtilsMkdirP(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args, "mode")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	options.report("mkdir -p", paths...)
+	if options.noop {
+		return fileUtilsList(args[0], paths), nil
+	}
+	for _, path := range paths {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			return nil, NewSystemCallError(err)
+		}
+		if err := options.chmod(path); err != nil {
+			return nil, err
+		}
+	}
+	return fileUtilsList(args[0], paths), nil
+}
+
+// fileUtilsDestination returns the path src is copied or moved to, which
+// is within dest if dest is a directory
+func fileUtilsDestination(src, dest string) string {
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		return filepath.Join(dest, filepath.Base(src))
+	}
+	return dest
+}
+
+// fileUtilsCp copies the files to dest, which must be a directory when
+// copying multiple files
+func fileUtilsCp(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsCopy("cp", false, args)
+}
+
+// fileUtilsCpR copies the files and directories with their contents to
+// dest
+func fileUtilsCpR(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsCopy("cp -r", true, args)
+}
+
+func fileUtilsCopy(command string, recursive bool, args []RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	sources, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	dest, err := stringArgument(args[1])
+	if err != nil {
+		return nil, err
+	}
+	options.report(command, append(sources, dest.Value)...)
+	if options.noop {
+		return NIL, nil
+	}
+	if err := checkDirectoryDestination(sources, dest.Value); err != nil {
+		return nil, err
+	}
+	for _, src := range sources {
+		target := fileUtilsDestination(src, dest.Value)
+		info, err := os.Stat(src)
+		if err != nil {
+			return nil, NewSystemCallError(err)
+		}
+		if info.IsDir() {
+			if !recursive {
+				return nil, NewSystemCallError(&os.PathError{Op: "cp", Path: src, Err: syscall.EISDIR})
+			}
+			err = copyTree(src, target)
+		} else {
+			err = copyFile(src, target, info.Mode())
+		}
+		if err != nil {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	return NIL, nil
+}
+
+// checkDirectoryDestination raises Errno::ENOTDIR if multiple sources are
+// to be copied or moved to something else than a directory
+func checkDirectoryDestination(sources []string, dest string) error {
+	if len(sources) < 2 {
+		return nil
+	}
+	if info, err := os.Stat(dest); err != nil || !info.IsDir() {
+		return NewSystemCallError(&os.PathError{Op: "stat", Path: dest, Err: syscall.ENOTDIR})
+	}
+	return nil
+}
+
+// copyFile copies the contents of src to dest, created with mode if it does
+// not exist
+func copyFile(src, dest string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyTree copies the directory src with its contents to dest
+func copyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode())
+		}
+	})
+}
+
+// fileUtilsMv moves the files and directories to dest, copying them if
+// they are on another file system
+func fileUtilsMv(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args, "force")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	sources, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	dest, err := stringArgument(args[1])
+	if err != nil {
+		return nil, err
+	}
+	options.report("mv", append(sources, dest.Value)...)
+	if options.noop {
+		return NIL, nil
+	}
+	if err := checkDirectoryDestination(sources, dest.Value); err != nil {
+		return nil, err
+	}
+	for _, src := range sources {
+		target := fileUtilsDestination(src, dest.Value)
+		err := os.Rename(src, target)
+		if errors.Is(err, syscall.EXDEV) {
+			err = copyTree(src, target)
+			if err == nil {
+				err = os.RemoveAll(src)
+			}
+		}
+		if err != nil && !(options.force && errors.Is(err, fs.ErrNotExist)) {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	return NIL, nil
+}
+
+// fileUtilsRm removes the files, ignoring missing ones with the force
+// option
+func fileUtilsRm(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsRemove("rm", false, false, args)
+}
+
+func fileUtilsRmF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsRemove("rm -f", false, true, args)
+}
+
+// fileUtilsRmR removes the files and the directories with their contents
+func fileUtilsRmR(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsRemove("rm -r", true, false, args)
+}
+
+func fileUtilsRmRF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return fileUtilsRemove("rm -rf", true, true, args)
+}
+
+func fileUtilsRemove(command string, recursive, force bool, args []RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args, "force")
+	if err != nil {
+		return nil, err
+	}
+	force = force || options.force
+	paths, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	options.report(command, paths...)
+	if options.noop {
+		return fileUtilsList(args[0], paths), nil
+	}
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		switch {
+		case err != nil:
+		case info.IsDir() && !recursive:
+			err = &os.PathError{Op: "unlink", Path: path, Err: syscall.EISDIR}
+		case info.IsDir():
+			err = os.RemoveAll(path)
+		default:
+			err = os.Remove(path)
+		}
+		if err != nil && !(force && errors.Is(err, fs.ErrNotExist)) {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	return fileUtilsList(args[0], paths), nil
+}
+
+// fileUtilsTouch creates the files if they are missing and sets their
+// modification time to the mtime option or the current time
+func fileUtilsTouch(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args, "mtime")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := fileUtilsPaths(args[0])
+	if err != nil {
+		return nil, err
+	}
+	options.report("touch", paths...)
+	if options.noop {
+		return fileUtilsList(args[0], paths), nil
+	}
+	mtime := options.mtime
+	if mtime.IsZero() {
+		mtime = time.Now()
+	}
+	for _, path := range paths {
+		err := os.Chtimes(path, mtime, mtime)
+		if errors.Is(err, fs.ErrNotExist) {
+			var file *os.File
+			if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0666); err == nil {
+				file.Close()
+				err = os.Chtimes(path, mtime, mtime)
+			}
+		}
+		if err != nil {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	return fileUtilsList(args[0], paths), nil
+}
+
+// fileUtilsChmod changes the permissions of the files to mode, an Integer
+// like 0755 or a symbolic mode like "u+x" or "go-w"
+func fileUtilsChmod(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, options, err := fileUtilsArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	paths, err := fileUtilsPaths(args[1])
+	if err != nil {
+		return nil, err
+	}
+	var mode func(fs.FileMode) (fs.FileMode, error)
+	var modeName string
+	switch arg := args[0].(type) {
+	case *Integer:
+		modeName = strconv.FormatInt(arg.Value, 8)
+		mode = func(fs.FileMode) (fs.FileMode, error) { return fs.FileMode(arg.Value) & fs.ModePerm, nil }
+	case *String:
+		modeName = arg.Value
+		mode = func(current fs.FileMode) (fs.FileMode, error) { return symbolicMode(arg.Value, current) }
+	default:
+		return nil, NewImplicitConversionTypeError(NewInteger(0), args[0])
+	}
+	options.report("chmod "+modeName, paths...)
+	if options.noop {
+		return fileUtilsList(args[1], paths), nil
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, NewSystemCallError(err)
+		}
+		perm, err := mode(info.Mode().Perm())
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	return fileUtilsList(args[1], paths), nil
+}
+
+// symbolicMode applies the comma separated clauses of a symbolic mode like
+// "u+x,go-w" to the permissions current
+func symbolicMode(mode string, current fs.FileMode) (fs.FileMode, error) {
+	invalid := NewArgumentError("invalid file mode: %s", mode)
+	for _, clause := range strings.Split(mode, ",") {
+		op := strings.IndexAny(clause, "+-=")
+		if op < 0 {
+			return 0, invalid
+		}
+		var who fs.FileMode
+		for _, c := range clause[:op] {
+			switch c {
+			case 'u':
+				who |= 0700
+			case 'g':
+				who |= 0070
+			case 'o':
+				who |= 0007
+			case 'a':
+				who |= 0777
+			default:
+				return 0, invalid
+			}
+		}
+		if who == 0 {
+			who = 0777
+		}
+		var perm fs.FileMode
+		for _, c := range clause[op+1:] {
+			switch c {
+			case 'r':
+				perm |= 0444
+			case 'w':
+				perm |= 0222
+			case 'x':
+				perm |= 0111
+			default:
+				return 0, invalid
+			}
+		}
+		perm &= who
+		switch clause[op] {
+		case '+':
+			current |= perm
+		case '-':
+			current &^= perm
+		case '=':
+			current = current&^who | perm
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,135 @@
+package object
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFileUtilsMkdirP(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+
+	result, err := Send(fileUtilsModule, "mkdir_p", &String{Value: dir})
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: dir})
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Expected %s to be created, got %v", dir, err)
+	}
+
+	_, err = Send(fileUtilsModule, "mkdir", &String{Value: dir})
+	if err, ok := err.(*SystemCallError); !ok || err.Class() != errnoClasses[syscall.EEXIST] {
+		t.Errorf("Expected Errno::EEXIST, got %v", err)
+	}
+}
+
+func TestFileUtilsCopyAndMove(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("content"), 0644)
+
+	_, err := Send(fileUtilsModule, "cp", &String{Value: src}, &String{Value: filepath.Join(dir, "copy")})
+	if _, ok := err.(*SystemCallError); !ok {
+		t.Errorf("Expected cp of a directory to fail, got %v", err)
+	}
+
+	_, err = Send(fileUtilsModule, "cp_r", &String{Value: src}, &String{Value: filepath.Join(dir, "copy")})
+	checkError(t, err, nil)
+	checkFileContent(t, filepath.Join(dir, "copy", "sub", "file.txt"), "content")
+
+	_, err = Send(fileUtilsModule, "cp", &String{Value: filepath.Join(src, "sub", "file.txt")}, &String{Value: dir})
+	checkError(t, err, nil)
+	checkFileContent(t, filepath.Join(dir, "file.txt"), "content")
+
+	_, err = Send(fileUtilsModule, "mv", &String{Value: filepath.Join(dir, "file.txt")}, &String{Value: filepath.Join(dir, "moved.txt")})
+	checkError(t, err, nil)
+	checkFileContent(t, filepath.Join(dir, "moved.txt"), "content")
+	if _, err := os.Stat(filepath.Join(dir, "file.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected the moved file to be gone, got %v", err)
+	}
+}
+
+func TestFileUtilsRemove(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	tree := filepath.Join(dir, "tree")
+	os.WriteFile(file, nil, 0644)
+	os.MkdirAll(filepath.Join(tree, "sub"), 0755)
+
+	_, err := Send(fileUtilsModule, "rm", &String{Value: tree})
+	if err, ok := err.(*SystemCallError); !ok || err.Class() != errnoClasses[syscall.EISDIR] {
+		t.Errorf("Expected Errno::EISDIR, got %v", err)
+	}
+
+	paths := NewArray(&String{Value: file}, &String{Value: tree})
+	result, err := Send(fileUtilsModule, "rm_rf", paths)
+	checkError(t, err, nil)
+	checkResult(t, result, paths)
+	for _, path := range []string{file, tree} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+
+	_, err = Send(fileUtilsModule, "rm_f", &String{Value: file})
+	checkError(t, err, nil)
+	_, err = Send(fileUtilsModule, "rm", &String{Value: file})
+	if err, ok := err.(*SystemCallError); !ok || err.Class() != errnoClasses[syscall.ENOENT] {
+		t.Errorf("Expected Errno::ENOENT, got %v", err)
+	}
+}
+
+func TestFileUtilsTouchAndChmod(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	options := NewHash(nil)
+	options.Set(NewSymbol("mtime"), NewTime(mtime))
+
+	_, err := Send(fileUtilsModule, "touch", &String{Value: file}, options)
+	checkError(t, err, nil)
+	info, err := os.Stat(file)
+	if err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("Expected %s to be created with mtime %s, got %v", file, mtime, err)
+	}
+
+	_, err = Send(fileUtilsModule, "chmod", NewInteger(0600), &String{Value: file})
+	checkError(t, err, nil)
+	_, err = Send(fileUtilsModule, "chmod", &String{Value: "u+x,g+r"}, &String{Value: file})
+	checkError(t, err, nil)
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0740 {
+		t.Errorf("Expected mode 0740, got %o", info.Mode().Perm())
+	}
+
+	_, err = Send(fileUtilsModule, "chmod", &String{Value: "u+z"}, &String{Value: file})
+	checkError(t, err, NewArgumentError("invalid file mode: u+z"))
+}
+
+func TestFileUtilsNoop(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dir")
+	options := NewHash(nil)
+	options.Set(NewSymbol("noop"), TRUE)
+
+	_, err := Send(fileUtilsModule, "mkdir_p", &String{Value: dir}, options)
+	checkError(t, err, nil)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s not to be created, got %v", dir, err)
+	}
+
+	options.Set(NewSymbol("force"), TRUE)
+	_, err = Send(fileUtilsModule, "mkdir_p", &String{Value: dir}, options)
+	checkError(t, err, NewArgumentError("unknown keyword: :force"))
+}
+
+func checkFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("Expected %s to exist, got %v", path, err)
+		return
+	}
+	if string(content) != expected {
+		t.Errorf("Expected %s to contain %q, got %q", path, expected, content)
+	}
+}
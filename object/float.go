@@ -0,0 +1,128 @@
+package object
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+var floatClass RubyClassObject = newClass("Float", numericClass, floatMethods, floatClassMethods)
+
+func init() {
+	classes.Set("Float", floatClass)
+	setConstant(floatClass, "INFINITY", NewFloat(math.Inf(1)))
+	setConstant(floatClass, "NAN", NewFloat(math.NaN()))
+}
+
+// NewFloat returns a new Float with the given value
+func NewFloat(value float64) *Float {
+	allocatedFloats.add()
+	return &Float{Value: value}
+}
+
+// Float represents a float in Ruby
+type Float struct {
+	Value float64
+}
+
+// Inspect returns the value as string, formatted the way Ruby does it
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsNaN(f.Value):
+		return "NaN"
+	case math.IsInf(f.Value, 1):
+		return "Infinity"
+	case math.IsInf(f.Value, -1):
+		return "-Infinity"
+	}
+	abs := math.Abs(f.Value)
+	if abs != 0 && (abs >= 1e16 || abs < 1e-4) {
+		formatted := strconv.FormatFloat(f.Value, 'e', -1, 64)
+		parts := strings.SplitN(formatted, "e", 2)
+		if !strings.Contains(parts[0], ".") {
+			parts[0] += ".0"
+		}
+		return parts[0] + "e" + parts[1]
+	}
+	formatted := strconv.FormatFloat(f.Value, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// Type returns FLOAT_OBJ
+func (f *Float) Type() Type { return FLOAT_OBJ }
+
+// Class returns floatClass
+func (f *Float) Class() RubyClass { return floatClass }
+
+func (f *Float) hashKey() hashKey {
+	return hashKey{Type: f.Type(), Value: f.Value}
+}
+
+// toFloat returns the float value of obj if it is an Integer or a Float
+func toFloat(obj RubyObject) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+var floatClassMethods = map[string]RubyMethod{}
+
+var floatMethods = map[string]RubyMethod{
+	"<=>":    withArity(1, publicMethod(floatSpaceship)),
+	"%":      withArity(1, publicMethod(floatModuloMethod)),
+	"modulo": withArity(1, publicMethod(floatModuloMethod)),
+	"**":     withArity(1, publicMethod(floatPow)),
+	"to_i":   withArity(0, publicMethod(floatToI)),
+	"to_f":   withArity(0, publicMethod(floatToF)),
+}
+
+func floatModuloMethod(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	f := context.(*Float)
+	divisor, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], f)
+	}
+	return NewFloat(floatModulo(f.Value, divisor)), nil
+}
+
+func floatPow(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	f := context.(*Float)
+	exponent, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], f)
+	}
+	return NewFloat(math.Pow(f.Value, exponent)), nil
+}
+
+func floatSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	f := context.(*Float)
+	other, ok := toFloat(args[0])
+	if !ok || math.IsNaN(f.Value) || math.IsNaN(other) {
+		return NIL, nil
+	}
+	switch {
+	case f.Value < other:
+		return NewInteger(-1), nil
+	case f.Value > other:
+		return NewInteger(1), nil
+	default:
+		return NewInteger(0), nil
+	}
+}
+
+func floatToI(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	f := context.(*Float)
+	return NewInteger(int64(f.Value)), nil
+}
+
+func floatToF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context, nil
+}
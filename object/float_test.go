@@ -0,0 +1,54 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1, "1.0"},
+		{1.5, "1.5"},
+		{-0.25, "-0.25"},
+		{0, "0.0"},
+		{1.5e10, "15000000000.0"},
+		{1e16, "1.0e+16"},
+		{1.25e-5, "1.25e-05"},
+		{0.0001, "0.0001"},
+		{math.Inf(1), "Infinity"},
+		{math.Inf(-1), "-Infinity"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		actual := NewFloat(tt.value).Inspect()
+
+		if actual != tt.expected {
+			t.Logf("Expected float to inspect to %q, got %q", tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestFloatSpaceship(t *testing.T) {
+	tests := []struct {
+		arg      RubyObject
+		expected RubyObject
+	}{
+		{NewFloat(2), NewInteger(-1)},
+		{NewInteger(1), NewInteger(1)},
+		{NewFloat(1.5), NewInteger(0)},
+		{&String{Value: "a"}, NIL},
+		{NewFloat(math.NaN()), NIL},
+	}
+
+	for _, tt := range tests {
+		result, err := floatSpaceship(NewFloat(1.5), tt.arg)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
@@ -0,0 +1,477 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sprintf formats args according to the format string format, as known from
+// Kernel#sprintf. Arguments are referenced in order, by position like `%1$s`
+// or by name like `%<name>s` and `%{name}`, which requires a single Hash
+// argument.
+func Sprintf(format string, args []RubyObject) (string, error) {
+	f := &formatter{args: args}
+	var out bytes.Buffer
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+		formatted, n, err := f.directive(format[i+1:])
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(formatted)
+		i += n
+	}
+	return out.String(), nil
+}
+
+// formatter keeps track of the arguments referenced by a format string
+type formatter struct {
+	args     []RubyObject
+	next     int  // the number of arguments referenced in order
+	numbered bool // whether arguments have been referenced by position
+	named    bool // whether arguments have been referenced by name
+}
+
+// formatSpec holds the flags, width and precision of a format directive
+type formatSpec struct {
+	minus, plus, space, zero, sharp bool
+	width                           int
+	precision                       int // negative if there is none
+}
+
+// directive formats the directive at the start of format, which follows a
+// `%`. It returns the formatted argument and the number of bytes consumed.
+func (f *formatter) directive(format string) (string, int, error) {
+	spec := formatSpec{precision: -1}
+	var arg RubyObject
+	for i := 0; i < len(format); i++ {
+		var err error
+		switch c := format[i]; {
+		case c == ' ':
+			spec.space = true
+		case c == '+':
+			spec.plus = true
+		case c == '-':
+			spec.minus = true
+		case c == '0':
+			spec.zero = true
+		case c == '#':
+			spec.sharp = true
+		case '1' <= c && c <= '9':
+			n, end := scanFormatNumber(format, i)
+			if end < len(format) && format[end] == '$' {
+				arg, err = f.positional(n)
+				i = end
+				break
+			}
+			spec.width = n
+			i = end - 1
+		case c == '*':
+			var width int
+			width, i, err = f.starArgument(format, i)
+			if width < 0 {
+				spec.minus = true
+				width = -width
+			}
+			spec.width = width
+		case c == '.':
+			spec.precision = 0
+			if i+1 < len(format) && format[i+1] == '*' {
+				spec.precision, i, err = f.starArgument(format, i+1)
+				break
+			}
+			spec.precision, i = scanFormatNumber(format, i+1)
+			i--
+		case c == '<' || c == '{':
+			closing := map[byte]byte{'<': '>', '{': '}'}[c]
+			end := strings.IndexByte(format[i:], closing)
+			if end < 0 {
+				return "", 0, NewArgumentError("malformed name - unmatched parenthesis")
+			}
+			name := format[i : i+end+1]
+			arg, err = f.namedArgument(name)
+			i += end
+			if err == nil && c == '{' {
+				return spec.pad(toS(arg)), i + 1, nil
+			}
+		case c == '%':
+			return "%", i + 1, nil
+		default:
+			if arg == nil {
+				arg, err = f.nextArgument()
+			}
+			if err != nil {
+				return "", 0, err
+			}
+			formatted, err := formatArgument(c, spec, arg)
+			return formatted, i + 1, err
+		}
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	return "", 0, NewArgumentError("incomplete format specifier; use %%%% (double %%) instead")
+}
+
+// scanFormatNumber returns the number starting at format[start] and the
+// position following it
+func scanFormatNumber(format string, start int) (int, int) {
+	end := start
+	for end < len(format) && '0' <= format[end] && format[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(format[start:end])
+	return n, end
+}
+
+// starArgument returns the Integer argument given for the `*` at
+// format[star], which is either the next one or the one referenced by a
+// following position like `*2$`. It also returns the position of the last
+// consumed byte.
+func (f *formatter) starArgument(format string, star int) (int, int, error) {
+	var arg RubyObject
+	var err error
+	n, end := scanFormatNumber(format, star+1)
+	if end > star+1 && end < len(format) && format[end] == '$' {
+		arg, err = f.positional(n)
+		star = end
+	} else {
+		arg, err = f.nextArgument()
+	}
+	if err != nil {
+		return 0, star, err
+	}
+	value, err := integerArgument(arg)
+	if err != nil {
+		return 0, star, err
+	}
+	return int(value.Value), star, nil
+}
+
+func (f *formatter) nextArgument() (RubyObject, error) {
+	if f.numbered {
+		return nil, NewArgumentError("unnumbered(%d) mixed with numbered", f.next+1)
+	}
+	if f.named {
+		return nil, NewArgumentError("unnumbered(%d) mixed with named", f.next+1)
+	}
+	if f.next >= len(f.args) {
+		return nil, NewArgumentError("too few arguments")
+	}
+	f.next++
+	return f.args[f.next-1], nil
+}
+
+func (f *formatter) positional(n int) (RubyObject, error) {
+	if f.next > 0 {
+		return nil, NewArgumentError("numbered(%d) after unnumbered(%d)", n, f.next)
+	}
+	if f.named {
+		return nil, NewArgumentError("numbered(%d) after named", n)
+	}
+	if n > len(f.args) {
+		return nil, NewArgumentError("too few arguments")
+	}
+	f.numbered = true
+	return f.args[n-1], nil
+}
+
+func (f *formatter) namedArgument(name string) (RubyObject, error) {
+	if f.next > 0 {
+		return nil, NewArgumentError("named%s after unnumbered(%d)", name, f.next)
+	}
+	if f.numbered {
+		return nil, NewArgumentError("named%s after numbered", name)
+	}
+	if len(f.args) != 1 {
+		return nil, NewArgumentError("one hash required")
+	}
+	hash, ok := f.args[0].(*Hash)
+	if !ok {
+		return nil, NewArgumentError("one hash required")
+	}
+	f.named = true
+	key := NewSymbol(name[1 : len(name)-1])
+	value, ok := hash.Get(key)
+	if !ok {
+		return nil, NewKeyError(key)
+	}
+	return value, nil
+}
+
+// formatArgument formats arg according to the conversion character c
+func formatArgument(c byte, spec formatSpec, arg RubyObject) (string, error) {
+	switch c {
+	case 'd', 'i', 'u', 'b', 'B', 'o', 'x', 'X':
+		value, err := kernelInteger(NIL, arg)
+		if err != nil {
+			return "", err
+		}
+		return spec.formatInteger(value.(*Integer).Value, c), nil
+	case 'f', 'e', 'E', 'g', 'G', 'a', 'A':
+		value, err := floatArgument(arg)
+		if err != nil {
+			return "", err
+		}
+		return spec.formatFloat(value, c), nil
+	case 'c':
+		switch arg := arg.(type) {
+		case *Integer:
+			return spec.pad(string(rune(arg.Value))), nil
+		case *String:
+			r, _ := utf8.DecodeRuneInString(arg.Value)
+			return spec.pad(string(r)), nil
+		default:
+			return "", NewImplicitConversionTypeError(&Integer{}, arg)
+		}
+	case 's':
+		return spec.pad(spec.truncate(toS(arg))), nil
+	case 'p':
+		return spec.pad(spec.truncate(inspect(arg))), nil
+	default:
+		return "", NewArgumentError("malformed format string - %%%c", c)
+	}
+}
+
+// floatArgument converts arg into a float like Kernel#Float
+func floatArgument(arg RubyObject) (float64, error) {
+	if value, ok := toFloat(arg); ok {
+		return value, nil
+	}
+	str, ok := arg.(*String)
+	if !ok {
+		return 0, NewTypeError("can't convert %s into Float", comparisonOperandName(arg))
+	}
+	value, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(str.Value), "_", "", -1), 64)
+	if err != nil {
+		return 0, NewArgumentError("invalid value for Float(): %q", str.Value)
+	}
+	return value, nil
+}
+
+// pad pads s with spaces up to the width of the spec
+func (spec formatSpec) pad(s string) string {
+	n := spec.width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	if spec.minus {
+		return s + strings.Repeat(" ", n)
+	}
+	return strings.Repeat(" ", n) + s
+}
+
+// truncate shortens s to the precision of the spec, if any
+func (spec formatSpec) truncate(s string) string {
+	if spec.precision < 0 || utf8.RuneCountInString(s) <= spec.precision {
+		return s
+	}
+	return string([]rune(s)[:spec.precision])
+}
+
+// sign returns the sign to print for a number
+func (spec formatSpec) sign(negative bool) string {
+	switch {
+	case negative:
+		return "-"
+	case spec.plus:
+		return "+"
+	case spec.space:
+		return " "
+	default:
+		return ""
+	}
+}
+
+// zeroPadding returns the number of characters to fill with zeros so that
+// a number of the given length fills the width of the spec
+func (spec formatSpec) zeroPadding(length int) int {
+	if !spec.zero || spec.minus || spec.width <= length {
+		return 0
+	}
+	return spec.width - length
+}
+
+var integerFormats = map[byte]struct {
+	base   int
+	prefix string
+}{
+	'd': {10, ""}, 'i': {10, ""}, 'u': {10, ""},
+	'b': {2, "0b"}, 'B': {2, "0B"},
+	'o': {8, "0"},
+	'x': {16, "0x"}, 'X': {16, "0X"},
+}
+
+// formatInteger formats value in the base given by c. Negative values in
+// other bases than 10 are shown in two's complement like `..f01`, unless a
+// sign is requested by the spec.
+func (spec formatSpec) formatInteger(value int64, c byte) string {
+	format := integerFormats[c]
+	prefix := format.prefix
+	if !spec.sharp || value == 0 {
+		prefix = ""
+	}
+	twosComplement := value < 0 && format.base != 10 && !spec.plus && !spec.space
+	var digits, sign, dots string
+	var fill byte = '0'
+	if twosComplement {
+		digits = twosComplementDigits(value, format.base)
+		fill = digits[0]
+		dots = ".."
+	} else {
+		magnitude := uint64(value)
+		if value < 0 {
+			magnitude = uint64(-(value + 1)) + 1
+		}
+		digits = strconv.FormatUint(magnitude, format.base)
+		sign = spec.sign(value < 0)
+	}
+	if c == 'o' && strings.HasPrefix(digits, "0") {
+		prefix = ""
+	}
+	var padding int
+	if spec.precision >= 0 {
+		padding = spec.precision - len(dots) - len(digits)
+	} else {
+		padding = spec.zeroPadding(len(sign) + len(prefix) + len(dots) + len(digits))
+	}
+	if padding > 0 {
+		digits = strings.Repeat(string(fill), padding) + digits
+	}
+	formatted := sign + prefix + dots + digits
+	if c == 'X' {
+		formatted = strings.ToUpper(formatted)
+	}
+	return spec.pad(formatted)
+}
+
+// twosComplementDigits returns the digits of the negative value in two's
+// complement, starting with a single digit representing the infinite
+// sequence of leading digits
+func twosComplementDigits(value int64, base int) string {
+	complement := new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(64), nil)
+	complement.Add(complement, big.NewInt(value))
+	maxDigit := strconv.FormatInt(int64(base-1), base)
+	return maxDigit + strings.TrimLeft(complement.Text(base), maxDigit)
+}
+
+// formatFloat formats value in the notation given by c
+func (spec formatSpec) formatFloat(value float64, c byte) string {
+	negative := value < 0 || value == 0 && math.Signbit(value)
+	sign := spec.sign(negative)
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		body := "Inf"
+		if math.IsNaN(value) {
+			body, sign = "NaN", spec.sign(false)
+		}
+		return spec.pad(sign + body)
+	}
+	value = math.Abs(value)
+	precision := spec.precision
+	if precision < 0 && c != 'a' && c != 'A' {
+		precision = 6
+	}
+	var body, prefix string
+	switch c {
+	case 'f':
+		body = strconv.FormatFloat(value, 'f', precision, 64)
+		if spec.sharp && precision == 0 {
+			body += "."
+		}
+	case 'e', 'E':
+		body = strconv.FormatFloat(value, 'e', precision, 64)
+		if spec.sharp && precision == 0 {
+			body = strings.Replace(body, "e", ".e", 1)
+		}
+	case 'g', 'G':
+		if precision == 0 {
+			precision = 1
+		}
+		verb := "%.*g"
+		if spec.sharp {
+			verb = "%#.*g"
+		}
+		body = fmt.Sprintf(verb, precision, value)
+	case 'a', 'A':
+		body = strconv.FormatFloat(value, 'x', precision, 64)
+		mantissa, exponent := body[2:strings.IndexByte(body, 'p')], body[strings.IndexByte(body, 'p')+1:]
+		exponentValue, _ := strconv.Atoi(exponent)
+		prefix, body = "0x", fmt.Sprintf("%sp%+d", mantissa, exponentValue)
+	}
+	if padding := spec.zeroPadding(len(sign) + len(prefix) + len(body)); padding > 0 {
+		body = strings.Repeat("0", padding) + body
+	}
+	formatted := sign + prefix + body
+	if c == 'E' || c == 'G' || c == 'A' {
+		formatted = strings.ToUpper(formatted)
+	}
+	return spec.pad(formatted)
+}
+
+func kernelSprintf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	format, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := Sprintf(format.Value, args[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: formatted}, nil
+}
+
+// kernelPrintf writes the formatted arguments to Stdout. If the first
+// argument is no String it is taken as the IO to write to instead.
+func kernelPrintf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return printfTo(DefaultStreams(), args...)
+}
+
+func printfTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 {
+		return NIL, nil
+	}
+	var target RubyObject
+	if _, ok := args[0].(*String); !ok {
+		target, args = args[0], args[1:]
+		if len(args) == 0 {
+			return nil, NewArgumentError("too few arguments")
+		}
+	}
+	formatted, err := kernelSprintf(NIL, args...)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil {
+		if _, err := Send(target, "write", formatted); err != nil {
+			return nil, err
+		}
+		return NIL, nil
+	}
+	fmt.Fprint(streams.Stdout, formatted.(*String).Value)
+	return NIL, nil
+}
+
+// stringFormat formats the argument, or the elements of an Array argument,
+// according to the string
+func stringFormat(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	formatArgs := args
+	if array, ok := args[0].(*Array); ok {
+		formatArgs = array.Elements
+	}
+	formatted, err := Sprintf(str.Value, formatArgs)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: formatted}, nil
+}
@@ -0,0 +1,91 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSprintf(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	hash := NewHash(nil)
+	hash.Set(&Symbol{Value: "a"}, NewInteger(3))
+	hash.Set(&Symbol{Value: "b"}, str("x"))
+
+	tests := []struct {
+		format   string
+		args     []RubyObject
+		expected string
+	}{
+		{"%d|%5d|%-5d|%05d|%+d|% d", []RubyObject{NewInteger(1), NewInteger(2), NewInteger(3), NewInteger(4), NewInteger(5), NewInteger(6)}, "1|    2|3    |00004|+5| 6"},
+		{"%x|%#x|%X|%#o|%b|%#B|%o", []RubyObject{NewInteger(255), NewInteger(255), NewInteger(255), NewInteger(8), NewInteger(5), NewInteger(5), NewInteger(0)}, "ff|0xff|FF|010|101|0B101|0"},
+		{"%x|%#x|%+x|%b|%o", []RubyObject{NewInteger(-255), NewInteger(-255), NewInteger(-255), NewInteger(-5), NewInteger(-8)}, "..f01|0x..f01|-ff|..1011|..70"},
+		{"%20.8d|%20.8x|%-20.8x|%020x", []RubyObject{NewInteger(123), NewInteger(-11), NewInteger(-11), NewInteger(-11)}, "            00000123|            ..fffff5|..fffff5            |..fffffffffffffffff5"},
+		{"%d|%d|%i", []RubyObject{NewFloat(3.99), str("12"), NewInteger(-4)}, "3|12|-4"},
+		{"%f|%.2f|%10.3f|%-6.1f|%010.2f|%+.1f|%#.0f", []RubyObject{NewFloat(3.14159), NewFloat(3.14159), NewFloat(-2.5), NewInteger(1), NewFloat(-3.5), NewFloat(2), NewFloat(3)}, "3.141590|3.14|    -2.500|1.0   |-000003.50|+2.0|3."},
+		{"%e|%E|%.0e", []RubyObject{NewFloat(12345.678), NewFloat(0.00012), NewFloat(5)}, "1.234568e+04|1.200000E-04|5e+00"},
+		{"%g|%g|%g|%G|%.3g|%#g", []RubyObject{NewFloat(100000), NewFloat(1000000), NewFloat(0.0001), NewFloat(0.00001), NewFloat(3.14159), NewFloat(1)}, "100000|1e+06|0.0001|1E-05|3.14|1.00000"},
+		{"%a|%A|%a", []RubyObject{NewFloat(1), NewFloat(-0.5), NewFloat(3)}, "0x1p+0|-0X1P-1|0x1.8p+1"},
+		{"%f|%5.1f|%+f", []RubyObject{NewFloat(math.Inf(1)), NewFloat(math.NaN()), NewFloat(math.Inf(1))}, "Inf|  NaN|+Inf"},
+		{"%s|%5s|%-5s|%.2s|%p", []RubyObject{str("abc"), str("ab"), str("ab"), str("abc"), str("x")}, `abc|   ab|ab   |ab|"x"`},
+		{"%c|%c|%3c", []RubyObject{NewInteger(65), str("hello"), NewInteger(0x263A)}, "A|h|  ☺"},
+		{"100%%", nil, "100%"},
+		{"%2$s %1$s", []RubyObject{str("a"), str("b")}, "b a"},
+		{"%*d|%-*d|", []RubyObject{NewInteger(5), NewInteger(1), NewInteger(4), NewInteger(2)}, "    1|2   |"},
+		{"%.*f", []RubyObject{NewInteger(1), NewFloat(2.25)}, "2.2"},
+		{"%<a>d-%{b}|%<a>5.1f", []RubyObject{hash}, "3-x|  3.0"},
+	}
+
+	for _, tt := range tests {
+		result, err := Sprintf(tt.format, tt.args)
+
+		checkError(t, err, nil)
+		if result != tt.expected {
+			t.Logf("Expected %q to format to %q, got %q", tt.format, tt.expected, result)
+			t.Fail()
+		}
+	}
+}
+
+func TestSprintfErrors(t *testing.T) {
+	tests := []struct {
+		format   string
+		args     []RubyObject
+		expected error
+	}{
+		{"%d", nil, NewArgumentError("too few arguments")},
+		{"%", nil, NewArgumentError("incomplete format specifier; use %%%% (double %%) instead")},
+		{"%z", []RubyObject{NewInteger(1)}, NewArgumentError("malformed format string - %%z")},
+		{"%1$s %s", []RubyObject{NewInteger(1)}, NewArgumentError("unnumbered(1) mixed with numbered")},
+		{"%s %1$s", []RubyObject{NewInteger(1)}, NewArgumentError("numbered(1) after unnumbered(1)")},
+		{"%<a>s", []RubyObject{NewInteger(1)}, NewArgumentError("one hash required")},
+		{"%<a>s", []RubyObject{NewHash(nil)}, NewKeyError(&Symbol{Value: "a"})},
+		{"%d", []RubyObject{NIL}, NewTypeError("can't convert nil into Integer")},
+		{"%f", []RubyObject{NIL}, NewTypeError("can't convert nil into Float")},
+		{"%f", []RubyObject{&String{Value: "x"}}, NewArgumentError("invalid value for Float(): %q", "x")},
+	}
+
+	for _, tt := range tests {
+		_, err := Sprintf(tt.format, tt.args)
+
+		checkError(t, err, tt.expected)
+	}
+}
+
+func TestStringFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		arg      RubyObject
+		expected RubyObject
+	}{
+		{"%05.1f", NewFloat(3.14159), &String{Value: "003.1"}},
+		{"%s and %s", NewArray(NewInteger(1), NewInteger(2)), &String{Value: "1 and 2"}},
+		{"%p", NIL, &String{Value: "nil"}},
+	}
+
+	for _, tt := range tests {
+		result, err := stringFormat(&String{Value: tt.format}, tt.arg)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
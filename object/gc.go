@@ -0,0 +1,131 @@
+package object
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var gcModule = newModule("GC", gcMethods)
+
+func init() {
+	classes.Set("GC", gcModule)
+}
+
+// allocationCounter counts the objects of one kind created by the
+// interpreter, be it by constructors, literals, copies or loading them. The
+// counters are shared by all runtimes.
+type allocationCounter struct {
+	name  string
+	count int64
+}
+
+func (c *allocationCounter) add() { atomic.AddInt64(&c.count, 1) }
+
+func (c *allocationCounter) value() int64 { return atomic.LoadInt64(&c.count) }
+
+var (
+	allocatedArrays       = &allocationCounter{name: "allocated_arrays"}
+	allocatedHashes       = &allocationCounter{name: "allocated_hashes"}
+	allocatedIntegers     = &allocationCounter{name: "allocated_integers"}
+	allocatedFloats       = &allocationCounter{name: "allocated_floats"}
+	allocatedInstances    = &allocationCounter{name: "allocated_instances"}
+	allocatedReturnValues = &allocationCounter{name: "allocated_return_values"}
+	requestedReturnValues = &allocationCounter{name: "return_values"}
+)
+
+var allocationCounters = []*allocationCounter{
+	allocatedArrays, allocatedHashes, allocatedIntegers, allocatedFloats,
+	allocatedInstances, allocatedReturnValues, requestedReturnValues,
+}
+
+// returnValues pools the ReturnValues wrapping the values of return
+// statements, which live only until the method returned
+var returnValues = sync.Pool{New: func() interface{} {
+	allocatedReturnValues.add()
+	return &ReturnValue{}
+}}
+
+// NewReturnValue returns a ReturnValue wrapping value. It is taken from a
+// pool and should be handed back by ReleaseReturnValue once unwrapped.
+func NewReturnValue(value RubyObject) *ReturnValue {
+	requestedReturnValues.add()
+	rv := returnValues.Get().(*ReturnValue)
+	rv.Value = value
+	return rv
+}
+
+// ReleaseReturnValue returns the value wrapped by rv and puts rv back into
+// the pool. rv must not be used afterwards.
+func ReleaseReturnValue(rv *ReturnValue) RubyObject {
+	value := rv.Value
+	rv.Value = nil
+	returnValues.Put(rv)
+	return value
+}
+
+// gcStat returns the statistics of GC.stat. Besides the counters of the Go
+// runtime it reports the objects created by the interpreter by kind. The
+// return_values requested exceed the allocated_return_values by the ones
+// taken from the pool.
+func gcStat() map[string]int64 {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := map[string]int64{
+		"count":                   int64(mem.NumGC),
+		"total_allocated_objects": int64(mem.Mallocs),
+		"total_freed_objects":     int64(mem.Frees),
+		"heap_live_slots":         int64(mem.Mallocs - mem.Frees),
+		"heap_allocated_bytes":    int64(mem.HeapAlloc),
+		"total_allocated_bytes":   int64(mem.TotalAlloc),
+	}
+	for _, counter := range allocationCounters {
+		stats[counter.name] = counter.value()
+	}
+	return stats
+}
+
+var gcMethods = map[string]RubyMethod{
+	"stat":  withArityRange(0, 1, publicMethod(gcModuleStat)),
+	"count": withArity(0, publicMethod(gcModuleCount)),
+	"start": withArity(0, publicMethod(gcModuleStart)),
+}
+
+// gcModuleStat returns a Hash of the statistics by Symbol, or the value of
+// the single statistic named
+func gcModuleStat(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	stats := gcStat()
+	if len(args) == 1 {
+		name, err := nameArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		value, ok := stats[name]
+		if !ok {
+			return nil, NewArgumentError("unknown key: %s", name)
+		}
+		return NewInteger(value), nil
+	}
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := NewHash(nil)
+	for _, name := range names {
+		hash.Set(NewSymbol(name), NewInteger(stats[name]))
+	}
+	return hash, nil
+}
+
+func gcModuleCount(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return NewInteger(int64(mem.NumGC)), nil
+}
+
+func gcModuleStart(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	runtime.GC()
+	return NIL, nil
+}
@@ -0,0 +1,57 @@
+package object
+
+import "testing"
+
+func TestGCStat(t *testing.T) {
+	tests := []struct {
+		counter  string
+		allocate func() (RubyObject, error)
+	}{
+		{"allocated_arrays", func() (RubyObject, error) { return NewArray(), nil }},
+		{"allocated_arrays", func() (RubyObject, error) { return Send(NewArray(), "dup") }},
+		{"allocated_arrays", func() (RubyObject, error) { return Send(NewArray(), "reverse") }},
+		{"allocated_arrays", func() (RubyObject, error) { return Send(&Object{}, "methods") }},
+		{"allocated_hashes", func() (RubyObject, error) { return Send(NewHash(nil), "dup") }},
+		{"allocated_hashes", func() (RubyObject, error) { return kernelHashConversion(&Object{}, NIL) }},
+		{"allocated_instances", func() (RubyObject, error) { return Send(&Object{}, "dup") }},
+	}
+
+	for _, tt := range tests {
+		before, err := Send(gcModule, "stat", NewSymbol(tt.counter))
+		checkError(t, err, nil)
+
+		_, err = tt.allocate()
+		checkError(t, err, nil)
+
+		after, err := Send(gcModule, "stat", NewSymbol(tt.counter))
+		checkError(t, err, nil)
+		if after.(*Integer).Value <= before.(*Integer).Value {
+			t.Logf("Expected %s to grow, got %d after %d", tt.counter, after.(*Integer).Value, before.(*Integer).Value)
+			t.Fail()
+		}
+	}
+
+	stat, err := Send(gcModule, "stat")
+	checkError(t, err, nil)
+	for _, key := range []string{"count", "total_allocated_objects", "allocated_hashes", "return_values"} {
+		if _, ok := stat.(*Hash).Get(NewSymbol(key)); !ok {
+			t.Logf("Expected GC.stat to contain %s", key)
+			t.Fail()
+		}
+	}
+
+	_, err = Send(gcModule, "stat", NewSymbol("unknown"))
+	checkError(t, err, NewArgumentError("unknown key: unknown"))
+}
+
+func TestReturnValuePool(t *testing.T) {
+	rv := NewReturnValue(NewInteger(3))
+
+	value := ReleaseReturnValue(rv)
+
+	checkResult(t, value, NewInteger(3))
+	if rv.Value != nil {
+		t.Logf("Expected the released ReturnValue to be cleared, got %v", rv.Value)
+		t.Fail()
+	}
+}
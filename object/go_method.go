@@ -0,0 +1,150 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NewGoMethod returns a public method calling the Go function fn, so that
+// Go functions can be called from Ruby. The arguments are converted into
+// the parameter types of fn, which may be bool, the integer and float kinds,
+// string, slices and maps of these, interface{} and RubyObject. A last
+// parameter of type *Proc receives the block, or nil without one.
+//
+// fn may return up to one value, converted back into a Ruby object, and an
+// error as last result. An error which is no Ruby exception is raised as
+// RuntimeError.
+func NewGoMethod(fn interface{}) (RubyMethod, error) {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return nil, fmt.Errorf("expected a func, got %T", fn)
+	}
+	return newGoMethod(value, 0)
+}
+
+// newGoMethod returns a method calling fn. The first receivers parameters
+// of fn, which is either none or one, are not passed from Ruby. The only
+// receiver is the Go value wrapped by the GoObject the method is called on.
+func newGoMethod(fn reflect.Value, receivers int) (RubyMethod, error) {
+	typ := fn.Type()
+	g := &goMethod{fn: fn, receivers: receivers}
+	params := typ.NumIn()
+	if params > receivers && typ.In(params-1) == procType && !typ.IsVariadic() {
+		g.takesBlock = true
+		params--
+	}
+	for i := receivers; i < params; i++ {
+		paramType := typ.In(i)
+		if typ.IsVariadic() && i == params-1 {
+			paramType = paramType.Elem()
+		}
+		if !isConvertibleType(paramType) {
+			return nil, fmt.Errorf("unsupported parameter type %s", typ.In(i))
+		}
+	}
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		g.returnsError = typ.Out(0) == errorType
+		g.returnsValue = !g.returnsError
+	case 2:
+		if typ.Out(1) != errorType {
+			return nil, fmt.Errorf("the second result must be an error, got %s", typ.Out(1))
+		}
+		g.returnsValue, g.returnsError = true, true
+	default:
+		return nil, fmt.Errorf("expected at most two results, got %d", typ.NumOut())
+	}
+	if g.returnsValue && !isConvertibleType(typ.Out(0)) {
+		return nil, fmt.Errorf("unsupported result type %s", typ.Out(0))
+	}
+	method := publicMethod(g.call)
+	params -= receivers
+	if typ.IsVariadic() {
+		return withArityRange(params-1, -1, method), nil
+	}
+	return withArity(params, method), nil
+}
+
+// goMethod calls a Go function with the arguments converted from Ruby
+type goMethod struct {
+	fn           reflect.Value
+	receivers    int
+	takesBlock   bool
+	returnsValue bool
+	returnsError bool
+}
+
+func (g *goMethod) call(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	typ := g.fn.Type()
+	in := make([]reflect.Value, 0, g.receivers+len(args)+1)
+	if g.receivers > 0 {
+		in = append(in, context.(*GoObject).value)
+	}
+	for i, arg := range args {
+		i += g.receivers
+		var paramType reflect.Type
+		if typ.IsVariadic() && i >= typ.NumIn()-1 {
+			paramType = typ.In(typ.NumIn() - 1).Elem()
+		} else {
+			paramType = typ.In(i)
+		}
+		converted, err := toGo(arg, paramType)
+		if err != nil {
+			return nil, err
+		}
+		in = append(in, converted)
+	}
+	if g.takesBlock {
+		in = append(in, reflect.ValueOf(block))
+	}
+	out := g.fn.Call(in)
+	if g.returnsError {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			if _, ok := err.(RubyObject); ok {
+				return nil, err
+			}
+			return nil, NewRuntimeError("%s", err.Error())
+		}
+	}
+	if !g.returnsValue {
+		return NIL, nil
+	}
+	return fromGo(out[0])
+}
+
+// DefineMethod adds method under name to context within r like AddMethod
+// does for methods defined in Ruby. Defined on the main object, the method
+// can be called like a global function.
+func (r *Runtime) DefineMethod(context RubyObject, name string, method RubyMethod) RubyObject {
+	return r.addMethod(context, name, method)
+}
+
+// DefineModuleFunction defines method as singleton method name of the top
+// level module moduleName, which is created if it does not exist yet,
+// outside of any Runtime, which makes it visible within all of them
+func DefineModuleFunction(moduleName, name string, method RubyMethod) error {
+	return (*Runtime)(nil).DefineModuleFunction(moduleName, name, method)
+}
+
+// DefineModuleFunction defines method as singleton method name of the top
+// level module moduleName of r, which is created if it does not exist yet
+func (r *Runtime) DefineModuleFunction(moduleName, name string, method RubyMethod) error {
+	if !IsConstantName(moduleName) {
+		return NewNameError(NIL, moduleName)
+	}
+	constant, ok := r.ownConstant(objectClass, moduleName)
+	if !ok {
+		module := newModule("", map[string]RubyMethod{})
+		r.SetConstant(moduleName, module)
+		constant = module
+	}
+	module, ok := constant.(*Module)
+	if !ok {
+		return NewTypeError("%s is not a module", moduleName)
+	}
+	NewSymbol(name)
+	r.defineMethod(module, name, method)
+	return nil
+}
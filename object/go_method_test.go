@@ -0,0 +1,108 @@
+package object
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewGoMethod(t *testing.T) {
+	call := func(t *testing.T, fn interface{}, args ...RubyObject) (RubyObject, error) {
+		method, err := NewGoMethod(fn)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return method.Call(NIL, args...)
+	}
+	t.Run("converts arguments and results", func(t *testing.T) {
+		result, err := call(t, func(s string, n int, f float64, ok bool) string {
+			return strings.Repeat(s, n) + strings.Repeat("!", int(f)) + map[bool]string{true: "?"}[ok]
+		}, &String{Value: "ab"}, NewInteger(2), NewInteger(1), TRUE)
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "abab!?"})
+	})
+	t.Run("converts slices and maps", func(t *testing.T) {
+		hash := NewHash(nil)
+		hash.Set(NewSymbol("a"), NewInteger(2))
+		result, err := call(t, func(values []int64, factors map[string]int64) []int64 {
+			for i := range values {
+				values[i] *= factors["a"]
+			}
+			return values
+		}, NewArray(NewInteger(1), NewInteger(3)), hash)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(NewInteger(2), NewInteger(6)))
+	})
+	t.Run("variadic", func(t *testing.T) {
+		result, err := call(t, func(sep string, parts ...string) string {
+			return strings.Join(parts, sep)
+		}, &String{Value: "-"}, &String{Value: "a"}, NewSymbol("b"))
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "a-b"})
+	})
+	t.Run("block", func(t *testing.T) {
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return NewInteger(args[0].(*Integer).Value + 1), nil
+		})
+		result, err := call(t, func(n RubyObject, block *Proc) (RubyObject, error) {
+			return block.Call(n)
+		}, NewInteger(1), block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(2))
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := call(t, func() error { return errors.New("failed") })
+
+		checkError(t, err, NewRuntimeError("failed"))
+
+		_, err = call(t, func() (int, error) { return 0, NewArgumentError("bad") })
+
+		checkError(t, err, NewArgumentError("bad"))
+	})
+	t.Run("argument errors", func(t *testing.T) {
+		_, err := call(t, func(n int) int { return n }, &String{Value: "1"})
+
+		checkError(t, err, NewTypeError("no implicit conversion of String into int"))
+
+		_, err = call(t, func(n int8) int8 { return n }, NewInteger(300))
+
+		checkError(t, err, NewRangeError("integer 300 too big to convert to int8"))
+
+		_, err = call(t, func(n int) int { return n })
+
+		checkError(t, err, NewWrongNumberOfArgumentsError(1, 0))
+	})
+	t.Run("unsupported funcs", func(t *testing.T) {
+		for _, fn := range []interface{}{
+			"foo",
+			func(c chan int) {},
+			func() (int, int) { return 0, 0 },
+			func() (int, string, error) { return 0, "", nil },
+		} {
+			if _, err := NewGoMethod(fn); err == nil {
+				t.Logf("Expected error for %T", fn)
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestDefineModuleFunction(t *testing.T) {
+	method, err := NewGoMethod(func(a, b int) int { return a + b })
+	checkError(t, err, nil)
+
+	err = DefineModuleFunction("GoMath", "add", method)
+	checkError(t, err, nil)
+
+	module, _ := ownConstant(objectClass, "GoMath")
+	result, err := Send(module, "add", NewInteger(1), NewInteger(2))
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(3))
+
+	err = DefineModuleFunction("String", "add", method)
+	checkError(t, err, NewTypeError("String is not a module"))
+}
@@ -0,0 +1,182 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// WrapStruct returns a GoObject exposing the struct ptr points to to Ruby.
+// Its exported fields are readable and writable by methods named after them
+// in snake case, like `listen_addr` and `listen_addr=` for ListenAddr, as
+// far as their types are convertible. Its exported methods are callable by
+// their snake case names as well, with arguments and results converted like
+// by NewGoMethod.
+//
+// Changes made from Ruby are made to the struct itself, so that they are
+// visible to the host application.
+func WrapStruct(ptr interface{}) (*GoObject, error) {
+	value := reflect.ValueOf(ptr)
+	if value.Kind() != reflect.Ptr || value.Type().Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct, got %T", ptr)
+	}
+	if value.IsNil() {
+		return nil, fmt.Errorf("expected a non-nil pointer, got nil %T", ptr)
+	}
+	return wrapStruct(value), nil
+}
+
+func wrapStruct(value reflect.Value) *GoObject {
+	return &GoObject{value: value, class: goStructClass(value.Type())}
+}
+
+// A GoObject represents a Go struct wrapped by WrapStruct
+type GoObject struct {
+	value reflect.Value
+	class RubyClassObject
+}
+
+// Type returns GO_OBJ
+func (g *GoObject) Type() Type { return GO_OBJ }
+
+// Inspect returns the class name and the values of the exposed fields, like
+// `#<Config name="app", port=80>`
+func (g *GoObject) Inspect() string {
+	var fields []string
+	for _, field := range goStructFields(g.value.Type().Elem()) {
+		if isStructType(field.typ) {
+			continue
+		}
+		value, err := fromGo(g.value.Elem().FieldByIndex(field.index))
+		if err != nil {
+			continue
+		}
+		fields = append(fields, field.name+"="+inspectQuoted(value))
+	}
+	if len(fields) == 0 {
+		return "#<" + g.class.Inspect() + ">"
+	}
+	return "#<" + g.class.Inspect() + " " + strings.Join(fields, ", ") + ">"
+}
+
+// inspectQuoted returns the inspected obj with Strings quoted
+func inspectQuoted(obj RubyObject) string {
+	if str, ok := obj.(*String); ok {
+		return fmt.Sprintf("%q", str.Value)
+	}
+	return obj.Inspect()
+}
+
+// Class returns the class created for the type of the wrapped struct
+func (g *GoObject) Class() RubyClass { return g.class }
+
+// Interface returns the pointer to the wrapped struct
+func (g *GoObject) Interface() interface{} { return g.value.Interface() }
+
+func (g *GoObject) hashKey() hashKey {
+	return hashKey{Type: g.Type(), Value: g.value.Pointer()}
+}
+
+// goStructClasses holds the classes created for the struct pointer types
+// wrapped so far
+var goStructClasses = struct {
+	sync.Mutex
+	classes map[reflect.Type]RubyClassObject
+}{classes: map[reflect.Type]RubyClassObject{}}
+
+// goStructClass returns the class of the GoObjects wrapping pointers of typ,
+// which is named after the struct type and not registered as constant
+func goStructClass(typ reflect.Type) RubyClassObject {
+	goStructClasses.Lock()
+	defer goStructClasses.Unlock()
+	if class, ok := goStructClasses.classes[typ]; ok {
+		return class
+	}
+	methods := map[string]RubyMethod{}
+	for _, field := range goStructFields(typ.Elem()) {
+		methods[field.name] = withArity(0, publicMethod(goStructGetter(field.index)))
+		methods[field.name+"="] = withArity(1, publicMethod(goStructSetter(field.index, field.typ)))
+	}
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		rubyMethod, err := newGoMethod(method.Func, 1)
+		if err != nil {
+			continue
+		}
+		methods[snakeCase(method.Name)] = rubyMethod
+	}
+	class := newClass(typ.Elem().Name(), objectClass, methods, nil)
+	goStructClasses.classes[typ] = class
+	return class
+}
+
+// goStructField is an exported field of a struct exposed to Ruby
+type goStructField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+// goStructFields returns the exported fields of typ with convertible types,
+// including the ones promoted from embedded structs
+func goStructFields(typ reflect.Type) []goStructField {
+	var fields []goStructField
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous || !isConvertibleType(field.Type) && !isStructType(field.Type) {
+			continue
+		}
+		fields = append(fields, goStructField{name: snakeCase(field.Name), index: field.Index, typ: field.Type})
+	}
+	return fields
+}
+
+// isStructType reports whether typ is a struct or a pointer to one, which
+// are exposed as GoObjects
+func isStructType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && typ != timeType
+}
+
+func goStructGetter(index []int) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		field := context.(*GoObject).value.Elem().FieldByIndex(index)
+		if field.Kind() == reflect.Struct && field.Type() != timeType {
+			return wrapStruct(field.Addr()), nil
+		}
+		return fromGo(field)
+	}
+}
+
+func goStructSetter(index []int, typ reflect.Type) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		value, err := toGo(args[0], typ)
+		if err != nil {
+			return nil, err
+		}
+		context.(*GoObject).value.Elem().FieldByIndex(index).Set(value)
+		return args[0], nil
+	}
+}
+
+// snakeCase converts the Go name into snake case, keeping acronyms like in
+// `HTTPServer` together
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var out strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			followsLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			endsAcronym := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (followsLower || endsAcronym) && runes[i-1] != '_' {
+				out.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
@@ -0,0 +1,117 @@
+package object
+
+import (
+	"errors"
+	"testing"
+)
+
+type testServer struct {
+	Host    string
+	Port    int
+	Tags    []string
+	TLS     testTLS
+	secret  string
+	Channel chan int
+}
+
+type testTLS struct {
+	CertFile string
+}
+
+func (s *testServer) Address() string { return s.Host + ":" + NewInteger(int64(s.Port)).Inspect() }
+
+func (s *testServer) AddTag(tag string) error {
+	if tag == "" {
+		return errors.New("empty tag")
+	}
+	s.Tags = append(s.Tags, tag)
+	return nil
+}
+
+func TestWrapStruct(t *testing.T) {
+	server := &testServer{Host: "localhost", Port: 80}
+	wrapped, err := WrapStruct(server)
+	checkError(t, err, nil)
+
+	t.Run("fields", func(t *testing.T) {
+		result, err := Send(wrapped, "host")
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "localhost"})
+
+		_, err = Send(wrapped, "port=", NewInteger(8080))
+		checkError(t, err, nil)
+		if server.Port != 8080 {
+			t.Logf("Expected setter to change the struct, got port %d", server.Port)
+			t.Fail()
+		}
+
+		_, err = Send(wrapped, "port=", &String{Value: "80"})
+		checkError(t, err, NewTypeError("no implicit conversion of String into int"))
+	})
+	t.Run("nested structs", func(t *testing.T) {
+		tls, err := Send(wrapped, "tls")
+		checkError(t, err, nil)
+
+		_, err = Send(tls, "cert_file=", &String{Value: "cert.pem"})
+		checkError(t, err, nil)
+		if server.TLS.CertFile != "cert.pem" {
+			t.Logf("Expected nested struct to be changed, got %q", server.TLS.CertFile)
+			t.Fail()
+		}
+	})
+	t.Run("methods", func(t *testing.T) {
+		_, err := Send(wrapped, "add_tag", &String{Value: "web"})
+		checkError(t, err, nil)
+		result, err := Send(wrapped, "tags")
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(&String{Value: "web"}))
+
+		_, err = Send(wrapped, "add_tag", &String{Value: ""})
+		checkError(t, err, NewRuntimeError("empty tag"))
+
+		result, err = Send(wrapped, "address")
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "localhost:8080"})
+	})
+	t.Run("unexposed fields", func(t *testing.T) {
+		for _, name := range []string{"secret", "channel"} {
+			_, err := Send(wrapped, name)
+			if _, ok := err.(*NoMethodError); !ok {
+				t.Logf("Expected NoMethodError for %s, got %v", name, err)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("inspect", func(t *testing.T) {
+		expected := `#<testServer host="localhost", port=8080, tags=[web]>`
+		if wrapped.Inspect() != expected {
+			t.Logf("Expected %s, got %s", expected, wrapped.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("invalid values", func(t *testing.T) {
+		for _, value := range []interface{}{testServer{}, (*testServer)(nil), "foo"} {
+			if _, err := WrapStruct(value); err == nil {
+				t.Logf("Expected error wrapping %T", value)
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Host":       "host",
+		"ListenAddr": "listen_addr",
+		"HTTPServer": "http_server",
+		"URL":        "url",
+		"AddTag":     "add_tag",
+	}
+
+	for name, expected := range tests {
+		if actual := snakeCase(name); actual != expected {
+			t.Logf("Expected %s to become %s, got %s", name, expected, actual)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+var (
+	rubyObjectType = reflect.TypeOf((*RubyObject)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	procType       = reflect.TypeOf((*Proc)(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// FromGo converts the Go value into a Ruby object. Bools, numbers and
+// strings become their Ruby counterparts, time.Time a Time, slices and
+// arrays Arrays and maps Hashes, with their elements converted as well.
+// Pointers to structs become GoObjects like returned by WrapStruct, and
+// channels Channels like returned by NewChannel. nil and nil pointers,
+// slices, maps and channels become nil. An error becomes a
+// RuntimeError with its message unless it is a Ruby exception already.
+// RubyObjects are returned as they are.
+//
+// It returns a TypeError for values which cannot be converted, like
+// structs or channels of such values.
+func FromGo(value interface{}) (RubyObject, error) {
+	if value == nil {
+		return NIL, nil
+	}
+	return fromGo(reflect.ValueOf(value))
+}
+
+// ToGo converts obj into the type target points to and stores it there. It
+// converts the objects FromGo returns back into the original Go types. For
+// an interface{} target obj is converted by GoValue.
+//
+// It returns a TypeError if obj cannot be converted into that type, and a
+// RangeError if an Integer exceeds the range of an integer type.
+func ToGo(obj RubyObject, target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("expected a non-nil pointer, got %T", target)
+	}
+	typ := ptr.Elem().Type()
+	if !isConvertibleType(typ) {
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	value, err := toGo(obj, typ)
+	if err != nil {
+		return err
+	}
+	ptr.Elem().Set(value)
+	return nil
+}
+
+// GoValue converts obj into the Go value closest to it: nil, bool, int64,
+// float64, string, time.Time, []interface{} or map[string]interface{} keyed
+// by the names of Strings and Symbols and the inspected other keys. Other
+// objects, including exceptions, which are errors, are returned as they
+// are.
+//
+// GoValue is a function rather than a method of RubyObject, which every
+// object would have to implement; the embedding API offers it as method of
+// its values. It returns an ArgumentError for Hashes with keys converting
+// to the same name, like "a" and :a.
+func GoValue(obj RubyObject) (interface{}, error) {
+	switch obj := obj.(type) {
+	case nil, *nilObject:
+		return nil, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *Integer:
+		return obj.Value, nil
+	case *Float:
+		return obj.Value, nil
+	case *String:
+		return obj.Value, nil
+	case *Symbol:
+		return obj.Value, nil
+	case *Time:
+		return obj.Value, nil
+	case *Array:
+		elements := make([]interface{}, len(obj.Elements))
+		for i, element := range obj.Elements {
+			value, err := GoValue(element)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *Hash:
+		pairs := make(map[string]interface{}, obj.Len())
+		converted := make(map[string]RubyObject, obj.Len())
+		keys, values := obj.Keys(), obj.Values()
+		for i, key := range keys {
+			name := key.Inspect()
+			switch key := key.(type) {
+			case *String:
+				name = key.Value
+			case *Symbol:
+				name = key.Value
+			}
+			if previous, ok := converted[name]; ok {
+				return nil, NewArgumentError("keys %s and %s both convert to %q", inspect(previous), inspect(key), name)
+			}
+			converted[name] = key
+			value, err := GoValue(values[i])
+			if err != nil {
+				return nil, err
+			}
+			pairs[name] = value
+		}
+		return pairs, nil
+	default:
+		return obj, nil
+	}
+}
+
+// isConvertibleType reports whether toGo and fromGo convert values of typ
+func isConvertibleType(typ reflect.Type) bool {
+	if typ == rubyObjectType || typ == errorType || typ == timeType || typ.Implements(rubyObjectType) {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Interface:
+		return typ.NumMethod() == 0
+	case reflect.Slice, reflect.Array:
+		return isConvertibleType(typ.Elem())
+	case reflect.Map:
+		return isConvertibleType(typ.Key()) && isConvertibleType(typ.Elem())
+	default:
+		return false
+	}
+}
+
+// toGo converts obj into a Go value of typ, returning a TypeError if obj is
+// not convertible
+func toGo(obj RubyObject, typ reflect.Type) (reflect.Value, error) {
+	if typ == rubyObjectType {
+		return reflect.ValueOf(&obj).Elem(), nil
+	}
+	if reflect.TypeOf(obj) == typ {
+		return reflect.ValueOf(obj), nil
+	}
+	if wrapped, ok := obj.(*GoObject); ok {
+		switch typ {
+		case wrapped.value.Type():
+			return wrapped.value, nil
+		case wrapped.value.Type().Elem():
+			return wrapped.value.Elem(), nil
+		}
+	}
+	value := reflect.New(typ).Elem()
+	if obj == NIL {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+			return value, nil
+		}
+	}
+	mismatch := func() (reflect.Value, error) {
+		return reflect.Value{}, NewTypeError(
+			"no implicit conversion of %s into %s",
+			obj.Class().(RubyObject).Inspect(), typ,
+		)
+	}
+	if typ == timeType {
+		t, ok := obj.(*Time)
+		if !ok {
+			return mismatch()
+		}
+		value.Set(reflect.ValueOf(t.Value))
+		return value, nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		b, ok := obj.(*Boolean)
+		if !ok {
+			return mismatch()
+		}
+		value.SetBool(b.Value)
+	case reflect.String:
+		switch str := obj.(type) {
+		case *String:
+			value.SetString(str.Value)
+		case *Symbol:
+			value.SetString(str.Value)
+		default:
+			return mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return mismatch()
+		}
+		if value.OverflowInt(i.Value) {
+			return reflect.Value{}, NewRangeError("integer %d too big to convert to %s", i.Value, typ)
+		}
+		value.SetInt(i.Value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := obj.(*Integer)
+		if !ok {
+			return mismatch()
+		}
+		if i.Value < 0 || value.OverflowUint(uint64(i.Value)) {
+			return reflect.Value{}, NewRangeError("integer %d out of range of %s", i.Value, typ)
+		}
+		value.SetUint(uint64(i.Value))
+	case reflect.Float32, reflect.Float64:
+		switch number := obj.(type) {
+		case *Float:
+			value.SetFloat(number.Value)
+		case *Integer:
+			value.SetFloat(float64(number.Value))
+		default:
+			return mismatch()
+		}
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			if !reflect.TypeOf(obj).Implements(typ) {
+				return mismatch()
+			}
+			value.Set(reflect.ValueOf(obj))
+			break
+		}
+		goValue, err := GoValue(obj)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if goValue != nil {
+			value.Set(reflect.ValueOf(goValue))
+		}
+	case reflect.Slice, reflect.Array:
+		array, ok := obj.(*Array)
+		if !ok {
+			return mismatch()
+		}
+		if typ.Kind() == reflect.Slice {
+			value = reflect.MakeSlice(typ, len(array.Elements), len(array.Elements))
+		} else if len(array.Elements) != typ.Len() {
+			return reflect.Value{}, NewArgumentError("expected %d elements for %s, got %d", typ.Len(), typ, len(array.Elements))
+		}
+		for i, element := range array.Elements {
+			converted, err := toGo(element, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(converted)
+		}
+	case reflect.Map:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch()
+		}
+		value = reflect.MakeMapWithSize(typ, hash.Len())
+		keys, values := hash.Keys(), hash.Values()
+		for i, key := range keys {
+			convertedKey, err := toGo(key, typ.Key())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			convertedValue, err := toGo(values[i], typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.SetMapIndex(convertedKey, convertedValue)
+		}
+	default:
+		return mismatch()
+	}
+	return value, nil
+}
+
+// fromGo converts the Go value into a Ruby object
+func fromGo(value reflect.Value) (RubyObject, error) {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return NIL, nil
+	case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan:
+		if value.IsNil() {
+			return NIL, nil
+		}
+	}
+	switch v := value.Interface().(type) {
+	case RubyObject:
+		return v, nil
+	case time.Time:
+		return NewTime(v), nil
+	case error:
+		return NewRuntimeError("%s", v.Error()), nil
+	}
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.Elem().Kind() == reflect.Struct {
+			return wrapStruct(value), nil
+		}
+	case reflect.Interface:
+		return fromGo(value.Elem())
+	case reflect.Chan:
+		if isConvertibleType(value.Type().Elem()) {
+			return &Channel{value: value}, nil
+		}
+	case reflect.Bool:
+		return nativeBoolToBoolean(value.Bool()), nil
+	case reflect.String:
+		return &String{Value: value.String()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewInteger(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NewInteger(int64(value.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return NewFloat(value.Float()), nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]RubyObject, value.Len())
+		for i := range elements {
+			element, err := fromGo(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return NewArray(elements...), nil
+	case reflect.Map:
+		hash := NewHash(nil)
+		for _, mapKey := range sortedMapKeys(value) {
+			key, err := fromGo(mapKey)
+			if err != nil {
+				return nil, err
+			}
+			val, err := fromGo(value.MapIndex(mapKey))
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(key, val)
+		}
+		return hash, nil
+	}
+	return nil, NewTypeError("can't convert %s into a Ruby object", value.Type())
+}
+
+// sortedMapKeys returns the keys of the map value, sorted if they are
+// strings or numbers, so that Hashes converted from maps are ordered
+// deterministically
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	var less func(a, b reflect.Value) bool
+	switch value.Type().Key().Kind() {
+	case reflect.String:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	default:
+		return keys
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
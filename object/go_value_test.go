@@ -0,0 +1,121 @@
+package object
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromGo(t *testing.T) {
+	now := time.Now()
+	expectedHash := NewHash(nil)
+	expectedHash.Set(&String{Value: "a"}, NewInteger(1))
+	expectedHash.Set(&String{Value: "b"}, NIL)
+
+	tests := []struct {
+		value    interface{}
+		expected RubyObject
+	}{
+		{nil, NIL},
+		{true, TRUE},
+		{42, NewInteger(42)},
+		{uint8(7), NewInteger(7)},
+		{1.5, NewFloat(1.5)},
+		{"foo", &String{Value: "foo"}},
+		{[]string{"a", "b"}, NewArray(&String{Value: "a"}, &String{Value: "b"})},
+		{[2]int{1, 2}, NewArray(NewInteger(1), NewInteger(2))},
+		{map[string]interface{}{"b": nil, "a": 1}, expectedHash},
+		{now, NewTime(now)},
+		{errors.New("failed"), NewRuntimeError("failed")},
+		{NewArgumentError("bad"), NewArgumentError("bad")},
+		{(*int)(nil), NIL},
+	}
+
+	for _, tt := range tests {
+		actual, err := FromGo(tt.value)
+
+		checkError(t, err, nil)
+		checkResult(t, actual, tt.expected)
+	}
+
+	_, err := FromGo(struct{}{})
+
+	checkError(t, err, NewTypeError("can't convert struct {} into a Ruby object"))
+}
+
+func TestToGo(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		tests := []interface{}{
+			true,
+			int64(-3),
+			uint16(9),
+			2.5,
+			"foo",
+			[]int{1, 2},
+			map[string][]bool{"x": {true, false}},
+			time.Unix(1500000000, 0),
+		}
+
+		for _, value := range tests {
+			obj, err := FromGo(value)
+			checkError(t, err, nil)
+
+			target := reflect.New(reflect.TypeOf(value))
+			err = ToGo(obj, target.Interface())
+
+			checkError(t, err, nil)
+			if actual := target.Elem().Interface(); !reflect.DeepEqual(actual, value) {
+				t.Logf("Expected %#v, got %#v", value, actual)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("interface", func(t *testing.T) {
+		hash := NewHash(nil)
+		hash.Set(NewSymbol("list"), NewArray(NewInteger(1), NIL))
+		var target interface{}
+
+		err := ToGo(hash, &target)
+
+		checkError(t, err, nil)
+		expected := map[string]interface{}{"list": []interface{}{int64(1), nil}}
+		if !reflect.DeepEqual(target, expected) {
+			t.Logf("Expected %#v, got %#v", expected, target)
+			t.Fail()
+		}
+	})
+	t.Run("interface with colliding keys", func(t *testing.T) {
+		hash := NewHash(nil)
+		hash.Set(&String{Value: "a"}, NewInteger(1))
+		hash.Set(NewSymbol("a"), NewInteger(2))
+		var target interface{}
+
+		err := ToGo(NewArray(hash), &target)
+
+		checkError(t, err, NewArgumentError(`keys "a" and :a both convert to "a"`))
+	})
+	t.Run("errors", func(t *testing.T) {
+		var target error
+
+		err := ToGo(NewArgumentError("bad"), &target)
+
+		checkError(t, err, nil)
+		checkError(t, target, NewArgumentError("bad"))
+
+		var s string
+		err = ToGo(NewInteger(1), &s)
+
+		checkError(t, err, NewTypeError("no implicit conversion of Integer into string"))
+
+		var u uint
+		err = ToGo(NewInteger(-1), &u)
+
+		checkError(t, err, NewRangeError("integer -1 out of range of uint"))
+
+		if err := ToGo(NIL, s); err == nil {
+			t.Logf("Expected error for non-pointer target")
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,523 @@
+package object
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+var hashClass RubyClassObject = newClass("Hash", objectClass, hashMethods, hashClassMethods)
+
+func init() {
+	classes.Set("Hash", hashClass)
+}
+
+// NewHash returns a new Hash populated with the given map. The insertion
+// order of the keys is not defined.
+func NewHash(m map[RubyObject]RubyObject) *Hash {
+	allocatedHashes.add()
+	hash := &Hash{}
+	for k, v := range m {
+		hash.Set(k, v)
+	}
+	return hash
+}
+
+// hashKey represents the key under which an object is stored within a Hash
+type hashKey struct {
+	Type  Type
+	Value interface{}
+}
+
+// hashable is implemented by objects which are equal by value rather than by
+// identity when used as Hash keys
+type hashable interface {
+	hashKey() hashKey
+}
+
+func hashKeyOf(obj RubyObject) hashKey {
+	if h, ok := obj.(hashable); ok {
+		return h.hashKey()
+	}
+	return hashKey{Type: obj.Type(), Value: obj}
+}
+
+// keyOf returns the hashKey under which key is stored within h. Objects
+// with their own `hash` method share the key of an existing key they are
+// `eql?` to.
+func (h *Hash) keyOf(key RubyObject) hashKey {
+	hash, ok := customHash(key)
+	if !ok {
+		return hashKeyOf(key)
+	}
+	for slot := 0; ; slot++ {
+		k := hashKey{Type: key.Type(), Value: customHashKey{hash: hash, slot: slot}}
+		pair, ok := h.table[k]
+		if !ok {
+			return k
+		}
+		if eql, err := isEql(key, pair.Key); err == nil && eql {
+			return k
+		}
+	}
+}
+
+type hashPair struct {
+	Key   RubyObject
+	Value RubyObject
+}
+
+// A Hash represents a Ruby Hash. It preserves the insertion order of its
+// keys.
+type Hash struct {
+	table        map[hashKey]hashPair
+	order        []hashKey
+	defaultValue RubyObject
+	defaultProc  *Proc
+	// shared is 1 while table and order may be shared with another Hash,
+	// see snapshot. It is accessed atomically like the one of Array.
+	shared int32
+	frozen bool
+}
+
+// snapshot returns a copy of h with the same pairs and defaults. Both
+// Hashes share their pairs until either is modified.
+func (h *Hash) snapshot() *Hash {
+	allocatedHashes.add()
+	atomic.StoreInt32(&h.shared, 1)
+	return &Hash{
+		table:        h.table,
+		order:        h.order[:len(h.order):len(h.order)],
+		defaultValue: h.defaultValue,
+		defaultProc:  h.defaultProc,
+		shared:       1,
+	}
+}
+
+// modify copies the pairs of h if they may be shared with another Hash.
+// It must be called before h is modified in place.
+func (h *Hash) modify() {
+	if atomic.LoadInt32(&h.shared) == 0 {
+		return
+	}
+	table := make(map[hashKey]hashPair, len(h.table))
+	for k, pair := range h.table {
+		table[k] = pair
+	}
+	h.table = table
+	h.order = append([]hashKey(nil), h.order...)
+	atomic.StoreInt32(&h.shared, 0)
+}
+
+// Type returns HASH_OBJ
+func (h *Hash) Type() Type { return HASH_OBJ }
+
+// Inspect returns all key value pairs divided by comma and surrounded by
+// braces
+func (h *Hash) Inspect() string {
+	pairs := make([]string, len(h.order))
+	for i, key := range h.order {
+		pair := h.table[key]
+		pairs[i] = pair.Key.Inspect() + "=>" + pair.Value.Inspect()
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Class returns hashClass
+func (h *Hash) Class() RubyClass { return hashClass }
+
+// Frozen returns true if the hash can not be modified anymore
+func (h *Hash) Frozen() bool { return h.frozen }
+
+// Freeze prevents any further modifications of the hash
+func (h *Hash) Freeze() { h.frozen = true }
+
+// Set stores value under key and returns value. If the Hash already
+// contains an equal key, that key is kept.
+func (h *Hash) Set(key, value RubyObject) RubyObject {
+	h.modify()
+	if h.table == nil {
+		h.table = make(map[hashKey]hashPair)
+	}
+	k := h.keyOf(key)
+	if pair, ok := h.table[k]; ok {
+		key = pair.Key
+	} else {
+		h.order = append(h.order, k)
+	}
+	h.table[k] = hashPair{Key: key, Value: value}
+	return value
+}
+
+// Get returns the value stored under key. If there is no such key, ok will
+// be false
+func (h *Hash) Get(key RubyObject) (RubyObject, bool) {
+	pair, ok := h.table[h.keyOf(key)]
+	if !ok {
+		return nil, false
+	}
+	return pair.Value, true
+}
+
+// Len returns the number of key value pairs within the hash
+func (h *Hash) Len() int { return len(h.order) }
+
+// Keys returns all keys in insertion order
+func (h *Hash) Keys() []RubyObject {
+	keys := make([]RubyObject, len(h.order))
+	for i, k := range h.order {
+		keys[i] = h.table[k].Key
+	}
+	return keys
+}
+
+// Values returns all values in the insertion order of their keys
+func (h *Hash) Values() []RubyObject {
+	values := make([]RubyObject, len(h.order))
+	for i, k := range h.order {
+		values[i] = h.table[k].Value
+	}
+	return values
+}
+
+// Default returns the value for key if it is missing, i.e. the result of
+// the default block or the default value
+func (h *Hash) Default(key RubyObject) (RubyObject, error) {
+	if h.defaultProc != nil {
+		return h.defaultProc.Call(h, key)
+	}
+	if h.defaultValue != nil {
+		return h.defaultValue, nil
+	}
+	return NIL, nil
+}
+
+var hashClassMethods = map[string]RubyMethod{
+	"new": withArityRange(0, 1, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		args, block := extractBlock(args)
+		hash := NewHash(nil)
+		if block != nil && len(args) == 1 {
+			return nil, NewWrongNumberOfArgumentsError(0, 1)
+		}
+		hash.defaultProc = block
+		if len(args) == 1 {
+			hash.defaultValue = args[0]
+		}
+		return hash, nil
+	})),
+}
+
+var hashMethods = map[string]RubyMethod{
+	"size":   withArity(0, publicMethod(hashSize)),
+	"length": withArity(0, publicMethod(hashSize)),
+	"keys":   withArity(0, publicMethod(hashKeys)),
+	"values": withArity(0, publicMethod(hashValues)),
+
+	"[]":       withArity(1, publicMethod(hashIndex)),
+	"[]=":      withArity(2, publicMethod(modifying(hashIndexAssign))),
+	"default":  withArityRange(0, 1, publicMethod(hashDefault)),
+	"default=": withArity(1, publicMethod(modifying(hashSetDefault))),
+	"fetch":    withArityRange(1, 2, publicMethod(hashFetch)),
+	"dig":      withArityRange(1, -1, publicMethod(hashDig)),
+	"key?":     withArity(1, publicMethod(hashHasKey)),
+	"has_key?": withArity(1, publicMethod(hashHasKey)),
+	"include?": withArity(1, publicMethod(hashHasKey)),
+	"member?":  withArity(1, publicMethod(hashHasKey)),
+	"to_a":     withArity(0, publicMethod(hashToA)),
+	"==":       withArity(1, publicMethod(hashEqual)),
+	"eql?":     withArity(1, publicMethod(hashEql)),
+	"hash":     withArity(0, publicMethod(hashHash)),
+
+	"each":             withArity(0, publicMethod(hashEach)),
+	"each_pair":        withArity(0, publicMethod(hashEach)),
+	"lazy":             withArity(0, publicMethod(enumerableLazy)),
+	"map":              withArity(0, publicMethod(hashMap)),
+	"select":           withArity(0, publicMethod(hashSelect)),
+	"filter":           withArity(0, publicMethod(hashSelect)),
+	"reject":           withArity(0, publicMethod(hashReject)),
+	"any?":             withArity(0, publicMethod(hashAny)),
+	"merge":            publicMethod(hashMerge),
+	"transform_values": withArity(0, publicMethod(hashTransformValues)),
+}
+
+func hashSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	return NewInteger(int64(hash.Len())), nil
+}
+
+func hashKeys(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	return NewArray(hash.Keys()...), nil
+}
+
+func hashValues(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	return NewArray(hash.Values()...), nil
+}
+
+// pairs returns all key value pairs in insertion order
+func (h *Hash) pairs() []hashPair {
+	pairs := make([]hashPair, len(h.order))
+	for i, k := range h.order {
+		pairs[i] = h.table[k]
+	}
+	return pairs
+}
+
+func hashIndex(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	value, ok := hash.Get(args[0])
+	if !ok {
+		return hash.Default(args[0])
+	}
+	return value, nil
+}
+
+func hashIndexAssign(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	return hash.Set(args[0], args[1]), nil
+}
+
+// hashDefault returns the default value. If a key is given and the hash has
+// a default block, it returns the block result for the key instead.
+func hashDefault(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	if len(args) == 1 {
+		return hash.Default(args[0])
+	}
+	if hash.defaultValue == nil {
+		return NIL, nil
+	}
+	return hash.defaultValue, nil
+}
+
+func hashSetDefault(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	hash.defaultValue = args[0]
+	hash.defaultProc = nil
+	return args[0], nil
+}
+
+// hashFetch returns the value for the given key. A missing key results in
+// the block result, the second argument or a KeyError, in that order.
+func hashFetch(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	args, block := extractBlock(args)
+	value, ok := hash.Get(args[0])
+	switch {
+	case ok:
+		return value, nil
+	case block != nil:
+		return block.Call(args[0])
+	case len(args) == 2:
+		return args[1], nil
+	default:
+		return nil, NewKeyError(args[0])
+	}
+}
+
+// hashDig looks up the first key and sends `dig` with the remaining keys to
+// the result. It returns nil as soon as a value is nil.
+func hashDig(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	value, err := hashIndex(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 1 || value == NIL {
+		return value, nil
+	}
+	return Send(value, "dig", args[1:]...)
+}
+
+func hashHasKey(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	_, ok := hash.Get(args[0])
+	return nativeBoolToBoolean(ok), nil
+}
+
+func hashToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	array := NewArray()
+	for _, pair := range hash.pairs() {
+		array.Elements = append(array.Elements, NewArray(pair.Key, pair.Value))
+	}
+	return array, nil
+}
+
+// hashEach yields every key value pair to the block
+func hashEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(hash, "each"), nil
+	}
+	for _, pair := range hash.pairs() {
+		if _, err := block.Call(NewArray(pair.Key, pair.Value)); err != nil {
+			return nil, err
+		}
+	}
+	return hash, nil
+}
+
+func hashMap(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	_, block := extractBlock(args)
+	if block == nil {
+		return hashToA(hash)
+	}
+	result := NewArray()
+	for _, pair := range hash.pairs() {
+		mapped, err := block.Call(NewArray(pair.Key, pair.Value))
+		if err != nil {
+			return nil, err
+		}
+		result.Elements = append(result.Elements, mapped)
+	}
+	return result, nil
+}
+
+func hashSelect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return hashFilter(context.(*Hash), block, true)
+}
+
+func hashReject(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	return hashFilter(context.(*Hash), block, false)
+}
+
+// hashFilter returns a new Hash with all pairs for which the truthiness of
+// the block result equals keep
+func hashFilter(hash *Hash, block *Proc, keep bool) (RubyObject, error) {
+	result := NewHash(nil)
+	for _, pair := range hash.pairs() {
+		if block != nil {
+			ok, err := block.Call(pair.Key, pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			if isTruthy(ok) != keep {
+				continue
+			}
+		}
+		result.Set(pair.Key, pair.Value)
+	}
+	return result, nil
+}
+
+// hashAny reports whether the block returns a truthy value for any pair.
+// Without a block it reports whether the hash is not empty.
+func hashAny(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	_, block := extractBlock(args)
+	if block == nil {
+		return nativeBoolToBoolean(hash.Len() > 0), nil
+	}
+	for _, pair := range hash.pairs() {
+		ok, err := block.Call(pair.Key, pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		if isTruthy(ok) {
+			return TRUE, nil
+		}
+	}
+	return FALSE, nil
+}
+
+// hashMerge returns a new Hash containing the pairs of the receiver and all
+// argument Hashes. For duplicate keys the block, if given, is called with
+// the key, the old and the new value to compute the merged value.
+func hashMerge(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	args, block := extractBlock(args)
+	merged := hash.snapshot()
+	for _, arg := range args {
+		other, ok := arg.(*Hash)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(hash, arg)
+		}
+		for _, pair := range other.pairs() {
+			value := pair.Value
+			if old, ok := merged.Get(pair.Key); ok && block != nil {
+				var err error
+				value, err = block.Call(pair.Key, old, pair.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			merged.Set(pair.Key, value)
+		}
+	}
+	return merged, nil
+}
+
+func hashTransformValues(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	_, block := extractBlock(args)
+	result := NewHash(nil)
+	for _, pair := range hash.pairs() {
+		value := pair.Value
+		if block != nil {
+			var err error
+			value, err = block.Call(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Set(pair.Key, value)
+	}
+	return result, nil
+}
+
+func hashEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareHashes(context.(*Hash), args[0], isEqual)
+}
+
+func hashEql(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareHashes(context.(*Hash), args[0], isEql)
+}
+
+// compareHashes reports whether other is a Hash with the same keys as hash
+// and values which are equal according to equal
+func compareHashes(hash *Hash, other RubyObject, equal func(a, b RubyObject) (bool, error)) (RubyObject, error) {
+	otherHash, ok := other.(*Hash)
+	if !ok || hash.Len() != otherHash.Len() {
+		return FALSE, nil
+	}
+	if hash == otherHash {
+		return TRUE, nil
+	}
+	for _, pair := range hash.pairs() {
+		value, ok := otherHash.Get(pair.Key)
+		if !ok {
+			return FALSE, nil
+		}
+		isEqual, err := equal(pair.Value, value)
+		if err != nil {
+			return nil, err
+		}
+		if !isEqual {
+			return FALSE, nil
+		}
+	}
+	return TRUE, nil
+}
+
+// hashHash combines the hash values of all key value pairs independent of
+// their order
+func hashHash(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	hash := context.(*Hash)
+	result := int64(hash.Len())
+	for _, pair := range hash.pairs() {
+		key, err := sendHash(pair.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := sendHash(pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		result += key*31 ^ value
+	}
+	return NewInteger(result), nil
+}
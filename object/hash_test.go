@@ -0,0 +1,217 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashSetAndGet(t *testing.T) {
+	hash := NewHash(nil)
+	hash.Set(&String{Value: "foo"}, NewInteger(1))
+	hash.Set(&Symbol{Value: "foo"}, NewInteger(2))
+	hash.Set(&String{Value: "foo"}, NewInteger(3))
+
+	if hash.Len() != 2 {
+		t.Logf("Expected hash to have 2 entries, got %d", hash.Len())
+		t.Fail()
+	}
+
+	value, ok := hash.Get(&String{Value: "foo"})
+	if !ok {
+		t.Logf("Expected hash to contain key %q", "foo")
+		t.FailNow()
+	}
+	checkResult(t, value, NewInteger(3))
+
+	_, ok = hash.Get(&String{Value: "bar"})
+	if ok {
+		t.Logf("Expected hash not to contain key %q", "bar")
+		t.Fail()
+	}
+}
+
+func TestHashInspect(t *testing.T) {
+	hash := NewHash(nil)
+	hash.Set(&Symbol{Value: "b"}, NewInteger(1))
+	hash.Set(&Symbol{Value: "a"}, NIL)
+
+	expected := "{:b=>1, :a=>nil}"
+	actual := hash.Inspect()
+
+	if expected != actual {
+		t.Logf("Expected hash to inspect to %q, got %q", expected, actual)
+		t.Fail()
+	}
+}
+
+func TestHashKeys(t *testing.T) {
+	hash := NewHash(nil)
+	hash.Set(&Symbol{Value: "b"}, NewInteger(1))
+	hash.Set(NewInteger(7), TRUE)
+
+	result, err := hashKeys(hash)
+
+	checkError(t, err, nil)
+
+	expected := NewArray(&Symbol{Value: "b"}, NewInteger(7))
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Logf("Expected keys to equal %s, got %s", toString(expected), toString(result))
+		t.Fail()
+	}
+}
+
+func TestHashValues(t *testing.T) {
+	hash := NewHash(nil)
+	hash.Set(&Symbol{Value: "b"}, NewInteger(1))
+	hash.Set(NewInteger(7), TRUE)
+
+	result, err := hashValues(hash)
+
+	checkError(t, err, nil)
+
+	expected := NewArray(NewInteger(1), TRUE)
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Logf("Expected values to equal %s, got %s", toString(expected), toString(result))
+		t.Fail()
+	}
+}
+
+func TestHashDefault(t *testing.T) {
+	missing := &Symbol{Value: "missing"}
+	t.Run("without default", func(t *testing.T) {
+		hash := NewHash(nil)
+
+		result, err := hashIndex(hash, missing)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NIL)
+	})
+	t.Run("default value", func(t *testing.T) {
+		hash, err := hashClassMethods["new"].Call(hashClass, NewInteger(0))
+		checkError(t, err, nil)
+
+		result, err := hashIndex(hash, missing)
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(0))
+
+		result, err = hashDefault(hash)
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(0))
+	})
+	t.Run("default block", func(t *testing.T) {
+		block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			args[0].(*Hash).Set(args[1], NewInteger(42))
+			return NewInteger(42), nil
+		}, "hash", "key")
+		hash, err := hashClassMethods["new"].Call(hashClass, block)
+		checkError(t, err, nil)
+
+		result, err := hashIndex(hash, missing)
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(42))
+
+		if hash.(*Hash).Len() != 1 {
+			t.Logf("Expected default block to store the key, got %s", hash.Inspect())
+			t.Fail()
+		}
+
+		result, err = hashDefault(hash)
+		checkError(t, err, nil)
+		checkResult(t, result, NIL)
+	})
+	t.Run("default=", func(t *testing.T) {
+		hash := NewHash(nil)
+
+		_, err := hashSetDefault(hash, &String{Value: "x"})
+		checkError(t, err, nil)
+
+		result, err := hashIndex(hash, missing)
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "x"})
+	})
+}
+
+func TestHashMethods(t *testing.T) {
+	a, b, c := &Symbol{Value: "a"}, &Symbol{Value: "b"}, &Symbol{Value: "c"}
+	newHash := func(pairs ...RubyObject) *Hash {
+		hash := NewHash(nil)
+		for i := 0; i < len(pairs); i += 2 {
+			hash.Set(pairs[i], pairs[i+1])
+		}
+		return hash
+	}
+	isOne := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		value, ok := args[1].(*Integer)
+		return nativeBoolToBoolean(ok && value.Value == 1), nil
+	}, "key", "value")
+	double := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerMul(args[0], NewInteger(2))
+	}, "value")
+	keyName := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return &String{Value: args[0].(*Symbol).Value}, nil
+	}, "key", "value")
+	sum := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		return integerAdd(args[1], args[2])
+	}, "key", "old", "new")
+	nested := newHash(a, newHash(b, NewArray(NewInteger(7))))
+
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  *Hash
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"fetch", hashFetch, newHash(a, NewInteger(1)), []RubyObject{a}, NewInteger(1)},
+		{"fetch with default", hashFetch, newHash(), []RubyObject{a, NewInteger(2)}, NewInteger(2)},
+		{"fetch with block", hashFetch, newHash(), []RubyObject{a, isOne}, FALSE},
+		{"dig", hashDig, nested, []RubyObject{a, b, NewInteger(0)}, NewInteger(7)},
+		{"dig missing", hashDig, nested, []RubyObject{c, b}, NIL},
+		{"key?", hashHasKey, newHash(a, NIL), []RubyObject{a}, TRUE},
+		{"key? missing", hashHasKey, newHash(a, NIL), []RubyObject{b}, FALSE},
+		{"to_a", hashToA, newHash(a, NewInteger(1), b, NewInteger(2)), nil, NewArray(NewArray(a, NewInteger(1)), NewArray(b, NewInteger(2)))},
+		{"map", hashMap, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{keyName}, NewArray(&String{Value: "a"}, &String{Value: "b"})},
+		{"select", hashSelect, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{isOne}, newHash(a, NewInteger(1))},
+		{"reject", hashReject, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{isOne}, newHash(b, NewInteger(2))},
+		{"any?", hashAny, newHash(a, NewInteger(2)), []RubyObject{isOne}, FALSE},
+		{"any? without block", hashAny, newHash(a, NewInteger(2)), nil, TRUE},
+		{"merge", hashMerge, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{newHash(b, NewInteger(3), c, NewInteger(4))}, newHash(a, NewInteger(1), b, NewInteger(3), c, NewInteger(4))},
+		{"merge with block", hashMerge, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{newHash(b, NewInteger(3)), sum}, newHash(a, NewInteger(1), b, NewInteger(5))},
+		{"transform_values", hashTransformValues, newHash(a, NewInteger(1), b, NewInteger(2)), []RubyObject{double}, newHash(a, NewInteger(2), b, NewInteger(4))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(tt.context, tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	t.Run("fetch missing key", func(t *testing.T) {
+		_, err := hashFetch(newHash(), a)
+
+		checkError(t, err, NewKeyError(a))
+		if err.Error() != "key not found: :a" {
+			t.Logf("Expected message %q, got %q", "key not found: :a", err.Error())
+			t.Fail()
+		}
+	})
+	t.Run("each", func(t *testing.T) {
+		var yielded []RubyObject
+		collect := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			yielded = append(yielded, args...)
+			return NIL, nil
+		}, "key", "value")
+		hash := newHash(a, NewInteger(1), b, NewInteger(2))
+
+		result, err := hashEach(hash, collect)
+
+		checkError(t, err, nil)
+		checkResult(t, result, hash)
+		checkResult(t, NewArray(yielded...), NewArray(a, NewInteger(1), b, NewInteger(2)))
+	})
+}
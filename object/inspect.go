@@ -0,0 +1,161 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// prettyPrintWidth is the line width pp tries to keep its output within
+const prettyPrintWidth = 80
+
+// inspect returns the representation of obj as produced by Ruby's inspect,
+// i.e. with Strings quoted and escaped
+func inspect(obj RubyObject) string {
+	return inspectValue(obj, make(map[RubyObject]bool))
+}
+
+// inspectValue returns the representation of obj like inspect. visited
+// holds all objects currently being inspected, so that recursive structures
+// are abbreviated instead of being inspected endlessly. Wrappers like self
+// are inspected as the object they wrap, so that they are recognized as
+// visited as well.
+func inspectValue(obj RubyObject, visited map[RubyObject]bool) string {
+	switch obj := unwrapObject(obj).(type) {
+	case *String:
+		return inspectString(obj.Value)
+	case *Array:
+		if visited[obj] {
+			return "[...]"
+		}
+		visited[obj] = true
+		defer delete(visited, obj)
+		elems := make([]string, len(obj.Elements))
+		for i, elem := range obj.Elements {
+			elems[i] = inspectValue(elem, visited)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case *Hash:
+		if visited[obj] {
+			return "{...}"
+		}
+		visited[obj] = true
+		defer delete(visited, obj)
+		pairs := obj.pairs()
+		elems := make([]string, len(pairs))
+		for i, pair := range pairs {
+			elems[i] = inspectValue(pair.Key, visited) + "=>" + inspectValue(pair.Value, visited)
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
+	case *Object:
+		return inspectObject(obj, visited)
+	default:
+		return obj.Inspect()
+	}
+}
+
+// inspectObject returns the default representation of obj, consisting of
+// its class name, its object ID and all of its instance variables, like
+// `#<Object:0x0000000000000010 @a=1>`
+func inspectObject(obj RubyObject, visited map[RubyObject]bool) string {
+	prefix := fmt.Sprintf("#<%s:0x%016x", className(obj), ObjectID(obj))
+	if visited[obj] {
+		return prefix + " ...>"
+	}
+	visited[obj] = true
+	defer delete(visited, obj)
+	var out strings.Builder
+	out.WriteString(prefix)
+	if ivars := instanceVariablesOf(obj, false); ivars != nil {
+		for i, name := range ivars.names {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(" " + name + "=" + inspectValue(ivars.values[name], visited))
+		}
+	}
+	out.WriteByte('>')
+	return out.String()
+}
+
+// className returns the name of the class of obj, skipping its eigenclass
+func className(obj RubyObject) string {
+	return realClass(obj).(RubyObject).Inspect()
+}
+
+var stringEscapes = map[rune]string{
+	'"':    `\"`,
+	'\\':   `\\`,
+	'\n':   `\n`,
+	'\t':   `\t`,
+	'\r':   `\r`,
+	'\f':   `\f`,
+	'\v':   `\v`,
+	'\a':   `\a`,
+	'\b':   `\b`,
+	'\x1b': `\e`,
+}
+
+// inspectString returns s as double quoted string literal
+func inspectString(s string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i, r := range s {
+		if escaped, ok := stringEscapes[r]; ok {
+			out.WriteString(escaped)
+			continue
+		}
+		switch {
+		case r == '#' && i+1 < len(s) && strings.ContainsRune("{$@", rune(s[i+1])):
+			out.WriteString(`\#`)
+		case r == utf8.RuneError:
+			fmt.Fprintf(&out, `\x%02X`, s[i])
+		case !unicode.IsPrint(r):
+			if r < 0x10000 {
+				fmt.Fprintf(&out, `\u%04X`, r)
+			} else {
+				fmt.Fprintf(&out, `\u{%X}`, r)
+			}
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
+// prettyInspect returns the representation of obj like inspect, but breaks
+// Arrays and Hashes which do not fit into the remaining line width onto
+// multiple lines, one element per line, as pp does. indent is the column the
+// representation starts at.
+func prettyInspect(obj RubyObject, indent int) string {
+	flat := inspect(obj)
+	if indent+len(flat) <= prettyPrintWidth {
+		return flat
+	}
+	separator := ",\n" + strings.Repeat(" ", indent+1)
+	switch obj := obj.(type) {
+	case *Array:
+		elems := make([]string, len(obj.Elements))
+		for i, elem := range obj.Elements {
+			elems[i] = prettyInspect(elem, indent+1)
+		}
+		return "[" + strings.Join(elems, separator) + "]"
+	case *Hash:
+		pairs := obj.pairs()
+		elems := make([]string, len(pairs))
+		for i, pair := range pairs {
+			key := inspect(pair.Key) + "=>"
+			value := inspect(pair.Value)
+			if indent+1+len(key)+len(value) > prettyPrintWidth {
+				// values which do not fit behind their key start on the next line
+				value = "\n" + strings.Repeat(" ", indent+2) + prettyInspect(pair.Value, indent+2)
+			}
+			elems[i] = key + value
+		}
+		return "{" + strings.Join(elems, separator) + "}"
+	default:
+		return flat
+	}
+}
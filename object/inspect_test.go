@@ -0,0 +1,141 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	hash := &Hash{}
+	hash.Set(&Symbol{"a"}, str("b"))
+	tests := []struct {
+		obj      RubyObject
+		expected string
+	}{
+		{str("foo"), `"foo"`},
+		{str("a\"b\\c\n\t\x1b"), `"a\"b\\c\n\t\e"`},
+		{str("#{x} #$y # z"), `"\#{x} \#$y # z"`},
+		{str("é\x00\xff"), `"é\u0000\xFF"`},
+		{NewArray(str("a"), NewInteger(1), NIL), `["a", 1, nil]`},
+		{hash, `{:a=>"b"}`},
+		{&Symbol{"a"}, ":a"},
+	}
+
+	for _, tt := range tests {
+		actual := inspect(tt.obj)
+
+		if actual != tt.expected {
+			t.Logf("Expected inspect to return %s, got %s", tt.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestInspectObject(t *testing.T) {
+	t.Run("without instance variables", func(t *testing.T) {
+		obj := &Object{}
+
+		expected := fmt.Sprintf("#<Object:0x%016x>", ObjectID(obj))
+		if actual := obj.Inspect(); actual != expected {
+			t.Logf("Expected Inspect to return %s, got %s", expected, actual)
+			t.Fail()
+		}
+	})
+	t.Run("with instance variables", func(t *testing.T) {
+		obj := &Object{}
+		InstanceVariableSet(obj, "@a", NewInteger(1))
+		InstanceVariableSet(obj, "@b", &String{Value: "foo"})
+
+		expected := fmt.Sprintf(`#<Object:0x%016x @a=1, @b="foo">`, ObjectID(obj))
+		if actual := inspect(obj); actual != expected {
+			t.Logf("Expected inspect to return %s, got %s", expected, actual)
+			t.Fail()
+		}
+	})
+	t.Run("recursive", func(t *testing.T) {
+		obj := &Object{}
+		other := &Object{}
+		arr := NewArray(obj)
+		arr.Elements = append(arr.Elements, arr)
+		InstanceVariableSet(obj, "@other", other)
+		InstanceVariableSet(other, "@arr", arr)
+
+		expected := fmt.Sprintf(
+			"#<Object:0x%016x @other=#<Object:0x%016x @arr=[#<Object:0x%016x ...>, [...]]>>",
+			ObjectID(obj), ObjectID(other), ObjectID(obj),
+		)
+		if actual := inspect(obj); actual != expected {
+			t.Logf("Expected inspect to return %s, got %s", expected, actual)
+			t.Fail()
+		}
+	})
+	t.Run("recursive through self", func(t *testing.T) {
+		obj := &Object{}
+		InstanceVariableSet(obj, "@me", &Self{obj})
+
+		expected := fmt.Sprintf("#<Object:0x%016x @me=#<Object:0x%016x ...>>", ObjectID(obj), ObjectID(obj))
+		if actual := inspect(&Self{obj}); actual != expected {
+			t.Logf("Expected inspect to return %s, got %s", expected, actual)
+			t.Fail()
+		}
+	})
+	t.Run("main", func(t *testing.T) {
+		main := &Self{&mainObject{&Object{}}}
+
+		if actual := inspect(main); actual != "main" {
+			t.Logf("Expected inspect to return main, got %s", actual)
+			t.Fail()
+		}
+	})
+}
+
+func TestKernelInspectAndToS(t *testing.T) {
+	obj := AddMethod(&Object{}, "foo", &Function{})
+	InstanceVariableSet(obj, "@a", NewInteger(1))
+
+	result, err := Send(obj, "to_s")
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "#<Object>"})
+
+	result, err = Send(obj, "inspect")
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: fmt.Sprintf("#<Object:0x%016x @a=1>", ObjectID(obj))})
+
+	result, err = Send(&String{Value: "a"}, "inspect")
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: `"a"`})
+
+	result, err = Send(NIL, "to_s")
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: ""})
+}
+
+func TestPrettyInspect(t *testing.T) {
+	t.Run("short", func(t *testing.T) {
+		obj := NewArray(NewInteger(1), NewArray(NewInteger(2)))
+
+		actual := prettyInspect(obj, 0)
+
+		if actual != "[1, [2]]" {
+			t.Logf("Expected prettyInspect to return %q, got %q", "[1, [2]]", actual)
+			t.Fail()
+		}
+	})
+	t.Run("nested", func(t *testing.T) {
+		long := &String{Value: strings.Repeat("x", 40)}
+		hash := &Hash{}
+		hash.Set(&Symbol{"key"}, NewArray(long, long))
+		obj := NewArray(NewInteger(1), hash)
+
+		actual := prettyInspect(obj, 0)
+
+		quoted := `"` + long.Value + `"`
+		expected := "[1,\n {:key=>\n   [" + quoted + ",\n    " + quoted + "]}]"
+		if actual != expected {
+			t.Logf("Expected prettyInspect to return\n%s\ngot\n%s", expected, actual)
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package object
+
+// evalSelf returns obj wrapped as Self, so that its private methods can be
+// called from within evaluated blocks. Methods defined within these blocks
+// are added to definee, if given.
+func evalSelf(obj RubyObject, definee methodDefiner) RubyObject {
+	obj = unwrapSelf(obj)
+	if definee == nil {
+		return &Self{obj}
+	}
+	return &definingSelf{Self: &Self{obj}, definee: definee}
+}
+
+// singletonDefinee returns the eigenclass holding the class methods of obj,
+// if obj is a class. It returns nil for all other objects, which get their
+// singleton methods through AddMethod.
+func singletonDefinee(obj RubyObject) methodDefiner {
+	obj = unwrapObject(obj)
+	if _, ok := obj.(RubyClassObject); !ok {
+		return nil
+	}
+	if eigenClass, ok := obj.Class().(*eigenclass); ok {
+		return eigenClass
+	}
+	return nil
+}
+
+// kernelInstanceEval evaluates the block with self set to context. The
+// block gets context passed as argument.
+func kernelInstanceEval(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	return block.callWithSelf(evalSelf(context, singletonDefinee(context)), context)
+}
+
+// kernelInstanceExec evaluates the block with self set to context, passing
+// all arguments to the block
+func kernelInstanceExec(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	return block.callWithSelf(evalSelf(context, singletonDefinee(context)), args...)
+}
+
+// classDefinee returns the class or module context refers to as target for
+// method definitions
+func classDefinee(context RubyObject) methodDefiner {
+	definee, _ := unwrapObject(context).(methodDefiner)
+	return definee
+}
+
+// moduleClassEval evaluates the block with self set to the receiving class
+// or module. Methods defined within the block become instance methods of
+// the class.
+func moduleClassEval(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	return block.callWithSelf(evalSelf(context, classDefinee(context)), context)
+}
+
+// moduleClassExec works like moduleClassEval, but passes all arguments to
+// the block
+func moduleClassExec(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	return block.callWithSelf(evalSelf(context, classDefinee(context)), args...)
+}
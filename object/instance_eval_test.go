@@ -0,0 +1,127 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+)
+
+// selfBlock returns a block which calls fn with the self it is evaluated
+// with and all of its arguments
+func selfBlock(fn func(self RubyObject, args ...RubyObject) (RubyObject, error)) *Proc {
+	block := &Proc{Env: NewEnvironment()}
+	block.Parameters = []*ast.Identifier{{Value: "x"}}
+	block.CallFn = func(body *ast.BlockStatement, env Environment) (RubyObject, error) {
+		self, _ := env.Get("self")
+		arg, _ := env.Get("x")
+		return fn(self, arg)
+	}
+	return block
+}
+
+func TestKernelInstanceEval(t *testing.T) {
+	t.Run("instance_eval", func(t *testing.T) {
+		context := NewArray(NewInteger(1))
+		var self, arg RubyObject
+		block := selfBlock(func(s RubyObject, args ...RubyObject) (RubyObject, error) {
+			self, arg = s, args[0]
+			return Send(s, "first")
+		})
+
+		result, err := Send(context, "instance_eval", block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(1))
+		if !reflect.DeepEqual(self, &Self{context}) {
+			t.Logf("Expected block to be evaluated with self %#v, got %#v", &Self{context}, self)
+			t.Fail()
+		}
+		if arg != context {
+			t.Logf("Expected block to get the receiver passed, got %#v", arg)
+			t.Fail()
+		}
+	})
+	t.Run("instance_exec", func(t *testing.T) {
+		context := NewArray()
+		block := selfBlock(func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+			return NewArray(unwrapSelf(self), args[0]), nil
+		})
+
+		result, err := Send(context, "instance_exec", NewInteger(3), block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(context, NewInteger(3)))
+	})
+	t.Run("private methods", func(t *testing.T) {
+		block := selfBlock(func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+			return Send(self, "respond_to_missing?", &Symbol{"foo"}, FALSE)
+		})
+
+		result, err := Send(NewArray(), "instance_eval", block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, FALSE)
+	})
+	t.Run("without block", func(t *testing.T) {
+		_, err := Send(NewArray(), "instance_eval")
+		checkError(t, err, NewArgumentError("no block given"))
+
+		_, err = Send(NewArray(), "instance_exec", NewInteger(1))
+		checkError(t, err, NewArgumentError("no block given"))
+	})
+	t.Run("class methods", func(t *testing.T) {
+		context := newClass("Foo", objectClass, nil, nil)
+		block := selfBlock(func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+			return AddMethod(self, "bar", &Function{}), nil
+		})
+
+		_, err := Send(context, "instance_eval", block)
+
+		checkError(t, err, nil)
+		if _, ok := findMethod(context, "bar"); !ok {
+			t.Logf("Expected bar to be defined as class method")
+			t.Fail()
+		}
+	})
+}
+
+func TestModuleClassEval(t *testing.T) {
+	t.Run("class_eval", func(t *testing.T) {
+		context := newClass("Foo", objectClass, nil, nil)
+		block := selfBlock(func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+			AddMethod(self, "bar", &Function{})
+			return args[0], nil
+		})
+
+		result, err := Send(context, "class_eval", block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, context)
+		if _, ok := context.Methods()["bar"]; !ok {
+			t.Logf("Expected bar to be defined as instance method")
+			t.Fail()
+		}
+		if _, ok := findMethod(context, "bar"); ok {
+			t.Logf("Expected bar not to be defined as class method")
+			t.Fail()
+		}
+	})
+	t.Run("module_exec", func(t *testing.T) {
+		context := newModule("Foo", nil)
+		block := selfBlock(func(self RubyObject, args ...RubyObject) (RubyObject, error) {
+			AddMethod(self, "bar", &Function{})
+			return args[0], nil
+		})
+
+		result, err := Send(context, "module_exec", NewInteger(2), block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(2))
+		including := mixin(newClass("Bar", objectClass, nil, nil), context)
+		if _, ok := including.Methods()["bar"]; !ok {
+			t.Logf("Expected bar to be defined as module method")
+			t.Fail()
+		}
+	})
+}
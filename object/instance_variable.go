@@ -0,0 +1,170 @@
+package object
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// instanceVariableTable holds the instance variables of a single object in
+// the order of their first assignment
+type instanceVariableTable struct {
+	names  []string
+	values map[string]RubyObject
+}
+
+func (t *instanceVariableTable) get(name string) (RubyObject, bool) {
+	value, ok := t.values[name]
+	return value, ok
+}
+
+func (t *instanceVariableTable) set(name string, value RubyObject) {
+	if t.values == nil {
+		t.values = make(map[string]RubyObject)
+	}
+	if _, ok := t.values[name]; !ok {
+		t.names = append(t.names, name)
+	}
+	t.values[name] = value
+}
+
+// instanceVariableHolder is implemented by objects carrying their own
+// instance variable table
+type instanceVariableHolder interface {
+	instanceVariables() *instanceVariableTable
+}
+
+// genericInstanceVariables holds the instance variables of all objects not
+// implementing instanceVariableHolder, like Strings or Arrays
+var genericInstanceVariables = struct {
+	sync.Mutex
+	tables map[RubyObject]*instanceVariableTable
+}{tables: make(map[RubyObject]*instanceVariableTable)}
+
+// unwrapObject returns the object wrapped by Self or an extendedObject
+func unwrapObject(obj RubyObject) RubyObject {
+	for {
+		switch wrapper := obj.(type) {
+		case *Self:
+			obj = wrapper.RubyObject
+		case *definingSelf:
+			obj = wrapper.RubyObject
+		case *extendedObject:
+			obj = wrapper.RubyObject
+		default:
+			return obj
+		}
+	}
+}
+
+// instanceVariablesOf returns the instance variable table of obj. It
+// returns nil if obj has none and create is false.
+func instanceVariablesOf(obj RubyObject, create bool) *instanceVariableTable {
+	obj = unwrapObject(obj)
+	if holder, ok := obj.(instanceVariableHolder); ok {
+		return holder.instanceVariables()
+	}
+	genericInstanceVariables.Lock()
+	defer genericInstanceVariables.Unlock()
+	table, ok := genericInstanceVariables.tables[obj]
+	if !ok && create {
+		table = &instanceVariableTable{}
+		genericInstanceVariables.tables[obj] = table
+	}
+	return table
+}
+
+// IsInstanceVariableName reports whether name is a valid instance variable
+// name, i.e. an identifier prefixed with a single `@`
+func IsInstanceVariableName(name string) bool {
+	if len(name) < 2 || name[0] != '@' {
+		return false
+	}
+	for i, r := range name[1:] {
+		if r == '_' || unicode.IsLetter(r) || r >= utf8.RuneSelf {
+			continue
+		}
+		if i > 0 && unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// InstanceVariableGet returns the instance variable name of obj. It reports
+// false if the variable is not defined.
+func InstanceVariableGet(obj RubyObject, name string) (RubyObject, bool) {
+	table := instanceVariablesOf(obj, false)
+	if table == nil {
+		return nil, false
+	}
+	return table.get(name)
+}
+
+// InstanceVariableSet sets the instance variable name of obj to value. It
+// returns a FrozenError if obj is frozen.
+func InstanceVariableSet(obj RubyObject, name string, value RubyObject) error {
+	if isFrozen(unwrapObject(obj)) {
+		return NewFrozenError(obj)
+	}
+	instanceVariablesOf(obj, true).set(name, value)
+	return nil
+}
+
+// instanceVariableNameArgument returns the instance variable name given as
+// Symbol or String. It returns a NameError if the name is not valid.
+func instanceVariableNameArgument(arg RubyObject) (string, error) {
+	name, err := nameArgument(arg)
+	if err != nil {
+		return "", err
+	}
+	if !IsInstanceVariableName(name) {
+		return "", NewInvalidInstanceVariableNameError(name)
+	}
+	return name, nil
+}
+
+func kernelInstanceVariableGet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := instanceVariableNameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := InstanceVariableGet(context, name)
+	if !ok {
+		return NIL, nil
+	}
+	return value, nil
+}
+
+func kernelInstanceVariableSet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := instanceVariableNameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := InstanceVariableSet(context, name, args[1]); err != nil {
+		return nil, err
+	}
+	return args[1], nil
+}
+
+func kernelInstanceVariableDefined(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := instanceVariableNameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	_, ok := InstanceVariableGet(context, name)
+	return nativeBoolToBoolean(ok), nil
+}
+
+func kernelInstanceVariables(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	names := NewArray()
+	table := instanceVariablesOf(context, false)
+	if table == nil {
+		return names, nil
+	}
+	for _, name := range table.names {
+		names.Elements = append(names.Elements, NewSymbol(name))
+	}
+	return names, nil
+}
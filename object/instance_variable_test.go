@@ -0,0 +1,110 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsInstanceVariableName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"@foo", true},
+		{"@_foo1", true},
+		{"@Foo", true},
+		{"@", false},
+		{"foo", false},
+		{"@@foo", false},
+		{"@1foo", false},
+		{"@foo-bar", false},
+	}
+
+	for _, testCase := range tests {
+		actual := IsInstanceVariableName(testCase.name)
+		if actual != testCase.expected {
+			t.Logf("Expected IsInstanceVariableName(%q) to return %t, got %t", testCase.name, testCase.expected, actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestKernelInstanceVariables(t *testing.T) {
+	t.Run("set and get", func(t *testing.T) {
+		context := &Object{}
+
+		result, err := Send(context, "instance_variable_set", &Symbol{"@foo"}, NewInteger(3))
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(3))
+
+		_, err = Send(context, "instance_variable_set", &String{Value: "@bar"}, TRUE)
+		checkError(t, err, nil)
+
+		result, err = Send(context, "instance_variable_get", &Symbol{"@foo"})
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(3))
+
+		result, err = Send(context, "instance_variable_get", &Symbol{"@qux"})
+		checkError(t, err, nil)
+		checkResult(t, result, NIL)
+
+		result, err = Send(context, "instance_variable_defined?", &Symbol{"@bar"})
+		checkError(t, err, nil)
+		checkResult(t, result, TRUE)
+
+		result, err = Send(context, "instance_variable_defined?", &Symbol{"@qux"})
+		checkError(t, err, nil)
+		checkResult(t, result, FALSE)
+
+		result, err = Send(context, "instance_variables")
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(&Symbol{"@foo"}, &Symbol{"@bar"}))
+	})
+	t.Run("object without own table", func(t *testing.T) {
+		context := &String{Value: "foo"}
+
+		_, err := Send(context, "instance_variable_set", &Symbol{"@foo"}, NewInteger(3))
+		checkError(t, err, nil)
+
+		result, err := Send(context, "instance_variables")
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(&Symbol{"@foo"}))
+
+		result, err = Send(&String{Value: "foo"}, "instance_variables")
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray())
+	})
+	t.Run("wrapped object", func(t *testing.T) {
+		object := &Object{}
+		context := &Self{AddMethod(object, "foo", &Function{})}
+
+		_, err := Send(context, "instance_variable_set", &Symbol{"@foo"}, NewInteger(3))
+		checkError(t, err, nil)
+
+		value, ok := InstanceVariableGet(object, "@foo")
+		if !ok || !reflect.DeepEqual(value, NewInteger(3)) {
+			t.Logf("Expected wrapped object to have @foo set to 3, got %v", value)
+			t.Fail()
+		}
+	})
+	t.Run("invalid names", func(t *testing.T) {
+		context := &Object{}
+
+		_, err := Send(context, "instance_variable_get", &Symbol{"foo"})
+		checkError(t, err, NewInvalidInstanceVariableNameError("foo"))
+
+		_, err = Send(context, "instance_variable_set", &String{Value: "@1"}, NIL)
+		checkError(t, err, NewInvalidInstanceVariableNameError("@1"))
+
+		_, err = Send(context, "instance_variable_defined?", NewInteger(1))
+		checkError(t, err, NewTypeError("1 is not a symbol nor a string"))
+	})
+	t.Run("frozen objects", func(t *testing.T) {
+		_, err := Send(NewInteger(1), "instance_variable_set", &Symbol{"@foo"}, NIL)
+		checkError(t, err, NewFrozenError(NewInteger(1)))
+
+		str := &String{Value: "foo", frozen: true}
+		_, err = Send(str, "instance_variable_set", &Symbol{"@foo"}, NIL)
+		checkError(t, err, NewFrozenError(str))
+	})
+}
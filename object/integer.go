@@ -1,15 +1,46 @@
 package object
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
 
-var integerClass RubyClassObject = newClass("Integer", objectClass, integerMethods, integerClassMethods)
+var (
+	numericClass RubyClassObject = mixin(newClass("Numeric", objectClass, nil, nil), comparableModule)
+	integerClass RubyClassObject = newClass("Integer", numericClass, integerMethods, integerClassMethods)
+)
 
 func init() {
+	classes.Set("Numeric", numericClass)
 	classes.Set("Integer", integerClass)
 }
 
-// NewInteger returns a new Integer with the given value
+// The range of Integers which are preallocated
+const (
+	minCachedInteger = -128
+	maxCachedInteger = 1024
+)
+
+// cachedIntegers holds the preallocated Integers, which are shared as they
+// cannot be modified
+var cachedIntegers = func() []*Integer {
+	integers := make([]*Integer, maxCachedInteger-minCachedInteger+1)
+	for i := range integers {
+		integers[i] = &Integer{Value: int64(i + minCachedInteger)}
+	}
+	return integers
+}()
+
+// NewInteger returns an Integer with the given value. Small Integers are
+// preallocated and not allocated again.
 func NewInteger(value int64) *Integer {
+	if value >= minCachedInteger && value <= maxCachedInteger {
+		return cachedIntegers[value-minCachedInteger]
+	}
+	allocatedIntegers.add()
 	return &Integer{Value: value}
 }
 
@@ -27,12 +58,42 @@ func (i *Integer) Type() Type { return INTEGER_OBJ }
 // Class returns integerClass
 func (i *Integer) Class() RubyClass { return integerClass }
 
+func (i *Integer) hashKey() hashKey {
+	return hashKey{Type: i.Type(), Value: i.Value}
+}
+
 var integerClassMethods = map[string]RubyMethod{}
 
 var integerMethods = map[string]RubyMethod{
-	"div": withArity(1, publicMethod(integerDiv)),
-	"/":   withArity(1, publicMethod(integerDiv)),
-	"*":   withArity(1, publicMethod(integerMul)),
+	"+":      withArity(1, publicMethod(integerAdd)),
+	"-":      withArity(1, publicMethod(integerSub)),
+	"div":    withArity(1, publicMethod(integerDiv)),
+	"/":      withArity(1, publicMethod(integerDiv)),
+	"*":      withArity(1, publicMethod(integerMul)),
+	"%":      withArity(1, publicMethod(integerModulo)),
+	"modulo": withArity(1, publicMethod(integerModulo)),
+	"**":     withArity(1, publicMethod(integerPow)),
+	"pow":    withArityRange(1, 2, publicMethod(integerPow)),
+	"divmod": withArity(1, publicMethod(integerDivmod)),
+	"abs":    withArity(0, publicMethod(integerAbs)),
+	"gcd":    withArity(1, publicMethod(integerGcd)),
+	"lcm":    withArity(1, publicMethod(integerLcm)),
+	"&":      withArity(1, publicMethod(integerBitAnd)),
+	"|":      withArity(1, publicMethod(integerBitOr)),
+	"^":      withArity(1, publicMethod(integerBitXor)),
+	"~":      withArity(0, publicMethod(integerBitNot)),
+	"<<":     withArity(1, publicMethod(integerLeftShift)),
+	">>":     withArity(1, publicMethod(integerRightShift)),
+	"even?":  withArity(0, publicMethod(integerEven)),
+	"odd?":   withArity(0, publicMethod(integerOdd)),
+	"zero?":  withArity(0, publicMethod(integerZero)),
+	"<=>":    withArity(1, publicMethod(integerSpaceship)),
+	"to_f":   withArity(0, publicMethod(integerToF)),
+	"to_s":   withArityRange(0, 1, publicMethod(integerToS)),
+	"times":  withArity(0, publicMethod(integerTimes)),
+	"upto":   withArity(1, publicMethod(integerUpto)),
+	"downto": withArity(1, publicMethod(integerDownto)),
+	"step":   withArityRange(1, 2, publicMethod(integerStep)),
 }
 
 func integerDiv(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -44,7 +105,28 @@ func integerDiv(context RubyObject, args ...RubyObject) (RubyObject, error) {
 	if divisor.Value == 0 {
 		return nil, NewZeroDivisionError()
 	}
-	return NewInteger(i.Value / divisor.Value), nil
+	quotient, _ := floorDivmod(i.Value, divisor.Value)
+	return NewInteger(quotient), nil
+}
+
+// floorDivmod divides a by b rounding towards negative infinity, as Ruby
+// does. The remainder therefore has the sign of b.
+func floorDivmod(a, b int64) (int64, int64) {
+	quotient, remainder := a/b, a%b
+	if remainder != 0 && (remainder < 0) != (b < 0) {
+		quotient--
+		remainder += b
+	}
+	return quotient, remainder
+}
+
+// floatModulo returns the modulo of a and b with the sign of b
+func floatModulo(a, b float64) float64 {
+	remainder := math.Mod(a, b)
+	if remainder != 0 && (remainder < 0) != (b < 0) {
+		remainder += b
+	}
+	return remainder
 }
 
 func integerMul(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -56,6 +138,30 @@ func integerMul(context RubyObject, args ...RubyObject) (RubyObject, error) {
 	return NewInteger(i.Value * factor.Value), nil
 }
 
+func integerSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	if f, ok := args[0].(*Float); ok {
+		return floatSpaceship(NewFloat(float64(i.Value)), f)
+	}
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return NIL, nil
+	}
+	switch {
+	case i.Value < other.Value:
+		return NewInteger(-1), nil
+	case i.Value > other.Value:
+		return NewInteger(1), nil
+	default:
+		return NewInteger(0), nil
+	}
+}
+
+func integerToF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	return NewFloat(float64(i.Value)), nil
+}
+
 func integerAdd(context RubyObject, args ...RubyObject) (RubyObject, error) {
 	i := context.(*Integer)
 	add, ok := args[0].(*Integer)
@@ -64,3 +170,464 @@ func integerAdd(context RubyObject, args ...RubyObject) (RubyObject, error) {
 	}
 	return NewInteger(i.Value + add.Value), nil
 }
+
+func integerSub(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	sub, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+	return NewInteger(i.Value - sub.Value), nil
+}
+
+func integerTimes(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(i, "times"), nil
+	}
+	for n := int64(0); n < i.Value; n++ {
+		if _, err := block.Call(NewInteger(n)); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+func integerUpto(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	args, block := extractBlock(args)
+	limit, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewComparisonError(i, args[0])
+	}
+	if block == nil {
+		return NewEnumerator(i, "upto", limit), nil
+	}
+	for n := i.Value; n <= limit.Value; n++ {
+		if _, err := block.Call(NewInteger(n)); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+func integerDownto(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	args, block := extractBlock(args)
+	limit, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewComparisonError(i, args[0])
+	}
+	if block == nil {
+		return NewEnumerator(i, "downto", limit), nil
+	}
+	for n := i.Value; n >= limit.Value; n-- {
+		if _, err := block.Call(NewInteger(n)); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+// integerStep yields the values from self up to limit in increments of step.
+// If limit or step is a Float all yielded values are Floats.
+func integerStep(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	args, block := extractBlock(args)
+	step := RubyObject(NewInteger(1))
+	if len(args) == 2 {
+		step = args[1]
+	}
+	limitValue, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewComparisonError(i, args[0])
+	}
+	stepValue, ok := toFloat(step)
+	if !ok {
+		return nil, NewCoercionTypeError(step, i)
+	}
+	if stepValue == 0 {
+		return nil, NewArgumentError("step can't be 0")
+	}
+	if block == nil {
+		return NewEnumerator(i, "step", args...), nil
+	}
+	_, intLimit := args[0].(*Integer)
+	_, intStep := step.(*Integer)
+	if intLimit && intStep {
+		limit, by := args[0].(*Integer).Value, step.(*Integer).Value
+		for n := i.Value; (by > 0 && n <= limit) || (by < 0 && n >= limit); n += by {
+			if _, err := block.Call(NewInteger(n)); err != nil {
+				return nil, err
+			}
+		}
+		return i, nil
+	}
+	count := int64(math.Floor((limitValue-float64(i.Value))/stepValue + 1e-9))
+	for n := int64(0); n <= count; n++ {
+		if _, err := block.Call(NewFloat(float64(i.Value) + float64(n)*stepValue)); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+func integerModulo(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	switch divisor := args[0].(type) {
+	case *Integer:
+		if divisor.Value == 0 {
+			return nil, NewZeroDivisionError()
+		}
+		_, remainder := floorDivmod(i.Value, divisor.Value)
+		return NewInteger(remainder), nil
+	case *Float:
+		return NewFloat(floatModulo(float64(i.Value), divisor.Value)), nil
+	default:
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+}
+
+// integerPow raises self to the power of the first argument. Negative
+// exponents result in a Float. If a modulus is given the result is computed
+// modulo it. Results exceeding the range of an Integer raise a RangeError.
+func integerPow(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	if len(args) == 2 {
+		return integerModPow(i, args[0], args[1])
+	}
+	switch exponent := args[0].(type) {
+	case *Integer:
+		if exponent.Value < 0 {
+			if i.Value == 0 {
+				return nil, NewZeroDivisionError()
+			}
+			return NewFloat(math.Pow(float64(i.Value), float64(exponent.Value))), nil
+		}
+		result, base, ok := int64(1), i.Value, true
+		for e := exponent.Value; e > 0 && ok; e >>= 1 {
+			if e&1 == 1 {
+				result, ok = multiply(result, base)
+			}
+			if e > 1 && ok {
+				base, ok = multiply(base, base)
+			}
+		}
+		if !ok {
+			return nil, NewRangeError("%d ** %d exceeds the range of Integer", i.Value, exponent.Value)
+		}
+		return NewInteger(result), nil
+	case *Float:
+		return NewFloat(math.Pow(float64(i.Value), exponent.Value)), nil
+	default:
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+}
+
+// multiply returns a * b and reports whether it fits into an Integer
+func multiply(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+func integerModPow(i *Integer, exponentArg, modulusArg RubyObject) (RubyObject, error) {
+	exponent, ok := exponentArg.(*Integer)
+	if !ok {
+		return nil, NewTypeError("Integer#pow() 2nd argument not allowed unless a 1st argument is integer")
+	}
+	modulus, ok := modulusArg.(*Integer)
+	if !ok {
+		return nil, NewTypeError("Integer#pow() 2nd argument not allowed unless all arguments are integers")
+	}
+	if exponent.Value < 0 {
+		return nil, NewRangeError("Integer#pow() 1st argument cannot be negative when 2nd argument specified")
+	}
+	if modulus.Value == 0 {
+		return nil, NewZeroDivisionError()
+	}
+	result := new(big.Int).Exp(big.NewInt(i.Value), big.NewInt(exponent.Value), big.NewInt(modulus.Value))
+	_, remainder := floorDivmod(result.Int64(), modulus.Value)
+	return NewInteger(remainder), nil
+}
+
+func integerDivmod(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	switch divisor := args[0].(type) {
+	case *Integer:
+		if divisor.Value == 0 {
+			return nil, NewZeroDivisionError()
+		}
+		quotient, remainder := floorDivmod(i.Value, divisor.Value)
+		return NewArray(NewInteger(quotient), NewInteger(remainder)), nil
+	case *Float:
+		if divisor.Value == 0 {
+			return nil, NewZeroDivisionError()
+		}
+		quotient := math.Floor(float64(i.Value) / divisor.Value)
+		return NewArray(NewInteger(int64(quotient)), NewFloat(floatModulo(float64(i.Value), divisor.Value))), nil
+	default:
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+}
+
+func integerAbs(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	if i.Value < 0 {
+		return NewInteger(-i.Value), nil
+	}
+	return i, nil
+}
+
+// integerOperand returns the value of obj if it is an Integer or a TypeError
+// otherwise
+func integerOperand(obj RubyObject) (int64, error) {
+	i, ok := obj.(*Integer)
+	if !ok {
+		return 0, NewTypeError("not an integer")
+	}
+	return i.Value, nil
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func integerGcd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	other, err := integerOperand(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewInteger(gcd(i.Value, other)), nil
+}
+
+func integerLcm(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	other, err := integerOperand(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if i.Value == 0 || other == 0 {
+		return NewInteger(0), nil
+	}
+	lcm := i.Value / gcd(i.Value, other) * other
+	if lcm < 0 {
+		lcm = -lcm
+	}
+	return NewInteger(lcm), nil
+}
+
+func integerBitAnd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+	return NewInteger(i.Value & other.Value), nil
+}
+
+func integerBitOr(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+	return NewInteger(i.Value | other.Value), nil
+}
+
+func integerBitXor(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	other, ok := args[0].(*Integer)
+	if !ok {
+		return nil, NewCoercionTypeError(args[0], i)
+	}
+	return NewInteger(i.Value ^ other.Value), nil
+}
+
+func integerBitNot(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	return NewInteger(^i.Value), nil
+}
+
+// shift shifts value by count bits to the left, or to the right if count is
+// negative. It reports whether the result fits into an Integer.
+func shift(value, count int64) (int64, bool) {
+	switch {
+	case count >= 64:
+		return 0, value == 0
+	case count >= 0:
+		result := value << uint(count)
+		return result, result>>uint(count) == value
+	case count <= -64:
+		if value < 0 {
+			return -1, true
+		}
+		return 0, true
+	default:
+		return value >> uint(-count), true
+	}
+}
+
+func integerLeftShift(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	count, err := integerOperand(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return shiftedInteger(i, "<<", count, count)
+}
+
+func integerRightShift(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	count, err := integerOperand(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return shiftedInteger(i, ">>", count, -count)
+}
+
+// shiftedInteger returns i shifted by count bits to the left, raising a
+// RangeError if the result exceeds the range of an Integer
+func shiftedInteger(i *Integer, operator string, operand, count int64) (RubyObject, error) {
+	result, ok := shift(i.Value, count)
+	if !ok {
+		return nil, NewRangeError("%d %s %d exceeds the range of Integer", i.Value, operator, operand)
+	}
+	return NewInteger(result), nil
+}
+
+func integerEven(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	return nativeBoolToBoolean(i.Value%2 == 0), nil
+}
+
+func integerOdd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	return nativeBoolToBoolean(i.Value%2 != 0), nil
+}
+
+func integerZero(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	return nativeBoolToBoolean(i.Value == 0), nil
+}
+
+// radixArgument returns the radix given as first element of args or 10 if
+// there is none. It returns an ArgumentError if the radix is not within
+// 2..36.
+func radixArgument(args []RubyObject) (int, error) {
+	if len(args) == 0 {
+		return 10, nil
+	}
+	radix, err := integerArgument(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if radix.Value < 2 || radix.Value > 36 {
+		return 0, NewArgumentError("invalid radix %d", radix.Value)
+	}
+	return int(radix.Value), nil
+}
+
+func integerToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	i := context.(*Integer)
+	radix, err := radixArgument(args)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: strconv.FormatInt(i.Value, radix)}, nil
+}
+
+// basePrefixes maps the prefixes of integer literals to their base
+var basePrefixes = map[string]int{
+	"0x": 16,
+	"0b": 2,
+	"0o": 8,
+	"0d": 10,
+}
+
+// trimBasePrefix removes a prefix denoting the base of the number from s if
+// it matches base. If base is 0 the base is determined by the prefix,
+// defaulting to 10. It returns the remaining digits and their base.
+func trimBasePrefix(s string, base int) (string, int) {
+	if len(s) >= 2 && s[0] == '0' {
+		if prefixBase, ok := basePrefixes[strings.ToLower(s[:2])]; ok && (base == 0 || base == prefixBase) {
+			return s[2:], prefixBase
+		}
+	}
+	if base != 0 {
+		return s, base
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return s[1:], 8
+	}
+	return s, 10
+}
+
+// digitValue returns the value of c as digit, with letters representing the
+// digits from 10 to 35. It returns 36 if c is no digit at all.
+func digitValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'Z':
+		return int(c-'A') + 10
+	default:
+		return 36
+	}
+}
+
+// parseInteger parses the integer at the start of s in the given base, as
+// String#to_i does. Single underscores between digits are ignored. If strict
+// is set, s must contain nothing but the integer and surrounding whitespace,
+// as required by Kernel#Integer, otherwise parsing stops at the first
+// invalid character. The returned bool reports whether s was valid.
+func parseInteger(s string, base int, strict bool) (int64, bool) {
+	s = strings.TrimLeft(s, leftWhitespace)
+	if strict {
+		s = strings.TrimRight(s, leftWhitespace)
+	}
+	negative := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	s, base = trimBasePrefix(s, base)
+	var value int64
+	digits, i := 0, 0
+	for ; i < len(s); i++ {
+		if s[i] == '_' {
+			if digits == 0 || i+1 == len(s) || s[i+1] == '_' {
+				break
+			}
+			continue
+		}
+		digit := digitValue(s[i])
+		if digit >= base {
+			break
+		}
+		value = value*int64(base) + int64(digit)
+		digits++
+	}
+	if strict && (digits == 0 || i < len(s)) {
+		return 0, false
+	}
+	if negative {
+		value = -value
+	}
+	return value, true
+}
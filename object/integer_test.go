@@ -1,10 +1,55 @@
 package object
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
 
+func TestNewInteger(t *testing.T) {
+	tests := []struct {
+		value  int64
+		shared bool
+	}{
+		{0, true},
+		{minCachedInteger, true},
+		{maxCachedInteger, true},
+		{minCachedInteger - 1, false},
+		{maxCachedInteger + 1, false},
+	}
+
+	for _, tt := range tests {
+		integer := NewInteger(tt.value)
+
+		if integer.Value != tt.value {
+			t.Logf("Expected value to equal %d, got %d", tt.value, integer.Value)
+			t.Fail()
+		}
+		if shared := NewInteger(tt.value) == integer; shared != tt.shared {
+			t.Logf("Expected sharing of %d to be %t, got %t", tt.value, tt.shared, shared)
+			t.Fail()
+		}
+	}
+}
+
+// integerSink keeps the benchmarked Integers from being optimized away
+var integerSink *Integer
+
+func BenchmarkNewInteger(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			integerSink = NewInteger(int64(i % maxCachedInteger))
+		}
+	})
+	b.Run("allocated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			integerSink = NewInteger(int64(maxCachedInteger + 1 + i%maxCachedInteger))
+		}
+	})
+}
+
 func TestIntegerDiv(t *testing.T) {
 	tests := []struct {
 		arguments []RubyObject
@@ -17,7 +62,7 @@ func TestIntegerDiv(t *testing.T) {
 			nil,
 		},
 		{
-			[]RubyObject{&String{""}},
+			[]RubyObject{&String{Value: ""}},
 			nil,
 			NewCoercionTypeError(&String{}, &Integer{}),
 		},
@@ -51,7 +96,7 @@ func TestIntegerMul(t *testing.T) {
 			nil,
 		},
 		{
-			[]RubyObject{&String{""}},
+			[]RubyObject{&String{Value: ""}},
 			nil,
 			NewCoercionTypeError(&String{}, &Integer{}),
 		},
@@ -80,7 +125,7 @@ func TestIntegerAdd(t *testing.T) {
 			nil,
 		},
 		{
-			[]RubyObject{&String{""}},
+			[]RubyObject{&String{Value: ""}},
 			nil,
 			NewCoercionTypeError(&String{}, &Integer{}),
 		},
@@ -97,6 +142,169 @@ func TestIntegerAdd(t *testing.T) {
 	}
 }
 
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []struct {
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  int64
+		args     []RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{integerDiv, -7, []RubyObject{NewInteger(2)}, NewInteger(-4), nil},
+		{integerModulo, -7, []RubyObject{NewInteger(3)}, NewInteger(2), nil},
+		{integerModulo, 7, []RubyObject{NewInteger(-3)}, NewInteger(-2), nil},
+		{integerModulo, 7, []RubyObject{NewFloat(2.5)}, NewFloat(2), nil},
+		{integerModulo, 7, []RubyObject{NewInteger(0)}, nil, NewZeroDivisionError()},
+		{integerPow, 3, []RubyObject{NewInteger(4)}, NewInteger(81), nil},
+		{integerPow, 2, []RubyObject{NewInteger(-2)}, NewFloat(0.25), nil},
+		{integerPow, 3, []RubyObject{NewInteger(4), NewInteger(5)}, NewInteger(1), nil},
+		{integerPow, 3, []RubyObject{NewInteger(3), NewInteger(-5)}, NewInteger(-3), nil},
+		{integerPow, 3, []RubyObject{NewInteger(-1), NewInteger(5)}, nil, NewRangeError("Integer#pow() 1st argument cannot be negative when 2nd argument specified")},
+		{integerPow, 2, []RubyObject{NewInteger(62)}, NewInteger(1 << 62), nil},
+		{integerPow, -2, []RubyObject{NewInteger(63)}, NewInteger(math.MinInt64), nil},
+		{integerPow, 2, []RubyObject{NewInteger(64)}, nil, NewRangeError("2 ** 64 exceeds the range of Integer")},
+		{integerPow, 10, []RubyObject{NewInteger(20)}, nil, NewRangeError("10 ** 20 exceeds the range of Integer")},
+		{integerPow, -1, []RubyObject{NewInteger(math.MaxInt64)}, NewInteger(-1), nil},
+		{integerPow, 0, []RubyObject{NewInteger(-1)}, nil, NewZeroDivisionError()},
+		{integerDivmod, 7, []RubyObject{NewInteger(2)}, NewArray(NewInteger(3), NewInteger(1)), nil},
+		{integerDivmod, 7, []RubyObject{NewInteger(0)}, nil, NewZeroDivisionError()},
+		{integerAbs, -3, nil, NewInteger(3), nil},
+		{integerGcd, 12, []RubyObject{NewInteger(-18)}, NewInteger(6), nil},
+		{integerGcd, 12, []RubyObject{NewFloat(1)}, nil, NewTypeError("not an integer")},
+		{integerLcm, 4, []RubyObject{NewInteger(6)}, NewInteger(12), nil},
+		{integerLcm, 4, []RubyObject{NewInteger(0)}, NewInteger(0), nil},
+		{integerBitAnd, 6, []RubyObject{NewInteger(3)}, NewInteger(2), nil},
+		{integerBitOr, 6, []RubyObject{NewInteger(3)}, NewInteger(7), nil},
+		{integerBitXor, 6, []RubyObject{NewInteger(3)}, NewInteger(5), nil},
+		{integerBitNot, 0, nil, NewInteger(-1), nil},
+		{integerLeftShift, 1, []RubyObject{NewInteger(3)}, NewInteger(8), nil},
+		{integerLeftShift, 8, []RubyObject{NewInteger(-3)}, NewInteger(1), nil},
+		{integerRightShift, -1, []RubyObject{NewInteger(70)}, NewInteger(-1), nil},
+		{integerLeftShift, 1, []RubyObject{NewInteger(62)}, NewInteger(1 << 62), nil},
+		{integerLeftShift, 1, []RubyObject{NewInteger(64)}, nil, NewRangeError("1 << 64 exceeds the range of Integer")},
+		{integerLeftShift, 3, []RubyObject{NewInteger(62)}, nil, NewRangeError("3 << 62 exceeds the range of Integer")},
+		{integerRightShift, 1, []RubyObject{NewInteger(-64)}, nil, NewRangeError("1 >> -64 exceeds the range of Integer")},
+		{integerLeftShift, 0, []RubyObject{NewInteger(100)}, NewInteger(0), nil},
+		{integerEven, 4, nil, TRUE, nil},
+		{integerOdd, 4, nil, FALSE, nil},
+		{integerZero, 0, nil, TRUE, nil},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.method(NewInteger(tt.context), tt.args...)
+
+		checkError(t, err, tt.err)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestIntegerToS(t *testing.T) {
+	tests := []struct {
+		context  int64
+		args     []RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{255, nil, &String{Value: "255"}, nil},
+		{255, []RubyObject{NewInteger(16)}, &String{Value: "ff"}, nil},
+		{-35, []RubyObject{NewInteger(36)}, &String{Value: "-z"}, nil},
+		{1, []RubyObject{NewInteger(37)}, nil, NewArgumentError("invalid radix 37")},
+	}
+
+	for _, tt := range tests {
+		result, err := integerToS(NewInteger(tt.context), tt.args...)
+
+		checkError(t, err, tt.err)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestParseInteger(t *testing.T) {
+	tests := []struct {
+		input    string
+		base     int
+		strict   bool
+		expected int64
+		ok       bool
+	}{
+		{"42", 10, false, 42, true},
+		{"  -42abc", 10, false, -42, true},
+		{"1_000", 10, false, 1000, true},
+		{"1__000", 10, false, 1, true},
+		{"abc", 10, false, 0, true},
+		{"0xff", 16, false, 255, true},
+		{"0xff", 10, false, 0, true},
+		{"0b101", 0, false, 5, true},
+		{"0o17", 0, false, 15, true},
+		{"017", 0, false, 15, true},
+		{"z", 36, false, 35, true},
+		{" 42\n", 0, true, 42, true},
+		{"42abc", 0, true, 0, false},
+		{"1_", 0, true, 0, false},
+		{"", 0, true, 0, false},
+		{"08", 0, true, 0, false},
+	}
+
+	for _, tt := range tests {
+		value, ok := parseInteger(tt.input, tt.base, tt.strict)
+
+		if value != tt.expected || ok != tt.ok {
+			t.Logf("Expected parseInteger(%q, %d, %t) to return %d, %t, got %d, %t", tt.input, tt.base, tt.strict, tt.expected, tt.ok, value, ok)
+			t.Fail()
+		}
+	}
+}
+
+func TestIntegerIteration(t *testing.T) {
+	collect := func(values *[]RubyObject) *Proc {
+		return testBlock(func(args ...RubyObject) (RubyObject, error) {
+			*values = append(*values, args[0])
+			return NIL, nil
+		}, "x")
+	}
+	tests := []struct {
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  int64
+		args     []RubyObject
+		expected []RubyObject
+	}{
+		{integerTimes, 3, nil, []RubyObject{NewInteger(0), NewInteger(1), NewInteger(2)}},
+		{integerTimes, 0, nil, nil},
+		{integerUpto, 2, []RubyObject{NewInteger(4)}, []RubyObject{NewInteger(2), NewInteger(3), NewInteger(4)}},
+		{integerUpto, 2, []RubyObject{NewInteger(1)}, nil},
+		{integerDownto, 4, []RubyObject{NewInteger(3)}, []RubyObject{NewInteger(4), NewInteger(3)}},
+		{integerStep, 1, []RubyObject{NewInteger(7), NewInteger(3)}, []RubyObject{NewInteger(1), NewInteger(4), NewInteger(7)}},
+		{integerStep, 3, []RubyObject{NewInteger(1), NewInteger(-2)}, []RubyObject{NewInteger(3), NewInteger(1)}},
+		{integerStep, 1, []RubyObject{NewInteger(2), NewFloat(0.5)}, []RubyObject{NewFloat(1), NewFloat(1.5), NewFloat(2)}},
+	}
+
+	for _, tt := range tests {
+		var values []RubyObject
+		context := NewInteger(tt.context)
+
+		result, err := tt.method(context, append(tt.args, collect(&values))...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, context)
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Logf("Expected yielded values to equal %v, got %v", tt.expected, values)
+			t.Fail()
+		}
+	}
+
+	t.Run("without block", func(t *testing.T) {
+		result, err := integerUpto(NewInteger(1), NewInteger(3))
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewEnumerator(NewInteger(1), "upto", NewInteger(3)))
+	})
+	t.Run("zero step", func(t *testing.T) {
+		_, err := integerStep(NewInteger(1), NewInteger(3), NewInteger(0))
+
+		checkError(t, err, NewArgumentError("step can't be 0"))
+	})
+}
+
 func checkError(t *testing.T, actual, expected error) {
 	if !reflect.DeepEqual(expected, actual) {
 		t.Logf("Expected error to equal %T:%v, got %T:%v\n", expected, expected, actual, actual)
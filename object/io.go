@@ -0,0 +1,428 @@
+package object
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+var ioClass RubyClassObject = newClass("IO", objectClass, ioMethods, nil)
+
+func init() {
+	classes.Set("IO", ioClass)
+}
+
+// NewIO returns a new IO reading from reader. If reader implements
+// io.Seeker the IO can be rewound.
+func NewIO(reader io.Reader) *IO {
+	return &IO{source: reader, reader: bufio.NewReader(reader)}
+}
+
+// newWriterIO returns a new IO writing to writer, which cannot be read
+func newWriterIO(writer io.Writer) *IO {
+	return &IO{writer: writer}
+}
+
+// IO represents an IO stream in Ruby, which is readable, writable or both
+type IO struct {
+	source io.Reader
+	reader *bufio.Reader
+	writer io.Writer
+	lineno int64
+	closed bool
+	// reading is closed once the read running in the background finished.
+	// It stores what it read in unread and readErr, where a read which has
+	// been interrupted leaves it for the next one.
+	reading chan struct{}
+	unread  string
+	readErr error
+}
+
+// Type returns IO_OBJ
+func (i *IO) Type() Type { return IO_OBJ }
+
+// Inspect returns the class name of the IO
+func (i *IO) Inspect() string { return "#<IO>" }
+
+// Class returns ioClass
+func (i *IO) Class() RubyClass { return ioClass }
+
+// readable returns an IOError if the IO cannot be read
+func (i *IO) readable() error {
+	if i.closed {
+		return NewIOError("closed stream")
+	}
+	if i.reader == nil {
+		return NewIOError("not opened for reading")
+	}
+	return nil
+}
+
+// writable returns an IOError if the IO cannot be written
+func (i *IO) writable() error {
+	if i.closed {
+		return NewIOError("closed stream")
+	}
+	if i.writer == nil {
+		return NewIOError("not opened for writing")
+	}
+	return nil
+}
+
+// Write writes s to the IO and returns the number of bytes written
+func (i *IO) Write(s string) (int, error) {
+	if err := i.writable(); err != nil {
+		return 0, err
+	}
+	return io.WriteString(i.writer, s)
+}
+
+// closeWrite closes the writing side of the IO, like the stdin of a child
+// process to signal that there is no more input
+func (i *IO) closeWrite() error {
+	if i.writer == nil {
+		return nil
+	}
+	closer, ok := i.writer.(io.Closer)
+	i.writer = nil
+	if ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Close closes the IO and the underlying streams which can be closed
+func (i *IO) Close() error {
+	if i.closed {
+		return nil
+	}
+	err := i.closeWrite()
+	if closer, ok := i.source.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	i.closed = true
+	return err
+}
+
+// Gets reads the next line including its line terminator. It returns
+// io.EOF if there is nothing left to read. Other Threads of r run while it
+// waits for input.
+func (i *IO) Gets(r *Runtime) (string, error) {
+	if err := i.readable(); err != nil {
+		return "", err
+	}
+	if err := i.awaitRead(r); err != nil {
+		return "", err
+	}
+	for !strings.Contains(i.unread, "\n") && i.readErr == nil {
+		err := i.startRead(r, func() (string, error) { return i.reader.ReadString('\n') })
+		if err != nil {
+			return "", err
+		}
+	}
+	line := i.unread
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end+1]
+	}
+	i.unread = i.unread[len(line):]
+	if line == "" {
+		err := i.readErr
+		i.readErr = nil
+		return "", err
+	}
+	i.lineno++
+	return line, nil
+}
+
+// Read reads everything up to the end of the stream, letting the other
+// Threads of r run meanwhile
+func (i *IO) Read(r *Runtime) (string, error) {
+	if err := i.readable(); err != nil {
+		return "", err
+	}
+	if err := i.awaitRead(r); err != nil {
+		return "", err
+	}
+	for i.readErr == nil {
+		err := i.startRead(r, func() (string, error) {
+			content, err := ioutil.ReadAll(i.reader)
+			if err == nil {
+				err = io.EOF
+			}
+			return string(content), err
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	content, err := i.unread, i.readErr
+	i.unread, i.readErr = "", nil
+	if err == io.EOF {
+		err = nil
+	}
+	return content, err
+}
+
+// startRead runs read in the background, so that the other Threads of r
+// run meanwhile, and waits for it to finish like awaitRead
+func (i *IO) startRead(r *Runtime, read func() (string, error)) error {
+	reading := make(chan struct{})
+	i.reading = reading
+	go func() {
+		data, err := read()
+		i.unread += data
+		i.readErr = err
+		close(reading)
+	}()
+	return i.awaitRead(r)
+}
+
+// awaitRead waits within r for the read running in the background, if any.
+// If the program is interrupted meanwhile, or the context it runs with is
+// done, it returns the interruption and the read keeps running.
+func (i *IO) awaitRead(r *Runtime) error {
+	if i.reading == nil {
+		return nil
+	}
+	if _, err := r.wait(i.reading, -1); err != nil {
+		return err
+	}
+	i.reading = nil
+	return nil
+}
+
+// Rewind positions the IO at the start of the stream, once the read
+// running in the background within r finished. It returns an error if the
+// underlying reader does not support seeking.
+func (i *IO) Rewind(r *Runtime) error {
+	if err := i.readable(); err != nil {
+		return err
+	}
+	if err := i.awaitRead(r); err != nil {
+		return err
+	}
+	seeker, ok := i.source.(io.Seeker)
+	if !ok {
+		return NewNotImplementedError("rewind() function is unimplemented for this IO")
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	i.reader.Reset(i.source)
+	i.unread, i.readErr = "", nil
+	i.lineno = 0
+	return nil
+}
+
+// EOF reports whether the stream has no more data
+func (i *IO) EOF() bool {
+	if i.reading != nil || i.unread != "" {
+		return false
+	}
+	_, err := i.reader.Peek(1)
+	return err != nil
+}
+
+var ioMethods = map[string]RubyMethod{
+	"gets":        withArityRange(0, 1, publicRuntimeMethod(ioGets)),
+	"read":        withArity(0, publicRuntimeMethod(ioRead)),
+	"readlines":   withArity(0, publicRuntimeMethod(ioReadlines)),
+	"rewind":      withArity(0, publicRuntimeMethod(ioRewind)),
+	"eof?":        withArity(0, publicMethod(ioEOF)),
+	"lineno":      withArity(0, publicMethod(ioLineno)),
+	"write":       publicMethod(ioWrite),
+	"print":       publicMethod(ioPrint),
+	"puts":        publicMethod(ioPuts),
+	"<<":          withArity(1, publicMethod(ioAppend)),
+	"close":       withArity(0, publicMethod(ioClose)),
+	"close_write": withArity(0, publicMethod(ioCloseWrite)),
+	"closed?":     withArity(0, publicMethod(ioIsClosed)),
+}
+
+// chompOption returns the value of the chomp option within the options
+// Hash passed as first argument, if any
+func chompOption(args []RubyObject) (bool, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 {
+		return false, nil
+	}
+	options, ok := args[0].(*Hash)
+	if !ok {
+		return false, NewImplicitConversionTypeError(&Hash{}, args[0])
+	}
+	chomp, ok := options.Get(NewSymbol("chomp"))
+	return ok && isTruthy(chomp), nil
+}
+
+func ioGets(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	chomp, err := chompOption(args)
+	if err != nil {
+		return nil, err
+	}
+	line, err := ioObj.Gets(r)
+	if err == io.EOF {
+		r.SetLastLine(NIL)
+		return NIL, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chomp {
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	}
+	result := &String{Value: line}
+	r.SetLastLine(result)
+	return result, nil
+}
+
+// LastLine returns the line read last by gets within the method the
+// current Thread of r runs, which `$_` refers to, or nil
+func (r *Runtime) LastLine() RubyObject {
+	if line := r.threadState().currentThread().lastLine; line != nil {
+		return line
+	}
+	return NIL
+}
+
+// SetLastLine sets the line read last by the current Thread of r and
+// returns the previous one, which is nil if there is none. Like for
+// SetLastMatch, methods defined in Ruby use it to have their own `$_`.
+func (r *Runtime) SetLastLine(line RubyObject) RubyObject {
+	thread := r.threadState().currentThread()
+	previous := thread.lastLine
+	thread.lastLine = line
+	return previous
+}
+
+func ioRead(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	content, err := ioObj.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: content}, nil
+}
+
+func ioReadlines(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	lines := NewArray()
+	for {
+		line, err := ioObj.Gets(r)
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines.Elements = append(lines.Elements, &String{Value: line})
+	}
+}
+
+func ioRewind(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	if err := ioObj.Rewind(r); err != nil {
+		return nil, err
+	}
+	return NewInteger(0), nil
+}
+
+func ioEOF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	if err := ioObj.readable(); err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(ioObj.EOF()), nil
+}
+
+func ioLineno(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	return NewInteger(ioObj.lineno), nil
+}
+
+// ioWrite writes the arguments converted by to_s and returns the number of
+// bytes written
+func ioWrite(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	var out strings.Builder
+	for _, arg := range args {
+		out.WriteString(toS(arg))
+	}
+	n, err := context.(*IO).Write(out.String())
+	if err != nil {
+		return nil, err
+	}
+	return NewInteger(int64(n)), nil
+}
+
+func ioPrint(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := ioWrite(context, args...); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+func ioPuts(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if _, err := context.(*IO).Write(putsLines(args)); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+func ioAppend(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := ioWrite(context, args...); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+func ioClose(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if err := context.(*IO).Close(); err != nil {
+		return nil, NewIOError("%s", err)
+	}
+	return NIL, nil
+}
+
+func ioCloseWrite(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ioObj := context.(*IO)
+	if err := ioObj.writable(); err != nil {
+		return nil, err
+	}
+	if err := ioObj.closeWrite(); err != nil {
+		return nil, NewIOError("%s", err)
+	}
+	return NIL, nil
+}
+
+func ioIsClosed(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*IO).closed), nil
+}
+
+// putsLines returns the arguments converted by to_s, and the elements of
+// Arrays, each followed by a newline unless it ends with one
+func putsLines(args []RubyObject) string {
+	var out strings.Builder
+	if len(args) == 0 {
+		out.WriteString("\n")
+	}
+	var writeLines func(args []RubyObject)
+	writeLines = func(args []RubyObject) {
+		for _, arg := range args {
+			if array, ok := arg.(*Array); ok {
+				writeLines(array.Elements)
+				continue
+			}
+			line := toS(arg)
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n")
+			}
+		}
+	}
+	writeLines(args)
+	return out.String()
+}
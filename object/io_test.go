@@ -0,0 +1,87 @@
+package object
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIOGets(t *testing.T) {
+	stream := NewIO(strings.NewReader("foo\nbar"))
+
+	var results []RubyObject
+	for i := 0; i < 3; i++ {
+		result, err := ioGets(nil, stream)
+		checkError(t, err, nil)
+		results = append(results, result)
+	}
+
+	expected := []RubyObject{&String{Value: "foo\n"}, &String{Value: "bar"}, NIL}
+
+	if !reflect.DeepEqual(expected, results) {
+		t.Logf("Expected results to equal %v, got %v", expected, results)
+		t.Fail()
+	}
+
+	lineno, err := ioLineno(stream)
+	checkError(t, err, nil)
+	checkResult(t, lineno, NewInteger(2))
+}
+
+func TestIOGetsChomp(t *testing.T) {
+	chomp := &Hash{}
+	chomp.Set(&Symbol{"chomp"}, TRUE)
+	stream := NewIO(strings.NewReader("foo\r\nbar\n"))
+
+	result, err := ioGets(nil, stream, chomp)
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "foo"})
+
+	result, err = ioGets(nil, stream, &Hash{})
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "bar\n"})
+
+	_, err = ioGets(nil, stream, NewInteger(1))
+	checkError(t, err, NewImplicitConversionTypeError(&Hash{}, NewInteger(1)))
+}
+
+func TestIOReadlines(t *testing.T) {
+	stream := NewIO(strings.NewReader("foo\nbar\n"))
+
+	result, err := ioReadlines(nil, stream)
+
+	checkError(t, err, nil)
+
+	expected := NewArray(&String{Value: "foo\n"}, &String{Value: "bar\n"})
+
+	if !reflect.DeepEqual(expected, result) {
+		t.Logf("Expected lines to equal %s, got %s", toString(expected), toString(result))
+		t.Fail()
+	}
+
+	eof, err := ioEOF(stream)
+	checkError(t, err, nil)
+	checkResult(t, eof, TRUE)
+}
+
+func TestIORewind(t *testing.T) {
+	t.Run("seekable source", func(t *testing.T) {
+		stream := NewIO(strings.NewReader("foo\nbar\n"))
+		_, err := ioRead(nil, stream)
+		checkError(t, err, nil)
+
+		_, err = ioRewind(nil, stream)
+		checkError(t, err, nil)
+
+		result, err := ioGets(nil, stream)
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: "foo\n"})
+	})
+	t.Run("unseekable source", func(t *testing.T) {
+		stream := NewIO(io.MultiReader(strings.NewReader("foo")))
+
+		_, err := ioRewind(nil, stream)
+		checkError(t, err, NewNotImplementedError("rewind() function is unimplemented for this IO"))
+	})
+}
@@ -0,0 +1,379 @@
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+)
+
+var jsonModule = newModule("JSON", jsonMethods)
+
+func init() {
+	classes.Set("JSON", jsonModule)
+	setConstant(jsonModule, "JSONError", jsonErrorClass)
+	setConstant(jsonModule, "ParserError", jsonParserErrorClass)
+	setConstant(jsonModule, "GeneratorError", jsonGeneratorErrorClass)
+	// registered here as generating objects refers to it
+	jsonToJSON = withArityRange(0, 1, publicMethod(kernelToJSON))
+	kernelMethodSet["to_json"] = jsonToJSON
+}
+
+var jsonMethods = map[string]RubyMethod{
+	"parse":           withArityRange(1, 2, publicMethod(jsonParse)),
+	"generate":        withArityRange(1, 2, publicMethod(jsonGenerate)),
+	"pretty_generate": withArityRange(1, 2, publicMethod(jsonPrettyGenerate)),
+}
+
+// jsonToJSON is the builtin Object#to_json. Objects redefining it are
+// generated by calling their own to_json.
+var jsonToJSON RubyMethod
+
+// jsonMaxNesting is the depth of nested Arrays and Hashes at which parsing
+// and generating fails, like for the default max_nesting of Ruby
+const jsonMaxNesting = 100
+
+// jsonOptions returns the options Hash passed as argument index, or an
+// empty Hash if there is none
+func jsonOptions(args []RubyObject, index int) (*Hash, error) {
+	args, _ = extractBlock(args)
+	if len(args) <= index || args[index] == NIL {
+		return NewHash(nil), nil
+	}
+	options, ok := args[index].(*Hash)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&Hash{}, args[index])
+	}
+	return options, nil
+}
+
+// jsonParse parses the JSON document passed into the corresponding Ruby
+// objects. Object keys become Strings, or Symbols with the option
+// symbolize_names. Numbers without fraction or exponent become Integers.
+func jsonParse(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	source, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	options, err := jsonOptions(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	symbolizeNames, ok := options.Get(NewSymbol("symbolize_names"))
+	decoder := json.NewDecoder(strings.NewReader(source.Value))
+	decoder.UseNumber()
+	parser := &jsonParser{decoder: decoder, symbolizeNames: ok && isTruthy(symbolizeNames)}
+	value, err := parser.parse(0)
+	if err != nil {
+		return nil, jsonParserError(err, source.Value)
+	}
+	offset := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		rest := strings.TrimLeft(source.Value[offset:], " \t\r\n")
+		return nil, NewJSONParserError("unexpected token at '%s'", rest)
+	}
+	return value, nil
+}
+
+// jsonParserError converts an error of encoding/json into a ParserError
+func jsonParserError(err error, source string) error {
+	switch err := err.(type) {
+	case *JSONParserError:
+		return err
+	case *json.SyntaxError:
+		if err.Offset < int64(len(source)) {
+			return NewJSONParserError("%s at offset %d", err, err.Offset)
+		}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF || strings.HasPrefix(err.Error(), "unexpected end") {
+		return NewJSONParserError("unexpected end of input")
+	}
+	return NewJSONParserError("%s", err)
+}
+
+// jsonParser builds Ruby objects from the tokens of decoder, keeping the
+// order of object keys
+type jsonParser struct {
+	decoder        *json.Decoder
+	symbolizeNames bool
+}
+
+func (p *jsonParser) parse(depth int) (RubyObject, error) {
+	token, err := p.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if depth == jsonMaxNesting {
+			return nil, NewJSONParserError("nesting of %d is too deep", depth+1)
+		}
+		if token == '[' {
+			return p.parseArray(depth + 1)
+		}
+		return p.parseObject(depth + 1)
+	case string:
+		return &String{Value: token}, nil
+	case json.Number:
+		return jsonNumber(token), nil
+	case bool:
+		return nativeBoolToBoolean(token), nil
+	default:
+		return NIL, nil
+	}
+}
+
+func (p *jsonParser) parseArray(depth int) (RubyObject, error) {
+	var elements []RubyObject
+	for p.decoder.More() {
+		element, err := p.parse(depth)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	if _, err := p.decoder.Token(); err != nil {
+		return nil, err
+	}
+	return NewArray(elements...), nil
+}
+
+func (p *jsonParser) parseObject(depth int) (RubyObject, error) {
+	hash := NewHash(nil)
+	for p.decoder.More() {
+		token, err := p.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var key RubyObject = &String{Value: token.(string)}
+		if p.symbolizeNames {
+			key = NewSymbol(token.(string))
+		}
+		value, err := p.parse(depth)
+		if err != nil {
+			return nil, err
+		}
+		hash.Set(key, value)
+	}
+	if _, err := p.decoder.Token(); err != nil {
+		return nil, err
+	}
+	return hash, nil
+}
+
+// jsonNumber returns number as Integer if it has neither fraction nor
+// exponent and fits into one, and as Float otherwise
+func jsonNumber(number json.Number) RubyObject {
+	if !strings.ContainsAny(number.String(), ".eE") {
+		if i, err := number.Int64(); err == nil {
+			return NewInteger(i)
+		}
+	}
+	f, _ := number.Float64()
+	return NewFloat(f)
+}
+
+// jsonGenerate returns the JSON document representing the object passed.
+// The options indent, space, object_nl and array_nl are inserted to format
+// it, like pretty_generate does.
+func jsonGenerate(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	options, err := jsonOptions(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	generator := &jsonGenerator{}
+	if err := generator.configure(options); err != nil {
+		return nil, err
+	}
+	return generator.generateString(args[0])
+}
+
+// jsonPrettyGenerate works like jsonGenerate, but indents nested values by
+// two spaces on separate lines by default
+func jsonPrettyGenerate(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	options, err := jsonOptions(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	generator := &jsonGenerator{indent: "  ", space: " ", objectNL: "\n", arrayNL: "\n"}
+	if err := generator.configure(options); err != nil {
+		return nil, err
+	}
+	return generator.generateString(args[0])
+}
+
+// kernelToJSON returns the JSON document representing self, like
+// JSON.generate
+func kernelToJSON(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	options, err := jsonOptions(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	generator := &jsonGenerator{}
+	if err := generator.configure(options); err != nil {
+		return nil, err
+	}
+	return generator.generateString(context)
+}
+
+// jsonGenerator writes the JSON representation of Ruby objects to out
+type jsonGenerator struct {
+	indent   string
+	space    string
+	objectNL string
+	arrayNL  string
+	out      strings.Builder
+}
+
+// configure sets the formatting given by options
+func (g *jsonGenerator) configure(options *Hash) error {
+	for name, target := range map[string]*string{
+		"indent": &g.indent, "space": &g.space, "object_nl": &g.objectNL, "array_nl": &g.arrayNL,
+	} {
+		value, ok := options.Get(NewSymbol(name))
+		if !ok {
+			continue
+		}
+		str, ok := value.(*String)
+		if !ok {
+			return NewImplicitConversionTypeError(&String{}, value)
+		}
+		*target = str.Value
+	}
+	return nil
+}
+
+func (g *jsonGenerator) generateString(obj RubyObject) (RubyObject, error) {
+	if err := g.generate(obj, 0); err != nil {
+		return nil, err
+	}
+	return &String{Value: g.out.String()}, nil
+}
+
+func (g *jsonGenerator) generate(obj RubyObject, depth int) error {
+	switch obj := obj.(type) {
+	case *nilObject:
+		g.out.WriteString("null")
+	case *Boolean:
+		g.out.WriteString(obj.Inspect())
+	case *Integer:
+		g.out.WriteString(obj.Inspect())
+	case *Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return NewJSONGeneratorError("%s not allowed in JSON", obj.Inspect())
+		}
+		g.out.WriteString(obj.Inspect())
+	case *String:
+		g.writeString(obj.Value)
+	case *Symbol:
+		g.writeString(obj.Value)
+	case *Array:
+		return g.generateArray(obj, depth+1)
+	case *Hash:
+		return g.generateHash(obj, depth+1)
+	default:
+		return g.generateObject(obj)
+	}
+	return nil
+}
+
+func (g *jsonGenerator) generateArray(array *Array, depth int) error {
+	if depth > jsonMaxNesting {
+		return NewJSONGeneratorError("nesting of %d is too deep", depth)
+	}
+	if len(array.Elements) == 0 {
+		g.out.WriteString("[]")
+		return nil
+	}
+	g.out.WriteString("[" + g.arrayNL)
+	for i, element := range array.Elements {
+		if i > 0 {
+			g.out.WriteString("," + g.arrayNL)
+		}
+		g.out.WriteString(strings.Repeat(g.indent, depth))
+		if err := g.generate(element, depth); err != nil {
+			return err
+		}
+	}
+	g.out.WriteString(g.arrayNL + strings.Repeat(g.indent, depth-1) + "]")
+	return nil
+}
+
+func (g *jsonGenerator) generateHash(hash *Hash, depth int) error {
+	if depth > jsonMaxNesting {
+		return NewJSONGeneratorError("nesting of %d is too deep", depth)
+	}
+	if hash.Len() == 0 {
+		g.out.WriteString("{}")
+		return nil
+	}
+	g.out.WriteString("{" + g.objectNL)
+	keys, values := hash.Keys(), hash.Values()
+	for i, key := range keys {
+		if i > 0 {
+			g.out.WriteString("," + g.objectNL)
+		}
+		g.out.WriteString(strings.Repeat(g.indent, depth))
+		name, err := jsonObjectString(key)
+		if err != nil {
+			return err
+		}
+		g.writeString(name)
+		g.out.WriteString(":" + g.space)
+		if err := g.generate(values[i], depth); err != nil {
+			return err
+		}
+	}
+	g.out.WriteString(g.objectNL + strings.Repeat(g.indent, depth-1) + "}")
+	return nil
+}
+
+// generateObject writes the result of the to_json method of obj if it
+// redefines it, and its to_s as JSON string otherwise
+func (g *jsonGenerator) generateObject(obj RubyObject) error {
+	if method, ok := findMethod(obj, "to_json"); ok && method != jsonToJSON {
+		json, err := Send(obj, "to_json")
+		if err != nil {
+			return err
+		}
+		str, ok := json.(*String)
+		if !ok {
+			return NewTypeError("can't convert %s to String (%s#to_json gives %s)", className(obj), className(obj), className(json))
+		}
+		g.out.WriteString(str.Value)
+		return nil
+	}
+	name, err := jsonObjectString(obj)
+	if err != nil {
+		return err
+	}
+	g.writeString(name)
+	return nil
+}
+
+// jsonObjectString returns the name of obj if it is a String or Symbol and
+// the result of its to_s otherwise
+func jsonObjectString(obj RubyObject) (string, error) {
+	switch obj := obj.(type) {
+	case *String:
+		return obj.Value, nil
+	case *Symbol:
+		return obj.Value, nil
+	}
+	str, err := Send(obj, "to_s")
+	if err != nil {
+		return "", err
+	}
+	return toS(str), nil
+}
+
+// writeString writes s as JSON string. Unlike encoding/json does by
+// default, it leaves HTML characters like `<` unescaped.
+func (g *jsonGenerator) writeString(s string) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	g.out.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
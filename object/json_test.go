@@ -0,0 +1,80 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJSONParse(t *testing.T) {
+	symbolizeNames := NewHash(nil)
+	symbolizeNames.Set(NewSymbol("symbolize_names"), TRUE)
+
+	tests := []struct {
+		source   string
+		options  []RubyObject
+		expected string
+	}{
+		{`{"b": 1, "a": [2.5, true, null, "x"]}`, nil, `{b=>1, a=>[2.5, true, nil, x]}`},
+		{`{"a": {"b": -3e2}}`, []RubyObject{symbolizeNames}, `{:a=>{:b=>-300.0}}`},
+		{`"é"`, nil, `é`},
+		{` [] `, nil, `[]`},
+	}
+
+	for _, tt := range tests {
+		args := append([]RubyObject{&String{Value: tt.source}}, tt.options...)
+		result, err := jsonParse(jsonModule, args...)
+
+		checkError(t, err, nil)
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected %s to parse to %s, got %s", tt.source, tt.expected, result.Inspect())
+			t.Fail()
+		}
+	}
+
+	invalid := []struct {
+		source string
+		err    error
+	}{
+		{`[1] 2`, NewJSONParserError("unexpected token at '2'")},
+		{``, NewJSONParserError("unexpected end of input")},
+		{`{"a": 1`, NewJSONParserError("unexpected end of input")},
+	}
+
+	for _, tt := range invalid {
+		_, err := jsonParse(jsonModule, &String{Value: tt.source})
+
+		checkError(t, err, tt.err)
+	}
+}
+
+func TestJSONGenerate(t *testing.T) {
+	nested := NewHash(nil)
+	nested.Set(&String{Value: "a"}, NewArray(NewInteger(1), NewFloat(2.5), NIL, TRUE))
+	nested.Set(NewSymbol("b"), &String{Value: `<"x">`})
+	nested.Set(NewInteger(3), NewHash(nil))
+
+	t.Run("generate", func(t *testing.T) {
+		result, err := jsonGenerate(jsonModule, nested)
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: `{"a":[1,2.5,null,true],"b":"<\"x\">","3":{}}`})
+	})
+	t.Run("pretty_generate", func(t *testing.T) {
+		result, err := jsonPrettyGenerate(jsonModule, nested)
+
+		expected := "{\n  \"a\": [\n    1,\n    2.5,\n    null,\n    true\n  ],\n  \"b\": \"<\\\"x\\\">\",\n  \"3\": {}\n}"
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: expected})
+	})
+	t.Run("to_json", func(t *testing.T) {
+		result, err := Send(NewArray(NewSymbol("a"), NewInteger(1)), "to_json")
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: `["a",1]`})
+	})
+	t.Run("NaN", func(t *testing.T) {
+		_, err := jsonGenerate(jsonModule, NewFloat(math.NaN()))
+
+		checkError(t, err, NewJSONGeneratorError("NaN not allowed in JSON"))
+	})
+}
@@ -1,23 +1,174 @@
 package object
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
 
 var kernelModule = newModule("Kernel", kernelMethodSet)
-var kernelFunctions = NewEnclosedEnvironment(classes)
+var kernelFunctions = newSharedEnvironment(classes)
+
+// Stdout is the writer all Kernel output functions like puts or print write
+// to
+var Stdout io.Writer = os.Stdout
+
+// Stderr is the writer warnings are written to
+var Stderr io.Writer = os.Stderr
+
+// Stdin is the stream Kernel#gets reads from
+var Stdin = NewIO(os.Stdin)
+
+// interruptState holds the interruption of the program run within a
+// Runtime. interrupted is closed, and replaced by a new channel, when the
+// program is interrupted or one of the stop conditions is done. pending is set until
+// the interruption has been raised.
+type interruptState struct {
+	sync.Mutex
+	interrupted chan struct{}
+	pending     bool
+	stops       []*stopCondition
+}
+
+// A stopCondition ends the program once done is closed, with the error
+// returned by reason
+type stopCondition struct {
+	done   <-chan struct{}
+	reason func() error
+}
+
+func newInterruptState() interruptState {
+	return interruptState{interrupted: make(chan struct{})}
+}
+
+// sharedInterrupts holds the interruption of the programs run outside of
+// any Runtime
+var sharedInterrupts = newInterruptState()
+
+func (r *Runtime) interruptState() *interruptState {
+	if r == nil {
+		return &sharedInterrupts
+	}
+	return &r.interrupts
+}
+
+// RaiseInterrupt interrupts the program run within r. Builtin methods it
+// is blocked in, like sleep, return an Interrupt exception. If there is
+// none the next call of CheckInterrupt returns it.
+func (r *Runtime) RaiseInterrupt() {
+	state := r.interruptState()
+	state.Lock()
+	defer state.Unlock()
+	state.wake()
+	state.pending = true
+}
+
+// wake wakes up the builtin methods blocking within the program. The
+// caller must hold the lock of the state.
+func (s *interruptState) wake() {
+	close(s.interrupted)
+	s.interrupted = make(chan struct{})
+}
+
+// StopWhen makes the program run within r stop once done is closed:
+// builtin methods it is blocked in meanwhile, like sleep, return the error
+// returned by reason, and so does CheckInterrupt afterwards. It returns a
+// function undoing it.
+func (r *Runtime) StopWhen(done <-chan struct{}, reason func() error) (restore func()) {
+	state := r.interruptState()
+	current := &stopCondition{done: done, reason: reason}
+	state.Lock()
+	state.stops = append(state.stops, current)
+	state.Unlock()
+	restored := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			state.Lock()
+			state.wake()
+			state.Unlock()
+		case <-restored:
+		}
+	}()
+	return func() {
+		close(restored)
+		state.Lock()
+		defer state.Unlock()
+		for i, condition := range state.stops {
+			if condition == current {
+				state.stops = append(state.stops[:i:i], state.stops[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// stopped returns the reason of the first stop condition which is done, if
+// any
+func (s *interruptState) stopped() error {
+	s.Lock()
+	defer s.Unlock()
+	for _, condition := range s.stops {
+		select {
+		case <-condition.done:
+			return condition.reason()
+		default:
+		}
+	}
+	return nil
+}
+
+// CheckInterrupt runs the handlers of the signals trapped by the program
+// which have been received meanwhile. It returns the error raised by a
+// handler, the reason of a stop condition set by StopWhen which is done,
+// or an Interrupt exception if the program run within r has been
+// interrupted since the interruption has been raised last, and nil
+// otherwise. Like in Ruby only the main Thread is interrupted, while stop
+// conditions end all of them.
+func (r *Runtime) CheckInterrupt() error {
+	if err := runTraps(); err != nil {
+		return err
+	}
+	if err := r.interruptState().stopped(); err != nil {
+		return err
+	}
+	if threads := r.threadState(); threads.currentThread() != threads.main {
+		return nil
+	}
+	state := r.interruptState()
+	state.Lock()
+	defer state.Unlock()
+	if !state.pending {
+		return nil
+	}
+	state.pending = false
+	return NewInterrupt()
+}
+
+// CheckInterrupt is like Runtime.CheckInterrupt for the programs run
+// outside of any Runtime
+func CheckInterrupt() error {
+	return (*Runtime)(nil).CheckInterrupt()
+}
+
+// interrupted returns a channel which is closed on the next call of
+// RaiseInterrupt on r
+func (r *Runtime) interrupted() <-chan struct{} {
+	state := r.interruptState()
+	state.Lock()
+	defer state.Unlock()
+	return state.interrupted
+}
 
 func init() {
 	classes.Set("Kernel", kernelModule)
-	kernelFunctions.Set("puts", &Builtin{
-		Fn: func(args ...RubyObject) RubyObject {
-			out := ""
-			for _, arg := range args {
-				out += arg.Inspect()
-			}
-			fmt.Println(out)
-			return NIL
-		},
-	},
-	)
+	// registered here as it refers to the exception classes, which depend
+	// on kernelModule
+	kernelMethodSet["loop"] = withArity(0, privateRuntimeMethod(kernelLoop))
+	kernelMethodSet["private_methods"] = withArity(0, publicMethod(kernelPrivateMethods))
 }
 
 var kernelMethodSet = map[string]RubyMethod{
@@ -25,31 +176,223 @@ var kernelMethodSet = map[string]RubyMethod{
 	"methods": withArity(0, publicMethod(kernelMethods)),
 	"class":   withArity(0, publicMethod(kernelClass)),
 	"puts":    privateMethod(kernelPuts),
+	"print":   privateMethod(kernelPrint),
+	"p":       privateMethod(kernelP),
+	"pp":      privateMethod(kernelPP),
+	"gets":    withArityRange(0, 1, privateRuntimeMethod(kernelGets)),
+	"<=>":     withArity(1, publicMethod(kernelSpaceship)),
+	"==":      withArity(1, publicMethod(kernelEqual)),
+	"===":     withArity(1, publicMethod(kernelCaseEqual)),
+	"eql?":    withArity(1, publicMethod(kernelEqual)),
+	"hash":    kernelHashMethod,
+	"freeze":  withArity(0, publicMethod(kernelFreeze)),
+	"frozen?": withArity(0, publicMethod(kernelIsFrozen)),
+	"sprintf": withArityRange(1, -1, privateMethod(kernelSprintf)),
+	"format":  withArityRange(1, -1, privateMethod(kernelSprintf)),
+	"printf":  privateMethod(kernelPrintf),
+	"warn":    privateRuntimeMethod(kernelWarn),
+
+	"Integer": withArityRange(1, 3, privateMethod(kernelInteger)),
+	"Float":   withArityRange(1, 2, privateMethod(kernelFloat)),
+	"String":  withArity(1, privateMethod(kernelString)),
+	"Array":   withArity(1, privateMethod(kernelArray)),
+	"Hash":    withArity(1, privateMethod(kernelHashConversion)),
+
+	"raise": withArityRange(0, 2, privateMethod(kernelRaise)),
+	"fail":  withArityRange(0, 2, privateMethod(kernelRaise)),
+
+	"autoload":  withArity(2, privateRuntimeMethod(kernelAutoload)),
+	"autoload?": withArity(1, privateRuntimeMethod(kernelIsAutoload)),
+	"sleep":     withArityRange(0, 1, privateRuntimeMethod(kernelSleep)),
+	"exit":      withArityRange(0, 1, privateMethod(kernelExit)),
+
+	"send":        withArityRange(1, -1, publicRuntimeMethod(kernelSend)),
+	"public_send": withArityRange(1, -1, publicRuntimeMethod(kernelPublicSend)),
+
+	"respond_to?":         withArityRange(1, 2, publicRuntimeMethod(kernelRespondTo)),
+	"respond_to_missing?": withArity(2, privateMethod(kernelRespondToMissing)),
+	"method":              withArity(1, publicRuntimeMethod(kernelMethod)),
+
+	"instance_variable_get":      withArity(1, publicMethod(kernelInstanceVariableGet)),
+	"instance_variable_set":      withArity(2, publicMethod(kernelInstanceVariableSet)),
+	"instance_variable_defined?": withArity(1, publicMethod(kernelInstanceVariableDefined)),
+	"instance_variables":         withArity(0, publicMethod(kernelInstanceVariables)),
+
+	"dup":             withArity(0, publicMethod(kernelDup)),
+	"clone":           withArityRange(0, 1, publicMethod(kernelClone)),
+	"initialize_copy": withArity(1, privateMethod(kernelInitializeCopy)),
+
+	"singleton_class": withArity(0, publicMethod(kernelSingletonClass)),
+
+	"object_id": withArity(0, publicMethod(kernelObjectID)),
+	"itself":    withArity(0, publicMethod(kernelItself)),
+
+	"is_a?":        withArity(1, publicMethod(kernelIsA)),
+	"kind_of?":     withArity(1, publicMethod(kernelIsA)),
+	"instance_of?": withArity(1, publicMethod(kernelIsInstanceOf)),
+
+	"tap":        withArity(0, publicMethod(kernelTap)),
+	"then":       withArity(0, publicMethod(kernelThen)),
+	"yield_self": withArity(0, publicMethod(kernelThen)),
+
+	"inspect": withArity(0, publicMethod(kernelInspect)),
+	"to_s":    withArity(0, publicMethod(kernelToS)),
+
+	"proc":   withArity(0, privateMethod(kernelProc)),
+	"lambda": withArity(0, privateMethod(kernelLambda)),
+
+	"instance_eval": withArity(0, publicMethod(kernelInstanceEval)),
+	"instance_exec": publicMethod(kernelInstanceExec),
+}
+
+// freezable is implemented by objects which can be frozen, i.e. made
+// immutable, at runtime
+type freezable interface {
+	Frozen() bool
+	Freeze()
+}
+
+// modifying wraps fn, a method modifying its receiver in place, to return a
+// FrozenError instead if the receiver is frozen
+func modifying(fn func(context RubyObject, args ...RubyObject) (RubyObject, error)) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		if isFrozen(unwrapObject(context)) {
+			return nil, NewFrozenError(context)
+		}
+		return fn(context, args...)
+	}
 }
 
 func kernelPuts(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return putsTo(DefaultStreams(), args...)
+}
+
+func putsTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
 	out := ""
 	for _, arg := range args {
 		out += arg.Inspect()
 	}
-	fmt.Println(out)
+	fmt.Fprintln(streams.Stdout, out)
+	return NIL, nil
+}
+
+func kernelPrint(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return printTo(DefaultStreams(), args...)
+}
+
+func printTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	for _, arg := range args {
+		fmt.Fprint(streams.Stdout, toS(arg))
+	}
 	return NIL, nil
 }
 
+// inspectArguments writes the representation returned by format for every
+// argument on its own line to out. It returns nil without arguments, the
+// argument itself for a single argument and all arguments as Array
+// otherwise.
+func inspectArguments(out io.Writer, args []RubyObject, format func(RubyObject) string) RubyObject {
+	args, _ = extractBlock(args)
+	for _, arg := range args {
+		fmt.Fprintln(out, format(arg))
+	}
+	switch len(args) {
+	case 0:
+		return NIL
+	case 1:
+		return args[0]
+	default:
+		return NewArray(args...)
+	}
+}
+
+func kernelP(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return pTo(DefaultStreams(), args...)
+}
+
+func pTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	return inspectArguments(streams.Stdout, args, inspect), nil
+}
+
+func kernelPP(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return ppTo(DefaultStreams(), args...)
+}
+
+func ppTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	return inspectArguments(streams.Stdout, args, func(obj RubyObject) string {
+		return prettyInspect(obj, 0)
+	}), nil
+}
+
+// kernelGets reads the next line from Stdin. It returns nil at the end of
+// the stream.
+func kernelGets(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return getsFrom(r, DefaultStreams(), args...)
+}
+
+func getsFrom(r *Runtime, streams *Streams, args ...RubyObject) (RubyObject, error) {
+	return ioGets(r, streams.Stdin, args...)
+}
+
 func kernelMethods(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return newArrayOf(methodNames(context, PUBLIC_METHOD)), nil
+}
+
+func kernelPrivateMethods(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return newArrayOf(methodNames(context, PRIVATE_METHOD)), nil
+}
+
+// methodNames returns the names of the methods of context with the given
+// visibility as Symbols
+func methodNames(context RubyObject, visibility MethodVisibility) []RubyObject {
 	var methodSymbols []RubyObject
 	class := context.Class()
 	for class != nil {
 		methods := class.Methods()
 		for meth, fn := range methods {
-			if fn.Visibility() == PUBLIC_METHOD {
-				methodSymbols = append(methodSymbols, &Symbol{meth})
+			if fn.Visibility() == visibility {
+				methodSymbols = append(methodSymbols, NewSymbol(meth))
 			}
 		}
 		class = class.SuperClass()
 	}
+	return methodSymbols
+}
+
+func kernelSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if context == args[0] {
+		return NewInteger(0), nil
+	}
+	return NIL, nil
+}
+
+func kernelEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if context == args[0] {
+		return TRUE, nil
+	}
+	left, ok := context.(hashable)
+	if !ok {
+		return FALSE, nil
+	}
+	right, ok := args[0].(hashable)
+	if !ok {
+		return FALSE, nil
+	}
+	return nativeBoolToBoolean(left.hashKey() == right.hashKey()), nil
+}
+
+// isEqual reports whether a equals b by sending `==` to a
+func isEqual(a, b RubyObject) (bool, error) {
+	result, err := Send(a, "==", b)
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(result), nil
+}
 
-	return &Array{Elements: methodSymbols}, nil
+func kernelCaseEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return Send(context, "==", args...)
 }
 
 func kernelIsNil(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -57,10 +400,172 @@ func kernelIsNil(context RubyObject, args ...RubyObject) (RubyObject, error) {
 }
 
 func kernelClass(context RubyObject, args ...RubyObject) (RubyObject, error) {
-	class := context.Class()
-	if eigenClass, ok := class.(*eigenclass); ok {
-		class = eigenClass.Class()
+	return realClass(context).(RubyClassObject), nil
+}
+
+func kernelIsA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ok, err := isA(context, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(ok), nil
+}
+
+func kernelIsInstanceOf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	switch args[0].(type) {
+	case *Module, RubyClassObject:
+	default:
+		return nil, NewTypeError("class or module required")
+	}
+	class, err := kernelClass(context)
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(class == args[0]), nil
+}
+
+func kernelInspect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: inspect(context)}, nil
+}
+
+func kernelToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: toS(unwrapObject(context))}, nil
+}
+
+// kernelTap yields context to the block and returns context
+func kernelTap(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	if _, err := block.Call(context); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// kernelThen yields context to the block and returns the result of the
+// block
+func kernelThen(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(context, "then"), nil
+	}
+	return block.Call(context)
+}
+
+// kernelLoop calls the block repeatedly until it breaks out. A
+// StopIteration raised within the block ends the loop as well, returning
+// the result of the iteration.
+func kernelLoop(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(context, "loop"), nil
+	}
+	for {
+		if err := r.CheckInterrupt(); err != nil {
+			return nil, err
+		}
+		_, err := block.Call()
+		if err == nil {
+			continue
+		}
+		if exception, ok := err.(RubyObject); ok && IsKindOf(exception, stopIterationClass) {
+			return Send(exception, "result")
+		}
+		return nil, err
+	}
+}
+
+func kernelFreeze(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if obj, ok := unwrapObject(context).(freezable); ok {
+		obj.Freeze()
+	}
+	return context, nil
+}
+
+func kernelIsFrozen(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(isFrozen(unwrapObject(context))), nil
+}
+
+// isFrozen reports whether obj is frozen. Immediate values like Integers or
+// Symbols are always frozen.
+func isFrozen(obj RubyObject) bool {
+	if obj, ok := obj.(freezable); ok {
+		return obj.Frozen()
+	}
+	switch obj.(type) {
+	case *Integer, *Float, *Symbol, *Boolean, *nilObject:
+		return true
+	default:
+		return false
+	}
+}
+
+// kernelRaise raises an exception. Given a String it raises a RuntimeError
+// with it as message. Given an exception class or object it raises the
+// result of sending `exception` to it, passing on the optional message.
+func kernelRaise(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 {
+		return nil, NewRuntimeError("unhandled exception")
+	}
+	if message, ok := args[0].(*String); ok && len(args) == 1 {
+		return nil, NewRuntimeError("%s", message.Value)
+	}
+	if _, ok := findMethod(args[0], "exception"); !ok {
+		return nil, NewTypeError("exception class/object expected")
+	}
+	exception, err := Send(args[0], "exception", args[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := exception.(messageHolder); ok {
+		return nil, err
+	}
+	return nil, NewTypeError("exception class/object expected")
+}
+
+// kernelSleep suspends the program for the given number of seconds, or
+// until it is interrupted if there is none. Trapped signals received
+// meanwhile are handled without waking it up. It returns the number of
+// seconds slept, rounded to an Integer.
+func kernelSleep(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	timeout := time.Duration(-1)
+	if len(args) == 1 {
+		seconds, ok := toFloat(args[0])
+		if !ok {
+			return nil, NewTypeError("can't convert %s into time interval", comparisonOperandName(args[0]))
+		}
+		if seconds < 0 {
+			return nil, NewArgumentError("time interval must not be negative")
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	start := time.Now()
+	if _, err := r.wait(nil, timeout); err != nil {
+		return nil, err
+	}
+	return NewInteger(int64(math.Round(time.Since(start).Seconds()))), nil
+}
+
+// kernelExit terminates the program by raising a SystemExit. The exit
+// status is given as Integer or as boolean indicating success.
+func kernelExit(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 {
+		return nil, NewSystemExit(0)
+	}
+	switch status := args[0].(type) {
+	case *Integer:
+		return nil, NewSystemExit(int(status.Value))
+	case *Boolean:
+		if status.Value {
+			return nil, NewSystemExit(0)
+		}
+		return nil, NewSystemExit(1)
+	default:
+		return nil, NewImplicitConversionTypeError(&Integer{}, args[0])
 	}
-	classObj := class.(RubyClassObject)
-	return classObj, nil
 }
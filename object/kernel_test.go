@@ -1,9 +1,12 @@
 package object
 
 import (
+	"bytes"
+	"io"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestKernelMethods(t *testing.T) {
@@ -190,6 +193,70 @@ func TestKernelIsNil(t *testing.T) {
 	}
 }
 
+func TestKernelEqual(t *testing.T) {
+	obj := &IO{}
+	tests := []struct {
+		context  RubyObject
+		arg      RubyObject
+		expected RubyObject
+	}{
+		{obj, obj, TRUE},
+		{obj, &IO{}, FALSE},
+		{&Symbol{Value: "a"}, &Symbol{Value: "a"}, TRUE},
+		{&Symbol{Value: "a"}, &String{Value: "a"}, FALSE},
+		{&String{Value: "a"}, &String{Value: "a"}, TRUE},
+		{NIL, NIL, TRUE},
+		{NIL, FALSE, FALSE},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelEqual(tt.context, tt.arg)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+
+		result, err = kernelCaseEqual(tt.context, tt.arg)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestKernelFreeze(t *testing.T) {
+	str := &String{Value: "foo"}
+
+	result, err := kernelIsFrozen(str)
+	checkError(t, err, nil)
+	checkResult(t, result, FALSE)
+
+	result, err = kernelFreeze(str)
+	checkError(t, err, nil)
+	if result != str {
+		t.Logf("Expected freeze to return its receiver, got %#v", result)
+		t.Fail()
+	}
+
+	result, err = kernelIsFrozen(str)
+	checkError(t, err, nil)
+	checkResult(t, result, TRUE)
+
+	tests := []struct {
+		context  RubyObject
+		expected RubyObject
+	}{
+		{NewInteger(1), TRUE},
+		{&Symbol{Value: "a"}, TRUE},
+		{NIL, TRUE},
+		{NewArray(), FALSE},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelIsFrozen(tt.context)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
 func TestKernelClass(t *testing.T) {
 	t.Run("regular object", func(t *testing.T) {
 		context := &Integer{1}
@@ -252,3 +319,153 @@ func TestKernelClass(t *testing.T) {
 		}
 	})
 }
+
+func TestKernelOutput(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		args     []RubyObject
+		output   string
+		expected RubyObject
+	}{
+		{"print", kernelPrint, []RubyObject{str("a"), NewInteger(1), NIL, &Symbol{"b"}}, "a1b", NIL},
+		{"p without args", kernelP, nil, "", NIL},
+		{"p", kernelP, []RubyObject{str("a\n")}, "\"a\\n\"\n", str("a\n")},
+		{"p with many args", kernelP, []RubyObject{NewInteger(1), NewArray(str("x"))}, "1\n[\"x\"]\n", NewArray(NewInteger(1), NewArray(str("x")))},
+		{"pp", kernelPP, []RubyObject{NIL}, "nil\n", NIL},
+		{"printf", kernelPrintf, []RubyObject{str("%s-%03d"), str("a"), NewInteger(7)}, "a-007", NIL},
+		{"printf without args", kernelPrintf, nil, "", NIL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			defer func(stdout io.Writer) { Stdout = stdout }(Stdout)
+			Stdout = &out
+
+			result, err := tt.method(&Object{}, tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+			if out.String() != tt.output {
+				t.Logf("Expected output %q, got %q", tt.output, out.String())
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestKernelTapAndThen(t *testing.T) {
+	var yielded []RubyObject
+	block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		yielded = append(yielded, args...)
+		return NewInteger(42), nil
+	}, "x")
+	context := &String{Value: "foo"}
+
+	result, err := Send(context, "tap", block)
+	checkError(t, err, nil)
+	if result != context {
+		t.Logf("Expected tap to return the receiver, got %v", result)
+		t.Fail()
+	}
+
+	result, err = Send(context, "then", block)
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(42))
+
+	result, err = Send(context, "yield_self", block)
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(42))
+
+	checkResult(t, NewArray(yielded...), NewArray(context, context, context))
+
+	_, err = Send(context, "tap")
+	checkError(t, err, NewArgumentError("no block given"))
+
+	result, err = Send(context, "then")
+	checkError(t, err, nil)
+	checkResult(t, result, NewEnumerator(context, "then"))
+}
+
+func TestKernelLoop(t *testing.T) {
+	calls := 0
+	block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		calls++
+		if calls == 3 {
+			return nil, NewStopIteration(NewInteger(42))
+		}
+		return NIL, nil
+	})
+
+	result, err := kernelLoop(nil, &Object{}, block)
+
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(42))
+	if calls != 3 {
+		t.Logf("Expected block to be called 3 times, got %d", calls)
+		t.Fail()
+	}
+}
+
+func TestKernelSleep(t *testing.T) {
+	t.Run("with duration", func(t *testing.T) {
+		start := time.Now()
+
+		result, err := kernelSleep(nil, &Object{}, NewFloat(0.01))
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(0))
+		if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+			t.Logf("Expected sleep to take at least 10ms, took %s", elapsed)
+			t.Fail()
+		}
+	})
+	t.Run("interrupted", func(t *testing.T) {
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			(*Runtime)(nil).RaiseInterrupt()
+		}()
+
+		_, err := kernelSleep(nil, &Object{})
+
+		checkError(t, err, NewInterrupt())
+		checkError(t, CheckInterrupt(), nil)
+	})
+	t.Run("invalid duration", func(t *testing.T) {
+		_, err := kernelSleep(nil, &Object{}, NewInteger(-1))
+		checkError(t, err, NewArgumentError("time interval must not be negative"))
+
+		_, err = kernelSleep(nil, &Object{}, &String{Value: "1"})
+		checkError(t, err, NewTypeError("can't convert String into time interval"))
+	})
+}
+
+func TestCheckInterrupt(t *testing.T) {
+	checkError(t, CheckInterrupt(), nil)
+
+	(*Runtime)(nil).RaiseInterrupt()
+
+	checkError(t, CheckInterrupt(), NewInterrupt())
+	checkError(t, CheckInterrupt(), nil)
+}
+
+func TestKernelExit(t *testing.T) {
+	tests := []struct {
+		args     []RubyObject
+		expected error
+	}{
+		{nil, NewSystemExit(0)},
+		{[]RubyObject{NewInteger(3)}, NewSystemExit(3)},
+		{[]RubyObject{TRUE}, NewSystemExit(0)},
+		{[]RubyObject{FALSE}, NewSystemExit(1)},
+		{[]RubyObject{&String{Value: "1"}}, NewImplicitConversionTypeError(&Integer{}, &String{})},
+	}
+
+	for _, tt := range tests {
+		_, err := kernelExit(&Object{}, tt.args...)
+
+		checkError(t, err, tt.expected)
+	}
+}
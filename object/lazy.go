@@ -0,0 +1,409 @@
+package object
+
+import "strings"
+
+var lazyClass RubyClassObject = newClass("Enumerator::Lazy", enumeratorClass, lazyMethods, nil)
+
+func init() {
+	setConstant(enumeratorClass, "Lazy", lazyClass)
+}
+
+// NewLazy returns a Lazy iterating source by its each method
+func NewLazy(source RubyObject) *Lazy {
+	return &Lazy{source: source}
+}
+
+// A Lazy represents an Enumerator::Lazy. Its operations like map and select
+// do not iterate, but return a new Lazy applying the operation to every
+// value once it is iterated. Values are thus only computed on demand, which
+// allows to work with infinite sequences.
+type Lazy struct {
+	// source is the object iterated, if the Lazy has no parent
+	source RubyObject
+	parent *Lazy
+	// method is the name of the operation applied to the values of parent
+	method string
+	args   []RubyObject
+	// step returns the function applying the operation to a single value
+	// for a new iteration
+	step  func() lazyStep
+	eager *Enumerator
+}
+
+// lazyStep applies an operation to value, passing the resulting values on
+// to emit. It returns errStopLazy to end the iteration early.
+type lazyStep func(value RubyObject, emit func(RubyObject) error) error
+
+// stopLazy ends an iteration early. It is no Ruby exception and passes
+// through the iteration methods up to the one who returned it.
+type stopLazy struct{}
+
+func (s *stopLazy) Error() string { return "stop lazy iteration" }
+
+// Type returns ENUMERATOR_OBJ
+func (l *Lazy) Type() Type { return ENUMERATOR_OBJ }
+
+// Inspect returns the source and operations of the Lazy
+func (l *Lazy) Inspect() string {
+	if l.parent == nil {
+		return "#<Enumerator::Lazy: " + l.source.Inspect() + ">"
+	}
+	var out strings.Builder
+	out.WriteString("#<Enumerator::Lazy: ")
+	out.WriteString(l.parent.Inspect())
+	out.WriteString(":")
+	out.WriteString(l.method)
+	if len(l.args) > 0 {
+		args := make([]string, len(l.args))
+		for i, arg := range l.args {
+			args[i] = arg.Inspect()
+		}
+		out.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// Class returns lazyClass
+func (l *Lazy) Class() RubyClass { return lazyClass }
+
+// Each iterates the values of l, calling block for every one of them
+func (l *Lazy) Each(block *Proc) (RubyObject, error) {
+	err := l.each(func(value RubyObject) error {
+		_, err := block.Call(value)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// each calls emit with every value of l. An errStopLazy returned by the
+// operation of l ends the iteration without error. It is replaced by a
+// stopLazy of its own while unwinding, so that it does not end the
+// iteration of other Lazies involved.
+func (l *Lazy) each(emit func(RubyObject) error) error {
+	if l.parent == nil {
+		_, err := Send(l.source, "each", newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return NIL, emit(yieldedValue(args))
+		}))
+		return err
+	}
+	step := l.step()
+	stop := &stopLazy{}
+	err := l.parent.each(func(value RubyObject) error {
+		if err := step(value, emit); err != errStopLazy {
+			return err
+		}
+		return stop
+	})
+	if err == stop {
+		return nil
+	}
+	return err
+}
+
+// errStopLazy is returned by a lazyStep to end the iteration of its Lazy
+var errStopLazy = &stopLazy{}
+
+// chain returns a Lazy applying the operation returned by step to the
+// values of l
+func (l *Lazy) chain(method string, args []RubyObject, step func() lazyStep) *Lazy {
+	return &Lazy{parent: l, method: method, args: args, step: step}
+}
+
+// take returns up to n values of l, stopping the iteration afterwards
+func (l *Lazy) take(n int) (*Array, error) {
+	values := NewArray()
+	if n == 0 {
+		return values, nil
+	}
+	stop := &stopLazy{}
+	err := l.each(func(value RubyObject) error {
+		values.Elements = append(values.Elements, value)
+		if len(values.Elements) >= n {
+			return stop
+		}
+		return nil
+	})
+	if err != nil && err != stop {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ToA returns all values of l
+func (l *Lazy) ToA() (*Array, error) {
+	values := NewArray()
+	err := l.each(func(value RubyObject) error {
+		values.Elements = append(values.Elements, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+var lazyMethods = map[string]RubyMethod{
+	"map":        withArity(0, publicMethod(lazyMap)),
+	"collect":    withArity(0, publicMethod(lazyMap)),
+	"select":     withArity(0, publicMethod(lazySelect)),
+	"filter":     withArity(0, publicMethod(lazySelect)),
+	"reject":     withArity(0, publicMethod(lazyReject)),
+	"filter_map": withArity(0, publicMethod(lazyFilterMap)),
+	"flat_map":   withArity(0, publicMethod(lazyFlatMap)),
+	"take_while": withArity(0, publicMethod(lazyTakeWhile)),
+	"drop_while": withArity(0, publicMethod(lazyDropWhile)),
+	"take":       withArity(1, publicMethod(lazyTake)),
+	"drop":       withArity(1, publicMethod(lazyDrop)),
+	"first":      withArityRange(0, 1, publicMethod(lazyFirst)),
+	"each":       withArity(0, publicMethod(lazyEach)),
+	"to_a":       withArity(0, publicMethod(lazyToA)),
+	"force":      withArity(0, publicMethod(lazyToA)),
+	"lazy":       withArity(0, publicMethod(lazyLazy)),
+	"eager":      withArity(0, publicMethod(lazyEager)),
+}
+
+func init() {
+	// the remaining methods of Enumerator are not lazy and run on an
+	// Enumerator iterating the Lazy
+	for name := range enumeratorMethods {
+		if _, ok := lazyMethods[name]; !ok {
+			lazyMethods[name] = publicMethod(lazyDelegate(name))
+		}
+	}
+}
+
+func lazyDelegate(name string) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return Send(context.(*Lazy).enumerator(), name, args...)
+	}
+}
+
+// enumerator returns the Enumerator iterating l, which keeps the state of
+// the external iteration by next and peek
+func (l *Lazy) enumerator() *Enumerator {
+	if l.eager == nil {
+		l.eager = NewEnumerator(l, "each")
+	}
+	return l.eager
+}
+
+// lazyBlock returns the block passed to the operation method, which is
+// required
+func lazyBlock(method string, args []RubyObject) (*Proc, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("tried to call lazy %s without a block", method)
+	}
+	return block, nil
+}
+
+// lazyOperation returns a method adding an operation taking a block to a
+// Lazy. The operation is built by step from the block.
+func lazyOperation(method string, step func(block *Proc) lazyStep) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		block, err := lazyBlock(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return context.(*Lazy).chain(method, nil, func() lazyStep { return step(block) }), nil
+	}
+}
+
+var lazyMap = lazyOperation("map", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil {
+			return err
+		}
+		return emit(result)
+	}
+})
+
+var lazySelect = lazyOperation("select", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil || !isTruthy(result) {
+			return err
+		}
+		return emit(value)
+	}
+})
+
+var lazyReject = lazyOperation("reject", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil || isTruthy(result) {
+			return err
+		}
+		return emit(value)
+	}
+})
+
+var lazyFilterMap = lazyOperation("filter_map", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil || !isTruthy(result) {
+			return err
+		}
+		return emit(result)
+	}
+})
+
+// lazyFlatMap emits the elements of Arrays returned by the block one by
+// one and other results as they are
+var lazyFlatMap = lazyOperation("flat_map", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil {
+			return err
+		}
+		array, ok := result.(*Array)
+		if !ok {
+			return emit(result)
+		}
+		for _, element := range array.Elements {
+			if err := emit(element); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+})
+
+var lazyTakeWhile = lazyOperation("take_while", func(block *Proc) lazyStep {
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		result, err := block.Call(value)
+		if err != nil {
+			return err
+		}
+		if !isTruthy(result) {
+			return errStopLazy
+		}
+		return emit(value)
+	}
+})
+
+var lazyDropWhile = lazyOperation("drop_while", func(block *Proc) lazyStep {
+	dropping := true
+	return func(value RubyObject, emit func(RubyObject) error) error {
+		if dropping {
+			result, err := block.Call(value)
+			if err != nil {
+				return err
+			}
+			if isTruthy(result) {
+				return nil
+			}
+			dropping = false
+		}
+		return emit(value)
+	}
+})
+
+// lazyCount returns the non-negative number of values given to take or
+// drop
+func lazyCount(arg RubyObject, method string) (int, error) {
+	n, err := integerArgument(arg)
+	if err != nil {
+		return 0, err
+	}
+	if n.Value < 0 {
+		return 0, NewArgumentError("attempt to %s negative size", method)
+	}
+	return int(n.Value), nil
+}
+
+// lazyTake passes on the first n values and ends the iteration right
+// after the last of them, so that no further value is computed
+func lazyTake(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	n, err := lazyCount(args[0], "take")
+	if err != nil {
+		return nil, err
+	}
+	lazy := context.(*Lazy)
+	if n == 0 {
+		return NewLazy(NewArray()), nil
+	}
+	return lazy.chain("take", args[:1], func() lazyStep {
+		taken := 0
+		return func(value RubyObject, emit func(RubyObject) error) error {
+			taken++
+			if err := emit(value); err != nil {
+				return err
+			}
+			if taken >= n {
+				return errStopLazy
+			}
+			return nil
+		}
+	}), nil
+}
+
+func lazyDrop(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	n, err := lazyCount(args[0], "drop")
+	if err != nil {
+		return nil, err
+	}
+	return context.(*Lazy).chain("drop", args[:1], func() lazyStep {
+		dropped := 0
+		return func(value RubyObject, emit func(RubyObject) error) error {
+			if dropped < n {
+				dropped++
+				return nil
+			}
+			return emit(value)
+		}
+	}), nil
+}
+
+// lazyFirst returns the first value, or an Array of the first n values,
+// computing no more values than needed
+func lazyFirst(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	lazy := context.(*Lazy)
+	if len(args) == 0 {
+		values, err := lazy.take(1)
+		if err != nil {
+			return nil, err
+		}
+		if len(values.Elements) == 0 {
+			return NIL, nil
+		}
+		return values.Elements[0], nil
+	}
+	n, err := lazyCount(args[0], "take")
+	if err != nil {
+		return nil, err
+	}
+	return lazy.take(n)
+}
+
+func lazyEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return context, nil
+	}
+	return context.(*Lazy).Each(block)
+}
+
+func lazyToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Lazy).ToA()
+}
+
+func lazyLazy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context, nil
+}
+
+// lazyEager returns an Enumerator iterating the values of the Lazy, whose
+// operations are no longer lazy
+func lazyEager(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewEnumerator(context, "each"), nil
+}
+
+// enumerableLazy returns a Lazy iterating the receiver
+func enumerableLazy(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewLazy(context), nil
+}
@@ -0,0 +1,44 @@
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLazyComputesOnDemand(t *testing.T) {
+	var computed []RubyObject
+	double := testBlock(func(args ...RubyObject) (RubyObject, error) {
+		computed = append(computed, args[0])
+		return NewInteger(args[0].(*Integer).Value * 2), nil
+	}, "x")
+	infinite, err := NewRange(NewInteger(1), NewFloat(math.Inf(1)), false)
+	checkError(t, err, nil)
+
+	lazy, err := Send(NewLazy(infinite), "map", double)
+	checkError(t, err, nil)
+	if len(computed) != 0 {
+		t.Logf("Expected map not to compute values, got %d", len(computed))
+		t.Fail()
+	}
+
+	result, err := Send(lazy, "first", NewInteger(3))
+
+	checkError(t, err, nil)
+	checkResult(t, result, NewArray(NewInteger(2), NewInteger(4), NewInteger(6)))
+	if len(computed) != 3 {
+		t.Logf("Expected 3 values to be computed, got %d", len(computed))
+		t.Fail()
+	}
+}
+
+func TestLazyInspect(t *testing.T) {
+	lazy := NewLazy(NewArray(NewInteger(1)))
+	taken, err := lazyTake(lazy, NewInteger(2))
+	checkError(t, err, nil)
+
+	expected := "#<Enumerator::Lazy: #<Enumerator::Lazy: [1]>:take(2)>"
+	if taken.Inspect() != expected {
+		t.Logf("Expected Inspect to return %q, got %q", expected, taken.Inspect())
+		t.Fail()
+	}
+}
@@ -0,0 +1,70 @@
+package object
+
+import "fmt"
+
+// locationClass is not registered as a constant, as there is no Thread
+// class to nest it in
+var locationClass RubyClassObject = newClass("Thread::Backtrace::Location", objectClass, locationMethods, nil)
+
+// NewLocation returns a Location at the given line of the file path, within
+// the method or block described by label
+func NewLocation(path string, lineno int, label string) *Location {
+	return &Location{Path: path, Lineno: lineno, Label: label}
+}
+
+// A Location represents a frame of the call stack, as returned by
+// Kernel#caller_locations
+type Location struct {
+	Path   string
+	Lineno int
+	Label  string
+}
+
+// Type returns LOCATION_OBJ
+func (l *Location) Type() Type { return LOCATION_OBJ }
+
+// Inspect returns the location formatted like within a backtrace, quoted
+func (l *Location) Inspect() string { return fmt.Sprintf("%q", l.String()) }
+
+// String returns the location formatted like within a backtrace
+func (l *Location) String() string {
+	return fmt.Sprintf("%s:%d:in `%s'", l.Path, l.Lineno, l.Label)
+}
+
+// Class returns locationClass
+func (l *Location) Class() RubyClass { return locationClass }
+
+var locationMethods = map[string]RubyMethod{
+	"path":          withArity(0, publicMethod(locationPath)),
+	"absolute_path": withArity(0, publicMethod(locationPath)),
+	"lineno":        withArity(0, publicMethod(locationLineno)),
+	"label":         withArity(0, publicMethod(locationLabel)),
+	"base_label":    withArity(0, publicMethod(locationLabel)),
+	"to_s":          withArity(0, publicMethod(locationToS)),
+	"inspect":       withArity(0, publicMethod(locationInspect)),
+}
+
+func locationPath(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	location := context.(*Location)
+	return &String{Value: location.Path}, nil
+}
+
+func locationLineno(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	location := context.(*Location)
+	return NewInteger(int64(location.Lineno)), nil
+}
+
+func locationLabel(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	location := context.(*Location)
+	return &String{Value: location.Label}, nil
+}
+
+func locationToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	location := context.(*Location)
+	return &String{Value: location.String()}, nil
+}
+
+func locationInspect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	location := context.(*Location)
+	return &String{Value: location.Inspect()}, nil
+}
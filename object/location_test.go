@@ -0,0 +1,25 @@
+package object
+
+import "testing"
+
+func TestLocationMethods(t *testing.T) {
+	location := NewLocation("foo.rb", 3, "block in bar")
+
+	tests := []struct {
+		method   string
+		expected RubyObject
+	}{
+		{"path", &String{Value: "foo.rb"}},
+		{"lineno", NewInteger(3)},
+		{"label", &String{Value: "block in bar"}},
+		{"to_s", &String{Value: "foo.rb:3:in `block in bar'"}},
+		{"inspect", &String{Value: "\"foo.rb:3:in `block in bar'\""}},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(location, tt.method)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
@@ -0,0 +1,786 @@
+package object
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+var marshalModule = newModule("Marshal", marshalMethods)
+
+func init() {
+	classes.Set("Marshal", marshalModule)
+	setConstant(marshalModule, "MAJOR_VERSION", NewInteger(marshalMajor))
+	setConstant(marshalModule, "MINOR_VERSION", NewInteger(marshalMinor))
+}
+
+var marshalMethods = map[string]RubyMethod{
+	"dump": withArity(1, publicMethod(marshalDump)),
+	"load": withArity(1, publicRuntimeMethod(marshalLoad)),
+}
+
+// The version of the Marshal format, which is the one of Ruby, so that data
+// can be exchanged with it
+const (
+	marshalMajor = 4
+	marshalMinor = 8
+)
+
+// The type bytes of the Marshal format
+const (
+	marshalNil        = '0'
+	marshalTrue       = 'T'
+	marshalFalse      = 'F'
+	marshalFixnum     = 'i'
+	marshalBignum     = 'l'
+	marshalFloat      = 'f'
+	marshalString     = '"'
+	marshalSymbol     = ':'
+	marshalSymlink    = ';'
+	marshalLink       = '@'
+	marshalIvar       = 'I'
+	marshalArray      = '['
+	marshalHash       = '{'
+	marshalObject     = 'o'
+	marshalClass      = 'c'
+	marshalModuleType = 'm'
+	marshalUserMarsh  = 'U'
+)
+
+// marshalFixnumRange is the range of Integers written as Fixnum, larger
+// ones are written as Bignum
+const marshalFixnumRange = 1 << 30
+
+// marshalDump serializes the object graph passed into a String. Cycles and
+// objects referenced more than once are written once and restored as such
+// by Marshal.load. Objects responding to marshal_dump are written as the
+// result of it.
+func marshalDump(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	dumper := &marshalDumper{symbols: map[string]int{}, objects: map[RubyObject]int{}}
+	dumper.out.WriteByte(marshalMajor)
+	dumper.out.WriteByte(marshalMinor)
+	if err := dumper.dump(args[0]); err != nil {
+		return nil, err
+	}
+	return &String{Value: dumper.out.String()}, nil
+}
+
+// marshalDumper writes the Marshal format of objects to out
+type marshalDumper struct {
+	out     strings.Builder
+	symbols map[string]int
+	objects map[RubyObject]int
+}
+
+// remember registers obj for being linked once written again. It reports
+// whether it has been written already, in which case the link is written.
+func (d *marshalDumper) remember(obj RubyObject) bool {
+	if index, ok := d.objects[obj]; ok {
+		d.out.WriteByte(marshalLink)
+		d.writeLong(int64(index))
+		return true
+	}
+	d.objects[obj] = len(d.objects)
+	return false
+}
+
+func (d *marshalDumper) dump(obj RubyObject) error {
+	obj = unwrapObject(obj)
+	switch obj := obj.(type) {
+	case *nilObject:
+		d.out.WriteByte(marshalNil)
+	case *Boolean:
+		if obj.Value {
+			d.out.WriteByte(marshalTrue)
+		} else {
+			d.out.WriteByte(marshalFalse)
+		}
+	case *Integer:
+		d.writeInteger(obj.Value)
+	case *Symbol:
+		d.writeSymbol(obj.Value)
+	case *Float:
+		if d.remember(obj) {
+			return nil
+		}
+		d.out.WriteByte(marshalFloat)
+		d.writeBytes(marshalFloatString(obj.Value))
+	case *String:
+		if d.remember(obj) {
+			return nil
+		}
+		d.out.WriteByte(marshalIvar)
+		d.out.WriteByte(marshalString)
+		d.writeBytes(obj.Value)
+		return d.writeIvars(obj, 1, func() error {
+			d.writeSymbol("E")
+			d.out.WriteByte(marshalTrue)
+			return nil
+		})
+	case *Array:
+		if d.remember(obj) {
+			return nil
+		}
+		return d.wrapIvars(obj, func() error {
+			d.out.WriteByte(marshalArray)
+			d.writeLong(int64(len(obj.Elements)))
+			for _, element := range obj.Elements {
+				if err := d.dump(element); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case *Hash:
+		if d.remember(obj) {
+			return nil
+		}
+		return d.wrapIvars(obj, func() error {
+			d.out.WriteByte(marshalHash)
+			d.writeLong(int64(obj.Len()))
+			keys, values := obj.Keys(), obj.Values()
+			for i, key := range keys {
+				if err := d.dump(key); err != nil {
+					return err
+				}
+				if err := d.dump(values[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case *Range:
+		if d.remember(obj) {
+			return nil
+		}
+		d.out.WriteByte(marshalObject)
+		d.writeSymbol("Range")
+		d.writeLong(3)
+		d.writeSymbol("excl")
+		if err := d.dump(nativeBoolToBoolean(obj.Exclusive)); err != nil {
+			return err
+		}
+		d.writeSymbol("begin")
+		if err := d.dump(obj.First); err != nil {
+			return err
+		}
+		d.writeSymbol("end")
+		return d.dump(obj.Last)
+	case *Module:
+		return d.writeModule(marshalModuleType, obj)
+	case RubyClassObject:
+		return d.writeModule(marshalClass, obj)
+	default:
+		return d.dumpObject(obj)
+	}
+	return nil
+}
+
+// dumpObject writes an instance of a class defined in Ruby with its
+// instance variables, or the result of its marshal_dump
+func (d *marshalDumper) dumpObject(obj RubyObject) error {
+	if _, ok := obj.(*Object); !ok {
+		return NewTypeError("no _dump_data is defined for class %s", className(obj))
+	}
+	name, err := marshalClassName(realClass(obj).(RubyObject))
+	if err != nil {
+		return err
+	}
+	if d.remember(obj) {
+		return nil
+	}
+	if _, ok := findMethod(obj, "marshal_dump"); ok {
+		data, err := Send(obj, "marshal_dump")
+		if err != nil {
+			return err
+		}
+		d.out.WriteByte(marshalUserMarsh)
+		d.writeSymbol(name)
+		return d.dump(data)
+	}
+	d.out.WriteByte(marshalObject)
+	d.writeSymbol(name)
+	ivars := instanceVariablesOf(obj, false)
+	if ivars == nil {
+		d.writeLong(0)
+		return nil
+	}
+	d.writeLong(int64(len(ivars.names)))
+	for _, name := range ivars.names {
+		d.writeSymbol(name)
+		if err := d.dump(ivars.values[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrapIvars writes obj by write, wrapped into its instance variables if it
+// has any
+func (d *marshalDumper) wrapIvars(obj RubyObject, write func() error) error {
+	ivars := instanceVariablesOf(obj, false)
+	if ivars == nil || len(ivars.names) == 0 {
+		return write()
+	}
+	d.out.WriteByte(marshalIvar)
+	if err := write(); err != nil {
+		return err
+	}
+	return d.writeIvars(obj, 0, nil)
+}
+
+// writeIvars writes the instance variables of obj following extra ones
+// written by writeExtra
+func (d *marshalDumper) writeIvars(obj RubyObject, extra int, writeExtra func() error) error {
+	ivars := instanceVariablesOf(obj, false)
+	count := extra
+	if ivars != nil {
+		count += len(ivars.names)
+	}
+	d.writeLong(int64(count))
+	if writeExtra != nil {
+		if err := writeExtra(); err != nil {
+			return err
+		}
+	}
+	if ivars == nil {
+		return nil
+	}
+	for _, name := range ivars.names {
+		d.writeSymbol(name)
+		if err := d.dump(ivars.values[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *marshalDumper) writeModule(typ byte, module RubyObject) error {
+	name, err := marshalClassName(module)
+	if err != nil {
+		return err
+	}
+	if d.remember(module) {
+		return nil
+	}
+	d.out.WriteByte(typ)
+	d.writeBytes(name)
+	return nil
+}
+
+// marshalClassName returns the name of the class or module, which must not
+// be anonymous to be found again by Marshal.load
+func marshalClassName(module RubyObject) (string, error) {
+	name := module.Inspect()
+	if strings.HasPrefix(name, "#<") {
+		if _, ok := module.(*Module); ok {
+			return "", NewTypeError("can't dump anonymous module %s", name)
+		}
+		return "", NewTypeError("can't dump anonymous class %s", name)
+	}
+	return name, nil
+}
+
+func (d *marshalDumper) writeInteger(value int64) {
+	if value >= -marshalFixnumRange && value < marshalFixnumRange {
+		d.out.WriteByte(marshalFixnum)
+		d.writeLong(value)
+		return
+	}
+	d.out.WriteByte(marshalBignum)
+	magnitude := uint64(value)
+	if value < 0 {
+		d.out.WriteByte('-')
+		magnitude = uint64(-value)
+	} else {
+		d.out.WriteByte('+')
+	}
+	var bytes []byte
+	for magnitude > 0 {
+		bytes = append(bytes, byte(magnitude))
+		magnitude >>= 8
+	}
+	if len(bytes)%2 == 1 {
+		bytes = append(bytes, 0)
+	}
+	d.writeLong(int64(len(bytes) / 2))
+	d.out.Write(bytes)
+}
+
+func (d *marshalDumper) writeSymbol(name string) {
+	if index, ok := d.symbols[name]; ok {
+		d.out.WriteByte(marshalSymlink)
+		d.writeLong(int64(index))
+		return
+	}
+	d.symbols[name] = len(d.symbols)
+	d.out.WriteByte(marshalSymbol)
+	d.writeBytes(name)
+}
+
+func (d *marshalDumper) writeBytes(s string) {
+	d.writeLong(int64(len(s)))
+	d.out.WriteString(s)
+}
+
+// writeLong writes the packed integer format of Marshal, which stores small
+// numbers in a single byte and others in up to four bytes
+func (d *marshalDumper) writeLong(x int64) {
+	switch {
+	case x == 0:
+		d.out.WriteByte(0)
+	case x > 0 && x < 123:
+		d.out.WriteByte(byte(x + 5))
+	case x < 0 && x > -124:
+		d.out.WriteByte(byte(x - 5))
+	default:
+		var buf [4]byte
+		for i := 1; i <= 4; i++ {
+			buf[i-1] = byte(x)
+			x >>= 8
+			if x == 0 {
+				d.out.WriteByte(byte(i))
+				d.out.Write(buf[:i])
+				return
+			}
+			if x == -1 {
+				d.out.WriteByte(byte(-i))
+				d.out.Write(buf[:i])
+				return
+			}
+		}
+	}
+}
+
+// marshalFloatString formats f like Ruby does for Marshal
+func marshalFloatString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// marshalLoad restores the object graph serialized by Marshal.dump from
+// the String passed
+func marshalLoad(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	source, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	loader := &marshalLoader{runtime: r, data: source.Value}
+	major, err := loader.readByte()
+	if err != nil {
+		return nil, err
+	}
+	minor, err := loader.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if major != marshalMajor || minor > marshalMinor {
+		return nil, NewTypeError(
+			"incompatible marshal file format (can't be read)\n\tformat version %d.%d required; %d.%d given",
+			marshalMajor, marshalMinor, major, minor,
+		)
+	}
+	return loader.load()
+}
+
+// marshalLoader reads objects in the Marshal format from data, looking up
+// the classes of the objects within runtime
+type marshalLoader struct {
+	runtime *Runtime
+	data    string
+	pos     int
+	symbols []string
+	objects []RubyObject
+}
+
+// register adds obj to the objects links refer to and returns it
+func (l *marshalLoader) register(obj RubyObject) RubyObject {
+	l.objects = append(l.objects, obj)
+	return obj
+}
+
+func (l *marshalLoader) load() (RubyObject, error) {
+	typ, err := l.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case marshalNil:
+		return NIL, nil
+	case marshalTrue:
+		return TRUE, nil
+	case marshalFalse:
+		return FALSE, nil
+	case marshalFixnum:
+		value, err := l.readLong()
+		if err != nil {
+			return nil, err
+		}
+		return NewInteger(value), nil
+	case marshalBignum:
+		return l.loadBignum()
+	case marshalSymbol, marshalSymlink:
+		l.pos--
+		name, err := l.readSymbol()
+		if err != nil {
+			return nil, err
+		}
+		return NewSymbol(name), nil
+	case marshalLink:
+		index, err := l.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= int64(len(l.objects)) {
+			return nil, NewArgumentError("dump format error (unlinked)")
+		}
+		return l.objects[index], nil
+	case marshalFloat:
+		s, err := l.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return l.register(NewFloat(marshalParseFloat(s))), nil
+	case marshalString:
+		s, err := l.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return l.register(&String{Value: s}), nil
+	case marshalIvar:
+		obj, err := l.load()
+		if err != nil {
+			return nil, err
+		}
+		return obj, l.loadIvars(obj)
+	case marshalArray:
+		count, err := l.readLong()
+		if err != nil {
+			return nil, err
+		}
+		array := NewArray()
+		l.register(array)
+		for i := int64(0); i < count; i++ {
+			element, err := l.load()
+			if err != nil {
+				return nil, err
+			}
+			array.Elements = append(array.Elements, element)
+		}
+		return array, nil
+	case marshalHash:
+		count, err := l.readLong()
+		if err != nil {
+			return nil, err
+		}
+		hash := NewHash(nil)
+		l.register(hash)
+		for i := int64(0); i < count; i++ {
+			key, err := l.load()
+			if err != nil {
+				return nil, err
+			}
+			value, err := l.load()
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(key, value)
+		}
+		return hash, nil
+	case marshalObject:
+		return l.loadObject()
+	case marshalUserMarsh:
+		return l.loadUserMarshal()
+	case marshalClass, marshalModuleType:
+		name, err := l.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		module, err := l.lookupClass(name)
+		if err != nil {
+			return nil, err
+		}
+		return l.register(module), nil
+	default:
+		return nil, NewArgumentError("dump format error(0x%x)", typ)
+	}
+}
+
+// loadIvars sets the instance variables following an object wrapped by
+// marshalIvar. The encoding of Strings is ignored, as all Strings are
+// UTF-8.
+func (l *marshalLoader) loadIvars(obj RubyObject) error {
+	count, err := l.readLong()
+	if err != nil {
+		return err
+	}
+	for i := int64(0); i < count; i++ {
+		name, err := l.readSymbol()
+		if err != nil {
+			return err
+		}
+		value, err := l.load()
+		if err != nil {
+			return err
+		}
+		if name == "E" || name == "encoding" {
+			continue
+		}
+		instanceVariablesOf(obj, true).set(name, value)
+	}
+	return nil
+}
+
+func (l *marshalLoader) loadBignum() (RubyObject, error) {
+	sign, err := l.readByte()
+	if err != nil {
+		return nil, err
+	}
+	words, err := l.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if words*2 > 8 {
+		return nil, NewRangeError("bignum too big to convert into `long'")
+	}
+	var magnitude uint64
+	for i := int64(0); i < words*2; i++ {
+		b, err := l.readByte()
+		if err != nil {
+			return nil, err
+		}
+		magnitude |= uint64(b) << (8 * i)
+	}
+	if magnitude > math.MaxInt64 {
+		return nil, NewRangeError("bignum too big to convert into `long'")
+	}
+	value := int64(magnitude)
+	if sign == '-' {
+		value = -value
+	}
+	return l.register(NewInteger(value)), nil
+}
+
+// loadObject allocates an instance of the class named and sets its
+// instance variables
+func (l *marshalLoader) loadObject() (RubyObject, error) {
+	name, err := l.readSymbol()
+	if err != nil {
+		return nil, err
+	}
+	if name == "Range" {
+		return l.loadRange()
+	}
+	class, err := l.lookupClass(name)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := marshalAllocate(class, name)
+	if err != nil {
+		return nil, err
+	}
+	l.register(obj)
+	return obj, l.loadIvars(obj)
+}
+
+// loadRange restores a Range from its instance variables
+func (l *marshalLoader) loadRange() (RubyObject, error) {
+	r := &Range{}
+	l.register(r)
+	count, err := l.readLong()
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < count; i++ {
+		name, err := l.readSymbol()
+		if err != nil {
+			return nil, err
+		}
+		value, err := l.load()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "excl":
+			r.Exclusive = isTruthy(value)
+		case "begin":
+			r.First = value
+		case "end":
+			r.Last = value
+		}
+	}
+	return r, nil
+}
+
+// loadUserMarshal allocates an instance of the class named and passes the
+// data written by its marshal_dump to marshal_load
+func (l *marshalLoader) loadUserMarshal() (RubyObject, error) {
+	name, err := l.readSymbol()
+	if err != nil {
+		return nil, err
+	}
+	class, err := l.lookupClass(name)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := marshalAllocate(class, name)
+	if err != nil {
+		return nil, err
+	}
+	l.register(obj)
+	data, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := findMethod(obj, "marshal_load"); !ok {
+		return nil, NewTypeError("instance of %s needs to have method `marshal_load'", name)
+	}
+	if _, err := Send(obj, "marshal_load", data); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// lookupClass returns the class or module named by the constant path name
+func (l *marshalLoader) lookupClass(name string) (RubyObject, error) {
+	module, err := moduleConstGet(l.runtime, objectClass, &String{Value: name})
+	if err != nil {
+		if IsKindOf(err.(RubyObject), nameErrorClass) {
+			return nil, NewArgumentError("undefined class/module %s", name)
+		}
+		return nil, err
+	}
+	return module, nil
+}
+
+// marshalAllocate returns a new, uninitialized instance of class
+func marshalAllocate(class RubyObject, name string) (RubyObject, error) {
+	classObject, ok := class.(RubyClassObject)
+	if !ok {
+		return nil, NewArgumentError("%s does not refer to class", name)
+	}
+	alloc := allocatorOf(classObject)
+	if alloc == nil {
+		return nil, NewTypeError("allocator undefined for %s", name)
+	}
+	allocatedInstances.add()
+	return alloc(classObject), nil
+}
+
+func (l *marshalLoader) readByte() (byte, error) {
+	if l.pos >= len(l.data) {
+		return 0, NewArgumentError("marshal data too short")
+	}
+	b := l.data[l.pos]
+	l.pos++
+	return b, nil
+}
+
+func (l *marshalLoader) readBytes() (string, error) {
+	n, err := l.readLong()
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || int64(l.pos)+n > int64(len(l.data)) {
+		return "", NewArgumentError("marshal data too short")
+	}
+	s := l.data[l.pos : l.pos+int(n)]
+	l.pos += int(n)
+	return s, nil
+}
+
+func (l *marshalLoader) readSymbol() (string, error) {
+	typ, err := l.readByte()
+	if err != nil {
+		return "", err
+	}
+	switch typ {
+	case marshalSymbol:
+		name, err := l.readBytes()
+		if err != nil {
+			return "", err
+		}
+		l.symbols = append(l.symbols, name)
+		return name, nil
+	case marshalSymlink:
+		index, err := l.readLong()
+		if err != nil {
+			return "", err
+		}
+		if index < 0 || index >= int64(len(l.symbols)) {
+			return "", NewArgumentError("bad symbol")
+		}
+		return l.symbols[index], nil
+	case marshalIvar:
+		name, err := l.readSymbol()
+		if err != nil {
+			return "", err
+		}
+		return name, l.loadIvars(NewSymbol(name))
+	default:
+		return "", NewArgumentError("dump format error for symbol(0x%x)", typ)
+	}
+}
+
+// readLong reads an integer in the packed format written by writeLong
+func (l *marshalLoader) readLong() (int64, error) {
+	b, err := l.readByte()
+	if err != nil {
+		return 0, err
+	}
+	c := int64(int8(b))
+	switch {
+	case c == 0:
+		return 0, nil
+	case c > 4:
+		return c - 5, nil
+	case c < -4:
+		return c + 5, nil
+	case c > 0:
+		var x int64
+		for i := int64(0); i < c; i++ {
+			b, err := l.readByte()
+			if err != nil {
+				return 0, err
+			}
+			x |= int64(b) << (8 * i)
+		}
+		return x, nil
+	default:
+		x := int64(-1)
+		for i := int64(0); i < -c; i++ {
+			b, err := l.readByte()
+			if err != nil {
+				return 0, err
+			}
+			x &^= 0xff << (8 * i)
+			x |= int64(b) << (8 * i)
+		}
+		return x, nil
+	}
+}
+
+// marshalParseFloat parses a Float written by Ruby or marshalFloatString
+func marshalParseFloat(s string) float64 {
+	switch s {
+	case "nan":
+		return math.NaN()
+	case "inf":
+		return math.Inf(1)
+	case "-inf":
+		return math.Inf(-1)
+	}
+	// Ruby may append the mantissa bits after a null byte
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,90 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestMarshalDump(t *testing.T) {
+	tests := []struct {
+		obj      RubyObject
+		expected string
+	}{
+		{NIL, "\x04\x080"},
+		{NewInteger(-129), "\x04\x08i\xff\x7f"},
+		{&String{Value: "hi"}, "\x04\x08I\"\x07hi\x06:\x06ET"},
+		{NewArray(NewInteger(1), NewInteger(1<<31), NewFloat(1)), "\x04\x08[\x08i\x06l+\x07\x00\x00\x00\x80f\x061"},
+		{NewArray(NewSymbol("a"), NewSymbol("a")), "\x04\x08[\x07:\x06a;\x00"},
+	}
+
+	for _, tt := range tests {
+		result, err := marshalDump(marshalModule, tt.obj)
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: tt.expected})
+	}
+
+	_, err := marshalDump(marshalModule, newNativeProc(nil))
+	checkError(t, err, NewTypeError("no _dump_data is defined for class Proc"))
+}
+
+func TestMarshalLoad(t *testing.T) {
+	class := newSubclass("MarshalTestNode", objectClass)
+	SetConstant("MarshalTestNode", class)
+	root, child := &Object{class: class}, &Object{class: class}
+	InstanceVariableSet(root, "@children", NewArray(child))
+	InstanceVariableSet(child, "@parent", root)
+	InstanceVariableSet(child, "@range", &Range{First: NewInteger(1), Last: NewInteger(3), Exclusive: true})
+
+	t.Run("round trip", func(t *testing.T) {
+		values := []RubyObject{
+			NewInteger(0), NewInteger(122), NewInteger(-123), NewInteger(1 << 40), NewInteger(-1 << 40),
+			NewFloat(-2.5), &String{Value: "é"}, NewSymbol("sym"), TRUE, class,
+		}
+		for _, value := range values {
+			dumped, err := marshalDump(marshalModule, value)
+			checkError(t, err, nil)
+			loaded, err := marshalLoad(nil, marshalModule, dumped)
+			checkError(t, err, nil)
+
+			if loaded.Inspect() != value.Inspect() {
+				t.Logf("Expected %s to be restored, got %s", value.Inspect(), loaded.Inspect())
+				t.Fail()
+			}
+		}
+	})
+	t.Run("object graph with cycle", func(t *testing.T) {
+		dumped, err := marshalDump(marshalModule, root)
+		checkError(t, err, nil)
+		loaded, err := marshalLoad(nil, marshalModule, dumped)
+		checkError(t, err, nil)
+
+		children, _ := InstanceVariableGet(loaded, "@children")
+		loadedChild := children.(*Array).Elements[0]
+		parent, _ := InstanceVariableGet(loadedChild, "@parent")
+		if parent != loaded {
+			t.Logf("Expected the parent of the child to be the restored root, got %s", parent.Inspect())
+			t.Fail()
+		}
+		r, _ := InstanceVariableGet(loadedChild, "@range")
+		if r.Inspect() != "1...3" {
+			t.Logf("Expected the range to be restored, got %s", r.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("invalid data", func(t *testing.T) {
+		tests := []struct {
+			data string
+			err  error
+		}{
+			{"\x04", NewArgumentError("marshal data too short")},
+			{"\x04\x08[\x07i\x06", NewArgumentError("marshal data too short")},
+			{"\x04\x08o:\x0cUnknown\x00", NewArgumentError("undefined class/module Unknown")},
+			{"\x03\x00", NewTypeError("incompatible marshal file format (can't be read)\n\tformat version 4.8 required; 3.0 given")},
+		}
+		for _, tt := range tests {
+			_, err := marshalLoad(nil, marshalModule, &String{Value: tt.data})
+
+			checkError(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package object
+
+import "fmt"
+
+var methodClass RubyClassObject = newClass("Method", objectClass, methodMethods, nil)
+
+func init() {
+	classes.Set("Method", methodClass)
+}
+
+// A Method represents a method bound to the receiver it was retrieved from
+type Method struct {
+	Name string
+	// Owner is the class or module defining the method
+	Owner    RubyObject
+	Receiver RubyObject
+	Method   RubyMethod
+}
+
+// Type returns METHOD_OBJ
+func (m *Method) Type() Type { return METHOD_OBJ }
+
+// Inspect returns the name of the method qualified by the class of the
+// receiver and its owner, or by the receiver for singleton methods
+func (m *Method) Inspect() string {
+	if _, ok := m.Owner.(*eigenclass); ok {
+		return fmt.Sprintf("#<Method: %s.%s>", m.Receiver.Inspect(), m.Name)
+	}
+	owner := m.Owner.Inspect()
+	if class := realClass(m.Receiver).(RubyObject); class != m.Owner {
+		owner = fmt.Sprintf("%s(%s)", class.Inspect(), owner)
+	}
+	return fmt.Sprintf("#<Method: %s#%s>", owner, m.Name)
+}
+
+// Class returns methodClass
+func (m *Method) Class() RubyClass { return methodClass }
+
+var methodMethods = map[string]RubyMethod{
+	"name":       withArity(0, publicMethod(methodName)),
+	"owner":      withArity(0, publicMethod(methodOwner)),
+	"receiver":   withArity(0, publicMethod(methodReceiver)),
+	"arity":      withArity(0, publicMethod(methodArity)),
+	"parameters": withArity(0, publicMethod(methodParameters)),
+	"call":       publicRuntimeMethod(methodCall),
+	"unbind":     withArity(0, publicMethod(methodUnbind)),
+	"to_proc":    withArity(0, publicRuntimeMethod(methodToProc)),
+	"inspect":    withArity(0, publicMethod(methodInspect)),
+	"to_s":       withArity(0, publicMethod(methodInspect)),
+}
+
+// kernelMethod returns the method named by the argument as Method bound to
+// the receiver. It raises a NameError if the receiver does not respond to
+// it.
+func kernelMethod(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	method, owner, ok := r.lookupInstanceMethod(context.Class().(RubyObject), name)
+	if !ok {
+		return nil, NewUndefinedMethodError(name, realClass(context).(RubyObject))
+	}
+	return &Method{Name: name, Owner: owner, Receiver: context, Method: method}, nil
+}
+
+func methodName(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewSymbol(context.(*Method).Name), nil
+}
+
+func methodOwner(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Method).Owner, nil
+}
+
+func methodReceiver(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Method).Receiver, nil
+}
+
+func methodArity(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	params := parametersOf(context.(*Method).Method)
+	return NewInteger(int64(arityOf(params))), nil
+}
+
+func methodParameters(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return parametersToArray(parametersOf(context.(*Method).Method)), nil
+}
+
+// methodCall calls the method on its receiver regardless of its visibility
+func methodCall(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method := context.(*Method)
+	return r.Call(method.Method, method.Receiver, args...)
+}
+
+func methodUnbind(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method := context.(*Method)
+	return &UnboundMethod{
+		Name:     method.Name,
+		Owner:    method.Owner,
+		Receiver: realClass(method.Receiver).(RubyObject),
+		Method:   method.Method,
+	}, nil
+}
+
+// methodToProc returns a lambda calling the method
+func methodToProc(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method := context.(*Method)
+	proc := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+		return r.Call(method.Method, method.Receiver, args...)
+	})
+	proc.Lambda = true
+	return proc, nil
+}
+
+func methodInspect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*Method).Inspect()}, nil
+}
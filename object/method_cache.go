@@ -0,0 +1,76 @@
+package object
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// methodCacheKey identifies a method lookup by the class it starts at and
+// the method name
+type methodCacheKey struct {
+	class RubyClass
+	name  string
+}
+
+// methodCache holds the results of method lookups, including failed ones.
+// Every Runtime has its own, as the methods it defines within the builtin
+// classes are its own. All of them are flushed completely whenever a
+// method gets defined or a class gets a new superclass, as both may change
+// the result of any lookup.
+type methodCache struct {
+	sync.Mutex
+	generation uint64
+	entries    map[methodCacheKey]RubyMethod
+}
+
+// methodCacheGeneration is incremented to flush all method caches
+var methodCacheGeneration uint64
+
+// sharedMethodCache holds the lookups made outside of any Runtime
+var sharedMethodCache methodCache
+
+// methodTables guards the method tables of all classes and the methods
+// runtimes define within the builtin ones
+var methodTables sync.RWMutex
+
+// methodCache returns the cache of the lookups made within r
+func (r *Runtime) methodCache() *methodCache {
+	if r == nil {
+		return &sharedMethodCache
+	}
+	return &r.cache
+}
+
+// cachedMethod returns the method found for name starting at class and
+// whether the lookup has been cached at all. A cached nil method denotes a
+// failed lookup.
+func (c *methodCache) cachedMethod(class RubyClass, name string) (RubyMethod, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if generation := atomic.LoadUint64(&methodCacheGeneration); c.generation != generation {
+		c.generation = generation
+		c.entries = nil
+	}
+	method, ok := c.entries[methodCacheKey{class, name}]
+	return method, ok
+}
+
+// cacheMethod stores the result of a lookup of name starting at class,
+// unless the cache has been flushed since generation, which the lookup
+// started with
+func (c *methodCache) cacheMethod(generation uint64, class RubyClass, name string, method RubyMethod) {
+	c.Lock()
+	defer c.Unlock()
+	if c.generation != generation || atomic.LoadUint64(&methodCacheGeneration) != generation {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[methodCacheKey]RubyMethod)
+	}
+	c.entries[methodCacheKey{class, name}] = method
+}
+
+// invalidateMethodCache drops all cached lookups
+func invalidateMethodCache() {
+	atomic.AddUint64(&methodCacheGeneration, 1)
+}
@@ -0,0 +1,66 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestFindMethodCache(t *testing.T) {
+	superClass := newClass("CacheSuper", objectClass, nil, nil)
+	subClass := newClass("CacheSub", superClass, nil, nil)
+	object := &Object{class: subClass}
+
+	if _, ok := findMethod(object, "cached"); ok {
+		t.Logf("Expected method not to be found")
+		t.Fail()
+	}
+
+	first := publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NewInteger(1), nil
+	})
+	superClass.addMethod("cached", first)
+
+	t.Run("definition flushes failed lookups", func(t *testing.T) {
+		result, err := Send(object, "cached")
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(1))
+	})
+
+	t.Run("definition within subclass takes precedence", func(t *testing.T) {
+		subClass.addMethod("cached", publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+			return NewInteger(2), nil
+		}))
+
+		result, err := Send(object, "cached")
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(2))
+	})
+
+	t.Run("singleton method takes precedence", func(t *testing.T) {
+		object.singletonClass().addMethod("cached", publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+			return NewInteger(3), nil
+		}))
+
+		result, err := Send(object, "cached")
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(3))
+
+		result, err = Send(&Object{class: subClass}, "cached")
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(2))
+	})
+
+	t.Run("new superclass flushes lookups", func(t *testing.T) {
+		other := newClass("CacheOther", objectClass, nil, nil)
+		instance := &Object{class: other}
+		if _, ok := findMethod(instance, "cached"); ok {
+			t.Logf("Expected method not to be found")
+			t.Fail()
+		}
+
+		other.inherit(superClass)
+
+		result, err := Send(instance, "cached")
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(1))
+	})
+}
@@ -22,16 +22,55 @@ type RubyMethod interface {
 	Visibility() MethodVisibility
 }
 
+// A runtimeFunction is a builtin method needing the Runtime it is called
+// within, e.g. to look up constants, methods or the current Thread
+type runtimeFunction func(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error)
+
+// runtimeMethod is implemented by methods needing the Runtime they are
+// called within. Their Call method calls them outside of any Runtime.
+type runtimeMethod interface {
+	callWithin(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error)
+}
+
+// withArity returns fn checking that it is called with arity arguments,
+// returning an ArgumentError otherwise. A block passed as last argument is
+// not counted.
 func withArity(arity int, fn RubyMethod) RubyMethod {
-	return &method{
-		fn: func(context RubyObject, args ...RubyObject) (RubyObject, error) {
-			if len(args) != arity {
-				return nil, NewWrongNumberOfArgumentsError(arity, len(args))
-			}
-			return fn.Call(context, args...)
-		},
-		visibility: fn.Visibility(),
+	m := arityChecked(fn)
+	m.arity = argumentCount{checked: true, min: arity, max: arity}
+	m.params = requiredParameters(arity)
+	return m
+}
+
+// withArityRange works like withArity but accepts any number of arguments
+// between min and max. A negative max allows an unlimited number of
+// arguments.
+func withArityRange(min, max int, fn RubyMethod) RubyMethod {
+	params := requiredParameters(min)
+	if max < 0 {
+		params = append(params, parameter{kind: "rest"})
+	}
+	for i := min; i < max; i++ {
+		params = append(params, parameter{kind: "opt"})
 	}
+	m := arityChecked(fn)
+	m.arity = argumentCount{checked: true, ranged: true, min: min, max: max}
+	m.params = params
+	return m
+}
+
+// arityChecked returns a copy of fn an arity can be set on. The arity is
+// checked by the method itself on every call, so that builtin methods are
+// called without any wrapper in between.
+func arityChecked(fn RubyMethod) *method {
+	if m, ok := fn.(*method); ok && !m.arity.checked {
+		copied := *m
+		return &copied
+	}
+	if m, ok := fn.(runtimeMethod); ok {
+		return &method{visibility: fn.Visibility(), within: m.callWithin}
+	}
+	return &method{visibility: fn.Visibility(), fn: fn.Call}
 }
 
 func publicMethod(fn func(context RubyObject, args ...RubyObject) (RubyObject, error)) RubyMethod {
@@ -46,6 +85,14 @@ func privateMethod(fn func(context RubyObject, args ...RubyObject) (RubyObject,
 	return &method{visibility: PRIVATE_METHOD, fn: fn}
 }
 
+func publicRuntimeMethod(fn runtimeFunction) RubyMethod {
+	return &method{visibility: PUBLIC_METHOD, within: fn}
+}
+
+func privateRuntimeMethod(fn runtimeFunction) RubyMethod {
+	return &method{visibility: PRIVATE_METHOD, within: fn}
+}
+
 type publicMethodX func(context RubyObject, args ...RubyObject) RubyObject
 
 func (m publicMethodX) Call(context RubyObject, args ...RubyObject) RubyObject {
@@ -56,12 +103,133 @@ func (m publicMethodX) Visibility() MethodVisibility { return PUBLIC_METHOD }
 type method struct {
 	visibility MethodVisibility
 	fn         func(context RubyObject, args ...RubyObject) (RubyObject, error)
+	// within is set instead of fn for methods needing the Runtime
+	within runtimeFunction
+	params []parameter
+	arity  argumentCount
+}
+
+// argumentCount is the number of arguments a builtin method accepts
+type argumentCount struct {
+	checked bool
+	// ranged selects the error message of methods declared by
+	// withArityRange
+	ranged   bool
+	min, max int
+}
+
+// check returns an ArgumentError if the number of args, not counting a
+// block, is out of range
+func (a argumentCount) check(args []RubyObject) error {
+	n := len(args)
+	if n > 0 {
+		if _, ok := args[n-1].(*Proc); ok {
+			n--
+		}
+	}
+	if n >= a.min && (a.max < 0 || n <= a.max) {
+		return nil
+	}
+	if a.ranged {
+		return NewWrongNumberOfArgumentsRangeError(a.min, a.max, n)
+	}
+	return NewWrongNumberOfArgumentsError(a.min, n)
 }
 
 func (m *method) Call(context RubyObject, args ...RubyObject) (RubyObject, error) {
-	return m.fn(context, args...)
+	return m.callWithin(nil, context, args...)
+}
+
+func (m *method) callWithin(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if m.arity.checked {
+		if err := m.arity.check(args); err != nil {
+			return nil, err
+		}
+	}
+	if m.within != nil {
+		return m.within(r, unwrapSelf(context), args...)
+	}
+	return m.fn(unwrapSelf(context), args...)
 }
 func (m *method) Visibility() MethodVisibility { return m.visibility }
+func (m *method) parameters() []parameter      { return m.params }
+
+// parameter describes a single parameter of a method or proc. Parameters of
+// builtin methods have no name.
+type parameter struct {
+	// kind is one of req, opt, rest, key, keyreq or block
+	kind string
+	name string
+}
+
+// signature is implemented by methods knowing the parameters they accept
+type signature interface {
+	parameters() []parameter
+}
+
+// parametersOf returns the parameters of fn. Methods not declaring them are
+// assumed to accept any number of arguments.
+func parametersOf(fn RubyMethod) []parameter {
+	if s, ok := fn.(signature); ok {
+		if params := s.parameters(); params != nil {
+			return params
+		}
+	}
+	return []parameter{{kind: "rest"}}
+}
+
+// requiredParameters returns n unnamed required parameters
+func requiredParameters(n int) []parameter {
+	params := make([]parameter, n)
+	for i := range params {
+		params[i].kind = "req"
+	}
+	return params
+}
+
+// arityOf returns the arity of a method with the given parameters, which
+// is the number of required arguments, or -n-1 if there are n required
+// arguments and optional ones as well. Like in Ruby keyword arguments count
+// as a single argument, which is required if any keyword is.
+func arityOf(params []parameter) int {
+	required := 0
+	optional, keyword, requiredKeyword := false, false, false
+	for _, param := range params {
+		switch param.kind {
+		case "req":
+			required++
+		case "opt", "rest":
+			optional = true
+		case "key":
+			keyword = true
+		case "keyreq":
+			requiredKeyword = true
+		}
+	}
+	if requiredKeyword {
+		required++
+	} else if keyword {
+		optional = true
+	}
+	if optional {
+		return -required - 1
+	}
+	return required
+}
+
+// parametersToArray returns params the way `parameters` reports them, as
+// an Array of pairs of kind and name
+func parametersToArray(params []parameter) *Array {
+	result := NewArray()
+	for _, param := range params {
+		pair := NewArray(NewSymbol(param.kind))
+		if param.name != "" {
+			pair.Elements = append(pair.Elements, NewSymbol(param.name))
+		}
+		result.Elements = append(result.Elements, pair)
+	}
+	return result
+}
 
 func mixin(class RubyClassObject, modules ...*Module) RubyClassObject {
 	return &methodSet{class, modules}
@@ -72,6 +240,10 @@ type methodSet struct {
 	modules []*Module
 }
 
+func (m *methodSet) addMethod(name string, method RubyMethod) {
+	m.RubyClassObject.(methodDefiner).addMethod(name, method)
+}
+
 func (m *methodSet) Methods() map[string]RubyMethod {
 	var methods = make(map[string]RubyMethod)
 	for _, mod := range m.modules {
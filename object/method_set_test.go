@@ -45,3 +45,98 @@ func TestWithArity(t *testing.T) {
 		checkError(t, err, testCase.err)
 	}
 }
+
+func TestWithArityRange(t *testing.T) {
+	wrappedMethod := publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NewInteger(1), nil
+	})
+
+	tests := []struct {
+		min, max  int
+		arguments []RubyObject
+		result    RubyObject
+		err       error
+	}{
+		{1, 2, []RubyObject{NIL}, NewInteger(1), nil},
+		{1, 2, []RubyObject{NIL, NIL}, NewInteger(1), nil},
+		{1, 2, []RubyObject{}, nil, NewWrongNumberOfArgumentsRangeError(1, 2, 0)},
+		{1, 2, []RubyObject{NIL, NIL, NIL}, nil, NewWrongNumberOfArgumentsRangeError(1, 2, 3)},
+		{1, -1, []RubyObject{NIL, NIL, NIL}, NewInteger(1), nil},
+		{1, -1, []RubyObject{}, nil, NewWrongNumberOfArgumentsRangeError(1, -1, 0)},
+	}
+
+	for _, testCase := range tests {
+		fn := withArityRange(testCase.min, testCase.max, wrappedMethod)
+
+		result, err := fn.Call(NIL, testCase.arguments...)
+
+		checkResult(t, result, testCase.result)
+
+		checkError(t, err, testCase.err)
+	}
+
+	err := NewWrongNumberOfArgumentsRangeError(1, -1, 0)
+	if err.Message != "wrong number of arguments (given 0, expected 1+)" {
+		t.Logf("Unexpected error message: %q", err.Message)
+		t.Fail()
+	}
+}
+
+func TestArityOf(t *testing.T) {
+	fn := publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NIL, nil
+	})
+
+	tests := []struct {
+		method     RubyMethod
+		arity      int
+		parameters string
+	}{
+		{fn, -1, "[[:rest]]"},
+		{withArity(0, fn), 0, "[]"},
+		{withArity(2, fn), 2, "[[:req], [:req]]"},
+		{withArityRange(0, 1, fn), -1, "[[:opt]]"},
+		{withArityRange(1, 3, fn), -2, "[[:req], [:opt], [:opt]]"},
+		{withArityRange(1, -1, fn), -2, "[[:req], [:rest]]"},
+		{withVisibility(withArity(1, fn), PRIVATE_METHOD), 1, "[[:req]]"},
+	}
+
+	for _, tt := range tests {
+		params := parametersOf(tt.method)
+		if arity := arityOf(params); arity != tt.arity {
+			t.Logf("Expected arity %d, got %d", tt.arity, arity)
+			t.Fail()
+		}
+		if result := parametersToArray(params).Inspect(); result != tt.parameters {
+			t.Logf("Expected parameters %s, got %s", tt.parameters, result)
+			t.Fail()
+		}
+	}
+
+	keywordTests := []struct {
+		params []parameter
+		arity  int
+	}{
+		{[]parameter{{kind: "req"}, {kind: "key"}}, -2},
+		{[]parameter{{kind: "req"}, {kind: "keyreq"}, {kind: "key"}}, 2},
+		{[]parameter{{kind: "req"}, {kind: "block"}}, 1},
+	}
+
+	for _, tt := range keywordTests {
+		if arity := arityOf(tt.params); arity != tt.arity {
+			t.Logf("Expected arity %d, got %d", tt.arity, arity)
+			t.Fail()
+		}
+	}
+
+	params := []parameter{{"req", "a"}, {"opt", "b"}, {"rest", "c"}, {"key", "d"}, {"block", "e"}}
+	expected := "[[:req, :a], [:opt, :b], [:rest, :c], [:key, :d], [:block, :e]]"
+	if result := parametersToArray(params).Inspect(); result != expected {
+		t.Logf("Expected parameters %s, got %s", expected, result)
+		t.Fail()
+	}
+	if arity := arityOf(params); arity != -2 {
+		t.Logf("Expected arity -2, got %d", arity)
+		t.Fail()
+	}
+}
@@ -0,0 +1,146 @@
+package object
+
+import "sync"
+
+// methodOverlay holds the methods a Runtime defines within the builtin
+// classes and modules, by the class or singleton class keeping their
+// methods. Reopening a builtin class within one Runtime thus leaves it
+// unchanged within all others.
+type methodOverlay struct {
+	tables map[RubyClass]map[string]RubyMethod
+}
+
+// builtinTables holds the classes and singleton classes keeping the
+// methods of the builtin classes and modules, guarded by methodTables. It
+// is filled once the first Runtime is created, after the package has been
+// initialized, and extended by the classes extensions define.
+var builtinTables = make(map[RubyClass]bool)
+
+var collectBuiltins sync.Once
+
+// isBuiltinTable reports whether table keeps the methods of a builtin
+// class or module, which are shared by all runtimes
+func isBuiltinTable(table RubyClass) bool {
+	return builtinTables[table]
+}
+
+// collectBuiltinTables marks the method tables of all classes and modules
+// defined outside of any Runtime as builtin
+func collectBuiltinTables() {
+	collectBuiltins.Do(func() {
+		var modules []RubyObject
+		for _, name := range classes.(*environment).names() {
+			module, _ := classes.Get(name)
+			modules = append(modules, module)
+		}
+		for _, name := range kernelFunctions.names() {
+			module, _ := kernelFunctions.Get(name)
+			modules = append(modules, module)
+		}
+		sharedConstants.Lock()
+		for module, table := range sharedConstants.tables {
+			modules = append(modules, module)
+			for _, value := range table.values {
+				modules = append(modules, value)
+			}
+		}
+		sharedConstants.Unlock()
+		markBuiltinTables(modules...)
+	})
+}
+
+// markBuiltinTables marks the tables keeping the instance and singleton
+// methods of modules, and the ones of the modules mixed into them, as
+// builtin
+func markBuiltinTables(modules ...RubyObject) {
+	methodTables.Lock()
+	defer methodTables.Unlock()
+	for _, module := range modules {
+		markBuiltinTable(module)
+	}
+}
+
+func markBuiltinTable(module RubyObject) {
+	switch module := module.(type) {
+	case *Module:
+		if eigen, ok := module.class.(*eigenclass); ok {
+			builtinTables[eigen] = true
+		}
+	case *methodSet:
+		markBuiltinTable(module.RubyClassObject)
+		for _, mixedIn := range module.modules {
+			markBuiltinTable(mixedIn)
+		}
+	case *class:
+		builtinTables[module] = true
+		if eigen, ok := module.Class().(*eigenclass); ok {
+			builtinTables[eigen] = true
+		}
+	}
+}
+
+// methodTableOf returns the class or singleton class keeping the methods
+// defined within module, which is a class, module or methodDefiner
+func methodTableOf(module interface{}) RubyClass {
+	switch module := module.(type) {
+	case *Module:
+		return module.class
+	case *methodSet:
+		return module.RubyClassObject
+	case *extendedObject:
+		return module.class
+	case RubyClass:
+		return module
+	default:
+		return nil
+	}
+}
+
+// overlaid returns the methods r defines within table, or nil if there are
+// none
+func (r *Runtime) overlaid(table RubyClass) map[string]RubyMethod {
+	if r == nil {
+		return nil
+	}
+	return r.methods.tables[table]
+}
+
+// defineMethod adds method to definer. Methods defined within builtin
+// classes and modules are kept by r, others are added to definer itself.
+func (r *Runtime) defineMethod(definer methodDefiner, name string, method RubyMethod) {
+	table := methodTableOf(definer)
+	methodTables.RLock()
+	builtin := isBuiltinTable(table)
+	methodTables.RUnlock()
+	if r == nil || !builtin {
+		definer.addMethod(name, method)
+		return
+	}
+	methodTables.Lock()
+	if r.methods.tables[table] == nil {
+		r.methods.tables[table] = make(map[string]RubyMethod)
+	}
+	r.methods.tables[table][name] = method
+	methodTables.Unlock()
+	invalidateMethodCache()
+}
+
+// ownMethods returns the methods defined within module itself as seen
+// within r, excluding the ones of mixed in modules
+func (r *Runtime) ownMethods(module RubyObject) map[string]RubyMethod {
+	methodTables.RLock()
+	defer methodTables.RUnlock()
+	methods := ownMethods(module)
+	overlay := r.overlaid(methodTableOf(module))
+	if len(overlay) == 0 {
+		return methods
+	}
+	merged := make(map[string]RubyMethod, len(methods)+len(overlay))
+	for name, method := range methods {
+		merged[name] = method
+	}
+	for name, method := range overlay {
+		merged[name] = method
+	}
+	return merged
+}
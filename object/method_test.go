@@ -0,0 +1,89 @@
+package object
+
+import "testing"
+
+func TestKernelMethod(t *testing.T) {
+	tests := []struct {
+		receiver RubyObject
+		name     string
+		inspect  string
+		owner    RubyObject
+	}{
+		{&String{Value: "abc"}, "upcase", "#<Method: String#upcase>", stringClass},
+		{&String{Value: "abc"}, "between?", "#<Method: String(Comparable)#between?>", comparableModule},
+		{NewInteger(1), "puts", "#<Method: Integer(Kernel)#puts>", kernelModule},
+	}
+
+	for _, tt := range tests {
+		result, err := kernelMethod(nil, tt.receiver, &Symbol{tt.name})
+		checkError(t, err, nil)
+		if result.Inspect() != tt.inspect {
+			t.Logf("Expected method to equal %s, got %s", tt.inspect, result.Inspect())
+			t.Fail()
+		}
+		method := result.(*Method)
+		if method.Owner != tt.owner {
+			t.Logf("Expected owner to be %s, got %s", tt.owner.Inspect(), method.Owner.Inspect())
+			t.Fail()
+		}
+		if method.Receiver != tt.receiver {
+			t.Logf("Expected receiver to be %s, got %s", tt.receiver.Inspect(), method.Receiver.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := kernelMethod(nil, NewInteger(1), &Symbol{"unknown"})
+	checkError(t, err, NewUndefinedMethodError("unknown", integerClass))
+}
+
+func TestMethodCall(t *testing.T) {
+	method, err := kernelMethod(nil, &String{Value: "abc"}, &Symbol{"upcase"})
+	checkError(t, err, nil)
+
+	result, err := methodCall(nil, method)
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "ABC"})
+
+	proc, err := methodToProc(nil, method)
+	checkError(t, err, nil)
+	result, err = proc.(*Proc).Call()
+	checkError(t, err, nil)
+	checkResult(t, result, &String{Value: "ABC"})
+
+	unbound, err := methodUnbind(method)
+	checkError(t, err, nil)
+	if unbound.Inspect() != "#<UnboundMethod: String#upcase>" {
+		t.Logf("Expected unbound method String#upcase, got %s", unbound.Inspect())
+		t.Fail()
+	}
+}
+
+func TestMethodArity(t *testing.T) {
+	tests := []struct {
+		receiver   RubyObject
+		name       string
+		arity      RubyObject
+		parameters string
+	}{
+		{&String{Value: "abc"}, "upcase", NewInteger(0), "[]"},
+		{&String{Value: "abc"}, "center", NewInteger(-2), "[[:req], [:opt]]"},
+		{NewArray(), "push", NewInteger(-1), "[[:rest]]"},
+		{NewInteger(1), "+", NewInteger(1), "[[:req]]"},
+	}
+
+	for _, tt := range tests {
+		method, err := kernelMethod(nil, tt.receiver, &Symbol{tt.name})
+		checkError(t, err, nil)
+
+		arity, err := methodArity(method)
+		checkError(t, err, nil)
+		checkResult(t, arity, tt.arity)
+
+		parameters, err := methodParameters(method)
+		checkError(t, err, nil)
+		if parameters.Inspect() != tt.parameters {
+			t.Logf("Expected parameters %s, got %s", tt.parameters, parameters.Inspect())
+			t.Fail()
+		}
+	}
+}
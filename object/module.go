@@ -1,9 +1,24 @@
 package object
 
+import (
+	"fmt"
+	"sort"
+)
+
 var moduleClass RubyClassObject = &class{name: "Module", instanceMethods: moduleMethods}
 
 func init() {
 	moduleClass.(*class).superClass = objectClass
+	// registered here as they refer to objectClass, which depends on
+	// moduleClass
+	moduleMethods["const_get"] = withArityRange(1, 2, publicRuntimeMethod(moduleConstGet))
+	moduleMethods["const_set"] = withArity(2, publicRuntimeMethod(moduleConstSet))
+	moduleMethods["const_defined?"] = withArityRange(1, 2, publicRuntimeMethod(moduleIsConstDefined))
+	moduleMethods["constants"] = withArityRange(0, 1, publicRuntimeMethod(moduleConstantNames))
+	moduleMethods["const_missing"] = withArity(1, publicMethod(moduleConstMissing))
+	setAllocator(moduleClass, func(class RubyClassObject) RubyObject {
+		return &Module{class: newEigenclass(class, nil)}
+	})
 	classes.Set("Module", moduleClass)
 }
 
@@ -18,7 +33,12 @@ type Module struct {
 }
 
 // Inspect returns the name of the module
-func (m *Module) Inspect() string { return m.name }
+func (m *Module) Inspect() string {
+	if m.name != "" {
+		return m.name
+	}
+	return fmt.Sprintf("#<Module:%p>", m)
+}
 
 // Type returns MODULE_OBJ
 func (m *Module) Type() Type { return MODULE_OBJ }
@@ -31,29 +51,215 @@ func (m *Module) Class() RubyClass {
 	return moduleClass
 }
 
+func (m *Module) addMethod(name string, method RubyMethod) {
+	m.class.(methodDefiner).addMethod(name, method)
+}
+
 var moduleMethods = map[string]RubyMethod{
 	"ancestors": withArity(0, publicMethod(moduleAncestors)),
+	"autoload":  withArity(2, publicRuntimeMethod(moduleAutoload)),
+	"autoload?": withArity(1, publicRuntimeMethod(moduleIsAutoload)),
+	"===":       withArity(1, publicMethod(moduleCaseEqual)),
+
+	"class_eval":  withArity(0, publicMethod(moduleClassEval)),
+	"module_eval": withArity(0, publicMethod(moduleClassEval)),
+	"class_exec":  publicMethod(moduleClassExec),
+	"module_exec": publicMethod(moduleClassExec),
+
+	"instance_methods":           withArityRange(0, 1, publicRuntimeMethod(moduleInstanceMethods)),
+	"public_instance_methods":    withArityRange(0, 1, publicRuntimeMethod(modulePublicInstanceMethods)),
+	"protected_instance_methods": withArityRange(0, 1, publicRuntimeMethod(moduleProtectedInstanceMethods)),
+	"private_instance_methods":   withArityRange(0, 1, publicRuntimeMethod(modulePrivateInstanceMethods)),
+	"method_defined?":            withArityRange(1, 2, publicRuntimeMethod(moduleIsMethodDefined)),
+	"instance_method":            withArity(1, publicRuntimeMethod(moduleInstanceMethod)),
+
+	"public":    &visibilityMethod{PUBLIC_METHOD},
+	"protected": &visibilityMethod{PROTECTED_METHOD},
+	"private":   &visibilityMethod{PRIVATE_METHOD},
+
+	"initialize": withArity(0, privateMethod(moduleInitialize)),
 }
 
-func moduleAncestors(context RubyObject, args ...RubyObject) (RubyObject, error) {
-	class := context.(RubyClassObject)
+// nameAnonymousModule gives obj the name if it is a class or module which
+// does not have a name yet, as happens on assigning it to a constant
+func nameAnonymousModule(obj RubyObject, name string) {
+	switch module := obj.(type) {
+	case *Module:
+		if module.name == "" {
+			module.name = name
+		}
+	case *class:
+		if module.name == "" {
+			module.name = name
+		}
+	}
+}
+
+// moduleInitialize evaluates a block passed to Module.new like module_eval
+func moduleInitialize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NIL, nil
+	}
+	return moduleClassEval(unwrapObject(context), block)
+}
+
+// moduleCaseEqual reports whether the argument is an instance of the
+// receiving class or module, so that classes can be used within case
+// expressions
+func moduleCaseEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	ok, err := isA(args[0], context)
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(ok), nil
+}
+
+// ancestorsOf returns the classes and modules which are searched for the
+// methods of instances of module, in method resolution order. Modules mixed
+// into a class follow the class, the last included one first.
+func ancestorsOf(module RubyObject) []RubyObject {
+	if mod, ok := module.(*Module); ok {
+		return []RubyObject{mod}
+	}
 	var ancestors []RubyObject
-	ancestors = append(ancestors, &String{class.Inspect()})
+	class := module.(RubyClass)
+	for class != nil {
+		ancestors = append(ancestors, class.(RubyObject))
+		if mixin, ok := class.(*methodSet); ok {
+			for i := len(mixin.modules) - 1; i >= 0; i-- {
+				ancestors = append(ancestors, mixin.modules[i])
+			}
+		}
+		class = class.SuperClass()
+	}
+	return ancestors
+}
 
-	if mixin, ok := class.(*methodSet); ok {
-		for _, m := range mixin.modules {
-			ancestors = append(ancestors, &String{m.name})
+// ownMethods returns the methods defined within module itself, excluding
+// the ones of mixed in modules
+func ownMethods(module RubyObject) map[string]RubyMethod {
+	switch module := module.(type) {
+	case *Module:
+		if module.class == nil {
+			return nil
 		}
+		return module.class.Methods()
+	case *methodSet:
+		return module.RubyClassObject.Methods()
+	case RubyClass:
+		return module.Methods()
+	default:
+		return nil
 	}
-	superClass := class.SuperClass()
-	if superClass != nil {
-		superAncestors, err := moduleAncestors(superClass.(RubyObject))
-		if err != nil {
-			return nil, err
+}
+
+// lookupInstanceMethod returns the method name instances of module respond
+// to within r, together with the class or module defining it
+func (r *Runtime) lookupInstanceMethod(module RubyObject, name string) (RubyMethod, RubyObject, bool) {
+	for _, ancestor := range ancestorsOf(module) {
+		if method, ok := r.ownMethods(ancestor)[name]; ok {
+			return method, ancestor, true
+		}
+	}
+	return nil, nil, false
+}
+
+func moduleAncestors(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewArray(ancestorsOf(context)...), nil
+}
+
+// instanceMethodNames returns the names of all methods instances of module
+// respond to with a visibility accepted by include. Methods of ancestors are
+// only taken into account if inherit is true. A method overridden with
+// another visibility is reported with the visibility of the override.
+func (r *Runtime) instanceMethodNames(module RubyObject, inherit bool, include func(MethodVisibility) bool) []RubyObject {
+	ancestors := ancestorsOf(module)
+	if !inherit {
+		ancestors = ancestors[:1]
+	}
+	seen := make(map[string]bool)
+	var names []RubyObject
+	for _, ancestor := range ancestors {
+		methods := r.ownMethods(ancestor)
+		sorted := make([]string, 0, len(methods))
+		for name := range methods {
+			sorted = append(sorted, name)
 		}
-		ancestors = append(ancestors, superAncestors.(*Array).Elements...)
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if include(methods[name].Visibility()) {
+				names = append(names, NewSymbol(name))
+			}
+		}
+	}
+	return names
+}
+
+// inheritArgument returns the optional boolean argument of the reflection
+// methods deciding whether ancestors are taken into account. It defaults to
+// true.
+func inheritArgument(args []RubyObject) bool {
+	args, _ = extractBlock(args)
+	return len(args) == 0 || isTruthy(args[0])
+}
+
+func moduleInstanceMethods(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	names := r.instanceMethodNames(context, inheritArgument(args), func(visibility MethodVisibility) bool {
+		return visibility != PRIVATE_METHOD
+	})
+	return NewArray(names...), nil
+}
+
+func modulePublicInstanceMethods(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.instanceMethodsWithVisibility(context, args, PUBLIC_METHOD)
+}
+
+func moduleProtectedInstanceMethods(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.instanceMethodsWithVisibility(context, args, PROTECTED_METHOD)
+}
+
+func modulePrivateInstanceMethods(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.instanceMethodsWithVisibility(context, args, PRIVATE_METHOD)
+}
+
+func (r *Runtime) instanceMethodsWithVisibility(module RubyObject, args []RubyObject, visibility MethodVisibility) (RubyObject, error) {
+	names := r.instanceMethodNames(module, inheritArgument(args), func(v MethodVisibility) bool {
+		return v == visibility
+	})
+	return NewArray(names...), nil
+}
+
+// moduleIsMethodDefined reports whether instances of the receiver respond
+// to a public or protected method with the given name
+func moduleIsMethodDefined(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var method RubyMethod
+	if inheritArgument(args[1:]) {
+		method, _, _ = r.lookupInstanceMethod(context, name)
+	} else {
+		method = r.ownMethods(ancestorsOf(context)[0])[name]
+	}
+	return nativeBoolToBoolean(method != nil && method.Visibility() != PRIVATE_METHOD), nil
+}
+
+func moduleInstanceMethod(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	method, owner, ok := r.lookupInstanceMethod(context, name)
+	if !ok {
+		return nil, NewUndefinedMethodError(name, context)
 	}
-	return &Array{ancestors}, nil
+	return &UnboundMethod{Name: name, Owner: owner, Receiver: context, Method: method}, nil
 }
 
 func moduleIncludedModules(context RubyObject, args ...RubyObject) (RubyObject, error) {
@@ -62,7 +268,7 @@ func moduleIncludedModules(context RubyObject, args ...RubyObject) (RubyObject,
 
 	if mixin, ok := class.(*methodSet); ok {
 		for _, m := range mixin.modules {
-			includedModules = append(includedModules, &String{m.name})
+			includedModules = append(includedModules, &String{Value: m.name})
 		}
 	}
 
@@ -75,5 +281,5 @@ func moduleIncludedModules(context RubyObject, args ...RubyObject) (RubyObject,
 		includedModules = append(includedModules, superIncludedModules.(*Array).Elements...)
 	}
 
-	return &Array{includedModules}, nil
+	return newArrayOf(includedModules), nil
 }
@@ -77,6 +77,10 @@ func TestModuleAncestors(t *testing.T) {
 				objectClass,
 				[]string{"Object", "Kernel", "BasicObject"},
 			},
+			{
+				stringClass,
+				[]string{"String", "Comparable", "Object", "Kernel", "BasicObject"},
+			},
 		}
 
 		for _, testCase := range tests {
@@ -146,3 +150,128 @@ func TestModuleIncludedModules(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestModuleInstanceMethods(t *testing.T) {
+	fn := func(context RubyObject, args ...RubyObject) (RubyObject, error) { return NIL, nil }
+	superClass := &class{name: "Super", superClass: basicObjectClass, instanceMethods: map[string]RubyMethod{
+		"inherited":  publicMethod(fn),
+		"overridden": publicMethod(fn),
+	}}
+	context := &class{name: "Klass", superClass: superClass, instanceMethods: map[string]RubyMethod{
+		"a_public":    publicMethod(fn),
+		"a_protected": protectedMethod(fn),
+		"a_private":   privateMethod(fn),
+		"overridden":  privateMethod(fn),
+	}}
+
+	tests := []struct {
+		method   runtimeFunction
+		args     []RubyObject
+		expected string
+	}{
+		{moduleInstanceMethods, nil, "[:a_protected, :a_public, :inherited, :__id__, :__send__, :equal?]"},
+		{moduleInstanceMethods, []RubyObject{FALSE}, "[:a_protected, :a_public]"},
+		{modulePublicInstanceMethods, []RubyObject{FALSE}, "[:a_public]"},
+		{moduleProtectedInstanceMethods, nil, "[:a_protected]"},
+		{modulePrivateInstanceMethods, []RubyObject{FALSE}, "[:a_private, :overridden]"},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.method(nil, context, tt.args...)
+		checkError(t, err, nil)
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected methods to equal %s, got %s", tt.expected, result.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestModuleIsMethodDefined(t *testing.T) {
+	tests := []struct {
+		module   RubyObject
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{stringClass, []RubyObject{&Symbol{"upcase"}}, TRUE},
+		{stringClass, []RubyObject{&String{Value: "between?"}}, TRUE},
+		{stringClass, []RubyObject{&Symbol{"between?"}, FALSE}, FALSE},
+		{stringClass, []RubyObject{&Symbol{"puts"}}, FALSE},
+		{stringClass, []RubyObject{&Symbol{"unknown"}}, FALSE},
+		{comparableModule, []RubyObject{&Symbol{"between?"}}, TRUE},
+	}
+
+	for _, tt := range tests {
+		result, err := moduleIsMethodDefined(nil, tt.module, tt.args...)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
+
+func TestModuleInstanceMethod(t *testing.T) {
+	tests := []struct {
+		module   RubyObject
+		name     string
+		expected string
+		owner    RubyObject
+	}{
+		{stringClass, "upcase", "#<UnboundMethod: String#upcase>", stringClass},
+		{stringClass, "between?", "#<UnboundMethod: String(Comparable)#between?>", comparableModule},
+		{stringClass, "puts", "#<UnboundMethod: String(Kernel)#puts>", kernelModule},
+	}
+
+	for _, tt := range tests {
+		result, err := moduleInstanceMethod(nil, tt.module, &Symbol{tt.name})
+		checkError(t, err, nil)
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected method to equal %s, got %s", tt.expected, result.Inspect())
+			t.Fail()
+		}
+		if owner := result.(*UnboundMethod).Owner; owner != tt.owner {
+			t.Logf("Expected owner to be %s, got %s", tt.owner.Inspect(), owner.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := moduleInstanceMethod(nil, stringClass, &Symbol{"unknown"})
+	checkError(t, err, NewUndefinedMethodError("unknown", stringClass))
+}
+
+func TestModuleVisibility(t *testing.T) {
+	fn := func(context RubyObject, args ...RubyObject) (RubyObject, error) { return NIL, nil }
+	superClass := &class{name: "Super", superClass: basicObjectClass, instanceMethods: map[string]RubyMethod{
+		"inherited": publicMethod(fn),
+	}}
+	context := &class{name: "Klass", superClass: superClass, instanceMethods: map[string]RubyMethod{
+		"a_public":  publicMethod(fn),
+		"a_private": privateMethod(fn),
+	}}
+
+	tests := []struct {
+		method     string
+		args       []RubyObject
+		name       string
+		visibility MethodVisibility
+	}{
+		{"private", []RubyObject{&Symbol{"a_public"}}, "a_public", PRIVATE_METHOD},
+		{"public", []RubyObject{&String{Value: "a_private"}}, "a_private", PUBLIC_METHOD},
+		{"protected", []RubyObject{NewArray(&Symbol{"inherited"})}, "inherited", PROTECTED_METHOD},
+	}
+
+	for _, tt := range tests {
+		result, err := moduleMethods[tt.method].Call(context, tt.args...)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.args[0])
+		if visibility := context.instanceMethods[tt.name].Visibility(); visibility != tt.visibility {
+			t.Logf("Expected %s to have visibility %d, got %d", tt.name, tt.visibility, visibility)
+			t.Fail()
+		}
+	}
+
+	if visibility := superClass.instanceMethods["inherited"].Visibility(); visibility != PUBLIC_METHOD {
+		t.Logf("Expected inherited method of superclass to stay public, got %d", visibility)
+		t.Fail()
+	}
+
+	_, err := moduleMethods["private"].Call(context, &Symbol{"unknown"})
+	checkError(t, err, NewUndefinedMethodError("unknown", context))
+}
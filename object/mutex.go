@@ -0,0 +1,125 @@
+package object
+
+import "fmt"
+
+var mutexClass RubyClassObject = newClass("Thread::Mutex", objectClass, mutexMethods, mutexClassMethods)
+
+func init() {
+	classes.Set("Mutex", mutexClass)
+	setConstant(threadClass, "Mutex", mutexClass)
+}
+
+// A Mutex represents a Ruby Mutex, a lock held by at most one Thread at a
+// time
+type Mutex struct {
+	owner   *Thread
+	waiters waitQueue
+}
+
+// Type returns MUTEX_OBJ
+func (m *Mutex) Type() Type { return MUTEX_OBJ }
+
+// Inspect returns the object id of the Mutex
+func (m *Mutex) Inspect() string { return fmt.Sprintf("#<Thread::Mutex:0x%016x>", ObjectID(m)) }
+
+// Class returns mutexClass
+func (m *Mutex) Class() RubyClass { return mutexClass }
+
+// lock blocks until the current Thread of r holds m
+func (m *Mutex) lock(r *Runtime) error {
+	thread := r.currentThread()
+	if m.owner == thread {
+		return NewThreadError("deadlock; recursive locking")
+	}
+	for m.owner != nil {
+		if _, err := m.waiters.wait(r, -1); err != nil {
+			return err
+		}
+	}
+	m.owner = thread
+	return nil
+}
+
+// unlock releases m held by the current Thread of r, resuming the next
+// Thread waiting for it
+func (m *Mutex) unlock(r *Runtime) error {
+	switch m.owner {
+	case nil:
+		return NewThreadError("Attempt to unlock a mutex which is not locked")
+	case r.currentThread():
+		m.owner = nil
+		m.waiters.signal()
+		return nil
+	default:
+		return NewThreadError("Attempt to unlock a mutex which is locked by another thread/fiber")
+	}
+}
+
+var mutexClassMethods = map[string]RubyMethod{
+	"new": withArity(0, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return &Mutex{}, nil
+	})),
+}
+
+var mutexMethods = map[string]RubyMethod{
+	"lock":        withArity(0, publicRuntimeMethod(mutexLock)),
+	"unlock":      withArity(0, publicRuntimeMethod(mutexUnlock)),
+	"try_lock":    withArity(0, publicRuntimeMethod(mutexTryLock)),
+	"locked?":     withArity(0, publicMethod(mutexLocked)),
+	"owned?":      withArity(0, publicRuntimeMethod(mutexOwned)),
+	"synchronize": withArity(0, publicRuntimeMethod(mutexSynchronize)),
+}
+
+func mutexLock(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if err := context.(*Mutex).lock(r); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+func mutexUnlock(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if err := context.(*Mutex).unlock(r); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// mutexTryLock locks the Mutex if it is not locked and reports whether it
+// did so
+func mutexTryLock(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	mutex := context.(*Mutex)
+	if mutex.owner != nil {
+		return FALSE, nil
+	}
+	mutex.owner = r.currentThread()
+	return TRUE, nil
+}
+
+func mutexLocked(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Mutex).owner != nil), nil
+}
+
+func mutexOwned(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Mutex).owner == r.currentThread()), nil
+}
+
+// mutexSynchronize calls the block while holding the Mutex and returns its
+// result. The Mutex is released even if the block raises.
+func mutexSynchronize(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	mutex := context.(*Mutex)
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewThreadError("must be called with a block")
+	}
+	if err := mutex.lock(r); err != nil {
+		return nil, err
+	}
+	result, err := block.Call()
+	if unlockErr := mutex.unlock(r); err == nil && unlockErr != nil {
+		return nil, unlockErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestMutex(t *testing.T) {
+	t.Run("synchronize", func(t *testing.T) {
+		mutex := &Mutex{}
+		var locked RubyObject
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			var err error
+			locked, err = Send(mutex, "owned?")
+			return NewInteger(3), err
+		})
+
+		result, err := Send(mutex, "synchronize", block)
+		checkError(t, err, nil)
+		checkResult(t, result, NewInteger(3))
+		checkResult(t, locked, TRUE)
+
+		locked, err = Send(mutex, "locked?")
+		checkError(t, err, nil)
+		checkResult(t, locked, FALSE)
+	})
+	t.Run("synchronize unlocks if the block raises", func(t *testing.T) {
+		mutex := &Mutex{}
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return nil, NewRuntimeError("boom")
+		})
+
+		_, err := Send(mutex, "synchronize", block)
+		checkError(t, err, NewRuntimeError("boom"))
+
+		locked, err := Send(mutex, "locked?")
+		checkError(t, err, nil)
+		checkResult(t, locked, FALSE)
+	})
+	t.Run("recursive locking", func(t *testing.T) {
+		mutex := &Mutex{}
+
+		_, err := Send(mutex, "lock")
+		checkError(t, err, nil)
+
+		_, err = Send(mutex, "lock")
+		checkError(t, err, NewThreadError("deadlock; recursive locking"))
+	})
+	t.Run("unlock by another thread", func(t *testing.T) {
+		mutex := &Mutex{}
+		_, err := Send(mutex, "lock")
+		checkError(t, err, nil)
+
+		thread, err := Send(threadClass, "new", newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return Send(mutex, "unlock")
+		}))
+		checkError(t, err, nil)
+
+		_, err = Send(thread, "join")
+		checkError(t, err, NewThreadError("Attempt to unlock a mutex which is locked by another thread/fiber"))
+	})
+}
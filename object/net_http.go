@@ -0,0 +1,593 @@
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	netModule            = newModule("Net", nil)
+	timeoutModule        = newModule("Timeout", nil)
+	netHTTPClass         = newClass("Net::HTTP", objectClass, netHTTPMethods, netHTTPClassMethods)
+	netHTTPResponseClass = newClass("Net::HTTPResponse", objectClass, netHTTPResponseMethods, nil)
+	// the responses are instances of the class of their status code
+	netHTTPResponseClasses = map[int]RubyClassObject{
+		1: newSubclass("Net::HTTPInformation", netHTTPResponseClass),
+		2: newSubclass("Net::HTTPSuccess", netHTTPResponseClass),
+		3: newSubclass("Net::HTTPRedirection", netHTTPResponseClass),
+		4: newSubclass("Net::HTTPClientError", netHTTPResponseClass),
+		5: newSubclass("Net::HTTPServerError", netHTTPResponseClass),
+	}
+)
+
+func init() {
+	classes.Set("Net", netModule)
+	classes.Set("Timeout", timeoutModule)
+	setConstant(timeoutModule, "Error", timeoutErrorClass)
+	setConstant(netModule, "HTTP", netHTTPClass)
+	setConstant(netModule, "HTTPResponse", netHTTPResponseClass)
+	for _, class := range netHTTPResponseClasses {
+		name := class.Inspect()
+		setConstant(netModule, name[strings.LastIndex(name, ":")+1:], class)
+	}
+	setConstant(netModule, "OpenTimeout", netOpenTimeoutClass)
+	setConstant(netModule, "ReadTimeout", netReadTimeoutClass)
+}
+
+// netHTTPDefaultTimeout is the default open and read timeout, like in Ruby
+const netHTTPDefaultTimeout = 60 * time.Second
+
+// newNetHTTP returns an HTTP client for the server at host and port with
+// the default timeouts
+func newNetHTTP(host string, port int, useSSL bool) *NetHTTP {
+	return &NetHTTP{
+		Host:        host,
+		Port:        port,
+		UseSSL:      useSSL,
+		OpenTimeout: netHTTPDefaultTimeout,
+		ReadTimeout: netHTTPDefaultTimeout,
+	}
+}
+
+// NetHTTP represents a Net::HTTP client sending requests to a single server
+type NetHTTP struct {
+	Host   string
+	Port   int
+	UseSSL bool
+	// OpenTimeout limits connecting to the server, ReadTimeout waiting for
+	// the response
+	OpenTimeout time.Duration
+	ReadTimeout time.Duration
+}
+
+// Type returns OBJECT_OBJ
+func (h *NetHTTP) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the server of the client, like `#<Net::HTTP example.com:80 open=false>`
+func (h *NetHTTP) Inspect() string {
+	return fmt.Sprintf("#<Net::HTTP %s:%d open=false>", h.Host, h.Port)
+}
+
+// Class returns netHTTPClass
+func (h *NetHTTP) Class() RubyClass { return netHTTPClass }
+
+// baseURL returns the URL of the server
+func (h *NetHTTP) baseURL() string {
+	scheme, defaultPort := "http", 80
+	if h.UseSSL {
+		scheme, defaultPort = "https", 443
+	}
+	if h.Port == defaultPort {
+		return scheme + "://" + h.Host
+	}
+	return scheme + "://" + net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+}
+
+// do sends the request and reads the whole response, letting other Threads
+// run meanwhile. Failing connections raise a SocketError, exceeded timeouts
+// a Net::OpenTimeout or Net::ReadTimeout.
+func (h *NetHTTP) do(r *Runtime, request *http.Request) (*NetHTTPResponse, error) {
+	dialer := &net.Dialer{Timeout: h.OpenTimeout}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   h.OpenTimeout,
+			ResponseHeaderTimeout: h.ReadTimeout,
+		},
+		// redirects are left to the program, like in Ruby
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var response *NetHTTPResponse
+	var err error
+	r.withoutInterpreter(func() {
+		var resp *http.Response
+		resp, err = client.Do(request)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		var body []byte
+		ctx, cancel := context.WithTimeout(context.Background(), h.ReadTimeout)
+		defer cancel()
+		go func() {
+			<-ctx.Done()
+			if ctx.Err() == context.DeadlineExceeded {
+				resp.Body.Close()
+			}
+		}()
+		body, err = io.ReadAll(resp.Body)
+		if ctx.Err() == context.DeadlineExceeded {
+			err = context.DeadlineExceeded
+		}
+		response = newNetHTTPResponse(resp, string(body))
+	})
+	if err != nil {
+		return nil, h.requestError(err)
+	}
+	return response, nil
+}
+
+// requestError converts the error of a request into the Ruby exception
+func (h *NetHTTP) requestError(err error) error {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		if opErr.Timeout() {
+			return NewNetOpenTimeoutError(net.JoinHostPort(h.Host, strconv.Itoa(h.Port)))
+		}
+		return NewSocketError("Failed to open TCP connection to %s:%d (%s)", h.Host, h.Port, opErr.Err)
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return NewNetReadTimeoutError()
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return NewSocketError("Failed to open TCP connection to %s:%d (%s)", h.Host, h.Port, dnsErr)
+	}
+	return NewSocketError("%s", err)
+}
+
+// request sends a request with method to the path of the server
+func (h *NetHTTP) request(r *Runtime, method, path string, body RubyObject, headers RubyObject) (*NetHTTPResponse, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	var reader io.Reader
+	if body != nil && body != NIL {
+		str, err := stringArgument(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = strings.NewReader(str.Value)
+	}
+	request, err := http.NewRequest(method, h.baseURL()+path, reader)
+	if err != nil {
+		return nil, NewArgumentError("%s", err)
+	}
+	request.Header.Set("User-Agent", "Ruby")
+	if reader != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if err := setRequestHeaders(request, headers); err != nil {
+		return nil, err
+	}
+	return h.do(r, request)
+}
+
+// setRequestHeaders sets the headers of the Hash passed, if any
+func setRequestHeaders(request *http.Request, headers RubyObject) error {
+	if headers == nil || headers == NIL {
+		return nil
+	}
+	hash, ok := headers.(*Hash)
+	if !ok {
+		return NewImplicitConversionTypeError(&Hash{}, headers)
+	}
+	keys, values := hash.Keys(), hash.Values()
+	for i, key := range keys {
+		request.Header.Set(toS(key), toS(values[i]))
+	}
+	return nil
+}
+
+// netHTTPForURL returns a client for the server of the URL passed as
+// String, and the path of the URL including the query
+func netHTTPForURL(arg RubyObject) (*NetHTTP, string, error) {
+	str, err := stringArgument(arg)
+	if err != nil {
+		return nil, "", err
+	}
+	u, err := url.Parse(str.Value)
+	if err != nil || u.Host == "" || u.Scheme != "http" && u.Scheme != "https" {
+		return nil, "", NewArgumentError("invalid HTTP URL: %s", str.Value)
+	}
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return nil, "", NewArgumentError("invalid port in URL: %s", str.Value)
+		}
+	}
+	return newNetHTTP(u.Hostname(), port, u.Scheme == "https"), u.RequestURI(), nil
+}
+
+var netHTTPClassMethods = map[string]RubyMethod{
+	"new":          withArityRange(1, 2, publicMethod(netHTTPNew)),
+	"start":        withArityRange(1, 3, publicMethod(netHTTPStart)),
+	"get":          withArityRange(1, 2, publicRuntimeMethod(netHTTPGet)),
+	"get_response": withArityRange(1, 2, publicRuntimeMethod(netHTTPGetResponse)),
+	"post":         withArityRange(2, 3, publicRuntimeMethod(netHTTPPost)),
+	"post_form":    withArity(2, publicRuntimeMethod(netHTTPPostForm)),
+}
+
+// netHTTPNew returns a client for the server at host and port, which
+// defaults to 80
+func netHTTPNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	host, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	port := int64(80)
+	if len(args) == 2 && args[1] != NIL {
+		p, err := integerArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		port = p.Value
+	}
+	return newNetHTTP(host.Value, int(port), false), nil
+}
+
+// netHTTPStart returns a client like new, configured by the options
+// use_ssl, open_timeout and read_timeout. Given a block it yields the
+// client and returns the result of the block.
+func netHTTPStart(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	options := NewHash(nil)
+	if len(args) > 1 {
+		if hash, ok := args[len(args)-1].(*Hash); ok {
+			options = hash
+			args = args[:len(args)-1]
+		}
+	}
+	obj, err := netHTTPNew(context, args...)
+	if err != nil {
+		return nil, err
+	}
+	client := obj.(*NetHTTP)
+	if useSSL, ok := options.Get(NewSymbol("use_ssl")); ok {
+		client.UseSSL = isTruthy(useSSL)
+		if client.UseSSL && (len(args) < 2 || args[1] == NIL) {
+			client.Port = 443
+		}
+	}
+	for name, timeout := range map[string]*time.Duration{"open_timeout": &client.OpenTimeout, "read_timeout": &client.ReadTimeout} {
+		if value, ok := options.Get(NewSymbol(name)); ok {
+			if *timeout, err = netHTTPTimeout(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if block == nil {
+		return client, nil
+	}
+	return block.Call(client)
+}
+
+// netHTTPGet returns the body of the response to a GET request for the URL
+func netHTTPGet(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	response, err := netHTTPGetResponse(r, context, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: response.(*NetHTTPResponse).Body}, nil
+}
+
+// netHTTPGetResponse returns the response to a GET request for the URL,
+// sent with the headers of an optional Hash
+func netHTTPGetResponse(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	client, path, err := netHTTPForURL(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var headers RubyObject = NIL
+	if len(args) == 2 {
+		headers = args[1]
+	}
+	return client.request(r, http.MethodGet, path, nil, headers)
+}
+
+// netHTTPPost returns the response to a POST request of the data to the
+// URL, sent with the headers of an optional Hash
+func netHTTPPost(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	client, path, err := netHTTPForURL(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var headers RubyObject = NIL
+	if len(args) == 3 {
+		headers = args[2]
+	}
+	return client.request(r, http.MethodPost, path, args[1], headers)
+}
+
+// netHTTPPostForm returns the response to a POST request of the Hash of
+// params as form data to the URL
+func netHTTPPostForm(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	client, path, err := netHTTPForURL(args[0])
+	if err != nil {
+		return nil, err
+	}
+	params, ok := args[1].(*Hash)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&Hash{}, args[1])
+	}
+	form := url.Values{}
+	keys, values := params.Keys(), params.Values()
+	for i, key := range keys {
+		form.Add(toS(key), toS(values[i]))
+	}
+	return client.request(r, http.MethodPost, path, &String{Value: form.Encode()}, NIL)
+}
+
+// netHTTPTimeout converts the seconds of a timeout into a Duration, where
+// nil means to wait forever
+func netHTTPTimeout(arg RubyObject) (time.Duration, error) {
+	if arg == NIL {
+		return 0, nil
+	}
+	seconds, ok := toFloat(arg)
+	if !ok || seconds < 0 {
+		return 0, NewArgumentError("invalid timeout: %s", arg.Inspect())
+	}
+	return secondsDuration(seconds), nil
+}
+
+var netHTTPMethods = map[string]RubyMethod{
+	"address":       withArity(0, publicMethod(netHTTPAddress)),
+	"port":          withArity(0, publicMethod(netHTTPPort)),
+	"use_ssl?":      withArity(0, publicMethod(netHTTPUseSSL)),
+	"use_ssl=":      withArity(1, publicMethod(netHTTPSetUseSSL)),
+	"open_timeout":  withArity(0, publicMethod(netHTTPGetTimeout(func(h *NetHTTP) *time.Duration { return &h.OpenTimeout }))),
+	"open_timeout=": withArity(1, publicMethod(netHTTPSetTimeout(func(h *NetHTTP) *time.Duration { return &h.OpenTimeout }))),
+	"read_timeout":  withArity(0, publicMethod(netHTTPGetTimeout(func(h *NetHTTP) *time.Duration { return &h.ReadTimeout }))),
+	"read_timeout=": withArity(1, publicMethod(netHTTPSetTimeout(func(h *NetHTTP) *time.Duration { return &h.ReadTimeout }))),
+	"start":         withArity(0, publicMethod(netHTTPStartSession)),
+	"finish":        withArity(0, publicMethod(netHTTPFinish)),
+	"get":           withArityRange(1, 2, publicRuntimeMethod(netHTTPRequest(http.MethodGet, false))),
+	"head":          withArityRange(1, 2, publicRuntimeMethod(netHTTPRequest(http.MethodHead, false))),
+	"delete":        withArityRange(1, 2, publicRuntimeMethod(netHTTPRequest(http.MethodDelete, false))),
+	"post":          withArityRange(2, 3, publicRuntimeMethod(netHTTPRequest(http.MethodPost, true))),
+	"put":           withArityRange(2, 3, publicRuntimeMethod(netHTTPRequest(http.MethodPut, true))),
+	"patch":         withArityRange(2, 3, publicRuntimeMethod(netHTTPRequest(http.MethodPatch, true))),
+}
+
+func netHTTPAddress(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*NetHTTP).Host}, nil
+}
+
+func netHTTPPort(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*NetHTTP).Port)), nil
+}
+
+func netHTTPUseSSL(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*NetHTTP).UseSSL), nil
+}
+
+func netHTTPSetUseSSL(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	context.(*NetHTTP).UseSSL = isTruthy(args[0])
+	return args[0], nil
+}
+
+// netHTTPGetTimeout returns a method returning the timeout field returns
+// in seconds, or nil if it is disabled
+func netHTTPGetTimeout(field func(*NetHTTP) *time.Duration) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		timeout := *field(context.(*NetHTTP))
+		if timeout == 0 {
+			return NIL, nil
+		}
+		if timeout%time.Second == 0 {
+			return NewInteger(int64(timeout / time.Second)), nil
+		}
+		return NewFloat(timeout.Seconds()), nil
+	}
+}
+
+// netHTTPSetTimeout returns a method setting the timeout field returns
+func netHTTPSetTimeout(field func(*NetHTTP) *time.Duration) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		timeout, err := netHTTPTimeout(args[0])
+		if err != nil {
+			return nil, err
+		}
+		*field(context.(*NetHTTP)) = timeout
+		return args[0], nil
+	}
+}
+
+// netHTTPStartSession yields the client to a given block. Connections are
+// not kept open between requests, so that finishing does nothing.
+func netHTTPStartSession(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return context, nil
+	}
+	return block.Call(context)
+}
+
+func netHTTPFinish(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NIL, nil
+}
+
+// netHTTPRequest returns a method sending a request with method to the path
+// passed, followed by the data if withBody is set, and an optional Hash of
+// headers
+func netHTTPRequest(method string, withBody bool) runtimeFunction {
+	return func(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+		args, _ = extractBlock(args)
+		path, err := stringArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		args = args[1:]
+		var body RubyObject = NIL
+		if withBody {
+			body, args = args[0], args[1:]
+		}
+		var headers RubyObject = NIL
+		if len(args) == 1 {
+			headers = args[0]
+		}
+		return context.(*NetHTTP).request(r, method, path.Value, body, headers)
+	}
+}
+
+// newNetHTTPResponse returns the response resp with the body read from it
+func newNetHTTPResponse(resp *http.Response, body string) *NetHTTPResponse {
+	return &NetHTTPResponse{
+		Code:    resp.StatusCode,
+		Message: strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		Header:  resp.Header,
+		Body:    body,
+	}
+}
+
+// NetHTTPResponse represents the response to a request sent by Net::HTTP
+type NetHTTPResponse struct {
+	Code    int
+	Message string
+	Header  http.Header
+	Body    string
+}
+
+// Type returns OBJECT_OBJ
+func (r *NetHTTPResponse) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the class and status, like `#<Net::HTTPSuccess 200 OK>`
+func (r *NetHTTPResponse) Inspect() string {
+	return fmt.Sprintf("#<%s %d %s>", r.Class().(RubyObject).Inspect(), r.Code, r.Message)
+}
+
+// Class returns the class of the status code, like Net::HTTPSuccess for 2xx
+func (r *NetHTTPResponse) Class() RubyClass {
+	if class, ok := netHTTPResponseClasses[r.Code/100]; ok {
+		return class
+	}
+	return netHTTPResponseClass
+}
+
+var netHTTPResponseMethods = map[string]RubyMethod{
+	"code":         withArity(0, publicMethod(netHTTPResponseCode)),
+	"message":      withArity(0, publicMethod(netHTTPResponseMessage)),
+	"body":         withArity(0, publicMethod(netHTTPResponseBody)),
+	"[]":           withArity(1, publicMethod(netHTTPResponseHeader)),
+	"key?":         withArity(1, publicMethod(netHTTPResponseHasHeader)),
+	"content_type": withArity(0, publicMethod(netHTTPResponseContentType)),
+	"to_hash":      withArity(0, publicMethod(netHTTPResponseToHash)),
+	"each_header":  withArity(0, publicMethod(netHTTPResponseEachHeader)),
+}
+
+// netHTTPResponseCode returns the status code as String, like in Ruby
+func netHTTPResponseCode(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: strconv.Itoa(context.(*NetHTTPResponse).Code)}, nil
+}
+
+func netHTTPResponseMessage(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*NetHTTPResponse).Message}, nil
+}
+
+func netHTTPResponseBody(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*NetHTTPResponse).Body}, nil
+}
+
+// netHTTPResponseHeader returns the values of the header named case
+// insensitively joined by commas, or nil if it is missing
+func netHTTPResponseHeader(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	values := context.(*NetHTTPResponse).Header.Values(name)
+	if len(values) == 0 {
+		return NIL, nil
+	}
+	return &String{Value: strings.Join(values, ", ")}, nil
+}
+
+func netHTTPResponseHasHeader(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(len(context.(*NetHTTPResponse).Header.Values(name)) > 0), nil
+}
+
+// netHTTPResponseContentType returns the media type of the Content-Type
+// header without parameters, like `application/json`
+func netHTTPResponseContentType(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	contentType := context.(*NetHTTPResponse).Header.Get("Content-Type")
+	if contentType == "" {
+		return NIL, nil
+	}
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return &String{Value: strings.ToLower(mediaType)}, nil
+}
+
+// netHTTPResponseToHash returns the headers by their lowercase names, with
+// Arrays of their values
+func netHTTPResponseToHash(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	header := context.(*NetHTTPResponse).Header
+	hash := NewHash(nil)
+	for _, name := range sortedHeaderNames(header) {
+		values := make([]RubyObject, len(header[name]))
+		for i, value := range header[name] {
+			values[i] = &String{Value: value}
+		}
+		hash.Set(&String{Value: strings.ToLower(name)}, NewArray(values...))
+	}
+	return hash, nil
+}
+
+// netHTTPResponseEachHeader yields the lowercase names and the values of
+// the headers joined by commas
+func netHTTPResponseEachHeader(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	header := context.(*NetHTTPResponse).Header
+	for _, name := range sortedHeaderNames(header) {
+		value := &String{Value: strings.Join(header[name], ", ")}
+		if _, err := block.Call(&String{Value: strings.ToLower(name)}, value); err != nil {
+			return nil, err
+		}
+	}
+	return context, nil
+}
+
+// sortedHeaderNames returns the names of the headers in alphabetical order
+func sortedHeaderNames(header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,130 @@
+package object
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newNetHTTPTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Greeting", r.Header.Get("X-Name"))
+		io.WriteString(w, "Hello "+r.URL.Query().Get("name"))
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, r.Method+" "+string(body))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNetHTTPGet(t *testing.T) {
+	server := newNetHTTPTestServer(t)
+
+	body, err := Send(netHTTPClass, "get", &String{Value: server.URL + "/hello?name=Ruby"})
+	checkError(t, err, nil)
+	checkResult(t, body, &String{Value: "Hello Ruby"})
+
+	response, err := Send(netHTTPClass, "get_response", &String{Value: server.URL + "/missing"})
+	checkError(t, err, nil)
+	if response.Class() != netHTTPResponseClasses[4] {
+		t.Errorf("Expected a Net::HTTPClientError, got %s", response.Inspect())
+	}
+	code, err := Send(response, "code")
+	checkError(t, err, nil)
+	checkResult(t, code, &String{Value: "404"})
+}
+
+func TestNetHTTPPost(t *testing.T) {
+	server := newNetHTTPTestServer(t)
+
+	response, err := Send(netHTTPClass, "post", &String{Value: server.URL + "/echo"}, &String{Value: "a=1"})
+	checkError(t, err, nil)
+	if inspect := response.Inspect(); inspect != "#<Net::HTTPSuccess 201 Created>" {
+		t.Errorf("Expected a Net::HTTPSuccess, got %s", inspect)
+	}
+
+	body, err := Send(response, "body")
+	checkError(t, err, nil)
+	checkResult(t, body, &String{Value: "POST a=1"})
+
+	contentType, err := Send(response, "content_type")
+	checkError(t, err, nil)
+	checkResult(t, contentType, &String{Value: "application/x-www-form-urlencoded"})
+
+	params := NewHash(nil)
+	params.Set(&String{Value: "q"}, &String{Value: "a b"})
+	response, err = Send(netHTTPClass, "post_form", &String{Value: server.URL + "/echo"}, params)
+	checkError(t, err, nil)
+	body, err = Send(response, "body")
+	checkError(t, err, nil)
+	checkResult(t, body, &String{Value: "POST q=a+b"})
+}
+
+func TestNetHTTPInstance(t *testing.T) {
+	server := newNetHTTPTestServer(t)
+	client, _, err := netHTTPForURL(&String{Value: server.URL})
+	checkError(t, err, nil)
+
+	headers := NewHash(nil)
+	headers.Set(&String{Value: "X-Name"}, &String{Value: "Go"})
+	response, err := Send(client, "get", &String{Value: "/hello"}, headers)
+	checkError(t, err, nil)
+
+	greeting, err := Send(response, "[]", &String{Value: "x-greeting"})
+	checkError(t, err, nil)
+	checkResult(t, greeting, &String{Value: "Go"})
+
+	missing, err := Send(response, "[]", &String{Value: "X-Missing"})
+	checkError(t, err, nil)
+	checkResult(t, missing, NIL)
+
+	response, err = Send(client, "put", &String{Value: "/echo"}, &String{Value: "data"})
+	checkError(t, err, nil)
+	body, err := Send(response, "body")
+	checkError(t, err, nil)
+	checkResult(t, body, &String{Value: "PUT data"})
+}
+
+func TestNetHTTPTimeouts(t *testing.T) {
+	server := newNetHTTPTestServer(t)
+	client, _, err := netHTTPForURL(&String{Value: server.URL})
+	checkError(t, err, nil)
+
+	timeout, err := Send(client, "read_timeout")
+	checkError(t, err, nil)
+	checkResult(t, timeout, NewInteger(60))
+
+	_, err = Send(client, "read_timeout=", NewFloat(0.05))
+	checkError(t, err, nil)
+	timeout, err = Send(client, "read_timeout")
+	checkError(t, err, nil)
+	checkResult(t, timeout, NewFloat(0.05))
+
+	_, err = Send(client, "get", &String{Value: "/slow"})
+	checkError(t, err, NewNetReadTimeoutError())
+}
+
+func TestNetHTTPErrors(t *testing.T) {
+	_, err := Send(netHTTPClass, "get", &String{Value: "ftp://example.com"})
+	checkError(t, err, NewArgumentError("invalid HTTP URL: ftp://example.com"))
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	_, err = Send(netHTTPClass, "get", &String{Value: url})
+	if _, ok := err.(*SocketError); !ok {
+		t.Errorf("Expected a SocketError, got %T (%v)", err, err)
+	}
+}
@@ -15,6 +15,7 @@ type nilObject struct{}
 func (n *nilObject) Inspect() string  { return "nil" }
 func (n *nilObject) Type() Type       { return NIL_OBJ }
 func (n *nilObject) Class() RubyClass { return nilClass }
+func (n *nilObject) hashKey() hashKey { return hashKey{Type: n.Type()} }
 
 var nilClassMethods = map[string]RubyMethod{}
 
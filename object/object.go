@@ -3,20 +3,58 @@ package object
 var objectClass = mixin(newClass("Object", basicObjectClass, objectMethods, objectClassMethods), kernelModule)
 
 func init() {
+	setAllocator(objectClass, func(class RubyClassObject) RubyObject {
+		return &Object{class: class}
+	})
 	classes.Set("Object", objectClass)
 }
 
 // Object represents an Object in Ruby
-type Object struct{}
+type Object struct {
+	ivars  instanceVariableTable
+	class  RubyClass
+	frozen bool
+}
 
-// Inspect return ""
-func (o *Object) Inspect() string { return "" }
+// Inspect returns the class name, the object ID and all instance variables
+// of the object
+func (o *Object) Inspect() string { return inspectObject(o, make(map[RubyObject]bool)) }
 
 // Type returns OBJECT_OBJ
 func (o *Object) Type() Type { return OBJECT_OBJ }
 
-// Class returns objectClass
-func (o *Object) Class() RubyClass { return objectClass }
+// Class returns the class the object has been allocated by, or objectClass
+func (o *Object) Class() RubyClass {
+	if o.class != nil {
+		return o.class
+	}
+	return objectClass
+}
+
+// Frozen returns true if the object can not be modified anymore
+func (o *Object) Frozen() bool { return o.frozen }
+
+// Freeze prevents any further modifications of the object
+func (o *Object) Freeze() { o.frozen = true }
+
+func (o *Object) instanceVariables() *instanceVariableTable { return &o.ivars }
+
+func (o *Object) singletonClass() *eigenclass {
+	if eigenClass, ok := o.class.(*eigenclass); ok {
+		return eigenClass
+	}
+	eigenClass := newEigenclass(o.Class(), nil)
+	o.class = eigenClass
+	return eigenClass
+}
+
+// mainObject is the top level object, which represents itself as `main`
+type mainObject struct {
+	*Object
+}
+
+// Inspect returns "main"
+func (m *mainObject) Inspect() string { return "main" }
 
 var objectClassMethods = map[string]RubyMethod{}
 
@@ -0,0 +1,72 @@
+package object
+
+import "sync"
+
+// Object IDs of the immediate values nil, true and false, as known from
+// Ruby
+const (
+	falseObjectID int64 = 0
+	nilObjectID   int64 = 8
+	trueObjectID  int64 = 20
+)
+
+// objectIDs keeps the IDs of all objects which have been asked for one.
+// Integers get odd IDs derived from their value, all other objects get
+// multiples of 8 in the order of their first request.
+var objectIDs = struct {
+	sync.Mutex
+	next int64
+	ids  map[interface{}]int64
+}{
+	next: 16,
+	ids:  make(map[interface{}]int64),
+}
+
+// ObjectID returns the unique identifier of obj. Integers, Floats, Symbols,
+// nil, true and false with the same value share the same ID.
+func ObjectID(obj RubyObject) int64 {
+	obj = unwrapObject(obj)
+	switch obj := obj.(type) {
+	case *Integer:
+		return 2*obj.Value + 1
+	case *nilObject:
+		return nilObjectID
+	case *Boolean:
+		if obj.Value {
+			return trueObjectID
+		}
+		return falseObjectID
+	}
+
+	var key interface{} = obj
+	switch obj := obj.(type) {
+	case *Symbol:
+		key = obj.hashKey()
+	case *Float:
+		key = obj.hashKey()
+	}
+
+	objectIDs.Lock()
+	defer objectIDs.Unlock()
+	id, ok := objectIDs.ids[key]
+	if !ok {
+		id = objectIDs.next
+		objectIDs.next += 8
+		objectIDs.ids[key] = id
+	}
+	return id
+}
+
+func kernelObjectID(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(ObjectID(context)), nil
+}
+
+// kernelIsEqual reports whether the argument is the very same object as
+// context, independent of any `==` implementation
+func kernelIsEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(ObjectID(context) == ObjectID(args[0])), nil
+}
+
+func kernelItself(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context, nil
+}
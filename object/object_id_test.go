@@ -0,0 +1,85 @@
+package object
+
+import "testing"
+
+func TestObjectID(t *testing.T) {
+	t.Run("immediates", func(t *testing.T) {
+		tests := []struct {
+			obj      RubyObject
+			expected int64
+		}{
+			{NewInteger(0), 1},
+			{NewInteger(3), 7},
+			{NewInteger(-2), -3},
+			{NIL, 8},
+			{TRUE, 20},
+			{FALSE, 0},
+		}
+
+		for _, testCase := range tests {
+			actual := ObjectID(testCase.obj)
+			if actual != testCase.expected {
+				t.Logf("Expected object_id of %s to equal %d, got %d", testCase.obj.Inspect(), testCase.expected, actual)
+				t.Fail()
+			}
+		}
+	})
+	t.Run("values", func(t *testing.T) {
+		if ObjectID(&Symbol{"foo"}) != ObjectID(&Symbol{"foo"}) {
+			t.Logf("Expected equal Symbols to share their object_id")
+			t.Fail()
+		}
+		if ObjectID(&Symbol{"foo"}) == ObjectID(&Symbol{"bar"}) {
+			t.Logf("Expected different Symbols to have different object_ids")
+			t.Fail()
+		}
+		if ObjectID(NewFloat(1.5)) != ObjectID(NewFloat(1.5)) {
+			t.Logf("Expected equal Floats to share their object_id")
+			t.Fail()
+		}
+	})
+	t.Run("objects", func(t *testing.T) {
+		obj := &String{Value: "foo"}
+		id := ObjectID(obj)
+		if id%8 != 0 || id == ObjectID(NIL) {
+			t.Logf("Expected object_id to be a multiple of 8 distinct from nil, got %d", id)
+			t.Fail()
+		}
+		if ObjectID(obj) != id {
+			t.Logf("Expected object_id to be stable")
+			t.Fail()
+		}
+		if ObjectID(&Self{obj}) != id {
+			t.Logf("Expected self to share the object_id of the wrapped object")
+			t.Fail()
+		}
+		if ObjectID(&String{Value: "foo"}) == id {
+			t.Logf("Expected equal Strings to have different object_ids")
+			t.Fail()
+		}
+	})
+}
+
+func TestKernelIsEqual(t *testing.T) {
+	str := &String{Value: "foo"}
+	tests := []struct {
+		context  RubyObject
+		arg      RubyObject
+		expected RubyObject
+	}{
+		{str, str, TRUE},
+		{str, &String{Value: "foo"}, FALSE},
+		{NewInteger(2), NewInteger(2), TRUE},
+		{NewInteger(2), NewFloat(2), FALSE},
+		{&Symbol{"a"}, &Symbol{"a"}, TRUE},
+		{NIL, FALSE, FALSE},
+	}
+
+	for _, testCase := range tests {
+		result, err := Send(testCase.context, "equal?", testCase.arg)
+
+		checkError(t, err, nil)
+
+		checkResult(t, result, testCase.expected)
+	}
+}
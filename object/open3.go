@@ -0,0 +1,162 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+var open3Module = newModule("Open3", open3Methods)
+
+func init() {
+	classes.Set("Open3", open3Module)
+}
+
+var open3Methods = map[string]RubyMethod{
+	"capture2":  withArityRange(1, -1, publicRuntimeMethod(open3Capture2)),
+	"capture2e": withArityRange(1, -1, publicRuntimeMethod(open3Capture2e)),
+	"capture3":  withArityRange(1, -1, publicRuntimeMethod(open3Capture3)),
+	"popen3":    withArityRange(1, -1, publicRuntimeMethod(open3Popen3)),
+}
+
+// open3Command returns the command to run for the arguments, which are
+// those of Kernel#system followed by an optional Hash of options. The only
+// option is stdin_data, the String written to the stdin of the command,
+// which is only allowed if stdinData is not nil.
+func open3Command(args []RubyObject, stdinData *string) (*exec.Cmd, error) {
+	args, _ = extractBlock(args)
+	if len(args) > 1 {
+		if options, ok := args[len(args)-1].(*Hash); ok {
+			args = args[:len(args)-1]
+			keys, values := options.Keys(), options.Values()
+			for i, key := range keys {
+				if stdinData == nil || toS(key) != "stdin_data" {
+					return nil, NewArgumentError("unknown keyword: %s", key.Inspect())
+				}
+				data, err := stringArgument(values[i])
+				if err != nil {
+					return nil, err
+				}
+				*stdinData = data.Value
+			}
+		}
+	}
+	return command(kernelCommandContext(), DefaultStreams(), args)
+}
+
+// startError returns the Errno::ENOENT raised for a command which cannot
+// be started
+func startError(cmd *exec.Cmd) error {
+	return NewSystemCallError(&os.PathError{Op: "exec", Path: strings.Join(cmd.Args, " "), Err: syscall.ENOENT})
+}
+
+// capture runs the command described by args with its output written to
+// stdout and its errors to stderr, or to the errors of the program if it is
+// nil, and returns its status
+func capture(r *Runtime, args []RubyObject, stdout, stderr io.Writer) (*ProcessStatus, error) {
+	var stdinData string
+	cmd, err := open3Command(args, &stdinData)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = strings.NewReader(stdinData)
+	cmd.Stdout = stdout
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+	status, err := runCommand(r, cmd)
+	if err != nil {
+		return nil, startError(cmd)
+	}
+	return status, nil
+}
+
+// open3Capture2 runs the command and returns its output and status, while
+// its errors go to the errors of the program
+func open3Capture2(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	var stdout bytes.Buffer
+	status, err := capture(r, args, &stdout, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(&String{Value: stdout.String()}, status), nil
+}
+
+// open3Capture2e runs the command and returns its output and errors
+// merged into one String, and its status
+func open3Capture2e(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	var out bytes.Buffer
+	status, err := capture(r, args, &out, &out)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(&String{Value: out.String()}, status), nil
+}
+
+// open3Capture3 runs the command and returns its output, its errors and
+// its status
+func open3Capture3(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	var stdout, stderr bytes.Buffer
+	status, err := capture(r, args, &stdout, &stderr)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(&String{Value: stdout.String()}, &String{Value: stderr.String()}, status), nil
+}
+
+// open3Popen3 starts the command with pipes connected to its standard
+// streams. It returns the IO writing its input, the IOs reading its output
+// and errors, and a Thread waiting for it, whose value is its status and
+// whose :pid is its process id. Given a block it yields them instead,
+// closes the IOs and waits for the command once the block returns, and
+// returns the value of the block.
+func open3Popen3(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	cmd, err := open3Command(args, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pipes [3]struct{ r, w *os.File }
+	for i := range pipes {
+		if pipes[i].r, pipes[i].w, err = os.Pipe(); err != nil {
+			return nil, NewSystemCallError(err)
+		}
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = pipes[0].r, pipes[1].w, pipes[2].w
+	err = cmd.Start()
+	// the child has its own copies of its ends of the pipes
+	pipes[0].r.Close()
+	pipes[1].w.Close()
+	pipes[2].w.Close()
+	if err != nil {
+		pipes[0].w.Close()
+		pipes[1].r.Close()
+		pipes[2].r.Close()
+		return nil, startError(cmd)
+	}
+	stdin, stdout, stderr := newWriterIO(pipes[0].w), NewIO(pipes[1].r), NewIO(pipes[2].r)
+	waiter := r.startThread(newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+		r.withoutInterpreter(func() {
+			cmd.Wait()
+		})
+		return newProcessStatus(cmd.ProcessState), nil
+	}), nil)
+	waiter.locals = map[string]RubyObject{"pid": NewInteger(int64(cmd.Process.Pid))}
+	if block == nil {
+		return NewArray(stdin, stdout, stderr, waiter), nil
+	}
+	result, err := block.Call(stdin, stdout, stderr, waiter)
+	for _, stream := range []*IO{stdin, stdout, stderr} {
+		stream.Close()
+	}
+	if _, joinErr := waiter.join(r, -1); err == nil {
+		err = joinErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package object
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestOpen3Capture3(t *testing.T) {
+	options := NewHash(nil)
+	options.Set(NewSymbol("stdin_data"), &String{Value: "input"})
+
+	result, err := Send(open3Module, "capture3", &String{Value: "cat; echo oops >&2; exit 2"}, options)
+	checkError(t, err, nil)
+	elements := result.(*Array).Elements
+	checkResult(t, elements[0], &String{Value: "input"})
+	checkResult(t, elements[1], &String{Value: "oops\n"})
+	if status := elements[2].(*ProcessStatus); status.ExitStatus != 2 {
+		t.Errorf("Expected exit status 2, got %d", status.ExitStatus)
+	}
+
+	result, err = Send(open3Module, "capture2e", &String{Value: "echo"}, &String{Value: "out"}, &String{Value: "err"})
+	checkError(t, err, nil)
+	checkResult(t, result.(*Array).Elements[0], &String{Value: "out err\n"})
+
+	_, err = Send(open3Module, "capture2", &String{Value: "goruby-missing-command"})
+	if err, ok := err.(*SystemCallError); !ok || err.Class() != errnoClasses[syscall.ENOENT] {
+		t.Errorf("Expected Errno::ENOENT, got %v", err)
+	}
+}
+
+func TestOpen3Popen3(t *testing.T) {
+	result, err := Send(open3Module, "popen3", &String{Value: "tr a-z A-Z"})
+	checkError(t, err, nil)
+	elements := result.(*Array).Elements
+	stdin, stdout, waiter := elements[0], elements[1], elements[3]
+
+	_, err = Send(stdin, "puts", &String{Value: "hello"})
+	checkError(t, err, nil)
+	_, err = Send(stdin, "gets")
+	checkError(t, err, NewIOError("not opened for reading"))
+	_, err = Send(stdin, "close")
+	checkError(t, err, nil)
+
+	output, err := Send(stdout, "read")
+	checkError(t, err, nil)
+	checkResult(t, output, &String{Value: "HELLO\n"})
+
+	status, err := Send(waiter, "value")
+	checkError(t, err, nil)
+	if !status.(*ProcessStatus).Success() {
+		t.Errorf("Expected the command to succeed, got %s", status.Inspect())
+	}
+	pid, err := Send(waiter, "[]", NewSymbol("pid"))
+	checkError(t, err, nil)
+	checkResult(t, pid, NewInteger(int64(status.(*ProcessStatus).Pid)))
+}
@@ -0,0 +1,140 @@
+package object
+
+import "runtime"
+
+var parallelModule = newModule("Parallel", parallelMethods)
+
+func init() {
+	classes.Set("Parallel", parallelModule)
+}
+
+var parallelMethods = map[string]RubyMethod{
+	"map":  withArityRange(1, 2, publicRuntimeMethod(parallelModuleMap)),
+	"each": withArityRange(1, 2, publicRuntimeMethod(parallelModuleEach)),
+}
+
+// parallelMap calls block with every value on one of workers Threads and
+// returns the results in the order of values. Once a block raised, no
+// further values are handed out and the first exception raised is
+// returned after all Threads finished.
+//
+// The Threads share the interpreter lock like all others, so that the
+// blocks run concurrently only while they wait, e.g. for sleep or IO.
+func parallelMap(r *Runtime, values []RubyObject, workers int, block *Proc) ([]RubyObject, error) {
+	results := make([]RubyObject, len(values))
+	if workers > len(values) {
+		workers = len(values)
+	}
+	// the state is shared by the Threads, which only access it while
+	// holding the interpreter lock
+	next := 0
+	var firstErr error
+	worker := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+		for firstErr == nil && next < len(values) {
+			i := next
+			next++
+			result, err := block.Call(values[i])
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				break
+			}
+			results[i] = result
+		}
+		return NIL, nil
+	})
+	threads := make([]*Thread, workers)
+	for i := range threads {
+		threads[i] = r.startThread(worker, nil)
+	}
+	for _, thread := range threads {
+		if _, err := thread.join(r, -1); err != nil {
+			return nil, err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// parallelWorkers returns the number of Threads given as optional
+// argument, which defaults to the number of CPUs
+func parallelWorkers(args []RubyObject) (int, error) {
+	if len(args) == 0 || args[0] == NIL {
+		return runtime.NumCPU(), nil
+	}
+	workers, err := integerArgument(args[0])
+	if err != nil {
+		return 0, err
+	}
+	if workers.Value <= 0 {
+		return 0, NewArgumentError("worker count must be positive")
+	}
+	return int(workers.Value), nil
+}
+
+// parallelArguments returns the values of the collection, the number of
+// workers and the block passed to Parallel.map or Parallel.each
+func parallelArguments(args []RubyObject) ([]RubyObject, int, *Proc, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, 0, nil, NewArgumentError("no block given")
+	}
+	values, ok := args[0].(*Array)
+	if !ok {
+		converted, ok, err := convertWith(args[0], &Array{}, "to_a")
+		if !ok {
+			return nil, 0, nil, NewTypeError("can't convert %s into Array", args[0].Class().(RubyObject).Inspect())
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		values = converted.(*Array)
+	}
+	workers, err := parallelWorkers(args[1:])
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return values.Elements, workers, block, nil
+}
+
+// parallelModuleMap maps the values of the collection by the block, which
+// is called on the given number of Threads
+func parallelModuleMap(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	values, workers, block, err := parallelArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parallelMap(r, values, workers, block)
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(results...), nil
+}
+
+// parallelModuleEach calls the block with every value of the collection on
+// the given number of Threads and returns the collection
+func parallelModuleEach(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	values, workers, block, err := parallelArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parallelMap(r, values, workers, block); err != nil {
+		return nil, err
+	}
+	return args[0], nil
+}
+
+// arrayParallelMap is Array#pmap, mapping the elements like map, but on
+// the number of Threads given
+func arrayParallelMap(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return parallelModuleMap(r, parallelModule, append([]RubyObject{context}, args...)...)
+}
+
+// arrayParallelEach is Array#peach, calling the block with every element
+// like each, but on the number of Threads given
+func arrayParallelEach(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return parallelModuleEach(r, parallelModule, append([]RubyObject{context}, args...)...)
+}
@@ -0,0 +1,45 @@
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParallelMap(t *testing.T) {
+	t.Run("keeps the order of the values", func(t *testing.T) {
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			value := args[0].(*Integer).Value
+			(*Runtime)(nil).withoutInterpreter(func() { time.Sleep(time.Duration(5-value) * time.Millisecond) })
+			return NewInteger(value * 10), nil
+		})
+		values := NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NewInteger(4))
+
+		result, err := Send(values, "pmap", NewInteger(3), block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(NewInteger(10), NewInteger(20), NewInteger(30), NewInteger(40)))
+	})
+	t.Run("returns the first exception", func(t *testing.T) {
+		var calls int
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			calls++
+			return nil, NewRuntimeError("failed %s", args[0].Inspect())
+		})
+		values := NewArray(NewInteger(1), NewInteger(2), NewInteger(3), NewInteger(4))
+
+		_, err := Send(parallelModule, "map", values, NewInteger(1), block)
+
+		checkError(t, err, NewRuntimeError("failed 1"))
+		if calls != 1 {
+			t.Logf("Expected no further values to be handed out, got %d calls", calls)
+			t.Fail()
+		}
+	})
+	t.Run("rejects invalid worker counts", func(t *testing.T) {
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) { return NIL, nil })
+
+		_, err := Send(NewArray(NIL), "pmap", NewInteger(0), block)
+
+		checkError(t, err, NewArgumentError("worker count must be positive"))
+	})
+}
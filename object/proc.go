@@ -0,0 +1,170 @@
+package object
+
+import "github.com/goruby/goruby/ast"
+
+var procClass RubyClassObject = newClass("Proc", objectClass, procMethods, nil)
+
+func init() {
+	classes.Set("Proc", procClass)
+}
+
+// A Proc represents a block of code bound to the environment it was defined
+// in. Blocks passed to methods are given as Proc in the last argument.
+//
+// A lambda is a Proc which checks its arguments strictly and within which
+// `return` only leaves the lambda itself.
+type Proc struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        Environment
+	CallFn     func(body *ast.BlockStatement, env Environment) (RubyObject, error)
+	Lambda     bool
+	native     func(args ...RubyObject) (RubyObject, error)
+}
+
+// newNativeProc returns a Proc which calls fn with all arguments it is
+// called with
+func newNativeProc(fn func(args ...RubyObject) (RubyObject, error)) *Proc {
+	return &Proc{native: fn}
+}
+
+// Type returns PROC_OBJ
+func (p *Proc) Type() Type { return PROC_OBJ }
+
+// Inspect returns the class name of the Proc, marking lambdas as such
+func (p *Proc) Inspect() string {
+	if p.Lambda {
+		return "#<Proc (lambda)>"
+	}
+	return "#<Proc>"
+}
+
+// Class returns procClass
+func (p *Proc) Class() RubyClass { return procClass }
+
+// parameters returns the parameters of the Proc, which are optional unless
+// it is a lambda
+func (p *Proc) parameters() []parameter {
+	params := p.lambdaParameters()
+	if !p.Lambda {
+		for i := range params {
+			if params[i].kind == "req" {
+				params[i].kind = "opt"
+			}
+		}
+	}
+	return params
+}
+
+// lambdaParameters returns the parameters the Proc has as a lambda. Like in
+// Ruby the arity of procs is the one of the lambda.
+func (p *Proc) lambdaParameters() []parameter {
+	if p.native != nil {
+		return []parameter{{kind: "rest"}}
+	}
+	params := make([]parameter, len(p.Parameters))
+	for i, param := range p.Parameters {
+		params[i] = parameter{kind: "req", name: param.Value}
+	}
+	return params
+}
+
+// Call evaluates the body of the Proc with args bound to its parameters.
+// Like in Ruby missing arguments are nil and surplus arguments are
+// ignored. A single Array argument is spread over multiple parameters.
+// Lambdas instead return an ArgumentError if the number of arguments does
+// not match.
+func (p *Proc) Call(args ...RubyObject) (RubyObject, error) {
+	return p.callWithSelf(nil, args...)
+}
+
+// callWithSelf calls the Proc like Call, but evaluates its body with self
+// set to the given object. A nil self keeps the self the Proc was defined
+// with.
+func (p *Proc) callWithSelf(self RubyObject, args ...RubyObject) (RubyObject, error) {
+	if p.native != nil {
+		return p.native(args...)
+	}
+	if p.Lambda && len(args) != len(p.Parameters) {
+		return nil, NewWrongNumberOfArgumentsError(len(p.Parameters), len(args))
+	}
+	env := newBlockEnvironment(p.Env)
+	if self != nil {
+		env.store["self"] = self
+	}
+	if array, ok := singleArray(args); ok && len(p.Parameters) > 1 && !p.Lambda {
+		args = array.Elements
+	}
+	for i, param := range p.Parameters {
+		var value RubyObject = NIL
+		if i < len(args) {
+			value = args[i]
+		}
+		env.store[param.Value] = value
+	}
+	return p.CallFn(p.Body, env)
+}
+
+func singleArray(args []RubyObject) (*Array, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	array, ok := args[0].(*Array)
+	return array, ok
+}
+
+// extractBlock splits args into the regular arguments and the block passed
+// as last argument. The returned block is nil if there is none.
+func extractBlock(args []RubyObject) ([]RubyObject, *Proc) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	block, ok := args[len(args)-1].(*Proc)
+	if !ok {
+		return args, nil
+	}
+	return args[:len(args)-1], block
+}
+
+var procMethods = map[string]RubyMethod{
+	"call":       publicMethod(procCall),
+	"lambda?":    withArity(0, publicMethod(procIsLambda)),
+	"arity":      withArity(0, publicMethod(procArity)),
+	"parameters": withArity(0, publicMethod(procParameters)),
+}
+
+func procCall(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	proc := context.(*Proc)
+	return proc.Call(args...)
+}
+
+func procIsLambda(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	proc := context.(*Proc)
+	return nativeBoolToBoolean(proc.Lambda), nil
+}
+
+func procArity(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(arityOf(context.(*Proc).lambdaParameters()))), nil
+}
+
+func procParameters(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	proc := context.(*Proc)
+	return parametersToArray(proc.parameters()), nil
+}
+
+func kernelProc(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	}
+	return block, nil
+}
+
+func kernelLambda(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	}
+	block.Lambda = true
+	return block, nil
+}
@@ -0,0 +1,101 @@
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKernelProcAndLambda(t *testing.T) {
+	t.Run("proc", func(t *testing.T) {
+		block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			return NewArray(args...), nil
+		}, "a", "b")
+
+		result, err := kernelProc(TRUE, block)
+
+		checkError(t, err, nil)
+		proc := result.(*Proc)
+		if proc.Lambda {
+			t.Logf("Expected proc not to be a lambda")
+			t.Fail()
+		}
+		value, err := proc.Call(NewInteger(1))
+		checkError(t, err, nil)
+		checkResult(t, value, NewArray(NewInteger(1), NIL))
+	})
+	t.Run("lambda", func(t *testing.T) {
+		block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			return NewArray(args...), nil
+		}, "a", "b")
+
+		result, err := kernelLambda(TRUE, block)
+
+		checkError(t, err, nil)
+		proc := result.(*Proc)
+		if !proc.Lambda {
+			t.Logf("Expected proc to be a lambda")
+			t.Fail()
+		}
+		_, err = proc.Call(NewInteger(1))
+		checkError(t, err, NewWrongNumberOfArgumentsError(2, 1))
+
+		value, err := proc.Call(NewArray(NewInteger(1), NewInteger(2)), NewInteger(3))
+		checkError(t, err, nil)
+		checkResult(t, value, NewArray(NewArray(NewInteger(1), NewInteger(2)), NewInteger(3)))
+	})
+	t.Run("without block", func(t *testing.T) {
+		expected := NewArgumentError("tried to create Proc object without a block")
+
+		_, err := kernelProc(TRUE)
+		checkError(t, err, expected)
+
+		_, err = kernelLambda(TRUE)
+		checkError(t, err, expected)
+	})
+}
+
+func TestProcArity(t *testing.T) {
+	tests := []struct {
+		proc  *Proc
+		arity RubyObject
+	}{
+		{testBlock(nil), NewInteger(0)},
+		{testBlock(nil, "a", "b"), NewInteger(2)},
+		{newNativeProc(func(args ...RubyObject) (RubyObject, error) { return NIL, nil }), NewInteger(-1)},
+	}
+
+	for _, tt := range tests {
+		result, err := procArity(tt.proc)
+
+		checkError(t, err, nil)
+		if !reflect.DeepEqual(result, tt.arity) {
+			t.Logf("Expected arity %s, got %s", tt.arity.Inspect(), result.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestProcParameters(t *testing.T) {
+	lambda := testBlock(nil, "a", "b")
+	lambda.Lambda = true
+
+	tests := []struct {
+		proc       *Proc
+		parameters string
+	}{
+		{testBlock(nil), "[]"},
+		{testBlock(nil, "a", "b"), "[[:opt, :a], [:opt, :b]]"},
+		{lambda, "[[:req, :a], [:req, :b]]"},
+		{newNativeProc(func(args ...RubyObject) (RubyObject, error) { return NIL, nil }), "[[:rest]]"},
+	}
+
+	for _, tt := range tests {
+		result, err := procParameters(tt.proc)
+
+		checkError(t, err, nil)
+		if result.Inspect() != tt.parameters {
+			t.Logf("Expected parameters %s, got %s", tt.parameters, result.Inspect())
+			t.Fail()
+		}
+	}
+}
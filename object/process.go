@@ -0,0 +1,297 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+var (
+	processModule      = newModule("Process", processMethods)
+	processStatusClass = newClass("Process::Status", objectClass, processStatusMethods, nil)
+)
+
+func init() {
+	classes.Set("Process", processModule)
+	setConstant(processModule, "Status", processStatusClass)
+	kernelMethodSet["system"] = withArityRange(1, -1, privateRuntimeMethod(kernelSystem))
+	kernelMethodSet["`"] = withArity(1, privateRuntimeMethod(kernelBacktick))
+}
+
+var processMethods = map[string]RubyMethod{
+	"pid": withArity(0, publicMethod(processPid)),
+}
+
+func processPid(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(os.Getpid())), nil
+}
+
+// shellMetaCharacters are the characters which make Ruby run a command
+// string by the shell rather than splitting it into words
+const shellMetaCharacters = "*?{}[]<>()~&|\\$;'`\"\n#=%"
+
+// shellWords are the reserved words and special builtins of the shell, which
+// make Ruby run a command string starting with them by the shell
+var shellWords = map[string]bool{
+	"case": true, "do": true, "done": true, "elif": true, "else": true,
+	"esac": true, "fi": true, "for": true, "if": true, "in": true,
+	"then": true, "until": true, "while": true, "!": true, ".": true,
+	":": true, "break": true, "continue": true, "eval": true, "exec": true,
+	"exit": true, "export": true, "readonly": true, "return": true,
+	"set": true, "shift": true, "times": true, "trap": true, "unset": true,
+}
+
+// command returns the command to run for the arguments of system: an
+// optional Hash of environment variables, followed either by a single
+// command line, run by /bin/sh if it contains shell meta characters or
+// starts with a word of the shell, or by the program and its arguments,
+// which are passed as they are.
+func command(ctx context.Context, streams *Streams, args []RubyObject) (*exec.Cmd, error) {
+	var env []string
+	if hash, ok := args[0].(*Hash); ok {
+		keys, values := hash.Keys(), hash.Values()
+		for i, key := range keys {
+			name, err := stringArgument(key)
+			if err != nil {
+				return nil, err
+			}
+			env = append(env, name.Value+"="+toS(values[i]))
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, NewWrongNumberOfArgumentsError(1, 0)
+	}
+	words := make([]string, len(args))
+	for i, arg := range args {
+		str, err := stringArgument(arg)
+		if err != nil {
+			return nil, err
+		}
+		words[i] = str.Value
+	}
+	if len(words) == 1 {
+		fields := strings.Fields(words[0])
+		if strings.ContainsAny(words[0], shellMetaCharacters) || len(fields) > 0 && shellWords[fields[0]] {
+			words = []string{"/bin/sh", "-c", words[0]}
+		} else {
+			words = fields
+		}
+	}
+	if len(words) == 0 {
+		return nil, NewSystemCallError(&os.PathError{Op: "exec", Path: "", Err: syscall.ENOENT})
+	}
+	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if streams.Stdin != nil {
+		if file, ok := streams.Stdin.source.(*os.File); ok {
+			cmd.Stdin = file
+		}
+	}
+	cmd.Stdout = streams.Stdout
+	cmd.Stderr = streams.Stderr
+	return cmd, nil
+}
+
+// runCommand runs cmd, letting other Threads run meanwhile, and returns
+// its status. Commands which cannot be started return a status with exit
+// status 127, like the ones failing within the shell, and the error.
+func runCommand(r *Runtime, cmd *exec.Cmd) (*ProcessStatus, error) {
+	var err error
+	r.withoutInterpreter(func() {
+		err = cmd.Run()
+	})
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return &ProcessStatus{ExitStatus: 127}, err
+	}
+	return newProcessStatus(cmd.ProcessState), nil
+}
+
+// RunSystem runs the command described by args like Kernel#system, with the
+// output written to the streams. It returns true if the command succeeded,
+// false if it failed and nil if it could not be run, as well as its status
+// for `$?`. The command is killed once ctx is done.
+func (r *Runtime) RunSystem(ctx context.Context, streams *Streams, args ...RubyObject) (RubyObject, *ProcessStatus, error) {
+	args, _ = extractBlock(args)
+	cmd, err := command(ctx, streams, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := runCommand(r, cmd)
+	if err != nil {
+		return NIL, status, nil
+	}
+	return nativeBoolToBoolean(status.Success()), status, nil
+}
+
+// RunCommand runs the command line like a command substitution in
+// backticks and returns its output, as well as its status for `$?`. The
+// errors of the command are written to the streams. A command which cannot
+// be run raises Errno::ENOENT.
+func (r *Runtime) RunCommand(ctx context.Context, streams *Streams, commandLine string) (RubyObject, *ProcessStatus, error) {
+	cmd, err := command(ctx, streams, []RubyObject{&String{Value: commandLine}})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	status, err := runCommand(r, cmd)
+	if err != nil {
+		return nil, status, NewSystemCallError(&os.PathError{Op: "exec", Path: commandLine, Err: syscall.ENOENT})
+	}
+	return &String{Value: out.String()}, status, nil
+}
+
+func kernelSystem(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	result, _, err := r.RunSystem(kernelCommandContext(), DefaultStreams(), args...)
+	return result, err
+}
+
+func kernelBacktick(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	result, _, err := r.RunCommand(kernelCommandContext(), DefaultStreams(), str.Value)
+	return result, err
+}
+
+// kernelCommandContext returns the context of the commands run by the
+// Kernel methods, which are called without an evaluation to bind them to
+func kernelCommandContext() context.Context { return context.Background() }
+
+// newProcessStatus returns the status of the exited process state
+func newProcessStatus(state *os.ProcessState) *ProcessStatus {
+	status := &ProcessStatus{Pid: state.Pid(), ExitStatus: state.ExitCode()}
+	if waitStatus, ok := state.Sys().(syscall.WaitStatus); ok && waitStatus.Signaled() {
+		status.Signal = int(waitStatus.Signal())
+	}
+	return status
+}
+
+// ProcessStatus represents the status of a finished child process, like
+// `$?` holds it after a command ran
+type ProcessStatus struct {
+	Pid int
+	// ExitStatus is the exit status, or -1 if the process was killed by a
+	// signal
+	ExitStatus int
+	// Signal is the number of the signal the process was killed by, or 0
+	Signal int
+}
+
+// Type returns OBJECT_OBJ
+func (p *ProcessStatus) Type() Type { return OBJECT_OBJ }
+
+// Inspect returns the pid and the exit status or signal, like
+// `#<Process::Status: pid 42 exit 0>`
+func (p *ProcessStatus) Inspect() string {
+	return "#<Process::Status: " + p.String() + ">"
+}
+
+// String returns the pid and the exit status or signal, like
+// `pid 42 exit 0` or `pid 42 SIGKILL (signal 9)`
+func (p *ProcessStatus) String() string {
+	if p.Signal != 0 {
+		if name := signalName(p.Signal); name != "" {
+			return fmt.Sprintf("pid %d SIG%s (signal %d)", p.Pid, name, p.Signal)
+		}
+		return fmt.Sprintf("pid %d signal %d", p.Pid, p.Signal)
+	}
+	return fmt.Sprintf("pid %d exit %d", p.Pid, p.ExitStatus)
+}
+
+// Class returns processStatusClass
+func (p *ProcessStatus) Class() RubyClass { return processStatusClass }
+
+// Success reports whether the process exited with status 0
+func (p *ProcessStatus) Success() bool { return p.Signal == 0 && p.ExitStatus == 0 }
+
+var processStatusMethods = map[string]RubyMethod{
+	"pid":        withArity(0, publicMethod(processStatusPid)),
+	"exitstatus": withArity(0, publicMethod(processStatusExitstatus)),
+	"success?":   withArity(0, publicMethod(processStatusSuccess)),
+	"exited?":    withArity(0, publicMethod(processStatusExited)),
+	"signaled?":  withArity(0, publicMethod(processStatusSignaled)),
+	"termsig":    withArity(0, publicMethod(processStatusTermsig)),
+	"to_i":       withArity(0, publicMethod(processStatusToI)),
+	"to_s":       withArity(0, publicMethod(processStatusToS)),
+	"==":         withArity(1, publicMethod(processStatusEqual)),
+}
+
+func processStatusPid(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*ProcessStatus).Pid)), nil
+}
+
+// processStatusExitstatus returns the exit status, or nil if the process
+// was killed by a signal
+func processStatusExitstatus(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	status := context.(*ProcessStatus)
+	if status.Signal != 0 {
+		return NIL, nil
+	}
+	return NewInteger(int64(status.ExitStatus)), nil
+}
+
+// processStatusSuccess reports whether the process exited with status 0,
+// returning nil if it was killed by a signal
+func processStatusSuccess(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	status := context.(*ProcessStatus)
+	if status.Signal != 0 {
+		return NIL, nil
+	}
+	return nativeBoolToBoolean(status.Success()), nil
+}
+
+func processStatusExited(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*ProcessStatus).Signal == 0), nil
+}
+
+func processStatusSignaled(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*ProcessStatus).Signal != 0), nil
+}
+
+func processStatusTermsig(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	status := context.(*ProcessStatus)
+	if status.Signal == 0 {
+		return NIL, nil
+	}
+	return NewInteger(int64(status.Signal)), nil
+}
+
+// processStatusToI returns the status like the wait system call does, with
+// the exit status in the second byte or the signal in the first one
+func processStatusToI(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	status := context.(*ProcessStatus)
+	if status.Signal != 0 {
+		return NewInteger(int64(status.Signal)), nil
+	}
+	return NewInteger(int64(status.ExitStatus) << 8), nil
+}
+
+func processStatusToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*ProcessStatus).String()}, nil
+}
+
+// processStatusEqual compares the status with another one or an Integer
+// like returned by to_i
+func processStatusEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	status := context.(*ProcessStatus)
+	switch other := args[0].(type) {
+	case *ProcessStatus:
+		return nativeBoolToBoolean(*status == *other), nil
+	case *Integer:
+		i, _ := processStatusToI(status)
+		return nativeBoolToBoolean(i.(*Integer).Value == other.Value), nil
+	default:
+		return FALSE, nil
+	}
+}
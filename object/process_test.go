@@ -0,0 +1,73 @@
+package object
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	var stderr bytes.Buffer
+	streams := &Streams{Stdin: NewIO(strings.NewReader("")), Stdout: &bytes.Buffer{}, Stderr: &stderr}
+
+	output, status, err := (*Runtime)(nil).RunCommand(context.Background(), streams, "echo out; echo err >&2; exit 2")
+	checkError(t, err, nil)
+	checkResult(t, output, &String{Value: "out\n"})
+	if stderr.String() != "err\n" {
+		t.Errorf("Expected the errors to be written to stderr, got %q", stderr.String())
+	}
+	exitstatus, err := Send(status, "exitstatus")
+	checkError(t, err, nil)
+	checkResult(t, exitstatus, NewInteger(2))
+	success, err := Send(status, "success?")
+	checkError(t, err, nil)
+	checkResult(t, success, FALSE)
+
+	_, status, err = (*Runtime)(nil).RunCommand(context.Background(), streams, "goruby_missing_command")
+	if err, ok := err.(*SystemCallError); !ok || err.Class() != errnoClasses[syscall.ENOENT] {
+		t.Errorf("Expected Errno::ENOENT, got %v", err)
+	}
+	if status.ExitStatus != 127 {
+		t.Errorf("Expected exit status 127, got %d", status.ExitStatus)
+	}
+}
+
+func TestRunSystem(t *testing.T) {
+	var stdout bytes.Buffer
+	streams := &Streams{Stdin: NewIO(strings.NewReader("")), Stdout: &stdout, Stderr: &bytes.Buffer{}}
+
+	result, status, err := (*Runtime)(nil).RunSystem(context.Background(), streams, &String{Value: "printf"}, &String{Value: "%s|"}, &String{Value: "a b"}, &String{Value: "$HOME"})
+	checkError(t, err, nil)
+	checkResult(t, result, TRUE)
+	if stdout.String() != "a b|$HOME|" {
+		t.Errorf("Expected the arguments to be passed as they are, got %q", stdout.String())
+	}
+	if !status.Success() {
+		t.Errorf("Expected the command to succeed, got %s", status.Inspect())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, _, err = (*Runtime)(nil).RunSystem(ctx, streams, &String{Value: "sleep 5"})
+	checkError(t, err, nil)
+	checkResult(t, result, NIL)
+}
+
+func TestProcessStatus(t *testing.T) {
+	status := &ProcessStatus{Pid: 42, ExitStatus: 1}
+	checkResult(t, &String{Value: status.Inspect()}, &String{Value: "#<Process::Status: pid 42 exit 1>"})
+	toI, err := Send(status, "to_i")
+	checkError(t, err, nil)
+	checkResult(t, toI, NewInteger(256))
+
+	killed := &ProcessStatus{Pid: 42, ExitStatus: -1, Signal: 9}
+	checkResult(t, &String{Value: killed.Inspect()}, &String{Value: "#<Process::Status: pid 42 SIGKILL (signal 9)>"})
+	exitstatus, err := Send(killed, "exitstatus")
+	checkError(t, err, nil)
+	checkResult(t, exitstatus, NIL)
+	termsig, err := Send(killed, "termsig")
+	checkError(t, err, nil)
+	checkResult(t, termsig, NewInteger(9))
+}
@@ -0,0 +1,209 @@
+package object
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goruby/goruby/profile"
+)
+
+var profilerModule = newModule("Profiler", profilerMethods)
+
+func init() {
+	classes.Set("Profiler", profilerModule)
+}
+
+// profiler holds the profile being recorded and the last one stopped. The
+// goroutine of the ticker sets due every interval, the evaluator samples
+// its call stack between statements once it is set. As the ticker may run
+// late, the samples are weighted by the intervals elapsed since sampled,
+// the time of the last sample.
+var profiler struct {
+	sync.Mutex
+	running *profile.Profile
+	last    *profile.Profile
+	stop    chan struct{}
+	due     int32
+	sampled time.Time
+}
+
+// StartProfiling starts recording a profile sampling the call stack every
+// interval. It returns a RuntimeError if a profile is recorded already.
+func StartProfiling(interval time.Duration) error {
+	if interval <= 0 {
+		return NewArgumentError("interval must be positive")
+	}
+	profiler.Lock()
+	defer profiler.Unlock()
+	if profiler.running != nil {
+		return NewRuntimeError("profiler already running")
+	}
+	profiler.running = profile.New(interval)
+	profiler.stop = make(chan struct{})
+	profiler.sampled = profiler.running.Start
+	go func(ticker *time.Ticker, stop chan struct{}) {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				atomic.StoreInt32(&profiler.due, 1)
+			case <-stop:
+				return
+			}
+		}
+	}(time.NewTicker(interval), profiler.stop)
+	return nil
+}
+
+// StopProfiling stops recording the profile and returns it, or nil if no
+// profile is recorded.
+func StopProfiling() *profile.Profile {
+	profiler.Lock()
+	defer profiler.Unlock()
+	p := profiler.running
+	if p == nil {
+		return nil
+	}
+	close(profiler.stop)
+	p.Duration = time.Since(p.Start)
+	profiler.running, profiler.last = nil, p
+	return p
+}
+
+// SampleDue returns the number of intervals elapsed since the last sample
+// once a sample is due while a profile is recorded. The evaluator checks it
+// between statements and records a sample weighted by it if it is not
+// zero, so that a statement running for several intervals counts for each
+// of them.
+func SampleDue() int64 {
+	if atomic.LoadInt32(&profiler.due) == 0 || !atomic.CompareAndSwapInt32(&profiler.due, 1, 0) {
+		return 0
+	}
+	profiler.Lock()
+	defer profiler.Unlock()
+	if profiler.running == nil {
+		return 0
+	}
+	interval := profiler.running.Interval
+	count := int64(time.Since(profiler.sampled) / interval)
+	profiler.sampled = profiler.sampled.Add(time.Duration(count) * interval)
+	return count
+}
+
+// RecordSample adds count samples of stack, outermost frame first, to the
+// profile being recorded
+func RecordSample(stack []profile.Frame, count int64) {
+	profiler.Lock()
+	defer profiler.Unlock()
+	if profiler.running != nil {
+		profiler.running.Add(stack, count)
+	}
+}
+
+var profilerMethods = map[string]RubyMethod{
+	"start":       withArityRange(0, 1, publicMethod(profilerStart)),
+	"stop":        withArity(0, publicMethod(profilerStop)),
+	"running?":    withArity(0, publicMethod(profilerIsRunning)),
+	"profile":     withArityRange(0, 1, publicMethod(profilerProfile)),
+	"report":      withArity(0, publicMethod(profilerReport)),
+	"write_pprof": withArity(1, publicMethod(profilerWritePprof)),
+}
+
+// profilerStart starts recording a profile, sampling every interval given
+// in seconds, and returns true
+func profilerStart(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	interval := profile.DefaultInterval
+	if len(args) == 1 {
+		seconds, ok := toFloat(args[0])
+		if !ok {
+			return nil, NewTypeError("can't convert %s into time interval", comparisonOperandName(args[0]))
+		}
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+	if err := StartProfiling(interval); err != nil {
+		return nil, err
+	}
+	return TRUE, nil
+}
+
+// profilerStop stops recording the profile and returns its report, or nil
+// if no profile is recorded
+func profilerStop(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	p := StopProfiling()
+	if p == nil {
+		return NIL, nil
+	}
+	return profileReport(p)
+}
+
+func profilerIsRunning(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	profiler.Lock()
+	defer profiler.Unlock()
+	return nativeBoolToBoolean(profiler.running != nil), nil
+}
+
+// profilerProfile records a profile while the block runs and returns its
+// report
+func profilerProfile(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("no block given")
+	}
+	if _, err := profilerStart(context, args...); err != nil {
+		return nil, err
+	}
+	_, err := block.Call()
+	p := StopProfiling()
+	if err != nil {
+		return nil, err
+	}
+	return profileReport(p)
+}
+
+// profilerReport returns the report of the profile stopped last, or nil if
+// there is none
+func profilerReport(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	profiler.Lock()
+	p := profiler.last
+	profiler.Unlock()
+	if p == nil {
+		return NIL, nil
+	}
+	return profileReport(p)
+}
+
+func profileReport(p *profile.Profile) (RubyObject, error) {
+	var out strings.Builder
+	if err := p.WriteReport(&out); err != nil {
+		return nil, NewRuntimeError("%s", err)
+	}
+	return &String{Value: out.String()}, nil
+}
+
+// profilerWritePprof writes the profile stopped last to the file at the
+// given path in the format of pprof
+func profilerWritePprof(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	path, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	profiler.Lock()
+	p := profiler.last
+	profiler.Unlock()
+	if p == nil {
+		return nil, NewRuntimeError("no profile recorded")
+	}
+	file, err := os.Create(path.Value)
+	if err != nil {
+		return nil, NewSystemCallError(err)
+	}
+	defer file.Close()
+	if err := p.WritePprof(file); err != nil {
+		return nil, NewSystemCallError(err)
+	}
+	return path, nil
+}
@@ -0,0 +1,53 @@
+package object
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goruby/goruby/profile"
+)
+
+func TestProfiling(t *testing.T) {
+	if err := StartProfiling(time.Hour); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := StartProfiling(time.Hour); err == nil {
+		t.Errorf("Expected an error starting the profiler twice")
+	}
+	if count := SampleDue(); count != 0 {
+		t.Errorf("Expected no sample to be due, got %d", count)
+	}
+	RecordSample([]profile.Frame{{Function: "<main>"}, {Function: "foo"}}, 2)
+
+	p := StopProfiling()
+
+	if p == nil || p.Samples() != 2 {
+		t.Fatalf("Expected a profile of 2 samples, got %v", p)
+	}
+	if StopProfiling() != nil {
+		t.Errorf("Expected no profile when stopping the profiler twice")
+	}
+	report, err := profilerReport(profilerModule)
+	checkError(t, err, nil)
+	if str, ok := report.(*String); !ok || str.Value[:13] != "Flat profile:" {
+		t.Errorf("Expected the report of the last profile, got %v", report.Inspect())
+	}
+}
+
+func TestSampleDue(t *testing.T) {
+	if err := StartProfiling(time.Millisecond); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer StopProfiling()
+
+	time.Sleep(5 * time.Millisecond)
+	var count int64
+	deadline := time.Now().Add(time.Second)
+	for count == 0 && time.Now().Before(deadline) {
+		count = SampleDue()
+	}
+
+	if count < 1 {
+		t.Errorf("Expected the intervals elapsed while sleeping to be due, got %d", count)
+	}
+}
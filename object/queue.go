@@ -0,0 +1,211 @@
+package object
+
+import "fmt"
+
+var queueClass RubyClassObject = newClass("Thread::Queue", objectClass, queueMethods, queueClassMethods)
+
+var sizedQueueClass RubyClassObject = newClass("Thread::SizedQueue", queueClass, sizedQueueMethods, sizedQueueClassMethods)
+
+func init() {
+	classes.Set("Queue", queueClass)
+	classes.Set("SizedQueue", sizedQueueClass)
+	setConstant(threadClass, "Queue", queueClass)
+	setConstant(threadClass, "SizedQueue", sizedQueueClass)
+}
+
+// A Queue represents a Ruby Queue or SizedQueue, passing objects between
+// Threads in first in first out order
+type Queue struct {
+	items  []RubyObject
+	closed bool
+	// max limits the number of items of a SizedQueue, it is zero for a
+	// Queue
+	max     int
+	popping waitQueue
+	pushing waitQueue
+}
+
+// Type returns QUEUE_OBJ
+func (q *Queue) Type() Type { return QUEUE_OBJ }
+
+// Inspect returns the class and object id of the Queue
+func (q *Queue) Inspect() string {
+	return fmt.Sprintf("#<%s:0x%016x>", q.Class().(RubyObject).Inspect(), ObjectID(q))
+}
+
+// Class returns sizedQueueClass for a SizedQueue, queueClass otherwise
+func (q *Queue) Class() RubyClass {
+	if q.max > 0 {
+		return sizedQueueClass
+	}
+	return queueClass
+}
+
+// full reports whether pushing to q has to wait for an item to be popped
+func (q *Queue) full() bool {
+	return q.max > 0 && len(q.items) >= q.max
+}
+
+// push appends obj, waiting for space in a SizedQueue unless nonBlock is
+// set
+func (q *Queue) push(r *Runtime, obj RubyObject, nonBlock bool) error {
+	for {
+		if q.closed {
+			return NewClosedQueueError()
+		}
+		if !q.full() {
+			break
+		}
+		if nonBlock {
+			return NewThreadError("queue full")
+		}
+		if _, err := q.pushing.wait(r, -1); err != nil {
+			return err
+		}
+	}
+	q.items = append(q.items, obj)
+	q.popping.signal()
+	return nil
+}
+
+// pop removes and returns the first item, waiting for one to be pushed
+// unless nonBlock is set. A closed and empty Queue returns nil.
+func (q *Queue) pop(r *Runtime, nonBlock bool) (RubyObject, error) {
+	for len(q.items) == 0 {
+		if q.closed {
+			return NIL, nil
+		}
+		if nonBlock {
+			return nil, NewThreadError("queue empty")
+		}
+		if _, err := q.popping.wait(r, -1); err != nil {
+			return nil, err
+		}
+	}
+	item := q.items[0]
+	q.items = q.items[1:]
+	q.pushing.signal()
+	return item, nil
+}
+
+var queueClassMethods = map[string]RubyMethod{
+	"new": withArityRange(0, 1, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		queue := &Queue{}
+		if len(args) == 1 {
+			items, ok, err := convertWith(args[0], &Array{}, "to_a")
+			if !ok {
+				return nil, NewTypeError("can't convert %s into Array", args[0].Class().(RubyObject).Inspect())
+			}
+			if err != nil {
+				return nil, err
+			}
+			queue.items = NewArray(items.(*Array).Elements...).Elements
+		}
+		return queue, nil
+	})),
+}
+
+var queueMethods = map[string]RubyMethod{
+	"push":        withArityRange(1, 2, publicRuntimeMethod(queuePush)),
+	"<<":          withArityRange(1, 2, publicRuntimeMethod(queuePush)),
+	"enq":         withArityRange(1, 2, publicRuntimeMethod(queuePush)),
+	"pop":         withArityRange(0, 1, publicRuntimeMethod(queuePop)),
+	"shift":       withArityRange(0, 1, publicRuntimeMethod(queuePop)),
+	"deq":         withArityRange(0, 1, publicRuntimeMethod(queuePop)),
+	"size":        withArity(0, publicMethod(queueSize)),
+	"length":      withArity(0, publicMethod(queueSize)),
+	"empty?":      withArity(0, publicMethod(queueEmpty)),
+	"clear":       withArity(0, publicMethod(queueClear)),
+	"close":       withArity(0, publicMethod(queueClose)),
+	"closed?":     withArity(0, publicMethod(queueClosed)),
+	"num_waiting": withArity(0, publicMethod(queueNumWaiting)),
+}
+
+func queuePush(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	nonBlock := len(args) == 2 && isTruthy(args[1])
+	if err := context.(*Queue).push(r, args[0], nonBlock); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+func queuePop(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	nonBlock := len(args) == 1 && isTruthy(args[0])
+	return context.(*Queue).pop(r, nonBlock)
+}
+
+func queueSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(len(context.(*Queue).items))), nil
+}
+
+func queueEmpty(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(len(context.(*Queue).items) == 0), nil
+}
+
+func queueClear(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	queue := context.(*Queue)
+	queue.items = nil
+	queue.pushing.broadcast()
+	return context, nil
+}
+
+// queueClose closes the Queue, so that pushing raises a ClosedQueueError
+// and Threads waiting to pop get nil
+func queueClose(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	queue := context.(*Queue)
+	queue.closed = true
+	queue.popping.broadcast()
+	queue.pushing.broadcast()
+	return context, nil
+}
+
+func queueClosed(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Queue).closed), nil
+}
+
+func queueNumWaiting(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	queue := context.(*Queue)
+	return NewInteger(int64(len(queue.popping.waiters) + len(queue.pushing.waiters))), nil
+}
+
+var sizedQueueClassMethods = map[string]RubyMethod{
+	"new": withArity(1, publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		max, err := queueMax(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &Queue{max: max}, nil
+	})),
+}
+
+var sizedQueueMethods = map[string]RubyMethod{
+	"max":  withArity(0, publicMethod(sizedQueueMax)),
+	"max=": withArity(1, publicMethod(sizedQueueSetMax)),
+}
+
+// queueMax returns the positive size limit of a SizedQueue
+func queueMax(arg RubyObject) (int, error) {
+	max, err := integerArgument(arg)
+	if err != nil {
+		return 0, err
+	}
+	if max.Value <= 0 {
+		return 0, NewArgumentError("queue size must be positive")
+	}
+	return int(max.Value), nil
+}
+
+func sizedQueueMax(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*Queue).max)), nil
+}
+
+func sizedQueueSetMax(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	queue := context.(*Queue)
+	max, err := queueMax(args[0])
+	if err != nil {
+		return nil, err
+	}
+	queue.max = max
+	queue.pushing.broadcast()
+	return args[0], nil
+}
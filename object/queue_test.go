@@ -0,0 +1,70 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	t.Run("first in first out", func(t *testing.T) {
+		queue := &Queue{}
+
+		for i := int64(1); i <= 3; i++ {
+			_, err := Send(queue, "push", NewInteger(i))
+			checkError(t, err, nil)
+		}
+
+		size, err := Send(queue, "size")
+		checkError(t, err, nil)
+		checkResult(t, size, NewInteger(3))
+
+		for i := int64(1); i <= 3; i++ {
+			item, err := Send(queue, "pop")
+			checkError(t, err, nil)
+			checkResult(t, item, NewInteger(i))
+		}
+	})
+	t.Run("pop waits for push", func(t *testing.T) {
+		queue := &Queue{}
+		thread, err := Send(threadClass, "new", newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return Send(queue, "push", NewInteger(5))
+		}))
+		checkError(t, err, nil)
+
+		item, err := Send(queue, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NewInteger(5))
+
+		_, err = Send(thread, "join")
+		checkError(t, err, nil)
+	})
+	t.Run("closed", func(t *testing.T) {
+		queue := &Queue{items: []RubyObject{NewInteger(1)}}
+
+		_, err := Send(queue, "close")
+		checkError(t, err, nil)
+
+		_, err = Send(queue, "push", NewInteger(2))
+		checkError(t, err, NewClosedQueueError())
+
+		item, err := Send(queue, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NewInteger(1))
+
+		item, err = Send(queue, "pop")
+		checkError(t, err, nil)
+		checkResult(t, item, NIL)
+	})
+	t.Run("sized queue", func(t *testing.T) {
+		queue, err := Send(sizedQueueClass, "new", NewInteger(1))
+		checkError(t, err, nil)
+
+		_, err = Send(queue, "push", NewInteger(1))
+		checkError(t, err, nil)
+
+		_, err = Send(queue, "push", NewInteger(2), TRUE)
+		checkError(t, err, NewThreadError("queue full"))
+
+		_, err = Send(sizedQueueClass, "new", NewInteger(0))
+		checkError(t, err, NewArgumentError("queue size must be positive"))
+	})
+}
@@ -0,0 +1,266 @@
+package object
+
+import "math"
+
+var rangeClass RubyClassObject = newClass("Range", objectClass, rangeMethods, nil)
+
+func init() {
+	classes.Set("Range", rangeClass)
+}
+
+// NewRange returns a new Range from first to last, excluding last if
+// exclusive is true. It returns an ArgumentError if first and last cannot be
+// compared with each other.
+func NewRange(first, last RubyObject, exclusive bool) (*Range, error) {
+	_, firstIsInt := first.(*Integer)
+	_, lastIsInt := last.(*Integer)
+	if !firstIsInt || !lastIsInt {
+		if _, err := Compare(first, last); err != nil {
+			return nil, NewArgumentError("bad value for range")
+		}
+	}
+	return &Range{First: first, Last: last, Exclusive: exclusive}, nil
+}
+
+// Range represents a Ruby Range like `1..5`
+type Range struct {
+	First     RubyObject
+	Last      RubyObject
+	Exclusive bool
+}
+
+// Inspect returns the inspected bounds joined by `..` or `...`
+func (r *Range) Inspect() string {
+	return r.First.Inspect() + r.operator() + r.Last.Inspect()
+}
+
+// Type returns RANGE_OBJ
+func (r *Range) Type() Type { return RANGE_OBJ }
+
+// Class returns rangeClass
+func (r *Range) Class() RubyClass { return rangeClass }
+
+func (r *Range) operator() string {
+	if r.Exclusive {
+		return "..."
+	}
+	return ".."
+}
+
+// Include reports whether obj lies between the bounds of r
+func (r *Range) Include(obj RubyObject) (bool, error) {
+	min, err := Compare(r.First, obj)
+	if err != nil {
+		return false, nil
+	}
+	if min > 0 {
+		return false, nil
+	}
+	max, err := Compare(obj, r.Last)
+	if err != nil {
+		return false, nil
+	}
+	if r.Exclusive {
+		return max < 0, nil
+	}
+	return max <= 0, nil
+}
+
+// infinite reports whether r ends at positive infinity
+func (r *Range) infinite() bool {
+	last, ok := r.Last.(*Float)
+	return ok && math.IsInf(last.Value, 1)
+}
+
+// integerBounds returns the bounds of r as ints with an exclusive end. It
+// returns a TypeError if r does not consist of Integers.
+func (r *Range) integerBounds() (int64, int64, error) {
+	first, ok := r.First.(*Integer)
+	if !ok {
+		return 0, 0, NewTypeError("can't iterate from %s", comparisonOperandName(r.First))
+	}
+	if r.infinite() {
+		return first.Value, math.MaxInt64, nil
+	}
+	last, err := integerArgument(r.Last)
+	if err != nil {
+		return 0, 0, err
+	}
+	end := last.Value
+	if !r.Exclusive {
+		end++
+	}
+	return first.Value, end, nil
+}
+
+// indices resolves r against a sequence of the given length the way
+// String#[] and Array#[] do: negative bounds count from the end. It returns
+// the start offset and the number of elements covered, or false if r starts
+// outside of the sequence.
+func (r *Range) indices(length int) (int, int, bool, error) {
+	first, err := integerArgument(r.First)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	last, err := integerArgument(r.Last)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	start, end := int(first.Value), int(last.Value)
+	if start < 0 {
+		start += length
+	}
+	if start < 0 || start > length {
+		return 0, 0, false, nil
+	}
+	if end < 0 {
+		end += length
+	}
+	if !r.Exclusive {
+		end++
+	}
+	if end > length {
+		end = length
+	}
+	count := end - start
+	if count < 0 {
+		count = 0
+	}
+	return start, count, true, nil
+}
+
+var rangeMethods = map[string]RubyMethod{
+	"first":        withArity(0, publicMethod(rangeFirst)),
+	"begin":        withArity(0, publicMethod(rangeFirst)),
+	"last":         withArity(0, publicMethod(rangeLast)),
+	"end":          withArity(0, publicMethod(rangeLast)),
+	"exclude_end?": withArity(0, publicMethod(rangeExcludeEnd)),
+	"include?":     withArity(1, publicMethod(rangeInclude)),
+	"member?":      withArity(1, publicMethod(rangeInclude)),
+	"cover?":       withArity(1, publicMethod(rangeInclude)),
+	"===":          withArity(1, publicMethod(rangeInclude)),
+	"==":           withArity(1, publicMethod(rangeEqual)),
+	"eql?":         withArity(1, publicMethod(rangeEql)),
+	"hash":         withArity(0, publicMethod(rangeHash)),
+	"size":         withArity(0, publicMethod(rangeSize)),
+	"to_a":         withArity(0, publicMethod(rangeToA)),
+	"each":         withArity(0, publicMethod(rangeEach)),
+	"to_s":         withArity(0, publicMethod(rangeToS)),
+	"lazy":         withArity(0, publicMethod(enumerableLazy)),
+}
+
+func rangeFirst(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Range).First, nil
+}
+
+func rangeLast(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*Range).Last, nil
+}
+
+func rangeExcludeEnd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Range).Exclusive), nil
+}
+
+func rangeInclude(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	include, err := context.(*Range).Include(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(include), nil
+}
+
+func rangeSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	first, end, err := context.(*Range).integerBounds()
+	if err != nil {
+		return NIL, nil
+	}
+	if context.(*Range).infinite() {
+		return NewFloat(math.Inf(1)), nil
+	}
+	if end < first {
+		return NewInteger(0), nil
+	}
+	return NewInteger(end - first), nil
+}
+
+func rangeToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	first, end, err := context.(*Range).integerBounds()
+	if err != nil {
+		return nil, err
+	}
+	if context.(*Range).infinite() {
+		return nil, NewRangeError("cannot convert endless range to an array")
+	}
+	array := NewArray()
+	for i := first; i < end; i++ {
+		array.Elements = append(array.Elements, NewInteger(i))
+	}
+	return array, nil
+}
+
+func rangeEach(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	if block == nil {
+		return NewEnumerator(context, "each"), nil
+	}
+	first, end, err := context.(*Range).integerBounds()
+	if err != nil {
+		return nil, err
+	}
+	for i := first; i < end; i++ {
+		if _, err := block.Call(NewInteger(i)); err != nil {
+			return nil, err
+		}
+	}
+	return context, nil
+}
+
+func rangeToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	r := context.(*Range)
+	return &String{Value: toS(r.First) + r.operator() + toS(r.Last)}, nil
+}
+
+func rangeEqual(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareRanges(context.(*Range), args[0], isEqual)
+}
+
+func rangeEql(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return compareRanges(context.(*Range), args[0], isEql)
+}
+
+// compareRanges reports whether other is a Range with the same exclusivity
+// as r and bounds which are equal according to equal
+func compareRanges(r *Range, other RubyObject, equal func(a, b RubyObject) (bool, error)) (RubyObject, error) {
+	otherRange, ok := other.(*Range)
+	if !ok || r.Exclusive != otherRange.Exclusive {
+		return FALSE, nil
+	}
+	for _, bounds := range [][2]RubyObject{{r.First, otherRange.First}, {r.Last, otherRange.Last}} {
+		isEqual, err := equal(bounds[0], bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		if !isEqual {
+			return FALSE, nil
+		}
+	}
+	return TRUE, nil
+}
+
+// rangeHash combines the hash values of both bounds and the exclusivity
+func rangeHash(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	r := context.(*Range)
+	first, err := sendHash(r.First)
+	if err != nil {
+		return nil, err
+	}
+	last, err := sendHash(r.Last)
+	if err != nil {
+		return nil, err
+	}
+	result := first*31 + last
+	if r.Exclusive {
+		result = ^result
+	}
+	return NewInteger(result), nil
+}
@@ -0,0 +1,68 @@
+package object
+
+import "testing"
+
+func TestNewRange(t *testing.T) {
+	t.Run("integers", func(t *testing.T) {
+		r, err := NewRange(NewInteger(1), NewInteger(3), true)
+
+		checkError(t, err, nil)
+		if r.Inspect() != "1...3" {
+			t.Logf("Expected Inspect to return %q, got %q", "1...3", r.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("strings", func(t *testing.T) {
+		r, err := NewRange(&String{Value: "a"}, &String{Value: "c"}, false)
+
+		checkError(t, err, nil)
+		if r.Inspect() != "a..c" {
+			t.Logf("Expected Inspect to return %q, got %q", "a..c", r.Inspect())
+			t.Fail()
+		}
+	})
+	t.Run("incomparable bounds", func(t *testing.T) {
+		_, err := NewRange(NewInteger(1), &String{Value: "c"}, false)
+
+		checkError(t, err, NewArgumentError("bad value for range"))
+	})
+}
+
+func TestRangeMethods(t *testing.T) {
+	inclusive := &Range{First: NewInteger(1), Last: NewInteger(3)}
+	exclusive := &Range{First: NewInteger(1), Last: NewInteger(3), Exclusive: true}
+	floats := &Range{First: NewFloat(1.5), Last: NewFloat(2.5)}
+	empty := &Range{First: NewInteger(3), Last: NewInteger(1)}
+
+	tests := []struct {
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  *Range
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{rangeFirst, inclusive, nil, NewInteger(1)},
+		{rangeLast, exclusive, nil, NewInteger(3)},
+		{rangeExcludeEnd, exclusive, nil, TRUE},
+		{rangeInclude, inclusive, []RubyObject{NewInteger(3)}, TRUE},
+		{rangeInclude, exclusive, []RubyObject{NewInteger(3)}, FALSE},
+		{rangeInclude, floats, []RubyObject{NewInteger(2)}, TRUE},
+		{rangeInclude, inclusive, []RubyObject{&String{Value: "2"}}, FALSE},
+		{rangeSize, inclusive, nil, NewInteger(3)},
+		{rangeSize, exclusive, nil, NewInteger(2)},
+		{rangeSize, empty, nil, NewInteger(0)},
+		{rangeSize, floats, nil, NIL},
+		{rangeToA, exclusive, nil, NewArray(NewInteger(1), NewInteger(2))},
+		{rangeToA, empty, nil, NewArray()},
+		{rangeToS, exclusive, nil, &String{Value: "1...3"}},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.method(tt.context, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	_, err := rangeToA(floats)
+	checkError(t, err, NewTypeError("can't iterate from Float"))
+}
@@ -0,0 +1,609 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var regexpClass RubyClassObject = newClass("Regexp", objectClass, regexpMethods, regexpClassMethods)
+var matchDataClass RubyClassObject = newClass("MatchData", objectClass, matchDataMethods, nil)
+
+func init() {
+	classes.Set("Regexp", regexpClass)
+	classes.Set("MatchData", matchDataClass)
+}
+
+// NewRegexp compiles source with the given options into a Regexp. Options
+// can contain the letters `i` (ignore case), `m` (dot matches newline) and
+// `x` (extended, ignore whitespace and comments). It returns a RegexpError if
+// source is not a valid regular expression.
+func NewRegexp(source, options string) (*Regexp, error) {
+	translated, err := translateRegexp(source, options)
+	if err != nil {
+		return nil, NewRegexpError("%s: /%s/", err, source)
+	}
+	re, err := regexp.Compile(translated)
+	if err != nil {
+		return nil, NewRegexpError("%s: /%s/", regexpErrorMessage(err), source)
+	}
+	return &Regexp{Source: source, Options: options, regexp: re}, nil
+}
+
+func regexpErrorMessage(err error) string {
+	if syntaxErr, ok := err.(*syntax.Error); ok {
+		return string(syntaxErr.Code)
+	}
+	return err.Error()
+}
+
+// Regexp represents a regular expression in Ruby
+type Regexp struct {
+	Source  string
+	Options string
+	regexp  *regexp.Regexp
+}
+
+// Inspect returns the regexp in its literal form
+func (r *Regexp) Inspect() string { return "/" + r.Source + "/" + r.Options }
+
+// Type returns REGEXP_OBJ
+func (r *Regexp) Type() Type { return REGEXP_OBJ }
+
+// Class returns regexpClass
+func (r *Regexp) Class() RubyClass { return regexpClass }
+
+func (r *Regexp) hashKey() hashKey {
+	return hashKey{Type: r.Type(), Value: r.Inspect()}
+}
+
+// Match matches the regexp against s, starting at the byte offset pos. It
+// returns nil if the regexp does not match.
+func (r *Regexp) Match(s string, pos int) *MatchData {
+	loc := r.regexp.FindStringSubmatchIndex(s[pos:])
+	if loc == nil {
+		return nil
+	}
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += pos
+		}
+	}
+	return &MatchData{Regexp: r, Target: s, offsets: loc}
+}
+
+// LastMatch returns the result of the last match of a Regexp within the
+// method the current Thread of r runs, which `$~` refers to, or nil if
+// there was none or it failed
+func (r *Runtime) LastMatch() *MatchData {
+	return r.threadState().currentThread().lastMatch
+}
+
+// SetLastMatch sets the result of the last match of the current Thread of r
+// and returns the previous one. Methods defined in Ruby use it to start with
+// no match and to restore the one of their caller when they return.
+func (r *Runtime) SetLastMatch(match *MatchData) *MatchData {
+	thread := r.threadState().currentThread()
+	previous := thread.lastMatch
+	thread.lastMatch = match
+	return previous
+}
+
+// MatchAll returns all successive non overlapping matches within s
+func (r *Regexp) MatchAll(s string) []*MatchData {
+	var matches []*MatchData
+	for _, loc := range r.regexp.FindAllStringSubmatchIndex(s, -1) {
+		matches = append(matches, &MatchData{Regexp: r, Target: s, offsets: loc})
+	}
+	return matches
+}
+
+// translateRegexp converts the Ruby regexp source into the syntax of the Go
+// regexp package
+func translateRegexp(source, options string) (string, error) {
+	// In Ruby `^` and `$` always match at line boundaries
+	flags := "m"
+	if strings.Contains(options, "i") {
+		flags += "i"
+	}
+	if strings.Contains(options, "m") {
+		flags += "s"
+	}
+	extended := strings.Contains(options, "x")
+
+	var out bytes.Buffer
+	out.WriteString("(?" + flags + ")")
+	inClass := false
+	// like in Ruby, groups without a name do not capture if any group has
+	// one, so that the numbers of the groups are the ones of named groups
+	named := false
+	var unnamedGroups []int
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(source) {
+				return "", fmt.Errorf("too short escape sequence")
+			}
+			i++
+			n, err := translateRegexpEscape(&out, source[i:], inClass)
+			if err != nil {
+				return "", err
+			}
+			i += n - 1
+		case c == '[':
+			inClass = true
+			out.WriteByte(c)
+			if strings.HasPrefix(source[i+1:], "^]") || strings.HasPrefix(source[i+1:], "]") {
+				j := strings.IndexByte(source[i+1:], ']')
+				out.WriteString(source[i+1 : i+2+j])
+				i += j + 1
+			}
+		case c == ']' && inClass:
+			inClass = false
+			out.WriteByte(c)
+		case c == '(' && !inClass && strings.HasPrefix(source[i:], "(?<") &&
+			!strings.HasPrefix(source[i:], "(?<=") && !strings.HasPrefix(source[i:], "(?<!"):
+			out.WriteString("(?P<")
+			i += len("(?<") - 1
+			named = true
+		case c == '(' && !inClass && !strings.HasPrefix(source[i:], "(?"):
+			unnamedGroups = append(unnamedGroups, out.Len())
+			out.WriteByte(c)
+		case extended && !inClass && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+		case extended && !inClass && c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	translated := out.String()
+	if named {
+		for i := len(unnamedGroups) - 1; i >= 0; i-- {
+			offset := unnamedGroups[i]
+			translated = translated[:offset] + "(?:" + translated[offset+1:]
+		}
+	}
+	return translated, nil
+}
+
+// translateRegexpEscape writes the Go equivalent of the escape sequence at
+// the start of s, i.e. following a backslash, to out. It returns the number of
+// consumed bytes.
+func translateRegexpEscape(out *bytes.Buffer, s string, inClass bool) (int, error) {
+	hexDigits := "0-9a-fA-F"
+	switch s[0] {
+	case 'h':
+		if inClass {
+			out.WriteString(hexDigits)
+		} else {
+			out.WriteString("[" + hexDigits + "]")
+		}
+		return 1, nil
+	case 'H':
+		out.WriteString("[^" + hexDigits + "]")
+		return 1, nil
+	case 'Z':
+		out.WriteString(`(?:\n?\z)`)
+		return 1, nil
+	case '/':
+		out.WriteByte('/')
+		return 1, nil
+	case 'u':
+		if strings.HasPrefix(s, "u{") {
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return 0, fmt.Errorf("invalid Unicode escape")
+			}
+			out.WriteString(`\x` + s[1:end+1])
+			return end + 1, nil
+		}
+		if len(s) < 5 {
+			return 0, fmt.Errorf("invalid Unicode escape")
+		}
+		if _, err := strconv.ParseUint(s[1:5], 16, 32); err != nil {
+			return 0, fmt.Errorf("invalid Unicode escape")
+		}
+		out.WriteString(`\x{` + s[1:5] + `}`)
+		return 5, nil
+	default:
+		_, size := utf8.DecodeRuneInString(s)
+		out.WriteString(`\` + s[:size])
+		return size, nil
+	}
+}
+
+// regexpArgument returns arg as Regexp. Strings are converted into a Regexp
+// matching the string literally.
+func regexpArgument(arg RubyObject) (*Regexp, error) {
+	switch arg := arg.(type) {
+	case *Regexp:
+		return arg, nil
+	case *String:
+		return NewRegexp(regexp.QuoteMeta(arg.Value), "")
+	default:
+		return nil, NewImplicitConversionTypeError(&Regexp{}, arg)
+	}
+}
+
+var regexpClassMethods = map[string]RubyMethod{
+	"new":        withArityRange(1, 2, publicMethod(regexpNew)),
+	"escape":     withArity(1, publicMethod(regexpEscape)),
+	"last_match": withArityRange(0, 1, publicRuntimeMethod(regexpLastMatch)),
+}
+
+var regexpMethods = map[string]RubyMethod{
+	"match":  withArityRange(1, 2, publicRuntimeMethod(regexpMatch)),
+	"=~":     withArity(1, publicRuntimeMethod(regexpMatchOperator)),
+	"===":    withArity(1, publicRuntimeMethod(regexpCaseEqual)),
+	"source": withArity(0, publicMethod(regexpSource)),
+	"names":  withArity(0, publicMethod(regexpNames)),
+}
+
+func regexpNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	source, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	options := ""
+	if len(args) == 2 {
+		switch opt := args[1].(type) {
+		case *String:
+			options = opt.Value
+		case *Boolean:
+			if opt.Value {
+				options = "i"
+			}
+		case *nilObject:
+		default:
+			return nil, NewImplicitConversionTypeError(&String{}, opt)
+		}
+	}
+	return NewRegexp(source.Value, options)
+}
+
+func regexpEscape(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: regexp.QuoteMeta(str.Value)}, nil
+}
+
+// regexpLastMatch returns `$~`, or the group of it given by index or name
+func regexpLastMatch(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := r.LastMatch()
+	if match == nil {
+		return NIL, nil
+	}
+	if len(args) == 1 {
+		return matchDataIndex(match, args[0])
+	}
+	return match, nil
+}
+
+// regexpMatch matches the regexp against the string, starting at the
+// optional character index, and returns the MatchData, which `$~` is set
+// to, or nil
+func regexpMatch(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	re := context.(*Regexp)
+	if args[0] == NIL {
+		r.SetLastMatch(nil)
+		return NIL, nil
+	}
+	str, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	pos := 0
+	if len(args) == 2 {
+		start, err := integerArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		pos, ok = byteOffset(str.Value, int(start.Value))
+		if !ok {
+			return NIL, nil
+		}
+	}
+	match := re.Match(str.Value, pos)
+	r.SetLastMatch(match)
+	if match == nil {
+		return NIL, nil
+	}
+	return match, nil
+}
+
+// regexpMatchOperator returns the character index of the match within the
+// string, or nil, and sets `$~` like match
+func regexpMatchOperator(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	re := context.(*Regexp)
+	if args[0] == NIL {
+		r.SetLastMatch(nil)
+		return NIL, nil
+	}
+	str, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	match := re.Match(str.Value, 0)
+	r.SetLastMatch(match)
+	if match == nil {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.Begin(0))), nil
+}
+
+func regexpCaseEqual(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	re := context.(*Regexp)
+	str, ok := args[0].(*String)
+	if !ok {
+		return FALSE, nil
+	}
+	match := re.Match(str.Value, 0)
+	r.SetLastMatch(match)
+	return nativeBoolToBoolean(match != nil), nil
+}
+
+func regexpSource(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	re := context.(*Regexp)
+	return &String{Value: re.Source}, nil
+}
+
+func regexpNames(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	re := context.(*Regexp)
+	return NewArray(re.names()...), nil
+}
+
+// names returns the names of the named groups in the order they appear
+func (r *Regexp) names() []RubyObject {
+	var names []RubyObject
+	for _, name := range r.regexp.SubexpNames() {
+		if name != "" {
+			names = append(names, &String{Value: name})
+		}
+	}
+	return names
+}
+
+// byteOffset converts the character index into a byte offset within s.
+// Negative indexes count from the end. It returns false if the index is out
+// of range.
+func byteOffset(s string, index int) (int, bool) {
+	count := utf8.RuneCountInString(s)
+	if index < 0 {
+		index += count
+	}
+	if index < 0 || index > count {
+		return 0, false
+	}
+	offset := 0
+	for i := 0; i < index; i++ {
+		_, size := utf8.DecodeRuneInString(s[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// MatchData represents the result of matching a Regexp against a string
+type MatchData struct {
+	Regexp  *Regexp
+	Target  string
+	offsets []int // pairs of start and end byte offsets for every group
+}
+
+// Inspect returns the matched string and all captured groups
+func (m *MatchData) Inspect() string {
+	var out bytes.Buffer
+	out.WriteString("#<MatchData ")
+	out.WriteString(strconv.Quote(m.String()))
+	names := m.Regexp.regexp.SubexpNames()
+	for i := 1; i < m.Len(); i++ {
+		name := names[i]
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		group, ok := m.Group(i)
+		value := "nil"
+		if ok {
+			value = strconv.Quote(group)
+		}
+		out.WriteString(" " + name + ":" + value)
+	}
+	out.WriteString(">")
+	return out.String()
+}
+
+// Type returns MATCH_DATA_OBJ
+func (m *MatchData) Type() Type { return MATCH_DATA_OBJ }
+
+// Class returns matchDataClass
+func (m *MatchData) Class() RubyClass { return matchDataClass }
+
+// Len returns the number of groups including the whole match
+func (m *MatchData) Len() int { return len(m.offsets) / 2 }
+
+// String returns the matched string
+func (m *MatchData) String() string {
+	group, _ := m.Group(0)
+	return group
+}
+
+// Group returns the string captured by the ith group. It returns false if the
+// group did not participate in the match.
+func (m *MatchData) Group(i int) (string, bool) {
+	if i < 0 || i >= m.Len() || m.offsets[2*i] < 0 {
+		return "", false
+	}
+	return m.Target[m.offsets[2*i]:m.offsets[2*i+1]], true
+}
+
+// NamedGroup returns the string captured by the group called name
+func (m *MatchData) NamedGroup(name string) (string, bool, error) {
+	index := m.Regexp.regexp.SubexpIndex(name)
+	if index < 0 {
+		return "", false, NewIndexError("undefined group name reference: %s", name)
+	}
+	group, ok := m.Group(index)
+	return group, ok, nil
+}
+
+// Begin returns the character offset of the start of the ith group
+func (m *MatchData) Begin(i int) int {
+	return utf8.RuneCountInString(m.Target[:m.offsets[2*i]])
+}
+
+// groupObject returns the ith group as String or NIL
+func (m *MatchData) groupObject(i int) RubyObject {
+	group, ok := m.Group(i)
+	if !ok {
+		return NIL
+	}
+	return &String{Value: group}
+}
+
+// captures returns all captured groups, excluding the whole match
+func (m *MatchData) captures() []RubyObject {
+	groups := make([]RubyObject, 0, m.Len()-1)
+	for i := 1; i < m.Len(); i++ {
+		groups = append(groups, m.groupObject(i))
+	}
+	return groups
+}
+
+var matchDataMethods = map[string]RubyMethod{
+	"[]":             withArity(1, publicMethod(matchDataIndex)),
+	"to_a":           withArity(0, publicMethod(matchDataToA)),
+	"captures":       withArity(0, publicMethod(matchDataCaptures)),
+	"pre_match":      withArity(0, publicMethod(matchDataPreMatch)),
+	"post_match":     withArity(0, publicMethod(matchDataPostMatch)),
+	"to_s":           withArity(0, publicMethod(matchDataToS)),
+	"begin":          withArity(1, publicMethod(matchDataBegin)),
+	"end":            withArity(1, publicMethod(matchDataEnd)),
+	"size":           withArity(0, publicMethod(matchDataSize)),
+	"length":         withArity(0, publicMethod(matchDataSize)),
+	"names":          withArity(0, publicMethod(matchDataNames)),
+	"named_captures": withArity(0, publicMethod(matchDataNamedCaptures)),
+	"string":         withArity(0, publicMethod(matchDataString)),
+	"regexp":         withArity(0, publicMethod(matchDataRegexp)),
+}
+
+func matchDataIndex(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	var name string
+	switch arg := args[0].(type) {
+	case *Integer:
+		index := int(arg.Value)
+		if index < 0 {
+			index += match.Len()
+		}
+		return match.groupObject(index), nil
+	case *String:
+		name = arg.Value
+	case *Symbol:
+		name = arg.Value
+	default:
+		return nil, NewImplicitConversionTypeError(&Integer{}, arg)
+	}
+	group, ok, err := match.NamedGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return NIL, nil
+	}
+	return &String{Value: group}, nil
+}
+
+func matchDataToA(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	return NewArray(append([]RubyObject{match.groupObject(0)}, match.captures()...)...), nil
+}
+
+func matchDataCaptures(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	return NewArray(match.captures()...), nil
+}
+
+func matchDataPreMatch(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	return &String{Value: match.Target[:match.offsets[0]]}, nil
+}
+
+func matchDataPostMatch(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	return &String{Value: match.Target[match.offsets[1]:]}, nil
+}
+
+func matchDataToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	return &String{Value: match.String()}, nil
+}
+
+func matchDataBegin(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	i := int(index.Value)
+	if i < 0 || i >= match.Len() {
+		return nil, NewIndexError("index %d out of matches", i)
+	}
+	if _, ok := match.Group(i); !ok {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.Begin(i))), nil
+}
+
+func matchDataEnd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	i := int(index.Value)
+	if i < 0 || i >= match.Len() {
+		return nil, NewIndexError("index %d out of matches", i)
+	}
+	group, ok := match.Group(i)
+	if !ok {
+		return NIL, nil
+	}
+	return NewInteger(int64(match.Begin(i) + utf8.RuneCountInString(group))), nil
+}
+
+func matchDataSize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(int64(context.(*MatchData).Len())), nil
+}
+
+func matchDataNames(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewArray(context.(*MatchData).Regexp.names()...), nil
+}
+
+// matchDataNamedCaptures returns a Hash of the named groups by their names
+func matchDataNamedCaptures(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	match := context.(*MatchData)
+	captures := NewHash(nil)
+	for i, name := range match.Regexp.regexp.SubexpNames() {
+		if name != "" {
+			captures.Set(&String{Value: name}, match.groupObject(i))
+		}
+	}
+	return captures, nil
+}
+
+func matchDataString(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*MatchData).Target}, nil
+}
+
+func matchDataRegexp(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*MatchData).Regexp, nil
+}
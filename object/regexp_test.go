@@ -0,0 +1,147 @@
+package object
+
+import "testing"
+
+func TestNewRegexp(t *testing.T) {
+	tests := []struct {
+		source  string
+		options string
+		input   string
+		matches bool
+	}{
+		{"abc", "", "xabcx", true},
+		{"^b", "", "a\nb", true},
+		{"ABC", "i", "abc", true},
+		{"a.c", "", "a\nc", false},
+		{"a.c", "m", "a\nc", true},
+		{"a b # comment\n c", "x", "abc", true},
+		{`\h+\Z`, "", "cafe\n", true},
+		{`[\h]`, "", "x", false},
+		{`a\/b`, "", "a/b", true},
+		{`é`, "", "é", true},
+		{`(?<word>\w+)`, "", "foo", true},
+		{`[]a]`, "", "]", true},
+	}
+
+	for _, tt := range tests {
+		re, err := NewRegexp(tt.source, tt.options)
+		checkError(t, err, nil)
+		if re == nil {
+			continue
+		}
+
+		match := re.Match(tt.input, 0)
+		if (match != nil) != tt.matches {
+			t.Logf("Expected /%s/%s matching %q to be %t", tt.source, tt.options, tt.input, tt.matches)
+			t.Fail()
+		}
+	}
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		_, err := NewRegexp("a(", "")
+
+		if _, ok := err.(*RegexpError); !ok {
+			t.Logf("Expected RegexpError, got %T:%v", err, err)
+			t.Fail()
+		}
+	})
+}
+
+func TestMatchData(t *testing.T) {
+	re, err := NewRegexp(`(?<key>\w+)=(?<value>\d+)?`, "")
+	checkError(t, err, nil)
+	match := re.Match("é foo= bar", 0)
+
+	tests := []struct {
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{matchDataToS, nil, &String{Value: "foo="}},
+		{matchDataIndex, []RubyObject{NewInteger(1)}, &String{Value: "foo"}},
+		{matchDataIndex, []RubyObject{NewInteger(2)}, NIL},
+		{matchDataIndex, []RubyObject{NewInteger(3)}, NIL},
+		{matchDataIndex, []RubyObject{&Symbol{Value: "key"}}, &String{Value: "foo"}},
+		{matchDataCaptures, nil, NewArray(&String{Value: "foo"}, NIL)},
+		{matchDataToA, nil, NewArray(&String{Value: "foo="}, &String{Value: "foo"}, NIL)},
+		{matchDataPreMatch, nil, &String{Value: "é "}},
+		{matchDataPostMatch, nil, &String{Value: " bar"}},
+		{matchDataBegin, []RubyObject{NewInteger(0)}, NewInteger(2)},
+		{matchDataEnd, []RubyObject{NewInteger(1)}, NewInteger(5)},
+		{matchDataEnd, []RubyObject{NewInteger(2)}, NIL},
+		{matchDataSize, nil, NewInteger(3)},
+		{matchDataNames, nil, NewArray(&String{Value: "key"}, &String{Value: "value"})},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.method(match, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	_, err = matchDataIndex(match, &String{Value: "missing"})
+	checkError(t, err, NewIndexError("undefined group name reference: missing"))
+
+	expectedInspect := `#<MatchData "foo=" key:"foo" value:nil>`
+	if match.Inspect() != expectedInspect {
+		t.Logf("Expected Inspect to return %q, got %q", expectedInspect, match.Inspect())
+		t.Fail()
+	}
+}
+
+func TestMatchDataWithNamedAndUnnamedGroups(t *testing.T) {
+	re, err := NewRegexp(`(?<key>\w+)(=)(\d+)`, "")
+	checkError(t, err, nil)
+	match := re.Match("foo=42", 0)
+
+	tests := []struct {
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{matchDataIndex, []RubyObject{NewInteger(1)}, &String{Value: "foo"}},
+		{matchDataIndex, []RubyObject{NewInteger(2)}, NIL},
+		{matchDataCaptures, nil, NewArray(&String{Value: "foo"})},
+		{matchDataToA, nil, NewArray(&String{Value: "foo=42"}, &String{Value: "foo"})},
+		{matchDataSize, nil, NewInteger(2)},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.method(match, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	expectedInspect := `#<MatchData "foo=42" key:"foo">`
+	if match.Inspect() != expectedInspect {
+		t.Logf("Expected Inspect to return %q, got %q", expectedInspect, match.Inspect())
+		t.Fail()
+	}
+}
+
+func TestRegexpMatchOperator(t *testing.T) {
+	re, err := NewRegexp("b", "")
+	checkError(t, err, nil)
+	runtime := RuntimeOf(NewMainEnvironment())
+
+	result, err := regexpMatchOperator(runtime, re, &String{Value: "äbc"})
+	checkError(t, err, nil)
+	checkResult(t, result, NewInteger(1))
+
+	if match := runtime.LastMatch(); match == nil || match.String() != "b" {
+		t.Errorf("Expected the last match to be set, got %v", match)
+	}
+
+	result, err = regexpMatchOperator(runtime, re, &String{Value: "xyz"})
+	checkError(t, err, nil)
+	checkResult(t, result, NIL)
+	if match := runtime.LastMatch(); match != nil {
+		t.Errorf("Expected the last match to be reset, got %s", match.Inspect())
+	}
+
+	result, err = regexpCaseEqual(runtime, re, NewInteger(1))
+	checkError(t, err, nil)
+	checkResult(t, result, FALSE)
+}
@@ -22,8 +22,34 @@ const (
 	CLASS_CLASS_OBJ        Type = "CLASS_CLASS"
 	ARRAY_OBJ              Type = "ARRAY"
 	ARRAY_CLASS_OBJ        Type = "ARRAY_CLASS"
+	IO_OBJ                 Type = "IO"
+	HASH_OBJ               Type = "HASH"
+	HASH_CLASS_OBJ         Type = "HASH_CLASS"
 	INTEGER_OBJ            Type = "INTEGER"
 	INTEGER_CLASS_OBJ      Type = "INTEGER_CLASS"
+	FLOAT_OBJ              Type = "FLOAT"
+	PROC_OBJ               Type = "PROC"
+	ENUMERATOR_OBJ         Type = "ENUMERATOR"
+	GENERATOR_OBJ          Type = "GENERATOR"
+	YIELDER_OBJ            Type = "YIELDER"
+	THREAD_OBJ             Type = "THREAD"
+	FIBER_OBJ              Type = "FIBER"
+	MUTEX_OBJ              Type = "MUTEX"
+	QUEUE_OBJ              Type = "QUEUE"
+	CONDITION_VARIABLE_OBJ Type = "CONDITION_VARIABLE"
+	REGEXP_OBJ             Type = "REGEXP"
+	MATCH_DATA_OBJ         Type = "MATCH_DATA"
+	RANGE_OBJ              Type = "RANGE"
+	TIME_OBJ               Type = "TIME"
+	GO_OBJ                 Type = "GO"
+	GO_CHANNEL_OBJ         Type = "GO_CHANNEL"
+	SOCKET_OBJ             Type = "SOCKET"
+	ERB_OBJ                Type = "ERB"
+	BINDING_OBJ            Type = "BINDING"
+	LOCATION_OBJ           Type = "LOCATION"
+	TRACE_POINT_OBJ        Type = "TRACE_POINT"
+	UNBOUND_METHOD_OBJ     Type = "UNBOUND_METHOD"
+	METHOD_OBJ             Type = "METHOD"
 	STRING_OBJ             Type = "STRING"
 	STRING_CLASS_OBJ       Type = "STRING_CLASS"
 	SYMBOL_OBJ             Type = "SYMBOL"
@@ -35,7 +61,6 @@ const (
 	EXCEPTION_CLASS_OBJ    Type = "EXCEPTION_CLASS"
 	MODULE_OBJ             Type = "MODULE"
 	MODULE_CLASS_OBJ       Type = "MODULE_CLASS"
-	BUILTIN_OBJ            Type = "BUILTIN"
 	SELF                   Type = "SELF"
 )
 
@@ -62,28 +87,6 @@ type RubyClassObject interface {
 	RubyClass
 }
 
-// A BuiltinFunction represents a function
-type BuiltinFunction func(args ...RubyObject) RubyObject
-
-// Builtin represents a builtin within the interpreter. It holds a function
-// which can be called directly. It is no real Ruby object.
-//
-// Ruby does not have any builtin functions as everything is bound to an object.
-//
-// This object will go away soon. Don't depend on it.
-type Builtin struct {
-	Fn BuiltinFunction
-}
-
-// Type returns BUILTIN_OBJ
-func (b *Builtin) Type() Type { return BUILTIN_OBJ }
-
-// Inspect returns 'buitin function'
-func (b *Builtin) Inspect() string { return "builtin function" }
-
-// Class returns nil
-func (b *Builtin) Class() RubyClass { return nil }
-
 // ReturnValue represents a wrapper object for a return statement. It is no
 // real Ruby object and only used within the interpreter evaluation
 type ReturnValue struct {
@@ -101,11 +104,20 @@ func (rv *ReturnValue) Class() RubyClass { return rv.Value.Class() }
 
 // A Function represents a user defined function. It is no real Ruby object.
 type Function struct {
+	Name             string
 	Parameters       []*ast.Identifier
 	Body             *ast.BlockStatement
 	Env              Environment
 	CallFn           func(context RubyObject, args []RubyObject) (RubyObject, error)
 	MethodVisibility MethodVisibility
+	// Line is the line the function has been defined at, if known
+	Line int
+	// File is the path of the file the function has been defined in, if
+	// known. Its body is evaluated with __FILE__ referring to it.
+	File *String
+	// Scope holds the local variables of Body if they have been resolved
+	// to slots
+	Scope *ast.Scope
 }
 
 // Type returns FUNCTION_OBJ
@@ -130,9 +142,18 @@ func (f *Function) Inspect() string {
 // Class returns nil
 func (f *Function) Class() RubyClass { return nil }
 
-// Call implements the RubyMethod interface. It calls f.CallFn
+// Call implements the RubyMethod interface. It calls f.CallFn with self
+// bound to context.
 func (f *Function) Call(context RubyObject, args ...RubyObject) (RubyObject, error) {
-	return f.CallFn(f, args)
+	return f.CallFn(f.Bind(context), args)
+}
+
+// Bind returns a copy of f whose environment has self bound to context
+func (f *Function) Bind(context RubyObject) *Function {
+	bound := *f
+	bound.Env = NewEnclosedEnvironment(f.Env)
+	bound.Env.Set("self", evalSelf(context, nil))
+	return &bound
 }
 
 // Visibility implements the RubyMethod interface. It returns f.MethodVisibility
@@ -140,6 +161,14 @@ func (f *Function) Visibility() MethodVisibility {
 	return f.MethodVisibility
 }
 
+func (f *Function) parameters() []parameter {
+	params := make([]parameter, len(f.Parameters))
+	for i, param := range f.Parameters {
+		params[i] = parameter{kind: "req", name: param.Value}
+	}
+	return params
+}
+
 // Self represents the value associated to `self`. It acts as a wrapper around
 // the RubyObject and is just meant to indicate that the given object is
 // self in the given context.
@@ -147,9 +176,37 @@ type Self struct {
 	RubyObject
 }
 
+// methodDefiner is implemented by objects methods can be defined in, like
+// classes and modules
+type methodDefiner interface {
+	addMethod(name string, method RubyMethod)
+}
+
 // Type returns SELF
 func (s *Self) Type() Type { return SELF }
 
+// unwrapSelf returns the object wrapped by obj if it is self, or obj
+// otherwise. Builtin methods always operate on the object itself.
+func unwrapSelf(obj RubyObject) RubyObject {
+	switch self := obj.(type) {
+	case *Self:
+		return self.RubyObject
+	case *definingSelf:
+		return self.RubyObject
+	default:
+		return obj
+	}
+}
+
+// definingSelf is self within blocks like the ones passed to class_eval,
+// where all defined methods are added to definee instead of the object
+// itself
+type definingSelf struct {
+	*Self
+	definee    methodDefiner
+	visibility MethodVisibility
+}
+
 // extendedObject is a wrapper object for an object extended by methods.
 type extendedObject struct {
 	RubyObject
@@ -0,0 +1,53 @@
+package object
+
+// A Runtime holds the state of the programs run within one main
+// environment, i.e. by one interpreter: the top level constants, the
+// constants defined within classes and modules, the methods defined within
+// the builtin classes, the autoloads, the Threads and the interruption.
+// Runtimes share none of it, so that interpreters running concurrently
+// within one process neither see nor race on the constants, methods or
+// Threads of each other, nor interrupt each other. Constants and methods
+// defined outside of any Runtime, like the builtin classes while the
+// package is initialized, are visible within all of them.
+//
+// The evaluator passes the Runtime of its environment to the builtin
+// methods needing it, see RuntimeOf. A nil *Runtime refers to the state of
+// everything run outside of any Runtime, which includes the constants and
+// methods shared by all of them.
+type Runtime struct {
+	// constants holds the top level constants and falls back to the
+	// shared ones
+	constants       *sharedEnvironment
+	moduleConstants constantTables
+	methods         methodOverlay
+	cache           methodCache
+	autoloads       autoloadTable
+	threads         threadState
+	interrupts      interruptState
+	abandoned       abandonedFibers
+}
+
+func newRuntime() *Runtime {
+	collectBuiltinTables()
+	r := &Runtime{
+		constants:       newSharedEnvironment(kernelFunctions),
+		moduleConstants: constantTables{tables: make(map[RubyObject]*constantTable)},
+		methods:         methodOverlay{tables: make(map[RubyClass]map[string]RubyMethod)},
+		autoloads:       autoloadTable{paths: make(map[string]map[string]string)},
+		interrupts:      newInterruptState(),
+	}
+	r.threads.init()
+	return r
+}
+
+// RuntimeOf returns the Runtime of the main environment env belongs to, or
+// nil if env is not enclosed by one
+func RuntimeOf(env Environment) *Runtime {
+	for env != nil {
+		if root, ok := env.(*runtimeEnvironment); ok {
+			return root.runtime
+		}
+		env = env.Outer()
+	}
+	return nil
+}
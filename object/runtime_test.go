@@ -0,0 +1,52 @@
+package object
+
+import "testing"
+
+func TestRuntimeConstants(t *testing.T) {
+	first, second := RuntimeOf(NewMainEnvironment()), RuntimeOf(NewMainEnvironment())
+	module := newModule("RuntimeTest", nil)
+
+	first.SetConstant("RUNTIME_TEST", NewInteger(1))
+	first.SetModuleConstant(module, "VALUE", NewInteger(2))
+
+	if value, ok := first.ModuleConstant(objectClass, "RUNTIME_TEST", false); !ok || value != NewInteger(1) {
+		t.Logf("Expected constant to be defined within its runtime, got %v", value)
+		t.Fail()
+	}
+	if _, ok := second.ModuleConstant(objectClass, "RUNTIME_TEST", false); ok {
+		t.Logf("Expected top level constant not to be visible within another runtime")
+		t.Fail()
+	}
+	if _, ok := second.ModuleConstant(module, "VALUE", false); ok {
+		t.Logf("Expected module constant not to be visible within another runtime")
+		t.Fail()
+	}
+	if _, ok := second.ModuleConstant(objectClass, "String", false); !ok {
+		t.Logf("Expected builtin classes to be visible within every runtime")
+		t.Fail()
+	}
+}
+
+func TestRuntimeMethods(t *testing.T) {
+	first, second := RuntimeOf(NewMainEnvironment()), RuntimeOf(NewMainEnvironment())
+	fn := &Function{MethodVisibility: PUBLIC_METHOD}
+
+	first.AddMethod(evalSelf(stringClass, stringClass.(methodDefiner)), "runtime_test", fn)
+
+	if _, ok := first.findMethod(&String{}, "runtime_test"); !ok {
+		t.Logf("Expected method to be defined within its runtime")
+		t.Fail()
+	}
+	if _, ok := second.findMethod(&String{}, "runtime_test"); ok {
+		t.Logf("Expected method not to be visible within another runtime")
+		t.Fail()
+	}
+	if _, ok := findMethod(&String{}, "runtime_test"); ok {
+		t.Logf("Expected method not to be visible outside of any runtime")
+		t.Fail()
+	}
+	if _, ok := stringClass.Methods()["runtime_test"]; ok {
+		t.Logf("Expected builtin class not to be changed")
+		t.Fail()
+	}
+}
@@ -1,47 +1,302 @@
 package object
 
-// Send sends message method with args to context and returns its result
+import "sync/atomic"
+
+// Send sends message method with args to context outside of any Runtime
+// and returns its result
 func Send(context RubyObject, method string, args ...RubyObject) (RubyObject, error) {
-	class := context.Class()
+	return (*Runtime)(nil).Send(context, method, args...)
+}
+
+// Send sends message method with args to context within r and returns its
+// result
+func (r *Runtime) Send(context RubyObject, method string, args ...RubyObject) (RubyObject, error) {
+	fn, ok := r.findMethod(context, method)
+	if !ok {
+		return r.sendMethodMissing(context, method, args...)
+	}
+
+	if fn.Visibility() == PRIVATE_METHOD && context.Type() != SELF {
+		return nil, NewPrivateNoMethodError(context, method)
+	}
+
+	return r.Call(fn, context, args...)
+}
+
+// SendFrom works like Send for calls with an explicit receiver made from
+// within caller. Protected methods can only be called if caller is a kind of
+// the class or module defining them.
+func SendFrom(caller, context RubyObject, method string, args ...RubyObject) (RubyObject, error) {
+	return (*Runtime)(nil).SendFrom(caller, context, method, args...)
+}
 
-	// search for the method in the ancestry tree
+// SendFrom works like Send within r for calls with an explicit receiver
+// made from within caller, see SendFrom
+func (r *Runtime) SendFrom(caller, context RubyObject, method string, args ...RubyObject) (RubyObject, error) {
+	fn, ok := r.findMethod(context, method)
+	if ok && fn.Visibility() == PROTECTED_METHOD && context.Type() != SELF &&
+		!r.isProtectedCallAllowed(caller, context, method) {
+		return nil, NewProtectedNoMethodError(context, method)
+	}
+	return r.Send(context, method, args...)
+}
+
+// MethodFrom returns the method SendFrom would call on context when sent
+// from within caller. It reports false if SendFrom would raise an error or
+// call method_missing instead.
+func (r *Runtime) MethodFrom(caller, context RubyObject, method string) (RubyMethod, bool) {
+	fn, ok := r.findMethod(context, method)
+	if !ok || context.Type() == SELF {
+		return fn, ok
+	}
+	switch fn.Visibility() {
+	case PRIVATE_METHOD:
+		return nil, false
+	case PROTECTED_METHOD:
+		return fn, r.isProtectedCallAllowed(caller, context, method)
+	default:
+		return fn, true
+	}
+}
+
+// Call calls fn with args on context within r
+func (r *Runtime) Call(fn RubyMethod, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if m, ok := fn.(runtimeMethod); ok {
+		return m.callWithin(r, context, args...)
+	}
+	return fn.Call(context, args...)
+}
+
+// findMethod searches for method within the ancestry tree of the class of
+// context outside of any Runtime
+func findMethod(context RubyObject, method string) (RubyMethod, bool) {
+	return (*Runtime)(nil).findMethod(context, method)
+}
+
+// findMethod searches for method within the ancestry tree of the class of
+// context as seen within r. Results are kept within the method cache.
+func (r *Runtime) findMethod(context RubyObject, method string) (RubyMethod, bool) {
+	start := classOf(context)
+	if start == nil {
+		return nil, false
+	}
+	cache := r.methodCache()
+	if fn, ok := cache.cachedMethod(start, method); ok {
+		return fn, fn != nil
+	}
+	generation := atomic.LoadUint64(&methodCacheGeneration)
+	fn, ok := r.lookupMethod(start, method)
+	cache.cacheMethod(generation, start, method, fn)
+	return fn, ok
+}
+
+// lookupMethod walks the ancestry tree starting at class looking for
+// method, taking the methods r defines within builtin classes into account
+func (r *Runtime) lookupMethod(class RubyClass, method string) (RubyMethod, bool) {
+	methodTables.RLock()
+	defer methodTables.RUnlock()
 	for class != nil {
-		fn, ok := class.Methods()[method]
-		if !ok {
-			class = class.SuperClass()
-			continue
+		if fn, ok := r.definedMethod(class, method); ok {
+			return fn, true
 		}
+		class = class.SuperClass()
+	}
+	return nil, false
+}
 
-		if fn.Visibility() == PRIVATE_METHOD && context.Type() != SELF {
-			return nil, NewPrivateNoMethodError(context, method)
+// definedMethod returns method if it is defined within class or the
+// modules mixed into it
+func (r *Runtime) definedMethod(class RubyClass, method string) (RubyMethod, bool) {
+	if r == nil || len(r.methods.tables) == 0 {
+		fn, ok := class.Methods()[method]
+		return fn, ok
+	}
+	mixin, ok := class.(*methodSet)
+	if !ok {
+		if fn, ok := r.overlaid(class)[method]; ok {
+			return fn, true
+		}
+		fn, ok := class.Methods()[method]
+		return fn, ok
+	}
+	if fn, ok := r.definedMethod(mixin.RubyClassObject, method); ok {
+		return fn, true
+	}
+	for i := len(mixin.modules) - 1; i >= 0; i-- {
+		if fn, ok := r.definedMethod(mixin.modules[i].Class(), method); ok {
+			return fn, true
 		}
+	}
+	return nil, false
+}
 
-		return fn.Call(context, args...)
+// nameArgument returns the name given as Symbol or String
+func nameArgument(arg RubyObject) (string, error) {
+	switch arg := arg.(type) {
+	case *Symbol:
+		return arg.Value, nil
+	case *String:
+		return arg.Value, nil
+	default:
+		return "", NewTypeError("%s is not a symbol nor a string", arg.Inspect())
 	}
+}
 
+func (r *Runtime) sendMethodMissing(context RubyObject, method string, args ...RubyObject) (RubyObject, error) {
 	methodMissingArgs := append(
-		[]RubyObject{&Symbol{method}},
+		[]RubyObject{NewSymbol(method)},
 		args...,
 	)
 
-	return methodMissing(context, methodMissingArgs...)
+	return r.methodMissing(context, methodMissingArgs...)
+}
+
+// kernelRespondTo reports whether context responds to the method named by
+// the first argument. Private and protected methods are only taken into
+// account if the second argument is truthy. For methods which cannot be
+// found respond_to_missing? is asked.
+func kernelRespondTo(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	method, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	includeAll := len(args) > 1 && isTruthy(args[1])
+	fn, ok := r.findMethod(context, method)
+	if ok {
+		return nativeBoolToBoolean(includeAll || fn.Visibility() == PUBLIC_METHOD), nil
+	}
+	respondToMissing, ok := r.findMethod(context, "respond_to_missing?")
+	if !ok {
+		return FALSE, nil
+	}
+	result, err := r.Call(respondToMissing, context, NewSymbol(method), nativeBoolToBoolean(includeAll))
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(isTruthy(result)), nil
+}
+
+func kernelRespondToMissing(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return FALSE, nil
+}
+
+// kernelSend calls the method named by the first argument with the
+// remaining arguments, regardless of its visibility
+func kernelSend(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := r.findMethod(context, method)
+	if !ok {
+		return r.sendMethodMissing(context, method, args[1:]...)
+	}
+	return r.Call(fn, context, args[1:]...)
 }
 
-// AddMethod adds a method to a given object. It returns the object with the modified method set
+// kernelPublicSend calls the method named by the first argument with the
+// remaining arguments. Only public methods can be called.
+func kernelPublicSend(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := r.findMethod(context, method)
+	if !ok {
+		return r.sendMethodMissing(context, method, args[1:]...)
+	}
+	switch fn.Visibility() {
+	case PRIVATE_METHOD:
+		return nil, NewPrivateNoMethodError(context, method)
+	case PROTECTED_METHOD:
+		return nil, NewProtectedNoMethodError(context, method)
+	}
+	return r.Call(fn, context, args[1:]...)
+}
+
+// CallSuper calls the method overridden by fn, which is the method of
+// context currently executed within r. It is looked up within the ancestors
+// of the class of context following the one defining fn.
+func (r *Runtime) CallSuper(context RubyObject, fn *Function, args ...RubyObject) (RubyObject, error) {
+	receiver := unwrapSelf(context)
+	ancestors := ancestorsOf(receiver.Class().(RubyObject))
+	for i, ancestor := range ancestors {
+		if !isSameFunction(r.ownMethods(ancestor)[fn.Name], fn) {
+			continue
+		}
+		for _, next := range ancestors[i+1:] {
+			if method, ok := r.ownMethods(next)[fn.Name]; ok {
+				return r.Call(method, context, args...)
+			}
+		}
+		break
+	}
+	return nil, NewNoSuperMethodError(receiver, fn.Name)
+}
+
+// isSameFunction reports whether method is fn or a copy of it, like the
+// ones bound to a receiver when called
+func isSameFunction(method RubyMethod, fn *Function) bool {
+	function, ok := method.(*Function)
+	return ok && function.Body == fn.Body
+}
+
+// AddMethod adds a method to a given object outside of any Runtime. It
+// returns the object with the modified method set.
 func AddMethod(context RubyObject, methodName string, method *Function) RubyObject {
+	return (*Runtime)(nil).AddMethod(context, methodName, method)
+}
+
+// AddMethod adds a method to a given object within r. It returns the
+// object with the modified method set. Like in Ruby, initialize and the
+// other hooks called by the interpreter itself are always private.
+func (r *Runtime) AddMethod(context RubyObject, methodName string, method *Function) RubyObject {
+	if self, ok := context.(*definingSelf); ok {
+		method.MethodVisibility = self.visibility
+	}
+	if privateMethodNames[methodName] {
+		method.MethodVisibility = PRIVATE_METHOD
+	}
+	return r.addMethod(context, methodName, method)
+}
+
+// privateMethodNames are the names of the methods which are private
+// wherever they get defined
+var privateMethodNames = map[string]bool{
+	"initialize":          true,
+	"initialize_copy":     true,
+	"respond_to_missing?": true,
+}
+
+// addMethod adds method to the class or module definition context, or to
+// the singleton class of any other object
+func (r *Runtime) addMethod(context RubyObject, methodName string, method RubyMethod) RubyObject {
+	if self, ok := context.(*definingSelf); ok {
+		r.defineMethod(self.definee, methodName, method)
+		return self
+	}
 	objectToExtend := context
 	self, contextIsSelf := context.(*Self)
 	if contextIsSelf {
 		objectToExtend = self.RubyObject
 	}
+	if holder, ok := objectToExtend.(singletonHolder); ok {
+		r.defineMethod(holder.singletonClass(), methodName, method)
+		return context
+	}
+	if eigenClass, ok := detachedSingleton(objectToExtend); ok {
+		r.defineMethod(eigenClass, methodName, method)
+		return context
+	}
 	extended, ok := objectToExtend.(*extendedObject)
 	if !ok {
 		extended = &extendedObject{
-			RubyObject: context,
-			class:      newEigenclass(context.Class(), map[string]RubyMethod{}),
+			RubyObject: objectToExtend,
+			class:      newEigenclass(objectToExtend.Class(), map[string]RubyMethod{}),
 		}
 	}
-	extended.addMethod(methodName, method)
+	r.defineMethod(extended, methodName, method)
 	if contextIsSelf {
 		self.RubyObject = extended
 		return self
@@ -49,17 +304,24 @@ func AddMethod(context RubyObject, methodName string, method *Function) RubyObje
 	return extended
 }
 
-func methodMissing(context RubyObject, args ...RubyObject) (RubyObject, error) {
-	class := context.Class()
+// IsMethodDefinedBy reports whether AddMethod would replace a method name
+// defined directly within the class or module context adds methods to
+// within r
+func (r *Runtime) IsMethodDefinedBy(context RubyObject, methodName string) bool {
+	var methods map[string]RubyMethod
+	if self, ok := context.(*definingSelf); ok {
+		definee, _ := self.definee.(RubyObject)
+		methods = r.ownMethods(definee)
+	} else if eigen, ok := unwrapSelf(context).Class().(*eigenclass); ok {
+		methods = r.ownMethods(eigen)
+	}
+	_, ok := methods[methodName]
+	return ok
+}
 
-	// search for method_missing in the ancestry tree
-	for class != nil {
-		fn, ok := class.Methods()["method_missing"]
-		if !ok {
-			class = class.SuperClass()
-			continue
-		}
-		return fn.Call(context, args...)
+func (r *Runtime) methodMissing(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if fn, ok := r.findMethod(context, "method_missing"); ok {
+		return r.Call(fn, context, args...)
 	}
 	return nil, NewNoMethodError(context, args[0].(*Symbol).Value)
 }
@@ -166,6 +166,160 @@ func TestSend(t *testing.T) {
 	})
 }
 
+func TestKernelSend(t *testing.T) {
+	echo := func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NewArray(args...), nil
+	}
+	context := &testRubyObject{
+		class: &class{
+			name: "base class",
+			instanceMethods: map[string]RubyMethod{
+				"a_method":           publicMethod(echo),
+				"a_private_method":   privateMethod(echo),
+				"a_protected_method": protectedMethod(echo),
+			},
+			superClass: objectClass,
+		},
+	}
+	block := &Proc{}
+
+	tests := []struct {
+		method         string
+		args           []RubyObject
+		expectedResult RubyObject
+		expectedError  error
+	}{
+		{
+			"send",
+			[]RubyObject{&Symbol{"a_method"}, NewInteger(1)},
+			NewArray(NewInteger(1)),
+			nil,
+		},
+		{
+			"send",
+			[]RubyObject{&String{Value: "a_method"}, NewInteger(1), block},
+			NewArray(NewInteger(1), block),
+			nil,
+		},
+		{
+			"send",
+			[]RubyObject{&Symbol{"a_private_method"}},
+			NewArray(),
+			nil,
+		},
+		{
+			"__send__",
+			[]RubyObject{&Symbol{"a_protected_method"}},
+			NewArray(),
+			nil,
+		},
+		{
+			"send",
+			[]RubyObject{&Symbol{"unknown_method"}},
+			nil,
+			NewNoMethodError(context, "unknown_method"),
+		},
+		{
+			"send",
+			[]RubyObject{NewInteger(1)},
+			nil,
+			NewTypeError("1 is not a symbol nor a string"),
+		},
+		{
+			"public_send",
+			[]RubyObject{&Symbol{"a_method"}, NewInteger(1)},
+			NewArray(NewInteger(1)),
+			nil,
+		},
+		{
+			"public_send",
+			[]RubyObject{&Symbol{"a_private_method"}},
+			nil,
+			NewPrivateNoMethodError(context, "a_private_method"),
+		},
+		{
+			"public_send",
+			[]RubyObject{&Symbol{"a_protected_method"}},
+			nil,
+			NewProtectedNoMethodError(context, "a_protected_method"),
+		},
+		{
+			"public_send",
+			[]RubyObject{block},
+			nil,
+			NewWrongNumberOfArgumentsRangeError(1, -1, 0),
+		},
+	}
+
+	for _, testCase := range tests {
+		result, err := Send(context, testCase.method, testCase.args...)
+
+		checkError(t, err, testCase.expectedError)
+
+		checkResult(t, result, testCase.expectedResult)
+	}
+}
+
+func TestKernelRespondTo(t *testing.T) {
+	fn := func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NIL, nil
+	}
+	context := &testRubyObject{
+		class: &class{
+			name: "base class",
+			instanceMethods: map[string]RubyMethod{
+				"a_method":           publicMethod(fn),
+				"a_private_method":   privateMethod(fn),
+				"a_protected_method": protectedMethod(fn),
+			},
+			superClass: objectClass,
+		},
+	}
+	extended := AddMethod(context, "an_eigen_method", &Function{})
+	missing := &testRubyObject{
+		class: &class{
+			name: "missing class",
+			instanceMethods: map[string]RubyMethod{
+				"respond_to_missing?": privateMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+					return nativeBoolToBoolean(args[0].(*Symbol).Value == "ghost"), nil
+				}),
+			},
+			superClass: objectClass,
+		},
+	}
+
+	tests := []struct {
+		context  RubyObject
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{context, []RubyObject{&Symbol{"a_method"}}, TRUE},
+		{context, []RubyObject{&String{Value: "a_method"}}, TRUE},
+		{context, []RubyObject{&Symbol{"respond_to?"}}, TRUE},
+		{context, []RubyObject{&Symbol{"a_private_method"}}, FALSE},
+		{context, []RubyObject{&Symbol{"a_private_method"}, TRUE}, TRUE},
+		{context, []RubyObject{&Symbol{"a_protected_method"}}, FALSE},
+		{context, []RubyObject{&Symbol{"a_protected_method"}, TRUE}, TRUE},
+		{context, []RubyObject{&Symbol{"unknown_method"}}, FALSE},
+		{context, []RubyObject{&Symbol{"an_eigen_method"}}, FALSE},
+		{extended, []RubyObject{&Symbol{"an_eigen_method"}}, TRUE},
+		{extended, []RubyObject{&Symbol{"a_method"}}, TRUE},
+		{missing, []RubyObject{&Symbol{"ghost"}}, TRUE},
+		{missing, []RubyObject{&Symbol{"unknown_method"}}, FALSE},
+	}
+
+	for _, testCase := range tests {
+		result, err := Send(testCase.context, "respond_to?", testCase.args...)
+
+		checkError(t, err, nil)
+
+		checkResult(t, result, testCase.expected)
+	}
+
+	_, err := Send(context, "respond_to?", NewInteger(1))
+	checkError(t, err, NewTypeError("1 is not a symbol nor a string"))
+}
+
 func TestAddMethod(t *testing.T) {
 	t.Run("vanilla object", func(t *testing.T) {
 		context := &testRubyObject{
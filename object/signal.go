@@ -0,0 +1,262 @@
+package object
+
+import (
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var signalModule = newModule("Signal", signalMethods)
+
+func init() {
+	classes.Set("Signal", signalModule)
+	kernelMethodSet["trap"] = withArityRange(1, 3, privateMethod(signalTrap))
+}
+
+// signalNumbers are the signals known to Signal by their names without the
+// SIG prefix
+var signalNumbers = map[string]syscall.Signal{
+	"HUP": syscall.SIGHUP, "INT": syscall.SIGINT, "QUIT": syscall.SIGQUIT,
+	"ILL": syscall.SIGILL, "TRAP": syscall.SIGTRAP, "ABRT": syscall.SIGABRT,
+	"BUS": syscall.SIGBUS, "FPE": syscall.SIGFPE, "KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1, "SEGV": syscall.SIGSEGV, "USR2": syscall.SIGUSR2,
+	"PIPE": syscall.SIGPIPE, "ALRM": syscall.SIGALRM, "TERM": syscall.SIGTERM,
+	"CHLD": syscall.SIGCHLD, "CONT": syscall.SIGCONT, "STOP": syscall.SIGSTOP,
+	"TSTP": syscall.SIGTSTP, "TTIN": syscall.SIGTTIN, "TTOU": syscall.SIGTTOU,
+	"URG": syscall.SIGURG, "XCPU": syscall.SIGXCPU, "XFSZ": syscall.SIGXFSZ,
+	"VTALRM": syscall.SIGVTALRM, "PROF": syscall.SIGPROF, "WINCH": syscall.SIGWINCH,
+	"IO": syscall.SIGIO, "SYS": syscall.SIGSYS,
+}
+
+// reservedSignals cannot be trapped, as the runtime needs them or the
+// operating system does not allow to
+var reservedSignals = map[syscall.Signal]bool{
+	syscall.SIGKILL: true, syscall.SIGSTOP: true, syscall.SIGSEGV: true,
+	syscall.SIGBUS: true, syscall.SIGILL: true, syscall.SIGFPE: true,
+	syscall.SIGVTALRM: true,
+}
+
+// traps holds the handlers installed by Signal.trap, by the signals they
+// handle, and the channels the signals are delivered to. The signals
+// received are queued in pending until CheckInterrupt runs their handlers;
+// arrived is closed, and replaced by a new channel, whenever one is queued.
+var traps = struct {
+	sync.Mutex
+	handlers map[syscall.Signal]RubyObject
+	channels map[syscall.Signal]chan os.Signal
+	pending  []syscall.Signal
+	arrived  chan struct{}
+}{
+	handlers: map[syscall.Signal]RubyObject{},
+	channels: map[syscall.Signal]chan os.Signal{},
+	arrived:  make(chan struct{}),
+}
+
+// SignalTrapped reports whether the program handles sig itself, as a
+// handler or the command IGNORE has been installed for it by Signal.trap.
+// Hosts which react to signals, like by interrupting the program on Ctrl-C,
+// should leave them to the program then.
+func SignalTrapped(sig os.Signal) bool {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return false
+	}
+	traps.Lock()
+	defer traps.Unlock()
+	_, ok = traps.handlers[s]
+	return ok
+}
+
+// setTrap installs handler for sig, or removes the handler if it is nil,
+// and returns the previous one
+func setTrap(sig syscall.Signal, handler RubyObject) RubyObject {
+	traps.Lock()
+	defer traps.Unlock()
+	previous, ok := traps.handlers[sig]
+	if !ok {
+		previous = &String{Value: "DEFAULT"}
+	}
+	if handler == nil {
+		delete(traps.handlers, sig)
+		if c, ok := traps.channels[sig]; ok {
+			signal.Stop(c)
+			close(c)
+			delete(traps.channels, sig)
+		}
+		return previous
+	}
+	traps.handlers[sig] = handler
+	if _, ok := traps.channels[sig]; !ok {
+		c := make(chan os.Signal, 1)
+		traps.channels[sig] = c
+		signal.Notify(c, sig)
+		go queueSignals(sig, c)
+	}
+	return previous
+}
+
+// queueSignals queues every signal delivered to c until it is closed
+func queueSignals(sig syscall.Signal, c <-chan os.Signal) {
+	for range c {
+		traps.Lock()
+		traps.pending = append(traps.pending, sig)
+		close(traps.arrived)
+		traps.arrived = make(chan struct{})
+		traps.Unlock()
+	}
+}
+
+// trapped returns a channel which is closed when the next trapped signal
+// is received, or already if one is waiting to be handled
+func trapped() <-chan struct{} {
+	traps.Lock()
+	defer traps.Unlock()
+	if len(traps.pending) != 0 {
+		arrived := make(chan struct{})
+		close(arrived)
+		return arrived
+	}
+	return traps.arrived
+}
+
+// runTraps runs the handlers of the signals received since it ran last, in
+// the order they were received. The handlers run within the Ruby thread
+// calling it, so it must hold the interpreter. It returns the first error
+// raised by a handler, like the SystemExit of the command EXIT.
+func runTraps() error {
+	traps.Lock()
+	pending := traps.pending
+	traps.pending = nil
+	handlers := make([]RubyObject, len(pending))
+	for i, sig := range pending {
+		handlers[i] = traps.handlers[sig]
+	}
+	traps.Unlock()
+	for i, handler := range handlers {
+		switch handler := handler.(type) {
+		case *Proc:
+			if _, err := handler.Call(NewInteger(int64(pending[i]))); err != nil {
+				return err
+			}
+		case *String:
+			if handler.Value == "EXIT" {
+				return NewSystemExit(0)
+			}
+		}
+	}
+	return nil
+}
+
+var signalMethods = map[string]RubyMethod{
+	"trap":    withArityRange(1, 3, publicMethod(signalTrap)),
+	"list":    withArity(0, publicMethod(signalList)),
+	"signame": withArity(1, publicMethod(signalSigname)),
+}
+
+// signalTrap installs the block, or the command given as second argument,
+// as handler of the signal. The handler is called with the signal number
+// between two statements of the program. The commands are IGNORE, to
+// ignore the signal, DEFAULT, to restore its default handling, and EXIT, to
+// exit the program. It returns the previous handler.
+func signalTrap(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if len(args) != 1 && len(args) != 2 {
+		return nil, NewWrongNumberOfArgumentsError(2, len(args))
+	}
+	sig, err := signalArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if reservedSignals[sig] {
+		return nil, NewArgumentError("can't trap reserved signal: SIG%s", signalName(int(sig)))
+	}
+	var command RubyObject
+	if len(args) == 2 {
+		command = args[1]
+	} else if block != nil {
+		command = block
+	}
+	switch command := command.(type) {
+	case *Proc:
+		return setTrap(sig, command), nil
+	case *String, *Symbol:
+		switch name := toS(command); name {
+		case "", "IGNORE", "SIG_IGN":
+			return setTrap(sig, &String{Value: "IGNORE"}), nil
+		case "DEFAULT", "SIG_DFL", "SYSTEM_DEFAULT":
+			return setTrap(sig, nil), nil
+		case "EXIT":
+			return setTrap(sig, &String{Value: "EXIT"}), nil
+		default:
+			return nil, NewArgumentError("wrong trap - %s", name)
+		}
+	case nil:
+		return nil, NewArgumentError("tried to create Proc object without a block")
+	default:
+		return nil, NewArgumentError("wrong trap - %s", command.Inspect())
+	}
+}
+
+// signalArgument returns the signal given by its name, with or without the
+// SIG prefix, or by its number
+func signalArgument(arg RubyObject) (syscall.Signal, error) {
+	switch arg := arg.(type) {
+	case *Integer:
+		sig := syscall.Signal(arg.Value)
+		if signalName(int(sig)) == "" {
+			return 0, NewArgumentError("invalid signal number (%d)", arg.Value)
+		}
+		return sig, nil
+	case *String, *Symbol:
+		name := toS(arg)
+		sig, ok := signalNumbers[strings.TrimPrefix(name, "SIG")]
+		if !ok {
+			return 0, NewArgumentError("unsupported signal 'SIG%s'", strings.TrimPrefix(name, "SIG"))
+		}
+		return sig, nil
+	default:
+		return 0, NewArgumentError("bad signal type %s", className(arg))
+	}
+}
+
+// signalList returns a Hash of the signal numbers by their names
+func signalList(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	names := make([]string, 0, len(signalNumbers))
+	for name := range signalNumbers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return signalNumbers[names[i]] < signalNumbers[names[j]] })
+	list := NewHash(nil)
+	for _, name := range names {
+		list.Set(&String{Value: name}, NewInteger(int64(signalNumbers[name])))
+	}
+	return list, nil
+}
+
+// signalSigname returns the name of the signal number without the SIG
+// prefix, or nil if it is unknown
+func signalSigname(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	number, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	name := signalName(int(number.Value))
+	if name == "" {
+		return NIL, nil
+	}
+	return &String{Value: name}, nil
+}
+
+// signalName returns the name of the signal without the SIG prefix, like
+// KILL, or an empty string if it is unknown
+func signalName(sig int) string {
+	for name, number := range signalNumbers {
+		if int(number) == sig {
+			return name
+		}
+	}
+	return ""
+}
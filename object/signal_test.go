@@ -0,0 +1,80 @@
+package object
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalTrap(t *testing.T) {
+	received := make(chan RubyObject, 1)
+	handler := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+		received <- args[0]
+		return NIL, nil
+	})
+
+	previous, err := Send(signalModule, "trap", &String{Value: "SIGUSR1"}, handler)
+	checkError(t, err, nil)
+	checkResult(t, previous, &String{Value: "DEFAULT"})
+	if !SignalTrapped(syscall.SIGUSR1) {
+		t.Errorf("Expected SIGUSR1 to be trapped")
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-trapped():
+	case <-time.After(time.Second):
+		t.Fatalf("Expected SIGUSR1 to be received")
+	}
+	checkError(t, CheckInterrupt(), nil)
+	select {
+	case signo := <-received:
+		checkResult(t, signo, NewInteger(int64(syscall.SIGUSR1)))
+	default:
+		t.Errorf("Expected the handler to be called")
+	}
+
+	previous, err = Send(signalModule, "trap", NewSymbol("USR1"), &String{Value: "EXIT"})
+	checkError(t, err, nil)
+	if previous != handler {
+		t.Errorf("Expected the previous handler to be returned, got %s", previous.Inspect())
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	<-trapped()
+	checkError(t, CheckInterrupt(), NewSystemExit(0))
+
+	previous, err = Send(signalModule, "trap", NewInteger(int64(syscall.SIGUSR1)), &String{Value: "DEFAULT"})
+	checkError(t, err, nil)
+	checkResult(t, previous, &String{Value: "EXIT"})
+	if SignalTrapped(syscall.SIGUSR1) {
+		t.Errorf("Expected SIGUSR1 not to be trapped anymore")
+	}
+}
+
+func TestSignalTrapErrors(t *testing.T) {
+	tests := []struct {
+		args []RubyObject
+		err  error
+	}{
+		{[]RubyObject{&String{Value: "KILL"}, &String{Value: "IGNORE"}}, NewArgumentError("can't trap reserved signal: SIGKILL")},
+		{[]RubyObject{&String{Value: "FOO"}, &String{Value: "IGNORE"}}, NewArgumentError("unsupported signal 'SIGFOO'")},
+		{[]RubyObject{&String{Value: "TERM"}, &String{Value: "BOGUS"}}, NewArgumentError("wrong trap - BOGUS")},
+		{[]RubyObject{&String{Value: "TERM"}}, NewArgumentError("tried to create Proc object without a block")},
+	}
+
+	for _, tt := range tests {
+		_, err := Send(signalModule, "trap", tt.args...)
+		checkError(t, err, tt.err)
+	}
+}
+
+func TestSignalSigname(t *testing.T) {
+	name, err := Send(signalModule, "signame", NewInteger(int64(syscall.SIGTERM)))
+	checkError(t, err, nil)
+	checkResult(t, name, &String{Value: "TERM"})
+
+	list, err := Send(signalModule, "list")
+	checkError(t, err, nil)
+	number, _ := list.(*Hash).Get(&String{Value: "INT"})
+	checkResult(t, number, NewInteger(int64(syscall.SIGINT)))
+}
@@ -0,0 +1,831 @@
+package object
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	basicSocketClass RubyClassObject = newClass("BasicSocket", objectClass, basicSocketMethods, nil)
+	ipSocketClass    RubyClassObject = newClass("IPSocket", basicSocketClass, ipSocketMethods, nil)
+	tcpSocketClass   RubyClassObject = newClass("TCPSocket", ipSocketClass, tcpSocketMethods, tcpSocketClassMethods)
+	tcpServerClass   RubyClassObject = newClass("TCPServer", ipSocketClass, tcpServerMethods, tcpServerClassMethods)
+	udpSocketClass   RubyClassObject = newClass("UDPSocket", ipSocketClass, udpSocketMethods, udpSocketClassMethods)
+)
+
+func init() {
+	classes.Set("BasicSocket", basicSocketClass)
+	classes.Set("IPSocket", ipSocketClass)
+	classes.Set("TCPSocket", tcpSocketClass)
+	classes.Set("TCPServer", tcpServerClass)
+	classes.Set("UDPSocket", udpSocketClass)
+}
+
+// socket is implemented by the Go types of the socket classes
+type socket interface {
+	RubyObject
+	close()
+	isClosed() bool
+	localAddr() net.Addr
+}
+
+// TCPSocket represents a TCP connection, opened by TCPSocket.new or
+// accepted by a TCPServer
+type TCPSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	closed bool
+}
+
+func newTCPSocket(conn net.Conn) *TCPSocket {
+	return &TCPSocket{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// Type returns SOCKET_OBJ
+func (s *TCPSocket) Type() Type { return SOCKET_OBJ }
+
+// Inspect returns the class and the file descriptor like in Ruby, but with
+// the local address in place of the descriptor, like
+// `#<TCPSocket:127.0.0.1:4242>`
+func (s *TCPSocket) Inspect() string {
+	if s.closed {
+		return "#<TCPSocket:(closed)>"
+	}
+	return fmt.Sprintf("#<TCPSocket:%s>", s.conn.LocalAddr())
+}
+
+// Class returns tcpSocketClass
+func (s *TCPSocket) Class() RubyClass { return tcpSocketClass }
+
+func (s *TCPSocket) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.conn.Close()
+}
+
+func (s *TCPSocket) isClosed() bool { return s.closed }
+
+func (s *TCPSocket) localAddr() net.Addr { return s.conn.LocalAddr() }
+
+// TCPServer represents a listening TCP socket
+type TCPServer struct {
+	listener net.Listener
+	closed   bool
+}
+
+// Type returns SOCKET_OBJ
+func (s *TCPServer) Type() Type { return SOCKET_OBJ }
+
+// Inspect returns the class and the listening address, like
+// `#<TCPServer:127.0.0.1:4242>`
+func (s *TCPServer) Inspect() string {
+	if s.closed {
+		return "#<TCPServer:(closed)>"
+	}
+	return fmt.Sprintf("#<TCPServer:%s>", s.listener.Addr())
+}
+
+// Class returns tcpServerClass
+func (s *TCPServer) Class() RubyClass { return tcpServerClass }
+
+func (s *TCPServer) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.listener.Close()
+}
+
+func (s *TCPServer) isClosed() bool { return s.closed }
+
+func (s *TCPServer) localAddr() net.Addr { return s.listener.Addr() }
+
+// UDPSocket represents a UDP socket, which is bound to a local address by
+// bind or connect, or by the first datagram sent
+type UDPSocket struct {
+	conn      *net.UDPConn
+	connected bool
+	closed    bool
+}
+
+// Type returns SOCKET_OBJ
+func (s *UDPSocket) Type() Type { return SOCKET_OBJ }
+
+// Inspect returns the class and the local address once bound, like
+// `#<UDPSocket:127.0.0.1:4242>`
+func (s *UDPSocket) Inspect() string {
+	switch {
+	case s.closed:
+		return "#<UDPSocket:(closed)>"
+	case s.conn == nil:
+		return "#<UDPSocket>"
+	default:
+		return fmt.Sprintf("#<UDPSocket:%s>", s.conn.LocalAddr())
+	}
+}
+
+// Class returns udpSocketClass
+func (s *UDPSocket) Class() RubyClass { return udpSocketClass }
+
+func (s *UDPSocket) close() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+func (s *UDPSocket) isClosed() bool { return s.closed }
+
+func (s *UDPSocket) localAddr() net.Addr {
+	if s.conn == nil {
+		return &net.UDPAddr{IP: net.IPv4zero}
+	}
+	return s.conn.LocalAddr()
+}
+
+// socketAddress returns the address for host and port, where port is an
+// Integer or a service name like "http". A nil or empty host means all
+// interfaces.
+func socketAddress(host, port RubyObject) (string, error) {
+	var hostName string
+	if host != NIL {
+		str, err := stringArgument(host)
+		if err != nil {
+			return "", err
+		}
+		hostName = str.Value
+	}
+	switch port := port.(type) {
+	case *Integer:
+		return net.JoinHostPort(hostName, strconv.FormatInt(port.Value, 10)), nil
+	case *String:
+		return net.JoinHostPort(hostName, port.Value), nil
+	default:
+		return "", NewImplicitConversionTypeError(NewInteger(0), port)
+	}
+}
+
+// socketError converts errors of the net package into Ruby exceptions.
+// Operations on closed sockets raise an IOError, other failures a
+// SocketError.
+func socketError(err error, operation string) error {
+	if errors.Is(err, net.ErrClosed) {
+		return NewIOError("closed stream")
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		err = opErr.Err
+	}
+	return NewSocketError("%s: %s", operation, err)
+}
+
+// addrArray returns addr like IPSocket#addr, like
+// `["AF_INET", 4242, "127.0.0.1", "127.0.0.1"]`
+func addrArray(addr net.Addr) RubyObject {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return NewArray(&String{Value: addr.Network()}, &String{Value: addr.String()})
+	}
+	family := "AF_INET"
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		family = "AF_INET6"
+	}
+	portNumber, _ := strconv.ParseInt(port, 10, 64)
+	return NewArray(&String{Value: family}, NewInteger(portNumber), &String{Value: host}, &String{Value: host})
+}
+
+// openSocket returns context as socket, raising an IOError if it is closed
+func openSocket(context RubyObject) (socket, error) {
+	sock := context.(socket)
+	if sock.isClosed() {
+		return nil, NewIOError("closed stream")
+	}
+	return sock, nil
+}
+
+var basicSocketMethods = map[string]RubyMethod{
+	"close":       withArity(0, publicMethod(basicSocketClose)),
+	"closed?":     withArity(0, publicMethod(basicSocketIsClosed)),
+	"to_io":       withArity(0, publicMethod(basicSocketToIO)),
+	"close_read":  withArity(0, publicMethod(basicSocketCloseRead)),
+	"close_write": withArity(0, publicMethod(basicSocketCloseWrite)),
+}
+
+func basicSocketClose(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	context.(socket).close()
+	return NIL, nil
+}
+
+func basicSocketIsClosed(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(socket).isClosed()), nil
+}
+
+func basicSocketToIO(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context, nil
+}
+
+// basicSocketCloseRead closes the socket for reading, which closes TCP
+// connections entirely once they are closed for writing as well
+func basicSocketCloseRead(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if tcp, ok := context.(*TCPSocket); ok && !tcp.closed {
+		if conn, ok := tcp.conn.(*net.TCPConn); ok {
+			conn.CloseRead()
+			return NIL, nil
+		}
+	}
+	context.(socket).close()
+	return NIL, nil
+}
+
+// basicSocketCloseWrite half closes a TCP connection, so that the peer
+// reads EOF while the answer can still be read
+func basicSocketCloseWrite(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if tcp, ok := context.(*TCPSocket); ok && !tcp.closed {
+		if conn, ok := tcp.conn.(*net.TCPConn); ok {
+			conn.CloseWrite()
+			return NIL, nil
+		}
+	}
+	context.(socket).close()
+	return NIL, nil
+}
+
+var ipSocketMethods = map[string]RubyMethod{
+	"addr":     withArityRange(0, 1, publicMethod(ipSocketAddr)),
+	"peeraddr": withArityRange(0, 1, publicMethod(ipSocketPeeraddr)),
+}
+
+func ipSocketAddr(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	return addrArray(sock.localAddr()), nil
+}
+
+// ipSocketPeeraddr returns the address of the remote end of connected
+// sockets
+func ipSocketPeeraddr(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := openSocket(context); err != nil {
+		return nil, err
+	}
+	switch sock := context.(type) {
+	case *TCPSocket:
+		return addrArray(sock.conn.RemoteAddr()), nil
+	case *UDPSocket:
+		if sock.connected {
+			return addrArray(sock.conn.RemoteAddr()), nil
+		}
+	}
+	return nil, NewSocketError("getpeername: not connected")
+}
+
+var tcpSocketClassMethods = map[string]RubyMethod{
+	"new":  withArityRange(2, 4, publicRuntimeMethod(tcpSocketNew)),
+	"open": withArityRange(2, 4, publicRuntimeMethod(tcpSocketNew)),
+}
+
+// tcpSocketNew connects to the server at host and port. Local host and
+// port to bind to may follow.
+func tcpSocketNew(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	address, err := socketAddress(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	if len(args) > 2 {
+		localPort := RubyObject(NewInteger(0))
+		if len(args) == 4 {
+			localPort = args[3]
+		}
+		local, err := socketAddress(args[2], localPort)
+		if err != nil {
+			return nil, err
+		}
+		if dialer.LocalAddr, err = net.ResolveTCPAddr("tcp", local); err != nil {
+			return nil, socketError(err, "bind")
+		}
+	}
+	var conn net.Conn
+	r.withoutInterpreter(func() {
+		conn, err = dialer.Dial("tcp", address)
+	})
+	if err != nil {
+		return nil, socketError(err, "connect")
+	}
+	return newTCPSocket(conn), nil
+}
+
+var tcpSocketMethods = map[string]RubyMethod{
+	"gets":     withArityRange(0, 1, publicRuntimeMethod(tcpSocketGets)),
+	"read":     withArityRange(0, 1, publicRuntimeMethod(tcpSocketRead)),
+	"readline": withArityRange(0, 1, publicRuntimeMethod(tcpSocketReadline)),
+	"recv":     withArityRange(1, 2, publicRuntimeMethod(tcpSocketRecv)),
+	"eof?":     withArity(0, publicRuntimeMethod(tcpSocketEOF)),
+	"write":    withArityRange(1, -1, publicRuntimeMethod(tcpSocketWrite)),
+	"send":     withArityRange(2, 3, publicRuntimeMethod(tcpSocketSend)),
+	"print":    withArityRange(0, -1, publicRuntimeMethod(tcpSocketPrint)),
+	"puts":     withArityRange(0, -1, publicRuntimeMethod(tcpSocketPuts)),
+	"<<":       withArity(1, publicRuntimeMethod(tcpSocketAppend)),
+	"flush":    withArity(0, publicMethod(tcpSocketFlush)),
+}
+
+// openTCPSocket returns context as TCPSocket, raising an IOError if it is
+// closed
+func openTCPSocket(context RubyObject) (*TCPSocket, error) {
+	if _, err := openSocket(context); err != nil {
+		return nil, err
+	}
+	return context.(*TCPSocket), nil
+}
+
+// readLine reads the next line including its line terminator, returning
+// io.EOF at the end of the stream
+func (s *TCPSocket) readLine(r *Runtime) (string, error) {
+	var line string
+	var err error
+	r.withoutInterpreter(func() {
+		line, err = s.reader.ReadString('\n')
+	})
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil && err != io.EOF {
+		return "", socketError(err, "read")
+	}
+	return line, err
+}
+
+// tcpSocketGets returns the next line, or nil at the end of the stream. The
+// line terminator is removed with the chomp option.
+func tcpSocketGets(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	chomp, err := chompOption(args)
+	if err != nil {
+		return nil, err
+	}
+	line, err := sock.readLine(r)
+	if err == io.EOF {
+		return NIL, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chomp {
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	}
+	return &String{Value: line}, nil
+}
+
+// tcpSocketReadline is like gets but raises an EOFError at the end of the
+// stream
+func tcpSocketReadline(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	line, err := tcpSocketGets(r, context, args...)
+	if err != nil {
+		return nil, err
+	}
+	if line == NIL {
+		return nil, NewEOFError()
+	}
+	return line, nil
+}
+
+// tcpSocketRead reads everything up to the end of the stream, or at most
+// length bytes, returning nil at the end of the stream then
+func tcpSocketRead(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] == NIL {
+		var content []byte
+		r.withoutInterpreter(func() {
+			content, err = io.ReadAll(sock.reader)
+		})
+		if err != nil {
+			return nil, socketError(err, "read")
+		}
+		return &String{Value: string(content)}, nil
+	}
+	length, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if length.Value < 0 {
+		return nil, NewArgumentError("negative length %d given", length.Value)
+	}
+	buf := make([]byte, length.Value)
+	var n int
+	r.withoutInterpreter(func() {
+		n, err = io.ReadFull(sock.reader, buf)
+	})
+	if err == io.EOF && length.Value > 0 {
+		return NIL, nil
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, socketError(err, "read")
+	}
+	return &String{Value: string(buf[:n])}, nil
+}
+
+// tcpSocketRecv returns up to maxlen bytes as soon as some are available,
+// and an empty String at the end of the stream. Flags are ignored.
+func tcpSocketRecv(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	maxlen, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if maxlen.Value < 0 {
+		return nil, NewArgumentError("negative buffer size (or size too big)")
+	}
+	buf := make([]byte, maxlen.Value)
+	var n int
+	r.withoutInterpreter(func() {
+		n, err = sock.reader.Read(buf)
+	})
+	if err != nil && err != io.EOF {
+		return nil, socketError(err, "recvfrom")
+	}
+	return &String{Value: string(buf[:n])}, nil
+}
+
+func tcpSocketEOF(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	r.withoutInterpreter(func() {
+		_, err = sock.reader.Peek(1)
+	})
+	return nativeBoolToBoolean(err != nil), nil
+}
+
+// write writes str to the connection, returning the number of bytes
+// written
+func (s *TCPSocket) write(r *Runtime, str string) (int, error) {
+	var n int
+	var err error
+	r.withoutInterpreter(func() {
+		n, err = io.WriteString(s.conn, str)
+	})
+	if err != nil {
+		return n, socketError(err, "write")
+	}
+	return n, nil
+}
+
+// tcpSocketWrite writes the arguments converted by to_s and returns the
+// number of bytes written
+func tcpSocketWrite(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	args, _ = extractBlock(args)
+	var out strings.Builder
+	for _, arg := range args {
+		out.WriteString(toS(arg))
+	}
+	n, err := sock.write(r, out.String())
+	if err != nil {
+		return nil, err
+	}
+	return NewInteger(int64(n)), nil
+}
+
+// tcpSocketSend writes the message like write, ignoring the flags
+func tcpSocketSend(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := integerArgument(args[1]); err != nil {
+		return nil, err
+	}
+	return tcpSocketWrite(r, context, args[0])
+}
+
+func tcpSocketPrint(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := tcpSocketWrite(r, context, args...); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+// tcpSocketPuts writes the arguments converted by to_s, and the elements
+// of Arrays, followed by a newline unless they end with one
+func tcpSocketPuts(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openTCPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	args, _ = extractBlock(args)
+	if _, err := sock.write(r, putsLines(args)); err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+func tcpSocketAppend(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := tcpSocketWrite(r, context, args...); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+// tcpSocketFlush does nothing as writes are not buffered
+func tcpSocketFlush(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := openTCPSocket(context); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
+
+var tcpServerClassMethods = map[string]RubyMethod{
+	"new":  withArityRange(1, 2, publicMethod(tcpServerNew)),
+	"open": withArityRange(1, 2, publicMethod(tcpServerNew)),
+}
+
+// tcpServerNew listens on port, on all interfaces or the ones of a host
+// passed first. Port 0 picks a free port.
+func tcpServerNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	host, port := RubyObject(NIL), args[0]
+	if len(args) == 2 {
+		host, port = args[0], args[1]
+	}
+	address, err := socketAddress(host, port)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, socketError(err, "bind(2) for "+address)
+	}
+	return &TCPServer{listener: listener}, nil
+}
+
+var tcpServerMethods = map[string]RubyMethod{
+	"accept":          withArity(0, publicRuntimeMethod(tcpServerAccept)),
+	"accept_nonblock": withArityRange(0, 1, publicMethod(tcpServerAcceptNonblock)),
+	"listen":          withArity(1, publicMethod(tcpServerListen)),
+}
+
+// tcpServerAccept waits for the next connection and returns it as
+// TCPSocket, letting other Threads run meanwhile. Closing the server
+// from another Thread raises an IOError.
+func tcpServerAccept(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	r.withoutInterpreter(func() {
+		conn, err = sock.(*TCPServer).listener.Accept()
+	})
+	if err != nil {
+		return nil, socketError(err, "accept")
+	}
+	return newTCPSocket(conn), nil
+}
+
+// tcpServerAcceptNonblock accepts a pending connection. Without one it
+// raises an IOError, or returns :wait_readable if exception: false is
+// passed.
+func tcpServerAcceptNonblock(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	raise := true
+	if len(args) == 1 {
+		if options, ok := args[0].(*Hash); ok {
+			if exception, ok := options.Get(NewSymbol("exception")); ok {
+				raise = isTruthy(exception)
+			}
+		}
+	}
+	listener, ok := sock.(*TCPServer).listener.(*net.TCPListener)
+	if !ok {
+		return nil, NewNotImplementedError("accept_nonblock() function is unimplemented on this machine")
+	}
+	listener.SetDeadline(time.Now())
+	conn, err := listener.Accept()
+	listener.SetDeadline(time.Time{})
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		if !raise {
+			return NewSymbol("wait_readable"), nil
+		}
+		return nil, NewIOError("Resource temporarily unavailable - accept(2) would block")
+	}
+	if err != nil {
+		return nil, socketError(err, "accept")
+	}
+	return newTCPSocket(conn), nil
+}
+
+// tcpServerListen is accepted for compatibility, the backlog being chosen
+// by the operating system
+func tcpServerListen(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if _, err := integerArgument(args[0]); err != nil {
+		return nil, err
+	}
+	return NewInteger(0), nil
+}
+
+var udpSocketClassMethods = map[string]RubyMethod{
+	"new":  withArityRange(0, 1, publicMethod(udpSocketNew)),
+	"open": withArityRange(0, 1, publicMethod(udpSocketNew)),
+}
+
+// udpSocketNew returns an unbound UDPSocket. The address family argument
+// is accepted for compatibility.
+func udpSocketNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &UDPSocket{}, nil
+}
+
+var udpSocketMethods = map[string]RubyMethod{
+	"bind":     withArity(2, publicMethod(udpSocketBind)),
+	"connect":  withArity(2, publicMethod(udpSocketConnect)),
+	"send":     withArityRange(2, 4, publicMethod(udpSocketSend)),
+	"recv":     withArityRange(1, 2, publicRuntimeMethod(udpSocketRecv)),
+	"recvfrom": withArityRange(1, 2, publicRuntimeMethod(udpSocketRecvfrom)),
+}
+
+// openUDPSocket returns context as UDPSocket, raising an IOError if it is
+// closed
+func openUDPSocket(context RubyObject) (*UDPSocket, error) {
+	if _, err := openSocket(context); err != nil {
+		return nil, err
+	}
+	return context.(*UDPSocket), nil
+}
+
+func resolveUDPAddr(host, port RubyObject) (*net.UDPAddr, error) {
+	address, err := socketAddress(host, port)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, socketError(err, "getaddrinfo")
+	}
+	return addr, nil
+}
+
+// udpSocketBind binds the socket to the local host and port, where port 0
+// picks a free port
+func udpSocketBind(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openUDPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	if sock.conn != nil {
+		return nil, NewSocketError("bind(2): Invalid argument - socket is bound already")
+	}
+	addr, err := resolveUDPAddr(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if sock.conn, err = net.ListenUDP("udp", addr); err != nil {
+		return nil, socketError(err, "bind(2)")
+	}
+	return NewInteger(0), nil
+}
+
+// udpSocketConnect sets the default destination of the datagrams sent and
+// limits the ones received to the ones from there
+func udpSocketConnect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openUDPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	addr, err := resolveUDPAddr(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	var local *net.UDPAddr
+	if sock.conn != nil {
+		local = sock.conn.LocalAddr().(*net.UDPAddr)
+		sock.conn.Close()
+	}
+	if sock.conn, err = net.DialUDP("udp", local, addr); err != nil {
+		return nil, socketError(err, "connect(2)")
+	}
+	sock.connected = true
+	return NewInteger(0), nil
+}
+
+// udpSocketSend sends the message to host and port, or to the address
+// connected to without them, and returns the number of bytes sent. Flags
+// are ignored.
+func udpSocketSend(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openUDPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := integerArgument(args[1]); err != nil {
+		return nil, err
+	}
+	var n int
+	switch len(args) {
+	case 2:
+		if !sock.connected {
+			return nil, NewSocketError("send(2): Destination address required")
+		}
+		n, err = sock.conn.Write([]byte(msg.Value))
+	case 4:
+		addr, err := resolveUDPAddr(args[2], args[3])
+		if err != nil {
+			return nil, err
+		}
+		if sock.conn == nil {
+			if sock.conn, err = net.ListenUDP("udp", &net.UDPAddr{}); err != nil {
+				return nil, socketError(err, "bind(2)")
+			}
+		}
+		if sock.connected {
+			n, err = sock.conn.Write([]byte(msg.Value))
+		} else {
+			n, err = sock.conn.WriteToUDP([]byte(msg.Value), addr)
+		}
+	default:
+		return nil, NewWrongNumberOfArgumentsError(4, len(args))
+	}
+	if err != nil {
+		return nil, socketError(err, "send(2)")
+	}
+	return NewInteger(int64(n)), nil
+}
+
+// receive waits for the next datagram of at most maxlen bytes, letting
+// other Threads run meanwhile
+func (s *UDPSocket) receive(r *Runtime, arg RubyObject) (string, *net.UDPAddr, error) {
+	maxlen, err := integerArgument(arg)
+	if err != nil {
+		return "", nil, err
+	}
+	if maxlen.Value < 0 {
+		return "", nil, NewArgumentError("negative buffer size (or size too big)")
+	}
+	if s.conn == nil {
+		return "", nil, NewSocketError("recvfrom(2): socket is not bound")
+	}
+	buf := make([]byte, maxlen.Value)
+	var n int
+	var addr *net.UDPAddr
+	r.withoutInterpreter(func() {
+		n, addr, err = s.conn.ReadFromUDP(buf)
+	})
+	if err != nil {
+		return "", nil, socketError(err, "recvfrom(2)")
+	}
+	return string(buf[:n]), addr, nil
+}
+
+// udpSocketRecv returns the next datagram, truncated to maxlen bytes
+func udpSocketRecv(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openUDPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	msg, _, err := sock.receive(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: msg}, nil
+}
+
+// udpSocketRecvfrom returns the next datagram and the address of the
+// sender, like `["hello", ["AF_INET", 4242, "127.0.0.1", "127.0.0.1"]]`
+func udpSocketRecvfrom(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	sock, err := openUDPSocket(context)
+	if err != nil {
+		return nil, err
+	}
+	msg, addr, err := sock.receive(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewArray(&String{Value: msg}, addrArray(addr)), nil
+}
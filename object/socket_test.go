@@ -0,0 +1,115 @@
+package object
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestTCPServerAccept(t *testing.T) {
+	server, err := Send(tcpServerClass, "new", &String{Value: "127.0.0.1"}, NewInteger(0))
+	checkError(t, err, nil)
+	defer Send(server, "close")
+	addr, err := Send(server, "addr")
+	checkError(t, err, nil)
+	port := addr.(*Array).Elements[1].(*Integer).Value
+
+	replies := make(chan string, 1)
+	go func() {
+		conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.FormatInt(port, 10)))
+		if err != nil {
+			replies <- err.Error()
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("ping\n"))
+		reply, _ := bufio.NewReader(conn).ReadString('\n')
+		replies <- reply
+	}()
+
+	client, err := Send(server, "accept")
+	checkError(t, err, nil)
+	line, err := Send(client, "gets")
+	checkError(t, err, nil)
+	checkResult(t, line, &String{Value: "ping\n"})
+
+	_, err = Send(client, "puts", &String{Value: "pong"})
+	checkError(t, err, nil)
+	if reply := <-replies; reply != "pong\n" {
+		t.Errorf("Expected the client to receive %q, got %q", "pong\n", reply)
+	}
+
+	line, err = Send(client, "gets")
+	checkError(t, err, nil)
+	checkResult(t, line, NIL)
+
+	_, err = Send(client, "close")
+	checkError(t, err, nil)
+	_, err = Send(client, "gets")
+	checkError(t, err, NewIOError("closed stream"))
+}
+
+func TestTCPSocketNew(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("echo: " + line))
+	}()
+	port := int64(listener.Addr().(*net.TCPAddr).Port)
+
+	socket, err := Send(tcpSocketClass, "new", &String{Value: "127.0.0.1"}, NewInteger(port))
+	checkError(t, err, nil)
+	n, err := Send(socket, "write", &String{Value: "hi\n"})
+	checkError(t, err, nil)
+	checkResult(t, n, NewInteger(3))
+
+	content, err := Send(socket, "read")
+	checkError(t, err, nil)
+	checkResult(t, content, &String{Value: "echo: hi\n"})
+	_, err = Send(socket, "readline")
+	checkError(t, err, NewEOFError())
+
+	listener.Close()
+	_, err = Send(tcpSocketClass, "new", &String{Value: "127.0.0.1"}, NewInteger(port))
+	if _, ok := err.(*SocketError); !ok {
+		t.Errorf("Expected a SocketError, got %T (%v)", err, err)
+	}
+}
+
+func TestUDPSocket(t *testing.T) {
+	receiver, err := Send(udpSocketClass, "new")
+	checkError(t, err, nil)
+	defer Send(receiver, "close")
+	_, err = Send(receiver, "bind", &String{Value: "127.0.0.1"}, NewInteger(0))
+	checkError(t, err, nil)
+	addr, err := Send(receiver, "addr")
+	checkError(t, err, nil)
+	port := addr.(*Array).Elements[1]
+
+	sender, err := Send(udpSocketClass, "new")
+	checkError(t, err, nil)
+	defer Send(sender, "close")
+	n, err := Send(sender, "send", &String{Value: "hello"}, NewInteger(0), &String{Value: "127.0.0.1"}, port)
+	checkError(t, err, nil)
+	checkResult(t, n, NewInteger(5))
+
+	received, err := Send(receiver, "recvfrom", NewInteger(16))
+	checkError(t, err, nil)
+	elements := received.(*Array).Elements
+	checkResult(t, elements[0], &String{Value: "hello"})
+	senderAddr, err := Send(sender, "addr")
+	checkError(t, err, nil)
+	checkResult(t, elements[1].(*Array).Elements[1], senderAddr.(*Array).Elements[1])
+
+	_, err = Send(sender, "send", &String{Value: "hello"}, NewInteger(0))
+	checkError(t, err, NewSocketError("send(2): Destination address required"))
+}
@@ -0,0 +1,73 @@
+package object
+
+import "io"
+
+// Streams are the standard streams the Kernel functions like puts and gets
+// use
+type Streams struct {
+	Stdin  *IO
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// DefaultStreams returns the process wide streams Stdin, Stdout and Stderr
+func DefaultStreams() *Streams {
+	return &Streams{Stdin: Stdin, Stdout: Stdout, Stderr: Stderr}
+}
+
+// A streamFunction is a variant of a Kernel method using the standard
+// streams, which takes the Runtime it is called within and the streams to
+// use
+type streamFunction func(r *Runtime, streams *Streams, args ...RubyObject) (RubyObject, error)
+
+// streamFunctions are the variants of the Kernel methods using the standard
+// streams
+var streamFunctions = map[string]streamFunction{
+	"puts":   anyRuntime(putsTo),
+	"print":  anyRuntime(printTo),
+	"p":      anyRuntime(pTo),
+	"pp":     anyRuntime(ppTo),
+	"gets":   getsFrom,
+	"printf": anyRuntime(printfTo),
+	"warn":   kernelWarnTo,
+}
+
+// anyRuntime returns fn as streamFunction for functions which work the
+// same within every Runtime
+func anyRuntime(fn func(streams *Streams, args ...RubyObject) (RubyObject, error)) streamFunction {
+	return func(r *Runtime, streams *Streams, args ...RubyObject) (RubyObject, error) {
+		return fn(streams, args...)
+	}
+}
+
+// builtinStreamMethods are the Kernel methods of streamFunctions as
+// defined initially, before they may have been redefined
+var builtinStreamMethods = map[string]RubyMethod{}
+
+func init() {
+	for name := range streamFunctions {
+		builtinStreamMethods[name] = kernelMethodSet[name]
+	}
+}
+
+// CallWithStreams calls the Kernel method name using the standard streams
+// on self within r like a call without receiver, but with streams in place
+// of the process wide ones. It reports false if name is no such method or
+// self overrides it, in which case it is to be sent as usual.
+func (r *Runtime) CallWithStreams(streams *Streams, self RubyObject, name string, args ...RubyObject) (RubyObject, bool, error) {
+	fn, ok := streamFunctions[name]
+	if !ok {
+		return nil, false, nil
+	}
+	found, ok := r.findMethod(unwrapSelf(self), name)
+	if !ok || found != builtinStreamMethods[name] {
+		return nil, false, nil
+	}
+	if m, ok := found.(*method); ok && m.arity.checked {
+		if err := m.arity.check(args); err != nil {
+			return nil, true, err
+		}
+	}
+	result, err := fn(r, streams, args...)
+	return result, true, err
+}
@@ -1,16 +1,69 @@
 package object
 
-var stringClass RubyClassObject = newClass("String", objectClass, stringMethods, stringClassMethods)
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var stringClass RubyClassObject = mixin(newClass("String", objectClass, stringMethods, stringClassMethods), comparableModule)
 
 func init() {
 	classes.Set("String", stringClass)
 }
 
+// frozenStrings holds the deduplicated frozen strings returned by
+// NewFrozenString
+var frozenStrings = struct {
+	sync.Mutex
+	table map[string]*String
+}{table: make(map[string]*String)}
+
+// NewFrozenString returns a frozen String with the given value. All calls
+// with the same value return the same instance, so it is suited for string
+// literals under the `frozen_string_literal` magic comment.
+func NewFrozenString(value string) *String {
+	frozenStrings.Lock()
+	defer frozenStrings.Unlock()
+	str, ok := frozenStrings.table[value]
+	if !ok {
+		str = &String{Value: value, frozen: true}
+		frozenStrings.table[value] = str
+	}
+	return str
+}
+
 // String represents a string in Ruby
 type String struct {
-	Value string
+	Value  string
+	frozen bool
+	// builder holds the content of Value if it has been built by
+	// appending, so that appending again does not copy it
+	builder *strings.Builder
+}
+
+// append appends other to the value of s in amortized constant time, as
+// long as Value is not replaced in between
+func (s *String) append(other string) {
+	// the comparison is cheap as long as Value and the built string share
+	// their memory
+	if s.builder == nil || s.builder.String() != s.Value {
+		s.builder = &strings.Builder{}
+		s.builder.Grow(2 * (len(s.Value) + len(other)))
+		s.builder.WriteString(s.Value)
+	}
+	s.builder.WriteString(other)
+	s.Value = s.builder.String()
 }
 
+// Frozen returns true if the string can not be modified anymore
+func (s *String) Frozen() bool { return s.frozen }
+
+// Freeze prevents any further modifications of the string
+func (s *String) Freeze() { s.frozen = true }
+
 // Inspect returns the Value
 func (s *String) Inspect() string { return s.Value }
 
@@ -20,6 +73,10 @@ func (s *String) Type() Type { return STRING_OBJ }
 // Class returns stringClass
 func (s *String) Class() RubyClass { return stringClass }
 
+func (s *String) hashKey() hashKey {
+	return hashKey{Type: s.Type(), Value: s.Value}
+}
+
 var stringClassMethods = map[string]RubyMethod{
 	"new": publicMethod(func(context RubyObject, args ...RubyObject) (RubyObject, error) {
 		switch len(args) {
@@ -39,9 +96,716 @@ var stringClassMethods = map[string]RubyMethod{
 
 var stringMethods = map[string]RubyMethod{
 	"to_s": withArity(0, publicMethod(stringToS)),
+	"to_i": withArityRange(0, 1, publicMethod(stringToI)),
+	"<=>":  withArity(1, publicMethod(stringSpaceship)),
+	"<<":   withArity(1, publicMethod(stringAppend)),
+	"+":    withArity(1, publicMethod(stringAdd)),
+	"*":    withArity(1, publicMethod(stringMultiply)),
+	"%":    withArity(1, publicMethod(stringFormat)),
+
+	"length":      withArity(0, publicMethod(stringLength)),
+	"size":        withArity(0, publicMethod(stringLength)),
+	"empty?":      withArity(0, publicMethod(stringIsEmpty)),
+	"include?":    withArity(1, publicMethod(stringInclude)),
+	"start_with?": publicMethod(stringStartWith),
+	"end_with?":   publicMethod(stringEndWith),
+	"index":       withArityRange(1, 2, publicMethod(stringIndex)),
+
+	"upcase":     withArity(0, publicMethod(stringUpcase)),
+	"downcase":   withArity(0, publicMethod(stringDowncase)),
+	"capitalize": withArity(0, publicMethod(stringCapitalize)),
+	"reverse":    withArity(0, publicMethod(stringReverse)),
+	"strip":      withArity(0, publicMethod(stringStrip)),
+	"lstrip":     withArity(0, publicMethod(stringLstrip)),
+	"rstrip":     withArity(0, publicMethod(stringRstrip)),
+	"chomp":      withArityRange(0, 1, publicMethod(stringChomp)),
+	"center":     withArityRange(1, 2, publicMethod(stringCenter)),
+	"ljust":      withArityRange(1, 2, publicMethod(stringLjust)),
+	"rjust":      withArityRange(1, 2, publicMethod(stringRjust)),
+
+	"=~":    withArity(1, publicRuntimeMethod(stringMatchOperator)),
+	"match": withArityRange(1, 2, publicRuntimeMethod(stringMatch)),
+	"scan":  withArity(1, publicMethod(stringScan)),
+	"sub":   withArityRange(1, 2, publicMethod(stringSub)),
+	"gsub":  withArityRange(1, 2, publicMethod(stringGsub)),
+	"split": withArityRange(0, 2, publicMethod(stringSplit)),
+
+	"chars":     withArity(0, publicMethod(stringChars)),
+	"bytes":     withArity(0, publicMethod(stringBytes)),
+	"lines":     withArityRange(0, 1, publicMethod(stringLines)),
+	"each_char": withArity(0, publicMethod(stringEachChar)),
+	"each_line": withArityRange(0, 1, publicMethod(stringEachLine)),
+	"[]":        withArityRange(1, 2, publicMethod(stringSlice)),
+	"slice":     withArityRange(1, 2, publicMethod(stringSlice)),
+}
+
+// leftWhitespace contains all characters removed by String#lstrip,
+// rightWhitespace those removed by String#rstrip
+const (
+	leftWhitespace  = " \t\n\v\f\r"
+	rightWhitespace = leftWhitespace + "\x00"
+)
+
+// stringArgument returns arg as String or a TypeError if it is none
+func stringArgument(arg RubyObject) (*String, error) {
+	str, ok := arg.(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, arg)
+	}
+	return str, nil
+}
+
+// integerArgument returns arg as Integer or a TypeError if it is none
+func integerArgument(arg RubyObject) (*Integer, error) {
+	i, ok := arg.(*Integer)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&Integer{}, arg)
+	}
+	return i, nil
 }
 
 func stringToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
 	str := context.(*String)
-	return &String{str.Value}, nil
+	return &String{Value: str.Value}, nil
+}
+
+func stringSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	other, ok := args[0].(*String)
+	if !ok {
+		return NIL, nil
+	}
+	return NewInteger(int64(strings.Compare(str.Value, other.Value))), nil
+}
+
+func stringAppend(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	if str.frozen {
+		return nil, NewFrozenError(str)
+	}
+	other, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(str, args[0])
+	}
+	str.append(other.Value)
+	return str, nil
+}
+
+func stringAdd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	other, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: str.Value + other.Value}, nil
+}
+
+func stringMultiply(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	times, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if times.Value < 0 {
+		return nil, NewArgumentError("negative argument")
+	}
+	return &String{Value: strings.Repeat(str.Value, int(times.Value))}, nil
+}
+
+func stringLength(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return NewInteger(int64(utf8.RuneCountInString(str.Value))), nil
+}
+
+func stringIsEmpty(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return nativeBoolToBoolean(str.Value == ""), nil
+}
+
+func stringInclude(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	other, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(strings.Contains(str.Value, other.Value)), nil
+}
+
+func stringStartWith(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	for _, arg := range args {
+		prefix, err := stringArgument(arg)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(str.Value, prefix.Value) {
+			return TRUE, nil
+		}
+	}
+	return FALSE, nil
+}
+
+func stringEndWith(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	for _, arg := range args {
+		suffix, err := stringArgument(arg)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(str.Value, suffix.Value) {
+			return TRUE, nil
+		}
+	}
+	return FALSE, nil
+}
+
+func stringIndex(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	substr, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	chars := []rune(str.Value)
+	offset := 0
+	if len(args) == 2 {
+		start, err := integerArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		offset = int(start.Value)
+		if offset < 0 {
+			offset += len(chars)
+		}
+		if offset < 0 || offset > len(chars) {
+			return NIL, nil
+		}
+	}
+	rest := string(chars[offset:])
+	index := strings.Index(rest, substr.Value)
+	if index < 0 {
+		return NIL, nil
+	}
+	return NewInteger(int64(offset + utf8.RuneCountInString(rest[:index]))), nil
+}
+
+func stringUpcase(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return &String{Value: strings.ToUpper(str.Value)}, nil
+}
+
+func stringDowncase(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return &String{Value: strings.ToLower(str.Value)}, nil
+}
+
+func stringCapitalize(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	first, size := utf8.DecodeRuneInString(str.Value)
+	if size == 0 {
+		return &String{}, nil
+	}
+	capitalized := string(unicode.ToUpper(first)) + strings.ToLower(str.Value[size:])
+	return &String{Value: capitalized}, nil
+}
+
+func stringReverse(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	chars := []rune(str.Value)
+	for i, j := 0, len(chars)-1; i < j; i, j = i+1, j-1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	return &String{Value: string(chars)}, nil
+}
+
+func stringStrip(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	stripped := strings.TrimRight(strings.TrimLeft(str.Value, leftWhitespace), rightWhitespace)
+	return &String{Value: stripped}, nil
+}
+
+func stringLstrip(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return &String{Value: strings.TrimLeft(str.Value, leftWhitespace)}, nil
+}
+
+func stringRstrip(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	return &String{Value: strings.TrimRight(str.Value, rightWhitespace)}, nil
+}
+
+// stringChomp returns the string without the trailing line separator, or
+// without the suffix given. An empty suffix removes all trailing line
+// separators.
+func stringChomp(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	value := context.(*String).Value
+	if len(args) == 0 {
+		if strings.HasSuffix(value, "\r\n") {
+			return &String{Value: value[:len(value)-2]}, nil
+		}
+		return &String{Value: strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")}, nil
+	}
+	suffix, err := stringArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if suffix.Value == "" {
+		return &String{Value: strings.TrimRight(value, "\r\n")}, nil
+	}
+	return &String{Value: strings.TrimSuffix(value, suffix.Value)}, nil
+}
+
+func stringCenter(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return stringJustify(context.(*String), args, func(padding int) (int, int) {
+		return padding / 2, padding - padding/2
+	})
+}
+
+func stringLjust(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return stringJustify(context.(*String), args, func(padding int) (int, int) {
+		return 0, padding
+	})
+}
+
+func stringRjust(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return stringJustify(context.(*String), args, func(padding int) (int, int) {
+		return padding, 0
+	})
+}
+
+// stringJustify pads str to the width given by args[0] with the optional
+// pad string args[1]. split distributes the needed padding between the left
+// and right side of str.
+func stringJustify(str *String, args []RubyObject, split func(padding int) (left, right int)) (RubyObject, error) {
+	width, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	pad := " "
+	if len(args) == 2 {
+		padStr, err := stringArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if padStr.Value == "" {
+			return nil, NewArgumentError("zero width padding")
+		}
+		pad = padStr.Value
+	}
+	padding := int(width.Value) - utf8.RuneCountInString(str.Value)
+	if padding <= 0 {
+		return &String{Value: str.Value}, nil
+	}
+	left, right := split(padding)
+	return &String{Value: padString(pad, left) + str.Value + padString(pad, right)}, nil
+}
+
+// padString returns a string of n characters built by repeating pad
+func padString(pad string, n int) string {
+	chars := []rune(strings.Repeat(pad, n/utf8.RuneCountInString(pad)+1))
+	return string(chars[:n])
+}
+
+func stringMatchOperator(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	if _, ok := args[0].(*String); ok {
+		return nil, NewTypeError("wrong argument type String (expected Regexp)")
+	}
+	return r.Send(args[0], "=~", str)
+}
+
+func stringMatch(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	re, err := regexpArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return regexpMatch(r, re, append([]RubyObject{str}, args[1:]...)...)
+}
+
+func stringScan(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	args, block := extractBlock(args)
+	re, err := regexpArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	results := NewArray()
+	for _, match := range re.MatchAll(str.Value) {
+		var result RubyObject = match.groupObject(0)
+		if match.Len() > 1 {
+			result = NewArray(match.captures()...)
+		}
+		if block != nil {
+			if _, err := block.Call(result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		results.Elements = append(results.Elements, result)
+	}
+	if block != nil {
+		return str, nil
+	}
+	return results, nil
+}
+
+func stringSub(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return stringReplace(context.(*String), args, false)
+}
+
+func stringGsub(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return stringReplace(context.(*String), args, true)
+}
+
+// stringReplace replaces the first or, if global is true, all matches of
+// args[0] within str. The replacement is either given as args[1], which can
+// contain backreferences like `\1`, or computed by the block.
+func stringReplace(str *String, args []RubyObject, global bool) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if len(args) == 1 && block == nil {
+		return nil, NewWrongNumberOfArgumentsError(2, 1)
+	}
+	re, err := regexpArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var replacement *String
+	if len(args) == 2 {
+		replacement, err = stringArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	matches := re.MatchAll(str.Value)
+	if !global && len(matches) > 1 {
+		matches = matches[:1]
+	}
+	var out bytes.Buffer
+	last := 0
+	for _, match := range matches {
+		out.WriteString(str.Value[last:match.offsets[0]])
+		last = match.offsets[1]
+		if replacement != nil {
+			out.WriteString(expandReplacement(replacement.Value, match))
+			continue
+		}
+		result, err := block.Call(match.groupObject(0))
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(toS(result))
+	}
+	out.WriteString(str.Value[last:])
+	return &String{Value: out.String()}, nil
+}
+
+// expandReplacement replaces the backreferences within replacement, i.e.
+// `\0` to `\9`, `\&`, `\k<name>`, "\`", `\'` and `\\`, with the respective
+// parts of match
+func expandReplacement(replacement string, match *MatchData) string {
+	var out bytes.Buffer
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c != '\\' || i+1 == len(replacement) {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		switch next := replacement[i]; {
+		case '0' <= next && next <= '9':
+			group, _ := match.Group(int(next - '0'))
+			out.WriteString(group)
+		case next == '&':
+			out.WriteString(match.String())
+		case next == '`':
+			out.WriteString(match.Target[:match.offsets[0]])
+		case next == '\'':
+			out.WriteString(match.Target[match.offsets[1]:])
+		case next == '\\':
+			out.WriteByte('\\')
+		case next == 'k' && strings.HasPrefix(replacement[i+1:], "<") && strings.Contains(replacement[i:], ">"):
+			end := i + strings.IndexByte(replacement[i:], '>')
+			group, _, _ := match.NamedGroup(replacement[i+2 : end])
+			out.WriteString(group)
+			i = end
+		default:
+			out.WriteByte(c)
+			out.WriteByte(next)
+		}
+	}
+	return out.String()
+}
+
+func stringSplit(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	limit := 0
+	if len(args) == 2 {
+		l, err := integerArgument(args[1])
+		if err != nil {
+			return nil, err
+		}
+		limit = int(l.Value)
+	}
+	var fields []string
+	pattern := RubyObject(NIL)
+	if len(args) > 0 {
+		pattern = args[0]
+	}
+	if sep, ok := pattern.(*String); pattern == NIL || ok && sep.Value == " " {
+		fields = splitWhitespace(str.Value, limit)
+	} else {
+		re, err := regexpArgument(pattern)
+		if err != nil {
+			return nil, err
+		}
+		fields = splitRegexp(str.Value, re, limit)
+	}
+	if limit == 0 {
+		for len(fields) > 0 && fields[len(fields)-1] == "" {
+			fields = fields[:len(fields)-1]
+		}
+	}
+	result := NewArray()
+	for _, field := range fields {
+		result.Elements = append(result.Elements, &String{Value: field})
+	}
+	return result, nil
+}
+
+// splitWhitespace splits s at runs of whitespace, ignoring leading
+// whitespace. A positive limit restricts the number of fields.
+func splitWhitespace(s string, limit int) []string {
+	s = strings.TrimLeft(s, leftWhitespace)
+	var fields []string
+	for s != "" {
+		if limit > 0 && len(fields) == limit-1 {
+			return append(fields, s)
+		}
+		end := strings.IndexAny(s, leftWhitespace)
+		if end < 0 {
+			return append(fields, s)
+		}
+		fields = append(fields, s[:end])
+		s = strings.TrimLeft(s[end:], leftWhitespace)
+		if s == "" {
+			fields = append(fields, "")
+		}
+	}
+	return fields
+}
+
+// splitRegexp splits s at the matches of re. Captured groups are included
+// in the result. A positive limit restricts the number of fields. An empty
+// match splits s into its characters.
+func splitRegexp(s string, re *Regexp, limit int) []string {
+	var fields []string
+	last := 0
+	for _, match := range re.MatchAll(s) {
+		if limit > 0 && len(fields) == limit-1 {
+			break
+		}
+		start, end := match.offsets[0], match.offsets[1]
+		if start == end {
+			if start == 0 || start >= len(s) {
+				continue
+			}
+		}
+		fields = append(fields, s[last:start])
+		for i := 1; i < match.Len(); i++ {
+			if group, ok := match.Group(i); ok {
+				fields = append(fields, group)
+			}
+		}
+		last = end
+	}
+	if last > len(s) {
+		return fields
+	}
+	return append(fields, s[last:])
+}
+
+func stringChars(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	chars := NewArray()
+	for _, char := range str.Value {
+		chars.Elements = append(chars.Elements, &String{Value: string(char)})
+	}
+	return chars, nil
+}
+
+func stringBytes(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	bytes := NewArray()
+	for i := 0; i < len(str.Value); i++ {
+		bytes.Elements = append(bytes.Elements, NewInteger(int64(str.Value[i])))
+	}
+	return bytes, nil
+}
+
+// stringLineSeparator returns the separator given as optional first argument
+// to String#lines and String#each_line, defaulting to a newline
+func stringLineSeparator(args []RubyObject) (string, error) {
+	if len(args) == 0 {
+		return "\n", nil
+	}
+	separator, err := stringArgument(args[0])
+	if err != nil {
+		return "", err
+	}
+	if separator.Value == "" {
+		return "", NewNotImplementedError("paragraph mode is not supported")
+	}
+	return separator.Value, nil
+}
+
+func stringLines(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	separator, err := stringLineSeparator(args)
+	if err != nil {
+		return nil, err
+	}
+	lines := NewArray()
+	for _, line := range strings.SplitAfter(str.Value, separator) {
+		if line != "" {
+			lines.Elements = append(lines.Elements, &String{Value: line})
+		}
+	}
+	return lines, nil
+}
+
+// stringEachChar yields every character of the string to the block. Without
+// a block it returns the characters as Array.
+func stringEachChar(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	chars, _ := stringChars(context)
+	if block == nil {
+		return chars, nil
+	}
+	for _, char := range chars.(*Array).Elements {
+		if _, err := block.Call(char); err != nil {
+			return nil, err
+		}
+	}
+	return context, nil
+}
+
+// stringEachLine yields every line of the string, including its separator,
+// to the block. Without a block it returns the lines as Array.
+func stringEachLine(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	lines, err := stringLines(context, args...)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return lines, nil
+	}
+	for _, line := range lines.(*Array).Elements {
+		if _, err := block.Call(line); err != nil {
+			return nil, err
+		}
+	}
+	return context, nil
+}
+
+// stringSlice implements String#[] and String#slice. It accepts a character
+// index, a start index and a length, a Range of indices, a Regexp with an
+// optional capture or a substring.
+func stringSlice(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	chars := []rune(str.Value)
+	switch arg := args[0].(type) {
+	case *Regexp:
+		match := arg.Match(str.Value, 0)
+		if match == nil {
+			return NIL, nil
+		}
+		if len(args) == 2 {
+			return matchDataIndex(match, args[1])
+		}
+		return match.groupObject(0), nil
+	case *String:
+		if len(args) == 2 {
+			return nil, NewWrongNumberOfArgumentsError(1, 2)
+		}
+		if !strings.Contains(str.Value, arg.Value) {
+			return NIL, nil
+		}
+		return &String{Value: arg.Value}, nil
+	case *Range:
+		if len(args) == 2 {
+			return nil, NewWrongNumberOfArgumentsError(1, 2)
+		}
+		start, count, ok, err := arg.indices(len(chars))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return NIL, nil
+		}
+		return &String{Value: string(chars[start : start+count])}, nil
+	}
+	index, err := integerArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	start := int(index.Value)
+	if start < 0 {
+		start += len(chars)
+	}
+	if len(args) == 1 {
+		if start < 0 || start >= len(chars) {
+			return NIL, nil
+		}
+		return &String{Value: string(chars[start])}, nil
+	}
+	length, err := integerArgument(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if length.Value < 0 || start < 0 || start > len(chars) {
+		return NIL, nil
+	}
+	end := start + int(length.Value)
+	if end > len(chars) || end < start {
+		end = len(chars)
+	}
+	return &String{Value: string(chars[start:end])}, nil
+}
+
+// toS returns the string representation of obj, like `to_s` in Ruby
+func toS(obj RubyObject) string {
+	switch obj := obj.(type) {
+	case *String:
+		return obj.Value
+	case *Symbol:
+		return obj.Value
+	case *nilObject:
+		return ""
+	case *Object:
+		return "#<" + className(obj) + ">"
+	default:
+		return obj.Inspect()
+	}
+}
+
+// stringToI interprets the leading characters of the string as integer in
+// the given radix, which defaults to 10. A radix of 0 determines the radix
+// from the prefix of the number, e.g. 0x for 16. If there is no valid
+// number at the start of the string it returns 0.
+func stringToI(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	str := context.(*String)
+	radix := 10
+	if len(args) == 1 {
+		if base, ok := args[0].(*Integer); ok && base.Value == 0 {
+			radix = 0
+		} else {
+			var err error
+			if radix, err = radixArgument(args); err != nil {
+				return nil, err
+			}
+		}
+	}
+	value, _ := parseInteger(str.Value, radix, false)
+	return NewInteger(value), nil
 }
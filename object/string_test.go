@@ -0,0 +1,340 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewFrozenString(t *testing.T) {
+	str := NewFrozenString("foo")
+
+	if !str.Frozen() {
+		t.Logf("Expected string to be frozen")
+		t.Fail()
+	}
+
+	if other := NewFrozenString("foo"); other != str {
+		t.Logf("Expected strings with equal values to be the same instance")
+		t.Fail()
+	}
+
+	if other := NewFrozenString("bar"); other == str {
+		t.Logf("Expected strings with different values to be different instances")
+		t.Fail()
+	}
+}
+
+func TestStringAppend(t *testing.T) {
+	t.Run("regular string", func(t *testing.T) {
+		str := &String{Value: "foo"}
+
+		result, err := stringAppend(str, &String{Value: "bar"})
+
+		checkError(t, err, nil)
+		if result != str {
+			t.Logf("Expected << to return its receiver, got %#v", result)
+			t.Fail()
+		}
+		if str.Value != "foobar" {
+			t.Logf("Expected value to equal %q, got %q", "foobar", str.Value)
+			t.Fail()
+		}
+	})
+	t.Run("repeated appends", func(t *testing.T) {
+		str := &String{Value: "a"}
+		var snapshots []string
+
+		for i := 0; i < 3; i++ {
+			snapshots = append(snapshots, str.Value)
+			_, err := stringAppend(str, &String{Value: "b"})
+			checkError(t, err, nil)
+		}
+		str.Value = "x"
+		_, err := stringAppend(str, &String{Value: "y"})
+		checkError(t, err, nil)
+
+		if str.Value != "xy" {
+			t.Logf("Expected value to equal %q, got %q", "xy", str.Value)
+			t.Fail()
+		}
+		expected := []string{"a", "ab", "abb"}
+		if !reflect.DeepEqual(snapshots, expected) {
+			t.Logf("Expected earlier values to stay %q, got %q", expected, snapshots)
+			t.Fail()
+		}
+	})
+	t.Run("copied string", func(t *testing.T) {
+		str := &String{Value: "foo"}
+		_, err := stringAppend(str, &String{Value: "bar"})
+		checkError(t, err, nil)
+		copied := *str
+
+		_, err = stringAppend(str, &String{Value: "baz"})
+		checkError(t, err, nil)
+		_, err = stringAppend(&copied, &String{Value: "qux"})
+		checkError(t, err, nil)
+
+		if str.Value != "foobarbaz" {
+			t.Logf("Expected value to equal %q, got %q", "foobarbaz", str.Value)
+			t.Fail()
+		}
+		if copied.Value != "foobarqux" {
+			t.Logf("Expected copied value to equal %q, got %q", "foobarqux", copied.Value)
+			t.Fail()
+		}
+	})
+	t.Run("frozen string", func(t *testing.T) {
+		str := &String{Value: "foo"}
+		str.Freeze()
+
+		_, err := stringAppend(str, &String{Value: "bar"})
+
+		checkError(t, err, NewFrozenError(str))
+		if str.Value != "foo" {
+			t.Logf("Expected value to stay %q, got %q", "foo", str.Value)
+			t.Fail()
+		}
+	})
+	t.Run("non string argument", func(t *testing.T) {
+		str := &String{Value: "foo"}
+
+		_, err := stringAppend(str, NewInteger(3))
+
+		checkError(t, err, NewImplicitConversionTypeError(str, NewInteger(3)))
+	})
+}
+
+func TestStringMethods(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  string
+		args     []RubyObject
+		expected RubyObject
+		err      error
+	}{
+		{"length", stringLength, "héllo", nil, NewInteger(5), nil},
+		{"length empty", stringLength, "", nil, NewInteger(0), nil},
+		{"empty?", stringIsEmpty, "", nil, TRUE, nil},
+		{"empty? non empty", stringIsEmpty, " ", nil, FALSE, nil},
+		{"include?", stringInclude, "hello", []RubyObject{str("ell")}, TRUE, nil},
+		{"include? missing", stringInclude, "hello", []RubyObject{str("elo")}, FALSE, nil},
+		{"include? non string", stringInclude, "hello", []RubyObject{NewInteger(1)}, nil, NewImplicitConversionTypeError(&String{}, NewInteger(1))},
+		{"start_with?", stringStartWith, "hello", []RubyObject{str("x"), str("he")}, TRUE, nil},
+		{"start_with? missing", stringStartWith, "hello", []RubyObject{str("lo")}, FALSE, nil},
+		{"start_with? no args", stringStartWith, "hello", nil, FALSE, nil},
+		{"end_with?", stringEndWith, "hello", []RubyObject{str("lo")}, TRUE, nil},
+		{"end_with? missing", stringEndWith, "hello", []RubyObject{str("he")}, FALSE, nil},
+		{"index", stringIndex, "héllo", []RubyObject{str("l")}, NewInteger(2), nil},
+		{"index missing", stringIndex, "hello", []RubyObject{str("x")}, NIL, nil},
+		{"index with offset", stringIndex, "héllo", []RubyObject{str("l"), NewInteger(3)}, NewInteger(3), nil},
+		{"index with negative offset", stringIndex, "hello", []RubyObject{str("l"), NewInteger(-2)}, NewInteger(3), nil},
+		{"index with offset out of range", stringIndex, "hello", []RubyObject{str("l"), NewInteger(6)}, NIL, nil},
+		{"upcase", stringUpcase, "héllo", nil, str("HÉLLO"), nil},
+		{"downcase", stringDowncase, "HÉLLO", nil, str("héllo"), nil},
+		{"capitalize", stringCapitalize, "éLLO wORLD", nil, str("Éllo world"), nil},
+		{"capitalize empty", stringCapitalize, "", nil, str(""), nil},
+		{"reverse", stringReverse, "héllo", nil, str("olléh"), nil},
+		{"strip", stringStrip, "\t hello \n\x00", nil, str("hello"), nil},
+		{"lstrip", stringLstrip, "  hello  ", nil, str("hello  "), nil},
+		{"rstrip", stringRstrip, "  hello  ", nil, str("  hello"), nil},
+		{"chomp", stringChomp, "hello\r\n", nil, str("hello"), nil},
+		{"chomp once", stringChomp, "hello\n\n", nil, str("hello\n"), nil},
+		{"chomp without newline", stringChomp, "hello", nil, str("hello"), nil},
+		{"chomp suffix", stringChomp, "hello", []RubyObject{str("llo")}, str("he"), nil},
+		{"chomp empty suffix", stringChomp, "hello\n\r\n", []RubyObject{str("")}, str("hello"), nil},
+		{"*", stringMultiply, "ab", []RubyObject{NewInteger(3)}, str("ababab"), nil},
+		{"* zero", stringMultiply, "ab", []RubyObject{NewInteger(0)}, str(""), nil},
+		{"* negative", stringMultiply, "ab", []RubyObject{NewInteger(-1)}, nil, NewArgumentError("negative argument")},
+		{"* non integer", stringMultiply, "ab", []RubyObject{str("a")}, nil, NewImplicitConversionTypeError(&Integer{}, str("a"))},
+		{"center", stringCenter, "abc", []RubyObject{NewInteger(8)}, str("  abc   "), nil},
+		{"center with pad", stringCenter, "abc", []RubyObject{NewInteger(10), str("12")}, str("121abc1212"), nil},
+		{"center too short", stringCenter, "abc", []RubyObject{NewInteger(2)}, str("abc"), nil},
+		{"ljust", stringLjust, "abc", []RubyObject{NewInteger(6), str("é")}, str("abcééé"), nil},
+		{"rjust", stringRjust, "abc", []RubyObject{NewInteger(5)}, str("  abc"), nil},
+		{"rjust empty pad", stringRjust, "abc", []RubyObject{NewInteger(5), str("")}, nil, NewArgumentError("zero width padding")},
+		{"to_i", stringToI, " 12abc", nil, NewInteger(12), nil},
+		{"to_i with radix", stringToI, "ff", []RubyObject{NewInteger(16)}, NewInteger(255), nil},
+		{"to_i with prefix", stringToI, "0x1f", []RubyObject{NewInteger(16)}, NewInteger(31), nil},
+		{"to_i detecting radix", stringToI, "-0b101", []RubyObject{NewInteger(0)}, NewInteger(-5), nil},
+		{"to_i invalid radix", stringToI, "1", []RubyObject{NewInteger(1)}, nil, NewArgumentError("invalid radix 1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(str(tt.context), tt.args...)
+
+			checkError(t, err, tt.err)
+			checkResult(t, result, tt.expected)
+		})
+	}
+}
+
+func TestStringRegexpMethods(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	strs := func(values ...string) *Array {
+		array := NewArray()
+		for _, v := range values {
+			array.Elements = append(array.Elements, str(v))
+		}
+		return array
+	}
+	regex := func(source string) *Regexp {
+		re, err := NewRegexp(source, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return re
+	}
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  string
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"split whitespace", stringSplit, " a  b\tc ", nil, strs("a", "b", "c")},
+		{"split whitespace with limit", stringSplit, " a  b c ", []RubyObject{str(" "), NewInteger(2)}, strs("a", "b c ")},
+		{"split string", stringSplit, "a,b,,", []RubyObject{str(",")}, strs("a", "b")},
+		{"split negative limit", stringSplit, "a,b,,", []RubyObject{str(","), NewInteger(-1)}, strs("a", "b", "", "")},
+		{"split limit", stringSplit, "a,b,c", []RubyObject{str(","), NewInteger(2)}, strs("a", "b,c")},
+		{"split regexp", stringSplit, "a1b22c", []RubyObject{regex(`\d+`)}, strs("a", "b", "c")},
+		{"split chars", stringSplit, "héj", []RubyObject{str("")}, strs("h", "é", "j")},
+		{"split regexp with groups", stringSplit, "a-b", []RubyObject{regex(`(-)`)}, strs("a", "-", "b")},
+		{"sub", stringSub, "aaa", []RubyObject{str("a"), str("b")}, str("baa")},
+		{"gsub", stringGsub, "aaa", []RubyObject{str("a"), str("b")}, str("bbb")},
+		{"gsub special chars", stringGsub, "a.a", []RubyObject{str("."), str("-")}, str("a-a")},
+		{"gsub backreferences", stringGsub, "ab", []RubyObject{regex(`(?<y>a)(?<x>b)`), str(`\2\1\0\k<x>\\`)}, str(`baabb\`)},
+		{"gsub pre and post match", stringGsub, "abc", []RubyObject{regex("b"), str("[\\`\\']")}, str("a[ac]c")},
+		{"scan", stringScan, "a1b22", []RubyObject{regex(`\d+`)}, strs("1", "22")},
+		{"scan groups", stringScan, "a1b2", []RubyObject{regex(`(\w)(\d)`)}, NewArray(strs("a", "1"), strs("b", "2"))},
+		{"match no match", func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+			return stringMatch(nil, context, args...)
+		}, "abc", []RubyObject{str("x")}, NIL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(str(tt.context), tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+
+	t.Run("gsub with block", func(t *testing.T) {
+		var yielded []RubyObject
+		block := testBlock(func(args ...RubyObject) (RubyObject, error) {
+			yielded = append(yielded, args[0])
+			return NewInteger(int64(len(yielded))), nil
+		}, "m")
+
+		result, err := stringGsub(str("a-b-c"), str("-"), block)
+
+		checkError(t, err, nil)
+		checkResult(t, result, str("a1b2c"))
+		checkResult(t, NewArray(yielded...), strs("-", "-"))
+	})
+
+	t.Run("gsub without replacement", func(t *testing.T) {
+		_, err := stringGsub(str("abc"), str("b"))
+
+		checkError(t, err, NewWrongNumberOfArgumentsError(2, 1))
+	})
+
+	t.Run("=~ with string", func(t *testing.T) {
+		_, err := stringMatchOperator(nil, str("abc"), str("b"))
+
+		checkError(t, err, NewTypeError("wrong argument type String (expected Regexp)"))
+	})
+}
+
+func TestStringDecomposition(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	tests := []struct {
+		name     string
+		method   func(context RubyObject, args ...RubyObject) (RubyObject, error)
+		context  string
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"chars", stringChars, "hé!", nil, NewArray(str("h"), str("é"), str("!"))},
+		{"chars empty", stringChars, "", nil, NewArray()},
+		{"bytes", stringBytes, "hé", nil, NewArray(NewInteger(104), NewInteger(195), NewInteger(169))},
+		{"lines", stringLines, "a\nb\n\nc", nil, NewArray(str("a\n"), str("b\n"), str("\n"), str("c"))},
+		{"lines with separator", stringLines, "a, b, c", []RubyObject{str(", ")}, NewArray(str("a, "), str("b, "), str("c"))},
+		{"each_char without block", stringEachChar, "ab", nil, NewArray(str("a"), str("b"))},
+		{"each_line without block", stringEachLine, "a\nb", nil, NewArray(str("a\n"), str("b"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.method(str(tt.context), tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, tt.expected)
+		})
+	}
+}
+
+func TestStringSlice(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	rng := func(first, last int64, exclusive bool) *Range {
+		return &Range{First: NewInteger(first), Last: NewInteger(last), Exclusive: exclusive}
+	}
+	digits, err := NewRegexp(`(?<first>\d)(?<second>\d)?`, "")
+	checkError(t, err, nil)
+
+	tests := []struct {
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{[]RubyObject{NewInteger(1)}, str("ö")},
+		{[]RubyObject{NewInteger(-1)}, str("7")},
+		{[]RubyObject{NewInteger(9)}, NIL},
+		{[]RubyObject{NewInteger(-10)}, NIL},
+		{[]RubyObject{NewInteger(1), NewInteger(2)}, str("öo")},
+		{[]RubyObject{NewInteger(7), NewInteger(5)}, str("37")},
+		{[]RubyObject{NewInteger(9), NewInteger(1)}, str("")},
+		{[]RubyObject{NewInteger(10), NewInteger(1)}, NIL},
+		{[]RubyObject{NewInteger(1), NewInteger(-1)}, NIL},
+		{[]RubyObject{rng(0, 2, false)}, str("föo")},
+		{[]RubyObject{rng(0, 2, true)}, str("fö")},
+		{[]RubyObject{rng(1, -1, false)}, str("öo bar37")},
+		{[]RubyObject{rng(-2, -1, false)}, str("37")},
+		{[]RubyObject{rng(3, 1, false)}, str("")},
+		{[]RubyObject{rng(9, 10, false)}, str("")},
+		{[]RubyObject{rng(10, 10, false)}, NIL},
+		{[]RubyObject{digits}, str("37")},
+		{[]RubyObject{digits, NewInteger(2)}, str("7")},
+		{[]RubyObject{digits, str("first")}, str("3")},
+		{[]RubyObject{str("bar")}, str("bar")},
+		{[]RubyObject{str("baz")}, NIL},
+	}
+
+	for _, tt := range tests {
+		result, err := stringSlice(str("föo bar37"), tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	_, err = stringSlice(str("foo"), NewFloat(1))
+	checkError(t, err, NewImplicitConversionTypeError(NewInteger(0), NewFloat(1)))
+}
+
+func BenchmarkStringAppend(b *testing.B) {
+	for _, count := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("%d appends", count), func(b *testing.B) {
+			line := &String{Value: "a line of a report\n"}
+			for i := 0; i < b.N; i++ {
+				str := &String{}
+				for j := 0; j < count; j++ {
+					stringAppend(str, line)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package object
 
+import "strings"
+
 var symbolClass RubyClassObject = newClass("Symbol", objectClass, symbolMethods, symbolClassMethods)
 
 func init() {
@@ -11,6 +13,11 @@ type Symbol struct {
 	Value string
 }
 
+// NewSymbol returns the Symbol named name
+func NewSymbol(name string) *Symbol {
+	return &Symbol{Value: name}
+}
+
 // Inspect returns the value of the symbol
 func (s *Symbol) Inspect() string { return ":" + s.Value }
 
@@ -20,6 +27,23 @@ func (s *Symbol) Type() Type { return SYMBOL_OBJ }
 // Class returns symbolClass
 func (s *Symbol) Class() RubyClass { return symbolClass }
 
+func (s *Symbol) hashKey() hashKey {
+	return hashKey{Type: s.Type(), Value: s.Value}
+}
+
 var symbolClassMethods = map[string]RubyMethod{}
 
-var symbolMethods = map[string]RubyMethod{}
+var symbolMethods = map[string]RubyMethod{
+	"<=>": withArity(1, publicMethod(symbolSpaceship)),
+}
+
+// symbolSpaceship compares the names of two Symbols. It returns nil if the
+// argument is no Symbol.
+func symbolSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	symbol := context.(*Symbol)
+	other, ok := args[0].(*Symbol)
+	if !ok {
+		return NIL, nil
+	}
+	return NewInteger(int64(strings.Compare(symbol.Value, other.Value))), nil
+}
@@ -0,0 +1,21 @@
+package object
+
+import "testing"
+
+func TestSymbolSpaceship(t *testing.T) {
+	tests := []struct {
+		other    RubyObject
+		expected RubyObject
+	}{
+		{NewSymbol("a"), NewInteger(1)},
+		{NewSymbol("b"), NewInteger(0)},
+		{NewSymbol("c"), NewInteger(-1)},
+		{&String{Value: "b"}, NIL},
+	}
+
+	for _, tt := range tests {
+		result, err := symbolSpaceship(NewSymbol("b"), tt.other)
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+}
@@ -0,0 +1,473 @@
+package object
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var threadClass RubyClassObject = newClass("Thread", objectClass, threadMethods, threadClassMethods)
+
+func init() {
+	classes.Set("Thread", threadClass)
+}
+
+// A Thread represents a Ruby Thread. Every Thread runs its block on its own
+// goroutine.
+type Thread struct {
+	done      chan struct{}
+	result    RubyObject
+	err       error
+	locals    map[string]RubyObject
+	variables map[string]RubyObject
+	// joiners counts the Threads waiting without timeout for this one
+	joiners int
+	// fiber is the Fiber currently resumed within the Thread, if any
+	fiber *Fiber
+	// lastMatch is the result of the last match of a Regexp within the
+	// method running in the Thread, which `$~` refers to
+	lastMatch *MatchData
+	// lastLine is the line read last by gets within the method running in
+	// the Thread, which `$_` refers to
+	lastLine RubyObject
+	// tracing is set while the Thread reports an event to TracePoints
+	tracing bool
+	// threads are the Threads of the Runtime the Thread runs within
+	threads *threadState
+}
+
+// Type returns THREAD_OBJ
+func (t *Thread) Type() Type { return THREAD_OBJ }
+
+// Inspect returns the object id and status of the Thread
+func (t *Thread) Inspect() string {
+	return fmt.Sprintf("#<Thread:0x%016x %s>", ObjectID(t), t.status())
+}
+
+// Class returns threadClass
+func (t *Thread) Class() RubyClass { return threadClass }
+
+// threadState holds the Threads of a Runtime and the interpreter lock,
+// which makes sure that only one of them runs Ruby code at a time, as
+// neither the evaluator nor the objects are safe for concurrent use. The
+// running Thread holds the lock and only gives it up while it waits, e.g.
+// for another Thread to finish. Every Runtime has its own, so that the
+// Threads of different runtimes run in parallel.
+type threadState struct {
+	sync.Mutex
+	// main is the Thread running the program
+	main *Thread
+	// held is set once the first Thread is started. Until then the main
+	// thread runs alone and does not need to hold the lock.
+	held    bool
+	current *Thread
+	// threads counts the running Threads and waiting the ones blocked
+	// without timeout, to detect when no Thread is left to resume them
+	threads int
+	waiting int
+}
+
+// init creates the main Thread of s
+func (s *threadState) init() {
+	s.main = &Thread{done: make(chan struct{}), threads: s}
+}
+
+// sharedThreads holds the Threads started outside of any Runtime
+var sharedThreads threadState
+
+func init() {
+	sharedThreads.init()
+}
+
+// threadState returns the Threads of r
+func (r *Runtime) threadState() *threadState {
+	if r == nil {
+		return &sharedThreads
+	}
+	return &r.threads
+}
+
+// acquire blocks until thread holds the interpreter lock
+func (s *threadState) acquire(thread *Thread) {
+	s.Lock()
+	s.current = thread
+}
+
+// withoutInterpreter calls wait without holding the interpreter lock of r,
+// so that its other Threads run meanwhile
+func (r *Runtime) withoutInterpreter(wait func()) {
+	r.threadState().without(wait)
+}
+
+// without calls wait without holding the interpreter lock
+func (s *threadState) without(wait func()) {
+	if !s.held {
+		wait()
+		return
+	}
+	thread := s.current
+	s.Unlock()
+	defer s.acquire(thread)
+	wait()
+}
+
+// currentThread returns the Thread running Ruby code within r
+func (r *Runtime) currentThread() *Thread {
+	return r.threadState().currentThread()
+}
+
+func (s *threadState) currentThread() *Thread {
+	if s.current == nil {
+		return s.main
+	}
+	return s.current
+}
+
+// startThread returns a new Thread calling block with args. It starts
+// running as soon as the current Thread of r waits.
+func (r *Runtime) startThread(block *Proc, args []RubyObject) *Thread {
+	state := r.threadState()
+	if !state.held {
+		state.acquire(state.main)
+		state.held = true
+		state.threads = 1
+	}
+	state.threads++
+	thread := &Thread{done: make(chan struct{}), threads: state}
+	go func() {
+		state.acquire(thread)
+		defer state.Unlock()
+		thread.result, thread.err = block.Call(args...)
+		state.threads--
+		state.waiting -= thread.joiners
+		close(thread.done)
+	}()
+	return thread
+}
+
+func (t *Thread) alive() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (t *Thread) status() string {
+	switch {
+	case t == t.threads.currentThread():
+		return "run"
+	case t.alive():
+		return "sleep"
+	default:
+		return "dead"
+	}
+}
+
+// join waits for t to finish for at most timeout, if positive. It reports
+// whether t finished. It is called by the current Thread of r.
+func (t *Thread) join(r *Runtime, timeout time.Duration) (bool, error) {
+	state := r.threadState()
+	if t == state.currentThread() {
+		return false, NewThreadError("Target thread must not be current thread")
+	}
+	blocked := timeout < 0 && t.alive()
+	if blocked {
+		if err := state.block(); err != nil {
+			return false, err
+		}
+		t.joiners++
+	}
+	finished, err := r.wait(t.done, timeout)
+	if err != nil {
+		// a Thread finishing meanwhile released its joiners already
+		if blocked && t.alive() {
+			t.joiners--
+			state.waiting--
+		}
+		return false, err
+	}
+	if finished && t.err != nil {
+		return true, t.err
+	}
+	return finished, nil
+}
+
+var threadClassMethods = map[string]RubyMethod{
+	"new":     publicRuntimeMethod(threadNew),
+	"start":   publicRuntimeMethod(threadNew),
+	"current": withArity(0, publicRuntimeMethod(threadCurrent)),
+	"main":    withArity(0, publicRuntimeMethod(threadMain)),
+	"pass":    withArity(0, publicRuntimeMethod(threadPass)),
+}
+
+var threadMethods = map[string]RubyMethod{
+	"join":                withArityRange(0, 1, publicRuntimeMethod(threadJoin)),
+	"value":               withArity(0, publicRuntimeMethod(threadValue)),
+	"alive?":              withArity(0, publicMethod(threadAlive)),
+	"status":              withArity(0, publicMethod(threadStatus)),
+	"[]":                  withArity(1, publicMethod(threadLocalGet)),
+	"[]=":                 withArity(2, publicMethod(threadLocalSet)),
+	"key?":                withArity(1, publicMethod(threadHasLocal)),
+	"thread_variable_get": withArity(1, publicMethod(threadVariableGet)),
+	"thread_variable_set": withArity(2, publicMethod(threadVariableSet)),
+}
+
+func threadNew(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewThreadError("must be called with a block")
+	}
+	return r.startThread(block, args), nil
+}
+
+func threadCurrent(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.currentThread(), nil
+}
+
+func threadMain(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return r.threadState().main, nil
+}
+
+// threadPass gives the other Threads the chance to run
+func threadPass(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	r.withoutInterpreter(runtime.Gosched)
+	return NIL, nil
+}
+
+// threadJoin waits for the Thread to finish, raising the exception it
+// terminated with. With a limit in seconds it returns nil if the Thread is
+// still running afterwards.
+func threadJoin(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	thread := context.(*Thread)
+	timeout := time.Duration(-1)
+	if len(args) == 1 {
+		var err error
+		if timeout, err = timeoutArgument(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	finished, err := thread.join(r, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return NIL, nil
+	}
+	return thread, nil
+}
+
+// timeoutArgument returns the timeout given in seconds. nil means no
+// timeout and is returned as negative duration.
+func timeoutArgument(arg RubyObject) (time.Duration, error) {
+	if arg == NIL {
+		return -1, nil
+	}
+	seconds, ok := toFloat(arg)
+	if !ok {
+		return 0, NewTypeError("can't convert %s into time interval", comparisonOperandName(arg))
+	}
+	if seconds < 0 {
+		return 0, nil
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// threadValue waits for the Thread to finish and returns the result of its
+// block
+func threadValue(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	thread := context.(*Thread)
+	if _, err := thread.join(r, -1); err != nil {
+		return nil, err
+	}
+	return thread.result, nil
+}
+
+func threadAlive(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Thread).alive()), nil
+}
+
+// threadStatus returns "run" for the current Thread and "sleep" for other
+// running Threads. Finished Threads return false, or nil if they terminated
+// with an exception.
+func threadStatus(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	thread := context.(*Thread)
+	switch status := thread.status(); {
+	case status != "dead":
+		return &String{Value: status}, nil
+	case thread.err != nil:
+		return NIL, nil
+	default:
+		return FALSE, nil
+	}
+}
+
+func threadLocalGet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return threadLookup(context.(*Thread).locals, args[0])
+}
+
+func threadLocalSet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	thread := context.(*Thread)
+	if thread.locals == nil {
+		thread.locals = make(map[string]RubyObject)
+	}
+	return threadStore(thread.locals, args[0], args[1])
+}
+
+func threadHasLocal(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	name, err := nameArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	_, ok := context.(*Thread).locals[name]
+	return nativeBoolToBoolean(ok), nil
+}
+
+func threadVariableGet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return threadLookup(context.(*Thread).variables, args[0])
+}
+
+func threadVariableSet(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	thread := context.(*Thread)
+	if thread.variables == nil {
+		thread.variables = make(map[string]RubyObject)
+	}
+	return threadStore(thread.variables, args[0], args[1])
+}
+
+// threadLookup returns the value stored in vars under the name given as
+// Symbol or String, or nil
+func threadLookup(vars map[string]RubyObject, key RubyObject) (RubyObject, error) {
+	name, err := nameArgument(key)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := vars[name]; ok {
+		return value, nil
+	}
+	return NIL, nil
+}
+
+// threadStore stores value in vars under the name given as Symbol or String
+func threadStore(vars map[string]RubyObject, key, value RubyObject) (RubyObject, error) {
+	name, err := nameArgument(key)
+	if err != nil {
+		return nil, err
+	}
+	vars[name] = value
+	return value, nil
+}
+
+// wait waits without holding the interpreter lock of r until done receives
+// or is closed, or timeout passed if it is not negative. It reports whether
+// done did. The handlers of the signals trapped meanwhile are run without
+// ending the wait, but if the program is interrupted, or a handler raises
+// an error, wait returns it instead.
+func (r *Runtime) wait(done <-chan struct{}, timeout time.Duration) (bool, error) {
+	var expired <-chan time.Time
+	if timeout >= 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		interrupted, ready, timedOut := r.interrupted(), false, false
+		r.threadState().without(func() {
+			select {
+			case <-done:
+				ready = true
+			case <-expired:
+				timedOut = true
+			case <-interrupted:
+			case <-trapped():
+			}
+		})
+		if ready || timedOut {
+			return ready, nil
+		}
+		if err := r.CheckInterrupt(); err != nil {
+			return false, err
+		}
+	}
+}
+
+// block records that the current Thread is about to wait without timeout.
+// It returns a ThreadError if all other Threads are waiting as well, as
+// none of them could resume it.
+func (s *threadState) block() error {
+	if s.waiting+1 >= s.threads {
+		return NewThreadError("No live threads left. Deadlock?")
+	}
+	s.waiting++
+	return nil
+}
+
+// A waitQueue holds the Threads waiting for a condition to become true.
+// Like all Ruby objects it must only be used while holding the interpreter
+// lock.
+type waitQueue struct {
+	waiters []*waiter
+}
+
+// A waiter is a Thread waiting within a waitQueue
+type waiter struct {
+	resumed chan struct{}
+	// blocked is set if the Thread waits without timeout
+	blocked bool
+	// threads are the Threads of the Runtime the waiting one belongs to
+	threads *threadState
+}
+
+// resume resumes the waiting Thread
+func (w *waiter) resume() {
+	if w.blocked {
+		w.threads.waiting--
+	}
+	w.resumed <- struct{}{}
+}
+
+// wait blocks until the waiting Thread is resumed by signal or broadcast,
+// or timeout passed if it is not negative. It reports whether the Thread
+// was resumed, and returns a ThreadError if there is no other Thread left
+// which could resume it, or the interruption of the program meanwhile. The
+// waiting Thread is the current one of r.
+func (w *waitQueue) wait(r *Runtime, timeout time.Duration) (bool, error) {
+	state := r.threadState()
+	current := &waiter{resumed: make(chan struct{}, 1), blocked: timeout < 0, threads: state}
+	if current.blocked {
+		if err := state.block(); err != nil {
+			return false, err
+		}
+	}
+	w.waiters = append(w.waiters, current)
+	_, err := r.wait(current.resumed, timeout)
+	for i, waiter := range w.waiters {
+		if waiter == current {
+			w.waiters = append(w.waiters[:i:i], w.waiters[i+1:]...)
+			if current.blocked {
+				state.waiting--
+			}
+			return false, err
+		}
+	}
+	return true, err
+}
+
+// signal resumes the Thread waiting the longest, if any
+func (w *waitQueue) signal() {
+	if len(w.waiters) == 0 {
+		return
+	}
+	w.waiters[0].resume()
+	w.waiters = w.waiters[1:]
+}
+
+// broadcast resumes all waiting Threads
+func (w *waitQueue) broadcast() {
+	for _, waiter := range w.waiters {
+		waiter.resume()
+	}
+	w.waiters = nil
+}
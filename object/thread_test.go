@@ -0,0 +1,109 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestThread(t *testing.T) {
+	t.Run("value", func(t *testing.T) {
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return NewArray(args...), nil
+		})
+
+		thread, err := Send(threadClass, "new", NewInteger(1), NewInteger(2), block)
+		checkError(t, err, nil)
+
+		result, err := Send(thread, "value")
+		checkError(t, err, nil)
+		checkResult(t, result, NewArray(NewInteger(1), NewInteger(2)))
+
+		alive, err := Send(thread, "alive?")
+		checkError(t, err, nil)
+		checkResult(t, alive, FALSE)
+	})
+	t.Run("join raises the exception of the thread", func(t *testing.T) {
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			return nil, NewRuntimeError("boom")
+		})
+
+		thread, err := Send(threadClass, "new", block)
+		checkError(t, err, nil)
+
+		_, err = Send(thread, "join")
+		checkError(t, err, NewRuntimeError("boom"))
+
+		_, err = Send(thread, "value")
+		checkError(t, err, NewRuntimeError("boom"))
+
+		status, err := Send(thread, "status")
+		checkError(t, err, nil)
+		checkResult(t, status, NIL)
+	})
+	t.Run("threads take turns", func(t *testing.T) {
+		var order []RubyObject
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			order = append(order, NewSymbol("thread"))
+			return NIL, nil
+		})
+
+		thread, err := Send(threadClass, "new", block)
+		checkError(t, err, nil)
+		order = append(order, NewSymbol("main"))
+
+		joined, err := Send(thread, "join")
+		checkError(t, err, nil)
+
+		checkResult(t, joined, thread)
+		checkResult(t, NewArray(order...), NewArray(NewSymbol("main"), NewSymbol("thread")))
+	})
+	t.Run("current", func(t *testing.T) {
+		var current RubyObject
+		block := newNativeProc(func(args ...RubyObject) (RubyObject, error) {
+			var err error
+			current, err = Send(threadClass, "current")
+			return current, err
+		})
+
+		thread, err := Send(threadClass, "new", block)
+		checkError(t, err, nil)
+		_, err = Send(thread, "join")
+		checkError(t, err, nil)
+
+		if current != thread {
+			t.Logf("Expected Thread.current to return the running thread, got %v", current)
+			t.Fail()
+		}
+
+		main, err := Send(threadClass, "current")
+		checkError(t, err, nil)
+		checkResult(t, main, sharedThreads.main)
+
+		_, err = Send(main, "join")
+		checkError(t, err, NewThreadError("Target thread must not be current thread"))
+	})
+	t.Run("thread locals", func(t *testing.T) {
+		thread := &Thread{done: make(chan struct{})}
+
+		_, err := Send(thread, "[]=", NewSymbol("foo"), NewInteger(3))
+		checkError(t, err, nil)
+
+		value, err := Send(thread, "[]", &String{Value: "foo"})
+		checkError(t, err, nil)
+		checkResult(t, value, NewInteger(3))
+
+		value, err = Send(thread, "thread_variable_get", NewSymbol("foo"))
+		checkError(t, err, nil)
+		checkResult(t, value, NIL)
+
+		_, err = Send(thread, "thread_variable_set", NewSymbol("bar"), TRUE)
+		checkError(t, err, nil)
+
+		value, err = Send(thread, "thread_variable_get", NewSymbol("bar"))
+		checkError(t, err, nil)
+		checkResult(t, value, TRUE)
+	})
+	t.Run("without block", func(t *testing.T) {
+		_, err := Send(threadClass, "new")
+		checkError(t, err, NewThreadError("must be called with a block"))
+	})
+}
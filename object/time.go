@@ -0,0 +1,209 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeClass RubyClassObject = mixin(newClass("Time", objectClass, timeMethods, timeClassMethods), comparableModule)
+
+func init() {
+	classes.Set("Time", timeClass)
+}
+
+// NewTime returns a Time representing t
+func NewTime(t time.Time) *Time {
+	return &Time{Value: t}
+}
+
+// Time represents a point in time
+type Time struct {
+	Value time.Time
+}
+
+// Inspect returns the time formatted like `2006-01-02 15:04:05 -0700`
+func (t *Time) Inspect() string { return t.String() }
+
+// String returns the time formatted like `2006-01-02 15:04:05 -0700`, or
+// with `UTC` as zone for times in UTC
+func (t *Time) String() string {
+	if t.Value.Location() == time.UTC {
+		return t.Value.Format("2006-01-02 15:04:05 UTC")
+	}
+	return t.Value.Format("2006-01-02 15:04:05 -0700")
+}
+
+// Type returns TIME_OBJ
+func (t *Time) Type() Type { return TIME_OBJ }
+
+// Class returns timeClass
+func (t *Time) Class() RubyClass { return timeClass }
+
+func (t *Time) hashKey() hashKey {
+	return hashKey{Type: t.Type(), Value: t.Value.UnixNano()}
+}
+
+var timeClassMethods = map[string]RubyMethod{
+	"now": withArity(0, publicMethod(timeNow)),
+	"at":  withArity(1, publicMethod(timeAt)),
+}
+
+var timeMethods = map[string]RubyMethod{
+	"to_i":     withArity(0, publicMethod(timeToI)),
+	"to_f":     withArity(0, publicMethod(timeToF)),
+	"year":     withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Year() }))),
+	"month":    withArity(0, publicMethod(timeComponent(func(t time.Time) int { return int(t.Month()) }))),
+	"day":      withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Day() }))),
+	"hour":     withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Hour() }))),
+	"min":      withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Minute() }))),
+	"sec":      withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Second() }))),
+	"nsec":     withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.Nanosecond() }))),
+	"wday":     withArity(0, publicMethod(timeComponent(func(t time.Time) int { return int(t.Weekday()) }))),
+	"yday":     withArity(0, publicMethod(timeComponent(func(t time.Time) int { return t.YearDay() }))),
+	"utc":      withArity(0, publicMethod(timeUTC)),
+	"utc?":     withArity(0, publicMethod(timeIsUTC)),
+	"+":        withArity(1, publicMethod(timeAdd)),
+	"-":        withArity(1, publicMethod(timeSub)),
+	"<=>":      withArity(1, publicMethod(timeSpaceship)),
+	"strftime": withArity(1, publicMethod(timeStrftime)),
+	"to_s":     withArity(0, publicMethod(timeToS)),
+	"inspect":  withArity(0, publicMethod(timeToS)),
+}
+
+func timeNow(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewTime(time.Now()), nil
+}
+
+// timeAt returns the Time the given number of seconds after the epoch
+func timeAt(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	if t, ok := args[0].(*Time); ok {
+		return NewTime(t.Value), nil
+	}
+	seconds, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewTypeError("can't convert %s into an exact number", comparisonOperandName(args[0]))
+	}
+	return NewTime(time.Unix(0, 0).Add(secondsDuration(seconds))), nil
+}
+
+// secondsDuration converts the number of seconds into a Duration
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
+
+func timeToI(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewInteger(context.(*Time).Value.Unix()), nil
+}
+
+func timeToF(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewFloat(float64(context.(*Time).Value.UnixNano()) / float64(time.Second)), nil
+}
+
+// timeComponent returns a method returning the component of the time
+// extracted by fn
+func timeComponent(fn func(time.Time) int) func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return func(context RubyObject, args ...RubyObject) (RubyObject, error) {
+		return NewInteger(int64(fn(context.(*Time).Value))), nil
+	}
+}
+
+func timeUTC(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewTime(context.(*Time).Value.UTC()), nil
+}
+
+func timeIsUTC(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*Time).Value.Location() == time.UTC), nil
+}
+
+// timeAdd returns the time the given number of seconds later
+func timeAdd(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	t := context.(*Time)
+	seconds, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewTypeError("can't convert %s into an exact number", comparisonOperandName(args[0]))
+	}
+	return NewTime(t.Value.Add(secondsDuration(seconds))), nil
+}
+
+// timeSub returns the seconds between two times as Float, or the time the
+// given number of seconds earlier
+func timeSub(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	t := context.(*Time)
+	if other, ok := args[0].(*Time); ok {
+		return NewFloat(t.Value.Sub(other.Value).Seconds()), nil
+	}
+	seconds, ok := toFloat(args[0])
+	if !ok {
+		return nil, NewTypeError("can't convert %s into an exact number", comparisonOperandName(args[0]))
+	}
+	return NewTime(t.Value.Add(-secondsDuration(seconds))), nil
+}
+
+func timeSpaceship(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	t := context.(*Time)
+	other, ok := args[0].(*Time)
+	if !ok {
+		return NIL, nil
+	}
+	switch {
+	case t.Value.Before(other.Value):
+		return NewInteger(-1), nil
+	case t.Value.After(other.Value):
+		return NewInteger(1), nil
+	default:
+		return NewInteger(0), nil
+	}
+}
+
+// strftimeLayouts maps the supported directives of strftime to the layouts
+// of time.Format
+var strftimeLayouts = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'H': "15",
+	'I': "03", 'M': "04", 'S': "05", 'p': "PM", 'Z': "MST",
+	'z': "-0700", 'A': "Monday", 'a': "Mon", 'B': "January", 'b': "Jan",
+}
+
+// timeStrftime formats the time by the directives of the format, like `%Y`
+func timeStrftime(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	t := context.(*Time).Value
+	format, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	var out strings.Builder
+	for i := 0; i < len(format.Value); i++ {
+		c := format.Value[i]
+		if c != '%' || i == len(format.Value)-1 {
+			out.WriteByte(c)
+			continue
+		}
+		i++
+		directive := format.Value[i]
+		switch directive {
+		case '%':
+			out.WriteByte('%')
+		case 'j':
+			out.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case 's':
+			out.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'L':
+			out.WriteString(t.Format(".000")[1:])
+		default:
+			layout, ok := strftimeLayouts[directive]
+			if !ok {
+				out.WriteByte('%')
+				out.WriteByte(directive)
+				continue
+			}
+			out.WriteString(t.Format(layout))
+		}
+	}
+	return &String{Value: out.String()}, nil
+}
+
+func timeToS(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*Time).String()}, nil
+}
@@ -0,0 +1,42 @@
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	base := NewTime(time.Date(2017, time.March, 4, 5, 6, 7, 8000000, time.UTC))
+
+	tests := []struct {
+		method   string
+		args     []RubyObject
+		expected RubyObject
+	}{
+		{"year", nil, NewInteger(2017)},
+		{"month", nil, NewInteger(3)},
+		{"yday", nil, NewInteger(63)},
+		{"to_i", nil, NewInteger(1488603967)},
+		{"to_s", nil, &String{Value: "2017-03-04 05:06:07 UTC"}},
+		{"+", []RubyObject{NewInteger(60)}, NewTime(base.Value.Add(time.Minute))},
+		{"-", []RubyObject{NewFloat(0.5)}, NewTime(base.Value.Add(-500 * time.Millisecond))},
+		{"-", []RubyObject{NewTime(base.Value.Add(-2 * time.Second))}, NewFloat(2)},
+		{"<=>", []RubyObject{NewTime(base.Value.Add(time.Second))}, NewInteger(-1)},
+		{"==", []RubyObject{NewTime(base.Value)}, TRUE},
+		{"strftime", []RubyObject{&String{Value: "%Y-%m-%d %H:%M:%S.%L %j %%"}}, &String{Value: "2017-03-04 05:06:07.008 063 %"}},
+	}
+
+	for _, tt := range tests {
+		result, err := Send(base, tt.method, tt.args...)
+
+		checkError(t, err, nil)
+		checkResult(t, result, tt.expected)
+	}
+
+	at, err := Send(timeClass, "at", NewInteger(1488603967))
+	checkError(t, err, nil)
+	if at.(*Time).Value.Unix() != 1488603967 {
+		t.Logf("Expected Time.at to return the time of the given seconds, got %s", at.Inspect())
+		t.Fail()
+	}
+}
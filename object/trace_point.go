@@ -0,0 +1,379 @@
+package object
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var tracePointClass RubyClassObject = newClass("TracePoint", objectClass, tracePointMethods, tracePointClassMethods)
+
+func init() {
+	classes.Set("TracePoint", tracePointClass)
+	kernelMethodSet["set_trace_func"] = withArityRange(0, 1, privateMethod(kernelSetTraceFunc))
+}
+
+// traceEvents are the events a TracePoint can be created for
+var traceEvents = []string{"call", "return", "line", "raise"}
+
+// A TracePoint represents a Ruby TracePoint, which calls its block for
+// every event it has been created for while it is enabled
+type TracePoint struct {
+	events map[string]bool
+	block  *Proc
+	// hook is called in place of block for TracePoints created by Go code
+	hook    func(event *TraceEvent) error
+	enabled bool
+	// event is the event reported to the block, set only while it runs
+	event *TraceEvent
+}
+
+// A TraceEvent describes an event of the evaluator reported to the enabled
+// TracePoints by Trace
+type TraceEvent struct {
+	// Event is one of call, return, line and raise
+	Event string
+	// MethodID is the name of the method the event occurred in, empty
+	// outside of methods
+	MethodID string
+	Path     string
+	Lineno   int
+	Self     RubyObject
+	// Binding captures the environment the event occurred in
+	Binding *Binding
+	// ReturnValue is the value returned by the method, for return events
+	ReturnValue RubyObject
+	// RaisedException is the exception raised, for raise events
+	RaisedException RubyObject
+}
+
+// Type returns TRACE_POINT_OBJ
+func (t *TracePoint) Type() Type { return TRACE_POINT_OBJ }
+
+// Inspect returns whether the TracePoint is enabled, or the event reported
+// to its block while it runs
+func (t *TracePoint) Inspect() string {
+	if t.event != nil {
+		return fmt.Sprintf("#<TracePoint:%s %s:%d>", t.event.Event, t.event.Path, t.event.Lineno)
+	}
+	if t.enabled {
+		return "#<TracePoint:enabled>"
+	}
+	return "#<TracePoint:disabled>"
+}
+
+// Class returns tracePointClass
+func (t *TracePoint) Class() RubyClass { return tracePointClass }
+
+// tracePoints holds the enabled TracePoints. enabled counts them, so that
+// Tracing does not need to lock.
+var tracePoints struct {
+	sync.Mutex
+	points  []*TracePoint
+	enabled int32
+}
+
+// Tracing reports whether any TracePoint is enabled, so that the evaluator
+// only describes events if they are reported to anybody.
+func Tracing() bool {
+	return atomic.LoadInt32(&tracePoints.enabled) != 0
+}
+
+// Trace calls the blocks of the TracePoints enabled for the event, in the
+// order they were enabled, and returns the first error raised by them.
+// Events occurring within r while a block runs are not reported.
+func (r *Runtime) Trace(event TraceEvent) error {
+	thread := r.currentThread()
+	if thread.tracing {
+		return nil
+	}
+	tracePoints.Lock()
+	points := append([]*TracePoint(nil), tracePoints.points...)
+	tracePoints.Unlock()
+	thread.tracing = true
+	defer func() { thread.tracing = false }()
+	for _, point := range points {
+		if !point.enabled || !point.events[event.Event] {
+			continue
+		}
+		err := point.report(&event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// report passes event to the hook or the block of the TracePoint
+func (t *TracePoint) report(event *TraceEvent) error {
+	if t.hook != nil {
+		return t.hook(event)
+	}
+	t.event = event
+	defer func() { t.event = nil }()
+	_, err := t.block.Call(t)
+	return err
+}
+
+// NewTraceHook returns a disabled TracePoint which calls hook for every
+// event, so that Go code can trace the evaluator like a TracePoint does
+func NewTraceHook(hook func(event *TraceEvent) error) *TracePoint {
+	events := make(map[string]bool)
+	for _, event := range traceEvents {
+		events[event] = true
+	}
+	return &TracePoint{events: events, hook: hook}
+}
+
+// SetEnabled enables or disables the TracePoint and returns whether it was
+// enabled before
+func (t *TracePoint) SetEnabled(enabled bool) bool {
+	tracePoints.Lock()
+	defer tracePoints.Unlock()
+	previous := t.enabled
+	if previous == enabled {
+		return previous
+	}
+	t.enabled = enabled
+	if enabled {
+		tracePoints.points = append(tracePoints.points, t)
+		atomic.AddInt32(&tracePoints.enabled, 1)
+		return previous
+	}
+	for i, point := range tracePoints.points {
+		if point == t {
+			tracePoints.points = append(tracePoints.points[:i], tracePoints.points[i+1:]...)
+			break
+		}
+	}
+	atomic.AddInt32(&tracePoints.enabled, -1)
+	return previous
+}
+
+var tracePointClassMethods = map[string]RubyMethod{
+	"new":   publicMethod(tracePointNew),
+	"trace": publicMethod(tracePointTrace),
+}
+
+var tracePointMethods = map[string]RubyMethod{
+	"enable":           withArity(0, publicMethod(tracePointEnable)),
+	"disable":          withArity(0, publicMethod(tracePointDisable)),
+	"enabled?":         withArity(0, publicMethod(tracePointIsEnabled)),
+	"event":            withArity(0, publicMethod(tracePointEvent)),
+	"method_id":        withArity(0, publicMethod(tracePointMethodID)),
+	"path":             withArity(0, publicMethod(tracePointPath)),
+	"lineno":           withArity(0, publicMethod(tracePointLineno)),
+	"self":             withArity(0, publicMethod(tracePointSelf)),
+	"return_value":     withArity(0, publicMethod(tracePointReturnValue)),
+	"raised_exception": withArity(0, publicMethod(tracePointRaisedException)),
+	"binding":          withArity(0, publicMethod(tracePointBinding)),
+}
+
+// tracePointNew returns a disabled TracePoint for the events given as
+// Symbols, or for all events if none are given
+func tracePointNew(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, block := extractBlock(args)
+	if block == nil {
+		return nil, NewArgumentError("must be called with a block")
+	}
+	events := make(map[string]bool)
+	for _, arg := range args {
+		symbol, ok := arg.(*Symbol)
+		if !ok {
+			return nil, NewImplicitConversionTypeError(&Symbol{}, arg)
+		}
+		if !isTraceEvent(symbol.Value) {
+			return nil, NewArgumentError("unknown event: %s", symbol.Value)
+		}
+		events[symbol.Value] = true
+	}
+	if len(events) == 0 {
+		for _, event := range traceEvents {
+			events[event] = true
+		}
+	}
+	return &TracePoint{events: events, block: block}, nil
+}
+
+func isTraceEvent(name string) bool {
+	for _, event := range traceEvents {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tracePointTrace returns a new TracePoint like new, but enabled
+func tracePointTrace(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	point, err := tracePointNew(context, args...)
+	if err != nil {
+		return nil, err
+	}
+	point.(*TracePoint).SetEnabled(true)
+	return point, nil
+}
+
+// tracePointEnable enables the TracePoint and returns whether it was
+// enabled before. Given a block it is enabled only while the block runs,
+// and the result of the block is returned.
+func tracePointEnable(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return tracePointSwitch(context.(*TracePoint), true, args)
+}
+
+// tracePointDisable disables the TracePoint like enable enables it
+func tracePointDisable(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return tracePointSwitch(context.(*TracePoint), false, args)
+}
+
+func tracePointSwitch(point *TracePoint, enabled bool, args []RubyObject) (RubyObject, error) {
+	_, block := extractBlock(args)
+	previous := point.SetEnabled(enabled)
+	if block == nil {
+		return nativeBoolToBoolean(previous), nil
+	}
+	defer point.SetEnabled(previous)
+	return block.Call()
+}
+
+func tracePointIsEnabled(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return nativeBoolToBoolean(context.(*TracePoint).enabled), nil
+}
+
+// currentTraceEvent returns the event reported to the block of the
+// TracePoint, or a RuntimeError if called outside of the block
+func currentTraceEvent(context RubyObject) (*TraceEvent, error) {
+	event := context.(*TracePoint).event
+	if event == nil {
+		return nil, NewRuntimeError("access from outside")
+	}
+	return event, nil
+}
+
+func tracePointEvent(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	return NewSymbol(event.Event), nil
+}
+
+func tracePointMethodID(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	if event.MethodID == "" {
+		return NIL, nil
+	}
+	return NewSymbol(event.MethodID), nil
+}
+
+func tracePointPath(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	return &String{Value: event.Path}, nil
+}
+
+func tracePointLineno(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	return NewInteger(int64(event.Lineno)), nil
+}
+
+func tracePointSelf(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	if event.Self == nil {
+		return NIL, nil
+	}
+	return event.Self, nil
+}
+
+func tracePointReturnValue(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	if event.Event != "return" {
+		return nil, NewRuntimeError("not supported by this event")
+	}
+	return event.ReturnValue, nil
+}
+
+func tracePointRaisedException(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	if event.Event != "raise" {
+		return nil, NewRuntimeError("not supported by this event")
+	}
+	return event.RaisedException, nil
+}
+
+func tracePointBinding(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	event, err := currentTraceEvent(context)
+	if err != nil {
+		return nil, err
+	}
+	if event.Binding == nil {
+		return NIL, nil
+	}
+	return event.Binding, nil
+}
+
+// traceFunc is the TracePoint installed by set_trace_func, if any
+var traceFunc struct {
+	sync.Mutex
+	point *TracePoint
+}
+
+// kernelSetTraceFunc calls the Proc given for every event, with the name
+// of the event, the file and line, the name of the method as Symbol, the
+// Binding and the class of self within methods. The Proc replaces the one
+// given before, nil removes it. It returns its argument.
+//
+// As a Proc passed as last argument is passed as block, the Proc is taken
+// from the block if there is no other argument.
+func kernelSetTraceFunc(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, proc := extractBlock(args)
+	switch {
+	case len(args) == 0 && proc == nil:
+		return nil, NewWrongNumberOfArgumentsError(1, 0)
+	case len(args) == 1 && args[0] != NIL:
+		return nil, NewTypeError("trace_func needs to be Proc")
+	}
+	traceFunc.Lock()
+	defer traceFunc.Unlock()
+	if traceFunc.point != nil {
+		traceFunc.point.SetEnabled(false)
+		traceFunc.point = nil
+	}
+	if proc == nil {
+		return NIL, nil
+	}
+	traceFunc.point = NewTraceHook(func(event *TraceEvent) error {
+		var id, classname RubyObject = NIL, NIL
+		if event.MethodID != "" {
+			id = NewSymbol(event.MethodID)
+			if event.Self != nil {
+				classname = realClass(event.Self).(RubyClassObject)
+			}
+		}
+		var binding RubyObject = NIL
+		if event.Binding != nil {
+			binding = event.Binding
+		}
+		_, err := proc.Call(&String{Value: event.Event}, &String{Value: event.Path}, NewInteger(int64(event.Lineno)), id, binding, classname)
+		return err
+	})
+	traceFunc.point.SetEnabled(true)
+	return proc, nil
+}
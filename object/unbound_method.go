@@ -0,0 +1,80 @@
+package object
+
+import "fmt"
+
+var unboundMethodClass RubyClassObject = newClass("UnboundMethod", objectClass, unboundMethodMethods, nil)
+
+func init() {
+	classes.Set("UnboundMethod", unboundMethodClass)
+}
+
+// An UnboundMethod represents a method retrieved from a class or module,
+// which is not associated to any receiver
+type UnboundMethod struct {
+	Name string
+	// Owner is the class or module defining the method
+	Owner RubyObject
+	// Receiver is the class or module the method was retrieved from
+	Receiver RubyObject
+	Method   RubyMethod
+}
+
+// Type returns UNBOUND_METHOD_OBJ
+func (u *UnboundMethod) Type() Type { return UNBOUND_METHOD_OBJ }
+
+// Inspect returns the name of the method qualified by its owner
+func (u *UnboundMethod) Inspect() string {
+	owner := u.Owner.Inspect()
+	if u.Receiver != nil && u.Receiver != u.Owner {
+		owner = fmt.Sprintf("%s(%s)", u.Receiver.Inspect(), owner)
+	}
+	return fmt.Sprintf("#<UnboundMethod: %s#%s>", owner, u.Name)
+}
+
+// Class returns unboundMethodClass
+func (u *UnboundMethod) Class() RubyClass { return unboundMethodClass }
+
+var unboundMethodMethods = map[string]RubyMethod{
+	"name":       withArity(0, publicMethod(unboundMethodName)),
+	"owner":      withArity(0, publicMethod(unboundMethodOwner)),
+	"arity":      withArity(0, publicMethod(unboundMethodArity)),
+	"parameters": withArity(0, publicMethod(unboundMethodParameters)),
+	"bind_call":  withArityRange(1, -1, publicRuntimeMethod(unboundMethodBindCall)),
+	"inspect":    withArity(0, publicMethod(unboundMethodInspect)),
+	"to_s":       withArity(0, publicMethod(unboundMethodInspect)),
+}
+
+func unboundMethodName(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return NewSymbol(context.(*UnboundMethod).Name), nil
+}
+
+func unboundMethodOwner(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return context.(*UnboundMethod).Owner, nil
+}
+
+func unboundMethodArity(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	params := parametersOf(context.(*UnboundMethod).Method)
+	return NewInteger(int64(arityOf(params))), nil
+}
+
+func unboundMethodParameters(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return parametersToArray(parametersOf(context.(*UnboundMethod).Method)), nil
+}
+
+// unboundMethodBindCall calls the method with the first argument as
+// receiver and passes on all other arguments
+func unboundMethodBindCall(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	method := context.(*UnboundMethod)
+	ok, err := isA(args[0], method.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewTypeError("bind argument must be an instance of %s", method.Owner.Inspect())
+	}
+	return r.Call(method.Method, args[0], args[1:]...)
+}
+
+func unboundMethodInspect(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return &String{Value: context.(*UnboundMethod).Inspect()}, nil
+}
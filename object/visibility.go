@@ -0,0 +1,120 @@
+package object
+
+// visibilityMethod implements `public`, `protected` and `private`. Unlike
+// other builtin methods it gets self passed as is, as called without
+// arguments it changes the visibility of all methods defined afterwards
+// within the current class body.
+type visibilityMethod struct {
+	visibility MethodVisibility
+}
+
+func (v *visibilityMethod) Call(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return v.callWithin(nil, context, args...)
+}
+
+func (v *visibilityMethod) callWithin(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	if len(args) == 0 {
+		if self, ok := context.(*definingSelf); ok {
+			self.visibility = v.visibility
+		}
+		return NIL, nil
+	}
+	module := unwrapSelf(context)
+	for _, arg := range args {
+		names, err := visibilityNames(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if err := r.setVisibility(module, name, v.visibility); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return NewArray(args...), nil
+}
+
+func (v *visibilityMethod) Visibility() MethodVisibility { return PRIVATE_METHOD }
+
+// visibilityNames returns the method names given as argument to a
+// visibility method, which are Symbols, Strings, Arrays of them or a method
+// just defined by `def`
+func visibilityNames(arg RubyObject) ([]string, error) {
+	switch arg := arg.(type) {
+	case *Function:
+		return []string{arg.Name}, nil
+	case *Array:
+		var names []string
+		for _, elem := range arg.Elements {
+			name, err := nameArgument(elem)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	default:
+		name, err := nameArgument(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []string{name}, nil
+	}
+}
+
+// setVisibility changes the visibility of the instance method name of
+// module within r. A method inherited from an ancestor is overridden within
+// module with the new visibility, leaving the ancestor untouched.
+func (r *Runtime) setVisibility(module RubyObject, name string, visibility MethodVisibility) error {
+	method, _, ok := r.lookupInstanceMethod(module, name)
+	if !ok {
+		return NewUndefinedMethodError(name, module)
+	}
+	definer, ok := module.(methodDefiner)
+	if !ok {
+		return NewTypeError("can't change visibility of methods of %s", module.Inspect())
+	}
+	r.defineMethod(definer, name, withVisibility(method, visibility))
+	return nil
+}
+
+// withVisibility returns method with the given visibility
+func withVisibility(fn RubyMethod, visibility MethodVisibility) RubyMethod {
+	if fn.Visibility() == visibility {
+		return fn
+	}
+	if function, ok := fn.(*Function); ok {
+		changed := *function
+		changed.MethodVisibility = visibility
+		return &changed
+	}
+	if m, ok := fn.(*method); ok {
+		changed := *m
+		changed.visibility = visibility
+		return &changed
+	}
+	if m, ok := fn.(runtimeMethod); ok {
+		return &method{visibility: visibility, within: m.callWithin, params: parametersOf(fn)}
+	}
+	return &method{visibility: visibility, fn: fn.Call, params: parametersOf(fn)}
+}
+
+// isProtectedCallAllowed reports whether caller may call the protected
+// method name of context within r, which is the case if caller is a kind of
+// the class or module defining it
+func (r *Runtime) isProtectedCallAllowed(caller, context RubyObject, name string) bool {
+	_, owner, ok := r.lookupInstanceMethod(context.Class().(RubyObject), name)
+	if !ok {
+		return false
+	}
+	for _, ancestor := range ancestorsOf(unwrapSelf(caller).Class().(RubyObject)) {
+		if ancestor == owner {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var warningModule = newModule("Warning", warningMethods)
+
+func init() {
+	classes.Set("Warning", warningModule)
+}
+
+// warningCategories holds whether warnings of the given categories are
+// emitted. Warnings of any other category are rejected.
+var warningCategories = struct {
+	sync.Mutex
+	enabled map[string]bool
+}{enabled: map[string]bool{
+	"deprecated":   false,
+	"experimental": true,
+	"performance":  false,
+}}
+
+// The methods of Warning are the ones of the module itself as well, so that
+// redefining `warn` within the module replaces the hook used by Kernel#warn
+var warningMethods = map[string]RubyMethod{
+	"warn": withArityRange(1, 2, publicMethod(warningWarn)),
+	"[]":   withArity(1, publicMethod(warningCategoryEnabled)),
+	"[]=":  withArity(2, publicMethod(warningSetCategoryEnabled)),
+}
+
+// defaultWarningWarn is the builtin Warning.warn, which writes to Stderr
+var defaultWarningWarn = warningMethods["warn"]
+
+// SetWarningLevel sets $VERBOSE like the -W flag of MRI. Level 0 disables
+// all warnings, level 1 enables the ones of Kernel#warn and level 2
+// additionally enables the ones emitted by the interpreter itself.
+func SetWarningLevel(level int) {
+	var verbose RubyObject
+	switch {
+	case level <= 0:
+		verbose = NIL
+	case level == 1:
+		verbose = FALSE
+	default:
+		verbose = TRUE
+	}
+	kernelFunctions.Set("$VERBOSE", verbose)
+}
+
+// IsVerbose reports whether $VERBOSE is true, which enables the warnings of
+// the interpreter itself, like for unused variables
+func IsVerbose() bool {
+	verbose, ok := kernelFunctions.Get("$VERBOSE")
+	return ok && isTruthy(verbose)
+}
+
+// warningsDisabled reports whether $VERBOSE is nil, which silences all
+// warnings
+func warningsDisabled() bool {
+	verbose, ok := kernelFunctions.Get("$VERBOSE")
+	return ok && verbose == NIL
+}
+
+// Warn emits message followed by a newline by Warning.warn outside of any
+// Runtime, unless warnings are disabled
+func Warn(message string) error {
+	return (*Runtime)(nil).WarnTo(DefaultStreams(), message)
+}
+
+// WarnTo works like Warn within r, but writes to the Stderr of streams
+// unless Warning.warn is redefined
+func (r *Runtime) WarnTo(streams *Streams, message string) error {
+	if warningsDisabled() {
+		return nil
+	}
+	_, err := r.emitWarning(streams, &String{Value: message + "\n"})
+	return err
+}
+
+// emitWarning passes args on to Warning.warn as seen within r, or writes
+// the message to the Stderr of streams right away if it is not redefined
+func (r *Runtime) emitWarning(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	if method, _ := r.findMethod(warningModule, "warn"); method == defaultWarningWarn {
+		return warnTo(streams, args...)
+	}
+	return r.Send(warningModule, "warn", args...)
+}
+
+// warningCategory returns the category given as `category:` option, which
+// is empty if it is nil. Other options raise an ArgumentError.
+func warningCategory(options *Hash) (string, error) {
+	var category string
+	for _, key := range options.Keys() {
+		value, _ := options.Get(key)
+		symbol, ok := key.(*Symbol)
+		if !ok || symbol.Value != "category" {
+			return "", NewArgumentError("unknown keyword: %s", key.Inspect())
+		}
+		if value == NIL {
+			continue
+		}
+		name, ok := value.(*Symbol)
+		if !ok {
+			return "", NewTypeError("%s is not a symbol", value.Inspect())
+		}
+		category = name.Value
+	}
+	if category == "" {
+		return "", nil
+	}
+	if _, err := isWarningCategoryEnabled(category); err != nil {
+		return "", err
+	}
+	return category, nil
+}
+
+func isWarningCategoryEnabled(category string) (bool, error) {
+	warningCategories.Lock()
+	defer warningCategories.Unlock()
+	enabled, ok := warningCategories.enabled[category]
+	if !ok {
+		return false, NewArgumentError("unknown category: %s", category)
+	}
+	return enabled, nil
+}
+
+// warningWarn writes the message to Stderr. It is the hook called by
+// Kernel#warn and can be redefined to handle warnings differently.
+func warningWarn(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return warnTo(DefaultStreams(), args...)
+}
+
+func warnTo(streams *Streams, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	message, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	if len(args) == 2 {
+		options, ok := args[1].(*Hash)
+		if !ok {
+			return nil, NewWrongNumberOfArgumentsError(1, 2)
+		}
+		if _, err := warningCategory(options); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprint(streams.Stderr, message.Value)
+	return NIL, nil
+}
+
+func warningCategoryEnabled(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	category, ok := args[0].(*Symbol)
+	if !ok {
+		return nil, NewTypeError("%s is not a symbol", args[0].Inspect())
+	}
+	enabled, err := isWarningCategoryEnabled(category.Value)
+	if err != nil {
+		return nil, err
+	}
+	return nativeBoolToBoolean(enabled), nil
+}
+
+func warningSetCategoryEnabled(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	category, ok := args[0].(*Symbol)
+	if !ok {
+		return nil, NewTypeError("%s is not a symbol", args[0].Inspect())
+	}
+	if _, err := isWarningCategoryEnabled(category.Value); err != nil {
+		return nil, err
+	}
+	warningCategories.Lock()
+	warningCategories.enabled[category.Value] = isTruthy(args[1])
+	warningCategories.Unlock()
+	return args[1], nil
+}
+
+// kernelWarn emits each message on its own line by Warning.warn unless
+// warnings are disabled. Arrays are flattened. Given a `category:` option
+// the warning is only emitted if its category is enabled.
+func kernelWarn(r *Runtime, context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return kernelWarnTo(r, DefaultStreams(), args...)
+}
+
+// kernelWarnTo works like Kernel#warn, but writes to the Stderr of streams
+// unless Warning.warn is redefined
+func kernelWarnTo(r *Runtime, streams *Streams, args ...RubyObject) (RubyObject, error) {
+	args, _ = extractBlock(args)
+	var category string
+	if len(args) > 0 {
+		if options, ok := args[len(args)-1].(*Hash); ok {
+			var err error
+			category, err = warningCategory(options)
+			if err != nil {
+				return nil, err
+			}
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) == 0 || warningsDisabled() {
+		return NIL, nil
+	}
+	if category != "" {
+		if enabled, _ := isWarningCategoryEnabled(category); !enabled {
+			return NIL, nil
+		}
+	}
+	var out strings.Builder
+	for _, line := range flattenLines(args) {
+		out.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	warnArgs := []RubyObject{&String{Value: out.String()}}
+	if category != "" {
+		options := NewHash(nil)
+		options.Set(NewSymbol("category"), NewSymbol(category))
+		warnArgs = append(warnArgs, options)
+	}
+	_, err := r.emitWarning(streams, warnArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return NIL, nil
+}
+
+// flattenLines returns the string representation of all objects, with the
+// elements of nested Arrays in place of the Arrays
+func flattenLines(objects []RubyObject) []string {
+	var lines []string
+	for _, obj := range objects {
+		if array, ok := obj.(*Array); ok {
+			lines = append(lines, flattenLines(array.Elements)...)
+			continue
+		}
+		lines = append(lines, toS(obj))
+	}
+	return lines
+}
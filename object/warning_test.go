@@ -0,0 +1,99 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestKernelWarn(t *testing.T) {
+	str := func(value string) *String { return &String{Value: value} }
+	category := func(name string) *Hash {
+		options := &Hash{}
+		options.Set(&Symbol{"category"}, &Symbol{name})
+		return options
+	}
+	tests := []struct {
+		name   string
+		level  int
+		args   []RubyObject
+		output string
+	}{
+		{"single message", 1, []RubyObject{str("foo")}, "foo\n"},
+		{"trailing newline kept", 1, []RubyObject{str("foo\n")}, "foo\n"},
+		{"many messages", 1, []RubyObject{str("foo"), NewInteger(3)}, "foo\n3\n"},
+		{"nested arrays", 1, []RubyObject{NewArray(str("a"), NewArray(str("b")))}, "a\nb\n"},
+		{"no messages", 1, nil, ""},
+		{"verbose", 2, []RubyObject{str("foo")}, "foo\n"},
+		{"silenced", 0, []RubyObject{str("foo")}, ""},
+		{"enabled category", 1, []RubyObject{str("foo"), category("experimental")}, "foo\n"},
+		{"disabled category", 1, []RubyObject{str("foo"), category("deprecated")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			defer func(stderr io.Writer) { Stderr = stderr }(Stderr)
+			Stderr = &out
+			defer SetWarningLevel(1)
+			SetWarningLevel(tt.level)
+
+			result, err := kernelWarn(nil, &Object{}, tt.args...)
+
+			checkError(t, err, nil)
+			checkResult(t, result, NIL)
+			if out.String() != tt.output {
+				t.Logf("Expected output %q, got %q", tt.output, out.String())
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("unknown category", func(t *testing.T) {
+		_, err := kernelWarn(nil, &Object{}, str("foo"), category("foo"))
+
+		checkError(t, err, NewArgumentError("unknown category: foo"))
+	})
+}
+
+func TestWarningCategories(t *testing.T) {
+	result, err := Send(warningModule, "[]", &Symbol{"deprecated"})
+	checkError(t, err, nil)
+	checkResult(t, result, FALSE)
+
+	defer Send(warningModule, "[]=", &Symbol{"deprecated"}, FALSE)
+	result, err = Send(warningModule, "[]=", &Symbol{"deprecated"}, TRUE)
+	checkError(t, err, nil)
+	checkResult(t, result, TRUE)
+
+	result, err = Send(warningModule, "[]", &Symbol{"deprecated"})
+	checkError(t, err, nil)
+	checkResult(t, result, TRUE)
+
+	_, err = Send(warningModule, "[]", &Symbol{"foo"})
+	checkError(t, err, NewArgumentError("unknown category: foo"))
+}
+
+func TestWarningLevel(t *testing.T) {
+	defer SetWarningLevel(1)
+	tests := []struct {
+		level    int
+		expected RubyObject
+		verbose  bool
+	}{
+		{0, NIL, false},
+		{1, FALSE, false},
+		{2, TRUE, true},
+	}
+
+	for _, tt := range tests {
+		SetWarningLevel(tt.level)
+
+		verbose, _ := kernelFunctions.Get("$VERBOSE")
+		checkResult(t, verbose, tt.expected)
+		if IsVerbose() != tt.verbose {
+			t.Logf("Expected IsVerbose to return %t for level %d", tt.verbose, tt.level)
+			t.Fail()
+		}
+	}
+}
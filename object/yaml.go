@@ -0,0 +1,278 @@
+package object
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlModule is Psych, the YAML implementation of Ruby, which is also
+// available as YAML
+var yamlModule = newModule("Psych", yamlMethods)
+
+func init() {
+	classes.Set("Psych", yamlModule)
+	classes.Set("YAML", yamlModule)
+	setConstant(yamlModule, "Exception", psychExceptionClass)
+	setConstant(yamlModule, "SyntaxError", psychSyntaxErrorClass)
+	kernelMethodSet["to_yaml"] = withArity(0, publicMethod(kernelToYAML))
+}
+
+var yamlMethods = map[string]RubyMethod{
+	"load":      withArityRange(1, 2, publicMethod(yamlLoad)),
+	"load_file": withArityRange(1, 2, publicMethod(yamlLoadFile)),
+	"dump":      withArity(1, publicMethod(yamlDump)),
+}
+
+// yamlLoad parses the first YAML document of the String passed into the
+// corresponding Ruby objects. Mapping keys are kept in order, and become
+// Symbols with the option symbolize_names. An empty document is false.
+func yamlLoad(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	source, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	return loadYAML([]byte(source.Value), "<unknown>", args[1:])
+}
+
+// yamlLoadFile loads the YAML document of the file at the path passed
+func yamlLoadFile(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	path, ok := args[0].(*String)
+	if !ok {
+		return nil, NewImplicitConversionTypeError(&String{}, args[0])
+	}
+	source, err := ioutil.ReadFile(path.Value)
+	if err != nil {
+		return nil, NewRuntimeError("%s", err)
+	}
+	return loadYAML(source, path.Value, args[1:])
+}
+
+// loadYAML parses source, read from filename, with the options in args
+func loadYAML(source []byte, filename string, args []RubyObject) (RubyObject, error) {
+	options, err := jsonOptions(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	symbolizeNames, ok := options.Get(NewSymbol("symbolize_names"))
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return nil, NewYAMLSyntaxError("(%s): %s", filename, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if len(document.Content) == 0 {
+		return FALSE, nil
+	}
+	return yamlToRuby(document.Content[0], ok && isTruthy(symbolizeNames))
+}
+
+// yamlToRuby converts the YAML node into a Ruby object
+func yamlToRuby(node *yaml.Node, symbolizeNames bool) (RubyObject, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlToRuby(node.Alias, symbolizeNames)
+	case yaml.SequenceNode:
+		elements := make([]RubyObject, len(node.Content))
+		for i, child := range node.Content {
+			element, err := yamlToRuby(child, symbolizeNames)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return NewArray(elements...), nil
+	case yaml.MappingNode:
+		hash := NewHash(nil)
+		if err := yamlMerge(hash, node, symbolizeNames); err != nil {
+			return nil, err
+		}
+		return hash, nil
+	default:
+		return yamlScalar(node)
+	}
+}
+
+// yamlMerge sets the pairs of the mapping node within hash. The pairs of
+// mappings merged by the key `<<` do not override the ones set explicitly.
+func yamlMerge(hash *Hash, node *yaml.Node, symbolizeNames bool) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			merged := NewHash(nil)
+			sources := []*yaml.Node{valueNode}
+			if valueNode.Kind == yaml.SequenceNode {
+				sources = valueNode.Content
+			}
+			for _, source := range sources {
+				if source.Kind == yaml.AliasNode {
+					source = source.Alias
+				}
+				if err := yamlMerge(merged, source, symbolizeNames); err != nil {
+					return err
+				}
+			}
+			keys, values := merged.Keys(), merged.Values()
+			for j, key := range keys {
+				if _, ok := hash.Get(key); !ok {
+					hash.Set(key, values[j])
+				}
+			}
+			continue
+		}
+		key, err := yamlToRuby(keyNode, false)
+		if err != nil {
+			return err
+		}
+		if str, ok := key.(*String); ok && symbolizeNames {
+			key = NewSymbol(str.Value)
+		}
+		value, err := yamlToRuby(valueNode, symbolizeNames)
+		if err != nil {
+			return err
+		}
+		hash.Set(key, value)
+	}
+	return nil
+}
+
+// yamlScalar converts the scalar node by its resolved tag
+func yamlScalar(node *yaml.Node) (RubyObject, error) {
+	var err error
+	switch node.ShortTag() {
+	case "!!null":
+		return NIL, nil
+	case "!!bool":
+		var b bool
+		if err = node.Decode(&b); err == nil {
+			return nativeBoolToBoolean(b), nil
+		}
+	case "!!int":
+		var i int64
+		if err = node.Decode(&i); err == nil {
+			return NewInteger(i), nil
+		}
+	case "!!float":
+		var f float64
+		if err = node.Decode(&f); err == nil {
+			return NewFloat(f), nil
+		}
+	case "!!timestamp":
+		var t time.Time
+		if err = node.Decode(&t); err == nil {
+			return NewTime(t), nil
+		}
+	default:
+		if strings.HasPrefix(node.Value, ":") && len(node.Value) > 1 && node.Style == 0 {
+			return NewSymbol(node.Value[1:]), nil
+		}
+		return &String{Value: node.Value}, nil
+	}
+	return nil, NewYAMLSyntaxError("%s", strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+// yamlDump returns the YAML document representing the object passed
+func yamlDump(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return dumpYAML(args[0])
+}
+
+// kernelToYAML returns the YAML document representing self, like
+// YAML.dump
+func kernelToYAML(context RubyObject, args ...RubyObject) (RubyObject, error) {
+	return dumpYAML(context)
+}
+
+// dumpYAML returns the YAML document representing obj, starting with the
+// document marker like the documents Ruby generates
+func dumpYAML(obj RubyObject) (RubyObject, error) {
+	node, err := rubyToYAML(obj, 0)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, NewTypeError("%s", err)
+	}
+	encoder.Close()
+	separator := "\n"
+	if node.Kind == yaml.ScalarNode || len(node.Content) == 0 {
+		separator = " "
+	}
+	return &String{Value: "---" + separator + out.String()}, nil
+}
+
+// rubyToYAML converts obj into a YAML node. Objects other than nil,
+// booleans, numbers, Strings, Symbols, Times, Arrays and Hashes are
+// represented by their to_s.
+func rubyToYAML(obj RubyObject, depth int) (*yaml.Node, error) {
+	if depth > jsonMaxNesting {
+		return nil, NewArgumentError("nesting of %d is too deep", depth)
+	}
+	scalar := func(tag, value string) (*yaml.Node, error) {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}, nil
+	}
+	switch obj := obj.(type) {
+	case *nilObject:
+		return scalar("!!null", "")
+	case *Boolean:
+		return scalar("!!bool", obj.Inspect())
+	case *Integer:
+		return scalar("!!int", obj.Inspect())
+	case *Float:
+		switch {
+		case math.IsNaN(obj.Value):
+			return scalar("!!float", ".nan")
+		case math.IsInf(obj.Value, 1):
+			return scalar("!!float", ".inf")
+		case math.IsInf(obj.Value, -1):
+			return scalar("!!float", "-.inf")
+		}
+		return scalar("!!float", obj.Inspect())
+	case *String:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: obj.Value}
+		if strings.HasPrefix(obj.Value, ":") {
+			// quoted to not be loaded as Symbol
+			node.Style = yaml.DoubleQuotedStyle
+		}
+		return node, nil
+	case *Symbol:
+		return scalar("!!str", ":"+obj.Value)
+	case *Time:
+		return scalar("!!timestamp", obj.Value.Format("2006-01-02 15:04:05.999999999 -07:00"))
+	case *Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, element := range obj.Elements {
+			child, err := rubyToYAML(element, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case *Hash:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys, values := obj.Keys(), obj.Values()
+		for i, key := range keys {
+			keyNode, err := rubyToYAML(key, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			valueNode, err := rubyToYAML(values[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, keyNode, valueNode)
+		}
+		return node, nil
+	default:
+		name, err := jsonObjectString(obj)
+		if err != nil {
+			return nil, err
+		}
+		return scalar("!!str", name)
+	}
+}
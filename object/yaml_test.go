@@ -0,0 +1,71 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestYAMLLoad(t *testing.T) {
+	symbolizeNames := NewHash(nil)
+	symbolizeNames.Set(NewSymbol("symbolize_names"), TRUE)
+
+	tests := []struct {
+		source   string
+		options  []RubyObject
+		expected string
+	}{
+		{"b: 1\na: [2.5, true, ~, x]\n", nil, "{b=>1, a=>[2.5, true, nil, x]}"},
+		{"a:\n  b: :c\n", []RubyObject{symbolizeNames}, "{:a=>{:b=>:c}}"},
+		{"base: &base\n  x: 1\n  y: 2\nderived:\n  <<: *base\n  y: 3\n", nil, "{base=>{x=>1, y=>2}, derived=>{x=>1, y=>3}}"},
+		{"- 0x10\n- .inf\n- ':a'\n", nil, "[16, Infinity, :a]"},
+		{"", nil, "false"},
+	}
+
+	for _, tt := range tests {
+		args := append([]RubyObject{&String{Value: tt.source}}, tt.options...)
+		result, err := yamlLoad(yamlModule, args...)
+
+		checkError(t, err, nil)
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected %q to load as %s, got %s", tt.source, tt.expected, result.Inspect())
+			t.Fail()
+		}
+	}
+
+	_, err := yamlLoad(yamlModule, &String{Value: "a: [1, 2\nb: 3"})
+
+	checkError(t, err, NewYAMLSyntaxError("(<unknown>): line 1: did not find expected ',' or ']'"))
+}
+
+func TestYAMLDump(t *testing.T) {
+	hash := NewHash(nil)
+	hash.Set(&String{Value: "name"}, &String{Value: "app"})
+	hash.Set(NewSymbol("ports"), NewArray(NewInteger(80), NewInteger(443)))
+	hash.Set(&String{Value: "debug"}, NIL)
+	hash.Set(&String{Value: "mode"}, &String{Value: ":fast"})
+
+	tests := []struct {
+		obj      RubyObject
+		expected string
+	}{
+		{hash, "---\nname: app\n:ports:\n  - 80\n  - 443\ndebug:\nmode: \":fast\"\n"},
+		{NewArray(&String{Value: "true"}, NewFloat(1.5)), "---\n- \"true\"\n- 1.5\n"},
+		{NewInteger(1), "--- 1\n"},
+		{NewArray(), "--- []\n"},
+	}
+
+	for _, tt := range tests {
+		result, err := yamlDump(yamlModule, tt.obj)
+
+		checkError(t, err, nil)
+		checkResult(t, result, &String{Value: tt.expected})
+	}
+
+	roundTrip, err := Send(hash, "to_yaml")
+	checkError(t, err, nil)
+	loaded, err := yamlLoad(yamlModule, roundTrip)
+	checkError(t, err, nil)
+	if loaded.Inspect() != hash.Inspect() {
+		t.Logf("Expected dumped YAML to load as %s, got %s", hash.Inspect(), loaded.Inspect())
+		t.Fail()
+	}
+}
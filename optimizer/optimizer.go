@@ -0,0 +1,265 @@
+package optimizer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/evaluator"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// Optimize rewrites program in place into an equivalent but cheaper to
+// evaluate program and returns it. It
+//
+//   - folds arithmetic and comparisons of numeric literals and the
+//     concatenation of string literals,
+//   - eliminates the branches of conditionals and loops which cannot be
+//     reached due to a literal condition, and
+//   - resolves symbols and frozen string literals to the objects they
+//     evaluate to, so that they are not allocated on every evaluation.
+//
+// Only operations the evaluator carries out itself instead of sending them
+// as method are folded, so that redefining methods has the same effect on
+// an optimized program as on the original one.
+func Optimize(program *ast.Program) *ast.Program {
+	return ast.Rewrite(program, optimize).(*ast.Program)
+}
+
+func optimize(node ast.Node) ast.Node {
+	switch node := node.(type) {
+	case *ast.Program:
+		node.Statements = eliminateStatements(node.Statements)
+	case *ast.BlockStatement:
+		node.Statements = eliminateStatements(node.Statements)
+	case *ast.InfixExpression:
+		return foldInfix(node)
+	case *ast.PrefixExpression:
+		return foldPrefix(node)
+	case *ast.IfExpression:
+		return eliminateIf(node)
+	case *ast.WhileExpression:
+		return eliminateWhile(node)
+	case *ast.SymbolLiteral:
+		return &ast.ObjectLiteral{Token: node.Token, Value: object.NewSymbol(node.Value)}
+	case *ast.StringLiteral:
+		if node.Frozen {
+			return &ast.ObjectLiteral{Token: node.Token, Value: object.NewFrozenString(node.Value)}
+		}
+	}
+	return node
+}
+
+// foldedOperators are the infix operators the evaluator applies itself to
+// the operands of the given type, without sending them as method
+var foldedOperators = map[object.Type]map[string]bool{
+	object.INTEGER_OBJ: {"+": true, "-": true, "*": true, "<": true, ">": true, "==": true, "!=": true},
+	object.FLOAT_OBJ:   {"+": true, "-": true, "*": true, "/": true, "<": true, ">": true, "==": true, "!=": true},
+	object.STRING_OBJ:  {"+": true},
+}
+
+func foldInfix(node *ast.InfixExpression) ast.Node {
+	left, ok := valueOf(node.Left)
+	if !ok {
+		return node
+	}
+	right, ok := valueOf(node.Right)
+	if !ok {
+		return node
+	}
+	if !foldedOperators[operandType(left, right)][node.Operator] {
+		return node
+	}
+	result, err := evaluator.EvalInfix(nil, node.Operator, left, right)
+	if err != nil {
+		return node
+	}
+	return literalOf(result, node.Token, node)
+}
+
+// operandType returns the type the evaluator treats both operands as, or
+// an empty type if it does not apply any operator itself
+func operandType(left, right object.RubyObject) object.Type {
+	switch {
+	case left.Type() == right.Type():
+		return left.Type()
+	case isNumeric(left) && isNumeric(right):
+		return object.FLOAT_OBJ
+	default:
+		return ""
+	}
+}
+
+func isNumeric(obj object.RubyObject) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func foldPrefix(node *ast.PrefixExpression) ast.Node {
+	right, ok := valueOf(node.Right)
+	if !ok {
+		return node
+	}
+	switch {
+	case node.Operator == "!":
+	case node.Operator == "-" && (right.Type() == object.INTEGER_OBJ || right.Type() == object.FLOAT_OBJ):
+	default:
+		return node
+	}
+	result, err := evaluator.EvalPrefix(nil, node.Operator, right)
+	if err != nil {
+		return node
+	}
+	return literalOf(result, node.Token, node)
+}
+
+// eliminateIf replaces node by the expression of the branch chosen by a
+// literal condition. Branches consisting of several statements are spliced
+// into the surrounding statements by eliminateStatements.
+func eliminateIf(node *ast.IfExpression) ast.Node {
+	branch, ok := chosenBranch(node)
+	if !ok {
+		return node
+	}
+	if branch == nil || len(branch.Statements) == 0 {
+		return &ast.Nil{Token: token.Token{Type: token.NIL, Literal: "nil", Line: node.Token.Line}}
+	}
+	if len(branch.Statements) == 1 {
+		if statement, ok := branch.Statements[0].(*ast.ExpressionStatement); ok && statement.Expression != nil {
+			return statement.Expression
+		}
+	}
+	return node
+}
+
+// chosenBranch returns the branch of node chosen by its condition, if the
+// condition is a literal and the other branch can be dropped
+func chosenBranch(node *ast.IfExpression) (*ast.BlockStatement, bool) {
+	condition, ok := valueOf(node.Condition)
+	if !ok {
+		return nil, false
+	}
+	chosen, dropped := node.Consequence, node.Alternative
+	if !isTruthy(condition) {
+		chosen, dropped = dropped, chosen
+	}
+	if dropped != nil && definesVariables(dropped) {
+		return nil, false
+	}
+	return chosen, true
+}
+
+// eliminateWhile replaces loops which never run by nil
+func eliminateWhile(node *ast.WhileExpression) ast.Node {
+	condition, ok := valueOf(node.Condition)
+	if !ok || isTruthy(condition) || definesVariables(node.Body) {
+		return node
+	}
+	return &ast.Nil{Token: token.Token{Type: token.NIL, Literal: "nil", Line: node.Token.Line}}
+}
+
+// eliminateStatements splices the chosen branch of statements consisting
+// of a conditional with a literal condition into statements and drops
+// literals whose value is not used
+func eliminateStatements(statements []ast.Statement) []ast.Statement {
+	var result []ast.Statement
+	for i, statement := range statements {
+		expression, ok := statement.(*ast.ExpressionStatement)
+		if !ok {
+			result = append(result, statement)
+			continue
+		}
+		if _, isLiteral := valueOf(expression.Expression); isLiteral && i < len(statements)-1 {
+			continue
+		}
+		conditional, ok := expression.Expression.(*ast.IfExpression)
+		if !ok {
+			result = append(result, statement)
+			continue
+		}
+		branch, ok := chosenBranch(conditional)
+		if !ok {
+			result = append(result, statement)
+			continue
+		}
+		if branch != nil {
+			result = append(result, branch.Statements...)
+		}
+		if (branch == nil || len(branch.Statements) == 0) && i == len(statements)-1 {
+			result = append(result, &ast.ExpressionStatement{
+				Token:      expression.Token,
+				Expression: &ast.Nil{Token: token.Token{Type: token.NIL, Literal: "nil", Line: expression.Token.Line}},
+			})
+		}
+	}
+	return result
+}
+
+// definesVariables reports whether node assigns any local variable. Local
+// variables are defined by their assignment even if it is never evaluated,
+// so such nodes cannot be dropped.
+func definesVariables(node ast.Node) bool {
+	defines := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.VariableAssignment:
+			name := node.Name.Value
+			defines = defines || !strings.HasPrefix(name, "$") && !object.IsConstantName(name)
+		case *ast.RescueBlock:
+			defines = defines || node.Exception != nil
+		}
+		return !defines
+	})
+	return defines
+}
+
+// valueOf returns the object the literal expr evaluates to
+func valueOf(expr ast.Expression) (object.RubyObject, bool) {
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return object.NewInteger(expr.Value), true
+	case *ast.FloatLiteral:
+		return object.NewFloat(expr.Value), true
+	case *ast.Boolean:
+		if expr.Value {
+			return object.TRUE, true
+		}
+		return object.FALSE, true
+	case *ast.Nil:
+		return object.NIL, true
+	case *ast.StringLiteral:
+		return &object.String{Value: expr.Value}, true
+	case *ast.SymbolLiteral:
+		return object.NewSymbol(expr.Value), true
+	case *ast.ObjectLiteral:
+		return expr.Value.(object.RubyObject), true
+	default:
+		return nil, false
+	}
+}
+
+// literalOf returns the literal evaluating to obj, or fallback if there is
+// none
+func literalOf(obj object.RubyObject, tok token.Token, fallback ast.Node) ast.Node {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		literal := strconv.FormatInt(obj.Value, 10)
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal, Pos: tok.Pos, Line: tok.Line}, Value: obj.Value}
+	case *object.Float:
+		literal := strconv.FormatFloat(obj.Value, 'g', -1, 64)
+		return &ast.FloatLiteral{Token: token.Token{Type: token.FLOAT, Literal: literal, Pos: tok.Pos, Line: tok.Line}, Value: obj.Value}
+	case *object.String:
+		return &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: obj.Value, Pos: tok.Pos, Line: tok.Line}, Value: obj.Value}
+	case *object.Boolean:
+		if obj.Value {
+			return &ast.Boolean{Token: token.Token{Type: token.TRUE, Literal: "true", Pos: tok.Pos, Line: tok.Line}, Value: true}
+		}
+		return &ast.Boolean{Token: token.Token{Type: token.FALSE, Literal: "false", Pos: tok.Pos, Line: tok.Line}, Value: false}
+	default:
+		return fallback
+	}
+}
+
+func isTruthy(obj object.RubyObject) bool {
+	return obj != object.NIL && obj != object.FALSE
+}
@@ -0,0 +1,153 @@
+package optimizer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/evaluator"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse error for %q: %v", input, err)
+	}
+	return program
+}
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2 * 3", "7"},
+		{"1 < 2", "true"},
+		{"-5 + 1.5", "-3.5"},
+		{"3.0 / 2", "1.5"},
+		{`"a" + "b"`, "ab"},
+		{"!nil", "true"},
+		{"foo(1 + 1)", "foo(2)"},
+		{"x + 1 * 2", "(x + 2)"},
+		{"if true; 1; else; 2; end", "1"},
+		{"if nil; 1; else; 2; end", "2"},
+		{"if false; 1; end", "nil"},
+		{"x = if 1; 3; end", "x = 3"},
+		{"while false; foo; end", "nil"},
+		// operations sent as method are left alone
+		{"7 / 2", "(7 / 2)"},
+		{"7 % 2", "(7 % 2)"},
+		{`"a" * 2`, "(a * 2)"},
+		{"1 <=> 2", "(1 <=> 2)"},
+		// operations raising an error are left alone
+		{"1 / 0", "(1 / 0)"},
+		{`1 + "a"`, "(1 + a)"},
+		// dropped branches must not define variables
+		{"if true; 1; else; x = 2; end", "iftrue 1else x = 2 end"},
+		{"while false; x = 2; end", "while false do x = 2 end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual := Optimize(parse(t, tt.input)).String()
+
+			if actual != tt.expected {
+				t.Logf("Expected optimized program to equal %q, got %q\n", tt.expected, actual)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestOptimizeSplicesBranches(t *testing.T) {
+	tests := []struct {
+		input      string
+		statements int
+	}{
+		{"if true\nfoo\nbar\nend\nbaz", 3},
+		{"if false\nfoo\nbar\nend\nbaz", 1},
+		{"baz\nif false\nfoo\nend\n", 2},
+		{"1\nfoo\n:a\n2", 2},
+		{"def qux\nif true\nfoo\nbar\nend\nend", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			program := Optimize(parse(t, tt.input))
+
+			if len(program.Statements) != tt.statements {
+				t.Logf("Expected %d statements, got %d: %s\n", tt.statements, len(program.Statements), program)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestOptimizeResolvesLiterals(t *testing.T) {
+	t.Run("symbols", func(t *testing.T) {
+		program := Optimize(parse(t, ":foo"))
+
+		literal, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ObjectLiteral)
+		if !ok {
+			t.Fatalf("Expected *ast.ObjectLiteral, got %T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+		}
+		if !reflect.DeepEqual(literal.Value, &object.Symbol{Value: "foo"}) {
+			t.Logf("Expected :foo, got %v", literal.Value)
+			t.Fail()
+		}
+	})
+	t.Run("frozen strings", func(t *testing.T) {
+		program := Optimize(parse(t, "# frozen_string_literal: true\n\"foo\""))
+
+		literal, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ObjectLiteral)
+		if !ok {
+			t.Fatalf("Expected *ast.ObjectLiteral, got %T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+		}
+		str, ok := literal.Value.(*object.String)
+		if !ok || str.Value != "foo" || !str.Frozen() {
+			t.Logf("Expected frozen string foo, got %v", literal.Value)
+			t.Fail()
+		}
+	})
+	t.Run("mutable strings", func(t *testing.T) {
+		program := Optimize(parse(t, `"foo"`))
+
+		if _, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.StringLiteral); !ok {
+			t.Logf("Expected *ast.StringLiteral, got %T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+			t.Fail()
+		}
+	})
+}
+
+func TestOptimizeKeepsResults(t *testing.T) {
+	tests := []string{
+		"x = 5; y = 2 * 3 + x; y - 1",
+		"if false; x = 3; end; x",
+		"if true\na = 1\nb = 2\nend\na + b",
+		"def foo\nif 1\n2\nelse\n3\nend\nend\nfoo",
+		"[:a, :b, 1 + 1.5, \"a\" + \"b\"]",
+		"x = 0\nwhile false\nx = 1\nend\nx",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			expected, err := evaluator.Eval(parse(t, input), object.NewMainEnvironment())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			actual, err := evaluator.Eval(Optimize(parse(t, input)), object.NewMainEnvironment())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if actual.Inspect() != expected.Inspect() {
+				t.Logf("Expected %s, got %s\n", expected.Inspect(), actual.Inspect())
+				t.Fail()
+			}
+		})
+	}
+}
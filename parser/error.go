@@ -3,6 +3,8 @@ package parser
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/goruby/goruby/token"
 	"github.com/pkg/errors"
@@ -17,7 +19,7 @@ var _ error = &Errors{}
 // NewErrors returns a composite Error object wrapping multiple errors into
 // one.
 func NewErrors(context string, errors ...error) *Errors {
-	return &Errors{context, errors}
+	return &Errors{context: context, errors: errors}
 }
 
 // Errors represents a group of errors and its context
@@ -26,6 +28,83 @@ func NewErrors(context string, errors ...error) *Errors {
 type Errors struct {
 	context string
 	errors  []error
+	lines   []int  // the lines the errors were found at, if known
+	offsets []int  // the positions within source the errors were found at, if known
+	source  string // the input parsed
+}
+
+// A SyntaxError is an error found while parsing the input
+type SyntaxError struct {
+	// Line is the line of the input the error was found at, counting from
+	// 1, or 0 if it is unknown
+	Line int
+	// Column is the character within the line the error was found at,
+	// counting from 1, or 0 if it is unknown
+	Column  int
+	Message string
+	// Source is the line of the input the error was found at, without
+	// its newline
+	Source string
+}
+
+// SyntaxErrors returns the errors along with the positions they were found
+// at
+func (e *Errors) SyntaxErrors() []SyntaxError {
+	syntaxErrors := make([]SyntaxError, len(e.errors))
+	for i, err := range e.errors {
+		syntaxErrors[i].Message = err.Error()
+		if i < len(e.lines) {
+			syntaxErrors[i].Line = e.lines[i]
+		}
+		if i < len(e.offsets) && e.lines[i] != 0 {
+			offset := e.offsets[i]
+			if offset == len(e.source) && strings.HasSuffix(e.source, "\n") && e.lines[i] > 1 {
+				// an error at the end of the input ending with a newline
+				// belongs to the last line
+				offset--
+				syntaxErrors[i].Line--
+			}
+			syntaxErrors[i].Column, syntaxErrors[i].Source = e.column(offset)
+		}
+	}
+	return syntaxErrors
+}
+
+// column returns the column of offset within source, counting from 1, and
+// the line it is part of. It returns 0 if offset is outside of source.
+func (e *Errors) column(offset int) (int, string) {
+	if offset < 0 || offset > len(e.source) {
+		return 0, ""
+	}
+	start := strings.LastIndex(e.source[:offset], "\n") + 1
+	end := strings.IndexByte(e.source[offset:], '\n')
+	if end < 0 {
+		end = len(e.source)
+	} else {
+		end += offset
+	}
+	return utf8.RuneCountInString(e.source[start:offset]) + 1, strings.TrimSuffix(e.source[start:end], "\r")
+}
+
+// Caret returns the source line of the error followed by a line marking its
+// column with a caret, each ending with a newline. It returns an empty
+// string if the column is unknown.
+func (s SyntaxError) Caret() string {
+	if s.Column == 0 {
+		return ""
+	}
+	var marker strings.Builder
+	for i, r := range []rune(s.Source) {
+		if i >= s.Column-1 {
+			break
+		}
+		if r == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	return fmt.Sprintf("%s\n%s^\n", s.Source, marker.String())
 }
 
 // Error returns all error messages divided by newlines and prepended with the
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/goruby/goruby/lexer"
 	"github.com/goruby/goruby/token"
 	"github.com/pkg/errors"
 )
@@ -206,3 +208,133 @@ func TestIsEOFInsteadOfNewline(t *testing.T) {
 		}
 	})
 }
+
+func TestSyntaxErrors(t *testing.T) {
+	input := "x = 1\ny = (\nputs )\nz = 3\n"
+
+	_, err := New(lexer.New(input)).ParseProgram()
+
+	parseErrors, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Expected parse errors, got %T:%v", err, err)
+	}
+	syntaxErrors := parseErrors.SyntaxErrors()
+	if len(syntaxErrors) != len(parseErrors.errors) {
+		t.Fatalf("Expected a syntax error per error, got %v", syntaxErrors)
+	}
+	expectedLines := []int{2, 3, 3}
+	for i, syntaxError := range syntaxErrors {
+		if syntaxError.Message != parseErrors.errors[i].Error() {
+			t.Errorf("Expected message %q, got %q", parseErrors.errors[i].Error(), syntaxError.Message)
+		}
+		if i < len(expectedLines) && syntaxError.Line != expectedLines[i] {
+			t.Errorf("Expected error %q at line %d, got %d", syntaxError.Message, expectedLines[i], syntaxError.Line)
+		}
+	}
+}
+
+func TestSyntaxErrorsRecovery(t *testing.T) {
+	input := "a = 1 +\nb = 2\nif a\n  c = ) + )\nend\nd = ]; e = 3\n"
+
+	_, err := New(lexer.New(input)).ParseProgram()
+
+	parseErrors, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Expected parse errors, got %T:%v", err, err)
+	}
+	expected := []SyntaxError{
+		{Line: 1, Column: 8, Source: "a = 1 +"},
+		{Line: 4, Column: 7, Source: "  c = ) + )"},
+		{Line: 6, Column: 5, Source: "d = ]; e = 3"},
+	}
+	syntaxErrors := parseErrors.SyntaxErrors()
+	if len(syntaxErrors) != len(expected) {
+		t.Fatalf("Expected a syntax error per erroneous statement, got %v", syntaxErrors)
+	}
+	for i, syntaxError := range syntaxErrors {
+		expected[i].Message = syntaxError.Message
+		if syntaxError != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], syntaxError)
+		}
+	}
+}
+
+func TestSyntaxErrorCaret(t *testing.T) {
+	tests := []struct {
+		syntaxError SyntaxError
+		expected    string
+	}{
+		{SyntaxError{Line: 1, Column: 5, Source: "d = ]"}, "d = ]\n    ^\n"},
+		{SyntaxError{Line: 2, Column: 3, Source: "\tä)"}, "\tä)\n\t ^\n"},
+		{SyntaxError{Line: 3, Source: "d = ]"}, ""},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.syntaxError.Caret(); actual != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, actual)
+		}
+	}
+}
+
+func TestSyntaxErrorAtEndOfInput(t *testing.T) {
+	_, err := New(lexer.New("x = 1\nif x\n")).ParseProgram()
+
+	parseErrors, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Expected parse errors, got %T:%v", err, err)
+	}
+	syntaxErrors := parseErrors.SyntaxErrors()
+	expected := SyntaxError{Line: 2, Column: 5, Source: "if x"}
+	if len(syntaxErrors) == 0 {
+		t.Fatalf("Expected a syntax error")
+	}
+	actual := syntaxErrors[len(syntaxErrors)-1]
+	expected.Message = actual.Message
+	if actual != expected {
+		t.Errorf("Expected the error at the end of the last line %+v, got %+v", expected, actual)
+	}
+}
+
+func TestSyntaxErrorsRecoveryInNestedBlocks(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedLines []int
+	}{
+		{"while a && b\n  c\nend\n", []int{1}},
+		{"def foo\n  [1].each do |x|\n    y = )\n  end\n  z = ]\nend\n", []int{3, 5}},
+	}
+
+	for _, tt := range tests {
+		_, err := New(lexer.New(tt.input)).ParseProgram()
+
+		parseErrors, ok := err.(*Errors)
+		if !ok {
+			t.Fatalf("Expected parse errors for %q, got %T:%v", tt.input, err, err)
+		}
+		var lines []int
+		for _, syntaxError := range parseErrors.SyntaxErrors() {
+			lines = append(lines, syntaxError.Line)
+		}
+		if !reflect.DeepEqual(lines, tt.expectedLines) {
+			t.Errorf("Expected errors of %q at lines %v, got %v", tt.input, tt.expectedLines, lines)
+		}
+	}
+}
+
+func TestSyntaxErrorsAreReportedOnce(t *testing.T) {
+	_, err := New(lexer.New("class A\n  def f\n    1\n")).ParseProgram()
+
+	parseErrors, ok := err.(*Errors)
+	if !ok {
+		t.Fatalf("Expected parse errors, got %T:%v", err, err)
+	}
+	syntaxErrors := parseErrors.SyntaxErrors()
+	if len(syntaxErrors) != 1 {
+		t.Fatalf("Expected the missing end to be reported once, got %v", syntaxErrors)
+	}
+	expected := SyntaxError{Line: 3, Column: 6, Source: "    1"}
+	expected.Message = syntaxErrors[0].Message
+	if syntaxErrors[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, syntaxErrors[0])
+	}
+}
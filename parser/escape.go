@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var simpleEscapes = map[byte]string{
+	'n': "\n",
+	't': "\t",
+	's': " ",
+	'r': "\r",
+	'e': "\x1b",
+	'a': "\a",
+	'b': "\b",
+	'f': "\f",
+	'v': "\v",
+}
+
+// unescape replaces all escape sequences within s, as known from double
+// quoted strings, with the characters they represent.
+func unescape(s string) (string, error) {
+	var out bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated escape sequence")
+		}
+		if escaped, ok := simpleEscapes[s[i]]; ok {
+			out.WriteString(escaped)
+			continue
+		}
+		switch c := s[i]; {
+		case c == 'u':
+			r, n, err := unescapeUnicode(s[i+1:])
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(r)
+			i += n
+		case c == 'x':
+			n := countPrefix(s[i+1:], 2, isHex)
+			if n == 0 {
+				return "", fmt.Errorf("invalid hex escape")
+			}
+			value, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+			out.WriteByte(byte(value))
+			i += n
+		case '0' <= c && c <= '7':
+			n := countPrefix(s[i:], 3, isOctal)
+			value, _ := strconv.ParseUint(s[i:i+n], 8, 8)
+			out.WriteByte(byte(value))
+			i += n - 1
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			out.WriteRune(r)
+			i += size - 1
+		}
+	}
+	return out.String(), nil
+}
+
+// unescapeUnicode decodes the unicode escape at the start of s, which is
+// either of the form `XXXX` or `{X...}`. It returns the decoded characters
+// and the number of consumed bytes.
+func unescapeUnicode(s string) (string, int, error) {
+	if !strings.HasPrefix(s, "{") {
+		if countPrefix(s, 4, isHex) != 4 {
+			return "", 0, fmt.Errorf("invalid Unicode escape")
+		}
+		value, _ := strconv.ParseUint(s[:4], 16, 32)
+		return string(rune(value)), 4, nil
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated Unicode escape")
+	}
+	var out bytes.Buffer
+	for _, codepoint := range strings.Fields(s[1:end]) {
+		value, err := strconv.ParseUint(codepoint, 16, 32)
+		if err != nil || len(codepoint) > 6 || value > utf8.MaxRune {
+			return "", 0, fmt.Errorf("invalid Unicode codepoint")
+		}
+		out.WriteRune(rune(value))
+	}
+	return out.String(), end + 1, nil
+}
+
+// countPrefix returns the number of bytes at the start of s, up to max,
+// which satisfy valid
+func countPrefix(s string, max int, valid func(byte) bool) int {
+	n := 0
+	for n < len(s) && n < max && valid(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func isOctal(c byte) bool {
+	return '0' <= c && c <= '7'
+}
@@ -0,0 +1,44 @@
+package parser
+
+import "testing"
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`foo`, "foo"},
+		{`a\nb\tc`, "a\nb\tc"},
+		{`\e\a\b\f\v\r\s`, "\x1b\a\b\f\v\r "},
+		{`été`, "été"},
+		{`\u{48 49}`, "HI"},
+		{`\x4a\x4`, "J\x04"},
+		{`\0\12\101`, "\x00\nA"},
+		{`\"\\\q`, `"\q`},
+	}
+
+	for _, tt := range tests {
+		actual, err := unescape(tt.input)
+		if err != nil {
+			t.Logf("Expected no error for %q, got %v", tt.input, err)
+			t.Fail()
+		}
+
+		if actual != tt.expected {
+			t.Logf("Expected %q to unescape to %q, got %q", tt.input, tt.expected, actual)
+			t.Fail()
+		}
+	}
+
+	t.Run("invalid escapes", func(t *testing.T) {
+		tests := []string{`\`, `\u12`, `\u{12`, `\u{1234567}`, `\xg`}
+
+		for _, input := range tests {
+			_, err := unescape(input)
+			if err == nil {
+				t.Logf("Expected error for %q, got nil", input)
+				t.Fail()
+			}
+		}
+	})
+}
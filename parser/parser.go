@@ -3,6 +3,9 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/goruby/goruby/ast"
 	"github.com/goruby/goruby/lexer"
@@ -14,34 +17,62 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	MODIFIER    // x rescue y
+	RANGE       // x..y
 	EQUALS      // ==
 	LESSGREATER // > or <
 	ASSIGNMENT  // x = 5
+	BITOR       // | or ^
+	BITAND      // &
+	SHIFT       // << or >>
 	SUM         // + or -
 	PRODUCT     // * or /
 	PREFIX      // -X or !X
+	POWER       // x ** y
 	CALL        // myFunction(X)
 	CONTEXT     // foo.myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[token.Type]int{
-	token.EQ:       EQUALS,
-	token.NOTEQ:    EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.ASSIGN:   ASSIGNMENT,
-	token.LPAREN:   CALL,
-	token.IDENT:    CALL,
-	token.INT:      CALL,
-	token.STRING:   CALL,
-	token.SYMBOL:   CALL,
-	token.DOT:      CONTEXT,
-	token.LBRACKET: INDEX,
+	token.EQ:        EQUALS,
+	token.NOTEQ:     EQUALS,
+	token.SPACESHIP: EQUALS,
+	token.MATCH:     EQUALS,
+	token.NOTMATCH:  EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.PIPE:      BITOR,
+	token.CARET:     BITOR,
+	token.AMP:       BITAND,
+	token.LSHIFT:    SHIFT,
+	token.RSHIFT:    SHIFT,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.SLASH:     PRODUCT,
+	token.ASTERISK:  PRODUCT,
+	token.MODULO:    PRODUCT,
+	token.POW:       POWER,
+	token.ASSIGN:    ASSIGNMENT,
+	token.LPAREN:    CALL,
+	token.IDENT:     CALL,
+	token.INT:       CALL,
+	token.FLOAT:     CALL,
+	token.CHAR:      CALL,
+	token.STRING:    CALL,
+	token.SYMBOL:    CALL,
+	token.REGEX:     CALL,
+	token.XSTRING:   CALL,
+	token.TRUE:      CALL,
+	token.FALSE:     CALL,
+	token.NIL:       CALL,
+	token.DEF:       CALL,
+	token.DOT:       CONTEXT,
+	token.SCOPE:     CONTEXT,
+	token.LBRACKET:  INDEX,
+	token.RESCUE:    MODIFIER,
+	token.DOT2:      RANGE,
+	token.DOT3:      RANGE,
 }
 
 type (
@@ -54,6 +85,18 @@ var defaultExpressionTerminators = []token.Type{
 	token.NEWLINE,
 }
 
+// hashLabelTerminators are the tokens which can follow a label without a
+// value, i.e. the tokens which make `{name:}` a shorthand for `{name: name}`
+var hashLabelTerminators = []token.Type{
+	token.COMMA,
+	token.RBRACE,
+	token.RPAREN,
+	token.RBRACKET,
+	token.SEMICOLON,
+	token.NEWLINE,
+	token.EOF,
+}
+
 // New returns a Parser ready to use the tokens emitted by l
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
@@ -65,54 +108,98 @@ func New(l *lexer.Lexer) *Parser {
 	p.nextToken()
 
 	p.prefixParseFns = make(map[token.Type]prefixParseFn)
-	p.registerPrefix(token.IDENT, p.parseIdentifier)
+	p.registerPrefix(token.IDENT, p.parseIdentifierExpression)
+	p.registerPrefix(token.SCOPE, p.parseTopLevelConstant)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharacterLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.CASE, p.parseCaseExpression)
+	p.registerPrefix(token.BEGIN, p.parseBeginExpression)
+	p.registerPrefix(token.WHILE, p.parseWhileExpression)
 	p.registerPrefix(token.DEF, p.parseFunctionLiteral)
 	p.registerPrefix(token.SYMBOL, p.parseSymbolLiteral)
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
+	p.registerPrefix(token.XSTRING, p.parseCommandLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.NIL, p.parseNilLiteral)
 	p.registerPrefix(token.REQUIRE, p.parseRequireExpression)
 	p.registerPrefix(token.SELF, p.parseSelf)
+	p.registerPrefix(token.CLASS, p.parseClassExpression)
+	p.registerPrefix(token.MODULE, p.parseModuleExpression)
+	p.registerPrefix(token.SUPER, p.parseSuper)
 
 	p.infixParseFns = make(map[token.Type]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.MODULO, p.parseInfixExpression)
+	p.registerInfix(token.POW, p.parseInfixExpression)
+	p.registerInfix(token.AMP, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOTEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.SPACESHIP, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.MATCH, p.parseInfixExpression)
+	p.registerInfix(token.NOTMATCH, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpressionWithParens)
 	p.registerInfix(token.IDENT, p.parseCallExpression)
 	p.registerInfix(token.INT, p.parseCallExpression)
+	p.registerInfix(token.FLOAT, p.parseCallExpression)
+	p.registerInfix(token.CHAR, p.parseCallExpression)
 	p.registerInfix(token.STRING, p.parseCallExpression)
 	p.registerInfix(token.DOT, p.parseContextCallExpression)
+	p.registerInfix(token.SCOPE, p.parseScopedExpression)
 	p.registerInfix(token.SYMBOL, p.parseCallExpression)
+	p.registerInfix(token.REGEX, p.parseCallExpression)
+	p.registerInfix(token.XSTRING, p.parseCallExpression)
+	p.registerInfix(token.TRUE, p.parseCallExpression)
+	p.registerInfix(token.FALSE, p.parseCallExpression)
+	p.registerInfix(token.NIL, p.parseCallExpression)
+	p.registerInfix(token.DEF, p.parseCallExpression)
 	p.registerInfix(token.RBRACKET, p.parseCallExpression)
 	p.registerInfix(token.ASSIGN, p.parseVariableAssignExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.RESCUE, p.parseRescueModifier)
+	p.registerInfix(token.DOT2, p.parseRangeLiteral)
+	p.registerInfix(token.DOT3, p.parseRangeLiteral)
 	return p
 }
 
 // A Parser parses the token emitted by the provided lexer.Lexer and returns an
 // AST describing the parsed program.
 type Parser struct {
-	l      *lexer.Lexer
-	errors []error
+	l       *lexer.Lexer
+	errors  []error
+	lines   []int // the lines the errors were found at
+	offsets []int // the positions within the input the errors were found at
+	settled int   // the number of errors recovered from at a statement boundary
+
+	lastEnd ast.Position // the end of the last token passed other than separators
 
 	curToken  token.Token
 	peekToken token.Token
 
 	prefixParseFns map[token.Type]prefixParseFn
 	infixParseFns  map[token.Type]infixParseFn
+
+	blockDepth  int  // the nesting level of block statements
+	loopDepth   int  // the nesting level of loops and blocks
+	inCondition bool // whether `do` terminates the current loop condition
 }
 
 func (p *Parser) registerPrefix(tokenType token.Type, fn prefixParseFn) {
@@ -124,6 +211,9 @@ func (p *Parser) registerInfix(tokenType token.Type, fn infixParseFn) {
 }
 
 func (p *Parser) nextToken() {
+	if !p.currentTokenOneOf(token.NEWLINE, token.SEMICOLON, token.EOF) && p.curToken.Line != 0 {
+		p.lastEnd = ast.TokenEnd(p.curToken)
+	}
 	p.curToken = p.peekToken
 	if p.l.HasNext() {
 		p.peekToken = p.l.NextToken()
@@ -142,7 +232,86 @@ func (p *Parser) peekError(t ...token.Type) {
 		expectedTokens: t,
 		actualToken:    p.peekToken.Type,
 	}
+	tok := p.peekToken
+	if tok.Line == 0 {
+		tok = p.curToken
+	}
+	p.addErrorAt(err, tok)
+}
+
+// addError records err found at the current token
+func (p *Parser) addError(err error) {
+	p.addErrorAt(err, p.curToken)
+}
+
+// addErrorAt records err found at tok. The end of the input is the
+// position of an EOF token without one.
+func (p *Parser) addErrorAt(err error, tok token.Token) {
+	offset := tok.Pos
+	if tok.Type == token.EOF && offset < 0 {
+		offset = len(p.l.Input())
+	}
 	p.errors = append(p.errors, err)
+	p.lines = append(p.lines, tok.Line)
+	p.offsets = append(p.offsets, offset)
+}
+
+// recoverStatement resumes parsing at the next statement boundary if the
+// statement parsed since the parser held errorCount errors was
+// erroneous. Of its own errors only the first one is kept, as the others
+// most likely follow from it, except for errors about the end of the
+// input, which tell that more input might complete the statement. The
+// statement ends at a newline, a semicolon, the end of the input or one
+// of the terminators given.
+func (p *Parser) recoverStatement(errorCount int, terminators ...token.Type) {
+	if len(p.errors) <= p.settled || len(p.errors) <= errorCount {
+		return
+	}
+	keep := errorCount + 1
+	if p.settled > errorCount {
+		keep = p.settled
+	}
+	errors, lines, offsets := p.errors[:keep], p.lines[:keep], p.offsets[:keep]
+	for i := keep; i < len(p.errors); i++ {
+		if IsEOFError(p.errors[i]) {
+			errors = append(errors, p.errors[i])
+			lines = append(lines, p.lines[i])
+			offsets = append(offsets, p.offsets[i])
+		}
+	}
+	p.errors, p.lines, p.offsets = errors, lines, offsets
+	p.settled = len(p.errors)
+
+	boundaries := append([]token.Type{token.NEWLINE, token.SEMICOLON, token.EOF}, terminators...)
+	if p.currentTokenOneOf(token.NEWLINE, token.SEMICOLON, token.EOF) {
+		return
+	}
+	for !p.peekTokenOneOf(boundaries...) {
+		p.nextToken()
+	}
+}
+
+// dropDuplicateErrors removes the errors reported at the same position with
+// the same message as an earlier one, like the missing `end` every
+// unfinished enclosing statement reports at the end of the input
+func (p *Parser) dropDuplicateErrors() {
+	type reported struct {
+		offset  int
+		message string
+	}
+	seen := make(map[reported]bool)
+	errors, lines, offsets := p.errors[:0], p.lines[:0], p.offsets[:0]
+	for i, err := range p.errors {
+		key := reported{p.offsets[i], err.Error()}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		errors = append(errors, err)
+		lines = append(lines, p.lines[i])
+		offsets = append(offsets, p.offsets[i])
+	}
+	p.errors, p.lines, p.offsets = errors, lines, offsets
 }
 
 // ParseProgram returns the parsed program AST and all errors which occured
@@ -153,58 +322,190 @@ func (p *Parser) ParseProgram() (*ast.Program, error) {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 	for !p.currentTokenIs(token.EOF) {
+		errorCount := len(p.errors)
 		stmt := p.parseStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
+		p.recoverStatement(errorCount)
 		p.nextToken()
 	}
+	program.MagicComments = p.l.MagicComments()
+	program.Comments = p.comments()
+	program.Data, program.HasData = p.l.Data()
+	p.dropDuplicateErrors()
 	if len(p.errors) != 0 {
-		return program, NewErrors("Parsing errors", p.errors...)
+		return program, &Errors{
+			context: "Parsing errors",
+			errors:  p.errors,
+			lines:   p.lines,
+			offsets: p.offsets,
+			source:  p.l.Input(),
+		}
 	}
 	return program, nil
 }
 
+// comments returns the comments skipped by the lexer along with their
+// position
+func (p *Parser) comments() []*ast.Comment {
+	var comments []*ast.Comment
+	input, line, counted := p.l.Input(), 1, 0
+	for _, comment := range p.l.Comments() {
+		line += strings.Count(input[counted:comment.Pos], "\n")
+		counted = comment.Pos
+		lineStart := strings.LastIndexByte(input[:comment.Pos], '\n') + 1
+		comments = append(comments, &ast.Comment{
+			Pos: ast.Position{
+				Offset: comment.Pos,
+				Line:   line,
+				Column: utf8.RuneCountInString(input[lineStart:comment.Pos]) + 1,
+			},
+			Text: comment.Text,
+		})
+	}
+	return comments
+}
+
+// setSpan records that node starts at start and ends with the last token
+// parsed, not counting statement separators
+func (p *Parser) setSpan(node ast.Node, start token.Token) {
+	if node == nil || start.Line == 0 {
+		return
+	}
+	end := p.lastEnd
+	if !p.currentTokenOneOf(token.NEWLINE, token.SEMICOLON, token.EOF) && p.curToken.Line != 0 {
+		end = ast.TokenEnd(p.curToken)
+	}
+	ast.SetSpan(node, ast.TokenPos(start), end)
+}
+
 func (p *Parser) parseStatement() ast.Statement {
+	start := p.curToken
+	stmt := p.parseStatementNode()
+	p.setSpan(stmt, start)
+	return stmt
+}
+
+func (p *Parser) parseStatementNode() ast.Statement {
 	switch p.curToken.Type {
 	case token.ILLEGAL:
 		msg := fmt.Errorf("%s", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	case token.EOF:
 		err := &unexpectedTokenError{
 			expectedTokens: []token.Type{token.NEWLINE},
 			actualToken:    token.EOF,
 		}
-		p.errors = append(p.errors, err)
+		p.addError(err)
 
 		return nil
-	case token.NEWLINE:
+	case token.NEWLINE, token.SEMICOLON:
 		return nil
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.NEXT:
+		return p.parseNextStatement()
+	case token.BEGIN_BLOCK:
+		return p.parseBeginBlock()
+	case token.END_BLOCK:
+		return p.parseEndBlock()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+func (p *Parser) parseReturnStatement() ast.Statement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
-	p.nextToken()
-
-	if p.currentTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON, token.END, token.RBRACE, token.EOF) {
 		p.nextToken()
-		return stmt
+		stmt.ReturnValue = p.parseExpression(LOWEST)
 	}
+	if p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+func (p *Parser) parseBreakStatement() ast.Statement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	if p.loopDepth == 0 {
+		msg := fmt.Errorf("Invalid break")
+		p.addError(msg)
+		return nil
+	}
+	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON, token.END, token.RBRACE, token.EOF) {
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+	if p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
 
-	if !p.acceptOneOf(token.NEWLINE, token.SEMICOLON) {
+func (p *Parser) parseNextStatement() ast.Statement {
+	stmt := &ast.NextStatement{Token: p.curToken}
+	if p.loopDepth == 0 {
+		msg := fmt.Errorf("Invalid next")
+		p.addError(msg)
 		return nil
 	}
+	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON, token.END, token.RBRACE, token.EOF) {
+		p.nextToken()
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+	if p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
 	return stmt
 }
 
+func (p *Parser) parseBeginBlock() ast.Statement {
+	block := &ast.BeginBlock{Token: p.curToken}
+	if p.blockDepth > 0 {
+		msg := fmt.Errorf("BEGIN is permitted only at toplevel")
+		p.addError(msg)
+		return nil
+	}
+	block.Body = p.parseBracedBlockStatement()
+	if block.Body == nil {
+		return nil
+	}
+	if p.peekTokenOneOf(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return block
+}
+
+func (p *Parser) parseEndBlock() ast.Statement {
+	block := &ast.EndBlock{Token: p.curToken}
+	block.Body = p.parseBracedBlockStatement()
+	if block.Body == nil {
+		return nil
+	}
+	if p.peekTokenOneOf(token.SEMICOLON, token.NEWLINE) {
+		p.nextToken()
+	}
+	return block
+}
+
+// parseBracedBlockStatement parses the statements between the next `{` and
+// its matching `}`
+func (p *Parser) parseBracedBlockStatement() *ast.BlockStatement {
+	if !p.accept(token.LBRACE) {
+		return nil
+	}
+	block := p.parseBlockStatement(token.RBRACE)
+	if !p.accept(token.RBRACE) {
+		return nil
+	}
+	return block
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
@@ -216,7 +517,7 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 
 func (p *Parser) noPrefixParseFnError(t token.Type) {
 	msg := fmt.Errorf("no prefix parse function for type %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
@@ -225,7 +526,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		p.noPrefixParseFnError(p.curToken.Type)
 		return nil
 	}
+	start := p.curToken
 	leftExp := prefix()
+	p.setSpan(leftExp, start)
 	for precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
@@ -233,15 +536,29 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 		}
 		p.nextToken()
 		leftExp = infix(leftExp)
+		p.setSpan(leftExp, start)
 	}
 	return leftExp
 }
 
 func (p *Parser) parseVariableAssignExpression(variable ast.Expression) ast.Expression {
+	if index, ok := variable.(*ast.IndexExpression); ok {
+		p.nextToken()
+		return &ast.IndexAssignment{Target: index, Value: p.parseExpression(LOWEST)}
+	}
+	if call, ok := variable.(*ast.ContextCallExpression); ok && call.Context != nil && len(call.Arguments) == 0 && call.Block == nil {
+		// `foo.bar = x` calls the setter method `bar=`
+		p.nextToken()
+		setter := *call.Function
+		setter.Value += "="
+		call.Function = &setter
+		call.Arguments = []ast.Expression{p.parseExpression(LOWEST)}
+		return call
+	}
 	ident, ok := variable.(*ast.Identifier)
 	if !ok {
 		msg := fmt.Errorf("could not parse variable assignment: expected identifier, got token '%T'", variable)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 	variableExp := &ast.VariableAssignment{
@@ -260,6 +577,21 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseIdentifierExpression parses an identifier, which is a method call
+// without arguments if a block follows it
+func (p *Parser) parseIdentifierExpression() ast.Expression {
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if !p.peekBlock() {
+		return ident
+	}
+	return &ast.ContextCallExpression{
+		Token:     ident.Token,
+		Function:  ident,
+		Arguments: []ast.Expression{},
+		Block:     p.parseCallBlock(),
+	}
+}
+
 func (p *Parser) parseSelf() ast.Expression {
 	self := &ast.Self{Token: p.curToken}
 	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON, token.DOT, token.EOF) {
@@ -271,10 +603,26 @@ func (p *Parser) parseSelf() ast.Expression {
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	literal, base := p.curToken.Literal, 0
+	if strings.HasPrefix(strings.ToLower(literal), "0d") {
+		literal, base = strings.Replace(literal[2:], "_", "", -1), 10
+	}
+	value, err := strconv.ParseInt(literal, base, 64)
 	if err != nil {
 		msg := fmt.Errorf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Errorf("could not parse %q as float", p.curToken.Literal)
+		p.addError(msg)
 		return nil
 	}
 	lit.Value = value
@@ -282,7 +630,50 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
-	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	value, err := unescape(p.curToken.Literal)
+	if err != nil {
+		msg := fmt.Errorf("could not parse string literal %q: %v", p.curToken.Literal, err)
+		p.addError(msg)
+		return nil
+	}
+	return &ast.StringLiteral{Token: p.curToken, Value: value, Frozen: p.frozenStringLiterals()}
+}
+
+// frozenStringLiterals reports whether the `frozen_string_literal` magic
+// comment is enabled for the current input
+func (p *Parser) frozenStringLiterals() bool {
+	value, ok := p.l.MagicComments()["frozen_string_literal"]
+	return ok && strings.EqualFold(value, "true")
+}
+
+func (p *Parser) parseRegexLiteral() ast.Expression {
+	literal := p.curToken.Literal
+	end := strings.LastIndex(literal, "/")
+	return &ast.RegexLiteral{
+		Token:   p.curToken,
+		Value:   literal[1:end],
+		Options: literal[end+1:],
+	}
+}
+
+func (p *Parser) parseCommandLiteral() ast.Expression {
+	value, err := unescape(p.curToken.Literal)
+	if err != nil {
+		msg := fmt.Errorf("could not parse command literal %q: %v", p.curToken.Literal, err)
+		p.addError(msg)
+		return nil
+	}
+	return &ast.CommandLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseCharacterLiteral() ast.Expression {
+	value, err := unescape(strings.TrimPrefix(p.curToken.Literal, "?"))
+	if err != nil {
+		msg := fmt.Errorf("could not parse %q as character literal: %v", p.curToken.Literal, err)
+		p.addError(msg)
+		return nil
+	}
+	return &ast.StringLiteral{Token: p.curToken, Value: value, Frozen: p.frozenStringLiterals()}
 }
 
 func (p *Parser) parseSymbolLiteral() ast.Expression {
@@ -298,6 +689,82 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	return array
 }
 
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	p.consumeNewlines()
+
+	if p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		return hash
+	}
+
+	p.nextToken()
+	if !p.parseHashPairs(hash, nil) {
+		return nil
+	}
+	p.consumeNewlines()
+	if !p.accept(token.RBRACE) {
+		return nil
+	}
+	return hash
+}
+
+// parseHashPairs parses comma separated key value pairs into hash. If key is
+// not nil it is used as the key of the first pair and the current token is
+// expected to be the last token of key.
+func (p *Parser) parseHashPairs(hash *ast.HashLiteral, key ast.Expression) bool {
+	for {
+		if !p.parseHashPair(hash, key) {
+			return false
+		}
+		key = nil
+		if !p.peekTokenIs(token.COMMA) {
+			return true
+		}
+		p.nextToken()
+		p.consumeNewlines()
+		if p.peekTokenIs(token.RBRACE) {
+			return true
+		}
+		p.nextToken()
+	}
+}
+
+func (p *Parser) parseHashPair(hash *ast.HashLiteral, key ast.Expression) bool {
+	if key == nil && p.isHashLabel() {
+		label := p.curToken
+		key = &ast.SymbolLiteral{Token: label, Value: label.Literal}
+		p.nextToken()
+		var value ast.Expression
+		if label.Type == token.IDENT && p.peekTokenOneOf(hashLabelTerminators...) {
+			value = &ast.Identifier{Token: label, Value: label.Literal}
+		} else {
+			p.nextToken()
+			value = p.parseExpression(LOWEST)
+		}
+		hash.Keys = append(hash.Keys, key)
+		hash.Values = append(hash.Values, value)
+		return true
+	}
+
+	if key == nil {
+		key = p.parseExpression(LOWEST)
+	}
+	if !p.accept(token.HASHROCKET) {
+		return false
+	}
+	p.nextToken()
+	hash.Keys = append(hash.Keys, key)
+	hash.Values = append(hash.Values, p.parseExpression(LOWEST))
+	return true
+}
+
+// isHashLabel returns true if the current token starts a `key:` style hash
+// key
+func (p *Parser) isHashLabel() bool {
+	return p.currentTokenOneOf(token.IDENT, token.STRING) && p.peekTokenIs(token.COLON)
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.currentTokenIs(token.TRUE)}
 }
@@ -319,7 +786,11 @@ func (p *Parser) parseRequireExpression() ast.Expression {
 	if !p.accept(token.STRING) {
 		return nil
 	}
-	expression.Name = p.parseStringLiteral().(*ast.StringLiteral)
+	name, ok := p.parseStringLiteral().(*ast.StringLiteral)
+	if !ok {
+		return nil
+	}
+	expression.Name = name
 	return expression
 }
 
@@ -330,16 +801,42 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 	precedence := p.curPrecedence()
+	if p.currentTokenIs(token.POW) {
+		// `**` is right associative
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 	return expression
 }
 
+func (p *Parser) parseRescueModifier(left ast.Expression) ast.Expression {
+	exp := &ast.RescueModifier{Token: p.curToken, Expression: left}
+	p.nextToken()
+	exp.Rescue = p.parseExpression(MODIFIER)
+	return exp
+}
+
+func (p *Parser) parseRangeLiteral(left ast.Expression) ast.Expression {
+	exp := &ast.RangeLiteral{
+		Token:     p.curToken,
+		Left:      left,
+		Exclusive: p.curToken.Type == token.DOT3,
+	}
+	p.nextToken()
+	exp.Right = p.parseExpression(RANGE)
+	return exp
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
 	p.nextToken()
 	exp.Index = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COMMA) {
+		p.consume(token.COMMA)
+		exp.Length = p.parseExpression(LOWEST)
+	}
 
 	if !p.accept(token.RBRACKET) {
 		return nil
@@ -362,8 +859,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	expression.Condition = p.parseExpression(LOWEST)
 	if p.peekTokenIs(token.THEN) {
 		p.accept(token.THEN)
-	}
-	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+	} else if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
 		msg := fmt.Sprintf(
 			"could not parse if expression: unexpected token %s: '%s'",
 			p.peekToken.Type,
@@ -376,21 +872,150 @@ func (p *Parser) parseIfExpression() ast.Expression {
 			},
 			msg,
 		)
-		p.errors = append(p.errors, err)
+		p.addError(err)
 		return nil
 	}
-	p.acceptOneOf(token.NEWLINE, token.SEMICOLON)
+	if p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
 	consequence := p.parseBlockStatement(token.ELSE)
 	expression.Consequence = consequence
 	if p.peekTokenIs(token.ELSE) {
 		p.accept(token.ELSE)
-		p.accept(token.NEWLINE)
 		expression.Alternative = p.parseBlockStatement()
 	}
 	p.accept(token.END)
 	return expression
 }
 
+func (p *Parser) parseCaseExpression() ast.Expression {
+	expression := &ast.CaseExpression{Token: p.curToken}
+	if !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+		expression.Subject = p.parseExpression(LOWEST)
+	}
+	for p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON) {
+		p.nextToken()
+	}
+	if !p.peekTokenIs(token.WHEN) {
+		p.peekError(token.WHEN)
+		return nil
+	}
+	for p.peekTokenIs(token.WHEN) {
+		p.nextToken()
+		when := p.parseWhenClause()
+		if when == nil {
+			return nil
+		}
+		expression.Whens = append(expression.Whens, when)
+	}
+	if p.peekTokenIs(token.ELSE) {
+		p.nextToken()
+		expression.Else = p.parseBlockStatement()
+	}
+	if !p.accept(token.END) {
+		return nil
+	}
+	return expression
+}
+
+func (p *Parser) parseWhenClause() *ast.WhenClause {
+	when := &ast.WhenClause{Token: p.curToken}
+	for {
+		p.consumeNewlines()
+		p.nextToken()
+		when.Conditions = append(when.Conditions, p.parseExpression(LOWEST))
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if !p.acceptOneOf(token.THEN, token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	when.Body = p.parseBlockStatement(token.WHEN, token.ELSE)
+	return when
+}
+
+func (p *Parser) parseBeginExpression() ast.Expression {
+	expression := &ast.BeginExpression{Token: p.curToken}
+	p.parseRescueBody(expression)
+	if !p.accept(token.END) {
+		return nil
+	}
+	return expression
+}
+
+// parseRescueBody parses the body of expression up to the closing end,
+// including its rescue and ensure clauses
+func (p *Parser) parseRescueBody(expression *ast.BeginExpression) {
+	expression.Body = p.parseBlockStatement(token.RESCUE, token.ENSURE)
+	for p.peekTokenIs(token.RESCUE) {
+		p.nextToken()
+		expression.Rescues = append(expression.Rescues, p.parseRescueBlock())
+	}
+	if p.peekTokenIs(token.ENSURE) {
+		p.nextToken()
+		expression.Ensure = p.parseBlockStatement()
+	}
+}
+
+// parseRescueBlock parses a rescue clause, starting at the rescue token. The
+// exception classes can be followed by `=> name` to bind the exception.
+func (p *Parser) parseRescueBlock() *ast.RescueBlock {
+	block := &ast.RescueBlock{Token: p.curToken}
+	for !p.peekTokenOneOf(token.NEWLINE, token.SEMICOLON, token.THEN, token.HASHROCKET) {
+		p.nextToken()
+		block.ExceptionClasses = append(block.ExceptionClasses, p.parseRescueClass())
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if p.peekTokenIs(token.HASHROCKET) {
+		p.nextToken()
+		if !p.accept(token.IDENT) {
+			return nil
+		}
+		block.Exception = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+	if p.peekTokenIs(token.THEN) {
+		p.nextToken()
+	}
+	block.Body = p.parseBlockStatement(token.RESCUE, token.ENSURE)
+	return block
+}
+
+// parseRescueClass parses a single exception class of a rescue clause, which
+// may be a splatted list of classes
+func (p *Parser) parseRescueClass() ast.Expression {
+	if !p.currentTokenIs(token.ASTERISK) {
+		return p.parseExpression(LOWEST)
+	}
+	splat := &ast.Splat{Token: p.curToken}
+	p.nextToken()
+	splat.Value = p.parseExpression(PREFIX)
+	return splat
+}
+
+func (p *Parser) parseWhileExpression() ast.Expression {
+	expression := &ast.WhileExpression{Token: p.curToken}
+	p.nextToken()
+	p.inCondition = true
+	expression.Condition = p.parseExpression(LOWEST)
+	p.inCondition = false
+	if !p.acceptOneOf(token.DO, token.NEWLINE, token.SEMICOLON) {
+		return nil
+	}
+	p.loopDepth++
+	expression.Body = p.parseBlockStatement()
+	p.loopDepth--
+	if !p.accept(token.END) {
+		return nil
+	}
+	return expression
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
@@ -404,13 +1029,71 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if !p.acceptOneOf(token.NEWLINE, token.SEMICOLON) {
 		return nil
 	}
-	lit.Body = p.parseBlockStatement()
+	// a method body can have rescue and ensure clauses without begin
+	body := &ast.BeginExpression{Token: p.curToken}
+	p.parseRescueBody(body)
+	lit.Body = body.Body
+	if len(body.Rescues) > 0 || body.Ensure != nil {
+		lit.Body = &ast.BlockStatement{
+			Token:      body.Token,
+			Statements: []ast.Statement{&ast.ExpressionStatement{Token: body.Token, Expression: body}},
+		}
+	}
 	if !p.accept(token.END) {
 		return nil
 	}
 	return lit
 }
 
+func (p *Parser) parseClassExpression() ast.Expression {
+	expression := &ast.ClassExpression{Token: p.curToken}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	expression.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.LT) {
+		p.nextToken()
+		p.nextToken()
+		expression.SuperClass = p.parseExpression(LOWEST)
+	}
+	expression.Body = p.parseBlockStatement()
+	if !p.accept(token.END) {
+		return nil
+	}
+	return expression
+}
+
+func (p *Parser) parseModuleExpression() ast.Expression {
+	expression := &ast.ModuleExpression{Token: p.curToken}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	expression.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	expression.Body = p.parseBlockStatement()
+	if !p.accept(token.END) {
+		return nil
+	}
+	return expression
+}
+
+// parseSuper parses `super` with or without arguments. Without any argument
+// list the arguments of the current method are passed on.
+func (p *Parser) parseSuper() ast.Expression {
+	super := &ast.Super{Token: p.curToken}
+	switch {
+	case p.peekTokenIs(token.LPAREN):
+		p.nextToken()
+		p.nextToken()
+		super.Arguments = p.parseExpressionList(token.RPAREN)
+	case p.peekStartsArgument():
+		p.nextToken()
+		super.Arguments = p.parseExpressions(MODIFIER)
+	default:
+		super.Implicit = true
+	}
+	return super
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	if p.peekTokenIs(token.LPAREN) {
 		p.accept(token.LPAREN)
@@ -457,12 +1140,20 @@ func (p *Parser) parseBlockStatement(t ...token.Type) *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
+	p.blockDepth++
+	defer func() { p.blockDepth-- }()
+
 	for !p.peekTokenOneOf(terminatorTokens...) {
 		p.nextToken()
+		errorCount := len(p.errors)
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
+		p.recoverStatement(errorCount, terminatorTokens...)
+	}
+	if len(block.Statements) != 0 {
+		ast.SetSpan(block, ast.Pos(block.Statements[0]), ast.End(block.Statements[len(block.Statements)-1]))
 	}
 
 	return block
@@ -474,7 +1165,8 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		return p.parseContextCallExpression(function)
 	}
 	exp := &ast.ContextCallExpression{Token: ident.Token, Function: ident}
-	exp.Arguments = p.parseExpressionList(token.SEMICOLON, token.NEWLINE)
+	exp.Arguments = p.parseCallArguments()
+	exp.Block = p.parseCallBlock()
 	return exp
 }
 
@@ -488,22 +1180,28 @@ func (p *Parser) parseContextCallExpression(context ast.Expression) ast.Expressi
 		p.nextToken()
 	}
 
-	function := p.parseExpression(CONTEXT)
+	var function ast.Expression
+	if p.currentTokenIs(token.IDENT) {
+		function = p.parseIdentifier()
+	} else {
+		function = p.parseExpression(CONTEXT)
+	}
 	ident, ok := function.(*ast.Identifier)
 	if !ok {
 		msg := fmt.Errorf(
 			"could not parse call expression: expected identifier, got token '%T'",
 			function,
 		)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 	contextCallExpression.Function = ident
 
 	args := []ast.Expression{}
 
-	if p.peekTokenOneOf(token.SEMICOLON, token.NEWLINE, token.DOT) {
+	if !p.peekStartsArgument() || p.peekBlock() {
 		contextCallExpression.Arguments = args
+		contextCallExpression.Block = p.parseCallBlock()
 		return contextCallExpression
 	}
 
@@ -511,40 +1209,139 @@ func (p *Parser) parseContextCallExpression(context ast.Expression) ast.Expressi
 		p.accept(token.LPAREN)
 		p.nextToken()
 		contextCallExpression.Arguments = p.parseExpressionList(token.RPAREN)
+		contextCallExpression.Block = p.parseCallBlock()
 		return contextCallExpression
 	}
 
 	p.nextToken()
-	contextCallExpression.Arguments = p.parseExpressionList(token.SEMICOLON, token.NEWLINE, token.EOF)
+	contextCallExpression.Arguments = p.parseCallArguments()
+	contextCallExpression.Block = p.parseCallBlock()
 	return contextCallExpression
 }
 
+// parseScopedExpression parses a constant looked up within scope, like
+// `Net::HTTP`, or a method called on scope, like `Math::sqrt(4)`
+func (p *Parser) parseScopedExpression(scope ast.Expression) ast.Expression {
+	scopeToken := p.curToken
+	if ident, ok := scope.(*ast.Identifier); ok {
+		spaceBefore := scopeToken.Pos > ident.Token.Pos+len(ident.Token.Literal)
+		spaceAfter := p.peekToken.Pos > scopeToken.Pos+len(scopeToken.Literal)
+		if spaceBefore && !spaceAfter {
+			// `puts ::String` passes the top level constant to puts
+			return p.parseCallExpression(ident)
+		}
+	}
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	if isConstantName(p.curToken.Literal) && !p.peekTokenIs(token.LPAREN) {
+		return &ast.ScopedConstant{Token: scopeToken, Scope: scope, Name: p.curToken.Literal}
+	}
+	return p.parseContextCallExpression(scope)
+}
+
+// isConstantName reports whether name starts with an uppercase letter
+func isConstantName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+// parseTopLevelConstant parses a constant of Object, like `::String`
+func (p *Parser) parseTopLevelConstant() ast.Expression {
+	scopeToken := p.curToken
+	if !p.accept(token.IDENT) {
+		return nil
+	}
+	return &ast.ScopedConstant{Token: scopeToken, Name: p.curToken.Literal}
+}
+
 func (p *Parser) parseCallExpressionWithParens(function ast.Expression) ast.Expression {
 	ident, ok := function.(*ast.Identifier)
 	if !ok {
 		msg := fmt.Errorf("could not parse call expression: expected identifier, got token '%T'", function)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 	exp := &ast.ContextCallExpression{Token: p.curToken, Function: ident}
 	p.nextToken()
 	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Block = p.parseCallBlock()
 	return exp
 }
 
-func (p *Parser) parseExpressionList(end ...token.Type) []ast.Expression {
-	list := []ast.Expression{}
-	if p.currentTokenOneOf(end...) {
-		return list
+// peekStartsArgument reports whether the next token starts the first
+// argument of a method call without parens. Operators, delimiters and
+// modifiers end the call instead. Like in Ruby, a bracket directly after the
+// method name indexes into the result, while one separated by whitespace
+// starts an Array argument.
+func (p *Parser) peekStartsArgument() bool {
+	switch p.peekToken.Type {
+	case token.MINUS, token.IF, token.WHILE:
+		return false
+	case token.LBRACKET:
+		return p.peekToken.Pos > p.curToken.Pos+len(p.curToken.Literal)
 	}
+	_, ok := p.prefixParseFns[p.peekToken.Type]
+	return ok
+}
 
-	list = append(list, p.parseExpression(LOWEST))
+// peekBlock reports whether the next token starts a block passed to the
+// current method call
+func (p *Parser) peekBlock() bool {
+	return p.peekTokenIs(token.LBRACE) || p.peekTokenIs(token.DO) && !p.inCondition
+}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.consume(token.COMMA)
-		list = append(list, p.parseExpression(LOWEST))
+// parseCallBlock parses the block following a method call. It returns nil
+// if there is none.
+func (p *Parser) parseCallBlock() *ast.BlockLiteral {
+	if !p.peekBlock() {
+		return nil
+	}
+	p.nextToken()
+	block := &ast.BlockLiteral{Token: p.curToken}
+	end := token.END
+	if p.currentTokenIs(token.LBRACE) {
+		end = token.RBRACE
+	}
+	if p.peekTokenIs(token.PIPE) {
+		p.nextToken()
+		block.Parameters = p.parseBlockParameters()
+	}
+	inCondition := p.inCondition
+	p.inCondition = false
+	p.loopDepth++
+	block.Body = p.parseBlockStatement(end)
+	p.loopDepth--
+	p.inCondition = inCondition
+	if !p.accept(end) {
+		return nil
+	}
+	return block
+}
+
+// parseBlockParameters parses the parameters of a block between pipes
+func (p *Parser) parseBlockParameters() []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+	for !p.peekTokenIs(token.PIPE) {
+		if !p.accept(token.IDENT) {
+			return nil
+		}
+		identifiers = append(identifiers, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		if !p.peekTokenIs(token.PIPE) && !p.accept(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+	return identifiers
+}
+
+func (p *Parser) parseExpressionList(end ...token.Type) []ast.Expression {
+	if p.currentTokenOneOf(end...) {
+		return []ast.Expression{}
 	}
 
+	list := p.parseExpressions(LOWEST)
+
 	if p.peekTokenOneOf(end...) {
 		p.acceptOneOf(end...)
 	}
@@ -552,6 +1349,51 @@ func (p *Parser) parseExpressionList(end ...token.Type) []ast.Expression {
 	return list
 }
 
+// parseCallArguments parses the arguments of a method call without parens.
+// Unlike parseExpressionList it leaves the terminating token to the caller,
+// as it terminates the statement as well. A rescue modifier following the
+// arguments applies to the whole call, like in `foo 1 rescue 2`.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	if p.currentTokenOneOf(token.SEMICOLON, token.NEWLINE, token.EOF) {
+		return []ast.Expression{}
+	}
+	return p.parseExpressions(MODIFIER)
+}
+
+// parseExpressions parses comma separated expressions, starting at the
+// current token, binding operators stronger than precedence. A trailing
+// hash can omit its braces.
+func (p *Parser) parseExpressions(precedence int) []ast.Expression {
+	list := []ast.Expression{}
+	for {
+		// a trailing hash argument can omit its braces
+		if p.isHashLabel() {
+			list = append(list, p.parseBracelessHash(nil))
+			break
+		}
+		exp := p.parseExpression(precedence)
+		if p.peekTokenIs(token.HASHROCKET) {
+			list = append(list, p.parseBracelessHash(exp))
+			break
+		}
+		list = append(list, exp)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.consume(token.COMMA)
+	}
+	return list
+}
+
+func (p *Parser) parseBracelessHash(key ast.Expression) ast.Expression {
+	hash := &ast.HashLiteral{Token: token.NewToken(token.LBRACE, "{", p.curToken.Pos)}
+	hash.Token.Line = p.curToken.Line
+	if !p.parseHashPairs(hash, key) {
+		return nil
+	}
+	return hash
+}
+
 func (p *Parser) peekPrecedence() int {
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
@@ -592,6 +1434,13 @@ func (p *Parser) peekTokenIs(t token.Type) bool {
 	return p.peekToken.Type == t
 }
 
+// consumeNewlines moves over all upcoming newline tokens
+func (p *Parser) consumeNewlines() {
+	for p.peekTokenIs(token.NEWLINE) {
+		p.nextToken()
+	}
+}
+
 // accept moves to the next Token
 // if it's from the valid set.
 func (p *Parser) accept(t token.Type) bool {
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/goruby/goruby/ast"
@@ -101,6 +103,45 @@ func TestReturnStatements(t *testing.T) {
 	}
 }
 
+func TestReturnStatementsInBlocks(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue interface{}
+	}{
+		{"lambda { return 1 }", 1},
+		{"[1].each { |x| return }", nil},
+		{"def foo; [1].each do |x| return x end; end", "x"},
+	}
+
+	for _, tt := range tests {
+		program, err := New(lexer.New(tt.input)).ParseProgram()
+		checkParserErrors(t, err)
+
+		var returnStmt *ast.ReturnStatement
+		ast.Inspect(program, func(node ast.Node) bool {
+			if stmt, ok := node.(*ast.ReturnStatement); ok {
+				returnStmt = stmt
+			}
+			return true
+		})
+		if returnStmt == nil {
+			t.Fatalf("Expected a return statement in %q", tt.input)
+		}
+		if tt.expectedValue == nil {
+			if returnStmt.ReturnValue != nil {
+				t.Errorf("Expected no return value, got %s", returnStmt.ReturnValue)
+			}
+			continue
+		}
+		testLiteralExpression(t, returnStmt.ReturnValue, tt.expectedValue)
+	}
+
+	_, err := New(lexer.New("[1].each { |x| return ) }")).ParseProgram()
+	if err == nil {
+		t.Errorf("Expected an error for an invalid return value")
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "foobar;"
 
@@ -202,6 +243,186 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralFormats(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1_000_000", 1000000},
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"0o755", 493},
+		{"0755", 493},
+		{"0b1010", 10},
+		{"0B1_010", 10},
+		{"0d123", 123},
+		{"0d0755", 755},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("expression.Value for %q not %d. got=%d", tt.input, tt.expected, literal.Value)
+		}
+	}
+
+	t.Run("invalid literals", func(t *testing.T) {
+		tests := []string{"08", "1__000", "1_", "0b", "0x_"}
+
+		for _, input := range tests {
+			l := lexer.New(input)
+			p := New(l)
+			_, err := p.ParseProgram()
+			if err == nil {
+				t.Logf("Expected %q to return a parser error", input)
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"1.5e10", 1.5e10},
+		{"2E-3", 2e-3},
+		{"1e+3", 1e3},
+		{"1_234.567_8", 1234.5678},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("expression.Value for %q not %f. got=%f", tt.input, tt.expected, literal.Value)
+		}
+	}
+
+	t.Run("method call on integer", func(t *testing.T) {
+		l := lexer.New("1.methods")
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		call, ok := stmt.Expression.(*ast.ContextCallExpression)
+		if !ok {
+			t.Fatalf("expression not *ast.ContextCallExpression. got=%T", stmt.Expression)
+		}
+		if call.Function.Value != "methods" {
+			t.Errorf("Expected function to be %q, got %q", "methods", call.Function.Value)
+		}
+	})
+}
+
+func TestStringLiteralEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"foo"`, "foo"},
+		{`"a\"b"`, `a"b`},
+		{`"tab\tnewline\n"`, "tab\tnewline\n"},
+		{`"\u00e9t\u00E9"`, "été"},
+		{`"\u{1F600}"`, "😀"},
+		{`"\u{48 69}"`, "Hi"},
+		{`"été"`, "été"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.StringLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value for %s not %q. got=%q", tt.input, tt.expected, literal.Value)
+		}
+	}
+
+	t.Run("invalid escapes", func(t *testing.T) {
+		l := lexer.New(`"\u{110000}"`)
+		p := New(l)
+		_, err := p.ParseProgram()
+		if err == nil {
+			t.Errorf("Expected a parser error")
+		}
+	})
+}
+
+func TestCharacterLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"?a", "a"},
+		{"?\\n", "\n"},
+		{"?\\s", " "},
+		{"?\\u00e9", "é"},
+		{"?\\u{1F600}", "😀"},
+		{"?\\x41", "A"},
+		{"?\\101", "A"},
+		{"?\\\\", "\\"},
+		{"?é", "é"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.StringLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value for %q not %q. got=%q", tt.input, tt.expected, literal.Value)
+		}
+	}
+
+	t.Run("invalid literals", func(t *testing.T) {
+		tests := []string{"?ab", "? ", "?", "?\\u00", "?\\u{110000}"}
+
+		for _, input := range tests {
+			l := lexer.New(input)
+			p := New(l)
+			_, err := p.ParseProgram()
+			if err == nil {
+				t.Logf("Expected %q to return a parser error", input)
+				t.Fail()
+			}
+		}
+	})
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -269,6 +490,7 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 <=> 5;", 5, "<=>", 5},
 		{"foobar + barfoo;", "foobar", "+", "barfoo"},
 		{"foobar - barfoo;", "foobar", "-", "barfoo"},
 		{"foobar * barfoo;", "foobar", "*", "barfoo"},
@@ -277,6 +499,13 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"foobar < barfoo;", "foobar", "<", "barfoo"},
 		{"foobar == barfoo;", "foobar", "==", "barfoo"},
 		{"foobar != barfoo;", "foobar", "!=", "barfoo"},
+		{"foobar <=> barfoo;", "foobar", "<=>", "barfoo"},
+		{"foobar << barfoo;", "foobar", "<<", "barfoo"},
+		{"foobar =~ barfoo;", "foobar", "=~", "barfoo"},
+		{"foobar !~ barfoo;", "foobar", "!~", "barfoo"},
+		{"foobar % barfoo;", "foobar", "%", "barfoo"},
+		{"foobar ** barfoo;", "foobar", "**", "barfoo"},
+		{"foobar >> barfoo;", "foobar", ">>", "barfoo"},
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
@@ -320,6 +549,74 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-a * b",
 			"((-a) * b)",
 		},
+		{
+			"a + b <=> c * d",
+			"((a + b) <=> (c * d))",
+		},
+		{
+			"a + 1..b - 1",
+			"((a + 1)..(b - 1))",
+		},
+		{
+			"x = 0...y.size",
+			"x = (0...y.size())",
+		},
+		{
+			"s[1..-1]",
+			"(s[(1..(-1))])",
+		},
+		{
+			"x.size + 1",
+			"(x.size() + 1)",
+		},
+		{
+			"x.size - y.size",
+			"(x.size() - y.size())",
+		},
+		{
+			"x.chars[0]",
+			"(x.chars()[0])",
+		},
+		{
+			"x.push [0]",
+			"x.push([0])",
+		},
+		{
+			"a[1, 2 + 3]",
+			"(a[1, (2 + 3)])",
+		},
+		{
+			"-a ** b ** c",
+			"(-(a ** (b ** c)))",
+		},
+		{
+			"a % b * c",
+			"((a % b) * c)",
+		},
+		{
+			"a | b & c ^ ~d",
+			"((a | (b & c)) ^ (~d))",
+		},
+		{
+			"a & b << 1 >> c + 1",
+			"(a & ((b << 1) >> (c + 1)))",
+		},
+		{
+			"a[1..2] = b + 1",
+			"(a[(1..2)]) = (b + 1)",
+		},
+		{
+			"h.default = 1 + 2",
+			"h.default=((1 + 2))",
+		},
+		{
+			"a < b <=> c",
+			"((a < b) <=> c)",
+		},
+		{
+			"a << b + c < d",
+			"((a << (b + c)) < d)",
+		},
 		{
 			"!-a",
 			"(!(-a))",
@@ -452,6 +749,18 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a + b rescue c",
+			"((a + b) rescue c)",
+		},
+		{
+			"a = b rescue c * d",
+			"a = (b rescue (c * d))",
+		},
+		{
+			"a rescue b rescue c",
+			"((a rescue b) rescue c)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -978,6 +1287,21 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 			expectedIdent: "add",
 			expectedArgs:  []string{"1"},
 		},
+		{
+			input:         "sleep 0.01;",
+			expectedIdent: "sleep",
+			expectedArgs:  []string{"0.01"},
+		},
+		{
+			input:         "p 1.5, 2;",
+			expectedIdent: "p",
+			expectedArgs:  []string{"1.5", "2"},
+		},
+		{
+			input:         "p ?a, ?b;",
+			expectedIdent: "p",
+			expectedArgs:  []string{"?a", "?b"},
+		},
 		{
 			input:         `add "foo";`,
 			expectedIdent: "add",
@@ -988,6 +1312,41 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 			expectedIdent: "add",
 			expectedArgs:  []string{":foo"},
 		},
+		{
+			input:         `exit false;`,
+			expectedIdent: "exit",
+			expectedArgs:  []string{"false"},
+		},
+		{
+			input:         `add nil, true;`,
+			expectedIdent: "add",
+			expectedArgs:  []string{"nil", "true"},
+		},
+		{
+			input:         `private def foo; 2; end;`,
+			expectedIdent: "private",
+			expectedArgs:  []string{"def foo() 2 end"},
+		},
+		{
+			input:         `log(level: :info);`,
+			expectedIdent: "log",
+			expectedArgs:  []string{"{:level => :info}"},
+		},
+		{
+			input:         `log "foo", level: :info, verbose: true;`,
+			expectedIdent: "log",
+			expectedArgs:  []string{"foo", "{:level => :info, :verbose => true}"},
+		},
+		{
+			input:         `log(1, :level => 2);`,
+			expectedIdent: "log",
+			expectedArgs:  []string{"1", "{:level => 2}"},
+		},
+		{
+			input:         `log(level:, verbose:);`,
+			expectedIdent: "log",
+			expectedArgs:  []string{"{:level => level, :verbose => verbose}"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1558,6 +1917,69 @@ func TestParsingArrayLiterals(t *testing.T) {
 	testInfixExpression(t, array.Elements[2], 3, "+", 3)
 }
 
+func TestParsingHashLiterals(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedKeys   []string
+		expectedValues []string
+	}{
+		{
+			`{}`,
+			[]string{},
+			[]string{},
+		},
+		{
+			`{"foo" => 1, :bar => 2 * 3}`,
+			[]string{"foo", ":bar"},
+			[]string{"1", "(2 * 3)"},
+		},
+		{
+			`{foo: 1, "bar": :baz}`,
+			[]string{":foo", ":bar"},
+			[]string{"1", ":baz"},
+		},
+		{
+			`{name:, age:}`,
+			[]string{":name", ":age"},
+			[]string{"name", "age"},
+		},
+		{
+			"{\n\tfoo: 1,\n\tbar => 2,\n}",
+			[]string{":foo", "bar"},
+			[]string{"1", "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+		}
+
+		keys := []string{}
+		for _, k := range hash.Keys {
+			keys = append(keys, k.String())
+		}
+		values := []string{}
+		for _, v := range hash.Values {
+			values = append(values, v.String())
+		}
+
+		if !reflect.DeepEqual(tt.expectedKeys, keys) {
+			t.Errorf("Expected keys to equal %q, got %q", tt.expectedKeys, keys)
+		}
+		if !reflect.DeepEqual(tt.expectedValues, values) {
+			t.Errorf("Expected values to equal %q, got %q", tt.expectedValues, values)
+		}
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1 + 1]"
 	l := lexer.New(input)
@@ -1613,6 +2035,363 @@ func TestRequireExpression(t *testing.T) {
 	}
 }
 
+func TestBeginAndEndBlocks(t *testing.T) {
+	input := "x = 1\nBEGIN { puts(1); x = 2 }\nEND {\n\tputs x\n}\n"
+	l := lexer.New(input)
+	p := New(l)
+	program, err := p.ParseProgram()
+	checkParserErrors(t, err)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf(
+			"program.Statements does not contain 3 statements. got=%d",
+			len(program.Statements),
+		)
+	}
+
+	beginBlock, ok := program.Statements[1].(*ast.BeginBlock)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not *ast.BeginBlock. got=%T", program.Statements[1])
+	}
+	if len(beginBlock.Body.Statements) != 2 {
+		t.Fatalf("BEGIN block does not contain 2 statements. got=%d", len(beginBlock.Body.Statements))
+	}
+
+	endBlock, ok := program.Statements[2].(*ast.EndBlock)
+	if !ok {
+		t.Fatalf("program.Statements[2] is not *ast.EndBlock. got=%T", program.Statements[2])
+	}
+	if endBlock.Body.String() != "puts(x)" {
+		t.Fatalf("END block body is not %q. got=%q", "puts(x)", endBlock.Body.String())
+	}
+}
+
+func TestScopedConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Net::HTTP", "Net::HTTP"},
+		{"A::B::C", "A::B::C"},
+		{"::String", "::String"},
+		{"Net::HTTP.get(url)", "Net::HTTP.get(url)"},
+		{"Math::sqrt(4)", "Math.sqrt(4)"},
+		{"puts ::String", "puts(::String)"},
+		{"x = Net::HTTP", "x = (Net::HTTP)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected %q to parse as %q, got %q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program, err := New(lexer.New("Net::HTTP")).ParseProgram()
+	checkParserErrors(t, err)
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	scoped, ok := stmt.Expression.(*ast.ScopedConstant)
+	if !ok {
+		t.Fatalf("expression is not *ast.ScopedConstant. got=%T", stmt.Expression)
+	}
+	if scoped.Name != "HTTP" || scoped.Scope.String() != "Net" {
+		t.Errorf("expected HTTP within Net, got %s within %s", scoped.Name, scoped.Scope)
+	}
+}
+
+func TestCommandLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"`ls -l`", "ls -l"},
+		{"%x(echo \\t)", "echo \t"},
+		{"puts `date`", "date"},
+	}
+
+	for _, tt := range tests {
+		program, err := New(lexer.New(tt.input)).ParseProgram()
+		checkParserErrors(t, err)
+
+		var command *ast.CommandLiteral
+		switch exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(type) {
+		case *ast.CommandLiteral:
+			command = exp
+		case *ast.ContextCallExpression:
+			command, _ = exp.Arguments[0].(*ast.CommandLiteral)
+		}
+		if command == nil {
+			t.Errorf("expected %q to contain a command literal, got %s", tt.input, program)
+			continue
+		}
+		if command.Value != tt.expected {
+			t.Errorf("expected command %q, got %q", tt.expected, command.Value)
+		}
+	}
+}
+
+func TestBeginBlockOnlyAtToplevel(t *testing.T) {
+	input := "def foo\nBEGIN { 1 }\nend\n"
+	l := lexer.New(input)
+	p := New(l)
+	_, err := p.ParseProgram()
+
+	if err == nil {
+		t.Fatalf("Expected parse error, got nil")
+	}
+}
+
+func TestProgramMagicComments(t *testing.T) {
+	input := "# frozen_string_literal: true\n=begin\nsome docs\n=end\nx = 5 # comment\n"
+	l := lexer.New(input)
+	p := New(l)
+	program, err := p.ParseProgram()
+	checkParserErrors(t, err)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf(
+			"program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements),
+		)
+	}
+
+	value, ok := program.MagicComment("frozen_string_literal")
+	if !ok {
+		t.Fatalf("Expected program to have magic comment frozen_string_literal")
+	}
+	if value != "true" {
+		t.Fatalf("Expected magic comment value to equal %q, got %q", "true", value)
+	}
+}
+
+func TestProgramComments(t *testing.T) {
+	input := "# frozen_string_literal: true\n=begin\nsome docs\n=end\nx = 5 # comment\n"
+	program, err := New(lexer.New(input)).ParseProgram()
+	checkParserErrors(t, err)
+
+	expected := []ast.Comment{
+		{Pos: ast.Position{Offset: 0, Line: 1, Column: 1}, Text: "# frozen_string_literal: true"},
+		{Pos: ast.Position{Offset: 30, Line: 2, Column: 1}, Text: "=begin\nsome docs\n=end"},
+		{Pos: ast.Position{Offset: 58, Line: 5, Column: 7}, Text: "# comment"},
+	}
+	if len(program.Comments) != len(expected) {
+		t.Fatalf("Expected %d comments, got %d", len(expected), len(program.Comments))
+	}
+	for i, comment := range program.Comments {
+		if *comment != expected[i] {
+			t.Errorf("Expected comment %+v, got %+v", expected[i], *comment)
+		}
+	}
+	comments := ast.NewCommentMap(program)
+	if attached := comments[program.Statements[0]]; len(attached) != 3 {
+		t.Errorf("Expected the leading and trailing comments to be attached to the statement, got %d", len(attached))
+	}
+}
+
+func TestStatementSeparators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"puts 1; x = 2", []string{"puts(1)", "x = 2"}},
+		{"x = 1;; y = 2;", []string{"x = 1", "y = 2"}},
+		{"foo.bar 1, 2; baz", []string{"foo.bar(1, 2)", "baz"}},
+		{"x = 1 + \\\n 2", []string{"x = (1 + 2)"}},
+		{"foo\n  .bar(1)\n  .baz\nqux", []string{"foo.bar(1).baz()", "qux"}},
+		{"def foo; puts 1; puts 2; end", []string{"def foo() puts(1)puts(2) end"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		var actual []string
+		for _, stmt := range program.Statements {
+			actual = append(actual, stmt.String())
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("Expected statements of %q to equal %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestControlStructureExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = if a then 1 else 2 end", "x = (ifa 1else 2 end)"},
+		{"if a then\n1\nelse 2 end", "ifa 1else 2 end"},
+		{"case x\nwhen 1, 2 then :a\nwhen 3\n:b\nelse :c\nend", "case x when 1, 2 then :a when 3 then :b else :c end"},
+		{"case; when a; 1; end", "case when a then 1 end"},
+		{"x = begin\n1\n2\nend", "x = (begin 12 end)"},
+		{"begin\nfoo\nrescue ArgumentError\n1\nrescue\n2\nend", "begin foo rescue ArgumentError 1 rescue 2 end"},
+		{"begin; foo rescue bar; rescue; 2; end", "begin (foo rescue bar) rescue 2 end"},
+		{"foo 1 rescue 2", "(foo(1) rescue 2)"},
+		{"foo 1, bar 2 rescue 3", "(foo(1, bar(2)) rescue 3)"},
+		{"foo(1 rescue 2)", "foo((1 rescue 2))"},
+		{"begin\nfoo\nrescue A, B => e\ne\nend", "begin foo rescue A, B => e e end"},
+		{"begin; foo; rescue *errors, C; 1; end", "begin foo rescue *errors, C 1 end"},
+		{"begin; foo; rescue => e then e; end", "begin foo rescue => e e end"},
+		{"begin\nfoo\nrescue\n1\nensure\n2\nend", "begin foo rescue 1 ensure 2 end"},
+		{"def a\nfoo\nrescue\n1\nensure\n2\nend", "def a() begin foo rescue 1 ensure 2 end end"},
+		{"def a\nfoo\nend", "def a() foo end"},
+		{"while true; next; end", "while true do next end"},
+		{"while true; next 1; end", "while true do next 1 end"},
+		{"case x\nwhen 1,\n  2 then :a\nend", "case x when 1, 2 then :a end"},
+		{"while a < 3 do\na = a + 1\nend", "while (a < 3) do a = (a + 1) end"},
+		{"while true; break 5; end", "while true do break 5 end"},
+		{"while true\nif a then break end\nend", "while true do ifa break end end"},
+		{"class Foo < Bar\ndef a\nsuper\nend\nend", "class Foo < Bar def a() super end end"},
+		{"class Foo; end", "class Foo  end"},
+		{"module Foo\nx\nend", "module Foo x end"},
+		{"def a(x)\nsuper(x, 1)\nend", "def a(x) super(x, 1) end"},
+		{"def a(x)\nsuper x\nend", "def a(x) super(x) end"},
+		{"def a\nsuper()\nend", "def a() super() end"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf(
+				"program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements),
+			)
+		}
+
+		actual := program.Statements[0].String()
+		if actual != tt.expected {
+			t.Errorf("Expected %q to parse to %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestBlockLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x.each { |a| a }", "x.each() { |a| a }"},
+		{"x.each do |a, b|\na\nend", "x.each() { |a, b| a }"},
+		{"x.each { }", "x.each() {  }"},
+		{"x.map(1) { || 2 }", "x.map(1) { 2 }"},
+		{"x.gsub /a/ do |m| m end", "x.gsub(/a/) { |m| m }"},
+		{"foo(1) { |a| a }", "foo(1) { |a| a }"},
+		{"proc { |a| a }", "proc() { |a| a }"},
+		{"lambda do\n1\nend", "lambda() { 1 }"},
+		{"x.each { break }", "x.each() { break }"},
+		{"while x.empty? do\nbreak\nend", "while x.empty?() do break end"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf(
+				"program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements),
+			)
+		}
+
+		actual := program.Statements[0].String()
+		if actual != tt.expected {
+			t.Errorf("Expected %q to parse to %q, got %q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestRegexLiterals(t *testing.T) {
+	tests := []struct {
+		input   string
+		value   string
+		options string
+	}{
+		{"/abc/", "abc", ""},
+		{`/a\/b/`, `a\/b`, ""},
+		{"/a.c/mix", "a.c", "mix"},
+		{"x = /a/i", "a", "i"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, err := p.ParseProgram()
+		checkParserErrors(t, err)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		expression := stmt.Expression
+		if assignment, ok := expression.(*ast.VariableAssignment); ok {
+			expression = assignment.Value
+		}
+		literal, ok := expression.(*ast.RegexLiteral)
+		if !ok {
+			t.Fatalf("expression not *ast.RegexLiteral. got=%T", expression)
+		}
+		if literal.Value != tt.value {
+			t.Errorf("literal.Value for %q not %q. got=%q", tt.input, tt.value, literal.Value)
+		}
+		if literal.Options != tt.options {
+			t.Errorf("literal.Options for %q not %q. got=%q", tt.input, tt.options, literal.Options)
+		}
+	}
+}
+
+func TestInvalidBreak(t *testing.T) {
+	tests := []string{
+		"break",
+		"if true; break; end",
+		"case 1; when 2; break 3; end",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		_, err := p.ParseProgram()
+		if err == nil {
+			t.Errorf("Expected %q to return a parser error", input)
+		}
+	}
+}
+
+func TestProgramData(t *testing.T) {
+	input := "x = 5\n__END__\nx = 3 +\n"
+	l := lexer.New(input)
+	p := New(l)
+	program, err := p.ParseProgram()
+	checkParserErrors(t, err)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf(
+			"program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements),
+		)
+	}
+
+	if !program.HasData {
+		t.Fatalf("Expected program to have data")
+	}
+	if program.Data != "x = 3 +\n" {
+		t.Fatalf("Expected program data to equal %q, got %q", "x = 3 +\n", program.Data)
+	}
+}
+
 func testVariableExpression(t *testing.T, e ast.Expression, name string) bool {
 	variable, ok := e.(*ast.VariableAssignment)
 	if !ok {
@@ -1777,3 +2556,98 @@ func checkParserErrors(t *testing.T, err error) {
 	t.Errorf("parser error: %s", err.Error())
 	t.FailNow()
 }
+
+func TestNodeSpans(t *testing.T) {
+	input := "x = foo(1, 2) + 3\ndef bar(a)\n  a * \"ä\"\nend\n"
+	program, err := New(lexer.New(input)).ParseProgram()
+	checkParserErrors(t, err)
+
+	assignment := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.VariableAssignment)
+	sum := assignment.Value.(*ast.InfixExpression)
+	function := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	product := function.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+	tests := []struct {
+		node       ast.Node
+		start, end ast.Position
+	}{
+		{program.Statements[0], ast.Position{Offset: 0, Line: 1, Column: 1}, ast.Position{Offset: 17, Line: 1, Column: 18}},
+		{sum, ast.Position{Offset: 4, Line: 1, Column: 5}, ast.Position{Offset: 17, Line: 1, Column: 18}},
+		{sum.Left, ast.Position{Offset: 4, Line: 1, Column: 5}, ast.Position{Offset: 13, Line: 1, Column: 14}},
+		{function, ast.Position{Offset: 18, Line: 2, Column: 1}, ast.Position{Offset: 43, Line: 4, Column: 4}},
+		{function.Body, ast.Position{Offset: 31, Line: 3, Column: 3}, ast.Position{Offset: 38, Line: 3, Column: 9}},
+		{product.Right, ast.Position{Offset: 36, Line: 3, Column: 8}, ast.Position{Offset: 38, Line: 3, Column: 9}},
+	}
+
+	for _, tt := range tests {
+		if start, end := ast.Pos(tt.node), ast.End(tt.node); start != tt.start || end != tt.end {
+			t.Errorf("Expected %s to span %+v to %+v, got %+v to %+v", tt.node, tt.start, tt.end, start, end)
+		}
+	}
+}
+
+func TestUnparseRoundTrip(t *testing.T) {
+	input := `# frozen_string_literal: true
+require "set"
+
+# A greeter
+class Greeter < Object
+  def greet(name, times)
+    i = 0 # counter
+    while i < times
+      puts("Hi \#{name}\t\"x\"")
+      i = i + 1
+    end
+    [1, 2.5, :sym, nil, true].each { |x| p(x) }
+    h = {:a => 1, "b" => [1, 2], c: 3}
+    h[:a] = -(1 + 2) * 3 ** 2
+    x = if i > 2
+      "big"
+    else
+      "small"
+    end
+    case x
+    when "big", "huge"
+      puts(1..i)
+    else
+      Foo::Bar.baz(x) do |a|
+        a
+      end
+    end
+    self.name = x rescue nil
+  end
+
+  def risky
+    1 / 0
+  rescue ZeroDivisionError => e
+    0
+  ensure
+    super
+  end
+end
+__END__
+data
+`
+	program, err := New(lexer.New(input)).ParseProgram()
+	checkParserErrors(t, err)
+
+	source := ast.Unparse(program)
+	unparsed, err := New(lexer.New(source)).ParseProgram()
+	checkParserErrors(t, err)
+
+	if dumpWithoutPositions(unparsed) != dumpWithoutPositions(program) {
+		t.Errorf("Expected\n%s\nto parse like\n%s", source, input)
+	}
+	if again := ast.Unparse(unparsed); again != source {
+		t.Errorf("Expected unparsing to be stable, got\n%s\nthen\n%s", source, again)
+	}
+}
+
+var positionsDumped = regexp.MustCompile(` \(line \d+\)| \[\d+:\d+-\d+:\d+\]`)
+
+// dumpWithoutPositions returns the tree of program as written by ast.Fprint
+// without the positions of the nodes
+func dumpWithoutPositions(program *ast.Program) string {
+	var out bytes.Buffer
+	ast.Fprint(&out, program)
+	return positionsDumped.ReplaceAllString(out.String(), "")
+}
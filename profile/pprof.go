@@ -0,0 +1,175 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// WritePprof writes the profile in the gzipped protocol buffer format read
+// by pprof, with the sample count and the time spent as values of each
+// sample.
+func (p *Profile) WritePprof(w io.Writer) error {
+	e := &pprofEncoder{strings: map[string]int64{"": 0}, stringTable: []string{""}}
+	e.encode(p)
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(e.out); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// The field numbers of the messages of profile.proto
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+)
+
+// pprofName replaces the angle brackets of labels like <main>, which pprof
+// would strip like the template arguments of C++ functions
+var pprofName = strings.NewReplacer("<", "[", ">", "]")
+
+// pprofEncoder encodes a Profile as protocol buffer message
+type pprofEncoder struct {
+	out         []byte
+	strings     map[string]int64
+	stringTable []string
+	functions   map[string]uint64
+	locations   map[Frame]uint64
+}
+
+func (e *pprofEncoder) encode(p *Profile) {
+	e.functions = make(map[string]uint64)
+	e.locations = make(map[Frame]uint64)
+	e.message(profileSampleType, e.valueType("samples", "count"))
+	e.message(profileSampleType, e.valueType("cpu", "nanoseconds"))
+	var functions, locations [][]byte
+	for _, s := range p.order {
+		ids := make([]uint64, len(s.stack))
+		for i, frame := range s.stack {
+			function, ok := e.functions[frame.Function]
+			if !ok {
+				function = uint64(len(e.functions) + 1)
+				e.functions[frame.Function] = function
+				var f []byte
+				f = appendVarintField(f, functionID, function)
+				f = appendVarintField(f, functionName, uint64(e.string(pprofName.Replace(frame.Function))))
+				f = appendVarintField(f, functionSystemName, uint64(e.string(frame.Function)))
+				f = appendVarintField(f, functionFilename, uint64(e.string(frame.File)))
+				functions = append(functions, f)
+			}
+			location, ok := e.locations[frame]
+			if !ok {
+				location = uint64(len(e.locations) + 1)
+				e.locations[frame] = location
+				var line []byte
+				line = appendVarintField(line, lineFunctionID, function)
+				line = appendVarintField(line, lineLine, uint64(frame.Line))
+				var l []byte
+				l = appendVarintField(l, locationID, location)
+				l = appendBytesField(l, locationLine, line)
+				locations = append(locations, l)
+			}
+			// pprof lists the innermost location first
+			ids[len(s.stack)-1-i] = location
+		}
+		var sample []byte
+		sample = appendPackedField(sample, sampleLocationID, ids)
+		sample = appendPackedField(sample, sampleValue, []uint64{uint64(s.count), uint64(s.count * int64(p.Interval))})
+		e.message(profileSample, sample)
+	}
+	for _, l := range locations {
+		e.message(profileLocation, l)
+	}
+	for _, f := range functions {
+		e.message(profileFunction, f)
+	}
+	e.out = appendVarintField(e.out, profileTimeNanos, uint64(p.Start.UnixNano()))
+	e.out = appendVarintField(e.out, profileDurationNanos, uint64(p.Duration))
+	e.message(profilePeriodType, e.valueType("cpu", "nanoseconds"))
+	e.out = appendVarintField(e.out, profilePeriod, uint64(p.Interval))
+	// the string table comes last, as encoding the other messages adds to
+	// it
+	for _, s := range e.stringTable {
+		e.out = appendBytesField(e.out, profileStringTable, []byte(s))
+	}
+}
+
+func (e *pprofEncoder) message(field int, message []byte) {
+	e.out = appendBytesField(e.out, field, message)
+}
+
+func (e *pprofEncoder) valueType(typ, unit string) []byte {
+	var v []byte
+	v = appendVarintField(v, valueTypeType, uint64(e.string(typ)))
+	return appendVarintField(v, valueTypeUnit, uint64(e.string(unit)))
+}
+
+// string returns the index of s within the string table, adding it if
+// necessary
+func (e *pprofEncoder) string(s string) int64 {
+	index, ok := e.strings[s]
+	if !ok {
+		index = int64(len(e.stringTable))
+		e.strings[s] = index
+		e.stringTable = append(e.stringTable, s)
+	}
+	return index
+}
+
+// The wire types of protocol buffer fields
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	b = appendVarint(b, uint64(field)<<3|wireVarint)
+	return appendVarint(b, v)
+}
+
+func appendBytesField(b []byte, field int, data []byte) []byte {
+	b = appendVarint(b, uint64(field)<<3|wireBytes)
+	b = appendVarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendPackedField(b []byte, field int, values []uint64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = appendVarint(packed, v)
+	}
+	return appendBytesField(b, field, packed)
+}
@@ -0,0 +1,197 @@
+// Package profile aggregates samples of the call stack of a Ruby program
+// and reports where the program spends its time, either as text or in the
+// format of pprof.
+package profile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultInterval is the interval between two samples unless configured
+// otherwise
+const DefaultInterval = time.Millisecond
+
+// A Frame is a frame of a sampled call stack
+type Frame struct {
+	// Function is the label of the frame, like the name of a method or
+	// `block in foo`
+	Function string
+	File     string
+	Line     int
+}
+
+// A Profile aggregates samples of the call stack taken at a fixed
+// interval. A sample counts as spending the interval within every function
+// of its stack, and within the innermost one itself.
+type Profile struct {
+	Interval time.Duration
+	// Start is the time the profile has been started at and Duration the
+	// time it has been recording
+	Start    time.Time
+	Duration time.Duration
+	samples  map[string]*sample
+	order    []*sample
+	total    int64
+}
+
+type sample struct {
+	stack []Frame
+	count int64
+}
+
+// New returns an empty Profile for samples taken every interval
+func New(interval time.Duration) *Profile {
+	return &Profile{Interval: interval, Start: time.Now(), samples: make(map[string]*sample)}
+}
+
+// Add records count samples of stack, whose outermost frame comes first
+func (p *Profile) Add(stack []Frame, count int64) {
+	if count <= 0 || len(stack) == 0 {
+		return
+	}
+	var key strings.Builder
+	for _, frame := range stack {
+		fmt.Fprintf(&key, "%s\x00%s\x00%d\x00", frame.Function, frame.File, frame.Line)
+	}
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &sample{stack: append([]Frame(nil), stack...)}
+		p.samples[key.String()] = s
+		p.order = append(p.order, s)
+	}
+	s.count += count
+	p.total += count
+}
+
+// Samples returns the number of samples recorded
+func (p *Profile) Samples() int64 {
+	return p.total
+}
+
+// A FunctionStats holds the samples spent within a function
+type FunctionStats struct {
+	Function string
+	// Self counts the samples the function was the innermost frame of and
+	// Total the samples it was part of the stack of
+	Self, Total int64
+	// Callers and Callees count the samples by the functions calling the
+	// function and called by it
+	Callers, Callees map[string]int64
+}
+
+// Functions returns the statistics of every function sampled, ordered by
+// their Self samples, then by their Total samples and their name.
+func (p *Profile) Functions() []*FunctionStats {
+	stats := make(map[string]*FunctionStats)
+	get := func(name string) *FunctionStats {
+		s, ok := stats[name]
+		if !ok {
+			s = &FunctionStats{Function: name, Callers: make(map[string]int64), Callees: make(map[string]int64)}
+			stats[name] = s
+		}
+		return s
+	}
+	for _, sample := range p.order {
+		seen := make(map[string]bool)
+		edges := make(map[[2]string]bool)
+		for i, frame := range sample.stack {
+			s := get(frame.Function)
+			if !seen[frame.Function] {
+				seen[frame.Function] = true
+				s.Total += sample.count
+			}
+			if i > 0 {
+				caller := sample.stack[i-1].Function
+				if edge := [2]string{caller, frame.Function}; !edges[edge] {
+					edges[edge] = true
+					s.Callers[caller] += sample.count
+					get(caller).Callees[frame.Function] += sample.count
+				}
+			}
+		}
+		get(sample.stack[len(sample.stack)-1].Function).Self += sample.count
+	}
+	functions := make([]*FunctionStats, 0, len(stats))
+	for _, s := range stats {
+		functions = append(functions, s)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		a, b := functions[i], functions[j]
+		if a.Self != b.Self {
+			return a.Self > b.Self
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Function < b.Function
+	})
+	return functions
+}
+
+// WriteReport writes the flat profile, listing the time spent within each
+// function, followed by the call graph, listing the time each function
+// spent by its callers and callees.
+func (p *Profile) WriteReport(w io.Writer) error {
+	functions := p.Functions()
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Flat profile: %d samples every %s, %s\n", p.total, p.Interval, p.duration(p.total))
+	fmt.Fprintf(tw, "self%%\tself\ttotal%%\ttotal\t\tfunction\n")
+	for _, f := range functions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\t%s\n", p.percent(f.Self), p.duration(f.Self), p.percent(f.Total), p.duration(f.Total), f.Function)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		return functions[i].Total > functions[j].Total
+	})
+	var out strings.Builder
+	out.WriteString("\nCall graph:\n")
+	for _, f := range functions {
+		fmt.Fprintf(&out, "\n%s: total %s (%s), self %s (%s)\n", f.Function, p.duration(f.Total), p.percent(f.Total), p.duration(f.Self), p.percent(f.Self))
+		for _, edge := range sortedEdges(f.Callers) {
+			fmt.Fprintf(&out, "  called by %s: %s\n", edge.function, p.duration(edge.count))
+		}
+		for _, edge := range sortedEdges(f.Callees) {
+			fmt.Fprintf(&out, "  calls %s: %s\n", edge.function, p.duration(edge.count))
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func (p *Profile) percent(count int64) string {
+	if p.total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(count)*100/float64(p.total))
+}
+
+func (p *Profile) duration(count int64) string {
+	return (time.Duration(count) * p.Interval).String()
+}
+
+type edge struct {
+	function string
+	count    int64
+}
+
+// sortedEdges returns the functions of edges ordered by their count
+func sortedEdges(edges map[string]int64) []edge {
+	sorted := make([]edge, 0, len(edges))
+	for function, count := range edges {
+		sorted = append(sorted, edge{function, count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].function < sorted[j].function
+	})
+	return sorted
+}
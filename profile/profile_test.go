@@ -0,0 +1,124 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testProfile() *Profile {
+	p := New(time.Millisecond)
+	main := Frame{Function: "<main>", File: "a.rb", Line: 9}
+	foo := Frame{Function: "foo", File: "a.rb", Line: 2}
+	bar := Frame{Function: "bar", File: "a.rb", Line: 5}
+	p.Add([]Frame{main, foo, bar}, 3)
+	p.Add([]Frame{main, foo}, 1)
+	p.Add([]Frame{main, bar}, 2)
+	p.Add([]Frame{main, foo, bar}, 1)
+	return p
+}
+
+func TestProfileFunctions(t *testing.T) {
+	p := testProfile()
+
+	if p.Samples() != 7 {
+		t.Errorf("Expected 7 samples, got %d", p.Samples())
+	}
+	var actual []FunctionStats
+	for _, f := range p.Functions() {
+		actual = append(actual, *f)
+	}
+	expected := []FunctionStats{
+		{Function: "bar", Self: 6, Total: 6, Callers: map[string]int64{"foo": 4, "<main>": 2}, Callees: map[string]int64{}},
+		{Function: "foo", Self: 1, Total: 5, Callers: map[string]int64{"<main>": 5}, Callees: map[string]int64{"bar": 4}},
+		{Function: "<main>", Self: 0, Total: 7, Callers: map[string]int64{}, Callees: map[string]int64{"foo": 5, "bar": 2}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected functions\n%+v\ngot\n%+v", expected, actual)
+	}
+}
+
+func TestProfileWriteReport(t *testing.T) {
+	var out strings.Builder
+	if err := testProfile().WriteReport(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"Flat profile: 7 samples every 1ms, 7ms\n",
+		"85.7%   6ms   85.7%    6ms  bar\n",
+		"\nbar: total 6ms (85.7%), self 6ms (85.7%)\n  called by foo: 4ms\n  called by <main>: 2ms\n",
+		"\n<main>: total 7ms (100.0%), self 0s (0.0%)\n  calls foo: 5ms\n  calls bar: 2ms\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected report to contain %q, got\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestProfileWritePprof(t *testing.T) {
+	var out bytes.Buffer
+	if err := testProfile().WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[uint64]int)
+	var stringTable []string
+	for len(data) > 0 {
+		key, n := readVarint(data)
+		data = data[n:]
+		field, wire := key>>3, key&7
+		switch wire {
+		case wireVarint:
+			_, n = readVarint(data)
+			data = data[n:]
+		case wireBytes:
+			length, n := readVarint(data)
+			data = data[n:]
+			if field == profileStringTable {
+				stringTable = append(stringTable, string(data[:length]))
+			}
+			data = data[length:]
+		default:
+			t.Fatalf("Unexpected wire type %d", wire)
+		}
+		fields[field]++
+	}
+
+	if fields[profileSample] != 3 {
+		t.Errorf("Expected 3 distinct samples, got %d", fields[profileSample])
+	}
+	if fields[profileFunction] != 3 {
+		t.Errorf("Expected 3 functions, got %d", fields[profileFunction])
+	}
+	if fields[profileLocation] != 3 {
+		t.Errorf("Expected 3 locations, got %d", fields[profileLocation])
+	}
+	expected := []string{"", "samples", "count", "cpu", "nanoseconds", "[main]", "<main>", "a.rb", "foo", "bar"}
+	if !reflect.DeepEqual(stringTable, expected) {
+		t.Errorf("Expected string table %q, got %q", expected, stringTable)
+	}
+}
+
+func readVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i, b := range data {
+		v |= uint64(b&0x7f) << (7 * uint(i))
+		if b < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, len(data)
+}
@@ -0,0 +1,139 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goruby/goruby/object"
+)
+
+// ANSI escape sequences used to color the output
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+)
+
+// IsTerminal reports whether file is a terminal rather than a file or pipe,
+// which is when Config.Color should be enabled by default
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps text into the escape sequence of color
+func colorize(color, text string) string {
+	return color + text + colorReset
+}
+
+// highlight returns the inspected obj with its literals colored by their
+// type. The elements of Arrays and Hashes are highlighted one by one.
+func highlight(obj object.RubyObject) string {
+	switch obj := obj.(type) {
+	case *object.Array:
+		elements := make([]string, len(obj.Elements))
+		for i, element := range obj.Elements {
+			elements[i] = highlight(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *object.Hash:
+		keys := obj.Keys()
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			value, _ := obj.Get(key)
+			pairs[i] = highlight(key) + "=>" + highlight(value)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case *object.String:
+		return colorize(colorGreen, obj.Inspect())
+	case *object.Integer, *object.Float:
+		return colorize(colorBlue, obj.Inspect())
+	case *object.Symbol:
+		return colorize(colorYellow, obj.Inspect())
+	case *object.Boolean:
+		return colorize(colorCyan, obj.Inspect())
+	case object.RubyClassObject:
+		return colorize(colorBold, obj.Inspect())
+	}
+	if obj == object.NIL {
+		return colorize(colorCyan, obj.Inspect())
+	}
+	return obj.Inspect()
+}
+
+// formatResult returns the line showing the result of an expression
+func formatResult(obj object.RubyObject, color bool) string {
+	if !color {
+		return fmt.Sprintf("=> %s\n", obj.Inspect())
+	}
+	return fmt.Sprintf("%s %s\n", colorize(colorDim, "=>"), highlight(obj))
+}
+
+// formatError returns the lines showing err. Exceptions with a backtrace
+// are shown along with it and their class, like the interpreter does.
+func formatError(err error, color bool) string {
+	message := err.Error()
+	backtrace, _ := object.Backtrace(err)
+	if exception, ok := err.(object.RubyObject); ok && len(backtrace) != 0 {
+		class := exception.Class().(object.RubyObject).Inspect()
+		message = fmt.Sprintf("%s: %s (%s)", backtrace[0], message, class)
+		backtrace = backtrace[1:]
+	} else {
+		backtrace = nil
+	}
+	if color {
+		message = colorize(colorRed, message)
+	}
+	var out strings.Builder
+	out.WriteString(message + "\n")
+	for _, line := range backtrace {
+		line = "\tfrom " + line
+		if color {
+			line = colorize(colorDim, line)
+		}
+		out.WriteString(line + "\n")
+	}
+	return out.String()
+}
+
+// inputFile is the file the expressions entered are evaluated as
+const inputFile = "-"
+
+// pointAt returns the line of source holding the expression which raised
+// err, followed by a line with a caret below the expression. It returns an
+// empty string unless err was raised at the top level of source.
+func pointAt(err error, source string, color bool) string {
+	pos, ok := object.Position(err)
+	backtrace, _ := object.Backtrace(err)
+	if !ok || pos.Path != inputFile || len(backtrace) == 0 || !strings.HasSuffix(backtrace[0], "<main>'") {
+		return ""
+	}
+	if pos.Offset < 0 || pos.Offset >= len(source) || strings.Count(source[:pos.Offset], "\n")+1 != pos.Line {
+		return ""
+	}
+	start := strings.LastIndex(source[:pos.Offset], "\n") + 1
+	end := strings.IndexByte(source[pos.Offset:], '\n')
+	if end < 0 {
+		end = len(source)
+	} else {
+		end += pos.Offset
+	}
+	// keep the tabs of the line, so that the caret lines up with it
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, source[start:pos.Offset])
+	caret := "^"
+	if color {
+		caret = colorize(colorRed, caret)
+	}
+	return source[start:end] + "\n" + indent + caret + "\n"
+}
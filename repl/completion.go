@@ -0,0 +1,226 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goruby/goruby/object"
+)
+
+var (
+	requirePathPattern   = regexp.MustCompile(`\b(?:require|require_relative|load)[\s(]+"([^"]*)$`)
+	constantPathPattern  = regexp.MustCompile(`(?:^|[^\w:])([A-Z]\w*(?:::[A-Z]\w*)*)$`)
+	identifierPattern    = regexp.MustCompile(`(?:^|[^\w:@$])([a-z_]\w*)$`)
+	floatLiteralPattern  = regexp.MustCompile(`(?:^|\W)\d+\.\d+$`)
+	numberLiteralPattern = regexp.MustCompile(`(?:^|\W)\d+$`)
+	symbolLiteralPattern = regexp.MustCompile(`(?:^|\W):\w+$`)
+)
+
+// completer completes the names within the lines entered into the REPL
+// from the objects currently living in its environment
+type completer struct {
+	env object.Environment
+}
+
+// complete returns the candidates replacing the word ending text, which is
+// the line up to the cursor, and the byte offset the word starts at.
+// Method names are completed after a dot, following a local variable, a
+// constant or a literal, and constants after `::`. A word on its own is
+// completed to local variables, methods of self and constants. Within the
+// string passed to require, load and require_relative the Ruby files
+// within the load path, or the current directory, are completed.
+func (c *completer) complete(text string) (int, []string) {
+	if match := requirePathPattern.FindStringSubmatchIndex(text); match != nil {
+		return match[2], c.requirePaths(text[match[2]:], strings.Contains(text[:match[2]], "require_relative"))
+	}
+	start := len(text)
+	for start > 0 && isWordByte(text[start-1]) {
+		start--
+	}
+	prefix := text[start:]
+	var names []string
+	switch {
+	case strings.HasSuffix(text[:start], "::"):
+		if module, ok := c.receiver(text[:start-2]); ok {
+			names = c.constants(module)
+		}
+	case strings.HasSuffix(text[:start], "."):
+		if receiver, ok := c.receiver(text[:start-1]); ok {
+			names = c.methods(receiver, "methods")
+		}
+	case prefix != "" && unicode.IsUpper(rune(prefix[0])):
+		if objectClass, ok := c.env.Get("Object"); ok {
+			names = c.constants(objectClass)
+		}
+	default:
+		names = c.localVariables()
+		if self, ok := c.env.Get("self"); ok {
+			names = append(names, c.methods(self, "methods")...)
+			names = append(names, c.methods(self, "private_methods")...)
+		}
+	}
+	return start, candidates(names, prefix)
+}
+
+// receiver returns the object described by the expression ending text, if
+// it can be determined without evaluating any code: a local variable, a
+// constant, self, or a literal
+func (c *completer) receiver(text string) (object.RubyObject, bool) {
+	if match := constantPathPattern.FindStringSubmatch(text); match != nil {
+		return c.constant(match[1])
+	}
+	if match := identifierPattern.FindStringSubmatch(text); match != nil {
+		value, ok := c.env.Get(match[1])
+		if _, isFunction := value.(*object.Function); !ok || isFunction {
+			return nil, false
+		}
+		return value, true
+	}
+	switch {
+	case strings.HasSuffix(text, `"`):
+		return &object.String{}, true
+	case strings.HasSuffix(text, "]"):
+		return object.NewArray(), true
+	case strings.HasSuffix(text, "}"):
+		return object.NewHash(nil), true
+	case floatLiteralPattern.MatchString(text):
+		return object.NewFloat(0), true
+	case numberLiteralPattern.MatchString(text):
+		return object.NewInteger(0), true
+	case symbolLiteralPattern.MatchString(text):
+		return object.NewSymbol(""), true
+	}
+	return nil, false
+}
+
+// constant returns the constant at the path, like Net::HTTP
+func (c *completer) constant(path string) (object.RubyObject, bool) {
+	names := strings.Split(path, "::")
+	value, ok := c.env.Get(names[0])
+	if !ok {
+		return nil, false
+	}
+	for _, name := range names[1:] {
+		var err error
+		if value, err = object.RuntimeOf(c.env).Send(value, "const_get", object.NewSymbol(name)); err != nil {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// constants returns the names of the constants of module
+func (c *completer) constants(module object.RubyObject) []string {
+	constants, err := object.RuntimeOf(c.env).Send(module, "constants")
+	if err != nil {
+		return nil
+	}
+	return symbolNames(constants)
+}
+
+// methods returns the names of the methods of receiver listed by the
+// method given, omitting operators
+func (c *completer) methods(receiver object.RubyObject, method string) []string {
+	methods, err := object.RuntimeOf(c.env).Send(receiver, method)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range symbolNames(methods) {
+		if name[0] == '_' || unicode.IsLetter(rune(name[0])) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (c *completer) localVariables() []string {
+	variables, err := object.RuntimeOf(c.env).Send(object.NewBinding(c.env), "local_variables")
+	if err != nil {
+		return nil
+	}
+	return symbolNames(variables)
+}
+
+// requirePaths returns the paths of the Ruby files and directories starting
+// with prefix within the load path, or the current directory for
+// require_relative. Ruby files are named without their extension.
+func (c *completer) requirePaths(prefix string, relative bool) []string {
+	dirs := []string{"."}
+	if loadPath, ok := c.env.Get("$LOAD_PATH"); ok && !relative {
+		dirs = symbolNames(loadPath)
+	}
+	dir, base := filepath.Split(prefix)
+	var paths []string
+	for _, loadDir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(loadDir, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			switch {
+			case entry.IsDir():
+				paths = append(paths, dir+name+"/")
+			case strings.HasSuffix(name, ".rb"):
+				paths = append(paths, dir+strings.TrimSuffix(name, ".rb"))
+			}
+		}
+	}
+	return candidates(paths, dir+base)
+}
+
+// symbolNames returns the names of the Symbols or Strings within array
+func symbolNames(array object.RubyObject) []string {
+	elements, ok := array.(*object.Array)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, element := range elements.Elements {
+		switch element := element.(type) {
+		case *object.Symbol:
+			names = append(names, element.Value)
+		case *object.String:
+			names = append(names, element.Value)
+		}
+	}
+	return names
+}
+
+// candidates returns the names starting with prefix, sorted and without
+// duplicates
+func candidates(names []string, prefix string) []string {
+	seen := make(map[string]bool)
+	var found []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			found = append(found, name)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// commonPrefix returns the longest prefix shared by all names
+func commonPrefix(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	prefix := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '?' || b == '!'
+}
@@ -0,0 +1,76 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/interpreter"
+	"github.com/goruby/goruby/object"
+)
+
+func TestCompleterComplete(t *testing.T) {
+	env := object.NewMainEnvironment()
+	interpreter := interpreter.New()
+	interpreter.SetEnvironment(env)
+	if _, err := interpreter.Interpret("counter = 1; count_limit = 2; module Outer; end; def greet_me; end"); err != nil {
+		t.Fatal(err)
+	}
+	completer := &completer{env: env}
+
+	tests := []struct {
+		text       string
+		start      int
+		candidates []string
+	}{
+		{"x = coun", 4, []string{"count_limit", "counter"}},
+		{"counter.ev", 8, []string{"even?"}},
+		{"counter.div", 8, []string{"div", "divmod"}},
+		{"Net::HTTPS", 5, []string{"HTTPServerError", "HTTPSuccess"}},
+		{"Oute", 0, []string{"Outer"}},
+		{`"abc".upca`, 6, []string{"upcase"}},
+		{"[1, 2].firs", 7, []string{"first"}},
+		{"greet_", 0, []string{"greet_me"}},
+		{"pu", 0, []string{"public_send", "puts"}},
+		{"unknown.to_", 8, nil},
+	}
+
+	for _, tt := range tests {
+		start, candidates := completer.complete(tt.text)
+		if start != tt.start || !reflect.DeepEqual(candidates, tt.candidates) {
+			t.Errorf("Expected %q to complete at %d to %q, got %d and %q", tt.text, tt.start, tt.candidates, start, candidates)
+		}
+	}
+}
+
+func TestCompleterRequirePaths(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "lib", "helpers"), 0755)
+	os.WriteFile(filepath.Join(dir, "lib", "library.rb"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "lib", "notes.txt"), nil, 0644)
+	env := object.NewMainEnvironment()
+	env.SetGlobal("$LOAD_PATH", object.NewArray(&object.String{Value: dir}))
+	completer := &completer{env: env}
+
+	start, candidates := completer.complete(`require "lib/`)
+	if expected := []string{"lib/helpers/", "lib/library"}; start != 9 || !reflect.DeepEqual(candidates, expected) {
+		t.Errorf("Expected the files within lib to complete at 9 to %q, got %d and %q", expected, start, candidates)
+	}
+}
+
+func TestLineEditorCompletion(t *testing.T) {
+	history, _ := LoadHistory("")
+	editor := newTestEditor("x.to\t\ta\t\r", history)
+	editor.complete = func(text string) (int, []string) {
+		return 2, candidates([]string{"to_s", "to_sym", "to_a", "tap"}, text[2:])
+	}
+
+	line, err := editor.readLine("> ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "x.to_a" {
+		t.Errorf("Expected the line to be completed to %q, got %q", "x.to_a", line)
+	}
+}
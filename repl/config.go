@@ -0,0 +1,193 @@
+package repl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/token"
+)
+
+// RCFile is the name of the file in the home directory which is evaluated
+// as Ruby when the REPL starts
+const RCFile = ".gorubyrc"
+
+// Config configures the prompts and the colors of the REPL.
+//
+// The prompts are templates in which %n is replaced by the line number,
+// %i by the nesting depth of the expression entered so far, %N by the name
+// of the REPL and %% by a percent sign. A width may be given like in
+// %03n.
+type Config struct {
+	// Prompt is shown when a new expression is expected
+	Prompt string
+	// ContinuationPrompt is shown for the lines continuing an unfinished
+	// expression
+	ContinuationPrompt string
+	// Color enables the highlighting of results and errors
+	Color bool
+	// RCFile is the path of the file evaluated before the first prompt.
+	// It is skipped if empty or missing.
+	RCFile string
+}
+
+// DefaultConfig returns the configuration used by Start: the prompts show
+// the line number and the nesting depth, colors are disabled and RCFile is
+// read from the home directory.
+func DefaultConfig() Config {
+	return Config{
+		Prompt:             "%N:%03n> ",
+		ContinuationPrompt: "%N:%03n:%i* ",
+		RCFile:             defaultRCPath(),
+	}
+}
+
+// defaultRCPath returns the path of RCFile within the home directory, or
+// an empty string if there is none
+func defaultRCPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, RCFile)
+}
+
+// settings returns a Hash with the settings of config, which Ruby code can
+// change through Girb.conf
+func (c Config) settings() *object.Hash {
+	settings := object.NewHash(nil)
+	settings.Set(object.NewSymbol("prompt"), &object.String{Value: c.Prompt})
+	settings.Set(object.NewSymbol("prompt_continuation"), &object.String{Value: c.ContinuationPrompt})
+	settings.Set(object.NewSymbol("color"), nativeBoolToBoolean(c.Color))
+	return settings
+}
+
+// update returns config with the settings changed in settings. Settings of
+// the wrong type are ignored.
+func (c Config) update(settings *object.Hash) Config {
+	if prompt, ok := settings.Get(object.NewSymbol("prompt")); ok {
+		if prompt, ok := prompt.(*object.String); ok {
+			c.Prompt = prompt.Value
+		}
+	}
+	if prompt, ok := settings.Get(object.NewSymbol("prompt_continuation")); ok {
+		if prompt, ok := prompt.(*object.String); ok {
+			c.ContinuationPrompt = prompt.Value
+		}
+	}
+	if color, ok := settings.Get(object.NewSymbol("color")); ok {
+		c.Color = color != object.NIL && color != object.FALSE
+	}
+	return c
+}
+
+// defineGirbModule defines the Girb module whose conf method returns
+// settings, for the startup file and the expressions entered to change the
+// configuration
+func defineGirbModule(settings *object.Hash) error {
+	_, err := object.DefineModule(object.ModuleDefinition{
+		Name: "Girb",
+		Methods: map[string]object.RubyMethod{
+			"conf": object.NewMethod(0, 0, func(object.RubyObject, ...object.RubyObject) (object.RubyObject, error) {
+				return settings, nil
+			}),
+		},
+	})
+	return err
+}
+
+func nativeBoolToBoolean(b bool) object.RubyObject {
+	if b {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+// formatPrompt replaces the placeholders of template by the line number and
+// the nesting depth. Unknown placeholders are kept as they are.
+func formatPrompt(template string, line, depth int) string {
+	var out strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			out.WriteByte(template[i])
+			continue
+		}
+		end := i + 1
+		for end < len(template) && template[end] >= '0' && template[end] <= '9' {
+			end++
+		}
+		if end == len(template) {
+			out.WriteString(template[i:])
+			break
+		}
+		width := template[i+1 : end]
+		switch template[end] {
+		case 'n':
+			out.WriteString(fmt.Sprintf("%"+width+"d", line))
+		case 'i':
+			out.WriteString(fmt.Sprintf("%"+width+"d", depth))
+		case 'N':
+			out.WriteString(fmt.Sprintf("%"+width+"s", "girb"))
+		case '%':
+			out.WriteByte('%')
+		default:
+			out.WriteString(template[i : end+1])
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// nestingDepth returns the number of blocks, definitions and brackets left
+// open in source. It only looks at the tokens, so `if`, `unless`, `while`
+// and `until` are assumed to be modifiers unless they start a statement.
+func nestingDepth(source string) int {
+	l := lexer.New(source)
+	depth := 0
+	statementStart, loopCondition := true, false
+	for l.HasNext() {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		keyword := tok.Type
+		if tok.Type == token.IDENT && (tok.Literal == "unless" || tok.Literal == "until") {
+			keyword = token.IF
+			if tok.Literal == "until" {
+				keyword = token.WHILE
+			}
+		}
+		switch keyword {
+		case token.DEF, token.CLASS, token.MODULE, token.CASE, token.BEGIN,
+			token.LBRACE, token.LBRACKET, token.LPAREN:
+			depth++
+		case token.DO:
+			if !loopCondition {
+				depth++
+			}
+			loopCondition = false
+		case token.IF, token.WHILE:
+			if statementStart {
+				depth++
+				loopCondition = keyword == token.WHILE
+			}
+		case token.END, token.RBRACE, token.RBRACKET, token.RPAREN:
+			if depth > 0 {
+				depth--
+			}
+		case token.NEWLINE, token.SEMICOLON:
+			loopCondition = false
+		}
+		switch tok.Type {
+		case token.NEWLINE, token.SEMICOLON, token.ASSIGN, token.LPAREN,
+			token.THEN, token.ELSE, token.DO, token.BEGIN:
+			statementStart = true
+		default:
+			statementStart = false
+		}
+	}
+	return depth
+}
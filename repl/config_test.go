@@ -0,0 +1,125 @@
+package repl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goruby/goruby/object"
+)
+
+func TestFormatPrompt(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"%N:%03n> ", "girb:007> "},
+		{"%N:%03n:%i* ", "girb:007:2* "},
+		{"[%n] %% ", "[7] % "},
+		{"%x %", "%x %"},
+	}
+
+	for _, tt := range tests {
+		prompt := formatPrompt(tt.template, 7, 2)
+		if prompt != tt.expected {
+			t.Errorf("Expected %q to format as %q, got %q", tt.template, tt.expected, prompt)
+		}
+	}
+}
+
+func TestNestingDepth(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected int
+	}{
+		{"", 0},
+		{"class Foo\n", 1},
+		{"class Foo\ndef bar\n", 2},
+		{"class Foo\ndef bar\nend\n", 1},
+		{"[1, 2].each do |x|\n", 1},
+		{"while x < 3 do\n", 1},
+		{"if x\n", 1},
+		{"x = if y\n", 1},
+		{"puts x if x\n", 0},
+		{"unless x\n", 1},
+		{"foo(1,\n", 1},
+		{"{a: [\n", 2},
+		{"end\nend\n", 0},
+	}
+
+	for _, tt := range tests {
+		depth := nestingDepth(tt.source)
+		if depth != tt.expected {
+			t.Errorf("Expected depth %d for %q, got %d", tt.expected, tt.source, depth)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	hash := object.NewHash(nil)
+	hash.Set(object.NewSymbol("a"), object.NIL)
+	array := object.NewArray(object.NewInteger(1), &object.String{Value: "s"}, hash)
+
+	expected := "[\x1b[34m1\x1b[0m, \x1b[32ms\x1b[0m, {\x1b[33m:a\x1b[0m=>\x1b[36mnil\x1b[0m}]"
+	if result := highlight(array); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if result := formatResult(array, false); result != "=> "+array.Inspect()+"\n" {
+		t.Errorf("Expected the result uncolored, got %q", result)
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	err := object.NewRuntimeError("boom")
+	object.SetBacktrace(err, []string{"foo.rb:2:in `bar'", "foo.rb:5:in `<main>'"})
+
+	expected := "foo.rb:2:in `bar': boom (RuntimeError)\n\tfrom foo.rb:5:in `<main>'\n"
+	if result := formatError(err, false); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	result := formatError(err, true)
+	if !strings.HasPrefix(result, colorRed) || !strings.Contains(result, colorDim+"\tfrom") {
+		t.Errorf("Expected the message red and the backtrace dim, got %q", result)
+	}
+}
+
+func TestPointAt(t *testing.T) {
+	source := "x = 1\n\ty = x + nil.foo\n"
+	err := object.NewNoMethodError(object.NIL, "foo")
+	object.SetBacktrace(err, []string{"-:2:in `<main>'"})
+	object.SetPosition(err, object.SourcePosition{Path: inputFile, Line: 2, Offset: strings.Index(source, ".foo")})
+
+	expected := "\ty = x + nil.foo\n\t           ^\n"
+	if result := pointAt(err, source, false); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	object.SetBacktrace(err, []string{"-:2:in `m'", "-:1:in `<main>'"})
+	object.SetPosition(err, object.SourcePosition{Path: inputFile, Line: 2, Offset: 8})
+	if result := pointAt(err, source, false); result != "" {
+		t.Errorf("Expected no pointer within methods, got %q", result)
+	}
+}
+
+func TestStartWithConfigRCFile(t *testing.T) {
+	rcFile := filepath.Join(t.TempDir(), RCFile)
+	content := "Girb.conf[:prompt] = \"rc %n> \"\ndef greeting\n\"hi\"\nend\n"
+	if err := os.WriteFile(rcFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.RCFile = rcFile
+
+	out := make(chan string)
+	go StartWithConfig(strings.NewReader("greeting\nGirb.conf[:color] = true\n:x\n"), out, nil, config)
+	var output strings.Builder
+	for s := range out {
+		output.WriteString(s)
+	}
+
+	expected := "rc 1> => hi\nrc 2> => true\nrc 3> " + colorize(colorDim, "=>") + " " + colorize(colorYellow, ":x") + "\nrc 4> \n"
+	if output.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, output.String())
+	}
+}
@@ -0,0 +1,85 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+)
+
+// HistoryFile is the name of the file in the home directory the lines
+// entered into the REPL are kept in across sessions
+const HistoryFile = ".goruby_history"
+
+// maxHistory is the number of lines kept in the history
+const maxHistory = 1000
+
+// History holds the lines entered into the REPL, oldest first, and
+// appends every new one to its file if it has one
+type History struct {
+	path    string
+	entries []string
+}
+
+// LoadHistory returns the history stored in the file at path, which may
+// not exist yet. An empty path keeps the history in memory only.
+func LoadHistory(path string) (*History, error) {
+	history := &History{path: path}
+	if path == "" {
+		return history, nil
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history.entries = append(history.entries, scanner.Text())
+	}
+	if len(history.entries) > maxHistory {
+		history.entries = history.entries[len(history.entries)-maxHistory:]
+	}
+	return history, scanner.Err()
+}
+
+// defaultHistoryPath returns the path of HistoryFile within the home
+// directory, or an empty string if there is none
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HistoryFile)
+}
+
+// Add appends line to the history, unless it is empty or repeats the last
+// line
+func (h *History) Add(line string) error {
+	if line == "" || len(h.entries) != 0 && h.entries[len(h.entries)-1] == line {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+	if h.path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Entries returns the lines of the history, oldest first
+func (h *History) Entries() []string {
+	return h.entries
+}
@@ -0,0 +1,354 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// errLineCanceled is returned by a lineReader when the line has been
+// canceled by Ctrl-C
+var errLineCanceled = errors.New("line canceled")
+
+// lineReader reads the lines entered into the REPL
+type lineReader interface {
+	// readLine shows the prompt and returns the next line without line
+	// terminator. It returns io.EOF once the input ends.
+	readLine(prompt string) (string, error)
+}
+
+// scanningReader reads lines from any input, without line editing
+type scanningReader struct {
+	scanner *bufio.Scanner
+	out     chan<- string
+}
+
+func (s *scanningReader) readLine(prompt string) (string, error) {
+	s.out <- prompt
+	if !s.scanner.Scan() {
+		return "", io.EOF
+	}
+	return s.scanner.Text(), nil
+}
+
+// keys which are not runes, decoded from escape sequences
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+const (
+	ctrlA     = 1
+	ctrlB     = 2
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlE     = 5
+	ctrlF     = 6
+	ctrlG     = 7
+	ctrlH     = 8
+	ctrlK     = 11
+	ctrlN     = 14
+	ctrlP     = 16
+	ctrlR     = 18
+	ctrlU     = 21
+	ctrlW     = 23
+	escape    = 27
+	backspace = 127
+)
+
+// lineEditor reads lines from a terminal with readline like editing: the
+// arrow keys, Home and End move within the line and through the history,
+// Ctrl-A and Ctrl-E jump to the start and end of the line, Ctrl-K, Ctrl-U
+// and Ctrl-W delete parts of it and Ctrl-R searches the history backwards.
+// Tab completes the word before the cursor. Everything it shows is sent to
+// out, like the rest of the REPL output.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     chan<- string
+	history *History
+	// complete returns the byte offset of the word ending text and the
+	// candidates to replace it with, if set
+	complete func(text string) (int, []string)
+	// raw puts the terminal into raw mode while a line is read, if set
+	raw func() (func(), error)
+}
+
+// editedLine is the state of the line being edited
+type editedLine struct {
+	prompt string
+	buffer []rune
+	pos    int
+	// entry is the index of the history entry shown, or the number of
+	// entries while editing the new line, which is kept in draft
+	entry int
+	draft []rune
+}
+
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.raw != nil {
+		if restore, err := e.raw(); err == nil {
+			defer restore()
+		}
+	}
+	line := &editedLine{prompt: prompt, entry: len(e.history.Entries())}
+	e.out <- prompt
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", io.EOF
+		}
+		if key == ctrlR {
+			if key, err = e.reverseSearch(line); err != nil {
+				return "", io.EOF
+			}
+		}
+		switch key {
+		case '\r', '\n':
+			e.out <- "\n"
+			text := string(line.buffer)
+			e.history.Add(text)
+			return text, nil
+		case ctrlC:
+			e.out <- "^C\n"
+			return "", errLineCanceled
+		case ctrlD:
+			if len(line.buffer) == 0 {
+				return "", io.EOF
+			}
+			line.delete(line.pos, line.pos+1)
+		case '\t':
+			if e.complete == nil {
+				line.insert(key)
+				break
+			}
+			e.completeWord(line)
+		default:
+			e.edit(line, key)
+		}
+		e.redraw(line)
+	}
+}
+
+// edit applies the key to the line
+func (e *lineEditor) edit(line *editedLine, key rune) {
+	entries := e.history.Entries()
+	switch key {
+	case ctrlA, keyHome:
+		line.pos = 0
+	case ctrlE, keyEnd:
+		line.pos = len(line.buffer)
+	case ctrlB, keyLeft:
+		if line.pos > 0 {
+			line.pos--
+		}
+	case ctrlF, keyRight:
+		if line.pos < len(line.buffer) {
+			line.pos++
+		}
+	case ctrlH, backspace:
+		if line.pos > 0 {
+			line.delete(line.pos-1, line.pos)
+		}
+	case keyDelete:
+		line.delete(line.pos, line.pos+1)
+	case ctrlK:
+		line.delete(line.pos, len(line.buffer))
+	case ctrlU:
+		line.delete(0, line.pos)
+	case ctrlW:
+		start := line.pos
+		for start > 0 && unicode.IsSpace(line.buffer[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(line.buffer[start-1]) {
+			start--
+		}
+		line.delete(start, line.pos)
+	case ctrlP, keyUp:
+		if line.entry > 0 {
+			if line.entry == len(entries) {
+				line.draft = line.buffer
+			}
+			line.entry--
+			line.set(entries[line.entry])
+		}
+	case ctrlN, keyDown:
+		if line.entry < len(entries) {
+			line.entry++
+			if line.entry == len(entries) {
+				line.set(string(line.draft))
+			} else {
+				line.set(entries[line.entry])
+			}
+		}
+	default:
+		if key == '\t' || key >= ' ' {
+			line.insert(key)
+		}
+	}
+}
+
+// completeWord replaces the word before the cursor by the candidate
+// completing it, or by the prefix shared by all candidates. If there are
+// several candidates which share nothing more it lists them below the line.
+func (e *lineEditor) completeWord(line *editedLine) {
+	text := string(line.buffer[:line.pos])
+	start, candidates := e.complete(text)
+	word := text[start:]
+	switch prefix := commonPrefix(candidates); {
+	case len(candidates) == 0:
+		e.out <- "\a"
+	case len(prefix) > len(word):
+		for _, r := range prefix[len(word):] {
+			line.insert(r)
+		}
+	case len(candidates) > 1:
+		e.out <- "\n" + strings.Join(candidates, "  ") + "\n"
+	}
+}
+
+// reverseSearch searches the history backwards for the entries containing
+// the text typed, until a key other than a printable one or Backspace is
+// pressed. The line is set to the entry found and the key returned to be
+// handled like any other. Ctrl-G and Ctrl-C cancel the search and keep the
+// line as it was.
+func (e *lineEditor) reverseSearch(line *editedLine) (rune, error) {
+	entries := e.history.Entries()
+	var query []rune
+	found, failed := len(entries), false
+	// find searches from the entry at the index backwards, keeping the
+	// entry found last if there is no other
+	find := func(from int) {
+		for i := from; i >= 0; i-- {
+			if strings.Contains(entries[i], string(query)) {
+				found, failed = i, false
+				return
+			}
+		}
+		failed = true
+	}
+	for {
+		match, label := "", "reverse-i-search"
+		if found < len(entries) {
+			match = entries[found]
+		}
+		if failed {
+			label = "failed " + label
+		}
+		e.out <- fmt.Sprintf("\r\x1b[K(%s)`%s': %s", label, string(query), match)
+		key, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case key == ctrlR:
+			find(found - 1)
+		case key == ctrlH || key == backspace:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(len(entries) - 1)
+			}
+		case key == ctrlG || key == ctrlC:
+			return keyUnknown, nil
+		case key >= ' ':
+			query = append(query, key)
+			if found == len(entries) {
+				find(found - 1)
+			} else {
+				find(found)
+			}
+		default:
+			if found < len(entries) {
+				line.entry = found
+				line.set(match)
+			}
+			return key, nil
+		}
+	}
+}
+
+// readKey reads the next key, decoding the escape sequences of the cursor
+// keys, Home, End and Delete
+func (e *lineEditor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != escape {
+		return r, err
+	}
+	prefix, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if prefix != '[' && prefix != 'O' {
+		return keyUnknown, nil
+	}
+	var sequence []rune
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		sequence = append(sequence, r)
+		if r < '0' || r > '9' && r != ';' {
+			break
+		}
+	}
+	switch string(sequence) {
+	case "A":
+		return keyUp, nil
+	case "B":
+		return keyDown, nil
+	case "C":
+		return keyRight, nil
+	case "D":
+		return keyLeft, nil
+	case "H", "1~", "7~":
+		return keyHome, nil
+	case "F", "4~", "8~":
+		return keyEnd, nil
+	case "3~":
+		return keyDelete, nil
+	default:
+		return keyUnknown, nil
+	}
+}
+
+// redraw shows the prompt and the line, and moves the cursor to its
+// position
+func (e *lineEditor) redraw(line *editedLine) {
+	out := "\r\x1b[K" + line.prompt + string(line.buffer)
+	if back := len(line.buffer) - line.pos; back > 0 {
+		out += fmt.Sprintf("\x1b[%dD", back)
+	}
+	e.out <- out
+}
+
+func (l *editedLine) set(text string) {
+	l.buffer = []rune(text)
+	l.pos = len(l.buffer)
+}
+
+func (l *editedLine) insert(r rune) {
+	l.buffer = append(l.buffer[:l.pos], append([]rune{r}, l.buffer[l.pos:]...)...)
+	l.pos++
+}
+
+// delete removes the runes from start up to end, which may exceed the line
+func (l *editedLine) delete(start, end int) {
+	if end > len(l.buffer) {
+		end = len(l.buffer)
+	}
+	if start >= end {
+		return
+	}
+	l.buffer = append(l.buffer[:start:start], l.buffer[end:]...)
+	l.pos = start
+}
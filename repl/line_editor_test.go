@@ -0,0 +1,107 @@
+package repl
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, history *History) *lineEditor {
+	out := make(chan string)
+	go func() {
+		for range out {
+		}
+	}()
+	return &lineEditor{in: bufio.NewReader(strings.NewReader(input)), out: out, history: history}
+}
+
+func TestLineEditorEditing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"puts 1\r", "puts 1"},
+		{"ab\x1b[Dx\r", "axb"},
+		{"bc\x01a\x05d\r", "abcd"},
+		{"abc\x7f\x7fx\r", "ax"},
+		{"abcd\x1b[D\x1b[D\x0b\r", "ab"},
+		{"abcd\x1b[D\x15\r", "d"},
+		{"foo bar \x17\r", "foo "},
+		{"abc\x01\x1b[3~\r", "bc"},
+		{"abc\x01\x04\r", "bc"},
+		{"é\x1b[Dà\r", "àé"},
+	}
+
+	for _, tt := range tests {
+		history, _ := LoadHistory("")
+		line, err := newTestEditor(tt.input, history).readLine("> ")
+		if err != nil {
+			t.Errorf("Expected no error for %q, got %v", tt.input, err)
+		}
+		if line != tt.expected {
+			t.Errorf("Expected %q to read %q, got %q", tt.input, tt.expected, line)
+		}
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	history, _ := LoadHistory("")
+	editor := newTestEditor("first\rsecond\r\x1b[A\x1b[A\r\x10\x10\x0e!\rdraft\x1b[A\x1b[B\r\x12fir\r\x12s\x12\x12\x1b[C!\r\x04", history)
+
+	var lines []string
+	for {
+		line, err := editor.readLine("> ")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	expected := []string{"first", "second", "first", "first!", "draft", "first!", "first!"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+}
+
+func TestLineEditorCancel(t *testing.T) {
+	history, _ := LoadHistory("")
+	_, err := newTestEditor("abc\x03", history).readLine("> ")
+	if err != errLineCanceled {
+		t.Errorf("Expected the line to be canceled, got %v", err)
+	}
+	if len(history.Entries()) != 0 {
+		t.Errorf("Expected a canceled line not to be added to the history, got %q", history.Entries())
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFile)
+	history, err := LoadHistory(path)
+	if err != nil {
+		t.Fatalf("Expected a missing history file to be fine, got %v", err)
+	}
+	for _, line := range []string{"a = 1", "a = 1", "", "puts a"} {
+		if err := history.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "a = 1\nputs a\n" {
+		t.Errorf("Expected the history file to contain the lines, got %q", content)
+	}
+	history, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a = 1", "puts a"}; !reflect.DeepEqual(history.Entries(), expected) {
+		t.Errorf("Expected entries %q, got %q", expected, history.Entries())
+	}
+}
@@ -4,46 +4,193 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"sync"
 
 	"github.com/goruby/goruby/interpreter"
 	"github.com/goruby/goruby/object"
 	"github.com/goruby/goruby/parser"
 )
 
-const PROMPT = "girb:%03d> "
+// Start reads expressions from in and writes the prompts and results to
+// out. A value received from interrupts aborts the expression currently
+// evaluated by an Interrupt exception. It has no effect while waiting for
+// input.
+//
+// If in is a terminal the lines are edited like with readline, and kept
+// in the history file in the home directory across sessions.
+//
+// The result of the last expression is bound to `_`, the one before to
+// `__`, and each result to `_` followed by the number of the line the
+// expression ends at, like _3.
+func Start(in io.Reader, out chan<- string, interrupts <-chan struct{}) {
+	StartWithConfig(in, out, interrupts, DefaultConfig())
+}
 
-func Start(in io.Reader, out chan<- string) {
-	scanner := bufio.NewScanner(in)
-	counter := 1
+// StartWithConfig works like Start with the prompts and colors set by
+// config. The startup file of config is evaluated before the first prompt.
+// It and the expressions entered can change the configuration through the
+// Hash returned by Girb.conf, like
+//
+//	Girb.conf[:prompt] = "%n> "
+//	Girb.conf[:color] = true
+func StartWithConfig(in io.Reader, out chan<- string, interrupts <-chan struct{}, config Config) {
 	env := object.NewMainEnvironment()
+	reader := newLineReader(in, out, &completer{env: env})
 	interpreter := interpreter.New()
 	interpreter.SetEnvironment(env)
+	settings := config.settings()
+	if err := defineGirbModule(settings); err != nil {
+		out <- formatError(err, config.Color)
+	}
+	if config.RCFile != "" {
+		if _, err := os.Stat(config.RCFile); err == nil {
+			if _, err := interpreter.InterpretFile(config.RCFile); err != nil {
+				out <- fmt.Sprintf("Error in %s: %s", config.RCFile, formatError(err, config.Color))
+			}
+		}
+	}
+	evaluation := &evaluation{}
+	go func() {
+		for range interrupts {
+			evaluation.interrupt(interpreter)
+		}
+	}()
+	config = readEvalPrint(reader, out, interpreter, env, evaluation, config, settings)
+	if err := interpreter.Finalize(); err != nil {
+		out <- formatError(err, config.Color)
+	}
+	close(out)
+}
+
+// Console reads expressions from in and evaluates them within binding,
+// with its local variables and self, until the input ends or exit is
+// called. Prompts and results are written to out. It returns once the
+// console is left, so that the program calling it, like binding.irb does,
+// continues. Neither the startup file nor the exit handlers are run.
+func Console(binding *object.Binding, in io.Reader, out io.Writer, config Config) {
+	lines := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := range lines {
+			fmt.Fprint(out, line)
+		}
+	}()
+	interpreter := interpreter.New()
+	interpreter.SetEnvironment(binding.Env)
+	reader := newLineReader(in, lines, &completer{env: binding.Env})
+	readEvalPrint(reader, lines, interpreter, binding.Env, &evaluation{}, config, config.settings())
+	close(lines)
+	<-done
+}
+
+// readEvalPrint evaluates the expressions read by reader within env and
+// sends the prompts and results to out, until the input ends or exit is
+// called. It returns the configuration as last changed through settings.
+func readEvalPrint(reader lineReader, out chan<- string, interpreter interpreter.Interpreter, env object.Environment, evaluation *evaluation, config Config, settings *object.Hash) Config {
+	counter := 1
 	var buffer string
 	for {
-		out <- fmt.Sprintf(PROMPT, counter)
+		config = config.update(settings)
+		prompt := formatPrompt(config.Prompt, counter, 0)
+		if buffer != "" {
+			prompt = formatPrompt(config.ContinuationPrompt, counter, nestingDepth(buffer))
+		}
+		line, err := reader.readLine(prompt)
 		counter++
-		scanned := scanner.Scan()
-		if !scanned {
+		if err == errLineCanceled {
+			buffer = ""
+			continue
+		}
+		if err != nil {
 			out <- fmt.Sprintln()
-			close(out)
-			return
+			return config
 		}
 
-		buffer += scanner.Text()
+		buffer += line
+		evaluation.start()
 		evaluated, err := interpreter.Interpret(buffer)
+		evaluation.stop(env)
+		if _, ok := err.(*object.SystemExit); ok {
+			return config
+		}
 		if err != nil {
 			if parser.IsEOFError(err) {
 				buffer += "\n"
 				continue
 			}
-			out <- fmt.Sprintf("%s\n", err.Error())
+			out <- formatError(err, config.Color) + pointAt(err, buffer, config.Color)
 			buffer = ""
 			continue
 		}
 
 		if evaluated != nil {
-			out <- fmt.Sprintf("=> %s\n", evaluated.Inspect())
+			out <- formatResult(evaluated, config.Color)
+			bindResult(env, evaluated, counter-1)
 		}
 		buffer = ""
 	}
 }
+
+// bindResult binds the result of the expression ending at the line to `_`,
+// so that it can be reused by the next expression, and to `_<line>`, like
+// _3, to refer to it later on. The result `_` was bound to is moved to
+// `__`.
+func bindResult(env object.Environment, result object.RubyObject, line int) {
+	if previous, ok := env.Get("_"); ok {
+		env.Set("__", previous)
+	}
+	env.Set("_", result)
+	env.Set(fmt.Sprintf("_%d", line), result)
+}
+
+// evaluation tracks whether an expression is being evaluated, so that
+// interrupts only affect running expressions
+type evaluation struct {
+	sync.Mutex
+	running bool
+}
+
+func (e *evaluation) start() {
+	e.Lock()
+	defer e.Unlock()
+	e.running = true
+}
+
+// stop marks the evaluation as finished and drops an interruption which
+// arrived too late to be raised
+func (e *evaluation) stop(env object.Environment) {
+	e.Lock()
+	defer e.Unlock()
+	e.running = false
+	object.RuntimeOf(env).CheckInterrupt()
+}
+
+func (e *evaluation) interrupt(interpreter interpreter.Interpreter) {
+	e.Lock()
+	defer e.Unlock()
+	if e.running {
+		interpreter.Interrupt()
+	}
+}
+
+// newLineReader returns a lineEditor completing words by completer if in
+// is a terminal, and a scanningReader otherwise
+func newLineReader(in io.Reader, out chan<- string, completer *completer) lineReader {
+	if file, ok := in.(*os.File); ok && canEditLines(file.Fd()) {
+		history, err := LoadHistory(defaultHistoryPath())
+		if err != nil {
+			out <- fmt.Sprintf("Cannot load history: %s\n", err)
+			history, _ = LoadHistory("")
+		}
+		return &lineEditor{
+			in:       bufio.NewReader(in),
+			out:      out,
+			history:  history,
+			complete: completer.complete,
+			raw:      func() (func(), error) { return makeRaw(file.Fd()) },
+		}
+	}
+	return &scanningReader{scanner: bufio.NewScanner(in), out: out}
+}
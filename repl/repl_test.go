@@ -0,0 +1,49 @@
+package repl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goruby/goruby/object"
+)
+
+func TestStartBindsResults(t *testing.T) {
+	config := DefaultConfig()
+	config.RCFile = ""
+	input := "1 + 1\n_ * 10\n__\n_1 + _2\nif true\n5\nend\n_7\n"
+
+	out := make(chan string)
+	go StartWithConfig(strings.NewReader(input), out, nil, config)
+	var results []string
+	for s := range out {
+		if strings.HasPrefix(s, "=> ") {
+			results = append(results, strings.TrimSpace(s))
+		}
+	}
+
+	expected := []string{"=> 2", "=> 20", "=> 2", "=> 22", "=> 5", "=> 5"}
+	if strings.Join(results, ";") != strings.Join(expected, ";") {
+		t.Errorf("Expected results %q, got %q", expected, results)
+	}
+}
+
+func TestConsole(t *testing.T) {
+	env := object.NewEnclosedEnvironment(object.NewMainEnvironment())
+	env.Set("x", object.NewInteger(41))
+	config := DefaultConfig()
+	config.Prompt = "> "
+	var out strings.Builder
+
+	Console(object.NewBinding(env), strings.NewReader("x + 1\ny = x\nexit\nz = 1\n"), &out, config)
+
+	expected := "> => 42\n> => 41\n> "
+	if out.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, out.String())
+	}
+	if y, ok := env.Get("y"); !ok || y.Inspect() != "41" {
+		t.Errorf("Expected y to be assigned within the binding, got %v", y)
+	}
+	if _, ok := env.Get("z"); ok {
+		t.Errorf("Expected the console to be left by exit")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package repl
+
+import "errors"
+
+// canEditLines reports whether fd refers to a terminal supporting line
+// editing. Line editing is only supported on Linux, so it reports false
+// elsewhere.
+func canEditLines(fd uintptr) bool { return false }
+
+// makeRaw is not supported outside Linux
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported")
+}
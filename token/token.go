@@ -11,8 +11,12 @@ const (
 
 	IDENT
 	INT
+	FLOAT
 	STRING
-	SYMBOL // :symbol
+	CHAR    // ?a
+	SYMBOL  // :symbol
+	REGEX   // /regex/
+	XSTRING // `command`
 
 	// Operators
 
@@ -22,11 +26,23 @@ const (
 	BANG     // !
 	ASTERISK // *
 	SLASH    // /
-
-	LT    // <
-	GT    // >
-	EQ    // ==
-	NOTEQ // !=
+	MODULO   // %
+	POW      // **
+	AMP      // &
+	CARET    // ^
+	TILDE    // ~
+
+	LT        // <
+	GT        // >
+	EQ        // ==
+	NOTEQ     // !=
+	SPACESHIP // <=>
+	LSHIFT    // <<
+	RSHIFT    // >>
+	MATCH     // =~
+	NOTMATCH  // !~
+
+	HASHROCKET // =>
 
 	// Delimiters
 
@@ -35,13 +51,17 @@ const (
 	SEMICOLON
 
 	DOT      // .
+	DOT2     // ..
+	DOT3     // ...
 	COLON    // :
+	SCOPE    // ::
 	LPAREN   // (
 	RPAREN   // )
 	LBRACE   // {
 	RBRACE   // }
 	LBRACKET // [
 	RBRACKET // ]
+	PIPE     // |
 
 	// Keywords
 
@@ -56,6 +76,20 @@ const (
 	FALSE
 	RETURN
 	NIL
+	RESCUE
+	BEGIN
+	CASE
+	WHEN
+	WHILE
+	DO
+	BREAK
+	NEXT
+	ENSURE
+	CLASS
+	MODULE
+	SUPER
+	BEGIN_BLOCK // BEGIN
+	END_BLOCK   // END
 )
 
 var keywords = map[string]Type{
@@ -70,6 +104,20 @@ var keywords = map[string]Type{
 	"return":  RETURN,
 	"require": REQUIRE,
 	"self":    SELF,
+	"rescue":  RESCUE,
+	"begin":   BEGIN,
+	"case":    CASE,
+	"when":    WHEN,
+	"while":   WHILE,
+	"do":      DO,
+	"break":   BREAK,
+	"next":    NEXT,
+	"ensure":  ENSURE,
+	"class":   CLASS,
+	"module":  MODULE,
+	"super":   SUPER,
+	"BEGIN":   BEGIN_BLOCK,
+	"END":     END_BLOCK,
 }
 
 // LookupIdent returns a keyword TokenType if ident is a keyword or IDENT
@@ -87,7 +135,7 @@ type Type int
 // NewToken returns a new Token associated with the given Type typ, the Literal
 // literal and the Position pos
 func NewToken(typ Type, literal string, pos int) Token {
-	return Token{typ, literal, pos}
+	return Token{Type: typ, Literal: literal, Pos: pos}
 }
 
 // A Token represents a known token with its literal representation
@@ -95,4 +143,6 @@ type Token struct {
 	Type    Type
 	Literal string
 	Pos     int
+	Line    int // the line the token starts at, counting from 1
+	Column  int // the character within Line the token starts at, counting from 1
 }
@@ -4,9 +4,9 @@ package token
 
 import "fmt"
 
-const _Type_name = "ILLEGALEOFIDENTINTSTRINGSYMBOLASSIGNPLUSMINUSBANGASTERISKSLASHLTGTEQNOTEQNEWLINECOMMASEMICOLONDOTCOLONLPARENRPARENLBRACERBRACELBRACKETRBRACKETDEFREQUIRESELFENDIFTHENELSETRUEFALSERETURNNIL"
+const _Type_name = "ILLEGALEOFIDENTINTFLOATSTRINGCHARSYMBOLREGEXXSTRINGASSIGNPLUSMINUSBANGASTERISKSLASHMODULOPOWAMPCARETTILDELTGTEQNOTEQSPACESHIPLSHIFTRSHIFTMATCHNOTMATCHHASHROCKETNEWLINECOMMASEMICOLONDOTDOT2DOT3COLONSCOPELPARENRPARENLBRACERBRACELBRACKETRBRACKETPIPEDEFREQUIRESELFENDIFTHENELSETRUEFALSERETURNNILRESCUEBEGINCASEWHENWHILEDOBREAKNEXTENSURECLASSMODULESUPERBEGIN_BLOCKEND_BLOCK"
 
-var _Type_index = [...]uint8{0, 7, 10, 15, 18, 24, 30, 36, 40, 45, 49, 57, 62, 64, 66, 68, 73, 80, 85, 94, 97, 102, 108, 114, 120, 126, 134, 142, 145, 152, 156, 159, 161, 165, 169, 173, 178, 184, 187}
+var _Type_index = [...]uint16{0, 7, 10, 15, 18, 23, 29, 33, 39, 44, 51, 57, 61, 66, 70, 78, 83, 89, 92, 95, 100, 105, 107, 109, 111, 116, 125, 131, 137, 142, 150, 160, 167, 172, 181, 184, 188, 192, 197, 202, 208, 214, 220, 226, 234, 242, 246, 249, 256, 260, 263, 265, 269, 273, 277, 282, 288, 291, 297, 302, 306, 310, 315, 317, 322, 326, 332, 337, 343, 348, 359, 368}
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_Type_index)-1) {
@@ -0,0 +1,265 @@
+package vm
+
+import (
+	"github.com/goruby/goruby/ast"
+	"github.com/goruby/goruby/code"
+	"github.com/goruby/goruby/compiler"
+	"github.com/goruby/goruby/evaluator"
+	"github.com/goruby/goruby/object"
+)
+
+// Run executes the compiled program within env and returns its result.
+// Local variables are kept within env, so that the nodes handed to the
+// evaluator share them with the compiled code.
+func Run(bytecode *compiler.Bytecode, env object.Environment) (object.RubyObject, error) {
+	return run(bytecode, env)
+}
+
+// run executes bytecode within env. On error the backtrace is recorded for
+// the statement the failing instruction belongs to.
+func run(bytecode *compiler.Bytecode, env object.Environment) (object.RubyObject, error) {
+	m := &machine{bytecode: bytecode, env: env, runtime: object.RuntimeOf(env), stack: make([]object.RubyObject, 0, 16)}
+	result, err := m.run()
+	if err != nil {
+		if statement := bytecode.StatementAt(m.ip); statement != nil {
+			evaluator.RecordBacktrace(env, statement, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+type machine struct {
+	bytecode *compiler.Bytecode
+	env      object.Environment
+	runtime  *object.Runtime
+	stack    []object.RubyObject
+	ip       int
+}
+
+func (m *machine) push(obj object.RubyObject) {
+	if obj == nil {
+		obj = object.NIL
+	}
+	m.stack = append(m.stack, obj)
+}
+
+func (m *machine) pop() object.RubyObject {
+	obj := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	return obj
+}
+
+// popN removes the topmost n values and returns them in the order they
+// have been pushed
+func (m *machine) popN(n int) []object.RubyObject {
+	values := make([]object.RubyObject, n)
+	copy(values, m.stack[len(m.stack)-n:])
+	m.stack = m.stack[:len(m.stack)-n]
+	return values
+}
+
+func (m *machine) run() (object.RubyObject, error) {
+	ins := m.bytecode.Instructions
+	// looped is set when jumping back to the condition of a loop, which
+	// starts no statement to report again
+	looped := false
+	for m.ip = 0; m.ip < len(ins); {
+		op := code.Opcode(ins[m.ip])
+		ip := m.ip
+		if !looped && (object.CoverageEnabled() || object.Tracing()) {
+			if statement := m.bytecode.StatementStartingAt(ip); statement != nil {
+				if err := evaluator.EnterStatement(statement, m.env); err != nil {
+					return nil, err
+				}
+			}
+		}
+		looped = false
+		switch op {
+		case code.OpConstant:
+			m.push(m.bytecode.Constants[code.ReadUint16(ins[ip+1:])])
+			m.ip += 3
+		case code.OpPop:
+			m.pop()
+			m.ip++
+		case code.OpNil:
+			m.push(object.NIL)
+			m.ip++
+		case code.OpTrue:
+			m.push(object.TRUE)
+			m.ip++
+		case code.OpFalse:
+			m.push(object.FALSE)
+			m.ip++
+		case code.OpSelf:
+			self, _ := m.env.Get("self")
+			m.push(self)
+			m.ip++
+		case code.OpGetLocal:
+			node := m.bytecode.Nodes[code.ReadUint16(ins[ip+1:])].(*ast.Identifier)
+			value, err := m.getLocal(node)
+			if err != nil {
+				return nil, err
+			}
+			m.push(value)
+			m.ip += 3
+		case code.OpSetLocal:
+			value := m.stack[len(m.stack)-1]
+			m.env.Set(m.bytecode.Names[code.ReadUint16(ins[ip+1:])], value)
+			m.ip += 3
+		case code.OpInfix:
+			operator := m.bytecode.Names[code.ReadUint16(ins[ip+1:])]
+			right := m.pop()
+			left := m.pop()
+			result, err := m.infix(operator, left, right)
+			if err != nil {
+				return nil, err
+			}
+			m.push(result)
+			m.ip += 3
+		case code.OpPrefix:
+			operator := m.bytecode.Names[code.ReadUint16(ins[ip+1:])]
+			result, err := evaluator.EvalPrefix(m.runtime, operator, m.pop())
+			if err != nil {
+				return nil, err
+			}
+			m.push(result)
+			m.ip += 3
+		case code.OpArray:
+			array, err := evaluator.CountObject(m.env, object.NewArray(m.popN(int(code.ReadUint16(ins[ip+1:])))...))
+			if err != nil {
+				return nil, err
+			}
+			m.push(array)
+			m.ip += 3
+		case code.OpIndex:
+			index := m.pop()
+			left := m.pop()
+			result, err := m.runtime.Send(left, "[]", index)
+			if err != nil {
+				return nil, err
+			}
+			m.push(result)
+			m.ip++
+		case code.OpCall:
+			node := m.bytecode.Nodes[code.ReadUint16(ins[ip+1:])].(*ast.ContextCallExpression)
+			args := m.popN(int(code.ReadUint8(ins[ip+3:])))
+			context := m.pop()
+			result, err := evaluator.Call(node, context, args, m.env)
+			if err == nil && node.Function.Value == "new" {
+				result, err = evaluator.CountObject(m.env, result)
+			}
+			if err != nil {
+				return nil, err
+			}
+			m.push(result)
+			m.ip += 4
+		case code.OpJump:
+			m.ip = int(code.ReadUint16(ins[ip+1:]))
+		case code.OpJumpNotTruthy:
+			if isTruthy(m.pop()) {
+				m.ip += 3
+			} else {
+				m.ip = int(code.ReadUint16(ins[ip+1:]))
+			}
+		case code.OpLoop:
+			if err := object.RuntimeOf(m.env).CheckInterrupt(); err != nil {
+				return nil, err
+			}
+			if err := evaluator.CheckContext(m.env); err != nil {
+				return nil, err
+			}
+			m.ip = int(code.ReadUint16(ins[ip+1:]))
+			looped = true
+		case code.OpEval:
+			result, err := evaluator.Eval(m.bytecode.Nodes[code.ReadUint16(ins[ip+1:])], m.env)
+			if err != nil {
+				return nil, err
+			}
+			m.push(result)
+			m.ip += 3
+		case code.OpDefine:
+			node := m.bytecode.Nodes[code.ReadUint16(ins[ip+1:])]
+			body := m.bytecode.Functions[code.ReadUint16(ins[ip+3:])]
+			result, err := evaluator.Eval(node, m.env)
+			if err != nil {
+				return nil, err
+			}
+			if fn, ok := result.(*object.Function); ok {
+				fn.CallFn = compiledMethod(body)
+			}
+			m.push(result)
+			m.ip += 5
+		default:
+			def, err := code.Lookup(byte(op))
+			if err != nil {
+				return nil, object.NewRuntimeError("%s", err)
+			}
+			return nil, object.NewRuntimeError("unsupported instruction %s", def.Name)
+		}
+	}
+	if len(m.stack) == 0 {
+		return object.NIL, nil
+	}
+	return m.pop(), nil
+}
+
+// getLocal returns the local variable named by node. Anything else, like a
+// method call, is resolved by the evaluator.
+func (m *machine) getLocal(node *ast.Identifier) (object.RubyObject, error) {
+	value, ok := m.env.Get(node.Value)
+	if ok {
+		if _, ok := value.(*object.Function); !ok {
+			return value, nil
+		}
+	}
+	return evaluator.Eval(node, m.env)
+}
+
+// compiledMethod returns the function calling a method whose body has been
+// compiled to body
+func compiledMethod(body *compiler.Bytecode) func(object.RubyObject, []object.RubyObject) (object.RubyObject, error) {
+	return func(fn object.RubyObject, args []object.RubyObject) (object.RubyObject, error) {
+		return evaluator.ApplyMethod(fn.(*object.Function), args, func(env object.Environment) (object.RubyObject, error) {
+			return run(body, env)
+		})
+	}
+}
+
+// infix applies the infix operator to left and right. Arithmetic and
+// comparisons of Integers are handled directly, all other operations like
+// the evaluator does.
+func (m *machine) infix(operator string, left, right object.RubyObject) (object.RubyObject, error) {
+	l, leftIsInteger := left.(*object.Integer)
+	r, rightIsInteger := right.(*object.Integer)
+	if leftIsInteger && rightIsInteger {
+		switch operator {
+		case "+":
+			return object.NewInteger(l.Value + r.Value), nil
+		case "-":
+			return object.NewInteger(l.Value - r.Value), nil
+		case "*":
+			return object.NewInteger(l.Value * r.Value), nil
+		case "<":
+			return nativeBool(l.Value < r.Value), nil
+		case ">":
+			return nativeBool(l.Value > r.Value), nil
+		case "==":
+			return nativeBool(l.Value == r.Value), nil
+		case "!=":
+			return nativeBool(l.Value != r.Value), nil
+		}
+	}
+	return evaluator.EvalInfix(m.runtime, operator, left, right)
+}
+
+func nativeBool(value bool) object.RubyObject {
+	if value {
+		return object.TRUE
+	}
+	return object.FALSE
+}
+
+func isTruthy(obj object.RubyObject) bool {
+	return obj != object.NIL && obj != object.FALSE
+}
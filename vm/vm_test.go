@@ -0,0 +1,143 @@
+package vm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goruby/goruby/compiler"
+	"github.com/goruby/goruby/evaluator"
+	"github.com/goruby/goruby/lexer"
+	"github.com/goruby/goruby/object"
+	"github.com/goruby/goruby/parser"
+)
+
+func runVM(t *testing.T, input string) (object.RubyObject, error) {
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse error for %q: %v", input, err)
+	}
+	bytecode, err := compiler.Compile(program)
+	if err != nil {
+		t.Fatalf("compile error for %q: %v", input, err)
+	}
+	return Run(bytecode, object.NewMainEnvironment())
+}
+
+func evalProgram(t *testing.T, input string) (object.RubyObject, error) {
+	program, err := parser.New(lexer.New(input)).ParseProgram()
+	if err != nil {
+		t.Fatalf("parse error for %q: %v", input, err)
+	}
+	return evaluator.Eval(program, object.NewMainEnvironment())
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "nil"},
+		{"1 + 2 * 3", "7"},
+		{"1.5 + 2", "3.5"},
+		{"7 % 3", "1"},
+		{"-3 + 1", "-2"},
+		{"!nil", "true"},
+		{"x = 5; y = x * 2; y - x", "5"},
+		{"if 1 > 2; :a; else; :b; end", ":b"},
+		{"if 1 < 2; :a; end", ":a"},
+		{"if false; :a; end", "nil"},
+		{"i = 0; s = 0; while i < 5; s = s + i; i = i + 1; end; s", "10"},
+		{"while false; end", "nil"},
+		{"i = 0; while true; i = i + 1; if i == 3; break; end; end; i", "3"},
+		{"[1, 2, 3][1]", "2"},
+		{"[1, 2, 3].first", "1"},
+		{"[1, 2, 3].map { |x| x * 2 }", "[2, 4, 6]"},
+		{"s = \"a\"; s << \"b\"; s", "ab"},
+		{"$vm_global = 3; $vm_global", "3"},
+		{"def vm_add(a, b); a + b; end; vm_add(2, 3)", "5"},
+		{"def vm_fib(n); if n < 2; n; else; vm_fib(n - 1) + vm_fib(n - 2); end; end; vm_fib(10)", "55"},
+		{"def vm_early(x); if x; return 1; end; 2; end; vm_early(false)", "2"},
+		{"def vm_each; s = 0; [1, 2].each { |x| s = s + x }; s; end; vm_each", "3"},
+		{"def vm_rescue; raise \"x\"; rescue => e; e.message; end; vm_rescue", "x"},
+		{"class VMFoo; def bar; 42; end; end; VMFoo.new.bar", "42"},
+		{"class VMBase; def a(x); x; end; end; class VMSub < VMBase; def a(x); super + 1; end; end; VMSub.new.a(1)", "2"},
+	}
+
+	for _, tt := range tests {
+		result, err := runVM(t, tt.input)
+		if err != nil {
+			t.Logf("Expected no error for %q, got %T:%v", tt.input, err, err)
+			t.Fail()
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Logf("Expected %q to evaluate to %s, got %s", tt.input, tt.expected, result.Inspect())
+			t.Fail()
+		}
+	}
+}
+
+func TestRunMatchesEvaluator(t *testing.T) {
+	tests := []string{
+		"1 / 0",
+		"nil + 1",
+		"undefined_vm_method",
+		"def vm_arity(a); a; end; vm_arity(1, 2)",
+		"[1, 2] + 3",
+	}
+
+	for _, input := range tests {
+		expectedResult, expectedErr := evalProgram(t, input)
+		result, err := runVM(t, input)
+
+		object.SetBacktrace(expectedErr, nil)
+		object.SetBacktrace(err, nil)
+		if !reflect.DeepEqual(err, expectedErr) {
+			t.Logf("Expected %q to fail with %T:%v, got %T:%v", input, expectedErr, expectedErr, err, err)
+			t.Fail()
+		}
+		if !reflect.DeepEqual(result, expectedResult) {
+			t.Logf("Expected %q to return %v, got %v", input, expectedResult, result)
+			t.Fail()
+		}
+	}
+}
+
+func TestRunBacktrace(t *testing.T) {
+	_, err := runVM(t, "x = 1\ny = x / 0\n")
+
+	backtrace, ok := object.Backtrace(err)
+	if !ok || len(backtrace) != 1 || backtrace[0] != "-:2:in `<main>'" {
+		t.Logf("Expected backtrace of the failing statement, got %q", backtrace)
+		t.Fail()
+	}
+}
+
+func TestRunReportsTraceEvents(t *testing.T) {
+	input := `def vm_traced(a)
+		a + 1
+	end
+	events = []
+	tp = TracePoint.new(:line, :call, :return) { |t| events << [t.event, t.lineno] }
+	tp.enable
+	vm_traced(1)
+	i = 0
+	while i < 2
+		i = i + 1
+	end
+	tp.disable
+	events`
+
+	expected, err := evalProgram(t, input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %T:%v", err, err)
+	}
+	result, err := runVM(t, input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %T:%v", err, err)
+	}
+	if result.Inspect() != expected.Inspect() {
+		t.Logf("Expected the events %s, got %s", expected.Inspect(), result.Inspect())
+		t.Fail()
+	}
+}